@@ -253,7 +253,6 @@ BINARIES:=./istioctl/cmd/istioctl \
   ./galley/cmd/galley \
   ./security/cmd/node_agent \
   ./security/cmd/node_agent_k8s \
-  ./security/cmd/istio_ca \
   ./security/tools/sdsclient \
   ./pkg/test/echo/cmd/client \
   ./pkg/test/echo/cmd/server \
@@ -261,6 +260,10 @@ BINARIES:=./istioctl/cmd/istioctl \
   ./tools/istio-iptables \
   ./tools/istio-clean-iptables
 
+# List of binaries built with cgo. They are linked dynamically, since Citadel loads the PKCS#11 modules of the
+# HSMs holding its signing key.
+CGO_BINARIES:=./security/cmd/istio_ca
+
 # List of binaries included in releases
 RELEASE_BINARIES:=pilot-discovery pilot-agent sidecar-injector mixc mixs mixgen node_agent node_agent_k8s istio_ca istioctl galley sdsclient
 
@@ -276,6 +279,7 @@ endif
 .PHONY: build
 build: depend $(BUILD_DEPS)
 	STATIC=0 GOOS=$(GOOS_LOCAL) GOARCH=$(GOARCH_LOCAL) LDFLAGS='-extldflags -static -s -w' common/scripts/gobuild.sh $(ISTIO_OUT)/ $(BINARIES)
+	CGO_ENABLED=1 STATIC=0 GOOS=$(GOOS_LOCAL) GOARCH=$(GOARCH_LOCAL) LDFLAGS='-s -w' common/scripts/gobuild.sh $(ISTIO_OUT)/ $(CGO_BINARIES)
 
 # The build-linux target is responsible for building binaries used within containers.
 # This target should be expanded upon as we add more Linux architectures: i.e. buld-arm64.
@@ -284,6 +288,7 @@ build: depend $(BUILD_DEPS)
 .PHONY: build-linux
 build-linux: depend
 	STATIC=0 GOOS=linux GOARCH=amd64 LDFLAGS='-extldflags -static -s -w' common/scripts/gobuild.sh $(ISTIO_OUT_LINUX)/ $(BINARIES)
+	CGO_ENABLED=1 STATIC=0 GOOS=linux GOARCH=amd64 LDFLAGS='-s -w' common/scripts/gobuild.sh $(ISTIO_OUT_LINUX)/ $(CGO_BINARIES)
 
 # Create targets for ISTIO_OUT_LINUX/binary
 # There are two use cases here:
@@ -302,9 +307,21 @@ endef
 
 $(foreach bin,$(BINARIES),$(eval $(call build-linux,$(bin))))
 
+define build-linux-cgo
+.PHONY: $(ISTIO_OUT_LINUX)/$(shell basename $(1))
+ifeq ($(BUILD_ALL),true)
+$(ISTIO_OUT_LINUX)/$(shell basename $(1)): build-linux
+else
+$(ISTIO_OUT_LINUX)/$(shell basename $(1)): $(ISTIO_OUT_LINUX)
+	CGO_ENABLED=1 STATIC=0 GOOS=linux GOARCH=amd64 LDFLAGS='-s -w' common/scripts/gobuild.sh $(ISTIO_OUT_LINUX)/ $(1)
+endif
+endef
+
+$(foreach bin,$(CGO_BINARIES),$(eval $(call build-linux-cgo,$(bin))))
+
 # Create helper targets for each binary, like "pilot-discovery"
 # As an optimization, these still build everything
-$(foreach bin,$(BINARIES),$(shell basename $(bin))): build
+$(foreach bin,$(BINARIES) $(CGO_BINARIES),$(shell basename $(bin))): build
 
 MARKDOWN_LINT_WHITELIST=localhost:8080,storage.googleapis.com/istio-artifacts/pilot/,http://ratings.default.svc.cluster.local:9080/ratings
 
@@ -0,0 +1,66 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"istio.io/istio/galley/pkg/server/components"
+	"istio.io/istio/galley/pkg/server/settings"
+	istiocmd "istio.io/istio/pkg/cmd"
+	"istio.io/pkg/log"
+)
+
+func canaryCmd() *cobra.Command {
+	canaryArgs := settings.DefaultArgs()
+
+	c := &cobra.Command{
+		Use:          "canary",
+		Short:        "Runs the canary analysis controller standalone",
+		Long:         "Steps the weights of the VirtualService routes of the canaries while their metrics meet their thresholds.",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(0),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return log.Configure(loggingOptions)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if canaryArgs.CanaryConfigFile == "" {
+				return fmt.Errorf("--canaryConfigFile is required")
+			}
+			canary := components.NewCanary(canaryArgs)
+			if err := canary.Start(); err != nil {
+				return err
+			}
+
+			stop := make(chan struct{})
+			istiocmd.WaitSignal(stop)
+			canary.Stop()
+			return nil
+		},
+	}
+	c.PersistentFlags().StringVar(&canaryArgs.KubeConfig, "kubeconfig", canaryArgs.KubeConfig,
+		"Use a Kubernetes configuration file instead of in-cluster configuration")
+	c.PersistentFlags().StringVar(&canaryArgs.DomainSuffix, "domain", canaryArgs.DomainSuffix,
+		"DNS domain suffix")
+	c.PersistentFlags().StringVar(&canaryArgs.CanaryConfigFile, "canaryConfigFile", canaryArgs.CanaryConfigFile,
+		"File of the canaries whose VirtualService routes are stepped")
+	c.PersistentFlags().StringVar(&canaryArgs.PrometheusAddress, "prometheusAddress", canaryArgs.PrometheusAddress,
+		"Address of the Prometheus compatible API queried for the metrics of the canaries")
+	loggingOptions.AttachCobraFlags(c)
+
+	return c
+}
@@ -160,6 +160,10 @@ func serverCmd() *cobra.Command {
 		"Enable the Fsnotify for watching config source files on the disk and implicit signaling on a config change. Explicit signaling will still be enabled")
 	svr.PersistentFlags().BoolVar(&serverArgs.EnableConfigAnalysis, "enableAnalysis", serverArgs.EnableConfigAnalysis,
 		"Enable config analysis service")
+	svr.PersistentFlags().StringVar(&serverArgs.CanaryConfigFile, "canaryConfigFile", serverArgs.CanaryConfigFile,
+		"File of the canaries whose VirtualService routes are stepped by Galley. Leaving empty disables the canary analysis")
+	svr.PersistentFlags().StringVar(&serverArgs.PrometheusAddress, "prometheusAddress", serverArgs.PrometheusAddress,
+		"Address of the Prometheus compatible API queried for the metrics of the canaries")
 
 	// validation config
 	svr.PersistentFlags().StringVar(&serverArgs.ValidationArgs.WebhookConfigFile,
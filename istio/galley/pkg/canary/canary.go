@@ -0,0 +1,197 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package canary implements the progressive delivery of a canary: the controller steps the weight of the
+// canary destination of a VirtualService route on a schedule while the metrics of the canary meet their
+// thresholds, and rolls the route back to the primary destination when they do not.
+package canary
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/hashicorp/go-multierror"
+)
+
+const (
+	// DefaultInterval is the interval between the steps of the canaries when none is configured.
+	DefaultInterval = time.Minute
+
+	// DefaultStepWeight is the weight added to the canary destination at each step when none is configured.
+	DefaultStepWeight = 10
+)
+
+// Destination is a destination of the weighted route.
+type Destination struct {
+	// Host is the host of the destination, as written in the route.
+	Host string `json:"host"`
+
+	// Subset is the subset of the destination, as written in the route.
+	Subset string `json:"subset,omitempty"`
+}
+
+// Thresholds are the thresholds of the metrics of the canary. The canary is rolled back when one of them
+// is breached.
+type Thresholds struct {
+	// MinSuccessRate is the minimum percentage of the requests of the canary which are not 5xx, e.g. 99.
+	MinSuccessRate float64 `json:"minSuccessRate,omitempty"`
+
+	// MaxLatencyP99 is the maximum 99th percentile of the latency of the requests of the canary, e.g. "500ms".
+	MaxLatencyP99 string `json:"maxLatencyP99,omitempty"`
+}
+
+// Canary is the progressive delivery of a canary destination of a weighted VirtualService route.
+type Canary struct {
+	// Name of the canary.
+	Name string `json:"name"`
+
+	// Namespace of the canary and its VirtualService.
+	Namespace string `json:"namespace"`
+
+	// VirtualService is the name of the VirtualService.
+	VirtualService string `json:"virtualService"`
+
+	// Route is the name of the HTTP route of the VirtualService. Defaults to the first route.
+	Route string `json:"route,omitempty"`
+
+	// Primary is the destination of the route serving the stable version, which the canary replaces.
+	Primary Destination `json:"primary"`
+
+	// Canary is the destination of the route serving the canary version.
+	Canary Destination `json:"canary"`
+
+	// Workload is the name of the workload of the canary destination, the destination_workload label of
+	// its metrics.
+	Workload string `json:"workload"`
+
+	// Interval is the interval between the steps, e.g. "1m". The metrics are analyzed over the same
+	// interval. Defaults to 1m.
+	Interval string `json:"interval,omitempty"`
+
+	// StepWeight is the weight added to the canary destination at each step. Defaults to 10.
+	StepWeight int32 `json:"stepWeight,omitempty"`
+
+	// MaxWeight is the weight of the canary destination after which it is promoted, taking all the
+	// traffic of the route. Defaults to 100.
+	MaxWeight int32 `json:"maxWeight,omitempty"`
+
+	// Thresholds are the thresholds of the metrics of the canary.
+	Thresholds Thresholds `json:"thresholds"`
+}
+
+// Canaries is the configuration of the canaries of the controller.
+type Canaries struct {
+	Canaries []*Canary `json:"canaries"`
+}
+
+// Key returns the namespace/name key of the canary.
+func (c *Canary) Key() string {
+	return c.Namespace + "/" + c.Name
+}
+
+// IntervalDuration returns the interval between the steps.
+func (c *Canary) IntervalDuration() time.Duration {
+	if d, err := time.ParseDuration(c.Interval); err == nil && d > 0 {
+		return d
+	}
+	return DefaultInterval
+}
+
+// MaxLatencyP99Duration returns the maximum latency, or 0 if the latency is not analyzed.
+func (c *Canary) MaxLatencyP99Duration() time.Duration {
+	d, _ := time.ParseDuration(c.Thresholds.MaxLatencyP99)
+	return d
+}
+
+func (c *Canary) stepWeight() int32 {
+	if c.StepWeight > 0 {
+		return c.StepWeight
+	}
+	return DefaultStepWeight
+}
+
+func (c *Canary) maxWeight() int32 {
+	if c.MaxWeight > 0 {
+		return c.MaxWeight
+	}
+	return 100
+}
+
+// ParseCanaries parses and validates the configuration of the canaries.
+func ParseCanaries(yamlText string) (*Canaries, error) {
+	out := &Canaries{}
+	if err := yaml.Unmarshal([]byte(yamlText), out); err != nil {
+		return nil, multierror.Prefix(err, "failed to parse canaries config")
+	}
+
+	var errs error
+	keys := map[string]bool{}
+	for _, c := range out.Canaries {
+		if c.Name == "" || c.Namespace == "" {
+			errs = multierror.Append(errs, fmt.Errorf("canary without name or namespace"))
+			continue
+		}
+		if keys[c.Key()] {
+			errs = multierror.Append(errs, fmt.Errorf("duplicate canary %q", c.Key()))
+		}
+		keys[c.Key()] = true
+		if c.VirtualService == "" {
+			errs = multierror.Append(errs, fmt.Errorf("canary %q: virtual service is required", c.Key()))
+		}
+		if c.Primary.Host == "" || c.Canary.Host == "" {
+			errs = multierror.Append(errs, fmt.Errorf("canary %q: primary and canary hosts are required", c.Key()))
+		} else if c.Primary == c.Canary {
+			errs = multierror.Append(errs, fmt.Errorf("canary %q: the primary and canary destinations are the same", c.Key()))
+		}
+		if c.Workload == "" {
+			errs = multierror.Append(errs, fmt.Errorf("canary %q: workload is required", c.Key()))
+		}
+		if c.Interval != "" {
+			if d, err := time.ParseDuration(c.Interval); err != nil || d <= 0 {
+				errs = multierror.Append(errs, fmt.Errorf("canary %q: invalid interval %q", c.Key(), c.Interval))
+			}
+		}
+		if c.StepWeight < 0 || c.StepWeight > 100 {
+			errs = multierror.Append(errs, fmt.Errorf("canary %q: invalid step weight %d", c.Key(), c.StepWeight))
+		}
+		if c.MaxWeight < 0 || c.MaxWeight > 100 {
+			errs = multierror.Append(errs, fmt.Errorf("canary %q: invalid max weight %d", c.Key(), c.MaxWeight))
+		}
+		if c.Thresholds.MinSuccessRate < 0 || c.Thresholds.MinSuccessRate > 100 {
+			errs = multierror.Append(errs, fmt.Errorf("canary %q: invalid min success rate %v", c.Key(),
+				c.Thresholds.MinSuccessRate))
+		}
+		if c.Thresholds.MaxLatencyP99 != "" {
+			if d, err := time.ParseDuration(c.Thresholds.MaxLatencyP99); err != nil || d <= 0 {
+				errs = multierror.Append(errs, fmt.Errorf("canary %q: invalid max latency %q", c.Key(),
+					c.Thresholds.MaxLatencyP99))
+			}
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return out, nil
+}
+
+// ReadCanaries gets the configuration of the canaries from a config file.
+func ReadCanaries(filename string) (*Canaries, error) {
+	yamlText, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, multierror.Prefix(err, "cannot read canaries config file")
+	}
+	return ParseCanaries(string(yamlText))
+}
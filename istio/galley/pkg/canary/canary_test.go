@@ -0,0 +1,121 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canary
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCanaries(t *testing.T) {
+	cases := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			yaml: `
+canaries:
+- name: reviews
+  namespace: default
+  virtualService: reviews
+  primary: {host: reviews, subset: v1}
+  canary: {host: reviews, subset: v2}
+  workload: reviews-v2
+  interval: 30s
+  stepWeight: 20
+  maxWeight: 60
+  thresholds:
+    minSuccessRate: 99
+    maxLatencyP99: 500ms
+`,
+		},
+		{
+			name: "missing fields",
+			yaml: `
+canaries:
+- name: reviews
+  namespace: default
+`,
+			wantErr: "virtual service is required",
+		},
+		{
+			name: "duplicate",
+			yaml: `
+canaries:
+- {name: reviews, namespace: default, virtualService: reviews, primary: {host: a}, canary: {host: b}, workload: b}
+- {name: reviews, namespace: default, virtualService: reviews, primary: {host: a}, canary: {host: b}, workload: b}
+`,
+			wantErr: "duplicate canary",
+		},
+		{
+			name: "same destinations",
+			yaml: `
+canaries:
+- {name: reviews, namespace: default, virtualService: reviews, primary: {host: a}, canary: {host: a}, workload: a}
+`,
+			wantErr: "the primary and canary destinations are the same",
+		},
+		{
+			name: "invalid values",
+			yaml: `
+canaries:
+- name: reviews
+  namespace: default
+  virtualService: reviews
+  primary: {host: reviews, subset: v1}
+  canary: {host: reviews, subset: v2}
+  workload: reviews-v2
+  interval: often
+  stepWeight: 200
+  thresholds:
+    minSuccessRate: 101
+    maxLatencyP99: -1s
+`,
+			wantErr: "invalid interval",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCanaries(tt.yaml)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCanaryDefaults(t *testing.T) {
+	c := &Canary{}
+	if c.IntervalDuration() != DefaultInterval || c.stepWeight() != DefaultStepWeight || c.maxWeight() != 100 {
+		t.Errorf("got interval %v, step weight %d and max weight %d", c.IntervalDuration(), c.stepWeight(), c.maxWeight())
+	}
+	if c.MaxLatencyP99Duration() != 0 {
+		t.Errorf("got max latency %v, want 0", c.MaxLatencyP99Duration())
+	}
+	c = &Canary{Interval: "2m", StepWeight: 5, MaxWeight: 50, Thresholds: Thresholds{MaxLatencyP99: "1s"}}
+	if c.IntervalDuration() != 2*time.Minute || c.stepWeight() != 5 || c.maxWeight() != 50 || c.MaxLatencyP99Duration() != time.Second {
+		t.Errorf("got interval %v, step weight %d, max weight %d and max latency %v", c.IntervalDuration(), c.stepWeight(),
+			c.maxWeight(), c.MaxLatencyP99Duration())
+	}
+}
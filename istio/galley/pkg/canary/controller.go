@@ -0,0 +1,274 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canary
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/pkg/log"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+// PhaseAnnotation is the annotation of the VirtualService recording the phase of its canary. The canaries
+// Promoted or RolledBack are not stepped anymore: removing the annotation starts a new analysis.
+const PhaseAnnotation = "networking.istio.io/canaryPhase"
+
+// Phase is the phase of a canary.
+type Phase string
+
+const (
+	// PhaseProgressing is the phase of the canaries whose weight is stepped.
+	PhaseProgressing Phase = "Progressing"
+	// PhasePromoted is the phase of the canaries which reached their max weight, taking all the traffic.
+	PhasePromoted Phase = "Promoted"
+	// PhaseRolledBack is the phase of the canaries which breached a threshold, not taking any traffic.
+	PhaseRolledBack Phase = "RolledBack"
+)
+
+const queryTimeout = 30 * time.Second
+
+var scope = log.RegisterScope("canary", "Canary analysis controller", 0)
+
+// Controller steps the weights of the routes of the canaries in the config store.
+type Controller struct {
+	store   model.ConfigStore
+	querier Querier
+
+	mutex    sync.Mutex
+	canaries []*Canary
+	// changed is notified when the canaries are replaced.
+	changed chan struct{}
+}
+
+// NewController creates a controller of the canaries, updating the VirtualServices of the store.
+func NewController(store model.ConfigStore, querier Querier, canaries []*Canary) *Controller {
+	return &Controller{
+		store:    store,
+		querier:  querier,
+		canaries: canaries,
+		changed:  make(chan struct{}, 1),
+	}
+}
+
+// SetCanaries replaces the canaries of the controller. The analysis of the new canaries starts over, from the
+// phase and weights recorded in their VirtualServices.
+func (c *Controller) SetCanaries(canaries []*Canary) {
+	c.mutex.Lock()
+	c.canaries = canaries
+	c.mutex.Unlock()
+	select {
+	case c.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Canaries returns the canaries of the controller.
+func (c *Controller) Canaries() []*Canary {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.canaries
+}
+
+// Run steps each canary at its interval until stop is closed. The canaries are restarted when they are replaced.
+func (c *Controller) Run(stop <-chan struct{}) {
+	for {
+		canariesStop := make(chan struct{})
+		done := make(chan struct{})
+		go func(canaries []*Canary) {
+			c.run(canaries, canariesStop)
+			close(done)
+		}(c.Canaries())
+
+		select {
+		case <-stop:
+			close(canariesStop)
+			<-done
+			return
+		case <-c.changed:
+			close(canariesStop)
+			<-done
+		}
+	}
+}
+
+// run steps each canary at its interval until stop is closed.
+func (c *Controller) run(canaries []*Canary, stop <-chan struct{}) {
+	var wg sync.WaitGroup
+	for _, canary := range canaries {
+		wg.Add(1)
+		go func(canary *Canary) {
+			defer wg.Done()
+			ticker := time.NewTicker(canary.IntervalDuration())
+			defer ticker.Stop()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+					if err := c.reconcile(canary); err != nil {
+						scope.Warnf("canary %s: %v", canary.Key(), err)
+					}
+				}
+			}
+		}(canary)
+	}
+	wg.Wait()
+}
+
+// reconcile runs a step of the canary: it analyzes the metrics of the canary destination, then either
+// rolls the route back, promotes the canary or adds the step weight to the canary destination.
+func (c *Controller) reconcile(canary *Canary) error {
+	cfg := c.store.Get(schemas.VirtualService.Type, canary.VirtualService, canary.Namespace)
+	if cfg == nil {
+		return fmt.Errorf("virtual service %s/%s not found", canary.Namespace, canary.VirtualService)
+	}
+	phase := Phase(cfg.Annotations[PhaseAnnotation])
+	if phase == PhasePromoted || phase == PhaseRolledBack {
+		return nil
+	}
+
+	vs := proto.Clone(cfg.Spec).(*networking.VirtualService)
+	primary, target, err := routeDestinations(vs, canary)
+	if err != nil {
+		return err
+	}
+
+	var weight int32
+	switch {
+	case target.Weight == 0:
+		weight = canary.stepWeight()
+		phase = PhaseProgressing
+	default:
+		breach, err := c.analyze(canary)
+		if err == ErrNoData {
+			scope.Debugf("canary %s: no metrics, waiting for the next step", canary.Key())
+			return nil
+		} else if err != nil {
+			return err
+		}
+		switch {
+		case breach != "":
+			scope.Infof("canary %s: rolling back, %s", canary.Key(), breach)
+			weight = 0
+			phase = PhaseRolledBack
+		case target.Weight >= canary.maxWeight():
+			scope.Infof("canary %s: promoting", canary.Key())
+			weight = 100
+			phase = PhasePromoted
+		default:
+			weight = target.Weight + canary.stepWeight()
+			if weight > canary.maxWeight() {
+				weight = canary.maxWeight()
+			}
+			phase = PhaseProgressing
+		}
+	}
+	scope.Infof("canary %s: %s, weight %d", canary.Key(), phase, weight)
+	target.Weight = weight
+	primary.Weight = 100 - weight
+
+	out := *cfg
+	out.Spec = vs
+	out.Annotations = make(map[string]string, len(cfg.Annotations)+1)
+	for k, v := range cfg.Annotations {
+		out.Annotations[k] = v
+	}
+	out.Annotations[PhaseAnnotation] = string(phase)
+	if _, err := c.store.Update(out); err != nil {
+		return fmt.Errorf("failed to update virtual service %s/%s: %v", canary.Namespace, canary.VirtualService, err)
+	}
+	return nil
+}
+
+// analyze returns the threshold breached by the metrics of the canary, if any.
+func (c *Controller) analyze(canary *Canary) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	interval := canary.IntervalDuration()
+	if min := canary.Thresholds.MinSuccessRate; min > 0 {
+		rate, err := c.querier.Query(ctx, successRateQuery(canary, interval))
+		if err != nil {
+			return "", err
+		}
+		if rate < min {
+			return fmt.Sprintf("success rate %.2f%% below %.2f%%", rate, min), nil
+		}
+	}
+	if max := canary.MaxLatencyP99Duration(); max > 0 {
+		seconds, err := c.querier.Query(ctx, latencyP99Query(canary, interval))
+		if err != nil {
+			return "", err
+		}
+		if latency := time.Duration(seconds * float64(time.Second)); latency > max {
+			return fmt.Sprintf("p99 latency %v above %v", latency, max), nil
+		}
+	}
+	return "", nil
+}
+
+// routeDestinations returns the primary and canary destinations of the route of the canary. The canary
+// destination is added to the route if it is missing.
+func routeDestinations(vs *networking.VirtualService, canary *Canary) (primary, target *networking.HTTPRouteDestination, err error) {
+	var route *networking.HTTPRoute
+	for _, r := range vs.Http {
+		if canary.Route == "" || r.Name == canary.Route {
+			route = r
+			break
+		}
+	}
+	if route == nil {
+		return nil, nil, fmt.Errorf("route %q not found in virtual service %s/%s", canary.Route, canary.Namespace,
+			canary.VirtualService)
+	}
+
+	for _, d := range route.Route {
+		switch destination(d) {
+		case canary.Primary:
+			primary = d
+		case canary.Canary:
+			target = d
+		default:
+			return nil, nil, fmt.Errorf("route %q has a destination %v other than the primary and the canary",
+				route.Name, destination(d))
+		}
+	}
+	if primary == nil {
+		return nil, nil, fmt.Errorf("primary destination %v not found in route %q", canary.Primary, route.Name)
+	}
+	if target == nil {
+		target = proto.Clone(primary).(*networking.HTTPRouteDestination)
+		target.Destination.Host = canary.Canary.Host
+		target.Destination.Subset = canary.Canary.Subset
+		target.Weight = 0
+		route.Route = append(route.Route, target)
+	}
+	return primary, target, nil
+}
+
+func destination(d *networking.HTTPRouteDestination) Destination {
+	if d.Destination == nil {
+		return Destination{}
+	}
+	return Destination{Host: d.Destination.Host, Subset: d.Destination.Subset}
+}
@@ -0,0 +1,240 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canary
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pilot/pkg/config/memory"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/schema"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+// fakeQuerier returns the success rate and the latency of the canary.
+type fakeQuerier struct {
+	successRate float64
+	latency     float64
+	err         error
+}
+
+func (q *fakeQuerier) Query(_ context.Context, query string) (float64, error) {
+	if q.err != nil {
+		return 0, q.err
+	}
+	if strings.HasPrefix(query, "histogram_quantile") {
+		return q.latency, nil
+	}
+	return q.successRate, nil
+}
+
+func newStore(t *testing.T, routes ...*networking.HTTPRouteDestination) model.ConfigStore {
+	t.Helper()
+	store := memory.Make(schema.Set{schemas.VirtualService})
+	_, err := store.Create(model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:      schemas.VirtualService.Type,
+			Group:     schemas.VirtualService.Group,
+			Version:   schemas.VirtualService.Version,
+			Name:      "reviews",
+			Namespace: "default",
+		},
+		Spec: &networking.VirtualService{
+			Hosts: []string{"reviews"},
+			Http:  []*networking.HTTPRoute{{Name: "default", Route: routes}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func routeDestination(subset string, weight int32) *networking.HTTPRouteDestination {
+	return &networking.HTTPRouteDestination{
+		Destination: &networking.Destination{Host: "reviews", Subset: subset},
+		Weight:      weight,
+	}
+}
+
+func testCanary() *Canary {
+	return &Canary{
+		Name:           "reviews",
+		Namespace:      "default",
+		VirtualService: "reviews",
+		Primary:        Destination{Host: "reviews", Subset: "v1"},
+		Canary:         Destination{Host: "reviews", Subset: "v2"},
+		Workload:       "reviews-v2",
+		StepWeight:     20,
+		MaxWeight:      50,
+		Thresholds:     Thresholds{MinSuccessRate: 99, MaxLatencyP99: "500ms"},
+	}
+}
+
+// weights returns the weights of the v1 and v2 destinations and the phase of the canary.
+func weights(t *testing.T, store model.ConfigStore) (int32, int32, Phase) {
+	t.Helper()
+	cfg := store.Get(schemas.VirtualService.Type, "reviews", "default")
+	var v1, v2 int32
+	for _, d := range cfg.Spec.(*networking.VirtualService).Http[0].Route {
+		switch d.Destination.Subset {
+		case "v1":
+			v1 = d.Weight
+		case "v2":
+			v2 = d.Weight
+		}
+	}
+	return v1, v2, Phase(cfg.Annotations[PhaseAnnotation])
+}
+
+func TestControllerPromotes(t *testing.T) {
+	// the canary destination is added to the route of the primary
+	store := newStore(t, routeDestination("v1", 0))
+	querier := &fakeQuerier{successRate: 99.9, latency: 0.2}
+	c := NewController(store, querier, []*Canary{testCanary()})
+
+	steps := []struct {
+		v1, v2 int32
+		phase  Phase
+	}{
+		{80, 20, PhaseProgressing},
+		{60, 40, PhaseProgressing},
+		{50, 50, PhaseProgressing},
+		{0, 100, PhasePromoted},
+		{0, 100, PhasePromoted},
+	}
+	for i, step := range steps {
+		if err := c.reconcile(c.canaries[0]); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if v1, v2, phase := weights(t, store); v1 != step.v1 || v2 != step.v2 || phase != step.phase {
+			t.Fatalf("step %d: got weights %d/%d in phase %q, want %d/%d in phase %q", i, v1, v2, phase,
+				step.v1, step.v2, step.phase)
+		}
+	}
+}
+
+func TestControllerSetCanaries(t *testing.T) {
+	store := newStore(t, routeDestination("v1", 100))
+	querier := &fakeQuerier{successRate: 99.9, latency: 0.2}
+	c := NewController(store, querier, nil)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		c.Run(stop)
+		close(done)
+	}()
+	defer func() {
+		close(stop)
+		<-done
+	}()
+
+	// the canaries added to a running controller are stepped
+	canary := testCanary()
+	canary.Interval = "10ms"
+	c.SetCanaries([]*Canary{canary})
+	for i := 0; i < 100; i++ {
+		if _, v2, _ := weights(t, store); v2 > 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("the canary was not stepped")
+}
+
+func TestControllerRollsBack(t *testing.T) {
+	cases := []struct {
+		name    string
+		querier *fakeQuerier
+	}{
+		{"success rate", &fakeQuerier{successRate: 95, latency: 0.2}},
+		{"latency", &fakeQuerier{successRate: 100, latency: 0.8}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newStore(t, routeDestination("v1", 80), routeDestination("v2", 20))
+			c := NewController(store, tt.querier, []*Canary{testCanary()})
+			for i := 0; i < 2; i++ {
+				if err := c.reconcile(c.canaries[0]); err != nil {
+					t.Fatal(err)
+				}
+				if v1, v2, phase := weights(t, store); v1 != 100 || v2 != 0 || phase != PhaseRolledBack {
+					t.Fatalf("got weights %d/%d in phase %q, want 100/0 in phase %q", v1, v2, phase, PhaseRolledBack)
+				}
+			}
+		})
+	}
+}
+
+func TestControllerWaitsForMetrics(t *testing.T) {
+	store := newStore(t, routeDestination("v1", 80), routeDestination("v2", 20))
+	c := NewController(store, &fakeQuerier{err: ErrNoData}, []*Canary{testCanary()})
+	if err := c.reconcile(c.canaries[0]); err != nil {
+		t.Fatal(err)
+	}
+	if v1, v2, phase := weights(t, store); v1 != 80 || v2 != 20 || phase != "" {
+		t.Fatalf("got weights %d/%d in phase %q, want unchanged", v1, v2, phase)
+	}
+}
+
+func TestControllerErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		store   model.ConfigStore
+		canary  func(*Canary)
+		wantErr string
+	}{
+		{
+			name:    "missing virtual service",
+			store:   newStore(t, routeDestination("v1", 0)),
+			canary:  func(c *Canary) { c.VirtualService = "ratings" },
+			wantErr: "not found",
+		},
+		{
+			name:    "missing route",
+			store:   newStore(t, routeDestination("v1", 0)),
+			canary:  func(c *Canary) { c.Route = "other" },
+			wantErr: `route "other" not found`,
+		},
+		{
+			name:    "missing primary",
+			store:   newStore(t, routeDestination("v2", 0)),
+			wantErr: "primary destination",
+		},
+		{
+			name:    "other destination",
+			store:   newStore(t, routeDestination("v1", 50), routeDestination("v3", 50)),
+			wantErr: "other than the primary and the canary",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			canary := testCanary()
+			if tt.canary != nil {
+				tt.canary(canary)
+			}
+			c := NewController(tt.store, &fakeQuerier{successRate: 100}, []*Canary{canary})
+			if err := c.reconcile(canary); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// ErrNoData is returned by the queries without any value, e.g. when the canary did not receive any request.
+var ErrNoData = errors.New("no data")
+
+// Querier runs the queries of a Prometheus compatible metrics backend.
+type Querier interface {
+	// Query returns the value of the instant query, or ErrNoData if the result is empty.
+	Query(ctx context.Context, query string) (float64, error)
+}
+
+// prometheusQuerier runs the queries with the Prometheus HTTP API.
+type prometheusQuerier struct {
+	api promv1.API
+}
+
+// NewPrometheusQuerier creates a querier of the Prometheus HTTP API at the address, e.g.
+// "http://prometheus.istio-system:9090".
+func NewPrometheusQuerier(address string) (Querier, error) {
+	client, err := api.NewClient(api.Config{Address: address})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the Prometheus client: %v", err)
+	}
+	return &prometheusQuerier{api: promv1.NewAPI(client)}, nil
+}
+
+// Query implements Querier.
+func (q *prometheusQuerier) Query(ctx context.Context, query string) (float64, error) {
+	val, _, err := q.api.Query(ctx, query, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("query %q failed: %v", query, err)
+	}
+	switch v := val.(type) {
+	case model.Vector:
+		if v.Len() < 1 {
+			return 0, ErrNoData
+		}
+		value := float64(v[0].Value)
+		// the ratios and quantiles of the metrics without samples are NaN
+		if math.IsNaN(value) {
+			return 0, ErrNoData
+		}
+		return value, nil
+	case *model.Scalar:
+		if math.IsNaN(float64(v.Value)) {
+			return 0, ErrNoData
+		}
+		return float64(v.Value), nil
+	default:
+		return 0, fmt.Errorf("query %q returned a %s, want a vector", query, val.Type())
+	}
+}
+
+// successRateQuery returns the query of the percentage of the requests of the canary which are not 5xx.
+func successRateQuery(c *Canary, interval time.Duration) string {
+	selector := workloadSelector(c)
+	return fmt.Sprintf(`sum(rate(istio_requests_total{%s,response_code!~"5.*"}[%s])) / `+
+		`sum(rate(istio_requests_total{%s}[%s])) * 100`,
+		selector, promDuration(interval), selector, promDuration(interval))
+}
+
+// latencyP99Query returns the query of the 99th percentile, in seconds, of the latency of the requests of
+// the canary.
+func latencyP99Query(c *Canary, interval time.Duration) string {
+	return fmt.Sprintf(`histogram_quantile(0.99, sum(rate(istio_request_duration_seconds_bucket{%s}[%s])) by (le))`,
+		workloadSelector(c), promDuration(interval))
+}
+
+func workloadSelector(c *Canary) string {
+	return fmt.Sprintf(`reporter="destination",destination_workload_namespace=%q,destination_workload=%q`,
+		c.Namespace, c.Workload)
+}
+
+func promDuration(d time.Duration) string {
+	if s := int64(d / time.Second); s > 0 {
+		return fmt.Sprintf("%ds", s)
+	}
+	return "1s"
+}
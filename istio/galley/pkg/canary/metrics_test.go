@@ -0,0 +1,86 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canary
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPrometheusQuerier(t *testing.T) {
+	results := map[string]string{
+		"value":  `{"resultType":"vector","result":[{"metric":{},"value":[1570000000,"99.5"]}]}`,
+		"empty":  `{"resultType":"vector","result":[]}`,
+		"nan":    `{"resultType":"vector","result":[{"metric":{},"value":[1570000000,"NaN"]}]}`,
+		"scalar": `{"resultType":"scalar","result":[1570000000,"2"]}`,
+		"matrix": `{"resultType":"matrix","result":[]}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, `{"status":"success","data":%s}`, results[r.Form.Get("query")])
+	}))
+	defer server.Close()
+
+	q, err := NewPrometheusQuerier(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		query   string
+		want    float64
+		wantErr bool
+		noData  bool
+	}{
+		{query: "value", want: 99.5},
+		{query: "empty", wantErr: true, noData: true},
+		{query: "nan", wantErr: true, noData: true},
+		{query: "scalar", want: 2},
+		{query: "matrix", wantErr: true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := q.Query(context.Background(), tt.query)
+			if (err != nil) != tt.wantErr || (err == ErrNoData) != tt.noData {
+				t.Fatalf("got error %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueries(t *testing.T) {
+	c := &Canary{Namespace: "default", Workload: "reviews-v2"}
+	if got, want := successRateQuery(c, time.Minute),
+		`sum(rate(istio_requests_total{reporter="destination",destination_workload_namespace="default",`+
+			`destination_workload="reviews-v2",response_code!~"5.*"}[60s])) / `+
+			`sum(rate(istio_requests_total{reporter="destination",destination_workload_namespace="default",`+
+			`destination_workload="reviews-v2"}[60s])) * 100`; got != want {
+		t.Errorf("got success rate query %s, want %s", got, want)
+	}
+	if got, want := latencyP99Query(c, 30*time.Second),
+		`histogram_quantile(0.99, sum(rate(istio_request_duration_seconds_bucket{reporter="destination",`+
+			`destination_workload_namespace="default",destination_workload="reviews-v2"}[30s])) by (le))`; got != want {
+		t.Errorf("got latency query %s, want %s", got, want)
+	}
+}
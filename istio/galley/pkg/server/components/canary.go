@@ -0,0 +1,106 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+
+	"istio.io/istio/galley/pkg/canary"
+	"istio.io/istio/galley/pkg/server/process"
+	"istio.io/istio/galley/pkg/server/settings"
+	"istio.io/pkg/filewatcher"
+)
+
+var (
+	canaryEventHandledProbe func()
+)
+
+// NewCanary returns a new canary analysis component, stepping the VirtualService routes of the canaries of
+// the CanaryConfigFile. The canaries are reloaded when the file changes, an invalid file keeps the previous ones.
+func NewCanary(a *settings.Args) process.Component {
+	var stop chan struct{}
+	var wg sync.WaitGroup
+
+	return process.ComponentFromFns(
+		// start
+		func() error {
+			canaries, err := canary.ReadCanaries(a.CanaryConfigFile)
+			if err != nil {
+				return err
+			}
+			querier, err := canary.NewPrometheusQuerier(a.PrometheusAddress)
+			if err != nil {
+				return err
+			}
+			store, err := newConfigStore(a.KubeConfig, a.DomainSuffix)
+			if err != nil {
+				return fmt.Errorf("unable to create the config client of the canaries: %v", err)
+			}
+
+			watcher := newFileWatcher()
+			if err := watcher.Add(a.CanaryConfigFile); err != nil {
+				return fmt.Errorf("unable to watch canaries file %q: %v", a.CanaryConfigFile, err)
+			}
+
+			c := canary.NewController(store, querier, canaries.Canaries)
+			stop = make(chan struct{})
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				c.Run(stop)
+			}()
+			go func() {
+				defer wg.Done()
+				watchCanaries(c, watcher, a.CanaryConfigFile, stop)
+			}()
+			scope.Infof("Canary analysis of %d canaries started", len(canaries.Canaries))
+			return nil
+		},
+		// stop
+		func() {
+			if stop != nil {
+				close(stop)
+				wg.Wait()
+				stop = nil
+			}
+		})
+}
+
+func watchCanaries(c *canary.Controller, watcher filewatcher.FileWatcher, canaryConfigFile string, stop <-chan struct{}) {
+	for {
+		select {
+		case e := <-watcher.Events(canaryConfigFile):
+			if e.Op&fsnotify.Write == fsnotify.Write || e.Op&fsnotify.Create == fsnotify.Create {
+				if canaries, err := canary.ReadCanaries(canaryConfigFile); err != nil {
+					scope.Errorf("Error reading canaries %q, keeping the previous ones: %v", canaryConfigFile, err)
+				} else {
+					c.SetCanaries(canaries.Canaries)
+					scope.Infof("Canary analysis of %d canaries reloaded", len(canaries.Canaries))
+				}
+			}
+			if canaryEventHandledProbe != nil {
+				canaryEventHandledProbe()
+			}
+		case e := <-watcher.Errors(canaryConfigFile):
+			scope.Errorf("error event while watching canaries file: %v", e)
+		case <-stop:
+			_ = watcher.Close()
+			return
+		}
+	}
+}
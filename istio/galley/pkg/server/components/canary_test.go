@@ -0,0 +1,133 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+	. "github.com/onsi/gomega"
+
+	"istio.io/istio/galley/pkg/canary"
+	"istio.io/istio/galley/pkg/server/settings"
+	"istio.io/istio/pilot/pkg/config/memory"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/schemas"
+	"istio.io/pkg/filewatcher"
+)
+
+const canaries = `
+canaries:
+- name: reviews
+  namespace: default
+  virtualService: reviews
+  primary: {host: reviews, subset: v1}
+  canary: {host: reviews, subset: v2}
+  workload: reviews-v2
+`
+
+func canaryArgs(t *testing.T) (*settings.Args, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "canary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := settings.DefaultArgs()
+	a.CanaryConfigFile = filepath.Join(dir, "canaries.yaml")
+	if err := ioutil.WriteFile(a.CanaryConfigFile, []byte(canaries), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return a, func() { _ = os.RemoveAll(dir) }
+}
+
+func TestCanary_Basic(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	defer resetPatchTable()
+	newConfigStore = func(string, string) (model.ConfigStore, error) {
+		return memory.Make(schemas.Istio), nil
+	}
+	a, cleanup := canaryArgs(t)
+	defer cleanup()
+
+	c := NewCanary(a)
+	err := c.Start()
+	g.Expect(err).To(BeNil())
+	c.Stop()
+	c.Stop() // nopanic
+}
+
+func TestCanary_Error(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	defer resetPatchTable()
+	newConfigStore = func(string, string) (model.ConfigStore, error) {
+		return nil, errors.New("ha")
+	}
+	a, cleanup := canaryArgs(t)
+	defer cleanup()
+
+	c := NewCanary(a)
+	err := c.Start()
+	g.Expect(err).NotTo(BeNil())
+	c.Stop() // nopanic
+
+	a.CanaryConfigFile = filepath.Join(filepath.Dir(a.CanaryConfigFile), "missing.yaml")
+	err = NewCanary(a).Start()
+	g.Expect(err).NotTo(BeNil())
+}
+
+func TestCanary_Reload(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	a, cleanup := canaryArgs(t)
+	defer cleanup()
+	defer func() {
+		canaryEventHandledProbe = nil
+	}()
+
+	newWatcher, fake := filewatcher.NewFakeWatcher(nil)
+	watcher := newWatcher()
+	if err := watcher.Add(a.CanaryConfigFile); err != nil {
+		t.Fatal(err)
+	}
+	c := canary.NewController(memory.Make(schemas.Istio), nil, nil)
+	stop := make(chan struct{})
+	defer close(stop)
+	go watchCanaries(c, watcher, a.CanaryConfigFile, stop)
+
+	write := func(content string) {
+		t.Helper()
+		if err := ioutil.WriteFile(a.CanaryConfigFile, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		handled := make(chan struct{})
+		canaryEventHandledProbe = func() { close(handled) }
+		fake.InjectEvent(a.CanaryConfigFile, fsnotify.Event{Name: a.CanaryConfigFile, Op: fsnotify.Write})
+		<-handled
+	}
+
+	write(canaries)
+	g.Expect(c.Canaries()).To(HaveLen(1))
+	g.Expect(c.Canaries()[0].Name).To(Equal("reviews"))
+
+	// an invalid file keeps the previous canaries
+	write("canaries: [{name: reviews}]")
+	g.Expect(c.Canaries()).To(HaveLen(1))
+}
@@ -23,6 +23,9 @@ import (
 	"istio.io/istio/galley/pkg/config/processor"
 	"istio.io/istio/galley/pkg/config/source/kube"
 	"istio.io/istio/galley/pkg/config/source/kube/fs"
+	"istio.io/istio/pilot/pkg/config/kube/crd/controller"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/schemas"
 	"istio.io/istio/pkg/mcp/monitoring"
 	"istio.io/pkg/filewatcher"
 )
@@ -38,8 +41,13 @@ var (
 	meshcfgNewFS        = func(path string) (event.Source, error) { return meshcfg.NewFS(path) }
 	processorInitialize = processor.Initialize
 	fsNew               = fs.New
+	newConfigStore      = newKubeConfigStore
 )
 
+func newKubeConfigStore(kubeConfig, domainSuffix string) (model.ConfigStore, error) {
+	return controller.NewClient(kubeConfig, "", schemas.Istio, domainSuffix, &model.DisabledLedger{})
+}
+
 func resetPatchTable() {
 	netListen = net.Listen
 	newInterfaces = kube.NewInterfacesFromConfigFile
@@ -50,4 +58,5 @@ func resetPatchTable() {
 	meshcfgNewFS = func(path string) (event.Source, error) { return meshcfg.NewFS(path) }
 	processorInitialize = processor.Initialize
 	fsNew = fs.New
+	newConfigStore = newKubeConfigStore
 }
@@ -55,6 +55,10 @@ func New(a *settings.Args) *Server {
 		topics = append(topics, t)
 	}
 
+	if a.CanaryConfigFile != "" {
+		s.host.Add(components.NewCanary(a))
+	}
+
 	mon := components.NewMonitoring(a.MonitoringPort)
 	s.host.Add(mon)
 
@@ -43,6 +43,8 @@ const (
 	defaultAccessListFile   = defaultConfigMapFolder + "accesslist.yaml"
 	defaultMeshConfigFile   = defaultMeshConfigFolder + "mesh"
 	defaultDomainSuffix     = "cluster.local"
+
+	defaultPrometheusAddress = "http://prometheus.istio-system:9090"
 )
 
 // Args contains the startup arguments to instantiate Galley.
@@ -145,6 +147,13 @@ type Args struct { // nolint:maligned
 
 	ValidationArgs *validation.WebhookParameters
 
+	// CanaryConfigFile is the YAML file of the canaries whose VirtualService routes are stepped by Galley.
+	// Leaving empty disables the canary analysis.
+	CanaryConfigFile string
+
+	// PrometheusAddress is the address of the Prometheus compatible API queried for the metrics of the canaries.
+	PrometheusAddress string
+
 	Liveness        probe.Options
 	Readiness       probe.Options
 	MonitoringPort  uint
@@ -183,6 +192,7 @@ func DefaultArgs() *Args {
 		PprofPort:                   9094,
 		WatchConfigFiles:            false,
 		EnableConfigAnalysis:        false,
+		PrometheusAddress:           defaultPrometheusAddress,
 		Liveness: probe.Options{
 			Path:           defaultLivenessProbeFilePath,
 			UpdateInterval: defaultProbeCheckInterval,
@@ -227,6 +237,8 @@ func (a *Args) String() string {
 	_, _ = fmt.Fprintf(buf, "KeepAlive.MaxServerConnectionAgeGrace: %v\n", a.KeepAlive.MaxServerConnectionAgeGrace)
 	_, _ = fmt.Fprintf(buf, "KeepAlive.Time: %v\n", a.KeepAlive.Time)
 	_, _ = fmt.Fprintf(buf, "KeepAlive.Timeout: %v\n", a.KeepAlive.Timeout)
+	_, _ = fmt.Fprintf(buf, "CanaryConfigFile: %s\n", a.CanaryConfigFile)
+	_, _ = fmt.Fprintf(buf, "PrometheusAddress: %s\n", a.PrometheusAddress)
 
 	return buf.String()
 }
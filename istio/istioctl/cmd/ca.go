@@ -0,0 +1,155 @@
+// Copyright 2019 Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	pb "istio.io/istio/security/proto"
+)
+
+type caClientOptions struct {
+	address    string
+	serverName string
+	certChain  string
+	key        string
+	rootCert   string
+	timeout    time.Duration
+}
+
+// caRevocationClientFactory creates the client used to reach the CA, it is replaced in tests.
+var caRevocationClientFactory = newCARevocationClient
+
+func caCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ca",
+		Short: "Interact with the Istio certificate authority",
+	}
+	cmd.AddCommand(caRevokeCmd())
+	return cmd
+}
+
+func caRevokeCmd() *cobra.Command {
+	var (
+		opts     caClientOptions
+		serials  []string
+		spiffeID string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "revoke",
+		Short: "Revoke workload certificates issued by Citadel",
+		Long: `Revokes workload certificates issued by Citadel, by serial number or for a whole identity.
+Revoked certificates are listed in the certificate revocation list Citadel publishes, which node agents
+deliver to the proxies when they run with ENABLE_CRL set. The client certificate must belong to one of the
+identities passed to Citadel with --revocation-admins.`,
+		Example: `
+# Revoke a certificate by serial number
+istioctl experimental ca revoke --serial 3f:a1:07 --cert-chain admin-cert.pem --key admin-key.pem --root-cert root-cert.pem
+
+# Revoke all the certificates issued to a service account
+istioctl experimental ca revoke --spiffe-id spiffe://cluster.local/ns/foo/sa/bar \
+  --cert-chain admin-cert.pem --key admin-key.pem --root-cert root-cert.pem`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(serials) == 0 && spiffeID == "" {
+				return errors.New("at least one of --serial or --spiffe-id must be specified")
+			}
+			client, err := caRevocationClientFactory(&opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+			defer cancel()
+			return revokeCertificates(ctx, client, serials, spiffeID, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.PersistentFlags().StringSliceVar(&serials, "serial", nil,
+		"Hexadecimal serial numbers of the certificates to revoke")
+	cmd.PersistentFlags().StringVar(&spiffeID, "spiffe-id", "",
+		"Identity whose unexpired certificates are all revoked")
+	cmd.PersistentFlags().StringVar(&opts.address, "address", "istio-citadel.istio-system:8060",
+		"Address of the Citadel gRPC server")
+	cmd.PersistentFlags().StringVar(&opts.serverName, "server-name", "istio-citadel",
+		"Name expected in the Citadel server certificate")
+	cmd.PersistentFlags().StringVar(&opts.certChain, "cert-chain", "",
+		"Client certificate chain file, authenticating the caller to Citadel")
+	cmd.PersistentFlags().StringVar(&opts.key, "key", "", "Client private key file")
+	cmd.PersistentFlags().StringVar(&opts.rootCert, "root-cert", "", "Root certificate file of the mesh")
+	cmd.PersistentFlags().DurationVar(&opts.timeout, "timeout", 30*time.Second,
+		"Timeout of the revocation request")
+
+	return cmd
+}
+
+func revokeCertificates(ctx context.Context, client pb.IstioCertificateRevocationServiceClient,
+	serials []string, spiffeID string, w io.Writer) error {
+	resp, err := client.RevokeCertificate(ctx, &pb.RevokeCertificateRequest{
+		SerialNumbers: serials,
+		SubjectId:     spiffeID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke certificates: %v", err)
+	}
+	if len(resp.RevokedSerialNumbers) == 0 {
+		_, _ = fmt.Fprintln(w, "No certificate newly revoked")
+		return nil
+	}
+	for _, serial := range resp.RevokedSerialNumbers {
+		_, _ = fmt.Fprintf(w, "Revoked certificate %s\n", serial)
+	}
+	return nil
+}
+
+func newCARevocationClient(opts *caClientOptions) (pb.IstioCertificateRevocationServiceClient, error) {
+	if opts.certChain == "" || opts.key == "" || opts.rootCert == "" {
+		return nil, errors.New("--cert-chain, --key and --root-cert must be specified")
+	}
+	cert, err := tls.LoadX509KeyPair(opts.certChain, opts.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the client certificate: %v", err)
+	}
+	rootCert, err := ioutil.ReadFile(opts.rootCert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the root certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(rootCert) {
+		return nil, fmt.Errorf("no certificate found in %s", opts.rootCert)
+	}
+
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		ServerName:   opts.serverName,
+	})
+	conn, err := grpc.Dial(opts.address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %v", opts.address, err)
+	}
+	return pb.NewIstioCertificateRevocationServiceClient(conn), nil
+}
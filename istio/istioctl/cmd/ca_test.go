@@ -0,0 +1,73 @@
+// Copyright 2019 Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	pb "istio.io/istio/security/proto"
+)
+
+type fakeRevocationClient struct {
+	revoked []string
+}
+
+func (c *fakeRevocationClient) RevokeCertificate(ctx context.Context, in *pb.RevokeCertificateRequest,
+	opts ...grpc.CallOption) (*pb.RevokeCertificateResponse, error) {
+	if in.SubjectId == "spiffe://cluster.local/ns/foo/sa/denied" {
+		return nil, fmt.Errorf("permission denied")
+	}
+	return &pb.RevokeCertificateResponse{RevokedSerialNumbers: c.revoked}, nil
+}
+
+func (c *fakeRevocationClient) GetCertificateRevocationList(ctx context.Context, in *pb.CertificateRevocationListRequest,
+	opts ...grpc.CallOption) (*pb.CertificateRevocationListResponse, error) {
+	return &pb.CertificateRevocationListResponse{}, nil
+}
+
+func TestCARevoke(t *testing.T) {
+	caRevocationClientFactory = func(*caClientOptions) (pb.IstioCertificateRevocationServiceClient, error) {
+		return &fakeRevocationClient{revoked: []string{"1f", "2a"}}, nil
+	}
+	defer func() { caRevocationClientFactory = newCARevocationClient }()
+
+	cases := []execTestCase{
+		{
+			args:           strings.Split("x ca revoke", " "),
+			expectedString: "at least one of --serial or --spiffe-id must be specified",
+			wantException:  true,
+		},
+		{
+			args:           strings.Split("x ca revoke --serial 1f,2a", " "),
+			expectedOutput: "Revoked certificate 1f\nRevoked certificate 2a\n",
+		},
+		{
+			args:           strings.Split("x ca revoke --spiffe-id spiffe://cluster.local/ns/foo/sa/denied", " "),
+			expectedString: "failed to revoke certificates: permission denied",
+			wantException:  true,
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case %d %s", i, strings.Join(c.args, " ")), func(t *testing.T) {
+			verifyExecTestOutput(t, c)
+		})
+	}
+}
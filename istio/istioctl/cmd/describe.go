@@ -18,7 +18,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -606,6 +608,93 @@ func getAuthenticationz(kubeClient istioctl_kubernetes.ExecClient, podName, ns s
 	return &debug, nil
 }
 
+// getOutlierz returns the outlier detection state of the destinations of the hostname reported to the Pilot
+// instances. The reports of a proxy to several instances are merged, keeping the most recent one.
+func getOutlierz(kubeClient istioctl_kubernetes.ExecClient, hostname string) ([]*model.DestinationOutlierStatus, error) {
+	results, err := kubeClient.AllPilotsDiscoveryDo(istioNamespace, "GET",
+		fmt.Sprintf("/debug/outlierz?hostname=%s", hostname), nil)
+	if err != nil {
+		return nil, err
+	}
+	byKey := map[string]*model.DestinationOutlierStatus{}
+	proxies := map[string]map[string]*model.ProxyOutlierStatus{}
+	for _, result := range results {
+		var destinations []*model.DestinationOutlierStatus
+		if err := json.Unmarshal(result, &destinations); err != nil {
+			// Ignore invalid responses, e.g. from Pilot instances without /debug/outlierz
+			continue
+		}
+		for _, d := range destinations {
+			if d == nil || string(d.Hostname) != hostname {
+				continue
+			}
+			key := model.BuildSubsetKey(model.TrafficDirectionOutbound, d.Subset, d.Hostname, d.Port)
+			if _, ok := byKey[key]; !ok {
+				byKey[key] = &model.DestinationOutlierStatus{Hostname: d.Hostname, Subset: d.Subset, Port: d.Port}
+				proxies[key] = map[string]*model.ProxyOutlierStatus{}
+			}
+			for _, p := range d.Proxies {
+				if prev, ok := proxies[key][p.Proxy]; !ok || p.Reported.After(prev.Reported) {
+					proxies[key][p.Proxy] = p
+				}
+			}
+		}
+	}
+
+	out := make([]*model.DestinationOutlierStatus, 0, len(byKey))
+	for key, d := range byKey {
+		for _, p := range proxies[key] {
+			d.Proxies = append(d.Proxies, p)
+		}
+		sort.Slice(d.Proxies, func(i, j int) bool { return d.Proxies[i].Proxy < d.Proxies[j].Proxy })
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Subset != out[j].Subset {
+			return out[i].Subset < out[j].Subset
+		}
+		return out[i].Port < out[j].Port
+	})
+	return out, nil
+}
+
+// printOutliers prints the ejections and the circuit breaker overflows of the destinations on the port, and warns
+// when the pod is ejected by some proxies.
+func printOutliers(writer io.Writer, pod *v1.Pod, destinations []*model.DestinationOutlierStatus, port v1.ServicePort) {
+	for _, d := range destinations {
+		if d.Port != int(port.Port) {
+			continue
+		}
+		var ejected, ejections, connections, pending, retries uint64
+		for _, p := range d.Proxies {
+			ejected += uint64(len(p.EjectedEndpoints))
+			ejections += p.Ejections
+			connections += p.ConnectionOverflows
+			pending += p.PendingRequestOverflows
+			retries += p.RetryOverflows
+		}
+		name := "Outliers"
+		if d.Subset != "" {
+			name = fmt.Sprintf("Outliers of subset %s", d.Subset)
+		}
+		fmt.Fprintf(writer, "%s: reported by %d proxies, %d endpoints ejected (%d ejections)\n",
+			name, len(d.Proxies), ejected, ejections)
+		if connections+pending+retries > 0 {
+			fmt.Fprintf(writer, "   Circuit breaker overflows: %d connections, %d pending requests, %d retries\n",
+				connections, pending, retries)
+		}
+		if pod == nil || pod.Status.PodIP == "" {
+			continue
+		}
+		for endpoint, proxies := range d.EjectedEndpoints() {
+			if ip, _, err := net.SplitHostPort(endpoint); err == nil && ip == pod.Status.PodIP {
+				fmt.Fprintf(writer, "WARNING: pod %s (%s) is ejected by %s\n",
+					kname(pod.ObjectMeta), endpoint, strings.Join(proxies, ", "))
+			}
+		}
+	}
+}
+
 func authnMatchSvc(debug envoy_v2.AuthenticationDebug, svc v1.Service, port v1.ServicePort) bool {
 	return debug.Host == svcFQDN(svc) && debug.Port == int(port.Port)
 }
@@ -1367,6 +1456,8 @@ func describePodServices(writer io.Writer, kubeClient istioctl_kubernetes.ExecCl
 			fmt.Fprintf(writer, "--------------------\n")
 		}
 		printService(writer, svc, pod, istioVersion)
+		// Keep going on error, the outliers are only reported by the proxies of recent versions
+		outliers, _ := getOutlierz(kubeClient, svcFQDN(svc))
 
 		for _, port := range svc.Spec.Ports {
 			matchingSubsets := []string{}
@@ -1403,6 +1494,10 @@ func describePodServices(writer io.Writer, kubeClient istioctl_kubernetes.ExecCl
 				}
 			}
 
+			if len(outliers) > 0 {
+				printOutliers(writer, pod, outliers, port)
+			}
+
 			policies, _ := getIstioRBACPolicies(&cd, port.Port)
 			if len(policies) > 0 {
 				if len(svc.Spec.Ports) > 1 {
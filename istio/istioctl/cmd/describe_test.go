@@ -705,6 +705,55 @@ VirtualService: bookinfo
 	}
 }
 
+func TestDescribeOutliers(t *testing.T) {
+	client := mockExecConfig{results: map[string][]byte{
+		"istio-pilot-1": []byte(`[
+{"hostname": "reviews.default.svc.cluster.local", "subset": "v1", "port": 9080, "proxies": [
+  {"proxy": "productpage-v1.default", "reported": "2019-11-05T10:00:00Z", "ejectedEndpoints": ["10.0.0.1:9080"], "ejections": 1},
+  {"proxy": "ratings-v1.default", "reported": "2019-11-05T10:00:00Z", "ejections": 0, "retryOverflows": 3}
+]},
+{"hostname": "details.default.svc.cluster.local", "port": 9080, "proxies": [
+  {"proxy": "productpage-v1.default", "reported": "2019-11-05T10:00:00Z", "ejectedEndpoints": ["10.0.0.2:9080"], "ejections": 1}
+]}
+]`),
+		"istio-pilot-2": []byte(`[
+{"hostname": "reviews.default.svc.cluster.local", "subset": "v1", "port": 9080, "proxies": [
+  {"proxy": "productpage-v1.default", "reported": "2019-11-05T10:01:00Z", "ejectedEndpoints": ["10.0.0.1:9080", "10.0.0.3:9080"], "ejections": 2,
+   "connectionOverflows": 1, "pendingRequestOverflows": 2}
+]}
+]`),
+		"istio-pilot-3": []byte(`404 page not found`),
+	}}
+
+	destinations, err := getOutlierz(client, "reviews.default.svc.cluster.local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(destinations) != 1 || len(destinations[0].Proxies) != 2 {
+		t.Fatalf("got %d destinations, want the reviews v1 destination reported by 2 proxies", len(destinations))
+	}
+
+	pod := &coreV1.Pod{
+		ObjectMeta: metaV1.ObjectMeta{Name: "reviews-v1-5b7f94f9bc-wp5tb", Namespace: "default"},
+		Status:     coreV1.PodStatus{PodIP: "10.0.0.3"},
+	}
+	var out bytes.Buffer
+	printOutliers(&out, pod, destinations, coreV1.ServicePort{Port: 9080})
+	want := `Outliers of subset v1: reported by 2 proxies, 2 endpoints ejected (2 ejections)
+   Circuit breaker overflows: 1 connections, 2 pending requests, 3 retries
+WARNING: pod reviews-v1-5b7f94f9bc-wp5tb (10.0.0.3:9080) is ejected by productpage-v1.default
+`
+	if out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	printOutliers(&out, pod, destinations, coreV1.ServicePort{Port: 9081})
+	if out.Len() != 0 {
+		t.Errorf("got %q for another port, want nothing", out.String())
+	}
+}
+
 func mockInterfaceFactoryGenerator(k8sConfigs []runtime.Object) func(kubeconfig string) (kubernetes.Interface, error) {
 	outFactory := func(_ string) (kubernetes.Interface, error) {
 		client := fake.NewSimpleClientset(k8sConfigs...)
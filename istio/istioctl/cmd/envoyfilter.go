@@ -0,0 +1,296 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/istioctl/pkg/util/configdump"
+	"istio.io/istio/istioctl/pkg/util/handlers"
+	"istio.io/istio/pilot/pkg/config/kube/crd"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/core/v1alpha3/envoyfilter"
+	configlabels "istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+func envoyFilterCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "envoyfilter",
+		Aliases: []string{"ef"},
+		Short:   "Commands to troubleshoot EnvoyFilter configurations",
+	}
+	cmd.AddCommand(envoyFilterPreviewCmd())
+	return cmd
+}
+
+func envoyFilterPreviewCmd() *cobra.Command {
+	var (
+		podName        string
+		configDumpFile string
+		diffContext    int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "preview <envoyfilter-file>",
+		Short: "Preview the effect of an EnvoyFilter on the config of a proxy",
+		Long: `Applies the patches of the EnvoyFilters of a file to the current config of a proxy, with the same code
+Pilot uses to generate the config, without applying the EnvoyFilters to the cluster. It lists the resources
+matched by each patch, flags the patches that match nothing, and prints the diff of the changed, added and
+removed listeners, clusters and routes.
+
+The config of the proxy is read from its config dump, which already includes the effect of the EnvoyFilters
+applied to the cluster. Neither the workload selector of the EnvoyFilters nor the proxy match of the patches
+are enforced: the preview shows what the patches would do if they were applied to the proxy.`,
+		Example: `  # Preview an EnvoyFilter on the proxy of a pod
+  istioctl experimental envoyfilter preview lua-filter.yaml --pod productpage-v1-7bbd79f8fd-k6j79.default
+
+  # Preview an EnvoyFilter on a config dump file
+  kubectl exec productpage-v1-7bbd79f8fd-k6j79 -c istio-proxy -- curl localhost:15000/config_dump > config_dump.json
+  istioctl experimental envoyfilter preview lua-filter.yaml --config-dump config_dump.json`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				cmd.Println(cmd.UsageString())
+				return errors.New("preview requires an EnvoyFilter file")
+			}
+			if (podName == "") == (configDumpFile == "") {
+				cmd.Println(cmd.UsageString())
+				return errors.New("preview requires either --pod or --config-dump")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			envoyFilters, err := readEnvoyFilters(args[0])
+			if err != nil {
+				return err
+			}
+
+			var dump *configdump.Wrapper
+			if configDumpFile != "" {
+				if dump, err = getConfigDumpFromFile(configDumpFile); err != nil {
+					return fmt.Errorf("failed to get config dump from file %s: %v", configDumpFile, err)
+				}
+			} else {
+				name, ns := handlers.InferPodInfo(podName, handlers.HandleNamespace(namespace, defaultNamespace))
+				if dump, err = getEnvoyConfigDump(name, ns); err != nil {
+					return err
+				}
+			}
+
+			proxyType, proxyLabels, current, err := proxyConfigFromDump(dump)
+			if err != nil {
+				return err
+			}
+
+			w := cmd.OutOrStdout()
+			patched := current
+			for i := range envoyFilters {
+				ef := &envoyFilters[i]
+				var reports []*envoyfilter.PatchReport
+				if patched, reports, err = envoyfilter.Preview(ef, proxyType, patched); err != nil {
+					return err
+				}
+				printPatchReports(w, ef, proxyLabels, reports)
+			}
+			return printProxyConfigDiff(w, current, patched, diffContext)
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&podName, "pod", "", "Pod of the proxy, as <pod-name>[.<namespace>]")
+	cmd.PersistentFlags().StringVar(&configDumpFile, "config-dump", "",
+		"Envoy config dump file of the proxy, used instead of --pod")
+	cmd.PersistentFlags().IntVar(&diffContext, "context", 3, "Number of context lines of the diff")
+
+	return cmd
+}
+
+func readEnvoyFilters(filename string) ([]model.Config, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	configs, _, err := crd.ParseInputs(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", filename, err)
+	}
+	var out []model.Config
+	for _, c := range configs {
+		if c.Type == schemas.EnvoyFilter.Type {
+			out = append(out, c)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no EnvoyFilter in %s", filename)
+	}
+	return out, nil
+}
+
+func getEnvoyConfigDump(podName, podNamespace string) (*configdump.Wrapper, error) {
+	kubeClient, err := clientExecFactory(kubeconfig, configContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create k8s client: %v", err)
+	}
+	data, err := kubeClient.EnvoyDo(podName, podNamespace, "GET", "config_dump", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proxy config for %s.%s: %v", podName, podNamespace, err)
+	}
+	dump := &configdump.Wrapper{}
+	if err := dump.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal proxy config: %v", err)
+	}
+	return dump, nil
+}
+
+// proxyConfigFromDump gets the type and the labels of a proxy, and the listeners, clusters and routes
+// Pilot sent to it, from its config dump.
+func proxyConfigFromDump(dump *configdump.Wrapper) (model.NodeType, configlabels.Instance, *envoyfilter.ProxyConfig, error) {
+	bootstrap, err := dump.GetBootstrapConfigDump()
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to get the bootstrap config: %v", err)
+	}
+	node := bootstrap.GetBootstrap().GetNode()
+	proxyType := model.NodeType(strings.Split(node.GetId(), "~")[0])
+	if !model.IsApplicationNodeType(proxyType) {
+		return "", nil, nil, fmt.Errorf("unknown proxy type of node %q", node.GetId())
+	}
+	// the labels are unknown for proxies that do not report them in their metadata
+	var proxyLabels configlabels.Instance
+	if l, ok := node.GetMetadata().GetFields()["LABELS"]; ok {
+		proxyLabels = configlabels.Instance{}
+		for k, v := range l.GetStructValue().GetFields() {
+			proxyLabels[k] = v.GetStringValue()
+		}
+	}
+
+	out := &envoyfilter.ProxyConfig{}
+	listenerDump, err := dump.GetDynamicListenerDump(true)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to get the listeners: %v", err)
+	}
+	for _, l := range listenerDump.DynamicListeners {
+		out.Listeners = append(out.Listeners, l.ActiveState.Listener)
+	}
+	clusterDump, err := dump.GetDynamicClusterDump(true)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to get the clusters: %v", err)
+	}
+	for _, c := range clusterDump.DynamicActiveClusters {
+		out.Clusters = append(out.Clusters, c.Cluster)
+	}
+	// proxies without HTTP routes do not have a route section
+	if routeDump, err := dump.GetDynamicRouteDump(true); err == nil {
+		for _, r := range routeDump.DynamicRouteConfigs {
+			out.Routes = append(out.Routes, r.RouteConfig)
+		}
+	}
+	return proxyType, proxyLabels, out, nil
+}
+
+func printPatchReports(w io.Writer, ef *model.Config, proxyLabels configlabels.Instance, reports []*envoyfilter.PatchReport) {
+	_, _ = fmt.Fprintf(w, "EnvoyFilter %s.%s\n", ef.Name, ef.Namespace)
+	spec := ef.Spec.(*networking.EnvoyFilter)
+	if spec.WorkloadSelector != nil && proxyLabels != nil && !configlabels.Instance(spec.WorkloadSelector.Labels).SubsetOf(proxyLabels) {
+		_, _ = fmt.Fprintf(w, "  WARNING: the workload selector %v does not select the proxy\n",
+			configlabels.Instance(spec.WorkloadSelector.Labels))
+	}
+	for _, r := range reports {
+		_, _ = fmt.Fprintf(w, "  patch %d: %s %s\n", r.Index, r.Operation, r.ApplyTo)
+		if len(r.Matched) == 0 {
+			_, _ = fmt.Fprintln(w, "    WARNING: matched nothing")
+		}
+		for _, m := range r.Matched {
+			_, _ = fmt.Fprintf(w, "    matched %s\n", m)
+		}
+	}
+	_, _ = fmt.Fprintln(w)
+}
+
+// printProxyConfigDiff prints the unified diff of each listener, cluster and route changed by the patches.
+func printProxyConfigDiff(w io.Writer, current, patched *envoyfilter.ProxyConfig, diffContext int) error {
+	before, after := proxyConfigResources(current), proxyConfigResources(patched)
+	names := make([]string, 0, len(before)+len(after))
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changed := false
+	for _, name := range names {
+		a, b := before[name], after[name]
+		if a != nil && b != nil && proto.Equal(a, b) {
+			continue
+		}
+		changed = true
+		diff := difflib.UnifiedDiff{
+			A:        difflib.SplitLines(marshalResource(a)),
+			B:        difflib.SplitLines(marshalResource(b)),
+			FromFile: name + " (current)",
+			ToFile:   name + " (patched)",
+			Context:  diffContext,
+		}
+		text, err := difflib.GetUnifiedDiffString(diff)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(w, text)
+	}
+	if !changed {
+		_, _ = fmt.Fprintln(w, "No change to the proxy config")
+	}
+	return nil
+}
+
+func proxyConfigResources(config *envoyfilter.ProxyConfig) map[string]proto.Message {
+	out := map[string]proto.Message{}
+	for _, l := range config.Listeners {
+		out["listener "+l.Name] = l
+	}
+	for _, c := range config.Clusters {
+		out["cluster "+c.Name] = c
+	}
+	for _, r := range config.Routes {
+		out["route "+r.Name] = r
+	}
+	return out
+}
+
+func marshalResource(resource proto.Message) string {
+	if resource == nil {
+		return ""
+	}
+	buf := &bytes.Buffer{}
+	if err := (&jsonpb.Marshaler{Indent: "  "}).Marshal(buf, resource); err != nil {
+		return err.Error() + "\n"
+	}
+	return buf.String() + "\n"
+}
@@ -0,0 +1,32 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestEnvoyFilterPreview(t *testing.T) {
+	runCommandWantOutput("experimental envoyfilter preview testdata/envoyfilter/productpage-envoyfilter.yaml "+
+		"--config-dump testdata/authz/productpage_config_dump.json", "testdata/envoyfilter/productpage.golden", t)
+}
+
+func TestEnvoyFilterPreviewArgs(t *testing.T) {
+	runCommandWantError("experimental envoyfilter preview testdata/envoyfilter/productpage-envoyfilter.yaml",
+		"preview requires either --pod or --config-dump", t)
+	runCommandWantError("experimental envoyfilter preview testdata/authz/converter/service-bookinfo.yaml "+
+		"--config-dump testdata/authz/productpage_config_dump.json",
+		"no EnvoyFilter in testdata/authz/converter/service-bookinfo.yaml", t)
+}
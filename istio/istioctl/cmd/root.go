@@ -150,6 +150,8 @@ debug and diagnose their Istio mesh.
 	experimentalCmd.AddCommand(removeFromMeshCmd())
 	experimentalCmd.AddCommand(softGraduatedCmd(Analyze()))
 	experimentalCmd.AddCommand(waitCmd())
+	experimentalCmd.AddCommand(caCmd())
+	experimentalCmd.AddCommand(envoyFilterCmd())
 
 	postInstallCmd.AddCommand(Webhook())
 	experimentalCmd.AddCommand(postInstallCmd)
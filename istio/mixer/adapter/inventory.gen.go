@@ -28,9 +28,11 @@ import (
 	memquota "istio.io/istio/mixer/adapter/memquota"
 	noop "istio.io/istio/mixer/adapter/noop"
 	opa "istio.io/istio/mixer/adapter/opa"
+	otlp "istio.io/istio/mixer/adapter/otlp"
 	prometheus "istio.io/istio/mixer/adapter/prometheus"
 	redisquota "istio.io/istio/mixer/adapter/redisquota"
 	solarwinds "istio.io/istio/mixer/adapter/solarwinds"
+	spool "istio.io/istio/mixer/adapter/spool"
 	stackdriver "istio.io/istio/mixer/adapter/stackdriver"
 	statsd "istio.io/istio/mixer/adapter/statsd"
 	stdio "istio.io/istio/mixer/adapter/stdio"
@@ -52,9 +54,11 @@ func Inventory() []adptr.InfoFn {
 		memquota.GetInfo,
 		noop.GetInfo,
 		opa.GetInfo,
+		otlp.GetInfo,
 		prometheus.GetInfo,
 		redisquota.GetInfo,
 		solarwinds.GetInfo,
+		spool.GetInfo,
 		stackdriver.GetInfo,
 		statsd.GetInfo,
 		stdio.GetInfo,
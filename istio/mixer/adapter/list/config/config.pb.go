@@ -3,8 +3,8 @@
 
 // The `list` adapter makes it possible to perform simple whitelist or blacklist
 // checks. You can configure the adapter with the list to check, or you can point
-// it to a URL from where the list should be fetched. Lists can be simple strings,
-// IP addresses, or regex patterns.
+// it to a URL from where the list should be fetched, to a local file, or to a
+// Kubernetes ConfigMap. Lists can be simple strings, IP addresses, or regex patterns.
 //
 // This adapter supports the [listentry template](https://istio.io/docs/reference/config/policy-and-telemetry/templates/listentry/).
 
@@ -72,6 +72,7 @@ func (Params_ListEntryType) EnumDescriptor() ([]byte, []int) {
 // Configuration format for the `list` adapter.
 type Params struct {
 	// Where to find the list to check against. This may be omitted for a completely local list.
+	// At most one of `providerUrl`, `filePath` and `configMap` may be set.
 	ProviderUrl string `protobuf:"bytes,1,opt,name=provider_url,json=providerUrl,proto3" json:"provider_url,omitempty"`
 	// Determines how often the provider is polled for
 	// an updated list
@@ -93,6 +94,12 @@ type Params struct {
 	EntryType Params_ListEntryType `protobuf:"varint,7,opt,name=entry_type,json=entryType,proto3,enum=adapter.list.config.Params_ListEntryType" json:"entry_type,omitempty"`
 	// Whether the list operates as a blacklist or a whitelist.
 	Blacklist bool `protobuf:"varint,8,opt,name=blacklist,proto3" json:"blacklist,omitempty"`
+	// Path of a local file holding the list. The file is watched for changes and
+	// the list is reloaded whenever the file is written or replaced.
+	FilePath string `protobuf:"bytes,9,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`
+	// Kubernetes ConfigMap holding the list. The ConfigMap is watched and the list
+	// is reloaded whenever the ConfigMap changes.
+	ConfigMap *Params_ConfigMapSource `protobuf:"bytes,10,opt,name=config_map,json=configMap,proto3" json:"config_map,omitempty"`
 }
 
 func (m *Params) Reset()      { *m = Params{} }
@@ -127,9 +134,55 @@ func (m *Params) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_Params proto.InternalMessageInfo
 
+// Describes a Kubernetes ConfigMap holding a list.
+type Params_ConfigMapSource struct {
+	// Namespace of the ConfigMap.
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// Name of the ConfigMap.
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Key of the ConfigMap entry holding the list.
+	Key string `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	// Path of the kubeconfig file used to connect to the cluster. If empty,
+	// the in-cluster configuration is used.
+	KubeconfigPath string `protobuf:"bytes,4,opt,name=kubeconfig_path,json=kubeconfigPath,proto3" json:"kubeconfig_path,omitempty"`
+}
+
+func (m *Params_ConfigMapSource) Reset()      { *m = Params_ConfigMapSource{} }
+func (*Params_ConfigMapSource) ProtoMessage() {}
+func (*Params_ConfigMapSource) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cb3210745c49e8, []int{0, 0}
+}
+func (m *Params_ConfigMapSource) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Params_ConfigMapSource) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Params_ConfigMapSource.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Params_ConfigMapSource) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Params_ConfigMapSource.Merge(m, src)
+}
+func (m *Params_ConfigMapSource) XXX_Size() int {
+	return m.Size()
+}
+func (m *Params_ConfigMapSource) XXX_DiscardUnknown() {
+	xxx_messageInfo_Params_ConfigMapSource.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Params_ConfigMapSource proto.InternalMessageInfo
+
 func init() {
 	proto.RegisterEnum("adapter.list.config.Params_ListEntryType", Params_ListEntryType_name, Params_ListEntryType_value)
 	proto.RegisterType((*Params)(nil), "adapter.list.config.Params")
+	proto.RegisterType((*Params_ConfigMapSource)(nil), "adapter.list.config.Params.ConfigMapSource")
 }
 
 func init() {
@@ -137,37 +190,44 @@ func init() {
 }
 
 var fileDescriptor_46cb3210745c49e8 = []byte{
-	// 473 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x91, 0xb1, 0x6e, 0xd3, 0x40,
-	0x1c, 0xc6, 0xef, 0x9a, 0x26, 0x8d, 0x2f, 0x05, 0xcc, 0xc1, 0x60, 0xaa, 0xea, 0x6a, 0x3a, 0x20,
-	0xc3, 0x60, 0x4b, 0x45, 0xec, 0xb4, 0x8d, 0x55, 0x2c, 0xa1, 0xa8, 0xb2, 0x53, 0x40, 0x2c, 0x96,
-	0xe3, 0x5c, 0xdc, 0x13, 0xae, 0xcf, 0x3a, 0x9f, 0x23, 0xb2, 0xf1, 0x02, 0x48, 0x8c, 0x3c, 0x02,
-	0x8f, 0x92, 0x31, 0x63, 0x27, 0x20, 0xce, 0xc2, 0xd8, 0x47, 0x40, 0x8e, 0xed, 0x46, 0x48, 0x0c,
-	0x30, 0xf9, 0xaf, 0xef, 0xbe, 0xdf, 0x7d, 0x9f, 0xff, 0x87, 0x9e, 0x5c, 0xb1, 0x8f, 0x54, 0x58,
-	0xc1, 0x38, 0x48, 0x25, 0x15, 0x56, 0xcc, 0x32, 0x69, 0x85, 0x3c, 0x99, 0xb0, 0xa8, 0xfe, 0x98,
-	0xa9, 0xe0, 0x92, 0xe3, 0x07, 0xb5, 0xc3, 0x2c, 0x1d, 0x66, 0x75, 0xb4, 0x47, 0x22, 0xce, 0xa3,
-	0x98, 0x5a, 0x6b, 0xcb, 0x28, 0x9f, 0x58, 0xe3, 0x5c, 0x04, 0x92, 0xf1, 0xa4, 0x82, 0xf6, 0x1e,
-	0x46, 0x3c, 0xe2, 0xeb, 0xd1, 0x2a, 0xa7, 0x4a, 0x3d, 0xfc, 0xbc, 0x8d, 0x3a, 0xe7, 0x81, 0x08,
-	0xae, 0x32, 0xfc, 0x18, 0xed, 0xa6, 0x82, 0x4f, 0xd9, 0x98, 0x0a, 0x3f, 0x17, 0xb1, 0x06, 0x75,
-	0x68, 0x28, 0x6e, 0xaf, 0xd1, 0x2e, 0x44, 0x8c, 0x07, 0x48, 0x15, 0x74, 0x22, 0x68, 0x76, 0xe9,
-	0xb3, 0x44, 0x52, 0x31, 0x0d, 0x62, 0x6d, 0x4b, 0x87, 0x46, 0xef, 0xe8, 0x91, 0x59, 0xc5, 0x9b,
-	0x4d, 0xbc, 0xd9, 0xaf, 0xe3, 0x4f, 0xba, 0xf3, 0xef, 0x07, 0xe0, 0xeb, 0x8f, 0x03, 0xe8, 0xde,
-	0xab, 0x61, 0xa7, 0x66, 0xf1, 0x0b, 0xd4, 0x92, 0x32, 0xd6, 0x5a, 0xff, 0x7e, 0x45, 0xe9, 0x2f,
-	0x6b, 0x84, 0x41, 0x78, 0xc9, 0x92, 0x68, 0x53, 0x63, 0xfb, 0x3f, 0x6a, 0xd4, 0xf0, 0x6d, 0x8d,
-	0x67, 0xe8, 0x7e, 0x73, 0x5f, 0x9e, 0x51, 0x3f, 0xe4, 0x79, 0x22, 0xb5, 0xb6, 0x0e, 0x8d, 0xf6,
-	0xad, 0xf7, 0x22, 0xa3, 0xa7, 0xa5, 0x8c, 0xf7, 0x91, 0xc2, 0xa7, 0x54, 0x08, 0x36, 0xa6, 0x99,
-	0xd6, 0xd1, 0x5b, 0x86, 0xe2, 0x6e, 0x04, 0xfc, 0x0a, 0x21, 0x9a, 0x48, 0x31, 0xf3, 0xe5, 0x2c,
-	0xa5, 0xda, 0x8e, 0x0e, 0x8d, 0xbb, 0x47, 0x4f, 0xcd, 0xbf, 0x3c, 0x97, 0x59, 0x2d, 0xdd, 0x7c,
-	0xcd, 0x32, 0x69, 0x97, 0xc4, 0x70, 0x96, 0x52, 0x57, 0xa1, 0xcd, 0x58, 0xe6, 0x8c, 0xe2, 0x20,
-	0xfc, 0x50, 0x32, 0x5a, 0x57, 0x87, 0x46, 0xd7, 0xdd, 0x08, 0x87, 0x6f, 0xd1, 0x9d, 0x3f, 0x48,
-	0xdc, 0x43, 0x3b, 0xde, 0xd0, 0x75, 0x06, 0x67, 0x9e, 0x0a, 0xf0, 0x3e, 0xd2, 0x4e, 0x8f, 0x3d,
-	0xdb, 0x77, 0x06, 0x9e, 0x3d, 0xf0, 0x9c, 0xa1, 0xf3, 0xc6, 0xf6, 0x9b, 0x53, 0x88, 0x55, 0xb4,
-	0xeb, 0x9c, 0xfb, 0xc7, 0xfd, 0xbe, 0x6b, 0x7b, 0x9e, 0xed, 0xa9, 0x5b, 0x58, 0x41, 0x6d, 0xd7,
-	0x3e, 0xb3, 0xdf, 0xa9, 0xad, 0x93, 0x97, 0xf3, 0x25, 0x01, 0x8b, 0x25, 0x01, 0xd7, 0x4b, 0x02,
-	0x6e, 0x96, 0x04, 0x7c, 0x2a, 0x08, 0xfc, 0x56, 0x10, 0x30, 0x2f, 0x08, 0x5c, 0x14, 0x04, 0xfe,
-	0x2c, 0x08, 0xfc, 0x55, 0x10, 0x70, 0x53, 0x10, 0xf8, 0x65, 0x45, 0xc0, 0x62, 0x45, 0xc0, 0xf5,
-	0x8a, 0x80, 0xf7, 0x9d, 0xea, 0xc7, 0x46, 0x9d, 0xf5, 0xea, 0x9f, 0xff, 0x0e, 0x00, 0x00, 0xff,
-	0xff, 0x1f, 0xed, 0x14, 0xa2, 0xcd, 0x02, 0x00, 0x00,
+	// 578 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x52, 0xbd, 0x6e, 0xd4, 0x4c,
+	0x14, 0xf5, 0x64, 0xb3, 0x9b, 0xf5, 0x24, 0x5f, 0xb2, 0xdf, 0x40, 0x61, 0x42, 0x34, 0x31, 0x29,
+	0xc0, 0x80, 0x64, 0x4b, 0x41, 0xf4, 0xe4, 0xc7, 0x0a, 0x46, 0xb0, 0x8a, 0xec, 0x04, 0x10, 0x8d,
+	0x35, 0xeb, 0x9d, 0xf5, 0x5a, 0xf1, 0x7a, 0xac, 0xf1, 0x38, 0x62, 0x1b, 0xc4, 0x23, 0x50, 0xf2,
+	0x08, 0x3c, 0x4a, 0xca, 0x94, 0xa9, 0x80, 0x75, 0x1a, 0xca, 0x54, 0xd4, 0x68, 0xfc, 0x93, 0x15,
+	0x08, 0x21, 0xa8, 0xe6, 0xce, 0xb9, 0xf7, 0xcc, 0x39, 0x73, 0xef, 0x85, 0x77, 0x27, 0xd1, 0x5b,
+	0xca, 0x2d, 0x32, 0x24, 0xa9, 0xa0, 0xdc, 0x8a, 0xa3, 0x4c, 0x58, 0x01, 0x4b, 0x46, 0x51, 0x58,
+	0x1f, 0x66, 0xca, 0x99, 0x60, 0xe8, 0x46, 0x5d, 0x61, 0xca, 0x0a, 0xb3, 0x4a, 0xad, 0xe3, 0x90,
+	0xb1, 0x30, 0xa6, 0x56, 0x59, 0x32, 0xc8, 0x47, 0xd6, 0x30, 0xe7, 0x44, 0x44, 0x2c, 0xa9, 0x48,
+	0xeb, 0x37, 0x43, 0x16, 0xb2, 0x32, 0xb4, 0x64, 0x54, 0xa1, 0x5b, 0xdf, 0xdb, 0xb0, 0x73, 0x48,
+	0x38, 0x99, 0x64, 0xe8, 0x0e, 0x5c, 0x49, 0x39, 0x3b, 0x8d, 0x86, 0x94, 0xfb, 0x39, 0x8f, 0x35,
+	0xa0, 0x03, 0x43, 0x75, 0x97, 0x1b, 0xec, 0x98, 0xc7, 0xa8, 0x0f, 0x7b, 0x9c, 0x8e, 0x38, 0xcd,
+	0xc6, 0x7e, 0x94, 0x08, 0xca, 0x4f, 0x49, 0xac, 0x2d, 0xe8, 0xc0, 0x58, 0xde, 0xbe, 0x65, 0x56,
+	0xf2, 0x66, 0x23, 0x6f, 0xee, 0xd7, 0xf2, 0xbb, 0xdd, 0xb3, 0xcf, 0x9b, 0xca, 0xc7, 0x2f, 0x9b,
+	0xc0, 0x5d, 0xab, 0xc9, 0x4e, 0xcd, 0x45, 0x8f, 0x61, 0x4b, 0x88, 0x58, 0x6b, 0xfd, 0xfd, 0x13,
+	0xb2, 0x5e, 0xda, 0x08, 0x48, 0x30, 0x8e, 0x92, 0x70, 0x6e, 0x63, 0xf1, 0x1f, 0x6c, 0xd4, 0xe4,
+	0x6b, 0x1b, 0x0f, 0xe0, 0xff, 0xcd, 0x7b, 0x79, 0x46, 0xfd, 0x80, 0xe5, 0x89, 0xd0, 0xda, 0x3a,
+	0x30, 0xda, 0xd7, 0xb5, 0xc7, 0x19, 0xdd, 0x93, 0x30, 0xda, 0x80, 0x2a, 0x3b, 0xa5, 0x9c, 0x47,
+	0x43, 0x9a, 0x69, 0x1d, 0xbd, 0x65, 0xa8, 0xee, 0x1c, 0x40, 0x4f, 0x21, 0xa4, 0x89, 0xe0, 0x53,
+	0x5f, 0x4c, 0x53, 0xaa, 0x2d, 0xe9, 0xc0, 0x58, 0xdd, 0xbe, 0x6f, 0xfe, 0x66, 0x5c, 0x66, 0xd5,
+	0x74, 0xf3, 0x79, 0x94, 0x09, 0x5b, 0x32, 0x8e, 0xa6, 0x29, 0x75, 0x55, 0xda, 0x84, 0x52, 0x67,
+	0x10, 0x93, 0xe0, 0x44, 0x72, 0xb4, 0xae, 0x0e, 0x8c, 0xae, 0x3b, 0x07, 0xd0, 0x6d, 0xa8, 0x8e,
+	0xa2, 0x98, 0xfa, 0x29, 0x11, 0x63, 0x4d, 0x2d, 0x07, 0xd5, 0x95, 0xc0, 0x21, 0x11, 0x63, 0xf4,
+	0x0c, 0xc2, 0x4a, 0xc4, 0x9f, 0x90, 0x54, 0x83, 0x65, 0x63, 0x1e, 0xfe, 0xc9, 0xc4, 0x5e, 0x79,
+	0x7b, 0x41, 0x52, 0x8f, 0xe5, 0x3c, 0xa0, 0xae, 0x1a, 0x34, 0xc0, 0xfa, 0x3b, 0xb8, 0xf6, 0x4b,
+	0x56, 0x3a, 0x4b, 0xc8, 0x84, 0x66, 0x29, 0x09, 0x68, 0xbd, 0x24, 0x73, 0x00, 0x21, 0xb8, 0x28,
+	0x2f, 0xe5, 0x5a, 0xa8, 0x6e, 0x19, 0xa3, 0x1e, 0x6c, 0x9d, 0xd0, 0x69, 0x39, 0x66, 0xd5, 0x95,
+	0x21, 0xba, 0x07, 0xd7, 0x4e, 0xf2, 0x01, 0xad, 0x6d, 0x96, 0xbf, 0x58, 0x2c, 0xb3, 0xab, 0x73,
+	0x58, 0xfe, 0x65, 0xeb, 0x15, 0xfc, 0xef, 0xa7, 0x16, 0xa1, 0x65, 0xb8, 0xe4, 0x1d, 0xb9, 0x4e,
+	0xff, 0xc0, 0xeb, 0x29, 0x68, 0x03, 0x6a, 0x7b, 0x3b, 0x9e, 0xed, 0x3b, 0x7d, 0xcf, 0xee, 0x7b,
+	0xce, 0x91, 0xf3, 0xd2, 0xf6, 0x9b, 0x2c, 0x40, 0x3d, 0xb8, 0xe2, 0x1c, 0xfa, 0x3b, 0xfb, 0xfb,
+	0xae, 0xed, 0x79, 0xb6, 0xd7, 0x5b, 0x40, 0x2a, 0x6c, 0xbb, 0xf6, 0x81, 0xfd, 0xba, 0xd7, 0xda,
+	0x7d, 0x72, 0x36, 0xc3, 0xca, 0xf9, 0x0c, 0x2b, 0x17, 0x33, 0xac, 0x5c, 0xcd, 0xb0, 0xf2, 0xbe,
+	0xc0, 0xe0, 0x53, 0x81, 0x95, 0xb3, 0x02, 0x83, 0xf3, 0x02, 0x83, 0xaf, 0x05, 0x06, 0xdf, 0x0a,
+	0xac, 0x5c, 0x15, 0x18, 0x7c, 0xb8, 0xc4, 0xca, 0xf9, 0x25, 0x56, 0x2e, 0x2e, 0xb1, 0xf2, 0xa6,
+	0x53, 0xd9, 0x1b, 0x74, 0xca, 0x1d, 0x7b, 0xf4, 0x63, 0x00, 0xbb, 0x83, 0xce, 0x5e, 0xb6, 0x03,
+	0x00, 0x00,
 }
 
 func (x Params_ListEntryType) String() string {
@@ -197,6 +257,25 @@ func (m *Params) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.ConfigMap != nil {
+		{
+			size, err := m.ConfigMap.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintConfig(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x52
+	}
+	if len(m.FilePath) > 0 {
+		i -= len(m.FilePath)
+		copy(dAtA[i:], m.FilePath)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.FilePath)))
+		i--
+		dAtA[i] = 0x4a
+	}
 	if m.Blacklist {
 		i--
 		if m.Blacklist {
@@ -226,29 +305,29 @@ func (m *Params) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0x28
 	}
-	n1, err1 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.CachingInterval, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.CachingInterval):])
-	if err1 != nil {
-		return 0, err1
-	}
-	i -= n1
-	i = encodeVarintConfig(dAtA, i, uint64(n1))
-	i--
-	dAtA[i] = 0x22
-	n2, err2 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.Ttl, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.Ttl):])
+	n2, err2 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.CachingInterval, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.CachingInterval):])
 	if err2 != nil {
 		return 0, err2
 	}
 	i -= n2
 	i = encodeVarintConfig(dAtA, i, uint64(n2))
 	i--
-	dAtA[i] = 0x1a
-	n3, err3 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.RefreshInterval, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.RefreshInterval):])
+	dAtA[i] = 0x22
+	n3, err3 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.Ttl, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.Ttl):])
 	if err3 != nil {
 		return 0, err3
 	}
 	i -= n3
 	i = encodeVarintConfig(dAtA, i, uint64(n3))
 	i--
+	dAtA[i] = 0x1a
+	n4, err4 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.RefreshInterval, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.RefreshInterval):])
+	if err4 != nil {
+		return 0, err4
+	}
+	i -= n4
+	i = encodeVarintConfig(dAtA, i, uint64(n4))
+	i--
 	dAtA[i] = 0x12
 	if len(m.ProviderUrl) > 0 {
 		i -= len(m.ProviderUrl)
@@ -260,6 +339,57 @@ func (m *Params) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *Params_ConfigMapSource) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Params_ConfigMapSource) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Params_ConfigMapSource) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.KubeconfigPath) > 0 {
+		i -= len(m.KubeconfigPath)
+		copy(dAtA[i:], m.KubeconfigPath)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.KubeconfigPath)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.Key) > 0 {
+		i -= len(m.Key)
+		copy(dAtA[i:], m.Key)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.Key)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Namespace) > 0 {
+		i -= len(m.Namespace)
+		copy(dAtA[i:], m.Namespace)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.Namespace)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintConfig(dAtA []byte, offset int, v uint64) int {
 	offset -= sovConfig(v)
 	base := offset
@@ -302,6 +432,39 @@ func (m *Params) Size() (n int) {
 	if m.Blacklist {
 		n += 2
 	}
+	l = len(m.FilePath)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	if m.ConfigMap != nil {
+		l = m.ConfigMap.Size()
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	return n
+}
+
+func (m *Params_ConfigMapSource) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Namespace)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	l = len(m.KubeconfigPath)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
 	return n
 }
 
@@ -324,6 +487,21 @@ func (this *Params) String() string {
 		`Overrides:` + fmt.Sprintf("%v", this.Overrides) + `,`,
 		`EntryType:` + fmt.Sprintf("%v", this.EntryType) + `,`,
 		`Blacklist:` + fmt.Sprintf("%v", this.Blacklist) + `,`,
+		`FilePath:` + fmt.Sprintf("%v", this.FilePath) + `,`,
+		`ConfigMap:` + strings.Replace(fmt.Sprintf("%v", this.ConfigMap), "Params_ConfigMapSource", "Params_ConfigMapSource", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Params_ConfigMapSource) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Params_ConfigMapSource{`,
+		`Namespace:` + fmt.Sprintf("%v", this.Namespace) + `,`,
+		`Name:` + fmt.Sprintf("%v", this.Name) + `,`,
+		`Key:` + fmt.Sprintf("%v", this.Key) + `,`,
+		`KubeconfigPath:` + fmt.Sprintf("%v", this.KubeconfigPath) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -586,6 +764,255 @@ func (m *Params) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.Blacklist = bool(v != 0)
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FilePath", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.FilePath = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ConfigMap", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ConfigMap == nil {
+				m.ConfigMap = &Params_ConfigMapSource{}
+			}
+			if err := m.ConfigMap.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipConfig(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Params_ConfigMapSource) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowConfig
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ConfigMapSource: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ConfigMapSource: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Namespace", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Namespace = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field KubeconfigPath", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.KubeconfigPath = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipConfig(dAtA[iNdEx:])
@@ -23,8 +23,21 @@ import (
 )
 
 type (
+	// ipList keeps IPv4 and IPv6 ranges in separate binary tries, so lookups
+	// take time proportional to the address length rather than to the number
+	// of entries.
 	ipList struct {
-		entries []*net.IPNet
+		v4    *cidrNode
+		v6    *cidrNode
+		count int
+	}
+
+	// cidrNode is a node of a binary trie keyed by address bits. A node is
+	// terminal if a range ends at it, in which case every address below it is
+	// contained in the list.
+	cidrNode struct {
+		children [2]*cidrNode
+		terminal bool
 	}
 
 	// represents the format of the data in a list
@@ -40,7 +53,7 @@ func parseIPList(buf []byte, overrides []string) (list, error) {
 		return nil, fmt.Errorf("could not unmarshal data from list %s", err)
 	}
 
-	ls := &ipList{make([]*net.IPNet, 0, len(lp.WhiteList)+len(overrides))}
+	ls := &ipList{v4: &cidrNode{}, v6: &cidrNode{}}
 	var err error
 
 	// copy to the internal format
@@ -69,7 +82,17 @@ func (ls *ipList) addEntry(ip string) error {
 	if err != nil {
 		return fmt.Errorf("could not parse list entry %s: %v", orig, err)
 	}
-	ls.entries = append(ls.entries, ipnet)
+	ones, bits := ipnet.Mask.Size()
+	if ip4 := ipnet.IP.To4(); ip4 != nil {
+		// IPv4-mapped IPv6 ranges, e.g. ::ffff:10.0.0.0/104, contain IPv4 addresses
+		if bits == 8*net.IPv6len {
+			ones -= 8 * (net.IPv6len - net.IPv4len)
+		}
+		ls.v4.insert(ip4, ones)
+	} else {
+		ls.v6.insert(ipnet.IP, ones)
+	}
+	ls.count++
 
 	return nil
 }
@@ -81,16 +104,48 @@ func (ls *ipList) checkList(symbol string) (bool, error) {
 		return false, fmt.Errorf("%s is not a valid IP address", symbol)
 	}
 
-	for _, ipnet := range ls.entries {
-		if ipnet.Contains(ipa) {
-			return true, nil
-		}
+	if ip4 := ipa.To4(); ip4 != nil {
+		return ls.v4.contains(ip4), nil
 	}
-
-	// not found in the list
-	return false, nil
+	return ls.v6.contains(ipa), nil
 }
 
 func (ls *ipList) numEntries() int {
-	return len(ls.entries)
+	return ls.count
+}
+
+func bit(ip net.IP, i int) int {
+	return int(ip[i/8]>>(7-uint(i%8))) & 1
+}
+
+// insert adds the range made of the first ones bits of ip.
+func (n *cidrNode) insert(ip net.IP, ones int) {
+	for i := 0; i < ones; i++ {
+		if n.terminal {
+			// already covered by a wider range
+			return
+		}
+		b := bit(ip, i)
+		if n.children[b] == nil {
+			n.children[b] = &cidrNode{}
+		}
+		n = n.children[b]
+	}
+	n.terminal = true
+	// narrower ranges are now redundant
+	n.children = [2]*cidrNode{}
+}
+
+// contains reports whether ip falls into any range of the trie.
+func (n *cidrNode) contains(ip net.IP) bool {
+	for i := 0; n != nil; i++ {
+		if n.terminal {
+			return true
+		}
+		if i == len(ip)*8 {
+			return false
+		}
+		n = n.children[bit(ip, i)]
+	}
+	return false
 }
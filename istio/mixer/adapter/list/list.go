@@ -33,6 +33,17 @@ import (
 	"sync"
 	"time"
 
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+
 	rpc "istio.io/gogo-genproto/googleapis/google/rpc"
 
 	"istio.io/api/policy/v1beta1"
@@ -41,8 +52,52 @@ import (
 	"istio.io/istio/mixer/pkg/adapter"
 	"istio.io/istio/mixer/pkg/status"
 	"istio.io/istio/mixer/template/listentry"
+	"istio.io/pkg/filewatcher"
+)
+
+var (
+	sourceTag tag.Key
+
+	listEntries = stats.Int64(
+		"mixer/adapter/list/entries",
+		"The number of entries in the list currently in use, including overrides.",
+		stats.UnitDimensionless)
+	lastRefreshSeconds = stats.Int64(
+		"mixer/adapter/list/last_refresh_timestamp_seconds",
+		"The time of the last successful refresh of the list, in seconds since the epoch.",
+		"s")
+	refreshErrorsTotal = stats.Int64(
+		"mixer/adapter/list/refresh_errors_total",
+		"The number of failed attempts to load the list.",
+		stats.UnitDimensionless)
 )
 
+func init() {
+	var err error
+	if sourceTag, err = tag.NewKey("list_source"); err != nil {
+		panic(err)
+	}
+
+	keys := []tag.Key{sourceTag}
+	if err = view.Register(
+		newView(listEntries, keys, view.LastValue()),
+		newView(lastRefreshSeconds, keys, view.LastValue()),
+		newView(refreshErrorsTotal, keys, view.Sum()),
+	); err != nil {
+		panic(err)
+	}
+}
+
+func newView(measure stats.Measure, keys []tag.Key, aggregation *view.Aggregation) *view.View {
+	return &view.View{
+		Name:        measure.Name(),
+		Description: measure.Description(),
+		Measure:     measure,
+		TagKeys:     keys,
+		Aggregation: aggregation,
+	}
+}
+
 type (
 	handler struct {
 		log           adapter.Logger
@@ -59,8 +114,19 @@ type (
 
 		// indirection to enable fault injection
 		readAll func(io.Reader) ([]byte, error)
+
+		// watches the list file, if the list is loaded from a local file
+		watcher filewatcher.FileWatcher
+		// closed when the handler is closed, stops the ConfigMap informer
+		stopCh chan struct{}
+
+		// tags the metrics of this handler with the source of the list
+		metricsCtx context.Context
 	}
 
+	// used strictly for testing purposes
+	clientFactoryFn func(kubeconfigPath string) (k8s.Interface, error)
+
 	// a specific list we use to check against
 	list interface {
 		checkList(symbol string) (bool, error)
@@ -124,12 +190,17 @@ func (h *handler) HandleListEntry(_ context.Context, entry *listentry.Instance)
 
 func (h *handler) Close() error {
 	close(h.closing)
+	close(h.stopCh)
 
 	if h.refreshTicker != nil {
 		h.refreshTicker.Stop()
 		h.purgeTimer.Stop()
 	}
 
+	if h.watcher != nil {
+		return h.watcher.Close()
+	}
+
 	return nil
 }
 
@@ -174,9 +245,7 @@ func (h *handler) fetchList() {
 			} else {
 				err = h.log.Errorf("could not fetch list from %s: %v", h.config.ProviderUrl, resp.StatusCode)
 			}
-			h.lock.Lock()
-			h.lastFetchError = err
-			h.lock.Unlock()
+			h.setFetchError(err)
 			return
 		}
 
@@ -184,21 +253,137 @@ func (h *handler) fetchList() {
 		buf, err = h.readAll(resp.Body)
 		if err != nil {
 			err = h.log.Errorf("Could not read from %s: %v", h.config.ProviderUrl, err)
-			h.lock.Lock()
-			h.lastFetchError = err
-			h.lock.Unlock()
+			h.setFetchError(err)
 			return
 		}
 
-		// determine whether the list has changed since the last fetch
 		sha = sha1.Sum(buf)
-		if sha == h.latestSHA && h.list != nil {
-			// the list hasn't changed since last time
-			h.log.Infof("Fetched list is unchanged")
-			h.resetPurgeTimer()
+	}
+
+	h.installList(buf, sha)
+}
+
+// loadFile reads the list from the local list file.
+func (h *handler) loadFile() {
+	h.log.Infof("Loading list from %s", h.config.FilePath)
+
+	buf, err := ioutil.ReadFile(h.config.FilePath)
+	if err != nil {
+		h.setFetchError(h.log.Errorf("could not read list from %s: %v", h.config.FilePath, err))
+		return
+	}
+
+	h.installList(buf, sha1.Sum(buf))
+}
+
+// fileWatcher reloads the list whenever the list file changes
+func (h *handler) fileWatcher() {
+	events := h.watcher.Events(h.config.FilePath)
+	errors := h.watcher.Errors(h.config.FilePath)
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+			h.loadFile()
+
+		case err, ok := <-errors:
+			if !ok {
+				return
+			}
+			h.log.Warningf("Error watching list file %s: %v", h.config.FilePath, err)
+
+		case <-h.closing:
 			return
 		}
 	}
+}
+
+// loadConfigMap reads the list from the current version of the ConfigMap.
+func (h *handler) loadConfigMap(client k8s.Interface) {
+	cm := h.config.ConfigMap
+	h.log.Infof("Loading list from ConfigMap %s/%s", cm.Namespace, cm.Name)
+
+	obj, err := client.CoreV1().ConfigMaps(cm.Namespace).Get(cm.Name, metav1.GetOptions{})
+	if err != nil {
+		h.setFetchError(h.log.Errorf("could not get ConfigMap %s/%s: %v", cm.Namespace, cm.Name, err))
+		return
+	}
+	h.onConfigMap(obj)
+}
+
+// configMapWatcher reloads the list whenever the ConfigMap changes
+func (h *handler) configMapWatcher(client k8s.Interface) {
+	cm := h.config.ConfigMap
+	factory := informers.NewSharedInformerFactoryWithOptions(client, 0,
+		informers.WithNamespace(cm.Namespace),
+		informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+			o.FieldSelector = fields.OneTermEqualSelector("metadata.name", cm.Name).String()
+		}))
+	informer := factory.Core().V1().ConfigMaps().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    h.onConfigMap,
+		UpdateFunc: func(_, obj interface{}) { h.onConfigMap(obj) },
+		DeleteFunc: func(obj interface{}) {
+			if !h.isListConfigMap(obj) {
+				return
+			}
+			h.setFetchError(h.log.Errorf("ConfigMap %s/%s was deleted", cm.Namespace, cm.Name))
+			h.purgeList()
+		},
+	})
+	informer.Run(h.stopCh)
+}
+
+func (h *handler) isListConfigMap(obj interface{}) bool {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	cm, ok := obj.(*v1.ConfigMap)
+	return ok && cm.Namespace == h.config.ConfigMap.Namespace && cm.Name == h.config.ConfigMap.Name
+}
+
+func (h *handler) onConfigMap(obj interface{}) {
+	if !h.isListConfigMap(obj) {
+		return
+	}
+
+	cm := obj.(*v1.ConfigMap)
+	data, found := cm.Data[h.config.ConfigMap.Key]
+	if !found {
+		h.setFetchError(h.log.Errorf("ConfigMap %s/%s has no key %s", cm.Namespace, cm.Name, h.config.ConfigMap.Key))
+		return
+	}
+
+	buf := []byte(data)
+	h.installList(buf, sha1.Sum(buf))
+}
+
+func (h *handler) setFetchError(err error) {
+	h.lock.Lock()
+	h.lastFetchError = err
+	h.lock.Unlock()
+
+	stats.Record(h.metricsCtx, refreshErrorsTotal.M(1))
+}
+
+// installList compiles the given list and swaps it in for the current one.
+// Lookups in progress keep using the list they started with.
+func (h *handler) installList(buf []byte, sha [sha1.Size]byte) {
+	var err error
+
+	// determine whether the list has changed since the last fetch
+	h.lock.Lock()
+	unchanged := sha == h.latestSHA && h.list != nil
+	h.lock.Unlock()
+	if unchanged {
+		// the list hasn't changed since last time
+		h.log.Infof("Fetched list is unchanged")
+		h.recordRefresh(-1)
+		h.resetPurgeTimer()
+		return
+	}
 
 	var l list
 
@@ -210,19 +395,15 @@ func (h *handler) fetchList() {
 	case config.IP_ADDRESSES:
 		l, err = parseIPList(buf, h.config.Overrides)
 		if err != nil {
-			err = h.log.Errorf("Could not parse data from %s: %v", h.config.ProviderUrl, err)
-			h.lock.Lock()
-			h.lastFetchError = err
-			h.lock.Unlock()
+			err = h.log.Errorf("Could not parse data from %s: %v", h.source(), err)
+			h.setFetchError(err)
 			return
 		}
 	case config.REGEX:
 		l, err = parseRegexList(buf, h.config.Overrides)
 		if err != nil {
-			err = h.log.Errorf("Could not parse data from %s: %v", h.config.ProviderUrl, err)
-			h.lock.Lock()
-			h.lastFetchError = err
-			h.lock.Unlock()
+			err = h.log.Errorf("Could not parse data from %s: %v", h.source(), err)
+			h.setFetchError(err)
 			return
 		}
 	}
@@ -233,12 +414,37 @@ func (h *handler) fetchList() {
 	h.lock.Lock()
 	h.list = l
 	h.lastFetchError = nil
+	h.latestSHA = sha
 	h.lock.Unlock()
 
-	h.latestSHA = sha
+	h.recordRefresh(l.numEntries())
 	h.resetPurgeTimer()
 }
 
+// source describes where the list comes from.
+func (h *handler) source() string {
+	switch {
+	case h.config.ProviderUrl != "":
+		return h.config.ProviderUrl
+	case h.config.FilePath != "":
+		return h.config.FilePath
+	case h.config.ConfigMap != nil:
+		return "configmap:" + h.config.ConfigMap.Namespace + "/" + h.config.ConfigMap.Name
+	default:
+		return "overrides"
+	}
+}
+
+// recordRefresh records a successful refresh. entries is negative if the list
+// did not change.
+func (h *handler) recordRefresh(entries int) {
+	ms := []stats.Measurement{lastRefreshSeconds.M(time.Now().Unix())}
+	if entries >= 0 {
+		ms = append(ms, listEntries.M(int64(entries)))
+	}
+	stats.Record(h.metricsCtx, ms...)
+}
+
 func (h *handler) resetPurgeTimer() {
 	if h.purgeTimer == nil {
 		return
@@ -263,6 +469,8 @@ func (h *handler) purgeList() {
 	h.lock.Lock()
 	h.list = nil
 	h.lock.Unlock()
+
+	stats.Record(h.metricsCtx, listEntries.M(0))
 }
 
 func (h *handler) hasData() (bool, error) {
@@ -287,12 +495,21 @@ func getCheckResult(config config.Params, code rpc.Code, msg string) adapter.Che
 // GetInfo returns the Info associated with this adapter implementation.
 func GetInfo() adapter.Info {
 	info := metadata.GetInfo("listchecker")
-	info.NewBuilder = func() adapter.HandlerBuilder { return &builder{} }
+	info.NewBuilder = func() adapter.HandlerBuilder { return &builder{newClientFn: newKubernetesClient} }
 	return info
 }
 
 type builder struct {
 	adapterConfig *config.Params
+	newClientFn   clientFactoryFn
+}
+
+func newKubernetesClient(kubeconfigPath string) (k8s.Interface, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve kubeconfig: %v", err)
+	}
+	return k8s.NewForConfig(config)
 }
 
 func (*builder) SetListEntryTypes(map[string]*listentry.Type) {}
@@ -301,6 +518,28 @@ func (b *builder) SetAdapterConfig(cfg adapter.Config)        { b.adapterConfig
 func (b *builder) Validate() (ce *adapter.ConfigErrors) {
 	ac := b.adapterConfig
 
+	sources := 0
+	for _, set := range []bool{ac.ProviderUrl != "", ac.FilePath != "", ac.ConfigMap != nil} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		ce = ce.Appendf("providerUrl", "at most one of providerUrl, filePath and configMap may be set")
+	}
+
+	if cm := ac.ConfigMap; cm != nil {
+		if cm.Namespace == "" {
+			ce = ce.Appendf("configMap.namespace", "ConfigMap namespace cannot be empty")
+		}
+		if cm.Name == "" {
+			ce = ce.Appendf("configMap.name", "ConfigMap name cannot be empty")
+		}
+		if cm.Key == "" {
+			ce = ce.Appendf("configMap.key", "ConfigMap key cannot be empty")
+		}
+	}
+
 	if ac.ProviderUrl != "" {
 		u, err := url.Parse(ac.ProviderUrl)
 		if err != nil {
@@ -357,21 +596,51 @@ func (b *builder) Build(context context.Context, env adapter.Env) (adapter.Handl
 	h := &handler{
 		log:     env.Logger(),
 		closing: make(chan bool),
+		stopCh:  make(chan struct{}),
 		config:  *ac,
 		readAll: ioutil.ReadAll,
 	}
 
-	if ac.ProviderUrl != "" {
-		h.refreshTicker = time.NewTicker(ac.RefreshInterval)
-		h.purgeTimer = time.NewTimer(ac.Ttl)
+	var err error
+	if h.metricsCtx, err = tag.New(context, tag.Upsert(sourceTag, h.source())); err != nil {
+		return nil, err
 	}
 
-	// Load up the list synchronously so we're ready to accept traffic immediately.
-	h.fetchList()
+	switch {
+	case ac.FilePath != "":
+		h.watcher = filewatcher.NewWatcher()
+		if err = h.watcher.Add(ac.FilePath); err != nil {
+			_ = h.watcher.Close()
+			return nil, fmt.Errorf("could not watch list file %s: %v", ac.FilePath, err)
+		}
+
+		// Load up the list synchronously so we're ready to accept traffic immediately.
+		h.loadFile()
+		env.ScheduleDaemon(h.fileWatcher)
 
-	if ac.ProviderUrl != "" {
-		// goroutine to periodically refresh the list
-		env.ScheduleDaemon(h.listRefresher)
+	case ac.ConfigMap != nil:
+		client, err := b.newClientFn(ac.ConfigMap.KubeconfigPath)
+		if err != nil {
+			return nil, err
+		}
+
+		// Load up the list synchronously so we're ready to accept traffic immediately.
+		h.loadConfigMap(client)
+		env.ScheduleDaemon(func() { h.configMapWatcher(client) })
+
+	default:
+		if ac.ProviderUrl != "" {
+			h.refreshTicker = time.NewTicker(ac.RefreshInterval)
+			h.purgeTimer = time.NewTimer(ac.Ttl)
+		}
+
+		// Load up the list synchronously so we're ready to accept traffic immediately.
+		h.fetchList()
+
+		if ac.ProviderUrl != "" {
+			// goroutine to periodically refresh the list
+			env.ScheduleDaemon(h.listRefresher)
+		}
 	}
 
 	return h, nil
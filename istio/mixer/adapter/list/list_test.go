@@ -17,15 +17,24 @@ package list
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strconv"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/ghodss/yaml"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
 
 	rpc "istio.io/gogo-genproto/googleapis/google/rpc"
 
@@ -606,6 +615,41 @@ func TestValidateConfig(t *testing.T) {
 			cfg:   config.Params{EntryType: config.REGEX, Overrides: []string{"(["}},
 			field: "overrides",
 		},
+
+		{
+			cfg:   config.Params{ProviderUrl: "http://foo.com", RefreshInterval: 1 * time.Second, Ttl: 2 * time.Second, FilePath: "/etc/list"},
+			field: "providerUrl",
+		},
+
+		{
+			cfg:   config.Params{FilePath: "/etc/list", ConfigMap: &config.Params_ConfigMapSource{Namespace: "ns", Name: "list", Key: "list"}},
+			field: "providerUrl",
+		},
+
+		{
+			cfg:   config.Params{ConfigMap: &config.Params_ConfigMapSource{Name: "list", Key: "list"}},
+			field: "configMap.namespace",
+		},
+
+		{
+			cfg:   config.Params{ConfigMap: &config.Params_ConfigMapSource{Namespace: "ns", Key: "list"}},
+			field: "configMap.name",
+		},
+
+		{
+			cfg:   config.Params{ConfigMap: &config.Params_ConfigMapSource{Namespace: "ns", Name: "list"}},
+			field: "configMap.key",
+		},
+
+		{
+			cfg:   config.Params{ConfigMap: &config.Params_ConfigMapSource{Namespace: "ns", Name: "list", Key: "list"}},
+			field: "",
+		},
+
+		{
+			cfg:   config.Params{FilePath: "/etc/list"},
+			field: "",
+		},
 	}
 
 	for i, c := range cases {
@@ -627,3 +671,178 @@ func TestValidateConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestCIDRTrie(t *testing.T) {
+	l, err := parseIPList([]byte(`whitelist: ["10.0.0.0/8", "10.1.2.0/24", "192.168.1.1", "2001:db8::/32", "0.0.0.0/32"]`), nil)
+	if err != nil {
+		t.Fatalf("Got error %v, expecting success", err)
+	}
+
+	cases := map[string]bool{
+		"10.0.0.1":         true,
+		"10.255.255.255":   true,
+		"10.1.2.3":         true,
+		"11.0.0.0":         false,
+		"192.168.1.1":      true,
+		"192.168.1.2":      false,
+		"0.0.0.0":          true,
+		"0.0.0.1":          false,
+		"2001:db8::1":      true,
+		"2001:db9::1":      false,
+		"::ffff:10.0.0.1":  true,
+		"::ffff:11.0.0.1":  false,
+		"::10.0.0.1":       false,
+		"2001:db8:ffff::1": true,
+	}
+	for addr, want := range cases {
+		got, err := l.checkList(addr)
+		if err != nil {
+			t.Errorf("%s: Got error %v, expecting success", addr, err)
+		}
+		if got != want {
+			t.Errorf("%s: Got %v, expecting %v", addr, got, want)
+		}
+	}
+
+	if l.numEntries() != 5 {
+		t.Errorf("Got %d entries, expecting 5", l.numEntries())
+	}
+}
+
+func TestCIDRTrieIPv4Mapped(t *testing.T) {
+	entries := []string{"::ffff:10.0.0.0/104", "::ffff:192.168.1.1/128", "::ffff:0:0/96"}
+	for _, entry := range entries {
+		l, err := parseIPList([]byte(fmt.Sprintf(`whitelist: ["%s"]`, entry)), nil)
+		if err != nil {
+			t.Fatalf("%s: Got error %v, expecting success", entry, err)
+		}
+		_, ipnet, _ := net.ParseCIDR(entry)
+		for _, addr := range []string{"10.0.0.1", "::ffff:10.255.0.1", "11.0.0.1", "192.168.1.1", "192.168.1.2", "2001:db8::1"} {
+			// the trie matches like IPNet.Contains
+			want := ipnet.Contains(net.ParseIP(addr))
+			if got, _ := l.checkList(addr); got != want {
+				t.Errorf("%s in %s: Got %v, expecting %v", addr, entry, got, want)
+			}
+		}
+	}
+}
+
+func TestLargeIPList(t *testing.T) {
+	var entries []string
+	for i := 0; i < 100000; i++ {
+		entries = append(entries, fmt.Sprintf("%d.%d.%d.0/24", 1+i/65536, (i/256)%256, i%256))
+	}
+	buf, err := yaml.Marshal(listPayload{WhiteList: entries})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := parseIPList(buf, nil)
+	if err != nil {
+		t.Fatalf("Got error %v, expecting success", err)
+	}
+
+	for addr, want := range map[string]bool{"1.0.0.7": true, "2.134.159.255": true, "2.134.160.1": false} {
+		if got, _ := l.checkList(addr); got != want {
+			t.Errorf("%s: Got %v, expecting %v", addr, got, want)
+		}
+	}
+}
+
+func TestFileList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "list.yaml")
+	if err = ioutil.WriteFile(path, []byte(`whitelist: ["10.0.0.0/8"]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := buildHandler(t, &config.Params{FilePath: path, EntryType: config.IP_ADDRESSES})
+	if err != nil {
+		t.Fatalf("Got error %v, expecting success", err)
+	}
+	defer func() { _ = h.Close() }()
+
+	checkCases(t, []listTestCase{
+		{"10.1.1.1", rpc.OK, false},
+		{"11.1.1.1", rpc.NOT_FOUND, false},
+	}, h)
+
+	if err = ioutil.WriteFile(path, []byte(`whitelist: ["11.0.0.0/8"]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitForEntry(t, h, "11.1.1.1")
+	checkCases(t, []listTestCase{{"10.1.1.1", rpc.NOT_FOUND, false}}, h)
+}
+
+func TestConfigMapList(t *testing.T) {
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "istio-system", Name: "blocklist"},
+		Data:       map[string]string{"list": `whitelist: ["10.0.0.0/8"]`},
+	}
+	client := fake.NewSimpleClientset(cm)
+
+	b := &builder{newClientFn: func(string) (k8s.Interface, error) { return client, nil }}
+	b.SetAdapterConfig(&config.Params{
+		ConfigMap: &config.Params_ConfigMapSource{Namespace: "istio-system", Name: "blocklist", Key: "list"},
+		EntryType: config.IP_ADDRESSES,
+	})
+	if err := b.Validate(); err != nil {
+		t.Fatalf("Got error %v, expecting success", err)
+	}
+	hh, err := b.Build(context.Background(), test.NewEnv(t))
+	if err != nil {
+		t.Fatalf("Got error %v, expecting success", err)
+	}
+	h := hh.(*handler)
+	defer func() { _ = h.Close() }()
+
+	checkCases(t, []listTestCase{
+		{"10.1.1.1", rpc.OK, false},
+		{"11.1.1.1", rpc.NOT_FOUND, false},
+	}, h)
+
+	cm = cm.DeepCopy()
+	cm.Data["list"] = `whitelist: ["11.0.0.0/8"]`
+	if _, err = client.CoreV1().ConfigMaps("istio-system").Update(cm); err != nil {
+		t.Fatal(err)
+	}
+	waitForEntry(t, h, "11.1.1.1")
+
+	if err = client.CoreV1().ConfigMaps("istio-system").Delete("blocklist", &metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		h.lock.Lock()
+		l := h.list
+		h.lock.Unlock()
+		if l == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the list to be purged")
+		}
+	}
+}
+
+// waitForEntry waits until the handler's list contains the given entry.
+func waitForEntry(t *testing.T, h *handler, entry string) {
+	t.Helper()
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		h.lock.Lock()
+		l := h.list
+		h.lock.Unlock()
+		if l != nil {
+			if found, _ := l.checkList(entry); found {
+				return
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s to be added to the list", entry)
+		}
+	}
+}
@@ -33,9 +33,11 @@ import (
 	list "istio.io/istio/mixer/adapter/list/config"
 	memquota "istio.io/istio/mixer/adapter/memquota/config"
 	opa "istio.io/istio/mixer/adapter/opa/config"
+	otlp "istio.io/istio/mixer/adapter/otlp/config"
 	prometheus "istio.io/istio/mixer/adapter/prometheus/config"
 	redisquota "istio.io/istio/mixer/adapter/redisquota/config"
 	solarwinds "istio.io/istio/mixer/adapter/solarwinds/config"
+	spool "istio.io/istio/mixer/adapter/spool/config"
 	stackdriver "istio.io/istio/mixer/adapter/stackdriver/config"
 	statsd "istio.io/istio/mixer/adapter/statsd/config"
 	stdio "istio.io/istio/mixer/adapter/stdio/config"
@@ -200,6 +202,24 @@ var (
 			DefaultConfig: &opa.Params{},
 		},
 
+		{
+			Name:        "otlp",
+			Impl:        "istio.io/istio/mixer/adapter/otlp",
+			Description: "Exports metrics, logs and traces to an OpenTelemetry collector",
+			SupportedTemplates: []string{
+				metric.TemplateName,
+				logentry.TemplateName,
+				tracespan.TemplateName,
+			},
+			DefaultConfig: &otlp.Params{
+				Address:              "localhost:4317",
+				InstanceBufferSize:   1024,
+				MaxBatchSize:         512,
+				PushIntervalDuration: 10 * time.Second,
+				PushTimeoutDuration:  10 * time.Second,
+			},
+		},
+
 		{
 			Name:        "prometheus",
 			Impl:        "istio.io/istio/mixer/adapter/prometheus",
@@ -234,6 +254,23 @@ var (
 			DefaultConfig: &solarwinds.Params{},
 		},
 
+		{
+			Name:        "spool",
+			Impl:        "istio.io/istio/mixer/adapter/spool",
+			Description: "Spools log entries to disk and forwards them to a remote sink",
+			SupportedTemplates: []string{
+				logentry.TemplateName,
+			},
+			DefaultConfig: &spool.Params{
+				MaxSpoolSizeBytes:        1 << 30,
+				SegmentSizeBytes:         64 << 20,
+				MaxBatchEntries:          500,
+				MaxBatchSizeBytes:        1 << 20,
+				PushIntervalDuration:     5 * time.Second,
+				MaxRetryIntervalDuration: time.Minute,
+			},
+		},
+
 		{
 			Name:        "stackdriver",
 			Impl:        "istio.io/istio/mixer/adapter/stackdriver",
@@ -0,0 +1,306 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opa
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+const (
+	bundleDataFile     = "data.json"
+	bundleManifestFile = ".manifest"
+	bundlePolicySuffix = ".rego"
+
+	defaultBundleTimeout = 30 * time.Second
+)
+
+type (
+	// bundle is the content of a policy bundle: Rego modules and the data
+	// documents they use.
+	bundle struct {
+		// revision as declared in the bundle manifest, if any.
+		revision string
+		// digest identifies the content of the bundle, so unchanged bundles
+		// are not recompiled.
+		digest  string
+		modules map[string]*ast.Module
+		data    map[string]interface{}
+	}
+
+	bundleManifest struct {
+		Revision string `json:"revision"`
+	}
+
+	// bundleLoader fetches the current version of a bundle. It returns a nil
+	// bundle if the bundle is known not to have changed since the last load.
+	bundleLoader interface {
+		load(ctx context.Context) (*bundle, error)
+		String() string
+	}
+
+	fileBundleLoader struct {
+		path string
+	}
+
+	httpBundleLoader struct {
+		url     string
+		headers map[string]string
+		client  *http.Client
+		etag    string
+	}
+)
+
+func newBundle() *bundle {
+	return &bundle{
+		modules: map[string]*ast.Module{},
+		data:    map[string]interface{}{},
+	}
+}
+
+// add adds a single file of the bundle. Files other than policies, data
+// documents and the manifest are ignored.
+func (b *bundle) add(name string, content []byte) error {
+	name = path.Clean("/" + filepath.ToSlash(name))
+	switch {
+	case strings.HasSuffix(name, bundlePolicySuffix):
+		m, err := ast.ParseModule(name, string(content))
+		if err != nil {
+			return err
+		}
+		b.modules[name] = m
+
+	case path.Base(name) == bundleDataFile:
+		var doc interface{}
+		if err := json.Unmarshal(content, &doc); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		var key []string
+		if dir := strings.Trim(path.Dir(name), "/"); dir != "" {
+			key = strings.Split(dir, "/")
+		}
+		if err := mergeData(b.data, key, doc); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+
+	case name == "/"+bundleManifestFile:
+		var m bundleManifest
+		if err := json.Unmarshal(content, &m); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		b.revision = m.Revision
+	}
+	return nil
+}
+
+// mergeData mounts doc at the given path of the data document.
+func mergeData(data map[string]interface{}, key []string, doc interface{}) error {
+	if len(key) > 0 {
+		child, found := data[key[0]]
+		if !found {
+			child = map[string]interface{}{}
+			data[key[0]] = child
+		}
+		obj, ok := child.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("data at %q is not an object", key[0])
+		}
+		return mergeData(obj, key[1:], doc)
+	}
+
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("data document must be an object")
+	}
+	for k, v := range obj {
+		existing, found := data[k]
+		if !found {
+			data[k] = v
+			continue
+		}
+		if _, isObj := existing.(map[string]interface{}); !isObj {
+			return fmt.Errorf("data at %q is defined more than once", k)
+		}
+		if err := mergeData(data, []string{k}, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readBundleTarball reads a gzipped tarball bundle.
+func readBundleTarball(content []byte) (*bundle, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gz)
+
+	b := newBundle()
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		buf, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		if err = b.add(hdr.Name, buf); err != nil {
+			return nil, err
+		}
+	}
+
+	sum := sha256.Sum256(content)
+	b.digest = hex.EncodeToString(sum[:])
+	return b, nil
+}
+
+// readBundleDir reads a bundle laid out as a directory tree.
+func readBundleDir(dir string) (*bundle, error) {
+	var names []string
+	err := filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.Mode().IsRegular() {
+			names = append(names, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	b := newBundle()
+	h := sha256.New()
+	for _, name := range names {
+		content, err := ioutil.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		if err = b.add(rel, content); err != nil {
+			return nil, err
+		}
+		_, _ = h.Write([]byte(rel))
+		_, _ = h.Write(content)
+	}
+	b.digest = hex.EncodeToString(h.Sum(nil))
+	return b, nil
+}
+
+func newFileBundleLoader(path string) *fileBundleLoader {
+	return &fileBundleLoader{path: path}
+}
+
+func (l *fileBundleLoader) load(context.Context) (*bundle, error) {
+	fi, err := os.Stat(l.path)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return readBundleDir(l.path)
+	}
+	content, err := ioutil.ReadFile(l.path)
+	if err != nil {
+		return nil, err
+	}
+	return readBundleTarball(content)
+}
+
+func (l *fileBundleLoader) String() string {
+	return l.path
+}
+
+func newHTTPBundleLoader(url string, headers map[string]string) *httpBundleLoader {
+	return &httpBundleLoader{
+		url:     url,
+		headers: headers,
+		client:  &http.Client{Timeout: defaultBundleTimeout},
+	}
+}
+
+// load downloads the bundle, using the ETag of the last download to avoid
+// transferring a bundle that did not change.
+func (l *httpBundleLoader) load(ctx context.Context) (*bundle, error) {
+	req, err := http.NewRequest(http.MethodGet, l.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range l.headers {
+		req.Header.Set(k, v)
+	}
+	if l.etag != "" {
+		req.Header.Set("If-None-Match", l.etag)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("bundle server responded with status %s", resp.Status)
+	}
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	b, err := readBundleTarball(content)
+	if err != nil {
+		return nil, err
+	}
+	l.etag = resp.Header.Get("ETag")
+	return b, nil
+}
+
+func (l *httpBundleLoader) String() string {
+	return l.url
+}
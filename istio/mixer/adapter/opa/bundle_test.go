@@ -0,0 +1,275 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opa
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	rpc "istio.io/gogo-genproto/googleapis/google/rpc"
+
+	"istio.io/istio/mixer/adapter/opa/config"
+)
+
+const bundlePolicy = `package mixerauthz
+
+default allow = false
+
+allow {
+  data.mixerauthz.users[_] = input.subject.user
+}`
+
+func bundleFiles(revision string, users string) map[string]string {
+	return map[string]string{
+		".manifest":                  `{"revision": "` + revision + `"}`,
+		"mixerauthz/policy.rego":     bundlePolicy,
+		"mixerauthz/data.json":       `{"users": [` + users + `]}`,
+		"mixerauthz/README.md":       "ignored",
+		"mixerauthz/extra/data.json": `{"enabled": true}`,
+	}
+}
+
+func tarball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func checkUsers(t *testing.T, h *handler, allowed map[string]bool) {
+	t.Helper()
+	for user, allow := range allowed {
+		result, err := h.HandleAuthorization(context.Background(), userInstance(user))
+		if err != nil {
+			t.Fatalf("Got error %v, expecting success", err)
+		}
+		expected := rpc.OK
+		if !allow {
+			expected = rpc.PERMISSION_DENIED
+		}
+		if result.Status.Code != int32(expected) {
+			t.Errorf("%s: Got status %v, expecting %v", user, result.Status.Code, expected)
+		}
+	}
+}
+
+func TestReadBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	files := bundleFiles("v1", `"alice"`)
+	for name, content := range files {
+		p := filepath.Join(dir, "dir", name)
+		if err = os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tarPath := filepath.Join(dir, "bundle.tar.gz")
+	if err = ioutil.WriteFile(tarPath, tarball(t, files), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{filepath.Join(dir, "dir"), tarPath} {
+		b, err := newFileBundleLoader(path).load(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Got error %v, expecting success", path, err)
+		}
+		if b.revision != "v1" {
+			t.Errorf("%s: Got revision %q, expecting v1", path, b.revision)
+		}
+		if _, found := b.modules["/mixerauthz/policy.rego"]; !found || len(b.modules) != 1 {
+			t.Errorf("%s: Got modules %v", path, b.modules)
+		}
+		authz, _ := b.data["mixerauthz"].(map[string]interface{})
+		extra, _ := authz["extra"].(map[string]interface{})
+		if authz["users"] == nil || extra["enabled"] != true {
+			t.Errorf("%s: Got data %v", path, b.data)
+		}
+	}
+
+	if _, err = readBundleTarball([]byte("not a tarball")); err == nil {
+		t.Error("Got success reading an invalid tarball, expecting error")
+	}
+}
+
+func TestFileBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	path := filepath.Join(dir, "bundle.tar.gz")
+	if err = ioutil.WriteFile(path, tarball(t, bundleFiles("v1", `"alice"`)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := buildHandler(t, &config.Params{
+		Bundle:      &config.Params_Bundle{FilePath: path},
+		CheckMethod: "data.mixerauthz.allow",
+	})
+	defer func() { _ = h.Close() }()
+	checkUsers(t, h, map[string]bool{"alice": true, "bob": false})
+
+	// a broken bundle leaves the active policy in place.
+	if err = ioutil.WriteFile(path, []byte("broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = h.refresh(context.Background()); err == nil {
+		t.Error("Got success refreshing a broken bundle, expecting error")
+	}
+	checkUsers(t, h, map[string]bool{"alice": true, "bob": false})
+
+	if err = ioutil.WriteFile(path, tarball(t, bundleFiles("v2", `"bob"`)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = h.refresh(context.Background()); err != nil {
+		t.Fatalf("Got error %v, expecting success", err)
+	}
+	if rev := h.currentPolicy().revision; rev != "v2" {
+		t.Errorf("Got revision %q, expecting v2", rev)
+	}
+	checkUsers(t, h, map[string]bool{"alice": false, "bob": true})
+}
+
+type bundleServer struct {
+	sync.Mutex
+	content  []byte
+	etag     string
+	requests int
+	notMod   int
+}
+
+func (s *bundleServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	s.requests++
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.Header.Get("If-None-Match") == s.etag {
+		s.notMod++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", s.etag)
+	_, _ = w.Write(s.content)
+}
+
+func (s *bundleServer) set(content []byte, etag string) {
+	s.Lock()
+	s.content, s.etag = content, etag
+	s.Unlock()
+}
+
+func TestHTTPBundle(t *testing.T) {
+	s := &bundleServer{}
+	s.set(tarball(t, bundleFiles("v1", `"alice"`)), `"v1"`)
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	h := buildHandler(t, &config.Params{
+		Bundle: &config.Params_Bundle{
+			Url:             srv.URL + "/bundle.tar.gz",
+			Headers:         map[string]string{"Authorization": "Bearer token"},
+			RefreshInterval: 10 * time.Millisecond,
+		},
+		CheckMethod: "data.mixerauthz.allow",
+	})
+	defer func() { _ = h.Close() }()
+	checkUsers(t, h, map[string]bool{"alice": true, "bob": false})
+
+	s.set(tarball(t, bundleFiles("v2", `"bob"`)), `"v2"`)
+	deadline := time.Now().Add(10 * time.Second)
+	for h.currentPolicy().revision != "v2" {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the bundle to be refreshed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	checkUsers(t, h, map[string]bool{"alice": false, "bob": true})
+
+	// the refreshes following the v2 download are conditional.
+	for {
+		s.Lock()
+		notMod, requests := s.notMod, s.requests
+		s.Unlock()
+		if notMod > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Bundle was downloaded on each of %d refreshes, expecting conditional requests", requests)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBundleNotLoaded(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	for _, failClose := range []bool{false, true} {
+		h := buildHandler(t, &config.Params{
+			Bundle:      &config.Params_Bundle{Url: srv.URL, RefreshInterval: time.Hour},
+			CheckMethod: "data.mixerauthz.allow",
+			FailClose:   failClose,
+		})
+		result, err := h.HandleAuthorization(context.Background(), userInstance("alice"))
+		if err != nil {
+			t.Fatalf("Got error %v, expecting success", err)
+		}
+		expected := rpc.OK
+		if failClose {
+			expected = rpc.PERMISSION_DENIED
+		}
+		if result.Status.Code != int32(expected) {
+			t.Errorf("failClose=%v: Got status %v, expecting %v", failClose, result.Status.Code, expected)
+		}
+		_ = h.Close()
+	}
+}
@@ -12,17 +12,23 @@ import (
 	fmt "fmt"
 	_ "github.com/gogo/protobuf/gogoproto"
 	proto "github.com/gogo/protobuf/proto"
+	github_com_gogo_protobuf_sortkeys "github.com/gogo/protobuf/sortkeys"
+	_ "github.com/gogo/protobuf/types"
+	github_com_gogo_protobuf_types "github.com/gogo/protobuf/types"
 	io "io"
 	math "math"
 	math_bits "math/bits"
 	reflect "reflect"
+	strconv "strconv"
 	strings "strings"
+	time "time"
 )
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
+var _ = time.Kitchen
 
 // This is a compile-time assertion to ensure that this generated file
 // is compatible with the proto package it is being compiled against.
@@ -30,36 +36,109 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
+// Destination of decision logs.
+type Params_DecisionLog_Sink int32
+
+const (
+	// Decisions are not logged.
+	NONE Params_DecisionLog_Sink = 0
+	// Decisions are logged through Mixer's own logger at info level.
+	ADAPTER_LOG Params_DecisionLog_Sink = 1
+	// Decisions are written to standard output, one JSON object per line.
+	STDOUT Params_DecisionLog_Sink = 2
+	// Decisions are appended to the file at `path`, one JSON object per line.
+	FILE Params_DecisionLog_Sink = 3
+)
+
+var Params_DecisionLog_Sink_name = map[int32]string{
+	0: "NONE",
+	1: "ADAPTER_LOG",
+	2: "STDOUT",
+	3: "FILE",
+}
+
+var Params_DecisionLog_Sink_value = map[string]int32{
+	"NONE":        0,
+	"ADAPTER_LOG": 1,
+	"STDOUT":      2,
+	"FILE":        3,
+}
+
+func (Params_DecisionLog_Sink) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_05827bfc1c8a686c, []int{0, 1, 0}
+}
+
 // Configuration format for the `opa` adapter.
 //
 // Example configuration:
 // ```yaml
 // policy:
+//
 //   - |+
 //     package mixerauthz
 //     policy = [
-//       {
-//         "rule": {
-//           "verbs": [
-//             "storage.buckets.get"
-//           ],
-//           "users": [
-//             "bucket-admins"
-//           ]
-//         }
-//       }
+//     {
+//     "rule": {
+//     "verbs": [
+//     "storage.buckets.get"
+//     ],
+//     "users": [
+//     "bucket-admins"
+//     ]
+//     }
+//     }
 //     ]
 //
 //     default allow = false
 //
 //     allow = true {
-//       rule = policy[_].rule
-//       input.subject.user = rule.users[_]
-//       input.action.method = rule.verbs[_]
+//     rule = policy[_].rule
+//     input.subject.user = rule.users[_]
+//     input.action.method = rule.verbs[_]
 //     }
+//
 // checkMethod: "data.mixerauthz.allow"
 // failClose: true
 // ```
+//
+// Policies can also be loaded from a bundle, either from local disk or from a
+// bundle server, and are refreshed periodically:
+//
+// ```yaml
+// bundle:
+//
+//	url: https://bundles.example.com/bundles/mixerauthz.tar.gz
+//	refreshInterval: 30s
+//
+// checkMethod: "data.mixerauthz.decision"
+// decisionLog:
+//
+//	sink: STDOUT
+//
+// decisionCache:
+//
+//	maxEntries: 10000
+//	ttl: 10s
+//
+// ```
+//
+// The check method either evaluates to a boolean, or to an object describing a
+// structured decision:
+//
+// ```json
+//
+//	{
+//	  "allow": false,
+//	  "reason": "user is not a bucket admin",
+//	  "status_code": 403,
+//	  "headers": {"x-denied-by": "opa"}
+//	}
+//
+// ```
+//
+// For denied requests, `reason`, `status_code` and `headers` make up the direct
+// response sent to the client. For allowed requests, `headers` are added to the
+// request forwarded to the destination.
 type Params struct {
 	// List of OPA policies
 	Policy []string `protobuf:"bytes,1,rep,name=policy,proto3" json:"policy,omitempty"`
@@ -70,6 +149,13 @@ type Params struct {
 	// If failClose is set to true and there is a runtime error,
 	// instead of disabling the adapter, close the client request
 	FailClose bool `protobuf:"varint,3,opt,name=fail_close,json=failClose,proto3" json:"fail_close,omitempty"`
+	// Policy bundle loaded in addition to the inline `policy`. Exactly one of
+	// `filePath` and `url` must be set.
+	Bundle *Params_Bundle `protobuf:"bytes,4,opt,name=bundle,proto3" json:"bundle,omitempty"`
+	// Decision logging settings. Decisions are not logged by default.
+	DecisionLog *Params_DecisionLog `protobuf:"bytes,5,opt,name=decision_log,json=decisionLog,proto3" json:"decision_log,omitempty"`
+	// Decision cache settings. Decisions are not cached by default.
+	DecisionCache *Params_DecisionCache `protobuf:"bytes,6,opt,name=decision_cache,json=decisionCache,proto3" json:"decision_cache,omitempty"`
 }
 
 func (m *Params) Reset()      { *m = Params{} }
@@ -104,8 +190,151 @@ func (m *Params) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_Params proto.InternalMessageInfo
 
+// Describes where a policy bundle is loaded from.
+//
+// A bundle is a gzipped tarball, or a directory on local disk, that contains
+// Rego policy files (`*.rego`) and data documents (`data.json`). Data documents
+// are mounted under `data` at the path of the directory that contains them.
+type Params_Bundle struct {
+	// Path of a bundle tarball or directory on local disk.
+	FilePath string `protobuf:"bytes,1,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`
+	// URL of a bundle served by a bundle server.
+	Url string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	// Additional headers sent with every request to the bundle server, for example
+	// an authorization header.
+	Headers map[string]string `protobuf:"bytes,3,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// How often the bundle is reloaded. The policy is only recompiled when the
+	// bundle changed; if a refresh fails the previously loaded policy stays active.
+	// Defaults to 1m.
+	RefreshInterval time.Duration `protobuf:"bytes,4,opt,name=refresh_interval,json=refreshInterval,proto3,stdduration" json:"refresh_interval"`
+}
+
+func (m *Params_Bundle) Reset()      { *m = Params_Bundle{} }
+func (*Params_Bundle) ProtoMessage() {}
+func (*Params_Bundle) Descriptor() ([]byte, []int) {
+	return fileDescriptor_05827bfc1c8a686c, []int{0, 0}
+}
+func (m *Params_Bundle) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Params_Bundle) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Params_Bundle.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Params_Bundle) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Params_Bundle.Merge(m, src)
+}
+func (m *Params_Bundle) XXX_Size() int {
+	return m.Size()
+}
+func (m *Params_Bundle) XXX_DiscardUnknown() {
+	xxx_messageInfo_Params_Bundle.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Params_Bundle proto.InternalMessageInfo
+
+// Describes how policy decisions are logged.
+type Params_DecisionLog struct {
+	// Where decisions are logged to.
+	Sink Params_DecisionLog_Sink `protobuf:"varint,1,opt,name=sink,proto3,enum=adapter.opa.config.Params_DecisionLog_Sink" json:"sink,omitempty"`
+	// Path of the decision log file. Required for the `FILE` sink.
+	Path string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// Whether the input document of the query is included in each decision log.
+	IncludeInput bool `protobuf:"varint,3,opt,name=include_input,json=includeInput,proto3" json:"include_input,omitempty"`
+}
+
+func (m *Params_DecisionLog) Reset()      { *m = Params_DecisionLog{} }
+func (*Params_DecisionLog) ProtoMessage() {}
+func (*Params_DecisionLog) Descriptor() ([]byte, []int) {
+	return fileDescriptor_05827bfc1c8a686c, []int{0, 1}
+}
+func (m *Params_DecisionLog) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Params_DecisionLog) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Params_DecisionLog.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Params_DecisionLog) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Params_DecisionLog.Merge(m, src)
+}
+func (m *Params_DecisionLog) XXX_Size() int {
+	return m.Size()
+}
+func (m *Params_DecisionLog) XXX_DiscardUnknown() {
+	xxx_messageInfo_Params_DecisionLog.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Params_DecisionLog proto.InternalMessageInfo
+
+// Describes how policy decisions are cached.
+//
+// Decisions are cached by the values of the input fields the policy actually
+// references, so that requests which differ only in fields the policy ignores
+// share a cache entry. The cache is flushed whenever the policy changes.
+type Params_DecisionCache struct {
+	// Maximum number of cached decisions. Caching is disabled when this is zero.
+	MaxEntries int32 `protobuf:"varint,1,opt,name=max_entries,json=maxEntries,proto3" json:"max_entries,omitempty"`
+	// How long a decision is cached for.
+	// Defaults to 1m.
+	Ttl time.Duration `protobuf:"bytes,2,opt,name=ttl,proto3,stdduration" json:"ttl"`
+}
+
+func (m *Params_DecisionCache) Reset()      { *m = Params_DecisionCache{} }
+func (*Params_DecisionCache) ProtoMessage() {}
+func (*Params_DecisionCache) Descriptor() ([]byte, []int) {
+	return fileDescriptor_05827bfc1c8a686c, []int{0, 2}
+}
+func (m *Params_DecisionCache) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Params_DecisionCache) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Params_DecisionCache.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Params_DecisionCache) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Params_DecisionCache.Merge(m, src)
+}
+func (m *Params_DecisionCache) XXX_Size() int {
+	return m.Size()
+}
+func (m *Params_DecisionCache) XXX_DiscardUnknown() {
+	xxx_messageInfo_Params_DecisionCache.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Params_DecisionCache proto.InternalMessageInfo
+
 func init() {
+	proto.RegisterEnum("adapter.opa.config.Params_DecisionLog_Sink", Params_DecisionLog_Sink_name, Params_DecisionLog_Sink_value)
 	proto.RegisterType((*Params)(nil), "adapter.opa.config.Params")
+	proto.RegisterType((*Params_Bundle)(nil), "adapter.opa.config.Params.Bundle")
+	proto.RegisterMapType((map[string]string)(nil), "adapter.opa.config.Params.Bundle.HeadersEntry")
+	proto.RegisterType((*Params_DecisionLog)(nil), "adapter.opa.config.Params.DecisionLog")
+	proto.RegisterType((*Params_DecisionCache)(nil), "adapter.opa.config.Params.DecisionCache")
 }
 
 func init() {
@@ -113,24 +342,56 @@ func init() {
 }
 
 var fileDescriptor_05827bfc1c8a686c = []byte{
-	// 236 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x52, 0xcd, 0xcd, 0xac, 0x48,
-	0x2d, 0xd2, 0x4f, 0x4c, 0x49, 0x2c, 0x28, 0x49, 0x2d, 0xd2, 0xcf, 0x2f, 0x48, 0xd4, 0x4f, 0xce,
-	0xcf, 0x4b, 0xcb, 0x4c, 0x87, 0x52, 0x7a, 0x05, 0x45, 0xf9, 0x25, 0xf9, 0x42, 0x42, 0x50, 0x05,
-	0x7a, 0xf9, 0x05, 0x89, 0x7a, 0x10, 0x19, 0x29, 0x91, 0xf4, 0xfc, 0xf4, 0x7c, 0xb0, 0xb4, 0x3e,
-	0x88, 0x05, 0x51, 0xa9, 0x94, 0xc4, 0xc5, 0x16, 0x90, 0x58, 0x94, 0x98, 0x5b, 0x2c, 0x24, 0xc6,
-	0xc5, 0x56, 0x90, 0x9f, 0x93, 0x99, 0x5c, 0x29, 0xc1, 0xa8, 0xc0, 0xac, 0xc1, 0x19, 0x04, 0xe5,
-	0x09, 0x29, 0x72, 0xf1, 0x24, 0x67, 0xa4, 0x26, 0x67, 0xc7, 0xe7, 0xa6, 0x96, 0x64, 0xe4, 0xa7,
-	0x48, 0x30, 0x29, 0x30, 0x6a, 0x70, 0x06, 0x71, 0x83, 0xc5, 0x7c, 0xc1, 0x42, 0x42, 0xb2, 0x5c,
-	0x5c, 0x69, 0x89, 0x99, 0x39, 0xf1, 0xc9, 0x39, 0xf9, 0xc5, 0xa9, 0x12, 0xcc, 0x0a, 0x8c, 0x1a,
-	0x1c, 0x41, 0x9c, 0x20, 0x11, 0x67, 0x90, 0x80, 0x93, 0xc3, 0x89, 0x87, 0x72, 0x0c, 0x17, 0x1e,
-	0xca, 0x31, 0xdc, 0x78, 0x28, 0xc7, 0xf0, 0xe1, 0xa1, 0x1c, 0x43, 0xc3, 0x23, 0x39, 0xc6, 0x15,
-	0x8f, 0xe4, 0x18, 0x4e, 0x3c, 0x92, 0x63, 0xbc, 0xf0, 0x48, 0x8e, 0xf1, 0xc1, 0x23, 0x39, 0xc6,
-	0x17, 0x8f, 0xe4, 0x18, 0x3e, 0x3c, 0x92, 0x63, 0x9c, 0xf0, 0x58, 0x8e, 0xe1, 0xc2, 0x63, 0x39,
-	0x86, 0x1b, 0x8f, 0xe5, 0x18, 0xa2, 0xd8, 0x20, 0x6e, 0x4f, 0x62, 0x03, 0x3b, 0xd6, 0x18, 0x10,
-	0x00, 0x00, 0xff, 0xff, 0x8e, 0xb7, 0xf6, 0xf2, 0xff, 0x00, 0x00, 0x00,
+	// 633 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x52, 0xcd, 0x6e, 0xd3, 0x4c,
+	0x14, 0xf5, 0x24, 0xa9, 0xbf, 0xe4, 0x26, 0x6d, 0xa3, 0x51, 0xf5, 0xc9, 0x04, 0x31, 0x4d, 0x8b,
+	0x40, 0x91, 0x90, 0x1c, 0xa9, 0x08, 0x01, 0xdd, 0x40, 0xdb, 0x04, 0x1a, 0xa9, 0x34, 0x95, 0x5b,
+	0x36, 0x6c, 0xac, 0xa9, 0x3d, 0x71, 0x46, 0x71, 0x3c, 0x96, 0x7f, 0xaa, 0x76, 0xc7, 0x0b, 0x20,
+	0xb1, 0xe4, 0x11, 0x78, 0x0c, 0x96, 0x5d, 0x76, 0xd9, 0x15, 0x10, 0x77, 0xc3, 0xb2, 0x1b, 0xf6,
+	0xc8, 0xe3, 0x29, 0x14, 0x21, 0x41, 0x57, 0xbe, 0xf7, 0xcc, 0x39, 0x77, 0xee, 0x9c, 0x63, 0xb8,
+	0x37, 0xe5, 0xc7, 0x2c, 0xea, 0x52, 0x97, 0x86, 0x09, 0x8b, 0xba, 0x22, 0xa4, 0x5d, 0x47, 0x04,
+	0x23, 0xee, 0xa9, 0x8f, 0x19, 0x46, 0x22, 0x11, 0x18, 0x2b, 0x82, 0x29, 0x42, 0x6a, 0x16, 0x27,
+	0xad, 0x25, 0x4f, 0x78, 0x42, 0x1e, 0x77, 0xf3, 0xaa, 0x60, 0xb6, 0x88, 0x27, 0x84, 0xe7, 0xb3,
+	0xae, 0xec, 0x0e, 0xd3, 0x51, 0xd7, 0x4d, 0x23, 0x9a, 0x70, 0x11, 0x14, 0xe7, 0xab, 0xdf, 0x75,
+	0xd0, 0xf7, 0x68, 0x44, 0xa7, 0x31, 0xfe, 0x1f, 0xf4, 0x50, 0xf8, 0xdc, 0x39, 0x31, 0x50, 0xbb,
+	0xdc, 0xa9, 0x59, 0xaa, 0xc3, 0x2b, 0xd0, 0x70, 0xc6, 0xcc, 0x99, 0xd8, 0x53, 0x96, 0x8c, 0x85,
+	0x6b, 0x94, 0xda, 0xa8, 0x53, 0xb3, 0xea, 0x12, 0x7b, 0x25, 0x21, 0x7c, 0x07, 0x60, 0x44, 0xb9,
+	0x6f, 0x3b, 0xbe, 0x88, 0x99, 0x51, 0x6e, 0xa3, 0x4e, 0xd5, 0xaa, 0xe5, 0xc8, 0x56, 0x0e, 0xe0,
+	0xa7, 0xa0, 0x1f, 0xa6, 0x81, 0xeb, 0x33, 0xa3, 0xd2, 0x46, 0x9d, 0xfa, 0xda, 0x8a, 0xf9, 0xe7,
+	0xfe, 0x66, 0xb1, 0x85, 0xb9, 0x29, 0x89, 0x96, 0x12, 0xe0, 0x01, 0x34, 0x5c, 0xe6, 0xf0, 0x98,
+	0x8b, 0xc0, 0xf6, 0x85, 0x67, 0xcc, 0xc9, 0x01, 0xf7, 0xff, 0x32, 0xa0, 0xa7, 0xe8, 0x3b, 0xc2,
+	0xb3, 0xea, 0xee, 0xaf, 0x06, 0x0f, 0x61, 0xe1, 0xe7, 0x28, 0x87, 0x3a, 0x63, 0x66, 0xe8, 0x72,
+	0x58, 0xe7, 0x06, 0xc3, 0xb6, 0x72, 0xbe, 0x35, 0xef, 0x5e, 0x6f, 0x5b, 0xef, 0x4a, 0xa0, 0x17,
+	0xeb, 0xe2, 0xdb, 0x50, 0x1b, 0x71, 0x9f, 0xd9, 0x21, 0x4d, 0xc6, 0x06, 0x92, 0x06, 0x55, 0x73,
+	0x60, 0x8f, 0x26, 0x63, 0xdc, 0x84, 0x72, 0x1a, 0xf9, 0xca, 0xb7, 0xbc, 0xc4, 0xdb, 0xf0, 0xdf,
+	0x98, 0x51, 0x97, 0x45, 0xb1, 0x51, 0x6e, 0x97, 0x3b, 0xf5, 0x35, 0xf3, 0x9f, 0x8e, 0x98, 0xdb,
+	0x85, 0xa0, 0x1f, 0x24, 0xd1, 0x89, 0x75, 0x25, 0xc7, 0xbb, 0xd0, 0x8c, 0xd8, 0x28, 0x62, 0xf1,
+	0xd8, 0xe6, 0x41, 0xc2, 0xa2, 0x23, 0xea, 0x2b, 0x93, 0x6f, 0x99, 0x45, 0xf4, 0xe6, 0x55, 0xf4,
+	0x66, 0x4f, 0x45, 0xbf, 0x59, 0x3d, 0xfd, 0xbc, 0xac, 0x7d, 0xf8, 0xb2, 0x8c, 0xac, 0x45, 0x25,
+	0x1e, 0x28, 0x6d, 0x6b, 0x1d, 0x1a, 0xd7, 0x2f, 0xca, 0x77, 0x9f, 0xb0, 0x13, 0xf5, 0xa4, 0xbc,
+	0xc4, 0x4b, 0x30, 0x77, 0x44, 0xfd, 0x94, 0xa9, 0xf7, 0x14, 0xcd, 0x7a, 0xe9, 0x09, 0x6a, 0x7d,
+	0x42, 0x50, 0xbf, 0xe6, 0x3e, 0x7e, 0x06, 0x95, 0x98, 0x07, 0x13, 0x29, 0x5e, 0x58, 0x7b, 0x70,
+	0xb3, 0xcc, 0xcc, 0x7d, 0x1e, 0x4c, 0x2c, 0x29, 0xc4, 0x18, 0x2a, 0xd2, 0xd0, 0xe2, 0x26, 0x59,
+	0xe3, 0xbb, 0x30, 0xcf, 0x03, 0xc7, 0x4f, 0x5d, 0x66, 0xf3, 0x20, 0x4c, 0x13, 0xf5, 0xb7, 0x35,
+	0x14, 0x38, 0xc8, 0xb1, 0xd5, 0xc7, 0x50, 0xc9, 0xc7, 0xe0, 0x2a, 0x54, 0x76, 0x87, 0xbb, 0xfd,
+	0xa6, 0x86, 0x17, 0xa1, 0xbe, 0xd1, 0xdb, 0xd8, 0x3b, 0xe8, 0x5b, 0xf6, 0xce, 0xf0, 0x65, 0x13,
+	0x61, 0x00, 0x7d, 0xff, 0xa0, 0x37, 0x7c, 0x7d, 0xd0, 0x2c, 0xe5, 0xb4, 0x17, 0x83, 0x9d, 0x7e,
+	0xb3, 0xdc, 0xf2, 0x60, 0xfe, 0xb7, 0xc8, 0xf1, 0x32, 0xd4, 0xa7, 0xf4, 0xd8, 0x66, 0x41, 0x12,
+	0x71, 0x16, 0xcb, 0xa7, 0xcc, 0x59, 0x30, 0xa5, 0xc7, 0xfd, 0x02, 0xc1, 0x8f, 0xa0, 0x9c, 0x24,
+	0x45, 0xb8, 0x37, 0xf4, 0x3c, 0xe7, 0x6f, 0x3e, 0x3f, 0x9d, 0x11, 0xed, 0x6c, 0x46, 0xb4, 0xf3,
+	0x19, 0xd1, 0x2e, 0x67, 0x44, 0x7b, 0x9b, 0x11, 0xf4, 0x31, 0x23, 0xda, 0x69, 0x46, 0xd0, 0x59,
+	0x46, 0xd0, 0xd7, 0x8c, 0xa0, 0x6f, 0x19, 0xd1, 0x2e, 0x33, 0x82, 0xde, 0x5f, 0x10, 0xed, 0xec,
+	0x82, 0x68, 0xe7, 0x17, 0x44, 0x7b, 0xa3, 0x17, 0xd6, 0x1d, 0xea, 0xf2, 0x8e, 0x87, 0x3f, 0x06,
+	0x00, 0xdb, 0x45, 0x61, 0x7f, 0x33, 0x04, 0x00, 0x00,
 }
 
+func (x Params_DecisionLog_Sink) String() string {
+	s, ok := Params_DecisionLog_Sink_name[int32(x)]
+	if ok {
+		return s
+	}
+	return strconv.Itoa(int(x))
+}
 func (m *Params) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -151,6 +412,42 @@ func (m *Params) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.DecisionCache != nil {
+		{
+			size, err := m.DecisionCache.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintConfig(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x32
+	}
+	if m.DecisionLog != nil {
+		{
+			size, err := m.DecisionLog.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintConfig(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x2a
+	}
+	if m.Bundle != nil {
+		{
+			size, err := m.Bundle.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintConfig(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x22
+	}
 	if m.FailClose {
 		i--
 		if m.FailClose {
@@ -180,6 +477,151 @@ func (m *Params) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *Params_Bundle) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Params_Bundle) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Params_Bundle) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	n4, err4 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.RefreshInterval, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.RefreshInterval):])
+	if err4 != nil {
+		return 0, err4
+	}
+	i -= n4
+	i = encodeVarintConfig(dAtA, i, uint64(n4))
+	i--
+	dAtA[i] = 0x22
+	if len(m.Headers) > 0 {
+		for k := range m.Headers {
+			v := m.Headers[k]
+			baseI := i
+			i -= len(v)
+			copy(dAtA[i:], v)
+			i = encodeVarintConfig(dAtA, i, uint64(len(v)))
+			i--
+			dAtA[i] = 0x12
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintConfig(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintConfig(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if len(m.Url) > 0 {
+		i -= len(m.Url)
+		copy(dAtA[i:], m.Url)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.Url)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.FilePath) > 0 {
+		i -= len(m.FilePath)
+		copy(dAtA[i:], m.FilePath)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.FilePath)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Params_DecisionLog) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Params_DecisionLog) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Params_DecisionLog) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.IncludeInput {
+		i--
+		if m.IncludeInput {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Path) > 0 {
+		i -= len(m.Path)
+		copy(dAtA[i:], m.Path)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.Path)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.Sink != 0 {
+		i = encodeVarintConfig(dAtA, i, uint64(m.Sink))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Params_DecisionCache) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Params_DecisionCache) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Params_DecisionCache) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	n5, err5 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.Ttl, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.Ttl):])
+	if err5 != nil {
+		return 0, err5
+	}
+	i -= n5
+	i = encodeVarintConfig(dAtA, i, uint64(n5))
+	i--
+	dAtA[i] = 0x12
+	if m.MaxEntries != 0 {
+		i = encodeVarintConfig(dAtA, i, uint64(m.MaxEntries))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintConfig(dAtA []byte, offset int, v uint64) int {
 	offset -= sovConfig(v)
 	base := offset
@@ -210,6 +652,78 @@ func (m *Params) Size() (n int) {
 	if m.FailClose {
 		n += 2
 	}
+	if m.Bundle != nil {
+		l = m.Bundle.Size()
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	if m.DecisionLog != nil {
+		l = m.DecisionLog.Size()
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	if m.DecisionCache != nil {
+		l = m.DecisionCache.Size()
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	return n
+}
+
+func (m *Params_Bundle) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.FilePath)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	l = len(m.Url)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	if len(m.Headers) > 0 {
+		for k, v := range m.Headers {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovConfig(uint64(len(k))) + 1 + len(v) + sovConfig(uint64(len(v)))
+			n += mapEntrySize + 1 + sovConfig(uint64(mapEntrySize))
+		}
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.RefreshInterval)
+	n += 1 + l + sovConfig(uint64(l))
+	return n
+}
+
+func (m *Params_DecisionLog) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Sink != 0 {
+		n += 1 + sovConfig(uint64(m.Sink))
+	}
+	l = len(m.Path)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	if m.IncludeInput {
+		n += 2
+	}
+	return n
+}
+
+func (m *Params_DecisionCache) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.MaxEntries != 0 {
+		n += 1 + sovConfig(uint64(m.MaxEntries))
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.Ttl)
+	n += 1 + l + sovConfig(uint64(l))
 	return n
 }
 
@@ -227,17 +741,66 @@ func (this *Params) String() string {
 		`Policy:` + fmt.Sprintf("%v", this.Policy) + `,`,
 		`CheckMethod:` + fmt.Sprintf("%v", this.CheckMethod) + `,`,
 		`FailClose:` + fmt.Sprintf("%v", this.FailClose) + `,`,
+		`Bundle:` + strings.Replace(fmt.Sprintf("%v", this.Bundle), "Params_Bundle", "Params_Bundle", 1) + `,`,
+		`DecisionLog:` + strings.Replace(fmt.Sprintf("%v", this.DecisionLog), "Params_DecisionLog", "Params_DecisionLog", 1) + `,`,
+		`DecisionCache:` + strings.Replace(fmt.Sprintf("%v", this.DecisionCache), "Params_DecisionCache", "Params_DecisionCache", 1) + `,`,
 		`}`,
 	}, "")
 	return s
 }
-func valueToStringConfig(v interface{}) string {
-	rv := reflect.ValueOf(v)
-	if rv.IsNil() {
+func (this *Params_Bundle) String() string {
+	if this == nil {
 		return "nil"
 	}
-	pv := reflect.Indirect(rv).Interface()
-	return fmt.Sprintf("*%v", pv)
+	keysForHeaders := make([]string, 0, len(this.Headers))
+	for k, _ := range this.Headers {
+		keysForHeaders = append(keysForHeaders, k)
+	}
+	github_com_gogo_protobuf_sortkeys.Strings(keysForHeaders)
+	mapStringForHeaders := "map[string]string{"
+	for _, k := range keysForHeaders {
+		mapStringForHeaders += fmt.Sprintf("%v: %v,", k, this.Headers[k])
+	}
+	mapStringForHeaders += "}"
+	s := strings.Join([]string{`&Params_Bundle{`,
+		`FilePath:` + fmt.Sprintf("%v", this.FilePath) + `,`,
+		`Url:` + fmt.Sprintf("%v", this.Url) + `,`,
+		`Headers:` + mapStringForHeaders + `,`,
+		`RefreshInterval:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.RefreshInterval), "Duration", "types.Duration", 1), `&`, ``, 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Params_DecisionLog) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Params_DecisionLog{`,
+		`Sink:` + fmt.Sprintf("%v", this.Sink) + `,`,
+		`Path:` + fmt.Sprintf("%v", this.Path) + `,`,
+		`IncludeInput:` + fmt.Sprintf("%v", this.IncludeInput) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Params_DecisionCache) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Params_DecisionCache{`,
+		`MaxEntries:` + fmt.Sprintf("%v", this.MaxEntries) + `,`,
+		`Ttl:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.Ttl), "Duration", "types.Duration", 1), `&`, ``, 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func valueToStringConfig(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("*%v", pv)
 }
 func (m *Params) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
@@ -352,6 +915,620 @@ func (m *Params) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.FailClose = bool(v != 0)
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Bundle", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Bundle == nil {
+				m.Bundle = &Params_Bundle{}
+			}
+			if err := m.Bundle.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DecisionLog", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.DecisionLog == nil {
+				m.DecisionLog = &Params_DecisionLog{}
+			}
+			if err := m.DecisionLog.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DecisionCache", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.DecisionCache == nil {
+				m.DecisionCache = &Params_DecisionCache{}
+			}
+			if err := m.DecisionCache.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipConfig(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Params_Bundle) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowConfig
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Bundle: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Bundle: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FilePath", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.FilePath = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Url", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Url = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Headers", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Headers == nil {
+				m.Headers = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowConfig
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowConfig
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthConfig
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthConfig
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowConfig
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthConfig
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue < 0 {
+						return ErrInvalidLengthConfig
+					}
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipConfig(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthConfig
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Headers[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RefreshInterval", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.RefreshInterval, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipConfig(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Params_DecisionLog) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowConfig
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DecisionLog: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DecisionLog: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Sink", wireType)
+			}
+			m.Sink = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Sink |= Params_DecisionLog_Sink(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Path", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Path = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IncludeInput", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IncludeInput = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipConfig(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Params_DecisionCache) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowConfig
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DecisionCache: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DecisionCache: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxEntries", wireType)
+			}
+			m.MaxEntries = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxEntries |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ttl", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.Ttl, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipConfig(dAtA[iNdEx:])
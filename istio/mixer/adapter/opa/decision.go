@@ -0,0 +1,304 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/open-policy-agent/opa/ast"
+
+	mixerpb "istio.io/api/mixer/v1"
+	policypb "istio.io/api/policy/v1beta1"
+	"istio.io/istio/mixer/adapter/opa/config"
+	"istio.io/istio/mixer/pkg/adapter"
+	"istio.io/istio/mixer/pkg/status"
+)
+
+const rejectedMessage = "opa: request was rejected"
+
+type (
+	// decision is the outcome of a policy query. Policies either return a
+	// boolean, or an object with the same fields as decision.
+	decision struct {
+		Allow      bool              `json:"allow"`
+		Reason     string            `json:"reason,omitempty"`
+		StatusCode int               `json:"status_code,omitempty"`
+		Headers    map[string]string `json:"headers,omitempty"`
+	}
+
+	// decisionLogEntry is a single record of the decision log.
+	decisionLogEntry struct {
+		Timestamp  time.Time              `json:"timestamp"`
+		Query      string                 `json:"query"`
+		Revision   string                 `json:"revision,omitempty"`
+		Input      map[string]interface{} `json:"input,omitempty"`
+		Result     *decision              `json:"result,omitempty"`
+		Error      string                 `json:"error,omitempty"`
+		Cached     bool                   `json:"cached"`
+		DurationMs float64                `json:"duration_ms"`
+	}
+
+	decisionLogger interface {
+		log(e *decisionLogEntry)
+		io.Closer
+	}
+
+	// writerDecisionLogger writes decisions to a stream as JSON lines.
+	writerDecisionLogger struct {
+		mu sync.Mutex
+		w  io.Writer
+	}
+
+	// envDecisionLogger writes decisions through the adapter's logger.
+	envDecisionLogger struct {
+		logger adapter.Logger
+	}
+)
+
+// parseDecision converts the value of a policy query into a decision.
+func parseDecision(value interface{}) (*decision, error) {
+	switch v := value.(type) {
+	case bool:
+		return &decision{Allow: v}, nil
+	case map[string]interface{}:
+		d := &decision{}
+		allow, ok := v["allow"].(bool)
+		if !ok {
+			return nil, fmt.Errorf("decision field 'allow' must be a boolean, got %v", v["allow"])
+		}
+		d.Allow = allow
+		if r, found := v["reason"]; found {
+			if d.Reason, ok = r.(string); !ok {
+				return nil, fmt.Errorf("decision field 'reason' must be a string, got %v", r)
+			}
+		}
+		if c, found := v["status_code"]; found {
+			code, err := toInt(c)
+			if err != nil || code < 100 || code > 599 {
+				return nil, fmt.Errorf("decision field 'status_code' must be an HTTP status code, got %v", c)
+			}
+			d.StatusCode = code
+		}
+		if h, found := v["headers"]; found {
+			headers, ok := h.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("decision field 'headers' must be an object, got %v", h)
+			}
+			d.Headers = make(map[string]string, len(headers))
+			for name, val := range headers {
+				s, ok := val.(string)
+				if !ok {
+					return nil, fmt.Errorf("value of header %s must be a string, got %v", name, val)
+				}
+				d.Headers[name] = s
+			}
+		}
+		return d, nil
+	default:
+		return nil, fmt.Errorf("unexpected decision %v", value)
+	}
+}
+
+func toInt(v interface{}) (int, error) {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := n.Int64()
+		return int(i), err
+	case float64:
+		if n != float64(int(n)) {
+			return 0, fmt.Errorf("%v is not an integer", n)
+		}
+		return int(n), nil
+	case int:
+		return n, nil
+	case int64:
+		return int(n), nil
+	default:
+		return 0, fmt.Errorf("%v is not a number", v)
+	}
+}
+
+// checkResult converts a decision into the result returned to Mixer. Denied
+// requests carry the reason, status code and headers of the decision as the
+// direct response sent to the client; headers of allowed requests are added to
+// the request sent upstream.
+func (d *decision) checkResult() adapter.CheckResult {
+	names := make([]string, 0, len(d.Headers))
+	for name := range d.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if d.Allow {
+		result := adapter.CheckResult{Status: status.OK}
+		if len(names) > 0 {
+			result.RouteDirective = &mixerpb.RouteDirective{}
+			for _, name := range names {
+				result.RouteDirective.RequestHeaderOperations = append(result.RouteDirective.RequestHeaderOperations,
+					mixerpb.HeaderOperation{Name: name, Value: d.Headers[name], Operation: mixerpb.REPLACE})
+			}
+		}
+		return result
+	}
+
+	msg := rejectedMessage
+	if d.Reason != "" {
+		msg = rejectedMessage + ": " + d.Reason
+	}
+	st := status.WithPermissionDenied(msg)
+	if d.StatusCode != 0 || d.Reason != "" || len(d.Headers) > 0 {
+		st.Details = []*types.Any{status.PackErrorDetail(&policypb.DirectHttpResponse{
+			Code:    policypb.HttpStatusCode(d.StatusCode),
+			Body:    d.Reason,
+			Headers: d.Headers,
+		})}
+	}
+	return adapter.CheckResult{Status: st}
+}
+
+// inputPaths returns the paths into the input document referenced by the
+// compiled modules. If a module references the input document as a whole, or
+// through a non-constant key, all is returned as true.
+func inputPaths(compiler *ast.Compiler) (paths [][]string, all bool) {
+	seen := map[string]bool{}
+	for _, m := range compiler.Modules {
+		ast.WalkRefs(m, func(ref ast.Ref) bool {
+			if all || !ref.HasPrefix(ast.InputRootRef) {
+				return false
+			}
+			var p []string
+			for _, t := range ref[1:] {
+				s, ok := t.Value.(ast.String)
+				if !ok {
+					break
+				}
+				p = append(p, string(s))
+			}
+			if len(p) == 0 {
+				all = true
+				return false
+			}
+			if key := strings.Join(p, "\x00"); !seen[key] {
+				seen[key] = true
+				paths = append(paths, p)
+			}
+			return false
+		})
+	}
+	if all {
+		return nil, true
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return strings.Join(paths[i], "\x00") < strings.Join(paths[j], "\x00")
+	})
+	return paths, false
+}
+
+// cacheKey builds a decision cache key out of the referenced parts of the
+// input document.
+func cacheKey(input map[string]interface{}, paths [][]string, all bool) (string, error) {
+	if all {
+		b, err := json.Marshal(input)
+		return string(b), err
+	}
+
+	var sb strings.Builder
+	for _, p := range paths {
+		sb.WriteString(strings.Join(p, "."))
+		sb.WriteByte('=')
+		if v, found := lookup(input, p); found {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return "", err
+			}
+			sb.Write(b)
+		}
+		sb.WriteByte(0)
+	}
+	return sb.String(), nil
+}
+
+func lookup(doc map[string]interface{}, p []string) (interface{}, bool) {
+	var cur interface{} = doc
+	for _, k := range p {
+		obj, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = obj[k]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func newDecisionLogger(cfg *config.Params_DecisionLog, env adapter.Env) (decisionLogger, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	switch cfg.Sink {
+	case config.ADAPTER_LOG:
+		return &envDecisionLogger{logger: env.Logger()}, nil
+	case config.STDOUT:
+		return &writerDecisionLogger{w: os.Stdout}, nil
+	case config.FILE:
+		f, err := os.OpenFile(cfg.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		return &writerDecisionLogger{w: f}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func (l *writerDecisionLogger) log(e *decisionLogEntry) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	b = append(b, '\n')
+
+	l.mu.Lock()
+	_, _ = l.w.Write(b)
+	l.mu.Unlock()
+}
+
+func (l *writerDecisionLogger) Close() error {
+	if f, ok := l.w.(*os.File); ok && f != os.Stdout {
+		return f.Close()
+	}
+	return nil
+}
+
+func (l *envDecisionLogger) log(e *decisionLogEntry) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	l.logger.Infof("opa decision: %s", b)
+}
+
+func (l *envDecisionLogger) Close() error {
+	return nil
+}
@@ -20,35 +20,73 @@ package opa // import "istio.io/istio/mixer/adapter/opa"
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"sync"
+	"time"
 
 	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/storage"
+	"github.com/open-policy-agent/opa/storage/inmem"
 
 	"istio.io/istio/mixer/adapter/metadata"
 	"istio.io/istio/mixer/adapter/opa/config"
 	"istio.io/istio/mixer/pkg/adapter"
 	"istio.io/istio/mixer/pkg/status"
 	"istio.io/istio/mixer/template/authorization"
+	"istio.io/pkg/cache"
 )
 
 type (
 	builder struct {
 		adapterConfig *config.Params
 		configErrors  []error
+		modules       map[string]*ast.Module
 		compiler      *ast.Compiler
 	}
 
 	handler struct {
 		checkMethod    string
-		compiler       *ast.Compiler
 		logger         adapter.Logger
 		hasConfigError bool
 		failClose      bool
+
+		// inline policies, compiled together with every version of the bundle.
+		modules map[string]*ast.Module
+		loader  bundleLoader
+
+		policyMu sync.RWMutex
+		policy   *policy
+
+		cache          cache.ExpiringCache
+		decisionLogger decisionLogger
+		includeInput   bool
+
+		stopCh    chan struct{}
+		closeOnce sync.Once
+	}
+
+	// policy is a compiled set of policies along with the data they use. A new
+	// policy is compiled every time the bundle changes.
+	policy struct {
+		compiler *ast.Compiler
+		store    storage.Store
+		revision string
+		digest   string
+		// generation distinguishes cached decisions of different policies.
+		generation uint64
+
+		// input fields referenced by the policy, used to build cache keys.
+		inputPaths [][]string
+		wholeInput bool
 	}
 )
 
 const (
 	policyFileNamePrefix = "opa_policy"
+
+	defaultRefreshInterval = time.Minute
+	defaultCacheTTL        = time.Minute
 )
 
 ///////////////// Configuration Methods ///////////////
@@ -94,6 +132,16 @@ func (b *builder) Validate() (ce *adapter.ConfigErrors) {
 		return
 	}
 
+	b.modules = modules
+	if ce = b.validateExtensions(ce); ce != nil || len(b.configErrors) > 0 {
+		return
+	}
+
+	// policies loaded from a bundle are compiled once the bundle is loaded.
+	if b.adapterConfig.Bundle != nil {
+		return
+	}
+
 	if parsedModuleCount == 0 {
 		msg := "policies are not configured"
 		if b.adapterConfig.FailClose {
@@ -121,18 +169,195 @@ func (b *builder) Validate() (ce *adapter.ConfigErrors) {
 	return
 }
 
+// validateExtensions validates the bundle, decision log and decision cache settings.
+func (b *builder) validateExtensions(ce *adapter.ConfigErrors) *adapter.ConfigErrors {
+	appendErr := func(field, format string, args ...interface{}) {
+		if b.adapterConfig.FailClose {
+			b.configErrors = append(b.configErrors, fmt.Errorf(format, args...))
+		} else {
+			ce = ce.Appendf(field, format, args...)
+		}
+	}
+
+	if bundle := b.adapterConfig.Bundle; bundle != nil {
+		switch {
+		case bundle.FilePath == "" && bundle.Url == "":
+			appendErr("Bundle", "either filePath or url must be set")
+		case bundle.FilePath != "" && bundle.Url != "":
+			appendErr("Bundle", "only one of filePath and url may be set")
+		case bundle.Url != "":
+			if u, err := url.Parse(bundle.Url); err != nil {
+				appendErr("Bundle", "url is malformed: %v", err)
+			} else if u.Scheme != "http" && u.Scheme != "https" {
+				appendErr("Bundle", "url scheme must be http or https, got %q", u.Scheme)
+			}
+		}
+		if bundle.RefreshInterval < 0 {
+			appendErr("Bundle", "refreshInterval must be positive")
+		}
+	}
+
+	if log := b.adapterConfig.DecisionLog; log != nil {
+		if log.Sink == config.FILE && log.Path == "" {
+			appendErr("DecisionLog", "path is required for the FILE sink")
+		}
+	}
+
+	if c := b.adapterConfig.DecisionCache; c != nil {
+		if c.MaxEntries < 0 {
+			appendErr("DecisionCache", "maxEntries must not be negative")
+		}
+		if c.Ttl < 0 {
+			appendErr("DecisionCache", "ttl must be positive")
+		}
+	}
+	return ce
+}
+
 func (b *builder) Build(context context.Context, env adapter.Env) (adapter.Handler, error) {
 	for _, err := range b.configErrors {
 		_ = env.Logger().Errorf("%v", err)
 	}
 
-	return &handler{
-		compiler:       b.compiler,
+	h := &handler{
 		checkMethod:    b.adapterConfig.CheckMethod,
 		failClose:      b.adapterConfig.FailClose,
 		logger:         env.Logger(),
 		hasConfigError: len(b.configErrors) > 0,
-	}, nil
+		modules:        b.modules,
+		stopCh:         make(chan struct{}),
+	}
+	if h.hasConfigError {
+		return h, nil
+	}
+
+	if b.compiler != nil {
+		h.setPolicy(newPolicy(b.compiler, inmem.New(), "", ""))
+	}
+
+	if c := b.adapterConfig.DecisionCache; c != nil && c.MaxEntries > 0 {
+		ttl := c.Ttl
+		if ttl == 0 {
+			ttl = defaultCacheTTL
+		}
+		h.cache = cache.NewLRU(ttl, ttl, c.MaxEntries)
+	}
+
+	var err error
+	if h.decisionLogger, err = newDecisionLogger(b.adapterConfig.DecisionLog, env); err != nil {
+		return nil, fmt.Errorf("opa: could not open decision log: %v", err)
+	}
+	if log := b.adapterConfig.DecisionLog; log != nil {
+		h.includeInput = log.IncludeInput
+	}
+
+	if bundle := b.adapterConfig.Bundle; bundle != nil {
+		if bundle.FilePath != "" {
+			h.loader = newFileBundleLoader(bundle.FilePath)
+		} else {
+			h.loader = newHTTPBundleLoader(bundle.Url, bundle.Headers)
+		}
+
+		// until the bundle is loaded, requests are handled as runtime errors.
+		if err := h.refresh(context); err != nil {
+			_ = env.Logger().Errorf("opa: could not load bundle from %s: %v", h.loader, err)
+		}
+
+		interval := bundle.RefreshInterval
+		if interval == 0 {
+			interval = defaultRefreshInterval
+		}
+		env.ScheduleDaemon(func() { h.refreshLoop(interval) })
+	}
+
+	return h, nil
+}
+
+func newPolicy(compiler *ast.Compiler, store storage.Store, revision, digest string) *policy {
+	paths, whole := inputPaths(compiler)
+	return &policy{
+		compiler:   compiler,
+		store:      store,
+		revision:   revision,
+		digest:     digest,
+		inputPaths: paths,
+		wholeInput: whole,
+	}
+}
+
+// compilePolicy compiles the inline policies together with the given bundle.
+func compilePolicy(inline map[string]*ast.Module, b *bundle) (*policy, error) {
+	modules := make(map[string]*ast.Module, len(inline)+len(b.modules))
+	for name, m := range inline {
+		modules[name] = m
+	}
+	for name, m := range b.modules {
+		modules["bundle"+name] = m
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("policies are not configured")
+	}
+
+	compiler := ast.NewCompiler()
+	compiler.Compile(modules)
+	if compiler.Failed() {
+		return nil, compiler.Errors
+	}
+	return newPolicy(compiler, inmem.NewFromObject(b.data), b.revision, b.digest), nil
+}
+
+func (h *handler) currentPolicy() *policy {
+	h.policyMu.RLock()
+	defer h.policyMu.RUnlock()
+	return h.policy
+}
+
+func (h *handler) setPolicy(p *policy) {
+	h.policyMu.Lock()
+	if h.policy != nil {
+		p.generation = h.policy.generation + 1
+	}
+	h.policy = p
+	h.policyMu.Unlock()
+
+	if h.cache != nil {
+		h.cache.RemoveAll()
+	}
+}
+
+// refresh loads the bundle and activates it if it changed. The active policy
+// is kept if the bundle cannot be loaded or compiled.
+func (h *handler) refresh(ctx context.Context) error {
+	b, err := h.loader.load(ctx)
+	if err != nil || b == nil {
+		return err
+	}
+	if cur := h.currentPolicy(); cur != nil && cur.digest == b.digest {
+		return nil
+	}
+
+	p, err := compilePolicy(h.modules, b)
+	if err != nil {
+		return err
+	}
+	h.setPolicy(p)
+	h.logger.Infof("opa: loaded bundle from %s (revision %q)", h.loader, b.revision)
+	return nil
+}
+
+func (h *handler) refreshLoop(interval time.Duration) {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-h.stopCh:
+			return
+		case <-tick.C:
+			if err := h.refresh(context.Background()); err != nil {
+				h.logger.Warningf("opa: could not refresh bundle from %s: %v", h.loader, err)
+			}
+		}
+	}
 }
 
 ////////////////// Runtime Methods //////////////////////////
@@ -211,42 +436,90 @@ func (h *handler) HandleAuthorization(context context.Context, instance *authori
 		return h.handleFailClose(fmt.Errorf("opa: request was rejected"))
 	}
 
-	// eval rego policy scripts
-	rs, err := rego.New(
-		rego.Compiler(h.compiler),
-		rego.Query(h.checkMethod),
-		rego.Input(map[string]interface{}{
-			"action":  convertActionObjectToMap(instance.Action),
-			"subject": convertSubjectObjectToMap(instance.Subject),
-		}),
-	).Eval(context)
+	p := h.currentPolicy()
+	if p == nil {
+		return h.handleFailClose(fmt.Errorf("opa: request was rejected. err: policy bundle is not loaded"))
+	}
+
+	input := map[string]interface{}{
+		"action":  convertActionObjectToMap(instance.Action),
+		"subject": convertSubjectObjectToMap(instance.Subject),
+	}
+
+	start := time.Now()
+	d, cached, err := h.decide(context, p, input)
+
+	if h.decisionLogger != nil {
+		e := &decisionLogEntry{
+			Timestamp:  start,
+			Query:      h.checkMethod,
+			Revision:   p.revision,
+			Result:     d,
+			Cached:     cached,
+			DurationMs: float64(time.Since(start)) / float64(time.Millisecond),
+		}
+		if err != nil {
+			e.Error = err.Error()
+		}
+		if h.includeInput {
+			e.Input = input
+		}
+		h.decisionLogger.log(e)
+	}
 
 	// Handle errors from OPA engine, policy scripts
 	if err != nil {
-		return h.handleFailClose(fmt.Errorf("opa: request was rejected. err: %v", err))
+		return h.handleFailClose(err)
 	}
 
-	if len(rs) != 1 {
-		return h.handleFailClose(fmt.Errorf("opa: request was rejected"))
+	return d.checkResult(), nil
+}
+
+// decide returns the decision of the policy for the given input, and whether
+// it was served from the decision cache.
+func (h *handler) decide(ctx context.Context, p *policy, input map[string]interface{}) (*decision, bool, error) {
+	var key string
+	if h.cache != nil {
+		k, err := cacheKey(input, p.inputPaths, p.wholeInput)
+		if err == nil {
+			key = fmt.Sprintf("%d\x00%s", p.generation, k)
+			if d, found := h.cache.Get(key); found {
+				return d.(*decision), true, nil
+			}
+		}
 	}
 
-	result, ok := rs[0].Expressions[0].Value.(bool)
-	if !ok {
-		return h.handleFailClose(fmt.Errorf("opa: request was rejected"))
+	// eval rego policy scripts
+	rs, err := rego.New(
+		rego.Compiler(p.compiler),
+		rego.Store(p.store),
+		rego.Query(h.checkMethod),
+		rego.Input(input),
+	).Eval(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("opa: request was rejected. err: %v", err)
 	}
 
-	// rejected by policy scripts
-	if !result {
-		return adapter.CheckResult{
-			Status: status.WithPermissionDenied("opa: request was rejected"),
-		}, nil
+	if len(rs) != 1 || len(rs[0].Expressions) != 1 {
+		return nil, false, fmt.Errorf("opa: request was rejected")
 	}
 
-	// Accepted
-	return adapter.CheckResult{Status: status.OK}, nil
+	d, err := parseDecision(rs[0].Expressions[0].Value)
+	if err != nil {
+		return nil, false, fmt.Errorf("opa: request was rejected. err: %v", err)
+	}
+
+	if key != "" {
+		h.cache.Set(key, d)
+	}
+	return d, false, nil
 }
 
 func (h *handler) Close() error {
+	h.closeOnce.Do(func() { close(h.stopCh) })
+	if h.decisionLogger != nil {
+		return h.decisionLogger.Close()
+	}
 	return nil
 }
 
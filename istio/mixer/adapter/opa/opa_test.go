@@ -19,13 +19,20 @@ package opa
 
 import (
 	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	rpc "istio.io/gogo-genproto/googleapis/google/rpc"
 
 	"istio.io/istio/mixer/adapter/opa/config"
 	"istio.io/istio/mixer/pkg/adapter/test"
+	"istio.io/istio/mixer/pkg/status"
 	"istio.io/istio/mixer/template/authorization"
 )
 
@@ -454,6 +461,251 @@ func TestFailOpenClose(t *testing.T) {
 	}
 }
 
+const structuredPolicy = `package mixerauthz
+
+default decision = {"allow": false, "reason": "unknown user", "status_code": 401, "headers": {"www-authenticate": "Bearer"}}
+
+decision = {"allow": true, "headers": {"x-user": input.subject.user}} {
+  input.subject.user = "alice"
+}
+
+decision = {"allow": false, "reason": "bob is suspended", "status_code": 403} {
+  input.subject.user = "bob"
+}`
+
+func buildHandler(t *testing.T, cfg *config.Params) *handler {
+	t.Helper()
+	b := GetInfo().NewBuilder().(*builder)
+	b.SetAdapterConfig(cfg)
+	if err := b.Validate(); err != nil {
+		t.Fatalf("Got error %v, expecting success", err)
+	}
+	h, err := b.Build(context.Background(), test.NewEnv(t))
+	if err != nil {
+		t.Fatalf("Got error %v, expecting success", err)
+	}
+	return h.(*handler)
+}
+
+func userInstance(user string) *authorization.Instance {
+	return &authorization.Instance{
+		Subject: &authorization.Subject{User: user},
+		Action:  &authorization.Action{Method: "GET", Path: "/" + user},
+	}
+}
+
+func TestStructuredDecision(t *testing.T) {
+	h := buildHandler(t, &config.Params{
+		Policy:      []string{structuredPolicy},
+		CheckMethod: "data.mixerauthz.decision",
+	})
+	defer func() { _ = h.Close() }()
+
+	cases := []struct {
+		user       string
+		code       rpc.Code
+		message    string
+		httpCode   int
+		headers    map[string]string
+		reqHeaders int
+	}{
+		{user: "alice", code: rpc.OK, reqHeaders: 1},
+		{user: "bob", code: rpc.PERMISSION_DENIED, message: "opa: request was rejected: bob is suspended", httpCode: 403},
+		{user: "eve", code: rpc.PERMISSION_DENIED, message: "opa: request was rejected: unknown user", httpCode: 401,
+			headers: map[string]string{"www-authenticate": "Bearer"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.user, func(t *testing.T) {
+			result, err := h.HandleAuthorization(context.Background(), userInstance(c.user))
+			if err != nil {
+				t.Fatalf("Got error %v, expecting success", err)
+			}
+			if result.Status.Code != int32(c.code) {
+				t.Fatalf("Got status %v, expecting %v", result.Status.Code, c.code)
+			}
+			if c.code == rpc.OK {
+				if result.RouteDirective == nil || len(result.RouteDirective.RequestHeaderOperations) != c.reqHeaders {
+					t.Fatalf("Got route directive %v, expecting %d request header operations", result.RouteDirective, c.reqHeaders)
+				}
+				if hop := result.RouteDirective.RequestHeaderOperations[0]; hop.Name != "x-user" || hop.Value != c.user {
+					t.Errorf("Got header operation %v, expecting x-user: %s", hop, c.user)
+				}
+				return
+			}
+			if result.Status.Message != c.message {
+				t.Errorf("Got message %q, expecting %q", result.Status.Message, c.message)
+			}
+			response := status.GetDirectHTTPResponse(result.Status)
+			if response == nil {
+				t.Fatalf("Got no direct response in %v", result.Status)
+			}
+			if int(response.Code) != c.httpCode {
+				t.Errorf("Got response code %v, expecting %d", response.Code, c.httpCode)
+			}
+			if len(c.headers) > 0 && !reflect.DeepEqual(response.Headers, c.headers) {
+				t.Errorf("Got response headers %v, expecting %v", response.Headers, c.headers)
+			}
+		})
+	}
+}
+
+func TestParseDecision(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected *decision
+		err      string
+	}{
+		{value: true, expected: &decision{Allow: true}},
+		{value: map[string]interface{}{"allow": false, "status_code": json.Number("429")},
+			expected: &decision{StatusCode: 429}},
+		{value: map[string]interface{}{"reason": "missing allow"}, err: "'allow' must be a boolean"},
+		{value: map[string]interface{}{"allow": false, "status_code": json.Number("42")}, err: "'status_code'"},
+		{value: map[string]interface{}{"allow": true, "headers": map[string]interface{}{"x": json.Number("1")}}, err: "header x"},
+		{value: "yes", err: "unexpected decision"},
+	}
+
+	for _, c := range cases {
+		d, err := parseDecision(c.value)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("parseDecision(%v) = %v, expecting error containing %q", c.value, err, c.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseDecision(%v) failed: %v", c.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(d, c.expected) {
+			t.Errorf("parseDecision(%v) = %v, expecting %v", c.value, d, c.expected)
+		}
+	}
+}
+
+func TestDecisionCache(t *testing.T) {
+	h := buildHandler(t, &config.Params{
+		Policy:        []string{structuredPolicy},
+		CheckMethod:   "data.mixerauthz.decision",
+		DecisionCache: &config.Params_DecisionCache{MaxEntries: 10, Ttl: time.Minute},
+	})
+	defer func() { _ = h.Close() }()
+
+	p := h.currentPolicy()
+	if p.wholeInput || !reflect.DeepEqual(p.inputPaths, [][]string{{"subject", "user"}}) {
+		t.Fatalf("Got input paths %v (whole: %v), expecting [[subject user]]", p.inputPaths, p.wholeInput)
+	}
+
+	input := func(user, path string) map[string]interface{} {
+		i := userInstance(user)
+		i.Action.Path = path
+		return map[string]interface{}{
+			"action":  convertActionObjectToMap(i.Action),
+			"subject": convertSubjectObjectToMap(i.Subject),
+		}
+	}
+
+	if _, cached, err := h.decide(context.Background(), p, input("alice", "/a")); err != nil || cached {
+		t.Fatalf("decide() = %v, cached %v; expecting uncached decision", err, cached)
+	}
+	// the path is not referenced by the policy, so the decision is reused.
+	d, cached, err := h.decide(context.Background(), p, input("alice", "/b"))
+	if err != nil || !cached || !d.Allow {
+		t.Fatalf("decide() = %v, %v, cached %v; expecting cached allow", d, err, cached)
+	}
+	if _, cached, _ = h.decide(context.Background(), p, input("bob", "/a")); cached {
+		t.Error("decide() for a different user was served from the cache")
+	}
+
+	// a new policy does not reuse decisions of the previous one.
+	h.setPolicy(newPolicy(p.compiler, p.store, "", ""))
+	if _, cached, _ = h.decide(context.Background(), h.currentPolicy(), input("alice", "/a")); cached {
+		t.Error("decide() after a policy change was served from the cache")
+	}
+}
+
+func TestDecisionLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	path := filepath.Join(dir, "decisions.log")
+
+	h := buildHandler(t, &config.Params{
+		Policy:      []string{structuredPolicy},
+		CheckMethod: "data.mixerauthz.decision",
+		DecisionLog: &config.Params_DecisionLog{Sink: config.FILE, Path: path, IncludeInput: true},
+	})
+	for _, user := range []string{"alice", "bob"} {
+		if _, err = h.HandleAuthorization(context.Background(), userInstance(user)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Got %d decision log lines, expecting 2: %s", len(lines), content)
+	}
+	var e decisionLogEntry
+	if err = json.Unmarshal([]byte(lines[1]), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Query != "data.mixerauthz.decision" || e.Result == nil || e.Result.Allow || e.Result.StatusCode != 403 {
+		t.Errorf("Got decision log entry %+v", e)
+	}
+	if e.Input == nil {
+		t.Error("Got decision log entry without input")
+	}
+}
+
+func TestValidateExtensions(t *testing.T) {
+	cases := map[string]struct {
+		cfg      *config.Params
+		expected string
+	}{
+		"bundle without source": {
+			cfg:      &config.Params{CheckMethod: "data.a.allow", Bundle: &config.Params_Bundle{}},
+			expected: "either filePath or url must be set",
+		},
+		"bundle with two sources": {
+			cfg: &config.Params{CheckMethod: "data.a.allow",
+				Bundle: &config.Params_Bundle{FilePath: "/tmp/bundle", Url: "http://localhost/bundle"}},
+			expected: "only one of filePath and url may be set",
+		},
+		"bundle with bad url": {
+			cfg:      &config.Params{CheckMethod: "data.a.allow", Bundle: &config.Params_Bundle{Url: "ftp://localhost"}},
+			expected: "url scheme",
+		},
+		"file decision log without path": {
+			cfg: &config.Params{CheckMethod: "data.a.allow", Policy: []string{"package a\nallow = true"},
+				DecisionLog: &config.Params_DecisionLog{Sink: config.FILE}},
+			expected: "path is required",
+		},
+		"negative cache size": {
+			cfg: &config.Params{CheckMethod: "data.a.allow", Policy: []string{"package a\nallow = true"},
+				DecisionCache: &config.Params_DecisionCache{MaxEntries: -1}},
+			expected: "maxEntries",
+		},
+	}
+
+	for id, c := range cases {
+		b := GetInfo().NewBuilder().(*builder)
+		b.SetAdapterConfig(c.cfg)
+		err := b.Validate()
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("%v: Got %v, expecting error containing %q", id, err, c.expected)
+		}
+	}
+}
+
 func contains(s []string, e string) bool {
 	for _, a := range s {
 		if a == e {
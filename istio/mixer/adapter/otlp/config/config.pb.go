@@ -0,0 +1,1878 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: mixer/adapter/otlp/config/config.proto
+
+// The `otlp` adapter exports Istio telemetry to any backend that speaks the
+// [OpenTelemetry protocol](https://github.com/open-telemetry/opentelemetry-proto)
+// over gRPC, typically an OpenTelemetry collector.
+//
+// This adapter supports the [metric template](https://istio.io/docs/reference/config/policy-and-telemetry/templates/metric/),
+// the [logentry template](https://istio.io/docs/reference/config/policy-and-telemetry/templates/logentry/)
+// and the [tracespan template](https://istio.io/docs/reference/config/policy-and-telemetry/templates/tracespan/).
+
+package config
+
+import (
+	encoding_binary "encoding/binary"
+	fmt "fmt"
+	_ "github.com/gogo/protobuf/gogoproto"
+	proto "github.com/gogo/protobuf/proto"
+	github_com_gogo_protobuf_sortkeys "github.com/gogo/protobuf/sortkeys"
+	_ "github.com/gogo/protobuf/types"
+	github_com_gogo_protobuf_types "github.com/gogo/protobuf/types"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+	reflect "reflect"
+	strconv "strconv"
+	strings "strings"
+	time "time"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+var _ = time.Kitchen
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+// Describes what kind of metric this is.
+type Params_MetricInfo_Kind int32
+
+const (
+	UNSPECIFIED  Params_MetricInfo_Kind = 0
+	GAUGE        Params_MetricInfo_Kind = 1
+	COUNTER      Params_MetricInfo_Kind = 2
+	DISTRIBUTION Params_MetricInfo_Kind = 3
+)
+
+var Params_MetricInfo_Kind_name = map[int32]string{
+	0: "UNSPECIFIED",
+	1: "GAUGE",
+	2: "COUNTER",
+	3: "DISTRIBUTION",
+}
+
+var Params_MetricInfo_Kind_value = map[string]int32{
+	"UNSPECIFIED":  0,
+	"GAUGE":        1,
+	"COUNTER":      2,
+	"DISTRIBUTION": 3,
+}
+
+func (Params_MetricInfo_Kind) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_db8256f5344d6f8d, []int{0, 2, 0}
+}
+
+// Configuration format for the `otlp` adapter.
+//
+// Instances are queued by the handler and pushed to the collector in batches.
+// Metric instances received between two pushes are aggregated into a single
+// data point per metric and label set, using delta temporality.
+type Params struct {
+	// Required. Address of the OTLP/gRPC collector. Example: otel-collector.istio-system:4317
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// Connect to the collector without transport security.
+	Insecure bool `protobuf:"varint,2,opt,name=insecure,proto3" json:"insecure,omitempty"`
+	// Path to a PEM encoded CA bundle used to verify the collector's certificate.
+	// Ignored if `insecure` is set. If empty, the system roots are used.
+	CaCertificatePath string `protobuf:"bytes,3,opt,name=ca_certificate_path,json=caCertificatePath,proto3" json:"ca_certificate_path,omitempty"`
+	// Additional gRPC metadata sent with every export request, for example an
+	// authentication token expected by the collector.
+	Headers map[string]string `protobuf:"bytes,4,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Resource attributes attached to all exported telemetry. Example: `service.name: istio-telemetry`
+	ResourceAttributes map[string]string `protobuf:"bytes,5,rep,name=resource_attributes,json=resourceAttributes,proto3" json:"resource_attributes,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The set of metrics to export. Metric instances that have no corresponding
+	// entry here are rejected at configuration time.
+	Metrics []*Params_MetricInfo `protobuf:"bytes,6,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	// Controls the size of the queue of instances awaiting export. If the
+	// collector falls behind and the queue is full, the handler rejects new
+	// instances until the queue drains.
+	// Defaults to 1024.
+	InstanceBufferSize int64 `protobuf:"varint,7,opt,name=instance_buffer_size,json=instanceBufferSize,proto3" json:"instance_buffer_size,omitempty"`
+	// Maximum number of queued items sent in a single export request. Once this
+	// many items are queued, the handler pushes them without waiting for the push interval.
+	// Defaults to 512.
+	MaxBatchSize int64 `protobuf:"varint,8,opt,name=max_batch_size,json=maxBatchSize,proto3" json:"max_batch_size,omitempty"`
+	// Duration interval for pushing batched data to the collector. At least once every duration,
+	// the handler will attempt to push data.
+	// Defaults to 10s.
+	PushIntervalDuration time.Duration `protobuf:"bytes,9,opt,name=push_interval_duration,json=pushIntervalDuration,proto3,stdduration" json:"push_interval_duration"`
+	// Timeout for a single export request to the collector.
+	// Defaults to 10s.
+	PushTimeoutDuration time.Duration `protobuf:"bytes,10,opt,name=push_timeout_duration,json=pushTimeoutDuration,proto3,stdduration" json:"push_timeout_duration"`
+	// Retry behavior for export requests that fail with a retryable status code.
+	Retry *Params_RetryPolicy `protobuf:"bytes,11,opt,name=retry,proto3" json:"retry,omitempty"`
+}
+
+func (m *Params) Reset()      { *m = Params{} }
+func (*Params) ProtoMessage() {}
+func (*Params) Descriptor() ([]byte, []int) {
+	return fileDescriptor_db8256f5344d6f8d, []int{0}
+}
+func (m *Params) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Params) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Params.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Params) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Params.Merge(m, src)
+}
+func (m *Params) XXX_Size() int {
+	return m.Size()
+}
+func (m *Params) XXX_DiscardUnknown() {
+	xxx_messageInfo_Params.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Params proto.InternalMessageInfo
+
+// Describes how a metric instance should be represented in OTLP.
+type Params_MetricInfo struct {
+	// Required. The fully qualified name of the Istio metric instance that this
+	// MetricInfo processes.
+	InstanceName string `protobuf:"bytes,1,opt,name=instance_name,json=instanceName,proto3" json:"instance_name,omitempty"`
+	// Optional. The name of the exported metric. If not specified, the
+	// instance name is used.
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Optional. A human readable description of this metric.
+	Description string `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	// Optional. The unit in which the metric value is reported, following
+	// the [UCUM](https://unitsofmeasure.org/ucum.html) conventions.
+	Unit string                 `protobuf:"bytes,4,opt,name=unit,proto3" json:"unit,omitempty"`
+	Kind Params_MetricInfo_Kind `protobuf:"varint,5,opt,name=kind,proto3,enum=adapter.otlp.config.Params_MetricInfo_Kind" json:"kind,omitempty"`
+	// For metrics with a metric kind of DISTRIBUTION, the explicit upper
+	// bounds of the histogram buckets. The values must be monotonically increasing.
+	Bounds []float64 `protobuf:"fixed64,6,rep,packed,name=bounds,proto3" json:"bounds,omitempty"`
+}
+
+func (m *Params_MetricInfo) Reset()      { *m = Params_MetricInfo{} }
+func (*Params_MetricInfo) ProtoMessage() {}
+func (*Params_MetricInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_db8256f5344d6f8d, []int{0, 2}
+}
+func (m *Params_MetricInfo) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Params_MetricInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Params_MetricInfo.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Params_MetricInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Params_MetricInfo.Merge(m, src)
+}
+func (m *Params_MetricInfo) XXX_Size() int {
+	return m.Size()
+}
+func (m *Params_MetricInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_Params_MetricInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Params_MetricInfo proto.InternalMessageInfo
+
+// Describes how failed export requests are retried.
+type Params_RetryPolicy struct {
+	// Delay before the first retry. Subsequent delays grow exponentially.
+	// Defaults to 100ms.
+	InitialInterval time.Duration `protobuf:"bytes,1,opt,name=initial_interval,json=initialInterval,proto3,stdduration" json:"initial_interval"`
+	// Upper bound for the delay between two retries.
+	// Defaults to 5s.
+	MaxInterval time.Duration `protobuf:"bytes,2,opt,name=max_interval,json=maxInterval,proto3,stdduration" json:"max_interval"`
+	// Total time spent retrying a batch before it is dropped.
+	// Defaults to 1m.
+	MaxElapsedTime time.Duration `protobuf:"bytes,3,opt,name=max_elapsed_time,json=maxElapsedTime,proto3,stdduration" json:"max_elapsed_time"`
+	// Do not retry failed export requests.
+	Disabled bool `protobuf:"varint,4,opt,name=disabled,proto3" json:"disabled,omitempty"`
+}
+
+func (m *Params_RetryPolicy) Reset()      { *m = Params_RetryPolicy{} }
+func (*Params_RetryPolicy) ProtoMessage() {}
+func (*Params_RetryPolicy) Descriptor() ([]byte, []int) {
+	return fileDescriptor_db8256f5344d6f8d, []int{0, 3}
+}
+func (m *Params_RetryPolicy) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Params_RetryPolicy) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Params_RetryPolicy.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Params_RetryPolicy) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Params_RetryPolicy.Merge(m, src)
+}
+func (m *Params_RetryPolicy) XXX_Size() int {
+	return m.Size()
+}
+func (m *Params_RetryPolicy) XXX_DiscardUnknown() {
+	xxx_messageInfo_Params_RetryPolicy.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Params_RetryPolicy proto.InternalMessageInfo
+
+func init() {
+	proto.RegisterEnum("adapter.otlp.config.Params_MetricInfo_Kind", Params_MetricInfo_Kind_name, Params_MetricInfo_Kind_value)
+	proto.RegisterType((*Params)(nil), "adapter.otlp.config.Params")
+	proto.RegisterMapType((map[string]string)(nil), "adapter.otlp.config.Params.HeadersEntry")
+	proto.RegisterMapType((map[string]string)(nil), "adapter.otlp.config.Params.ResourceAttributesEntry")
+	proto.RegisterType((*Params_MetricInfo)(nil), "adapter.otlp.config.Params.MetricInfo")
+	proto.RegisterType((*Params_RetryPolicy)(nil), "adapter.otlp.config.Params.RetryPolicy")
+}
+
+func init() {
+	proto.RegisterFile("mixer/adapter/otlp/config/config.proto", fileDescriptor_db8256f5344d6f8d)
+}
+
+var fileDescriptor_db8256f5344d6f8d = []byte{
+	// 784 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x94, 0x4f, 0x8f, 0xdb, 0x44,
+	0x18, 0xc6, 0x3d, 0xf9, 0xbf, 0xaf, 0x97, 0xd6, 0xcc, 0x2e, 0xc5, 0xe4, 0xe0, 0x46, 0x05, 0x95,
+	0x48, 0x48, 0x0e, 0xda, 0x5e, 0x50, 0x25, 0x44, 0x37, 0xbb, 0x69, 0x89, 0x50, 0xd3, 0xc8, 0x9b,
+	0x08, 0xc1, 0xc5, 0x9a, 0xd8, 0x93, 0x64, 0x54, 0xff, 0x89, 0xc6, 0xe3, 0x2a, 0xe9, 0x89, 0x8f,
+	0xd0, 0x1b, 0x7c, 0x04, 0x3e, 0xca, 0x1e, 0xf7, 0x82, 0xd4, 0x13, 0x90, 0xec, 0x85, 0xe3, 0x7e,
+	0x04, 0xe4, 0x19, 0x3b, 0x59, 0x09, 0x58, 0x85, 0x53, 0xe6, 0x9d, 0xf7, 0x79, 0x7e, 0x72, 0xf2,
+	0x3c, 0x0e, 0x3c, 0x0e, 0xd9, 0x92, 0xf2, 0x0e, 0xf1, 0xc9, 0x42, 0x50, 0xde, 0x89, 0x45, 0xb0,
+	0xe8, 0x78, 0x71, 0x34, 0x65, 0xb3, 0xfc, 0xc3, 0x5e, 0xf0, 0x58, 0xc4, 0xf8, 0x28, 0x57, 0xd8,
+	0x99, 0xc2, 0x56, 0xab, 0xe6, 0xf1, 0x2c, 0x9e, 0xc5, 0x72, 0xdf, 0xc9, 0x4e, 0x4a, 0xda, 0xb4,
+	0x66, 0x71, 0x3c, 0x0b, 0x68, 0x47, 0x4e, 0x93, 0x74, 0xda, 0xf1, 0x53, 0x4e, 0x04, 0x8b, 0x23,
+	0xb5, 0x7f, 0xf4, 0x1b, 0x40, 0x6d, 0x48, 0x38, 0x09, 0x13, 0x6c, 0x42, 0x9d, 0xf8, 0x3e, 0xa7,
+	0x49, 0x62, 0xa2, 0x16, 0x6a, 0x1f, 0x38, 0xc5, 0x88, 0x9b, 0xd0, 0x60, 0x51, 0x42, 0xbd, 0x94,
+	0x53, 0xb3, 0xd4, 0x42, 0xed, 0x86, 0xb3, 0x9d, 0xb1, 0x0d, 0x47, 0x1e, 0x71, 0x3d, 0xca, 0x05,
+	0x9b, 0x32, 0x8f, 0x08, 0xea, 0x2e, 0x88, 0x98, 0x9b, 0x65, 0x49, 0xf8, 0xd0, 0x23, 0x67, 0xbb,
+	0xcd, 0x90, 0x88, 0x39, 0xee, 0x42, 0x7d, 0x4e, 0x89, 0x4f, 0x79, 0x62, 0x56, 0x5a, 0xe5, 0xb6,
+	0x7e, 0xd2, 0xb6, 0xff, 0xe5, 0xdb, 0xd8, 0xea, 0x99, 0xec, 0x6f, 0x95, 0xb4, 0x17, 0x09, 0xbe,
+	0x72, 0x0a, 0x23, 0xf6, 0xe1, 0x88, 0xd3, 0x24, 0x4e, 0xb9, 0x47, 0x5d, 0x22, 0x04, 0x67, 0x93,
+	0x54, 0xd0, 0xc4, 0xac, 0x4a, 0xde, 0x93, 0xbb, 0x78, 0x4e, 0x6e, 0x3b, 0xdd, 0xba, 0x14, 0x1a,
+	0xf3, 0x7f, 0x2c, 0xf0, 0x33, 0xa8, 0x87, 0x54, 0x70, 0xe6, 0x25, 0x66, 0x4d, 0x92, 0x1f, 0xdf,
+	0x45, 0x7e, 0x29, 0xa5, 0xfd, 0x68, 0x1a, 0x3b, 0x85, 0x0d, 0x7f, 0x09, 0xc7, 0x2c, 0x4a, 0x04,
+	0x89, 0x3c, 0xea, 0x4e, 0xd2, 0xe9, 0x94, 0x72, 0x37, 0x61, 0x6f, 0xa9, 0x59, 0x6f, 0xa1, 0x76,
+	0xd9, 0xc1, 0xc5, 0xae, 0x2b, 0x57, 0x17, 0xec, 0x2d, 0xc5, 0x9f, 0xc1, 0xbd, 0x90, 0x2c, 0xdd,
+	0x09, 0x11, 0xde, 0x5c, 0x69, 0x1b, 0x52, 0x7b, 0x18, 0x92, 0x65, 0x37, 0xbb, 0x94, 0xaa, 0x1f,
+	0xe0, 0xc1, 0x22, 0x4d, 0xe6, 0x2e, 0x8b, 0x04, 0xe5, 0x6f, 0x48, 0xe0, 0x16, 0xa1, 0x9a, 0x07,
+	0x2d, 0xd4, 0xd6, 0x4f, 0x3e, 0xb1, 0x55, 0xea, 0x76, 0x91, 0xba, 0x7d, 0x9e, 0x0b, 0xba, 0x8d,
+	0xcb, 0xdf, 0x1f, 0x6a, 0xbf, 0xfc, 0xf1, 0x10, 0x39, 0xc7, 0x19, 0xa2, 0x9f, 0x13, 0x8a, 0x3d,
+	0xfe, 0x1e, 0x3e, 0x92, 0x68, 0xc1, 0x42, 0x1a, 0xa7, 0x62, 0x47, 0x86, 0xfd, 0xc9, 0x47, 0x19,
+	0x61, 0xa4, 0x00, 0x5b, 0xf0, 0xd7, 0x50, 0xe5, 0x54, 0xf0, 0x95, 0xa9, 0x4b, 0xd0, 0xe7, 0x77,
+	0xa7, 0x24, 0xf8, 0x6a, 0x18, 0x07, 0xcc, 0x5b, 0x39, 0xca, 0xd5, 0x7c, 0x0a, 0x87, 0xb7, 0xbb,
+	0x80, 0x0d, 0x28, 0xbf, 0xa6, 0xab, 0xbc, 0xa8, 0xd9, 0x11, 0x1f, 0x43, 0xf5, 0x0d, 0x09, 0x52,
+	0xd5, 0xd0, 0x03, 0x47, 0x0d, 0x4f, 0x4b, 0x5f, 0xa1, 0x66, 0x0f, 0x3e, 0xfe, 0x8f, 0xdc, 0xff,
+	0x17, 0xe6, 0xe7, 0x12, 0xc0, 0x2e, 0x65, 0xfc, 0x29, 0x7c, 0xb0, 0x0d, 0x37, 0x22, 0x21, 0xcd,
+	0x21, 0x87, 0xc5, 0xe5, 0x80, 0x84, 0x14, 0x63, 0xa8, 0xc8, 0x9d, 0x82, 0xc9, 0x33, 0x6e, 0x81,
+	0xee, 0xd3, 0xc4, 0xe3, 0x6c, 0x21, 0x7f, 0x58, 0xf5, 0xa6, 0xdc, 0xbe, 0xca, 0x5c, 0x69, 0xc4,
+	0x84, 0x59, 0x51, 0xae, 0xec, 0x8c, 0xbf, 0x81, 0xca, 0x6b, 0x16, 0xf9, 0x66, 0xb5, 0x85, 0xda,
+	0xf7, 0x4e, 0xbe, 0xd8, 0xaf, 0x8a, 0xf6, 0x77, 0x2c, 0xf2, 0x1d, 0x69, 0xc4, 0x0f, 0xa0, 0x36,
+	0x89, 0xd3, 0xc8, 0x57, 0x6d, 0x46, 0x4e, 0x3e, 0x3d, 0x3a, 0x85, 0x4a, 0xa6, 0xc2, 0xf7, 0x41,
+	0x1f, 0x0f, 0x2e, 0x86, 0xbd, 0xb3, 0xfe, 0xf3, 0x7e, 0xef, 0xdc, 0xd0, 0xf0, 0x01, 0x54, 0x5f,
+	0x9c, 0x8e, 0x5f, 0xf4, 0x0c, 0x84, 0x75, 0xa8, 0x9f, 0xbd, 0x1a, 0x0f, 0x46, 0x3d, 0xc7, 0x28,
+	0x61, 0x03, 0x0e, 0xcf, 0xfb, 0x17, 0x23, 0xa7, 0xdf, 0x1d, 0x8f, 0xfa, 0xaf, 0x06, 0x46, 0xb9,
+	0xf9, 0xae, 0x04, 0xfa, 0xad, 0xcc, 0xf0, 0x00, 0x0c, 0x16, 0x31, 0xc1, 0x48, 0xb0, 0xad, 0xa8,
+	0x89, 0xf6, 0xef, 0xcf, 0xfd, 0xdc, 0x5c, 0x94, 0x13, 0x3f, 0x87, 0xac, 0xff, 0x3b, 0x56, 0x69,
+	0x7f, 0x96, 0x1e, 0x92, 0xe5, 0x96, 0xf3, 0x12, 0x8c, 0x8c, 0x43, 0x03, 0xb2, 0x48, 0xa8, 0x2f,
+	0x3b, 0x6e, 0x96, 0xf7, 0x67, 0x65, 0xaf, 0x66, 0x4f, 0x79, 0xb3, 0x76, 0x67, 0x7f, 0x8b, 0x3e,
+	0x4b, 0xc8, 0x24, 0xa0, 0xbe, 0x8c, 0xaa, 0xe1, 0x6c, 0xe7, 0xee, 0xb3, 0xcb, 0xb5, 0xa5, 0x5d,
+	0xad, 0x2d, 0xed, 0xfd, 0xda, 0xd2, 0x6e, 0xd6, 0x96, 0xf6, 0xd3, 0xc6, 0x42, 0xbf, 0x6e, 0x2c,
+	0xed, 0x72, 0x63, 0xa1, 0xab, 0x8d, 0x85, 0xfe, 0xdc, 0x58, 0xe8, 0xaf, 0x8d, 0xa5, 0xdd, 0x6c,
+	0x2c, 0xf4, 0xee, 0xda, 0xd2, 0xae, 0xae, 0x2d, 0xed, 0xfd, 0xb5, 0xa5, 0xfd, 0x58, 0x53, 0x61,
+	0x4e, 0x6a, 0xf2, 0x51, 0x9e, 0xfc, 0x3d, 0x00, 0xc9, 0x79, 0xce, 0x28, 0x15, 0x06, 0x00, 0x00,
+}
+
+func (x Params_MetricInfo_Kind) String() string {
+	s, ok := Params_MetricInfo_Kind_name[int32(x)]
+	if ok {
+		return s
+	}
+	return strconv.Itoa(int(x))
+}
+func (m *Params) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Params) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Params) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Retry != nil {
+		{
+			size, err := m.Retry.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintConfig(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x5a
+	}
+	n2, err2 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.PushTimeoutDuration, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.PushTimeoutDuration):])
+	if err2 != nil {
+		return 0, err2
+	}
+	i -= n2
+	i = encodeVarintConfig(dAtA, i, uint64(n2))
+	i--
+	dAtA[i] = 0x52
+	n3, err3 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.PushIntervalDuration, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.PushIntervalDuration):])
+	if err3 != nil {
+		return 0, err3
+	}
+	i -= n3
+	i = encodeVarintConfig(dAtA, i, uint64(n3))
+	i--
+	dAtA[i] = 0x4a
+	if m.MaxBatchSize != 0 {
+		i = encodeVarintConfig(dAtA, i, uint64(m.MaxBatchSize))
+		i--
+		dAtA[i] = 0x40
+	}
+	if m.InstanceBufferSize != 0 {
+		i = encodeVarintConfig(dAtA, i, uint64(m.InstanceBufferSize))
+		i--
+		dAtA[i] = 0x38
+	}
+	if len(m.Metrics) > 0 {
+		for iNdEx := len(m.Metrics) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Metrics[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintConfig(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x32
+		}
+	}
+	if len(m.ResourceAttributes) > 0 {
+		for k := range m.ResourceAttributes {
+			v := m.ResourceAttributes[k]
+			baseI := i
+			i -= len(v)
+			copy(dAtA[i:], v)
+			i = encodeVarintConfig(dAtA, i, uint64(len(v)))
+			i--
+			dAtA[i] = 0x12
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintConfig(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintConfig(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x2a
+		}
+	}
+	if len(m.Headers) > 0 {
+		for k := range m.Headers {
+			v := m.Headers[k]
+			baseI := i
+			i -= len(v)
+			copy(dAtA[i:], v)
+			i = encodeVarintConfig(dAtA, i, uint64(len(v)))
+			i--
+			dAtA[i] = 0x12
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintConfig(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintConfig(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if len(m.CaCertificatePath) > 0 {
+		i -= len(m.CaCertificatePath)
+		copy(dAtA[i:], m.CaCertificatePath)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.CaCertificatePath)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.Insecure {
+		i--
+		if m.Insecure {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Address) > 0 {
+		i -= len(m.Address)
+		copy(dAtA[i:], m.Address)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.Address)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Params_MetricInfo) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Params_MetricInfo) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Params_MetricInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Bounds) > 0 {
+		for iNdEx := len(m.Bounds) - 1; iNdEx >= 0; iNdEx-- {
+			f4 := math.Float64bits(float64(m.Bounds[iNdEx]))
+			i -= 8
+			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f4))
+		}
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.Bounds)*8))
+		i--
+		dAtA[i] = 0x32
+	}
+	if m.Kind != 0 {
+		i = encodeVarintConfig(dAtA, i, uint64(m.Kind))
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.Unit) > 0 {
+		i -= len(m.Unit)
+		copy(dAtA[i:], m.Unit)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.Unit)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.Description) > 0 {
+		i -= len(m.Description)
+		copy(dAtA[i:], m.Description)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.Description)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.InstanceName) > 0 {
+		i -= len(m.InstanceName)
+		copy(dAtA[i:], m.InstanceName)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.InstanceName)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Params_RetryPolicy) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Params_RetryPolicy) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Params_RetryPolicy) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Disabled {
+		i--
+		if m.Disabled {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
+	n5, err5 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.MaxElapsedTime, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.MaxElapsedTime):])
+	if err5 != nil {
+		return 0, err5
+	}
+	i -= n5
+	i = encodeVarintConfig(dAtA, i, uint64(n5))
+	i--
+	dAtA[i] = 0x1a
+	n6, err6 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.MaxInterval, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.MaxInterval):])
+	if err6 != nil {
+		return 0, err6
+	}
+	i -= n6
+	i = encodeVarintConfig(dAtA, i, uint64(n6))
+	i--
+	dAtA[i] = 0x12
+	n7, err7 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.InitialInterval, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.InitialInterval):])
+	if err7 != nil {
+		return 0, err7
+	}
+	i -= n7
+	i = encodeVarintConfig(dAtA, i, uint64(n7))
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintConfig(dAtA []byte, offset int, v uint64) int {
+	offset -= sovConfig(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *Params) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Address)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	if m.Insecure {
+		n += 2
+	}
+	l = len(m.CaCertificatePath)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	if len(m.Headers) > 0 {
+		for k, v := range m.Headers {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovConfig(uint64(len(k))) + 1 + len(v) + sovConfig(uint64(len(v)))
+			n += mapEntrySize + 1 + sovConfig(uint64(mapEntrySize))
+		}
+	}
+	if len(m.ResourceAttributes) > 0 {
+		for k, v := range m.ResourceAttributes {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovConfig(uint64(len(k))) + 1 + len(v) + sovConfig(uint64(len(v)))
+			n += mapEntrySize + 1 + sovConfig(uint64(mapEntrySize))
+		}
+	}
+	if len(m.Metrics) > 0 {
+		for _, e := range m.Metrics {
+			l = e.Size()
+			n += 1 + l + sovConfig(uint64(l))
+		}
+	}
+	if m.InstanceBufferSize != 0 {
+		n += 1 + sovConfig(uint64(m.InstanceBufferSize))
+	}
+	if m.MaxBatchSize != 0 {
+		n += 1 + sovConfig(uint64(m.MaxBatchSize))
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.PushIntervalDuration)
+	n += 1 + l + sovConfig(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.PushTimeoutDuration)
+	n += 1 + l + sovConfig(uint64(l))
+	if m.Retry != nil {
+		l = m.Retry.Size()
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	return n
+}
+
+func (m *Params_MetricInfo) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.InstanceName)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	l = len(m.Description)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	l = len(m.Unit)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	if m.Kind != 0 {
+		n += 1 + sovConfig(uint64(m.Kind))
+	}
+	if len(m.Bounds) > 0 {
+		n += 1 + sovConfig(uint64(len(m.Bounds)*8)) + len(m.Bounds)*8
+	}
+	return n
+}
+
+func (m *Params_RetryPolicy) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.InitialInterval)
+	n += 1 + l + sovConfig(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.MaxInterval)
+	n += 1 + l + sovConfig(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.MaxElapsedTime)
+	n += 1 + l + sovConfig(uint64(l))
+	if m.Disabled {
+		n += 2
+	}
+	return n
+}
+
+func sovConfig(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozConfig(x uint64) (n int) {
+	return sovConfig(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *Params) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForMetrics := "[]*Params_MetricInfo{"
+	for _, f := range this.Metrics {
+		repeatedStringForMetrics += strings.Replace(fmt.Sprintf("%v", f), "Params_MetricInfo", "Params_MetricInfo", 1) + ","
+	}
+	repeatedStringForMetrics += "}"
+	keysForHeaders := make([]string, 0, len(this.Headers))
+	for k, _ := range this.Headers {
+		keysForHeaders = append(keysForHeaders, k)
+	}
+	github_com_gogo_protobuf_sortkeys.Strings(keysForHeaders)
+	mapStringForHeaders := "map[string]string{"
+	for _, k := range keysForHeaders {
+		mapStringForHeaders += fmt.Sprintf("%v: %v,", k, this.Headers[k])
+	}
+	mapStringForHeaders += "}"
+	keysForResourceAttributes := make([]string, 0, len(this.ResourceAttributes))
+	for k, _ := range this.ResourceAttributes {
+		keysForResourceAttributes = append(keysForResourceAttributes, k)
+	}
+	github_com_gogo_protobuf_sortkeys.Strings(keysForResourceAttributes)
+	mapStringForResourceAttributes := "map[string]string{"
+	for _, k := range keysForResourceAttributes {
+		mapStringForResourceAttributes += fmt.Sprintf("%v: %v,", k, this.ResourceAttributes[k])
+	}
+	mapStringForResourceAttributes += "}"
+	s := strings.Join([]string{`&Params{`,
+		`Address:` + fmt.Sprintf("%v", this.Address) + `,`,
+		`Insecure:` + fmt.Sprintf("%v", this.Insecure) + `,`,
+		`CaCertificatePath:` + fmt.Sprintf("%v", this.CaCertificatePath) + `,`,
+		`Headers:` + mapStringForHeaders + `,`,
+		`ResourceAttributes:` + mapStringForResourceAttributes + `,`,
+		`Metrics:` + repeatedStringForMetrics + `,`,
+		`InstanceBufferSize:` + fmt.Sprintf("%v", this.InstanceBufferSize) + `,`,
+		`MaxBatchSize:` + fmt.Sprintf("%v", this.MaxBatchSize) + `,`,
+		`PushIntervalDuration:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.PushIntervalDuration), "Duration", "types.Duration", 1), `&`, ``, 1) + `,`,
+		`PushTimeoutDuration:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.PushTimeoutDuration), "Duration", "types.Duration", 1), `&`, ``, 1) + `,`,
+		`Retry:` + strings.Replace(fmt.Sprintf("%v", this.Retry), "Params_RetryPolicy", "Params_RetryPolicy", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Params_MetricInfo) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Params_MetricInfo{`,
+		`InstanceName:` + fmt.Sprintf("%v", this.InstanceName) + `,`,
+		`Name:` + fmt.Sprintf("%v", this.Name) + `,`,
+		`Description:` + fmt.Sprintf("%v", this.Description) + `,`,
+		`Unit:` + fmt.Sprintf("%v", this.Unit) + `,`,
+		`Kind:` + fmt.Sprintf("%v", this.Kind) + `,`,
+		`Bounds:` + fmt.Sprintf("%v", this.Bounds) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Params_RetryPolicy) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Params_RetryPolicy{`,
+		`InitialInterval:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.InitialInterval), "Duration", "types.Duration", 1), `&`, ``, 1) + `,`,
+		`MaxInterval:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.MaxInterval), "Duration", "types.Duration", 1), `&`, ``, 1) + `,`,
+		`MaxElapsedTime:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.MaxElapsedTime), "Duration", "types.Duration", 1), `&`, ``, 1) + `,`,
+		`Disabled:` + fmt.Sprintf("%v", this.Disabled) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func valueToStringConfig(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("*%v", pv)
+}
+func (m *Params) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowConfig
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Params: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Params: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Address", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Address = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Insecure", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Insecure = bool(v != 0)
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CaCertificatePath", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.CaCertificatePath = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Headers", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Headers == nil {
+				m.Headers = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowConfig
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowConfig
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthConfig
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthConfig
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowConfig
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthConfig
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue < 0 {
+						return ErrInvalidLengthConfig
+					}
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipConfig(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthConfig
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Headers[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ResourceAttributes", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ResourceAttributes == nil {
+				m.ResourceAttributes = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowConfig
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowConfig
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthConfig
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthConfig
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowConfig
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthConfig
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue < 0 {
+						return ErrInvalidLengthConfig
+					}
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipConfig(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthConfig
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.ResourceAttributes[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metrics", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Metrics = append(m.Metrics, &Params_MetricInfo{})
+			if err := m.Metrics[len(m.Metrics)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field InstanceBufferSize", wireType)
+			}
+			m.InstanceBufferSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.InstanceBufferSize |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxBatchSize", wireType)
+			}
+			m.MaxBatchSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxBatchSize |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PushIntervalDuration", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.PushIntervalDuration, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PushTimeoutDuration", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.PushTimeoutDuration, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Retry", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Retry == nil {
+				m.Retry = &Params_RetryPolicy{}
+			}
+			if err := m.Retry.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipConfig(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Params_MetricInfo) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowConfig
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MetricInfo: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MetricInfo: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field InstanceName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.InstanceName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Description", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Description = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Unit", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Unit = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Kind", wireType)
+			}
+			m.Kind = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Kind |= Params_MetricInfo_Kind(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType == 1 {
+				var v uint64
+				if (iNdEx + 8) > l {
+					return io.ErrUnexpectedEOF
+				}
+				v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+				iNdEx += 8
+				v2 := float64(math.Float64frombits(v))
+				m.Bounds = append(m.Bounds, v2)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowConfig
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthConfig
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthConfig
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				elementCount = packedLen / 8
+				if elementCount != 0 && len(m.Bounds) == 0 {
+					m.Bounds = make([]float64, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					if (iNdEx + 8) > l {
+						return io.ErrUnexpectedEOF
+					}
+					v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+					iNdEx += 8
+					v2 := float64(math.Float64frombits(v))
+					m.Bounds = append(m.Bounds, v2)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Bounds", wireType)
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipConfig(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Params_RetryPolicy) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowConfig
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RetryPolicy: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RetryPolicy: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field InitialInterval", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.InitialInterval, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxInterval", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.MaxInterval, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxElapsedTime", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.MaxElapsedTime, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Disabled", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Disabled = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipConfig(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipConfig(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowConfig
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+			return iNdEx, nil
+		case 1:
+			iNdEx += 8
+			return iNdEx, nil
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthConfig
+			}
+			iNdEx += length
+			if iNdEx < 0 {
+				return 0, ErrInvalidLengthConfig
+			}
+			return iNdEx, nil
+		case 3:
+			for {
+				var innerWire uint64
+				var start int = iNdEx
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return 0, ErrIntOverflowConfig
+					}
+					if iNdEx >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					innerWire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				innerWireType := int(innerWire & 0x7)
+				if innerWireType == 4 {
+					break
+				}
+				next, err := skipConfig(dAtA[start:])
+				if err != nil {
+					return 0, err
+				}
+				iNdEx = start + next
+				if iNdEx < 0 {
+					return 0, ErrInvalidLengthConfig
+				}
+			}
+			return iNdEx, nil
+		case 4:
+			return iNdEx, nil
+		case 5:
+			iNdEx += 4
+			return iNdEx, nil
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+	}
+	panic("unreachable")
+}
+
+var (
+	ErrInvalidLengthConfig = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowConfig   = fmt.Errorf("proto: integer overflow")
+)
@@ -0,0 +1,282 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opencensus.io/trace"
+
+	descriptor "istio.io/api/policy/v1beta1"
+	"istio.io/istio/mixer/adapter/otlp/config"
+	"istio.io/istio/mixer/adapter/otlp/otlppb"
+	"istio.io/istio/mixer/pkg/adapter"
+	"istio.io/istio/mixer/pkg/adapter/opencensus"
+	"istio.io/istio/mixer/template/logentry"
+	"istio.io/istio/mixer/template/metric"
+	"istio.io/istio/mixer/template/tracespan"
+)
+
+const (
+	// logentry variable used as the body of the exported log record.
+	messageVariable = "message"
+
+	attrHTTPStatusCode    = "http.status_code"
+	attrSourceName        = "source.name"
+	attrSourceIP          = "source.ip"
+	attrDestinationName   = "destination.name"
+	attrDestinationIP     = "destination.ip"
+	attrRequestSize       = "request.size"
+	attrRequestTotalSize  = "request.total_size"
+	attrResponseSize      = "response.size"
+	attrResponseTotalSize = "response.total_size"
+	attrAPIProtocol       = "api.protocol"
+	attrMixerInstanceName = "mixer.instance"
+
+	// number of OTLP severity numbers for each severity level, e.g. INFO..INFO4.
+	severityNumbersPerLevel = 4
+)
+
+// metricPoint is a single metric observation waiting to be aggregated.
+type metricPoint struct {
+	info  *config.Params_MetricInfo
+	key   string
+	attrs []*otlppb.KeyValue
+	value float64
+}
+
+func newMetricPoint(info *config.Params_MetricInfo, inst *metric.Instance) (*metricPoint, error) {
+	v, err := metricValue(inst.Value)
+	if err != nil {
+		return nil, fmt.Errorf("instance %s: %v", inst.Name, err)
+	}
+	attrs := toAttributes(inst.Dimensions, nil)
+
+	var key strings.Builder
+	key.WriteString(info.InstanceName)
+	for _, kv := range attrs {
+		key.WriteByte(0)
+		key.WriteString(kv.Key)
+		key.WriteByte('=')
+		key.WriteString(adapter.Stringify(inst.Dimensions[kv.Key]))
+	}
+
+	return &metricPoint{
+		info:  info,
+		key:   key.String(),
+		attrs: attrs,
+		value: v,
+	}, nil
+}
+
+func metricValue(val interface{}) (float64, error) {
+	switch v := val.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case time.Duration:
+		// follow the prometheus adapter and report durations in seconds
+		return v.Seconds(), nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fmt.Errorf("could not extract numeric value for %v", val)
+	}
+}
+
+func toLogRecord(inst *logentry.Instance, types map[string]descriptor.ValueType) *otlppb.LogRecord {
+	vars := inst.Variables
+	body := &otlppb.AnyValue{Value: &otlppb.AnyValue_StringValue{StringValue: inst.Name}}
+	if msg, found := vars[messageVariable]; found {
+		body = toAnyValue(msg, types[messageVariable])
+		vars = make(map[string]interface{}, len(inst.Variables))
+		for k, v := range inst.Variables {
+			if k != messageVariable {
+				vars[k] = v
+			}
+		}
+	}
+
+	attrs := toAttributes(vars, types)
+	attrs = append(attrs, stringAttr(attrMixerInstanceName, inst.Name))
+
+	return &otlppb.LogRecord{
+		TimeUnixNano:         unixNano(inst.Timestamp),
+		ObservedTimeUnixNano: unixNano(time.Now()),
+		SeverityNumber:       severityNumber(inst.Severity),
+		SeverityText:         inst.Severity,
+		Body:                 body,
+		Attributes:           attrs,
+	}
+}
+
+// severityNumber maps the free-form logentry severity onto the OTLP severity
+// scale, picking the lowest number of the matching range.
+func severityNumber(severity string) otlppb.SeverityNumber {
+	s := strings.ToUpper(severity)
+	var level int32
+	switch {
+	case strings.HasPrefix(s, "TRACE"):
+		level = 0
+	case strings.HasPrefix(s, "DEBUG"):
+		level = 1
+	case strings.HasPrefix(s, "INFO"), strings.HasPrefix(s, "DEFAULT"), strings.HasPrefix(s, "NOTICE"):
+		level = 2
+	case strings.HasPrefix(s, "WARN"):
+		level = 3
+	case strings.HasPrefix(s, "ERR"):
+		level = 4
+	case strings.HasPrefix(s, "CRIT"), strings.HasPrefix(s, "ALERT"), strings.HasPrefix(s, "EMERG"), strings.HasPrefix(s, "FATAL"):
+		level = 5
+	default:
+		return otlppb.SEVERITY_NUMBER_UNSPECIFIED
+	}
+	return otlppb.SeverityNumber(level*severityNumbersPerLevel + 1)
+}
+
+func toSpan(inst *tracespan.Instance) (*otlppb.Span, bool) {
+	parentContext, ok := opencensus.ExtractParentContext(inst.TraceId, inst.ParentSpanId)
+	if !ok {
+		return nil, false
+	}
+	spanContext, ok := opencensus.ExtractSpanContext(inst.SpanId, parentContext)
+	if !ok {
+		return nil, false
+	}
+
+	kind := otlppb.SPAN_KIND_SERVER
+	parentSpanID := parentContext.SpanID
+	spanID := spanContext.SpanID
+	if inst.ClientSpan {
+		kind = otlppb.SPAN_KIND_CLIENT
+		if inst.RewriteClientSpanId {
+			spanID = opencensus.RewriteSpanID(spanID)
+		}
+	} else if inst.RewriteClientSpanId {
+		parentSpanID = opencensus.RewriteSpanID(spanID)
+	}
+
+	attrs := toAttributes(inst.SpanTags, nil)
+	status := &otlppb.Status{}
+	if inst.HttpStatusCode > 0 {
+		attrs = append(attrs, intAttr(attrHTTPStatusCode, inst.HttpStatusCode))
+		if inst.HttpStatusCode >= 500 || (inst.ClientSpan && inst.HttpStatusCode >= 400) {
+			status.Code = otlppb.STATUS_CODE_ERROR
+		}
+	}
+	if inst.SourceName != "" {
+		attrs = append(attrs, stringAttr(attrSourceName, inst.SourceName))
+	}
+	if inst.SourceIp != nil {
+		attrs = append(attrs, stringAttr(attrSourceIP, inst.SourceIp.String()))
+	}
+	if inst.DestinationName != "" {
+		attrs = append(attrs, stringAttr(attrDestinationName, inst.DestinationName))
+	}
+	if inst.DestinationIp != nil {
+		attrs = append(attrs, stringAttr(attrDestinationIP, inst.DestinationIp.String()))
+	}
+	if inst.ApiProtocol != "" {
+		attrs = append(attrs, stringAttr(attrAPIProtocol, inst.ApiProtocol))
+	}
+	attrs = append(attrs,
+		intAttr(attrRequestSize, inst.RequestSize),
+		intAttr(attrRequestTotalSize, inst.RequestTotalSize),
+		intAttr(attrResponseSize, inst.ResponseSize),
+		intAttr(attrResponseTotalSize, inst.ResponseTotalSize))
+
+	span := &otlppb.Span{
+		TraceId:           spanContext.TraceID[:],
+		SpanId:            spanID[:],
+		Name:              inst.SpanName,
+		Kind:              kind,
+		StartTimeUnixNano: unixNano(inst.StartTime),
+		EndTimeUnixNano:   unixNano(inst.EndTime),
+		Attributes:        attrs,
+		Status:            status,
+	}
+	if parentSpanID != (trace.SpanID{}) {
+		span.ParentSpanId = parentSpanID[:]
+	}
+	return span, true
+}
+
+// toAttributes converts instance values into OTLP attributes sorted by key.
+// types is optional and is used to render IP addresses encoded as bytes.
+func toAttributes(vals map[string]interface{}, types map[string]descriptor.ValueType) []*otlppb.KeyValue {
+	keys := make([]string, 0, len(vals))
+	for k := range vals {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]*otlppb.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, &otlppb.KeyValue{Key: k, Value: toAnyValue(vals[k], types[k])})
+	}
+	return attrs
+}
+
+func toAnyValue(val interface{}, t descriptor.ValueType) *otlppb.AnyValue {
+	switch v := val.(type) {
+	case string:
+		return &otlppb.AnyValue{Value: &otlppb.AnyValue_StringValue{StringValue: v}}
+	case bool:
+		return &otlppb.AnyValue{Value: &otlppb.AnyValue_BoolValue{BoolValue: v}}
+	case int64:
+		return &otlppb.AnyValue{Value: &otlppb.AnyValue_IntValue{IntValue: v}}
+	case float64:
+		return &otlppb.AnyValue{Value: &otlppb.AnyValue_DoubleValue{DoubleValue: v}}
+	case []byte:
+		if t == descriptor.IP_ADDRESS {
+			return &otlppb.AnyValue{Value: &otlppb.AnyValue_StringValue{StringValue: net.IP(v).String()}}
+		}
+		return &otlppb.AnyValue{Value: &otlppb.AnyValue_BytesValue{BytesValue: v}}
+	case map[string]string:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		kvs := make([]*otlppb.KeyValue, 0, len(keys))
+		for _, k := range keys {
+			kvs = append(kvs, stringAttr(k, v[k]))
+		}
+		return &otlppb.AnyValue{Value: &otlppb.AnyValue_KvlistValue{KvlistValue: &otlppb.KeyValueList{Values: kvs}}}
+	default:
+		return &otlppb.AnyValue{Value: &otlppb.AnyValue_StringValue{StringValue: adapter.Stringify(val)}}
+	}
+}
+
+func stringAttr(key, value string) *otlppb.KeyValue {
+	return &otlppb.KeyValue{Key: key, Value: &otlppb.AnyValue{Value: &otlppb.AnyValue_StringValue{StringValue: value}}}
+}
+
+func intAttr(key string, value int64) *otlppb.KeyValue {
+	return &otlppb.KeyValue{Key: key, Value: &otlppb.AnyValue{Value: &otlppb.AnyValue_IntValue{IntValue: value}}}
+}
+
+func unixNano(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint64(t.UnixNano())
+}
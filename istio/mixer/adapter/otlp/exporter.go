@@ -0,0 +1,387 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcmd "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"istio.io/istio/mixer/adapter/otlp/config"
+	"istio.io/istio/mixer/adapter/otlp/otlppb"
+	"istio.io/istio/mixer/pkg/adapter"
+)
+
+const scopeName = "istio.io/istio/mixer/adapter/otlp"
+
+type (
+	exporterOptions struct {
+		headers            map[string]string
+		resourceAttributes map[string]string
+		bufferSize         int64
+		maxBatchSize       int64
+		pushInterval       time.Duration
+		pushTimeout        time.Duration
+		noRetry            bool
+		initialInterval    time.Duration
+		maxInterval        time.Duration
+		maxElapsedTime     time.Duration
+	}
+
+	// exporter owns the connection to the collector. Items are queued by the
+	// handler and pushed by a single daemon goroutine, either once a batch is
+	// full or when the push interval elapses.
+	exporter struct {
+		exporterOptions
+
+		env      adapter.Env
+		conn     *grpc.ClientConn
+		metrics  otlppb.MetricsServiceClient
+		logs     otlppb.LogsServiceClient
+		traces   otlppb.TraceServiceClient
+		md       grpcmd.MD
+		resource *otlppb.Resource
+		scope    *otlppb.InstrumentationScope
+
+		queue     chan item
+		stopCh    chan struct{}
+		doneCh    chan struct{}
+		closeOnce sync.Once
+	}
+
+	// batch accumulates the items pushed in a single round of export requests.
+	batch struct {
+		start      time.Time
+		size       int64
+		aggregates map[string]*aggregate
+		logs       []*otlppb.LogRecord
+		spans      []*otlppb.Span
+	}
+
+	// aggregate holds the state of a single metric time series within a batch.
+	aggregate struct {
+		info    *config.Params_MetricInfo
+		attrs   []*otlppb.KeyValue
+		sum     float64
+		last    float64
+		count   uint64
+		buckets []uint64
+	}
+)
+
+func newExporter(conn *grpc.ClientConn, o exporterOptions, env adapter.Env) *exporter {
+	md := grpcmd.New(o.headers)
+
+	keys := make([]string, 0, len(o.resourceAttributes))
+	for k := range o.resourceAttributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	resource := &otlppb.Resource{}
+	for _, k := range keys {
+		resource.Attributes = append(resource.Attributes, stringAttr(k, o.resourceAttributes[k]))
+	}
+
+	return &exporter{
+		exporterOptions: o,
+		env:             env,
+		conn:            conn,
+		metrics:         otlppb.NewMetricsServiceClient(conn),
+		logs:            otlppb.NewLogsServiceClient(conn),
+		traces:          otlppb.NewTraceServiceClient(conn),
+		md:              md,
+		resource:        resource,
+		scope:           &otlppb.InstrumentationScope{Name: scopeName},
+		queue:           make(chan item, o.bufferSize),
+		stopCh:          make(chan struct{}),
+		doneCh:          make(chan struct{}),
+	}
+}
+
+// enqueue adds an item to the export queue without blocking. It returns
+// false if the queue is full, so callers can reject the instance instead of
+// stalling Mixer's request path.
+func (e *exporter) enqueue(it item) bool {
+	select {
+	case e.queue <- it:
+		return true
+	default:
+		return false
+	}
+}
+
+func (e *exporter) run() {
+	defer close(e.doneCh)
+
+	b := newBatch(time.Now())
+	tick := time.NewTicker(e.pushInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-e.stopCh:
+			e.drain(b)
+			e.push(b)
+			return
+		case it := <-e.queue:
+			b.add(it)
+			if b.size >= e.maxBatchSize {
+				e.push(b)
+				b = newBatch(time.Now())
+			}
+		case <-tick.C:
+			e.push(b)
+			b = newBatch(time.Now())
+		}
+	}
+}
+
+// drain moves everything still queued into the given batch.
+func (e *exporter) drain(b *batch) {
+	for {
+		select {
+		case it := <-e.queue:
+			b.add(it)
+		default:
+			return
+		}
+	}
+}
+
+func (e *exporter) push(b *batch) {
+	now := time.Now()
+	if len(b.aggregates) > 0 {
+		req := &otlppb.ExportMetricsServiceRequest{
+			ResourceMetrics: []*otlppb.ResourceMetrics{{
+				Resource:     e.resource,
+				ScopeMetrics: []*otlppb.ScopeMetrics{{Scope: e.scope, Metrics: b.metrics(now)}},
+			}},
+		}
+		e.export("metrics", func(ctx context.Context) error {
+			_, err := e.metrics.Export(ctx, req)
+			return err
+		})
+	}
+	if len(b.logs) > 0 {
+		req := &otlppb.ExportLogsServiceRequest{
+			ResourceLogs: []*otlppb.ResourceLogs{{
+				Resource:  e.resource,
+				ScopeLogs: []*otlppb.ScopeLogs{{Scope: e.scope, LogRecords: b.logs}},
+			}},
+		}
+		e.export("logs", func(ctx context.Context) error {
+			_, err := e.logs.Export(ctx, req)
+			return err
+		})
+	}
+	if len(b.spans) > 0 {
+		req := &otlppb.ExportTraceServiceRequest{
+			ResourceSpans: []*otlppb.ResourceSpans{{
+				Resource:   e.resource,
+				ScopeSpans: []*otlppb.ScopeSpans{{Scope: e.scope, Spans: b.spans}},
+			}},
+		}
+		e.export("spans", func(ctx context.Context) error {
+			_, err := e.traces.Export(ctx, req)
+			return err
+		})
+	}
+}
+
+// export calls fn until it succeeds, fails with a non-retryable status, or
+// the retry budget is exhausted. Retries are abandoned when the exporter is
+// closed, so Close does not wait on an unreachable collector.
+func (e *exporter) export(kind string, fn func(context.Context) error) {
+	bo := backoff.NewExponentialBackOff()
+	bo.InitialInterval = e.initialInterval
+	bo.MaxInterval = e.maxInterval
+	bo.MaxElapsedTime = e.maxElapsedTime
+	bo.Reset()
+
+	for {
+		err := e.call(fn)
+		if err == nil {
+			return
+		}
+		if e.noRetry || !retryable(err) {
+			e.env.Logger().Warningf("failed to export %s to %s; dropping: %v", kind, e.conn.Target(), err)
+			return
+		}
+
+		d := bo.NextBackOff()
+		if d == backoff.Stop {
+			e.env.Logger().Warningf("giving up exporting %s to %s; dropping: %v", kind, e.conn.Target(), err)
+			return
+		}
+		e.env.Logger().Debugf("failed to export %s, retrying in %v: %v", kind, d, err)
+
+		select {
+		case <-time.After(d):
+		case <-e.stopCh:
+			e.env.Logger().Warningf("handler closed while exporting %s; dropping: %v", kind, err)
+			return
+		}
+	}
+}
+
+func (e *exporter) call(fn func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), e.pushTimeout)
+	defer cancel()
+	if len(e.md) > 0 {
+		ctx = grpcmd.NewOutgoingContext(ctx, e.md)
+	}
+	return fn(ctx)
+}
+
+// retryable reports whether an export error is transient, following the
+// OTLP/gRPC specification.
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Canceled, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted,
+		codes.OutOfRange, codes.Unavailable, codes.DataLoss:
+		return true
+	}
+	return false
+}
+
+// close stops the daemon after it has pushed whatever is queued, then tears
+// down the connection.
+func (e *exporter) close() error {
+	e.closeOnce.Do(func() {
+		close(e.stopCh)
+	})
+	<-e.doneCh
+	return e.conn.Close()
+}
+
+func newBatch(start time.Time) *batch {
+	return &batch{
+		start:      start,
+		aggregates: make(map[string]*aggregate),
+	}
+}
+
+func (b *batch) add(it item) {
+	b.size++
+	switch {
+	case it.metric != nil:
+		b.addMetric(it.metric)
+	case it.log != nil:
+		b.logs = append(b.logs, it.log)
+	case it.span != nil:
+		b.spans = append(b.spans, it.span)
+	}
+}
+
+func (b *batch) addMetric(p *metricPoint) {
+	a, found := b.aggregates[p.key]
+	if !found {
+		a = &aggregate{info: p.info, attrs: p.attrs}
+		if p.info.Kind == config.DISTRIBUTION {
+			a.buckets = make([]uint64, len(p.info.Bounds)+1)
+		}
+		b.aggregates[p.key] = a
+	}
+	a.sum += p.value
+	a.last = p.value
+	a.count++
+	if a.buckets != nil {
+		// OTLP buckets are inclusive of their upper bound.
+		a.buckets[sort.SearchFloat64s(a.info.Bounds, p.value)]++
+	}
+}
+
+// metrics converts the aggregated time series into OTLP metrics, one per
+// configured metric, using delta temporality for counters and distributions.
+func (b *batch) metrics(now time.Time) []*otlppb.Metric {
+	keys := make([]string, 0, len(b.aggregates))
+	for k := range b.aggregates {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	start, end := unixNano(b.start), unixNano(now)
+	byInstance := make(map[string]*otlppb.Metric)
+	var out []*otlppb.Metric
+	for _, k := range keys {
+		a := b.aggregates[k]
+		m, found := byInstance[a.info.InstanceName]
+		if !found {
+			m = newMetric(a.info)
+			byInstance[a.info.InstanceName] = m
+			out = append(out, m)
+		}
+
+		switch d := m.Data.(type) {
+		case *otlppb.Metric_Gauge:
+			d.Gauge.DataPoints = append(d.Gauge.DataPoints, &otlppb.NumberDataPoint{
+				Attributes:   a.attrs,
+				TimeUnixNano: end,
+				Value:        &otlppb.NumberDataPoint_AsDouble{AsDouble: a.last},
+			})
+		case *otlppb.Metric_Sum:
+			d.Sum.DataPoints = append(d.Sum.DataPoints, &otlppb.NumberDataPoint{
+				Attributes:        a.attrs,
+				StartTimeUnixNano: start,
+				TimeUnixNano:      end,
+				Value:             &otlppb.NumberDataPoint_AsDouble{AsDouble: a.sum},
+			})
+		case *otlppb.Metric_Histogram:
+			d.Histogram.DataPoints = append(d.Histogram.DataPoints, &otlppb.HistogramDataPoint{
+				Attributes:        a.attrs,
+				StartTimeUnixNano: start,
+				TimeUnixNano:      end,
+				Count:             a.count,
+				Sum:               a.sum,
+				BucketCounts:      a.buckets,
+				ExplicitBounds:    a.info.Bounds,
+			})
+		}
+	}
+	return out
+}
+
+func newMetric(info *config.Params_MetricInfo) *otlppb.Metric {
+	m := &otlppb.Metric{
+		Name:        info.Name,
+		Description: info.Description,
+		Unit:        info.Unit,
+	}
+	if m.Name == "" {
+		m.Name = info.InstanceName
+	}
+
+	switch info.Kind {
+	case config.COUNTER:
+		m.Data = &otlppb.Metric_Sum{Sum: &otlppb.Sum{
+			AggregationTemporality: otlppb.AGGREGATION_TEMPORALITY_DELTA,
+			IsMonotonic:            true,
+		}}
+	case config.DISTRIBUTION:
+		m.Data = &otlppb.Metric_Histogram{Histogram: &otlppb.Histogram{
+			AggregationTemporality: otlppb.AGGREGATION_TEMPORALITY_DELTA,
+		}}
+	default:
+		m.Data = &otlppb.Metric_Gauge{Gauge: &otlppb.Gauge{}}
+	}
+	return m
+}
@@ -0,0 +1,316 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// nolint: lll
+//go:generate $REPO_ROOT/bin/mixer_codegen.sh -a mixer/adapter/otlp/config/config.proto -x "-n otlp -t metric -t logentry -t tracespan"
+
+// Package otlp contains an adapter that exports metrics, log entries and
+// trace spans to an OpenTelemetry collector using OTLP over gRPC.
+package otlp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"sort"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	descriptor "istio.io/api/policy/v1beta1"
+	"istio.io/istio/mixer/adapter/metadata"
+	"istio.io/istio/mixer/adapter/otlp/config"
+	"istio.io/istio/mixer/adapter/otlp/otlppb"
+	"istio.io/istio/mixer/pkg/adapter"
+	"istio.io/istio/mixer/template/logentry"
+	"istio.io/istio/mixer/template/metric"
+	"istio.io/istio/mixer/template/tracespan"
+)
+
+const (
+	defaultBufferSize      int64 = 1024
+	defaultMaxBatchSize    int64 = 512
+	defaultPushInterval          = 10 * time.Second
+	defaultPushTimeout           = 10 * time.Second
+	defaultInitialInterval       = 100 * time.Millisecond
+	defaultMaxInterval           = 5 * time.Second
+	defaultMaxElapsedTime        = time.Minute
+)
+
+var errBufferFull = errors.New("otlp instance buffer full; dropping instance")
+
+type (
+	builder struct {
+		adpCfg      *config.Params
+		metricTypes map[string]*metric.Type
+		logTypes    map[string]*logentry.Type
+		spanTypes   map[string]*tracespan.Type
+	}
+
+	handler struct {
+		env      adapter.Env
+		exporter *exporter
+		metrics  map[string]*config.Params_MetricInfo
+		logTypes map[string]*logentry.Type
+	}
+)
+
+// ensure types implement the requisite interfaces
+var _ metric.HandlerBuilder = &builder{}
+var _ metric.Handler = &handler{}
+var _ logentry.HandlerBuilder = &builder{}
+var _ logentry.Handler = &handler{}
+var _ tracespan.HandlerBuilder = &builder{}
+var _ tracespan.Handler = &handler{}
+
+///////////////// Configuration-time Methods ///////////////
+
+// adapter.HandlerBuilder#Build
+func (b *builder) Build(ctx context.Context, env adapter.Env) (adapter.Handler, error) {
+	opts := []grpc.DialOption{}
+	switch {
+	case b.adpCfg.Insecure:
+		opts = append(opts, grpc.WithInsecure())
+	case b.adpCfg.CaCertificatePath != "":
+		creds, err := credentials.NewClientTLSFromFile(b.adpCfg.CaCertificatePath, "")
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithTransportCredentials(creds))
+	default:
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{})))
+	}
+
+	conn, err := grpc.Dial(b.adpCfg.Address, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := make(map[string]*config.Params_MetricInfo, len(b.adpCfg.Metrics))
+	for _, m := range b.adpCfg.Metrics {
+		metrics[m.InstanceName] = m
+	}
+
+	h := &handler{
+		env:      env,
+		exporter: newExporter(conn, b.exporterOptions(), env),
+		metrics:  metrics,
+		logTypes: b.logTypes,
+	}
+	env.ScheduleDaemon(h.exporter.run)
+
+	return h, nil
+}
+
+func (b *builder) exporterOptions() exporterOptions {
+	o := exporterOptions{
+		headers:            b.adpCfg.Headers,
+		resourceAttributes: b.adpCfg.ResourceAttributes,
+		bufferSize:         defaultBufferSize,
+		maxBatchSize:       defaultMaxBatchSize,
+		pushInterval:       defaultPushInterval,
+		pushTimeout:        defaultPushTimeout,
+		initialInterval:    defaultInitialInterval,
+		maxInterval:        defaultMaxInterval,
+		maxElapsedTime:     defaultMaxElapsedTime,
+	}
+	if b.adpCfg.InstanceBufferSize > 0 {
+		o.bufferSize = b.adpCfg.InstanceBufferSize
+	}
+	if b.adpCfg.MaxBatchSize > 0 {
+		o.maxBatchSize = b.adpCfg.MaxBatchSize
+	}
+	if b.adpCfg.PushIntervalDuration > 0 {
+		o.pushInterval = b.adpCfg.PushIntervalDuration
+	}
+	if b.adpCfg.PushTimeoutDuration > 0 {
+		o.pushTimeout = b.adpCfg.PushTimeoutDuration
+	}
+	if r := b.adpCfg.Retry; r != nil {
+		o.noRetry = r.Disabled
+		if r.InitialInterval > 0 {
+			o.initialInterval = r.InitialInterval
+		}
+		if r.MaxInterval > 0 {
+			o.maxInterval = r.MaxInterval
+		}
+		if r.MaxElapsedTime > 0 {
+			o.maxElapsedTime = r.MaxElapsedTime
+		}
+	}
+	return o
+}
+
+// adapter.HandlerBuilder#SetAdapterConfig
+func (b *builder) SetAdapterConfig(cfg adapter.Config) {
+	b.adpCfg = cfg.(*config.Params)
+}
+
+// adapter.HandlerBuilder#Validate
+func (b *builder) Validate() (ce *adapter.ConfigErrors) {
+	if b.adpCfg.Address == "" {
+		ce = ce.Appendf("address", "Address is empty")
+	} else if _, _, err := net.SplitHostPort(b.adpCfg.Address); err != nil {
+		ce = ce.Appendf("address", "Address is malformed: %v", err)
+	}
+	if b.adpCfg.InstanceBufferSize < 0 {
+		ce = ce.Appendf("instanceBufferSize", "Value must be a positive number")
+	}
+	if b.adpCfg.MaxBatchSize < 0 {
+		ce = ce.Appendf("maxBatchSize", "Value must be a positive number")
+	}
+	if b.adpCfg.PushIntervalDuration < 0 {
+		ce = ce.Appendf("pushIntervalDuration", "Duration must be positive")
+	}
+	if b.adpCfg.PushTimeoutDuration < 0 {
+		ce = ce.Appendf("pushTimeoutDuration", "Duration must be positive")
+	}
+	if r := b.adpCfg.Retry; r != nil {
+		if r.InitialInterval < 0 || r.MaxInterval < 0 || r.MaxElapsedTime < 0 {
+			ce = ce.Appendf("retry", "Durations must be positive")
+		}
+	}
+
+	metrics := make(map[string]*config.Params_MetricInfo, len(b.adpCfg.Metrics))
+	for i, m := range b.adpCfg.Metrics {
+		if m.InstanceName == "" {
+			ce = ce.Appendf("metrics", "instanceName of metric %d is empty", i)
+			continue
+		}
+		if _, found := metrics[m.InstanceName]; found {
+			ce = ce.Appendf("metrics", "instance %s is described more than once", m.InstanceName)
+		}
+		metrics[m.InstanceName] = m
+
+		switch m.Kind {
+		case config.GAUGE, config.COUNTER:
+		case config.DISTRIBUTION:
+			if len(m.Bounds) == 0 {
+				ce = ce.Appendf("metrics", "DISTRIBUTION metric %s must specify bounds", m.InstanceName)
+			}
+			if !sort.Float64sAreSorted(m.Bounds) {
+				ce = ce.Appendf("metrics", "bounds of metric %s must be monotonically increasing", m.InstanceName)
+			}
+		default:
+			ce = ce.Appendf("metrics", "metric %s has unsupported kind %v", m.InstanceName, m.Kind)
+		}
+	}
+
+	for name, t := range b.metricTypes {
+		if _, found := metrics[name]; !found {
+			ce = ce.Appendf("metrics", "no metric described for instance %s", name)
+		}
+		switch t.Value {
+		case descriptor.INT64, descriptor.DOUBLE, descriptor.DURATION, descriptor.STRING:
+		default:
+			ce = ce.Appendf("metrics", "instance %s has value type %v; want INT64, DOUBLE, DURATION or a STRING holding a number",
+				name, t.Value)
+		}
+	}
+	return
+}
+
+// metric.HandlerBuilder#SetMetricTypes
+func (b *builder) SetMetricTypes(types map[string]*metric.Type) {
+	b.metricTypes = types
+}
+
+// logentry.HandlerBuilder#SetLogEntryTypes
+func (b *builder) SetLogEntryTypes(types map[string]*logentry.Type) {
+	b.logTypes = types
+}
+
+// tracespan.HandlerBuilder#SetTraceSpanTypes
+func (b *builder) SetTraceSpanTypes(types map[string]*tracespan.Type) {
+	b.spanTypes = types
+}
+
+////////////////// Request-time Methods //////////////////////////
+
+// metric.Handler#HandleMetric
+func (h *handler) HandleMetric(_ context.Context, insts []*metric.Instance) error {
+	var handleErr *multierror.Error
+	for _, inst := range insts {
+		info, found := h.metrics[inst.Name]
+		if !found {
+			h.env.Logger().Debugf("no metric described for instance %s; dropping", inst.Name)
+			continue
+		}
+		p, err := newMetricPoint(info, inst)
+		if err != nil {
+			handleErr = multierror.Append(handleErr, err)
+			continue
+		}
+		if !h.exporter.enqueue(item{metric: p}) {
+			handleErr = multierror.Append(handleErr, errBufferFull)
+		}
+	}
+	return handleErr.ErrorOrNil()
+}
+
+// logentry.Handler#HandleLogEntry
+func (h *handler) HandleLogEntry(_ context.Context, insts []*logentry.Instance) error {
+	var handleErr *multierror.Error
+	for _, inst := range insts {
+		var types map[string]descriptor.ValueType
+		if t, found := h.logTypes[inst.Name]; found {
+			types = t.Variables
+		}
+		if !h.exporter.enqueue(item{log: toLogRecord(inst, types)}) {
+			handleErr = multierror.Append(handleErr, errBufferFull)
+		}
+	}
+	return handleErr.ErrorOrNil()
+}
+
+// tracespan.Handler#HandleTraceSpan
+func (h *handler) HandleTraceSpan(_ context.Context, insts []*tracespan.Instance) error {
+	var handleErr *multierror.Error
+	for _, inst := range insts {
+		span, ok := toSpan(inst)
+		if !ok {
+			h.env.Logger().Debugf("tracespan %s has invalid trace or span id; dropping", inst.Name)
+			continue
+		}
+		if !h.exporter.enqueue(item{span: span}) {
+			handleErr = multierror.Append(handleErr, errBufferFull)
+		}
+	}
+	return handleErr.ErrorOrNil()
+}
+
+// adapter.Handler#Close
+func (h *handler) Close() error {
+	return h.exporter.close()
+}
+
+////////////////// Bootstrap //////////////////////////
+
+// GetInfo returns the adapter.Info specific to this adapter.
+func GetInfo() adapter.Info {
+	info := metadata.GetInfo("otlp")
+	info.NewBuilder = func() adapter.HandlerBuilder { return &builder{} }
+	return info
+}
+
+// item is a single unit of telemetry waiting in the export queue. Exactly one
+// field is set.
+type item struct {
+	metric *metricPoint
+	log    *otlppb.LogRecord
+	span   *otlppb.Span
+}
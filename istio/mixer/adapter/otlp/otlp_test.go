@@ -0,0 +1,475 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcmd "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	descriptor "istio.io/api/policy/v1beta1"
+	"istio.io/istio/mixer/adapter/otlp/config"
+	"istio.io/istio/mixer/adapter/otlp/otlppb"
+	"istio.io/istio/mixer/pkg/adapter/test"
+	"istio.io/istio/mixer/template/logentry"
+	"istio.io/istio/mixer/template/metric"
+	"istio.io/istio/mixer/template/tracespan"
+)
+
+// receiver is an in-process OTLP/gRPC collector that records what it receives.
+type receiver struct {
+	sync.Mutex
+	metrics []*otlppb.ExportMetricsServiceRequest
+	logs    []*otlppb.ExportLogsServiceRequest
+	spans   []*otlppb.ExportTraceServiceRequest
+	headers []grpcmd.MD
+
+	// number of requests to fail with codes.Unavailable before accepting data.
+	failures int
+
+	srv  *grpc.Server
+	addr string
+}
+
+func newReceiver(t *testing.T) *receiver {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %v", err)
+	}
+	r := &receiver{srv: grpc.NewServer(), addr: l.Addr().String()}
+	otlppb.RegisterMetricsServiceServer(r.srv, r)
+	otlppb.RegisterLogsServiceServer(r.srv, logsReceiver{r})
+	otlppb.RegisterTraceServiceServer(r.srv, traceReceiver{r})
+	go func() { _ = r.srv.Serve(l) }()
+	return r
+}
+
+func (r *receiver) record(ctx context.Context) error {
+	r.Lock()
+	defer r.Unlock()
+	if r.failures > 0 {
+		r.failures--
+		return status.Error(codes.Unavailable, "try again")
+	}
+	md, _ := grpcmd.FromIncomingContext(ctx)
+	r.headers = append(r.headers, md)
+	return nil
+}
+
+func (r *receiver) Export(ctx context.Context, req *otlppb.ExportMetricsServiceRequest) (*otlppb.ExportMetricsServiceResponse, error) {
+	if err := r.record(ctx); err != nil {
+		return nil, err
+	}
+	r.Lock()
+	r.metrics = append(r.metrics, req)
+	r.Unlock()
+	return &otlppb.ExportMetricsServiceResponse{}, nil
+}
+
+type logsReceiver struct{ *receiver }
+type traceReceiver struct{ *receiver }
+
+func (r logsReceiver) Export(ctx context.Context, req *otlppb.ExportLogsServiceRequest) (*otlppb.ExportLogsServiceResponse, error) {
+	if err := r.record(ctx); err != nil {
+		return nil, err
+	}
+	r.Lock()
+	r.logs = append(r.logs, req)
+	r.Unlock()
+	return &otlppb.ExportLogsServiceResponse{}, nil
+}
+
+func (r traceReceiver) Export(ctx context.Context, req *otlppb.ExportTraceServiceRequest) (*otlppb.ExportTraceServiceResponse, error) {
+	if err := r.record(ctx); err != nil {
+		return nil, err
+	}
+	r.Lock()
+	r.spans = append(r.spans, req)
+	r.Unlock()
+	return &otlppb.ExportTraceServiceResponse{}, nil
+}
+
+func TestBasic(t *testing.T) {
+	info := GetInfo()
+	for _, tmpl := range []string{metric.TemplateName, logentry.TemplateName, tracespan.TemplateName} {
+		if !contains(info.SupportedTemplates, tmpl) {
+			t.Errorf("Didn't find expected supported template %s", tmpl)
+		}
+	}
+
+	b := info.NewBuilder().(*builder)
+	b.SetAdapterConfig(info.DefaultConfig)
+	if err := b.Validate(); err != nil {
+		t.Errorf("Got error %v, expecting success", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	metricTypes := map[string]*metric.Type{
+		"requestcount": {Value: descriptor.INT64},
+	}
+
+	cases := []struct {
+		name   string
+		cfg    config.Params
+		types  map[string]*metric.Type
+		errors []string
+	}{
+		{
+			name: "valid",
+			cfg: config.Params{
+				Address: "localhost:4317",
+				Metrics: []*config.Params_MetricInfo{{InstanceName: "requestcount", Kind: config.COUNTER}},
+			},
+			types: metricTypes,
+		},
+		{
+			name:   "empty address",
+			cfg:    config.Params{},
+			errors: []string{"address"},
+		},
+		{
+			name:   "bad address",
+			cfg:    config.Params{Address: "localhost"},
+			errors: []string{"address"},
+		},
+		{
+			name: "negative values",
+			cfg: config.Params{
+				Address:              "localhost:4317",
+				InstanceBufferSize:   -1,
+				MaxBatchSize:         -1,
+				PushIntervalDuration: -time.Second,
+				Retry:                &config.Params_RetryPolicy{MaxInterval: -time.Second},
+			},
+			errors: []string{"instanceBufferSize", "maxBatchSize", "pushIntervalDuration", "retry"},
+		},
+		{
+			name:   "undescribed metric",
+			cfg:    config.Params{Address: "localhost:4317"},
+			types:  metricTypes,
+			errors: []string{"no metric described for instance requestcount"},
+		},
+		{
+			name: "distribution without bounds",
+			cfg: config.Params{
+				Address: "localhost:4317",
+				Metrics: []*config.Params_MetricInfo{{InstanceName: "requestcount", Kind: config.DISTRIBUTION}},
+			},
+			types:  metricTypes,
+			errors: []string{"must specify bounds"},
+		},
+		{
+			name: "unsorted bounds",
+			cfg: config.Params{
+				Address: "localhost:4317",
+				Metrics: []*config.Params_MetricInfo{{InstanceName: "requestcount", Kind: config.DISTRIBUTION, Bounds: []float64{2, 1}}},
+			},
+			types:  metricTypes,
+			errors: []string{"monotonically increasing"},
+		},
+		{
+			name: "unspecified kind",
+			cfg: config.Params{
+				Address: "localhost:4317",
+				Metrics: []*config.Params_MetricInfo{{InstanceName: "requestcount"}},
+			},
+			types:  metricTypes,
+			errors: []string{"unsupported kind"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := &builder{}
+			b.SetAdapterConfig(&c.cfg)
+			b.SetMetricTypes(c.types)
+			ce := b.Validate()
+			if len(c.errors) == 0 {
+				if ce != nil {
+					t.Fatalf("Validate() = %v, expecting success", ce)
+				}
+				return
+			}
+			if ce == nil {
+				t.Fatalf("Validate() succeeded, expecting errors %v", c.errors)
+			}
+			for _, e := range c.errors {
+				if !strings.Contains(ce.Error(), e) {
+					t.Errorf("Validate() = %v, expecting it to contain %q", ce, e)
+				}
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	r := newReceiver(t)
+	defer r.srv.Stop()
+
+	env := test.NewEnv(t)
+	b := &builder{}
+	b.SetAdapterConfig(&config.Params{
+		Address:              r.addr,
+		Insecure:             true,
+		Headers:              map[string]string{"x-token": "secret"},
+		ResourceAttributes:   map[string]string{"service.name": "istio-telemetry"},
+		PushIntervalDuration: time.Hour,
+		Metrics: []*config.Params_MetricInfo{
+			{InstanceName: "requestcount", Name: "istio_requests_total", Kind: config.COUNTER},
+			{InstanceName: "requestduration", Kind: config.DISTRIBUTION, Bounds: []float64{0.1, 1}},
+		},
+	})
+	b.SetMetricTypes(map[string]*metric.Type{
+		"requestcount":    {Value: descriptor.INT64},
+		"requestduration": {Value: descriptor.DURATION},
+	})
+	b.SetLogEntryTypes(map[string]*logentry.Type{
+		"accesslog": {Variables: map[string]descriptor.ValueType{"sourceIp": descriptor.IP_ADDRESS}},
+	})
+	if ce := b.Validate(); ce != nil {
+		t.Fatalf("Validate() = %v", ce)
+	}
+
+	h, err := b.Build(context.Background(), env)
+	if err != nil {
+		t.Fatalf("Build() = %v", err)
+	}
+
+	ctx := context.Background()
+	dims := map[string]interface{}{"destination": "reviews"}
+	if err := h.(metric.Handler).HandleMetric(ctx, []*metric.Instance{
+		{Name: "requestcount", Value: int64(1), Dimensions: dims},
+		{Name: "requestcount", Value: int64(2), Dimensions: dims},
+		{Name: "requestduration", Value: 500 * time.Millisecond, Dimensions: dims},
+		{Name: "requestduration", Value: 2 * time.Second, Dimensions: dims},
+	}); err != nil {
+		t.Fatalf("HandleMetric() = %v", err)
+	}
+	if err := h.(logentry.Handler).HandleLogEntry(ctx, []*logentry.Instance{{
+		Name:      "accesslog",
+		Severity:  "Warning",
+		Timestamp: time.Unix(10, 0),
+		Variables: map[string]interface{}{"message": "hello", "sourceIp": []byte{10, 0, 0, 1}},
+	}}); err != nil {
+		t.Fatalf("HandleLogEntry() = %v", err)
+	}
+	if err := h.(tracespan.Handler).HandleTraceSpan(ctx, []*tracespan.Instance{{
+		Name:           "span",
+		TraceId:        "463ac35c9f6413ad48485a3953bb6124",
+		SpanId:         "a2fb4a1d1a96d312",
+		ParentSpanId:   "0020000000000001",
+		SpanName:       "/reviews",
+		HttpStatusCode: 503,
+	}, {
+		Name:    "span",
+		TraceId: "invalid",
+		SpanId:  "a2fb4a1d1a96d312",
+	}}); err != nil {
+		t.Fatalf("HandleTraceSpan() = %v", err)
+	}
+
+	// Close pushes everything that is still queued.
+	if err := h.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	if len(r.metrics) != 1 || len(r.logs) != 1 || len(r.spans) != 1 {
+		t.Fatalf("got %d metric, %d log and %d span requests; want 1 of each", len(r.metrics), len(r.logs), len(r.spans))
+	}
+	for _, md := range r.headers {
+		if got := md.Get("x-token"); len(got) != 1 || got[0] != "secret" {
+			t.Errorf("x-token header = %v, want [secret]", got)
+		}
+	}
+
+	rm := r.metrics[0].ResourceMetrics[0]
+	if got := rm.Resource.Attributes[0].Key; got != "service.name" {
+		t.Errorf("resource attribute = %s, want service.name", got)
+	}
+	metrics := rm.ScopeMetrics[0].Metrics
+	if len(metrics) != 2 {
+		t.Fatalf("got %d metrics, want 2", len(metrics))
+	}
+	sum := metrics[0].GetSum()
+	if metrics[0].Name != "istio_requests_total" || sum == nil {
+		t.Fatalf("got metric %v, want sum istio_requests_total", metrics[0])
+	}
+	if got := sum.DataPoints[0].GetAsDouble(); got != 3 {
+		t.Errorf("requests total = %v, want 3", got)
+	}
+	hist := metrics[1].GetHistogram()
+	if hist == nil {
+		t.Fatalf("got metric %v, want histogram", metrics[1])
+	}
+	if dp := hist.DataPoints[0]; dp.Count != 2 || dp.Sum != 2.5 || len(dp.BucketCounts) != 3 ||
+		dp.BucketCounts[0] != 0 || dp.BucketCounts[1] != 1 || dp.BucketCounts[2] != 1 {
+		t.Errorf("got histogram point %v", dp)
+	}
+
+	lr := r.logs[0].ResourceLogs[0].ScopeLogs[0].LogRecords[0]
+	if lr.Body.GetStringValue() != "hello" || lr.SeverityNumber != otlppb.SEVERITY_NUMBER_WARN {
+		t.Errorf("got log record %v", lr)
+	}
+	for _, kv := range lr.Attributes {
+		if kv.Key == "sourceIp" && kv.Value.GetStringValue() != "10.0.0.1" {
+			t.Errorf("sourceIp = %v, want 10.0.0.1", kv.Value)
+		}
+	}
+
+	spans := r.spans[0].ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if spans[0].Kind != otlppb.SPAN_KIND_SERVER || spans[0].Status.Code != otlppb.STATUS_CODE_ERROR || len(spans[0].TraceId) != 16 {
+		t.Errorf("got span %v", spans[0])
+	}
+}
+
+func TestExportRetry(t *testing.T) {
+	r := newReceiver(t)
+	defer r.srv.Stop()
+	r.failures = 2
+
+	conn, err := grpc.Dial(r.addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Dial() = %v", err)
+	}
+	e := newExporter(conn, exporterOptions{
+		bufferSize:      10,
+		maxBatchSize:    1,
+		pushInterval:    time.Hour,
+		pushTimeout:     time.Second,
+		initialInterval: time.Millisecond,
+		maxInterval:     time.Millisecond,
+		maxElapsedTime:  time.Second,
+	}, test.NewEnv(t))
+	go e.run()
+
+	if !e.enqueue(item{log: &otlppb.LogRecord{SeverityText: "INFO"}}) {
+		t.Fatal("enqueue() = false, want true")
+	}
+
+	// Retries are abandoned on close, so wait for the batch to get through
+	// before closing.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		r.Lock()
+		failures, logs := r.failures, len(r.logs)
+		r.Unlock()
+		if logs > 0 {
+			if failures != 0 || logs != 1 {
+				t.Errorf("got %d remaining failures and %d log requests; want 0 and 1", failures, logs)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("log request not received after retries; %d failures remaining", failures)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := e.close(); err != nil {
+		t.Fatalf("close() = %v", err)
+	}
+}
+
+func TestCloseAbandonsRetries(t *testing.T) {
+	r := newReceiver(t)
+	defer r.srv.Stop()
+	r.failures = 1 << 20
+
+	conn, err := grpc.Dial(r.addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Dial() = %v", err)
+	}
+	e := newExporter(conn, exporterOptions{
+		bufferSize:      10,
+		maxBatchSize:    1,
+		pushInterval:    time.Hour,
+		pushTimeout:     time.Second,
+		initialInterval: 10 * time.Millisecond,
+		maxInterval:     10 * time.Millisecond,
+		maxElapsedTime:  time.Minute,
+	}, test.NewEnv(t))
+	go e.run()
+
+	if !e.enqueue(item{log: &otlppb.LogRecord{SeverityText: "INFO"}}) {
+		t.Fatal("enqueue() = false, want true")
+	}
+	start := time.Now()
+	if err := e.close(); err != nil {
+		t.Fatalf("close() = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("close() took %v; want retries abandoned on close", elapsed)
+	}
+}
+
+func TestBackpressure(t *testing.T) {
+	conn, err := grpc.Dial("localhost:0", grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Dial() = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// the daemon is not running, so nothing drains the queue.
+	e := newExporter(conn, exporterOptions{bufferSize: 1}, test.NewEnv(t))
+	h := &handler{env: test.NewEnv(t), exporter: e}
+
+	insts := []*tracespan.Instance{
+		{TraceId: "463ac35c9f6413ad48485a3953bb6124", SpanId: "a2fb4a1d1a96d312"},
+		{TraceId: "463ac35c9f6413ad48485a3953bb6124", SpanId: "a2fb4a1d1a96d313"},
+	}
+	if err := h.HandleTraceSpan(context.Background(), insts); err == nil || !strings.Contains(err.Error(), errBufferFull.Error()) {
+		t.Errorf("HandleTraceSpan() = %v, want %v", err, errBufferFull)
+	}
+}
+
+func TestSeverityNumber(t *testing.T) {
+	cases := map[string]otlppb.SeverityNumber{
+		"":         otlppb.SEVERITY_NUMBER_UNSPECIFIED,
+		"Default":  otlppb.SEVERITY_NUMBER_INFO,
+		"debug":    otlppb.SEVERITY_NUMBER_DEBUG,
+		"WARNING":  otlppb.SEVERITY_NUMBER_WARN,
+		"Error":    otlppb.SEVERITY_NUMBER_ERROR,
+		"CRITICAL": otlppb.SEVERITY_NUMBER_FATAL,
+	}
+	for severity, want := range cases {
+		if got := severityNumber(severity); got != want {
+			t.Errorf("severityNumber(%q) = %v, want %v", severity, got, want)
+		}
+	}
+}
+
+func contains(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,1186 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: mixer/adapter/spool/config/config.proto
+
+// The `spool` adapter provides at-least-once delivery of Istio log entries.
+// Entries are appended to a local spool on disk before the request that produced
+// them completes, and are forwarded in batches to a configurable sink. The offset of
+// the last entry acknowledged by the sink is persisted alongside the spool, so entries
+// that were not acknowledged are replayed after a restart.
+//
+// Because delivery is at-least-once, every forwarded entry carries its spool offset,
+// which sinks can use to discard duplicates.
+//
+// This adapter supports the [logentry template](https://istio.io/docs/reference/config/policy-and-telemetry/templates/logentry/).
+
+package config
+
+import (
+	fmt "fmt"
+	_ "github.com/gogo/protobuf/gogoproto"
+	proto "github.com/gogo/protobuf/proto"
+	github_com_gogo_protobuf_sortkeys "github.com/gogo/protobuf/sortkeys"
+	_ "github.com/gogo/protobuf/types"
+	github_com_gogo_protobuf_types "github.com/gogo/protobuf/types"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+	reflect "reflect"
+	strings "strings"
+	time "time"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+var _ = time.Kitchen
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+// Configuration format for the `spool` adapter.
+type Params struct {
+	// Required. Directory holding the spool. Each handler must use its own
+	// directory, which should be backed by a persistent volume.
+	SpoolDirectory string `protobuf:"bytes,1,opt,name=spool_directory,json=spoolDirectory,proto3" json:"spool_directory,omitempty"`
+	// Maximum size of the spool on disk. Once the spool reaches this size, new log
+	// entries are rejected until the sink acknowledges older ones.
+	// Defaults to 1,073,741,824 (1 GiB).
+	MaxSpoolSizeBytes int64 `protobuf:"varint,2,opt,name=max_spool_size_bytes,json=maxSpoolSizeBytes,proto3" json:"max_spool_size_bytes,omitempty"`
+	// Size after which the spool starts a new segment file. Segments are deleted
+	// once all of their entries have been acknowledged.
+	// Defaults to 67,108,864 (64 MiB).
+	SegmentSizeBytes int64 `protobuf:"varint,3,opt,name=segment_size_bytes,json=segmentSizeBytes,proto3" json:"segment_size_bytes,omitempty"`
+	// Flush the spool to stable storage before acknowledging each batch of log
+	// entries to Mixer. This trades throughput for durability across node crashes.
+	SyncWrites bool `protobuf:"varint,4,opt,name=sync_writes,json=syncWrites,proto3" json:"sync_writes,omitempty"`
+	// Required. Where spooled entries are forwarded to.
+	//
+	// Types that are valid to be assigned to Sink:
+	//	*Params_Http
+	Sink isParams_Sink `protobuf_oneof:"sink"`
+	// Maximum number of log entries forwarded in a single batch.
+	// Defaults to 500.
+	MaxBatchEntries int64 `protobuf:"varint,6,opt,name=max_batch_entries,json=maxBatchEntries,proto3" json:"max_batch_entries,omitempty"`
+	// Maximum number of spooled bytes forwarded in a single batch.
+	// Defaults to 1,048,576 (1 MiB).
+	MaxBatchSizeBytes int64 `protobuf:"varint,7,opt,name=max_batch_size_bytes,json=maxBatchSizeBytes,proto3" json:"max_batch_size_bytes,omitempty"`
+	// How often the spool is checked for new entries when the sink has caught up.
+	// Defaults to 5s.
+	PushIntervalDuration time.Duration `protobuf:"bytes,8,opt,name=push_interval_duration,json=pushIntervalDuration,proto3,stdduration" json:"push_interval_duration"`
+	// Upper bound for the delay between two attempts to deliver a batch the sink
+	// did not acknowledge. Delays grow exponentially up to this value; delivery is
+	// retried until it succeeds.
+	// Defaults to 1m.
+	MaxRetryIntervalDuration time.Duration `protobuf:"bytes,9,opt,name=max_retry_interval_duration,json=maxRetryIntervalDuration,proto3,stdduration" json:"max_retry_interval_duration"`
+}
+
+func (m *Params) Reset()      { *m = Params{} }
+func (*Params) ProtoMessage() {}
+func (*Params) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d2c9ff2d21be3d29, []int{0}
+}
+func (m *Params) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Params) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Params.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Params) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Params.Merge(m, src)
+}
+func (m *Params) XXX_Size() int {
+	return m.Size()
+}
+func (m *Params) XXX_DiscardUnknown() {
+	xxx_messageInfo_Params.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Params proto.InternalMessageInfo
+
+type isParams_Sink interface {
+	isParams_Sink()
+	MarshalTo([]byte) (int, error)
+	Size() int
+}
+
+type Params_Http struct {
+	Http *Params_HttpSink `protobuf:"bytes,5,opt,name=http,proto3,oneof" json:"http,omitempty"`
+}
+
+func (*Params_Http) isParams_Sink() {}
+
+func (m *Params) GetSink() isParams_Sink {
+	if m != nil {
+		return m.Sink
+	}
+	return nil
+}
+
+func (m *Params) GetHttp() *Params_HttpSink {
+	if x, ok := m.GetSink().(*Params_Http); ok {
+		return x.Http
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*Params) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*Params_Http)(nil),
+	}
+}
+
+// Describes an HTTP endpoint that accepts batches of log entries encoded as JSON.
+//
+// Each request body is a JSON array whose elements have an `offset` field with the
+// spool offset of the entry and an `entry` field with the `name`, `timestamp`,
+// `severity` and `variables` of the log entry. A batch is acknowledged by any 2xx
+// response; any other response causes the batch to be retried.
+type Params_HttpSink struct {
+	// Required. URL that batches are POSTed to.
+	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// Additional headers sent with every request, for example an authorization header.
+	Headers map[string]string `protobuf:"bytes,2,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Timeout for a single request to the sink.
+	// Defaults to 30s.
+	Timeout time.Duration `protobuf:"bytes,3,opt,name=timeout,proto3,stdduration" json:"timeout"`
+}
+
+func (m *Params_HttpSink) Reset()      { *m = Params_HttpSink{} }
+func (*Params_HttpSink) ProtoMessage() {}
+func (*Params_HttpSink) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d2c9ff2d21be3d29, []int{0, 0}
+}
+func (m *Params_HttpSink) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Params_HttpSink) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Params_HttpSink.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Params_HttpSink) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Params_HttpSink.Merge(m, src)
+}
+func (m *Params_HttpSink) XXX_Size() int {
+	return m.Size()
+}
+func (m *Params_HttpSink) XXX_DiscardUnknown() {
+	xxx_messageInfo_Params_HttpSink.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Params_HttpSink proto.InternalMessageInfo
+
+func init() {
+	proto.RegisterType((*Params)(nil), "adapter.spool.config.Params")
+	proto.RegisterType((*Params_HttpSink)(nil), "adapter.spool.config.Params.HttpSink")
+	proto.RegisterMapType((map[string]string)(nil), "adapter.spool.config.Params.HttpSink.HeadersEntry")
+}
+
+func init() {
+	proto.RegisterFile("mixer/adapter/spool/config/config.proto", fileDescriptor_d2c9ff2d21be3d29)
+}
+
+var fileDescriptor_d2c9ff2d21be3d29 = []byte{
+	// 543 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x92, 0x4f, 0x8b, 0x13, 0x31,
+	0x18, 0xc6, 0x27, 0xdb, 0xed, 0xbf, 0x54, 0xdc, 0x35, 0x14, 0x19, 0x2b, 0xa4, 0x45, 0x90, 0x2d,
+	0x22, 0x33, 0x50, 0x2f, 0xb2, 0x22, 0x48, 0x59, 0xa1, 0x82, 0x07, 0x99, 0x1e, 0x44, 0x2f, 0x43,
+	0xda, 0x66, 0xa7, 0xa1, 0x9d, 0xc9, 0x90, 0xc9, 0xac, 0xed, 0x9e, 0xfc, 0x08, 0x1e, 0xfd, 0x08,
+	0x7e, 0x94, 0x1e, 0x7b, 0xdc, 0x93, 0xda, 0x29, 0x82, 0xc7, 0x3d, 0x7b, 0x92, 0x24, 0x33, 0x6e,
+	0x91, 0x3d, 0xf4, 0x34, 0xc9, 0xfb, 0xfc, 0xf2, 0xcc, 0xf3, 0xbe, 0x09, 0x3c, 0x09, 0xd9, 0x82,
+	0x0a, 0x97, 0x4c, 0x48, 0x2c, 0xa9, 0x70, 0x93, 0x98, 0xf3, 0xb9, 0x3b, 0xe6, 0xd1, 0x39, 0x0b,
+	0xf2, 0x8f, 0x13, 0x0b, 0x2e, 0x39, 0x6a, 0xe6, 0x88, 0xa3, 0x11, 0xc7, 0x68, 0xad, 0x66, 0xc0,
+	0x03, 0xae, 0x01, 0x57, 0xad, 0x0c, 0xdb, 0xc2, 0x01, 0xe7, 0xc1, 0x9c, 0xba, 0x7a, 0x37, 0x4a,
+	0xcf, 0xdd, 0x49, 0x2a, 0x88, 0x64, 0x3c, 0x32, 0xfa, 0xa3, 0x3f, 0x65, 0x58, 0x79, 0x47, 0x04,
+	0x09, 0x13, 0x74, 0x02, 0x8f, 0xb4, 0xa1, 0x3f, 0x61, 0x82, 0x8e, 0x25, 0x17, 0x4b, 0x1b, 0x74,
+	0x40, 0xb7, 0xee, 0xdd, 0xd5, 0xe5, 0xb3, 0xa2, 0x8a, 0x5c, 0xd8, 0x0c, 0xc9, 0xc2, 0x37, 0x70,
+	0xc2, 0x2e, 0xa9, 0x3f, 0x5a, 0x4a, 0x9a, 0xd8, 0x07, 0x1d, 0xd0, 0x2d, 0x79, 0xf7, 0x42, 0xb2,
+	0x18, 0x2a, 0x69, 0xc8, 0x2e, 0x69, 0x5f, 0x09, 0xe8, 0x29, 0x44, 0x09, 0x0d, 0x42, 0x1a, 0xc9,
+	0x5d, 0xbc, 0xa4, 0xf1, 0xe3, 0x5c, 0xb9, 0xa1, 0xdb, 0xb0, 0x91, 0x2c, 0xa3, 0xb1, 0xff, 0x49,
+	0x30, 0x85, 0x1d, 0x76, 0x40, 0xb7, 0xe6, 0x41, 0x55, 0x7a, 0xaf, 0x2b, 0xe8, 0x05, 0x3c, 0x9c,
+	0x4a, 0x19, 0xdb, 0xe5, 0x0e, 0xe8, 0x36, 0x7a, 0x8f, 0x9d, 0xdb, 0xc6, 0xe1, 0x98, 0xa6, 0x9c,
+	0x81, 0x94, 0xf1, 0x90, 0x45, 0xb3, 0x81, 0xe5, 0xe9, 0x43, 0xe8, 0x09, 0x54, 0x01, 0xfd, 0x11,
+	0x91, 0xe3, 0xa9, 0x4f, 0x23, 0x29, 0x18, 0x4d, 0xec, 0x8a, 0x8e, 0x72, 0x14, 0x92, 0x45, 0x5f,
+	0xd5, 0x5f, 0x9b, 0x72, 0xd1, 0xa8, 0x61, 0x77, 0x92, 0x57, 0xff, 0x35, 0xaa, 0xf1, 0x9b, 0xe8,
+	0x1f, 0xe0, 0xfd, 0x38, 0x4d, 0xa6, 0x3e, 0x8b, 0x24, 0x15, 0x17, 0x64, 0xee, 0x17, 0xd3, 0xb6,
+	0x6b, 0x3a, 0xeb, 0x03, 0xc7, 0x5c, 0x87, 0x53, 0x5c, 0x87, 0x73, 0x96, 0x03, 0xfd, 0xda, 0xea,
+	0x7b, 0xdb, 0xfa, 0xfa, 0xa3, 0x0d, 0xbc, 0xa6, 0xb2, 0x78, 0x93, 0x3b, 0x14, 0x3a, 0x1a, 0xc1,
+	0x87, 0x2a, 0x8b, 0xa0, 0x52, 0x2c, 0x6f, 0xf1, 0xaf, 0xef, 0xef, 0x6f, 0x87, 0x64, 0xe1, 0x29,
+	0x9b, 0xff, 0xff, 0xd1, 0xfa, 0x05, 0x60, 0xad, 0x18, 0x18, 0x3a, 0x86, 0xa5, 0x54, 0xcc, 0xf3,
+	0x27, 0xa0, 0x96, 0xe8, 0x2d, 0xac, 0x4e, 0x29, 0x99, 0x50, 0xa1, 0xae, 0xba, 0xd4, 0x6d, 0xf4,
+	0x7a, 0x7b, 0x8d, 0xde, 0x19, 0x98, 0x43, 0x6a, 0xaa, 0x4b, 0xaf, 0xb0, 0x40, 0x2f, 0x61, 0x55,
+	0xb2, 0x90, 0xf2, 0x54, 0xda, 0xa5, 0xfd, 0xc3, 0x17, 0x67, 0x5a, 0xa7, 0xf0, 0xce, 0xae, 0xaf,
+	0x8a, 0x3b, 0xa3, 0xc5, 0x8b, 0x55, 0x4b, 0xd4, 0x84, 0xe5, 0x0b, 0x32, 0x4f, 0xa9, 0x7e, 0x97,
+	0x75, 0xcf, 0x6c, 0x4e, 0x0f, 0x9e, 0x83, 0x7e, 0x05, 0x1e, 0x26, 0x2c, 0x9a, 0xf5, 0x5f, 0xad,
+	0x36, 0xd8, 0x5a, 0x6f, 0xb0, 0x75, 0xb5, 0xc1, 0xd6, 0xf5, 0x06, 0x5b, 0x9f, 0x33, 0x0c, 0xbe,
+	0x65, 0xd8, 0x5a, 0x65, 0x18, 0xac, 0x33, 0x0c, 0x7e, 0x66, 0x18, 0xfc, 0xce, 0xb0, 0x75, 0x9d,
+	0x61, 0xf0, 0x65, 0x8b, 0xad, 0xf5, 0x16, 0x5b, 0x57, 0x5b, 0x6c, 0x7d, 0xac, 0x98, 0x56, 0x47,
+	0x15, 0x9d, 0xf5, 0xd9, 0xdf, 0x01, 0x00, 0x9c, 0xdf, 0x91, 0x1c, 0xbc, 0x03, 0x00, 0x00,
+}
+
+func (m *Params) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Params) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Params) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	n1, err1 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.MaxRetryIntervalDuration, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.MaxRetryIntervalDuration):])
+	if err1 != nil {
+		return 0, err1
+	}
+	i -= n1
+	i = encodeVarintConfig(dAtA, i, uint64(n1))
+	i--
+	dAtA[i] = 0x4a
+	n2, err2 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.PushIntervalDuration, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.PushIntervalDuration):])
+	if err2 != nil {
+		return 0, err2
+	}
+	i -= n2
+	i = encodeVarintConfig(dAtA, i, uint64(n2))
+	i--
+	dAtA[i] = 0x42
+	if m.MaxBatchSizeBytes != 0 {
+		i = encodeVarintConfig(dAtA, i, uint64(m.MaxBatchSizeBytes))
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.MaxBatchEntries != 0 {
+		i = encodeVarintConfig(dAtA, i, uint64(m.MaxBatchEntries))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.Sink != nil {
+		{
+			size := m.Sink.Size()
+			i -= size
+			if _, err := m.Sink.MarshalTo(dAtA[i:]); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if m.SyncWrites {
+		i--
+		if m.SyncWrites {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.SegmentSizeBytes != 0 {
+		i = encodeVarintConfig(dAtA, i, uint64(m.SegmentSizeBytes))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.MaxSpoolSizeBytes != 0 {
+		i = encodeVarintConfig(dAtA, i, uint64(m.MaxSpoolSizeBytes))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.SpoolDirectory) > 0 {
+		i -= len(m.SpoolDirectory)
+		copy(dAtA[i:], m.SpoolDirectory)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.SpoolDirectory)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Params_Http) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Params_Http) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.Http != nil {
+		{
+			size, err := m.Http.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintConfig(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x2a
+	}
+	return len(dAtA) - i, nil
+}
+func (m *Params_HttpSink) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Params_HttpSink) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Params_HttpSink) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	n4, err4 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.Timeout, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.Timeout):])
+	if err4 != nil {
+		return 0, err4
+	}
+	i -= n4
+	i = encodeVarintConfig(dAtA, i, uint64(n4))
+	i--
+	dAtA[i] = 0x1a
+	if len(m.Headers) > 0 {
+		for k := range m.Headers {
+			v := m.Headers[k]
+			baseI := i
+			i -= len(v)
+			copy(dAtA[i:], v)
+			i = encodeVarintConfig(dAtA, i, uint64(len(v)))
+			i--
+			dAtA[i] = 0x12
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintConfig(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintConfig(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.Url) > 0 {
+		i -= len(m.Url)
+		copy(dAtA[i:], m.Url)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.Url)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintConfig(dAtA []byte, offset int, v uint64) int {
+	offset -= sovConfig(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *Params) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.SpoolDirectory)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	if m.MaxSpoolSizeBytes != 0 {
+		n += 1 + sovConfig(uint64(m.MaxSpoolSizeBytes))
+	}
+	if m.SegmentSizeBytes != 0 {
+		n += 1 + sovConfig(uint64(m.SegmentSizeBytes))
+	}
+	if m.SyncWrites {
+		n += 2
+	}
+	if m.Sink != nil {
+		n += m.Sink.Size()
+	}
+	if m.MaxBatchEntries != 0 {
+		n += 1 + sovConfig(uint64(m.MaxBatchEntries))
+	}
+	if m.MaxBatchSizeBytes != 0 {
+		n += 1 + sovConfig(uint64(m.MaxBatchSizeBytes))
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.PushIntervalDuration)
+	n += 1 + l + sovConfig(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.MaxRetryIntervalDuration)
+	n += 1 + l + sovConfig(uint64(l))
+	return n
+}
+
+func (m *Params_Http) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Http != nil {
+		l = m.Http.Size()
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	return n
+}
+func (m *Params_HttpSink) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Url)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	if len(m.Headers) > 0 {
+		for k, v := range m.Headers {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovConfig(uint64(len(k))) + 1 + len(v) + sovConfig(uint64(len(v)))
+			n += mapEntrySize + 1 + sovConfig(uint64(mapEntrySize))
+		}
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.Timeout)
+	n += 1 + l + sovConfig(uint64(l))
+	return n
+}
+
+func sovConfig(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozConfig(x uint64) (n int) {
+	return sovConfig(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *Params) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Params{`,
+		`SpoolDirectory:` + fmt.Sprintf("%v", this.SpoolDirectory) + `,`,
+		`MaxSpoolSizeBytes:` + fmt.Sprintf("%v", this.MaxSpoolSizeBytes) + `,`,
+		`SegmentSizeBytes:` + fmt.Sprintf("%v", this.SegmentSizeBytes) + `,`,
+		`SyncWrites:` + fmt.Sprintf("%v", this.SyncWrites) + `,`,
+		`Sink:` + fmt.Sprintf("%v", this.Sink) + `,`,
+		`MaxBatchEntries:` + fmt.Sprintf("%v", this.MaxBatchEntries) + `,`,
+		`MaxBatchSizeBytes:` + fmt.Sprintf("%v", this.MaxBatchSizeBytes) + `,`,
+		`PushIntervalDuration:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.PushIntervalDuration), "Duration", "types.Duration", 1), `&`, ``, 1) + `,`,
+		`MaxRetryIntervalDuration:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.MaxRetryIntervalDuration), "Duration", "types.Duration", 1), `&`, ``, 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Params_Http) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Params_Http{`,
+		`Http:` + strings.Replace(fmt.Sprintf("%v", this.Http), "Params_HttpSink", "Params_HttpSink", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Params_HttpSink) String() string {
+	if this == nil {
+		return "nil"
+	}
+	keysForHeaders := make([]string, 0, len(this.Headers))
+	for k, _ := range this.Headers {
+		keysForHeaders = append(keysForHeaders, k)
+	}
+	github_com_gogo_protobuf_sortkeys.Strings(keysForHeaders)
+	mapStringForHeaders := "map[string]string{"
+	for _, k := range keysForHeaders {
+		mapStringForHeaders += fmt.Sprintf("%v: %v,", k, this.Headers[k])
+	}
+	mapStringForHeaders += "}"
+	s := strings.Join([]string{`&Params_HttpSink{`,
+		`Url:` + fmt.Sprintf("%v", this.Url) + `,`,
+		`Headers:` + mapStringForHeaders + `,`,
+		`Timeout:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.Timeout), "Duration", "types.Duration", 1), `&`, ``, 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func valueToStringConfig(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("*%v", pv)
+}
+func (m *Params) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowConfig
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Params: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Params: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SpoolDirectory", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SpoolDirectory = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxSpoolSizeBytes", wireType)
+			}
+			m.MaxSpoolSizeBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxSpoolSizeBytes |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SegmentSizeBytes", wireType)
+			}
+			m.SegmentSizeBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SegmentSizeBytes |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SyncWrites", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.SyncWrites = bool(v != 0)
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Http", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &Params_HttpSink{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Sink = &Params_Http{v}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxBatchEntries", wireType)
+			}
+			m.MaxBatchEntries = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxBatchEntries |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxBatchSizeBytes", wireType)
+			}
+			m.MaxBatchSizeBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxBatchSizeBytes |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PushIntervalDuration", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.PushIntervalDuration, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxRetryIntervalDuration", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.MaxRetryIntervalDuration, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipConfig(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Params_HttpSink) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowConfig
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: HttpSink: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: HttpSink: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Url", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Url = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Headers", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Headers == nil {
+				m.Headers = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowConfig
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowConfig
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthConfig
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthConfig
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowConfig
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthConfig
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue < 0 {
+						return ErrInvalidLengthConfig
+					}
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipConfig(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthConfig
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Headers[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timeout", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.Timeout, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipConfig(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipConfig(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowConfig
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+			return iNdEx, nil
+		case 1:
+			iNdEx += 8
+			return iNdEx, nil
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthConfig
+			}
+			iNdEx += length
+			if iNdEx < 0 {
+				return 0, ErrInvalidLengthConfig
+			}
+			return iNdEx, nil
+		case 3:
+			for {
+				var innerWire uint64
+				var start int = iNdEx
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return 0, ErrIntOverflowConfig
+					}
+					if iNdEx >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					innerWire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				innerWireType := int(innerWire & 0x7)
+				if innerWireType == 4 {
+					break
+				}
+				next, err := skipConfig(dAtA[start:])
+				if err != nil {
+					return 0, err
+				}
+				iNdEx = start + next
+				if iNdEx < 0 {
+					return 0, ErrInvalidLengthConfig
+				}
+			}
+			return iNdEx, nil
+		case 4:
+			return iNdEx, nil
+		case 5:
+			iNdEx += 4
+			return iNdEx, nil
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+	}
+	panic("unreachable")
+}
+
+var (
+	ErrInvalidLengthConfig = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowConfig   = fmt.Errorf("proto: integer overflow")
+)
@@ -0,0 +1,531 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spool
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	segmentSuffix = ".log"
+	committedFile = "committed"
+	lockFile      = "lock"
+
+	// every record is prefixed by its length and the CRC32 of its payload.
+	recordHeaderSize = 8
+)
+
+var (
+	errSpoolFull = errors.New("spool is full")
+
+	// errChecksum is returned for a record read in full whose payload does not
+	// match its checksum. The records after it can still be read.
+	errChecksum = errors.New("checksum mismatch")
+
+	// queues are the queues opened by the handlers of this process, by
+	// directory. Mixer builds the handlers of a new configuration before it
+	// closes the old ones, so both generations share the queue of a directory
+	// instead of writing to it concurrently.
+	queuesMu sync.Mutex
+	queues   = map[string]*diskQueue{}
+)
+
+type (
+	// diskQueue is an append-only, segmented log of records on disk. Records
+	// are addressed by a monotonically increasing offset. The queue remembers
+	// the offset below which all records have been acknowledged, and deletes
+	// segments once all of their records are acknowledged.
+	//
+	// Segment files are named after the offset of their first record, so the
+	// queue can be recovered from the directory listing alone. The directory
+	// is locked while the queue is open, so no other process writes to it.
+	diskQueue struct {
+		dir  string
+		lock *os.File
+
+		// refs is the number of handlers holding the queue, guarded by queuesMu.
+		refs int
+
+		// forwardMu is held while a batch is forwarded, so the forwarders of
+		// the handlers sharing the queue never send the same records.
+		forwardMu sync.Mutex
+
+		mu          sync.Mutex
+		segmentSize int64
+		maxSize     int64
+		syncWrites  bool
+		segments    []*segment
+		active      *os.File
+		next        int64
+		committed   int64
+		size        int64
+
+		// position of the last read, used to avoid rescanning a segment from
+		// the start for every batch.
+		cursor readCursor
+	}
+
+	segment struct {
+		base  int64
+		count int64
+		size  int64
+	}
+
+	readCursor struct {
+		offset int64
+		base   int64
+		pos    int64
+	}
+
+	record struct {
+		offset int64
+		data   []byte
+	}
+
+	// readBatch is the result of a read.
+	readBatch struct {
+		records []record
+		// next is the offset following the records read or skipped.
+		next int64
+		// corrupt is the number of corrupt records skipped.
+		corrupt int64
+	}
+)
+
+// acquireDiskQueue returns the queue of the directory, opening it unless a
+// handler of this process already holds it. The options of the last handler
+// acquiring the queue apply. The queue must be released once unused.
+func acquireDiskQueue(dir string, segmentSize, maxSize int64, syncWrites bool) (*diskQueue, error) {
+	dir = filepath.Clean(dir)
+	queuesMu.Lock()
+	defer queuesMu.Unlock()
+
+	if q, ok := queues[dir]; ok {
+		q.mu.Lock()
+		q.segmentSize, q.maxSize, q.syncWrites = segmentSize, maxSize, syncWrites
+		q.mu.Unlock()
+		q.refs++
+		return q, nil
+	}
+
+	q, err := openDiskQueue(dir, segmentSize, maxSize, syncWrites)
+	if err != nil {
+		return nil, err
+	}
+	q.refs = 1
+	queues[dir] = q
+	return q, nil
+}
+
+// release closes the queue once no handler holds it anymore.
+func (q *diskQueue) release() error {
+	queuesMu.Lock()
+	defer queuesMu.Unlock()
+
+	if q.refs--; q.refs > 0 {
+		return nil
+	}
+	delete(queues, q.dir)
+	return q.close()
+}
+
+func openDiskQueue(dir string, segmentSize, maxSize int64, syncWrites bool) (*diskQueue, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	lock, err := lockDirectory(filepath.Join(dir, lockFile))
+	if err != nil {
+		return nil, err
+	}
+
+	q := &diskQueue{
+		dir:         dir,
+		lock:        lock,
+		segmentSize: segmentSize,
+		maxSize:     maxSize,
+		syncWrites:  syncWrites,
+	}
+	if err = q.recover(); err != nil {
+		_ = lock.Close()
+		return nil, err
+	}
+	return q, nil
+}
+
+// recover loads the committed offset and the segments of the directory.
+func (q *diskQueue) recover() error {
+	dir := q.dir
+	committed, err := readCommitted(filepath.Join(dir, committedFile))
+	if err != nil {
+		return err
+	}
+	q.committed = committed
+	q.next = committed
+
+	bases, err := listSegments(dir)
+	if err != nil {
+		return err
+	}
+	for i, base := range bases {
+		last := i == len(bases)-1
+		var next int64
+		if !last {
+			next = bases[i+1]
+		}
+		seg, err := recoverSegment(q.segmentPath(base), base, next, last)
+		if err != nil {
+			return err
+		}
+		q.segments = append(q.segments, seg)
+		q.size += seg.size
+		if end := seg.base + seg.count; end > q.next {
+			q.next = end
+		}
+	}
+
+	if len(q.segments) > 0 {
+		last := q.segments[len(q.segments)-1]
+		if q.active, err = os.OpenFile(q.segmentPath(last.base), os.O_WRONLY|os.O_APPEND, 0600); err != nil {
+			return err
+		}
+	}
+
+	// drop segments that were fully acknowledged before the last shutdown.
+	q.deleteAcknowledged()
+	return nil
+}
+
+func (q *diskQueue) segmentPath(base int64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%s", base, segmentSuffix))
+}
+
+// append durably adds the given payloads to the end of the queue. Either all
+// or none of the payloads are added.
+func (q *diskQueue) append(payloads [][]byte) error {
+	var buf []byte
+	for _, p := range payloads {
+		var hdr [recordHeaderSize]byte
+		binary.BigEndian.PutUint32(hdr[0:4], uint32(len(p)))
+		binary.BigEndian.PutUint32(hdr[4:8], crc32.ChecksumIEEE(p))
+		buf = append(buf, hdr[:]...)
+		buf = append(buf, p...)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.size+int64(len(buf)) > q.maxSize {
+		return errSpoolFull
+	}
+
+	if q.active == nil || q.segments[len(q.segments)-1].size >= q.segmentSize {
+		if err := q.roll(); err != nil {
+			return err
+		}
+	}
+
+	seg := q.segments[len(q.segments)-1]
+	n, err := q.active.Write(buf)
+	if err != nil {
+		// undo a partial write so the segment never contains a torn record.
+		_ = q.active.Truncate(seg.size)
+		return err
+	}
+	if q.syncWrites {
+		if err = q.active.Sync(); err != nil {
+			_ = q.active.Truncate(seg.size)
+			return err
+		}
+	}
+
+	seg.size += int64(n)
+	seg.count += int64(len(payloads))
+	q.size += int64(n)
+	q.next += int64(len(payloads))
+	return nil
+}
+
+// roll starts a new segment beginning at the next offset.
+func (q *diskQueue) roll() error {
+	f, err := os.OpenFile(q.segmentPath(q.next), os.O_WRONLY|os.O_CREATE|os.O_EXCL|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if q.active != nil {
+		_ = q.active.Close()
+	}
+	q.active = f
+	q.segments = append(q.segments, &segment{base: q.next})
+	return nil
+}
+
+// read returns up to maxEntries records, totalling at most maxBytes of
+// payload, starting at the given offset. At least one record is returned if
+// any is available, regardless of its size. Corrupt records are skipped: a
+// record failing its checksum alone, or the rest of its segment when the
+// record cannot be delimited anymore.
+func (q *diskQueue) read(from int64, maxEntries int, maxBytes int64) (readBatch, error) {
+	q.mu.Lock()
+	var seg segment
+	found := false
+	for _, s := range q.segments {
+		if from >= s.base && from < s.base+s.count {
+			seg = *s
+			found = true
+			break
+		}
+	}
+	cursor := q.cursor
+	q.mu.Unlock()
+
+	if !found {
+		return readBatch{next: from}, nil
+	}
+
+	f, err := os.Open(q.segmentPath(seg.base))
+	if err != nil {
+		return readBatch{}, err
+	}
+	defer func() { _ = f.Close() }()
+
+	offset, pos := seg.base, int64(0)
+	if cursor.base == seg.base && cursor.offset <= from {
+		offset, pos = cursor.offset, cursor.pos
+	}
+	if _, err = f.Seek(pos, io.SeekStart); err != nil {
+		return readBatch{}, err
+	}
+
+	r := bufio.NewReader(io.LimitReader(f, seg.size-pos))
+	var out readBatch
+	var total int64
+	end := seg.base + seg.count
+	for offset < end && len(out.records) < maxEntries {
+		data, err := readRecord(r, seg.size-pos)
+		if err != nil && err != errChecksum {
+			// the following records cannot be delimited.
+			if offset >= from {
+				out.corrupt += end - offset
+			} else {
+				out.corrupt += end - from
+			}
+			offset, pos = end, seg.size
+			break
+		}
+		if offset >= from {
+			if err == errChecksum {
+				out.corrupt++
+			} else {
+				if len(out.records) > 0 && total+int64(len(data)) > maxBytes {
+					break
+				}
+				out.records = append(out.records, record{offset: offset, data: data})
+				total += int64(len(data))
+			}
+		}
+		offset++
+		pos += int64(recordHeaderSize + len(data))
+	}
+	out.next = offset
+
+	q.mu.Lock()
+	q.cursor = readCursor{offset: offset, base: seg.base, pos: pos}
+	q.mu.Unlock()
+
+	return out, nil
+}
+
+// commit marks all records below offset as acknowledged and deletes the
+// segments that no longer hold unacknowledged records.
+func (q *diskQueue) commit(offset int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if offset <= q.committed {
+		return nil
+	}
+	if err := writeCommitted(filepath.Join(q.dir, committedFile), offset); err != nil {
+		return err
+	}
+	q.committed = offset
+	q.deleteAcknowledged()
+	return nil
+}
+
+// deleteAcknowledged removes fully acknowledged segments, except the one
+// currently being written to. Must be called with the lock held.
+func (q *diskQueue) deleteAcknowledged() {
+	for len(q.segments) > 1 {
+		s := q.segments[0]
+		if s.base+s.count > q.committed {
+			return
+		}
+		if err := os.Remove(q.segmentPath(s.base)); err != nil && !os.IsNotExist(err) {
+			return
+		}
+		q.size -= s.size
+		q.segments = q.segments[1:]
+	}
+}
+
+// committedOffset returns the offset of the first unacknowledged record.
+func (q *diskQueue) committedOffset() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.committed
+}
+
+// depth returns the number of unacknowledged records and the size of the spool on disk.
+func (q *diskQueue) depth() (int64, int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.next - q.committed, q.size
+}
+
+func (q *diskQueue) close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var err error
+	if q.active != nil {
+		err = q.active.Close()
+		q.active = nil
+	}
+	if q.lock != nil {
+		if lerr := q.lock.Close(); err == nil {
+			err = lerr
+		}
+		q.lock = nil
+	}
+	return err
+}
+
+// readRecord reads the next record, of at most limit bytes including its
+// header.
+func readRecord(r io.Reader, limit int64) ([]byte, error) {
+	var hdr [recordHeaderSize]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+	size := int64(binary.BigEndian.Uint32(hdr[0:4]))
+	if size > limit-recordHeaderSize {
+		return nil, io.ErrUnexpectedEOF
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(hdr[4:8]) {
+		return data, errChecksum
+	}
+	return data, nil
+}
+
+// recoverSegment counts the records of a segment. A torn or corrupt record at
+// the end of the last segment, left behind by a crash during a write, is
+// truncated so that new records can be appended after it. The records of the
+// other segments end at the base of the next one, next; the corrupt ones are
+// skipped when read.
+func recoverSegment(path string, base, next int64, last bool) (*segment, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	seg := &segment{base: base}
+	r := bufio.NewReader(f)
+	for {
+		data, err := readRecord(r, fi.Size()-seg.size)
+		if err == io.EOF {
+			return seg, nil
+		}
+		if err != nil && err != errChecksum {
+			if !last {
+				seg.count, seg.size = next-base, fi.Size()
+				return seg, nil
+			}
+			return seg, f.Truncate(seg.size)
+		}
+		seg.count++
+		seg.size += int64(recordHeaderSize + len(data))
+	}
+}
+
+func listSegments(dir string) ([]int64, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var bases []int64
+	for _, fi := range files {
+		name := fi.Name()
+		if fi.IsDir() || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		base, err := strconv.ParseInt(strings.TrimSuffix(name, segmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		bases = append(bases, base)
+	}
+	sort.Slice(bases, func(i, j int) bool { return bases[i] < bases[j] })
+	return bases, nil
+}
+
+func readCommitted(path string) (int64, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+}
+
+// writeCommitted atomically replaces the committed offset file.
+func writeCommitted(path string, offset int64) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.WriteString(strconv.FormatInt(offset, 10)); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,305 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spool
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func openTestQueue(t *testing.T, dir string, segmentSize, maxSize int64) *diskQueue {
+	t.Helper()
+	q, err := openDiskQueue(dir, segmentSize, maxSize, false)
+	if err != nil {
+		t.Fatalf("openDiskQueue() failed: %v", err)
+	}
+	return q
+}
+
+func payloads(from, to int) [][]byte {
+	var out [][]byte
+	for i := from; i < to; i++ {
+		out = append(out, []byte(fmt.Sprintf("entry-%03d", i)))
+	}
+	return out
+}
+
+func checkRecords(t *testing.T, b readBatch, from, to int) {
+	t.Helper()
+	got := b.records
+	if len(got) != to-from {
+		t.Fatalf("got %d records, want %d", len(got), to-from)
+	}
+	for i, r := range got {
+		want := fmt.Sprintf("entry-%03d", from+i)
+		if r.offset != int64(from+i) || string(r.data) != want {
+			t.Errorf("record %d = {%d, %q}, want {%d, %q}", i, r.offset, r.data, from+i, want)
+		}
+	}
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*"+segmentSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestDiskQueue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	// each record takes 17 bytes, so segments roll every 4 records.
+	q := openTestQueue(t, dir, 64, 1<<20)
+	for i := 0; i < 10; i++ {
+		if err = q.append(payloads(i, i+1)); err != nil {
+			t.Fatalf("append() failed: %v", err)
+		}
+	}
+	if got := len(segmentFiles(t, dir)); got != 3 {
+		t.Errorf("got %d segments, want 3", got)
+	}
+
+	// reads stop at segment boundaries and respect both limits.
+	recs, err := q.read(0, 100, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRecords(t, recs, 0, 4)
+
+	recs, err = q.read(1, 2, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRecords(t, recs, 1, 3)
+
+	recs, err = q.read(4, 100, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRecords(t, recs, 4, 6)
+
+	if depth, _ := q.depth(); depth != 10 {
+		t.Errorf("depth = %d, want 10", depth)
+	}
+
+	// acknowledging the first segment deletes it.
+	if err = q.commit(5); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(segmentFiles(t, dir)); got != 2 {
+		t.Errorf("got %d segments after commit, want 2", got)
+	}
+	if depth, _ := q.depth(); depth != 5 {
+		t.Errorf("depth = %d, want 5", depth)
+	}
+	if err = q.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// unacknowledged records are replayed after a restart.
+	q = openTestQueue(t, dir, 64, 1<<20)
+	defer func() { _ = q.close() }()
+	if got := q.committedOffset(); got != 5 {
+		t.Errorf("committedOffset() = %d, want 5", got)
+	}
+	recs, err = q.read(q.committedOffset(), 100, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRecords(t, recs, 5, 8)
+
+	if err = q.append(payloads(10, 11)); err != nil {
+		t.Fatal(err)
+	}
+	recs, err = q.read(8, 100, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRecords(t, recs, 8, 11)
+}
+
+func TestDiskQueueTornWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	q := openTestQueue(t, dir, 1<<20, 1<<20)
+	if err = q.append(payloads(0, 3)); err != nil {
+		t.Fatal(err)
+	}
+	_ = q.close()
+
+	// simulate a crash in the middle of writing a record.
+	f, err := os.OpenFile(segmentFiles(t, dir)[0], os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.Write([]byte{0, 0, 0, 9, 1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	q = openTestQueue(t, dir, 1<<20, 1<<20)
+	defer func() { _ = q.close() }()
+	if err = q.append(payloads(3, 4)); err != nil {
+		t.Fatal(err)
+	}
+	recs, err := q.read(0, 100, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRecords(t, recs, 0, 4)
+}
+
+func TestDiskQueueFull(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	q := openTestQueue(t, dir, 34, 68)
+	defer func() { _ = q.close() }()
+	for i := 0; i < 4; i += 2 {
+		if err = q.append(payloads(i, i+2)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = q.append(payloads(4, 5)); err != errSpoolFull {
+		t.Fatalf("append() = %v, want %v", err, errSpoolFull)
+	}
+
+	// space is reclaimed once whole segments are acknowledged.
+	if err = q.commit(2); err != nil {
+		t.Fatal(err)
+	}
+	if err = q.append(payloads(4, 5)); err != nil {
+		t.Fatalf("append() after commit failed: %v", err)
+	}
+}
+
+func TestDiskQueueCorrupt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	// each record takes 17 bytes, so segments roll every 4 records.
+	q := openTestQueue(t, dir, 64, 1<<20)
+	for i := 0; i < 10; i++ {
+		if err = q.append(payloads(i, i+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = q.close()
+
+	segments := segmentFiles(t, dir)
+	corrupt := func(path string, offset int64, b byte) {
+		t.Helper()
+		f, err := os.OpenFile(path, os.O_WRONLY, 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = f.Close() }()
+		if _, err = f.WriteAt([]byte{b}, offset); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a payload byte of record 1, and the length of record 5.
+	corrupt(segments[0], 17+recordHeaderSize, 'x')
+	corrupt(segments[1], 17, 0xff)
+
+	q = openTestQueue(t, dir, 64, 1<<20)
+	defer func() { _ = q.close() }()
+
+	b, err := q.read(0, 100, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.records) != 3 || b.records[0].offset != 0 || b.records[1].offset != 2 || b.corrupt != 1 || b.next != 4 {
+		t.Errorf("got %d records, %d corrupt, next %d; want records 0, 2 and 3, 1 corrupt, next 4",
+			len(b.records), b.corrupt, b.next)
+	}
+
+	// the rest of the segment cannot be delimited after a corrupt length.
+	b, err = q.read(4, 100, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRecords(t, b, 4, 5)
+	if b.corrupt != 3 || b.next != 8 {
+		t.Errorf("got %d corrupt, next %d; want 3 corrupt, next 8", b.corrupt, b.next)
+	}
+	b, err = q.read(8, 100, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRecords(t, b, 8, 10)
+}
+
+func TestDiskQueueShared(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	q1, err := acquireDiskQueue(dir, 64, 1<<20, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q2, err := acquireDiskQueue(dir+"/", 64, 1<<20, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q1 != q2 {
+		t.Fatal("acquireDiskQueue() opened the directory twice")
+	}
+
+	// the directory is locked while the queue is open.
+	if _, err = openDiskQueue(dir, 64, 1<<20, false); err == nil {
+		t.Fatal("openDiskQueue() of a locked directory succeeded")
+	}
+
+	if err = q1.release(); err != nil {
+		t.Fatal(err)
+	}
+	if err = q2.append(payloads(0, 1)); err != nil {
+		t.Fatalf("append() after the first release failed: %v", err)
+	}
+	if err = q2.release(); err != nil {
+		t.Fatal(err)
+	}
+
+	q := openTestQueue(t, dir, 64, 1<<20)
+	defer func() { _ = q.close() }()
+	b, err := q.read(0, 100, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRecords(t, b, 0, 1)
+}
@@ -0,0 +1,261 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spool
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"istio.io/istio/mixer/pkg/adapter"
+)
+
+const initialRetryInterval = 500 * time.Millisecond
+
+var (
+	directoryTag tag.Key
+
+	depthEntries = stats.Int64(
+		"mixer/adapter/spool/depth",
+		"The number of log entries in the spool that have not been acknowledged by the sink.",
+		stats.UnitDimensionless)
+	sizeBytes = stats.Int64(
+		"mixer/adapter/spool/size_bytes",
+		"The size of the spool on disk.",
+		stats.UnitBytes)
+	rejectedTotal = stats.Int64(
+		"mixer/adapter/spool/rejected_total",
+		"The number of log entries rejected because the spool was full.",
+		stats.UnitDimensionless)
+	sendFailuresTotal = stats.Int64(
+		"mixer/adapter/spool/send_failures_total",
+		"The number of batches the sink failed to acknowledge.",
+		stats.UnitDimensionless)
+	corruptTotal = stats.Int64(
+		"mixer/adapter/spool/corrupt_total",
+		"The number of corrupt log entries skipped in the spool.",
+		stats.UnitDimensionless)
+	invalidTotal = stats.Int64(
+		"mixer/adapter/spool/invalid_total",
+		"The number of log entries skipped in the spool because they are not valid JSON.",
+		stats.UnitDimensionless)
+)
+
+func init() {
+	var err error
+	if directoryTag, err = tag.NewKey("spool_directory"); err != nil {
+		panic(err)
+	}
+
+	keys := []tag.Key{directoryTag}
+	if err = view.Register(
+		newView(depthEntries, keys, view.LastValue()),
+		newView(sizeBytes, keys, view.LastValue()),
+		newView(rejectedTotal, keys, view.Sum()),
+		newView(sendFailuresTotal, keys, view.Sum()),
+		newView(corruptTotal, keys, view.Sum()),
+		newView(invalidTotal, keys, view.Sum()),
+	); err != nil {
+		panic(err)
+	}
+}
+
+func newView(measure stats.Measure, keys []tag.Key, aggregation *view.Aggregation) *view.View {
+	return &view.View{
+		Name:        measure.Name(),
+		Description: measure.Description(),
+		Measure:     measure,
+		TagKeys:     keys,
+		Aggregation: aggregation,
+	}
+}
+
+type (
+	forwarderOptions struct {
+		maxBatchEntries  int
+		maxBatchBytes    int64
+		pushInterval     time.Duration
+		maxRetryInterval time.Duration
+	}
+
+	// forwarder moves records from the spool to the sink. Records are only
+	// committed once the sink acknowledged them, so any record not yet
+	// acknowledged when Mixer stops is sent again after a restart.
+	forwarder struct {
+		forwarderOptions
+
+		queue  *diskQueue
+		sink   sink
+		env    adapter.Env
+		ctx    context.Context
+		cancel context.CancelFunc
+
+		notifyCh  chan struct{}
+		stopCh    chan struct{}
+		doneCh    chan struct{}
+		closeOnce sync.Once
+	}
+)
+
+func newForwarder(queue *diskQueue, s sink, o forwarderOptions, env adapter.Env) *forwarder {
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, _ = tag.New(ctx, tag.Upsert(directoryTag, queue.dir))
+
+	return &forwarder{
+		forwarderOptions: o,
+		queue:            queue,
+		sink:             s,
+		env:              env,
+		ctx:              ctx,
+		cancel:           cancel,
+		notifyCh:         make(chan struct{}, 1),
+		stopCh:           make(chan struct{}),
+		doneCh:           make(chan struct{}),
+	}
+}
+
+// notify wakes up the forwarder after new records were spooled.
+func (f *forwarder) notify() {
+	select {
+	case f.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+func (f *forwarder) run() {
+	defer close(f.doneCh)
+
+	bo := backoff.NewExponentialBackOff()
+	bo.InitialInterval = initialRetryInterval
+	bo.MaxInterval = f.maxRetryInterval
+	// never give up; the spool is bounded by its size instead.
+	bo.MaxElapsedTime = 0
+	bo.Reset()
+
+	tick := time.NewTicker(f.pushInterval)
+	defer tick.Stop()
+
+	for {
+		f.recordDepth()
+
+		sent, err := f.forward()
+		if err != nil {
+			stats.Record(f.ctx, sendFailuresTotal.M(1))
+			d := bo.NextBackOff()
+			f.env.Logger().Warningf("failed to forward spooled log entries, retrying in %v: %v", d, err)
+			if !f.wait(time.After(d)) {
+				return
+			}
+			continue
+		}
+		bo.Reset()
+
+		if sent {
+			// keep going while there is a backlog.
+			select {
+			case <-f.stopCh:
+				return
+			default:
+			}
+			continue
+		}
+
+		select {
+		case <-f.stopCh:
+			return
+		case <-f.notifyCh:
+		case <-tick.C:
+		}
+	}
+}
+
+// wait blocks until the given channel fires. It returns false if the
+// forwarder was closed in the meantime.
+func (f *forwarder) wait(c <-chan time.Time) bool {
+	select {
+	case <-c:
+		return true
+	case <-f.stopCh:
+		return false
+	}
+}
+
+// forward sends the next batch of unacknowledged records, if any, and commits
+// them once acknowledged. Corrupt records, and records that are not valid JSON
+// and would fail every request to the sink, are committed without being sent.
+// It reports whether a batch was sent.
+func (f *forwarder) forward() (bool, error) {
+	// another handler may share the queue while Mixer swaps configurations.
+	f.queue.forwardMu.Lock()
+	defer f.queue.forwardMu.Unlock()
+
+	from := f.queue.committedOffset()
+	b, err := f.queue.read(from, f.maxBatchEntries, f.maxBatchBytes)
+	if err != nil {
+		return false, err
+	}
+	if b.corrupt > 0 {
+		f.env.Logger().Warningf("skipping %d corrupt log entries in spool %s", b.corrupt, f.queue.dir)
+		stats.Record(f.ctx, corruptTotal.M(b.corrupt))
+	}
+	if b.next <= from {
+		return false, nil
+	}
+
+	records := make([]record, 0, len(b.records))
+	for _, r := range b.records {
+		if !json.Valid(r.data) {
+			f.env.Logger().Warningf("skipping invalid log entry %d in spool %s", r.offset, f.queue.dir)
+			stats.Record(f.ctx, invalidTotal.M(1))
+			continue
+		}
+		records = append(records, r)
+	}
+	if len(records) > 0 {
+		if err = f.sink.send(f.ctx, records); err != nil {
+			return false, err
+		}
+	}
+	if err = f.queue.commit(b.next); err != nil {
+		return false, err
+	}
+	f.env.Logger().Debugf("forwarded %d spooled log entries", len(records))
+	return true, nil
+}
+
+func (f *forwarder) recordDepth() {
+	depth, size := f.queue.depth()
+	stats.Record(f.ctx, depthEntries.M(depth), sizeBytes.M(size))
+}
+
+func (f *forwarder) recordRejected(n int) {
+	stats.Record(f.ctx, rejectedTotal.M(int64(n)))
+}
+
+// close stops the forwarder, abandoning any in-flight request. Records that
+// were not acknowledged stay in the spool.
+func (f *forwarder) close() {
+	f.closeOnce.Do(func() {
+		close(f.stopCh)
+		f.cancel()
+	})
+	<-f.doneCh
+}
@@ -0,0 +1,88 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+type (
+	// sink delivers a batch of spooled records. A nil error acknowledges the
+	// whole batch.
+	sink interface {
+		send(ctx context.Context, records []record) error
+	}
+
+	httpSink struct {
+		url     string
+		headers map[string]string
+		client  *http.Client
+	}
+
+	// sinkEntry is the wire format of a single record sent to an HTTP sink.
+	sinkEntry struct {
+		Offset int64           `json:"offset"`
+		Entry  json.RawMessage `json:"entry"`
+	}
+)
+
+func newHTTPSink(url string, headers map[string]string, timeout time.Duration) *httpSink {
+	return &httpSink{
+		url:     url,
+		headers: headers,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *httpSink) send(ctx context.Context, records []record) error {
+	entries := make([]sinkEntry, 0, len(records))
+	for _, r := range records {
+		entries = append(entries, sinkEntry{Offset: r.offset, Entry: r.data})
+	}
+	body, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	// drain the body so the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("sink %s responded with status %s", s.url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// nolint: lll
+//go:generate $REPO_ROOT/bin/mixer_codegen.sh -a mixer/adapter/spool/config/config.proto -x "-n spool -t logentry"
+
+// Package spool contains an adapter that spools log entries to local disk and
+// forwards them to a remote sink with at-least-once delivery.
+package spool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	descriptor "istio.io/api/policy/v1beta1"
+	"istio.io/istio/mixer/adapter/metadata"
+	"istio.io/istio/mixer/adapter/spool/config"
+	"istio.io/istio/mixer/pkg/adapter"
+	"istio.io/istio/mixer/template/logentry"
+)
+
+const (
+	defaultMaxSpoolSize     int64 = 1 << 30
+	defaultSegmentSize      int64 = 64 << 20
+	defaultMaxBatchEntries  int64 = 500
+	defaultMaxBatchSize     int64 = 1 << 20
+	defaultPushInterval           = 5 * time.Second
+	defaultMaxRetryInterval       = time.Minute
+	defaultSinkTimeout            = 30 * time.Second
+)
+
+type (
+	builder struct {
+		adpCfg *config.Params
+		types  map[string]*logentry.Type
+	}
+
+	handler struct {
+		env       adapter.Env
+		types     map[string]*logentry.Type
+		queue     *diskQueue
+		forwarder *forwarder
+	}
+
+	// entry is the JSON representation of a spooled log entry.
+	entry struct {
+		Name      string                 `json:"name"`
+		Timestamp time.Time              `json:"timestamp"`
+		Severity  string                 `json:"severity,omitempty"`
+		Variables map[string]interface{} `json:"variables,omitempty"`
+	}
+)
+
+// ensure types implement the requisite interfaces
+var _ logentry.HandlerBuilder = &builder{}
+var _ logentry.Handler = &handler{}
+
+///////////////// Configuration-time Methods ///////////////
+
+// adapter.HandlerBuilder#Build
+func (b *builder) Build(ctx context.Context, env adapter.Env) (adapter.Handler, error) {
+	cfg := b.adpCfg
+	queue, err := acquireDiskQueue(cfg.SpoolDirectory,
+		orDefault(cfg.SegmentSizeBytes, defaultSegmentSize),
+		orDefault(cfg.MaxSpoolSizeBytes, defaultMaxSpoolSize),
+		cfg.SyncWrites)
+	if err != nil {
+		return nil, fmt.Errorf("could not open spool %s: %v", cfg.SpoolDirectory, err)
+	}
+
+	var s sink
+	switch t := cfg.Sink.(type) {
+	case *config.Params_Http:
+		timeout := t.Http.Timeout
+		if timeout == 0 {
+			timeout = defaultSinkTimeout
+		}
+		s = newHTTPSink(t.Http.Url, t.Http.Headers, timeout)
+	default:
+		_ = queue.release()
+		return nil, fmt.Errorf("unsupported sink %T", cfg.Sink)
+	}
+
+	o := forwarderOptions{
+		maxBatchEntries:  int(orDefault(cfg.MaxBatchEntries, defaultMaxBatchEntries)),
+		maxBatchBytes:    orDefault(cfg.MaxBatchSizeBytes, defaultMaxBatchSize),
+		pushInterval:     cfg.PushIntervalDuration,
+		maxRetryInterval: cfg.MaxRetryIntervalDuration,
+	}
+	if o.pushInterval == 0 {
+		o.pushInterval = defaultPushInterval
+	}
+	if o.maxRetryInterval == 0 {
+		o.maxRetryInterval = defaultMaxRetryInterval
+	}
+
+	if depth, _ := queue.depth(); depth > 0 {
+		env.Logger().Infof("replaying %d unacknowledged log entries from spool %s", depth, cfg.SpoolDirectory)
+	}
+
+	h := &handler{
+		env:       env,
+		types:     b.types,
+		queue:     queue,
+		forwarder: newForwarder(queue, s, o, env),
+	}
+	env.ScheduleDaemon(h.forwarder.run)
+
+	return h, nil
+}
+
+func orDefault(v, def int64) int64 {
+	if v > 0 {
+		return v
+	}
+	return def
+}
+
+// adapter.HandlerBuilder#SetAdapterConfig
+func (b *builder) SetAdapterConfig(cfg adapter.Config) {
+	b.adpCfg = cfg.(*config.Params)
+}
+
+// adapter.HandlerBuilder#Validate
+func (b *builder) Validate() (ce *adapter.ConfigErrors) {
+	cfg := b.adpCfg
+	if cfg.SpoolDirectory == "" {
+		ce = ce.Appendf("spoolDirectory", "Spool directory is empty")
+	}
+	if cfg.MaxSpoolSizeBytes < 0 {
+		ce = ce.Appendf("maxSpoolSizeBytes", "Value must be a positive number")
+	}
+	if cfg.SegmentSizeBytes < 0 {
+		ce = ce.Appendf("segmentSizeBytes", "Value must be a positive number")
+	}
+	if orDefault(cfg.SegmentSizeBytes, defaultSegmentSize) > orDefault(cfg.MaxSpoolSizeBytes, defaultMaxSpoolSize) {
+		ce = ce.Appendf("segmentSizeBytes", "Segment size must not exceed the maximum spool size")
+	}
+	if cfg.MaxBatchEntries < 0 {
+		ce = ce.Appendf("maxBatchEntries", "Value must be a positive number")
+	}
+	if cfg.MaxBatchSizeBytes < 0 {
+		ce = ce.Appendf("maxBatchSizeBytes", "Value must be a positive number")
+	}
+	if cfg.PushIntervalDuration < 0 {
+		ce = ce.Appendf("pushIntervalDuration", "Duration must be positive")
+	}
+	if cfg.MaxRetryIntervalDuration < 0 {
+		ce = ce.Appendf("maxRetryIntervalDuration", "Duration must be positive")
+	}
+
+	switch t := cfg.Sink.(type) {
+	case nil:
+		ce = ce.Appendf("sink", "No sink configured")
+	case *config.Params_Http:
+		if t.Http == nil || t.Http.Url == "" {
+			ce = ce.Appendf("http.url", "URL is empty")
+			break
+		}
+		if u, err := url.Parse(t.Http.Url); err != nil {
+			ce = ce.Appendf("http.url", "URL is malformed: %v", err)
+		} else if u.Scheme != "http" && u.Scheme != "https" {
+			ce = ce.Appendf("http.url", "URL scheme must be http or https, got %q", u.Scheme)
+		}
+		if t.Http.Timeout < 0 {
+			ce = ce.Appendf("http.timeout", "Duration must be positive")
+		}
+	default:
+		ce = ce.Appendf("sink", "Unsupported sink %T", cfg.Sink)
+	}
+	return
+}
+
+// logentry.HandlerBuilder#SetLogEntryTypes
+func (b *builder) SetLogEntryTypes(types map[string]*logentry.Type) {
+	b.types = types
+}
+
+////////////////// Request-time Methods //////////////////////////
+
+// logentry.Handler#HandleLogEntry
+//
+// Entries are only acknowledged to Mixer once they have been written to the
+// spool. If the spool is full the whole batch is rejected.
+func (h *handler) HandleLogEntry(_ context.Context, insts []*logentry.Instance) error {
+	payloads := make([][]byte, 0, len(insts))
+	for _, inst := range insts {
+		var types map[string]descriptor.ValueType
+		if t, found := h.types[inst.Name]; found {
+			types = t.Variables
+		}
+		b, err := json.Marshal(toEntry(inst, types))
+		if err != nil {
+			return fmt.Errorf("could not encode log entry %s: %v", inst.Name, err)
+		}
+		payloads = append(payloads, b)
+	}
+
+	if err := h.queue.append(payloads); err != nil {
+		if err == errSpoolFull {
+			h.forwarder.recordRejected(len(payloads))
+		}
+		return fmt.Errorf("could not spool %d log entries: %v", len(payloads), err)
+	}
+	h.forwarder.notify()
+	return nil
+}
+
+func toEntry(inst *logentry.Instance, types map[string]descriptor.ValueType) *entry {
+	vars := make(map[string]interface{}, len(inst.Variables))
+	for k, v := range inst.Variables {
+		switch val := v.(type) {
+		case string, bool, int64, float64, time.Time, map[string]string:
+			vars[k] = val
+		case []byte:
+			if types[k] == descriptor.IP_ADDRESS {
+				vars[k] = net.IP(val).String()
+			} else {
+				vars[k] = val
+			}
+		default:
+			vars[k] = adapter.Stringify(val)
+		}
+	}
+	return &entry{
+		Name:      inst.Name,
+		Timestamp: inst.Timestamp,
+		Severity:  inst.Severity,
+		Variables: vars,
+	}
+}
+
+// adapter.Handler#Close
+func (h *handler) Close() error {
+	h.forwarder.close()
+	return h.queue.release()
+}
+
+////////////////// Bootstrap //////////////////////////
+
+// GetInfo returns the adapter.Info specific to this adapter.
+func GetInfo() adapter.Info {
+	info := metadata.GetInfo("spool")
+	info.NewBuilder = func() adapter.HandlerBuilder { return &builder{} }
+	return info
+}
@@ -0,0 +1,414 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spool
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	descriptor "istio.io/api/policy/v1beta1"
+	"istio.io/istio/mixer/adapter/spool/config"
+	"istio.io/istio/mixer/pkg/adapter/test"
+	"istio.io/istio/mixer/template/logentry"
+)
+
+// fakeSink is an HTTP sink that fails the first failures requests and records
+// the entries of all acknowledged ones.
+type fakeSink struct {
+	sync.Mutex
+	failures int
+	requests int
+	acked    []sinkEntry
+	received chan struct{}
+}
+
+func newFakeSink(failures int) *fakeSink {
+	return &fakeSink{failures: failures, received: make(chan struct{}, 100)}
+}
+
+func (s *fakeSink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	defer func() { s.received <- struct{}{} }()
+
+	s.requests++
+	if r.Header.Get("x-token") != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if s.requests <= s.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var entries []sinkEntry
+	if err := json.NewDecoder(r.Body).Decode(&entries); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.acked = append(s.acked, entries...)
+}
+
+func (s *fakeSink) waitForAcked(t *testing.T, n int) []sinkEntry {
+	t.Helper()
+	deadline := time.After(10 * time.Second)
+	for {
+		s.Lock()
+		acked := s.acked
+		s.Unlock()
+		if len(acked) >= n {
+			return acked
+		}
+		select {
+		case <-s.received:
+		case <-deadline:
+			t.Fatalf("timed out waiting for %d acknowledged entries, got %d", n, len(acked))
+		}
+	}
+}
+
+func testConfig(dir, url string) *config.Params {
+	return &config.Params{
+		SpoolDirectory: dir,
+		Sink: &config.Params_Http{Http: &config.Params_HttpSink{
+			Url:     url,
+			Headers: map[string]string{"x-token": "secret"},
+		}},
+		PushIntervalDuration:     10 * time.Millisecond,
+		MaxRetryIntervalDuration: 10 * time.Millisecond,
+	}
+}
+
+func TestBasic(t *testing.T) {
+	info := GetInfo()
+	if len(info.SupportedTemplates) != 1 || info.SupportedTemplates[0] != logentry.TemplateName {
+		t.Errorf("SupportedTemplates = %v, want [%s]", info.SupportedTemplates, logentry.TemplateName)
+	}
+
+	b := info.NewBuilder().(*builder)
+	cfg := *info.DefaultConfig.(*config.Params)
+	cfg.SpoolDirectory = "/var/spool/mixer"
+	cfg.Sink = &config.Params_Http{Http: &config.Params_HttpSink{Url: "http://localhost:8080/logs"}}
+	b.SetAdapterConfig(&cfg)
+	if err := b.Validate(); err != nil {
+		t.Errorf("Got error %v, expecting success", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name   string
+		cfg    *config.Params
+		errors []string
+	}{
+		{
+			name: "valid",
+			cfg:  testConfig("/tmp/spool", "https://logs.example.com/ingest"),
+		},
+		{
+			name:   "empty",
+			cfg:    &config.Params{},
+			errors: []string{"spoolDirectory", "sink"},
+		},
+		{
+			name: "empty url",
+			cfg: &config.Params{
+				SpoolDirectory: "/tmp/spool",
+				Sink:           &config.Params_Http{Http: &config.Params_HttpSink{}},
+			},
+			errors: []string{"http.url"},
+		},
+		{
+			name:   "bad scheme",
+			cfg:    testConfig("/tmp/spool", "ftp://logs.example.com"),
+			errors: []string{"http.url"},
+		},
+		{
+			name: "negative values",
+			cfg: &config.Params{
+				SpoolDirectory:           "/tmp/spool",
+				Sink:                     &config.Params_Http{Http: &config.Params_HttpSink{Url: "http://localhost", Timeout: -time.Second}},
+				MaxSpoolSizeBytes:        -1,
+				SegmentSizeBytes:         -1,
+				MaxBatchEntries:          -1,
+				MaxBatchSizeBytes:        -1,
+				PushIntervalDuration:     -time.Second,
+				MaxRetryIntervalDuration: -time.Second,
+			},
+			errors: []string{"maxSpoolSizeBytes", "segmentSizeBytes", "maxBatchEntries", "maxBatchSizeBytes",
+				"pushIntervalDuration", "maxRetryIntervalDuration", "http.timeout"},
+		},
+		{
+			name: "segment larger than spool",
+			cfg: &config.Params{
+				SpoolDirectory:    "/tmp/spool",
+				Sink:              &config.Params_Http{Http: &config.Params_HttpSink{Url: "http://localhost"}},
+				MaxSpoolSizeBytes: 1024,
+				SegmentSizeBytes:  2048,
+			},
+			errors: []string{"segmentSizeBytes"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := &builder{}
+			b.SetAdapterConfig(c.cfg)
+			ce := b.Validate()
+			if len(c.errors) == 0 {
+				if ce != nil {
+					t.Fatalf("Validate() = %v, expecting success", ce)
+				}
+				return
+			}
+			if ce == nil {
+				t.Fatalf("Validate() succeeded, expecting errors %v", c.errors)
+			}
+			for _, e := range c.errors {
+				if !strings.Contains(ce.Error(), e) {
+					t.Errorf("Validate() = %v, expecting it to contain %q", ce, e)
+				}
+			}
+		})
+	}
+}
+
+func build(t *testing.T, cfg *config.Params) *handler {
+	t.Helper()
+	b := &builder{}
+	b.SetAdapterConfig(cfg)
+	b.SetLogEntryTypes(map[string]*logentry.Type{
+		"accesslog": {Variables: map[string]descriptor.ValueType{"sourceIp": descriptor.IP_ADDRESS}},
+	})
+	if ce := b.Validate(); ce != nil {
+		t.Fatalf("Validate() failed: %v", ce)
+	}
+	h, err := b.Build(context.Background(), test.NewEnv(t))
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	return h.(*handler)
+}
+
+func logEntry(msg string) *logentry.Instance {
+	return &logentry.Instance{
+		Name:      "accesslog",
+		Timestamp: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+		Severity:  "INFO",
+		Variables: map[string]interface{}{
+			"message":  msg,
+			"code":     int64(200),
+			"sourceIp": []byte(net.ParseIP("10.0.0.1").To4()),
+			"latency":  150 * time.Millisecond,
+		},
+	}
+}
+
+func TestForwardWithRetry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	s := newFakeSink(2)
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	h := build(t, testConfig(dir, srv.URL))
+	defer func() { _ = h.Close() }()
+
+	if err = h.HandleLogEntry(context.Background(), []*logentry.Instance{logEntry("first"), logEntry("second")}); err != nil {
+		t.Fatalf("HandleLogEntry() failed: %v", err)
+	}
+
+	acked := s.waitForAcked(t, 2)
+	for i, want := range []string{"first", "second"} {
+		if acked[i].Offset != int64(i) {
+			t.Errorf("entry %d has offset %d", i, acked[i].Offset)
+		}
+		var e entry
+		if err = json.Unmarshal(acked[i].Entry, &e); err != nil {
+			t.Fatal(err)
+		}
+		if e.Name != "accesslog" || e.Severity != "INFO" || e.Variables["message"] != want {
+			t.Errorf("entry %d = %+v, want message %q", i, e, want)
+		}
+		if e.Variables["sourceIp"] != "10.0.0.1" || e.Variables["latency"] != "150ms" || e.Variables["code"] != float64(200) {
+			t.Errorf("entry %d has unexpected variables %v", i, e.Variables)
+		}
+	}
+
+	// the spool is committed right after the sink responds.
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		depth, _ := h.queue.depth()
+		if depth == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("depth = %d after acknowledgement, want 0", depth)
+		}
+	}
+}
+
+func TestReplayAfterRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	// a sink that never acknowledges anything.
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer down.Close()
+
+	h := build(t, testConfig(dir, down.URL))
+	if err = h.HandleLogEntry(context.Background(), []*logentry.Instance{logEntry("first"), logEntry("second")}); err != nil {
+		t.Fatalf("HandleLogEntry() failed: %v", err)
+	}
+	if err = h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newFakeSink(0)
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	h = build(t, testConfig(dir, srv.URL))
+	defer func() { _ = h.Close() }()
+	if err = h.HandleLogEntry(context.Background(), []*logentry.Instance{logEntry("third")}); err != nil {
+		t.Fatalf("HandleLogEntry() failed: %v", err)
+	}
+
+	acked := s.waitForAcked(t, 3)
+	for i, e := range acked[:3] {
+		if e.Offset != int64(i) {
+			t.Errorf("entry %d has offset %d, want %d", i, e.Offset, i)
+		}
+	}
+}
+
+func TestSpoolFull(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer down.Close()
+
+	cfg := testConfig(dir, down.URL)
+	cfg.MaxSpoolSizeBytes = 512
+	cfg.SegmentSizeBytes = 256
+	h := build(t, cfg)
+	defer func() { _ = h.Close() }()
+
+	var handleErr error
+	for i := 0; i < 10 && handleErr == nil; i++ {
+		handleErr = h.HandleLogEntry(context.Background(), []*logentry.Instance{logEntry("entry")})
+	}
+	if handleErr == nil || !strings.Contains(handleErr.Error(), errSpoolFull.Error()) {
+		t.Errorf("HandleLogEntry() = %v, want spool full error", handleErr)
+	}
+}
+
+func TestHandlerHandover(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	s := newFakeSink(0)
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	// Mixer builds the handler of the new configuration before closing the old one.
+	old := build(t, testConfig(dir, srv.URL))
+	h := build(t, testConfig(dir, srv.URL))
+	defer func() { _ = h.Close() }()
+	for i, handler := range []*handler{old, h, old, h} {
+		if err = handler.HandleLogEntry(context.Background(), []*logentry.Instance{logEntry("entry")}); err != nil {
+			t.Fatalf("HandleLogEntry() %d failed: %v", i, err)
+		}
+	}
+	if err = old.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = h.HandleLogEntry(context.Background(), []*logentry.Instance{logEntry("entry")}); err != nil {
+		t.Fatalf("HandleLogEntry() after handover failed: %v", err)
+	}
+
+	s.waitForAcked(t, 5)
+	// let any duplicate get through before checking.
+	time.Sleep(100 * time.Millisecond)
+	s.Lock()
+	defer s.Unlock()
+	if len(s.acked) != 5 {
+		t.Fatalf("got %d acknowledged entries, want 5", len(s.acked))
+	}
+	for i, e := range s.acked {
+		if e.Offset != int64(i) {
+			t.Errorf("entry %d has offset %d, want %d", i, e.Offset, i)
+		}
+	}
+}
+
+func TestSkipInvalidEntry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	s := newFakeSink(0)
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	h := build(t, testConfig(dir, srv.URL))
+	defer func() { _ = h.Close() }()
+
+	// the record of the invalid entry passes its checksum, but would fail every request to the sink.
+	if err = h.queue.append([][]byte{[]byte(`{"name":"first"}`), []byte(`{"name":`), []byte(`{"name":"third"}`)}); err != nil {
+		t.Fatal(err)
+	}
+
+	acked := s.waitForAcked(t, 2)
+	if len(acked) != 2 || acked[0].Offset != 0 || acked[1].Offset != 2 {
+		t.Errorf("got acknowledged entries %+v, want offsets 0 and 2", acked)
+	}
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		depth, _ := h.queue.depth()
+		if depth == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("depth = %d after skipping the invalid entry, want 0", depth)
+		}
+	}
+}
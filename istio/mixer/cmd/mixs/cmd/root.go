@@ -45,6 +45,7 @@ func GetRootCmd(args []string, info map[string]template.Info, adapters []adapter
 
 	rootCmd.AddCommand(serverCmd(info, adapters, printf, fatalf))
 	rootCmd.AddCommand(probeCmd(printf, fatalf))
+	rootCmd.AddCommand(validateCmd(info, adapters, printf, fatalf))
 	rootCmd.AddCommand(version.CobraCommand())
 	rootCmd.AddCommand(collateral.CobraCommand(rootCmd, &doc.GenManHeader{
 		Title:   "Istio Mixer Server",
@@ -0,0 +1,92 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/cobra"
+
+	"istio.io/istio/mixer/cmd/shared"
+	"istio.io/istio/mixer/pkg/adapter"
+	"istio.io/istio/mixer/pkg/config"
+	"istio.io/istio/mixer/pkg/config/store"
+	runtimeconfig "istio.io/istio/mixer/pkg/runtime/config"
+	"istio.io/istio/mixer/pkg/template"
+)
+
+func validateCmd(info map[string]template.Info, adapters []adapter.InfoFn, printf, fatalf shared.FormatFn) *cobra.Command {
+	var configDir string
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validates a set of Mixer configuration files without applying them",
+		Long: "Loads all the Mixer configuration resources found in the YAML files of a directory and evaluates them\n" +
+			"together, as Mixer would once they are applied. Unresolved references, type errors and handler\n" +
+			"configurations rejected by their adapters are reported as errors; handlers and instances not used\n" +
+			"by any rule are reported as warnings. Exits with a non-zero status if any error is found.",
+		Args: cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			if configDir == "" {
+				fatalf("--config-dir must be specified")
+			}
+			if n := runValidate(configDir, info, adapters, printf); n > 0 {
+				fatalf("%d error(s) found in %s", n, configDir)
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&configDir, "config-dir", "",
+		"Directory containing the handlers, instances, rules and other Mixer configuration resources to validate.")
+
+	return cmd
+}
+
+// runValidate validates the configuration found in dir, reports the findings and returns the number of errors.
+func runValidate(dir string, info map[string]template.Info, adapters []adapter.InfoFn, printf shared.FormatFn) int {
+	templateMap := make(map[string]*template.Info, len(info))
+	for k, v := range info {
+		t := v // Make a local copy, otherwise we end up capturing the location of the last entry
+		templateMap[k] = &t
+	}
+	tmplRepo := template.NewRepository(info)
+	adapterMap := config.AdapterInfoMap(adapters, tmplRepo.SupportsTemplate)
+
+	var errs []error
+	state, err := store.LoadDir(dir, runtimeconfig.KindMap(adapterMap, templateMap))
+	if merr, ok := err.(*multierror.Error); ok {
+		errs = append(errs, merr.Errors...)
+	} else if err != nil {
+		errs = append(errs, err)
+	}
+
+	result := runtimeconfig.Validate(templateMap, adapterMap, state)
+	errs = append(errs, result.Errors...)
+
+	for _, err := range errs {
+		printf("error: %v", err)
+	}
+	for _, h := range result.UnusedHandlers {
+		printf("warning: handler %s is not used by any rule", h)
+	}
+	for _, i := range result.UnusedInstances {
+		printf("warning: instance %s is not used by any rule", i)
+	}
+
+	s := result.Snapshot
+	printf("%d resource(s): %d handler(s), %d instance(s), %d rule(s)", len(state),
+		len(s.HandlersStatic)+len(s.HandlersDynamic), len(s.InstancesStatic)+len(s.InstancesDynamic), len(s.Rules))
+
+	return len(errs)
+}
@@ -19,10 +19,14 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/ghodss/yaml"
+	"github.com/gogo/protobuf/proto"
+
+	cfg "istio.io/api/policy/v1beta1"
 )
 
 const testingCheckDuration = time.Millisecond * 5
@@ -358,3 +362,69 @@ func TestFSStore2Robust(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadDir(t *testing.T) {
+	fsroot, err := ioutil.TempDir("", "fsStore-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanupRootIfOK(t, fsroot)
+	const ns = "istio-mixer-testing"
+
+	k := Key{Kind: "handler", Namespace: ns, Name: "default"}
+	if err = write(fsroot, k, map[string]interface{}{"name": "default", "adapter": "noop"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = write(fsroot, Key{Kind: "VirtualService", Namespace: ns, Name: "ignored"}, map[string]interface{}{}); err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[string]proto.Message{"handler": &cfg.Handler{}}
+
+	resources, err := LoadDir(fsroot, kinds)
+	if err != nil {
+		t.Fatalf("Got %v, Want nil", err)
+	}
+	want := map[Key]*Resource{k: {
+		Metadata: ResourceMeta{Namespace: ns, Name: "default"},
+		Spec:     &cfg.Handler{Name: "default", Adapter: "noop"},
+	}}
+	if !reflect.DeepEqual(resources, want) {
+		t.Errorf("Got %+v, Want %+v", resources, want)
+	}
+
+	// Bad content is reported rather than skipped.
+	bad := `
+kind: handler
+metadata:
+  namespace: istio-mixer-testing
+  name: default
+spec:
+  adapter: noop
+---
+kind: handler
+metadata:
+  namespace: istio-mixer-testing
+  name: bad-spec
+spec:
+  adapter: [noop]
+---
+kind: handler
+spec:
+  adapter: noop
+`
+	if err = ioutil.WriteFile(filepath.Join(fsroot, "bad.yaml"), []byte(bad), 0644); err != nil {
+		t.Fatal(err)
+	}
+	resources, err = LoadDir(fsroot, kinds)
+	if err == nil {
+		t.Fatal("Got nil, Want error")
+	}
+	for _, msg := range []string{"is already defined", "unable to convert bad-spec.handler.istio-mixer-testing", "bad.yaml[2]"} {
+		if !strings.Contains(err.Error(), msg) {
+			t.Errorf("Got %v, Want it to contain %q", err, msg)
+		}
+	}
+	if len(resources) != 1 {
+		t.Errorf("Got %d resources, Want 1", len(resources))
+	}
+}
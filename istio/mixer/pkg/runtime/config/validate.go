@@ -0,0 +1,149 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gogo/protobuf/proto"
+	multierror "github.com/hashicorp/go-multierror"
+
+	config "istio.io/api/policy/v1beta1"
+	"istio.io/istio/mixer/pkg/adapter"
+	"istio.io/istio/mixer/pkg/config/store"
+	"istio.io/istio/mixer/pkg/runtime/config/constant"
+	"istio.io/istio/mixer/pkg/template"
+)
+
+// ValidationResult is the outcome of validating a complete set of configuration resources.
+type ValidationResult struct {
+	// Snapshot built out of the valid part of the configuration.
+	Snapshot *Snapshot
+
+	// Errors contains unresolved references, type errors and handler configurations rejected by their adapters.
+	Errors []error
+
+	// UnusedHandlers contains the names of the handlers that are not referenced by any rule.
+	UnusedHandlers []string
+
+	// UnusedInstances contains the names of the instances that are not referenced by any rule.
+	UnusedInstances []string
+}
+
+// Validate evaluates the given set of resources as a whole, the same way Mixer does when the resources are applied,
+// without instantiating any handler. In addition to the errors reported while building the snapshot, handler
+// configurations are validated by their adapters, and handlers and instances that no rule uses are reported.
+func Validate(
+	templates map[string]*template.Info,
+	adapters map[string]*adapter.Info,
+	state map[store.Key]*store.Resource) *ValidationResult {
+
+	e := NewEphemeral(templates, adapters)
+	e.SetState(state)
+
+	s, err := e.BuildSnapshot()
+	result := &ValidationResult{Snapshot: s}
+	if merr, ok := err.(*multierror.Error); ok {
+		result.Errors = append(result.Errors, merr.Errors...)
+	} else if err != nil {
+		result.Errors = append(result.Errors, err)
+	}
+
+	result.Errors = append(result.Errors, validateCompiledHandlers(adapters, state)...)
+
+	grouped := GetInstancesGroupedByHandlers(s)
+	groupedDynamic := GetInstancesGroupedByHandlersDynamic(s)
+
+	used := make(map[string]bool)
+	for _, instances := range grouped {
+		for _, i := range instances {
+			used[i.Name] = true
+		}
+	}
+	for _, instances := range groupedDynamic {
+		for _, i := range instances {
+			used[i.Name] = true
+		}
+	}
+
+	for _, h := range s.HandlersStatic {
+		instances, found := grouped[h]
+		if !found {
+			result.UnusedHandlers = append(result.UnusedHandlers, h.Name)
+		}
+		if _, err := ValidateBuilder(h, instances, templates); err != nil {
+			result.Errors = append(result.Errors, adapter.ConfigError{Field: fmt.Sprintf("handler='%s'", h.Name), Underlying: err})
+		}
+	}
+	for _, h := range s.HandlersDynamic {
+		if _, found := groupedDynamic[h]; !found {
+			result.UnusedHandlers = append(result.UnusedHandlers, h.Name)
+		}
+	}
+
+	for _, i := range s.InstancesStatic {
+		if !used[i.Name] {
+			result.UnusedInstances = append(result.UnusedInstances, i.Name)
+		}
+	}
+	for _, i := range s.InstancesDynamic {
+		if !used[i.Name] {
+			result.UnusedInstances = append(result.UnusedInstances, i.Name)
+		}
+	}
+
+	sort.Slice(result.Errors, func(i, j int) bool { return result.Errors[i].Error() < result.Errors[j].Error() })
+	sort.Strings(result.UnusedHandlers)
+	sort.Strings(result.UnusedInstances)
+
+	return result
+}
+
+// validateCompiledHandlers reports the handler resources that refer to unknown compiled adapters or carry params
+// the adapter cannot accept. Both are tolerated by the runtime, which ignores such handlers or falls back to the
+// adapter's default configuration.
+func validateCompiledHandlers(adapters map[string]*adapter.Info, state map[store.Key]*store.Resource) []error {
+	var errs []error
+	for key, resource := range state {
+		if key.Kind != constant.HandlerKind {
+			continue
+		}
+		hdl := resource.Spec.(*config.Handler)
+		if hdl.CompiledAdapter == "" {
+			continue
+		}
+
+		field := fmt.Sprintf("handler='%s'", key.Name+"."+key.Namespace)
+		a, found := adapters[hdl.CompiledAdapter]
+		if !found {
+			errs = append(errs, adapter.ConfigError{Field: field + ".compiledAdapter",
+				Underlying: fmt.Errorf("adapter '%s' not found", hdl.CompiledAdapter)})
+			continue
+		}
+		if hdl.Params == nil {
+			continue
+		}
+
+		dict, err := toDictionary(hdl.Params)
+		if err == nil {
+			err = convert(dict, proto.Clone(a.DefaultConfig))
+		}
+		if err != nil {
+			errs = append(errs, adapter.ConfigError{Field: field + ".params", Underlying: err})
+		}
+	}
+	return errs
+}
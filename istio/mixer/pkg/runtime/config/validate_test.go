@@ -0,0 +1,116 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"istio.io/istio/mixer/pkg/config/storetest"
+	"istio.io/istio/mixer/pkg/runtime/testing/data"
+)
+
+var handlerUnknownCompiledAdapter = `
+apiVersion: "config.istio.io/v1alpha2"
+kind: handler
+metadata:
+  name: hmissing
+  namespace: istio-system
+spec:
+  compiledAdapter: inspector-gadget
+`
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name            string
+		cfg             string
+		adapterSettings []data.FakeAdapterSettings
+		errors          []string
+		unusedHandlers  []string
+		unusedInstances []string
+	}{
+		{
+			name: "valid",
+			cfg:  data.JoinConfigs(data.HandlerACheck1, data.InstanceCheck1, data.RuleCheck1),
+		},
+		{
+			name:            "unused",
+			cfg:             data.JoinConfigs(data.HandlerACheck1, data.HandlerACheck2, data.InstanceCheck1, data.InstanceCheck2, data.RuleCheck1),
+			unusedHandlers:  []string{"hcheck2.acheck.istio-system"},
+			unusedInstances: []string{"icheck2.tcheck.istio-system"},
+		},
+		{
+			name:            "unresolved handler",
+			cfg:             data.JoinConfigs(data.HandlerACheck1, data.InstanceCheck1, data.RuleCheck1WithBadHandler),
+			errors:          []string{"Handler not found: handler='hcheck1.inspector-gadget'", "No valid actions found in rule"},
+			unusedHandlers:  []string{"hcheck1.acheck.istio-system"},
+			unusedInstances: []string{"icheck1.tcheck.istio-system"},
+		},
+		{
+			name:   "bad condition",
+			cfg:    data.JoinConfigs(data.HandlerACheck1, data.InstanceCheck1, data.RuleCheck1WithNonBooleanCondition),
+			errors: []string{"rule='rcheck1.rule.istio-system'.Match"},
+		},
+		{
+			name:            "rejected by adapter",
+			cfg:             data.JoinConfigs(data.HandlerACheck1, data.InstanceCheck1, data.RuleCheck1),
+			adapterSettings: []data.FakeAdapterSettings{{Name: "acheck", ErrorAtValidate: true}},
+			errors:          []string{"handler='hcheck1.acheck.istio-system'", "some validation error"},
+		},
+		{
+			name:   "unknown compiled adapter",
+			cfg:    data.JoinConfigs(data.HandlerACheck1, data.InstanceCheck1, data.RuleCheck1, handlerUnknownCompiledAdapter),
+			errors: []string{"handler='hmissing.istio-system'.compiledAdapter"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			templates := data.BuildTemplates(nil)
+			adapters := data.BuildAdapters(nil, c.adapterSettings...)
+
+			s, _ := storetest.SetupStoreForTest(data.ServiceConfig, c.cfg)
+			if err := s.Init(KindMap(adapters, templates)); err != nil {
+				t.Fatal(err)
+			}
+			state := s.List()
+			s.Stop()
+
+			result := Validate(templates, adapters, state)
+
+			var msgs []string
+			for _, err := range result.Errors {
+				msgs = append(msgs, err.Error())
+			}
+			all := strings.Join(msgs, "\n")
+			if len(c.errors) == 0 && len(msgs) != 0 {
+				t.Errorf("Got errors:\n%s\nwant none", all)
+			}
+			for _, e := range c.errors {
+				if !strings.Contains(all, e) {
+					t.Errorf("Got errors:\n%s\nwant %q", all, e)
+				}
+			}
+
+			if !reflect.DeepEqual(result.UnusedHandlers, c.unusedHandlers) {
+				t.Errorf("Got unused handlers %v, want %v", result.UnusedHandlers, c.unusedHandlers)
+			}
+			if !reflect.DeepEqual(result.UnusedInstances, c.unusedInstances) {
+				t.Errorf("Got unused instances %v, want %v", result.UnusedInstances, c.unusedInstances)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"path/filepath"
 	"time"
 
@@ -34,7 +35,13 @@ const (
 
 // newEnvoy creates a new Envoy struct and starts envoy.
 func (s *TestSetup) newEnvoy() (envoy.Instance, error) {
-	confPath := filepath.Join(env.IstioOut, fmt.Sprintf("config.conf.%v.yaml", s.ports.AdminPort))
+	// The config is written to the temporary directory rather than the working directory, i.e. the package of
+	// the test, when the output directory cannot be resolved.
+	outDir := env.IstioOut
+	if outDir == "" {
+		outDir = os.TempDir()
+	}
+	confPath := filepath.Join(outDir, fmt.Sprintf("config.conf.%v.yaml", s.ports.AdminPort))
 	log.Printf("Envoy config: in %v\n", confPath)
 	if err := s.CreateEnvoyConf(confPath); err != nil {
 		return nil, err
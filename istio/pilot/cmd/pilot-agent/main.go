@@ -38,6 +38,7 @@ import (
 	"istio.io/pkg/log"
 	"istio.io/pkg/version"
 
+	"istio.io/istio/pilot/cmd/pilot-agent/outlier"
 	"istio.io/istio/pilot/cmd/pilot-agent/status"
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
@@ -98,6 +99,8 @@ var (
 	tlsCertsToWatch          []string
 	loggingOptions           = log.DefaultOptions()
 	outlierLogPath           string
+	outlierReportAddress     string
+	outlierReportInterval    time.Duration
 
 	wg sync.WaitGroup
 
@@ -479,6 +482,26 @@ var (
 
 			log.Infof("PilotSAN %#v", pilotSAN)
 
+			if outlierReportAddress != "" {
+				localHostAddr := "127.0.0.1"
+				if proxyIPv6 {
+					localHostAddr = "[::1]"
+				}
+				reporter := outlier.NewReporter(outlier.Config{
+					LogPath:       outlierLogPath,
+					LocalHostAddr: localHostAddr,
+					AdminPort:     proxyAdminPort,
+					PilotAddress:  outlierReportAddress,
+					CertChainFile: tlsClientCertChain,
+					KeyFile:       tlsClientKey,
+					RootCertFile:  tlsClientRootCert,
+					PilotSAN:      pilotSAN,
+					Proxy:         role.ID,
+					Interval:      outlierReportInterval,
+				})
+				go waitForCompletion(ctx, reporter.Run)
+			}
+
 			envoyProxy := envoy.NewProxy(envoy.ProxyConfig{
 				Config:              proxyConfig,
 				Node:                role.ServiceNode(),
@@ -744,6 +767,12 @@ func init() {
 		"Process bootstrap provided via templateFile to be used by control plane components.")
 	proxyCmd.PersistentFlags().StringVar(&outlierLogPath, "outlierLogPath", "",
 		"The log path for outlier detection")
+	proxyCmd.PersistentFlags().StringVar(&outlierReportAddress, "outlierReportAddress", "",
+		"The host:port of the secure port of Pilot receiving the outlier ejections and circuit breaker overflows of the proxy "+
+			"over mutual TLS with the client certificate of the proxy, e.g. istio-pilot.istio-system:15011. "+
+			"Leaving empty disables the reports")
+	proxyCmd.PersistentFlags().DurationVar(&outlierReportInterval, "outlierReportInterval", 30*time.Second,
+		"The interval between the outlier reports")
 
 	// Attach the Istio logging options to the command.
 	loggingOptions.AttachCobraFlags(rootCmd)
@@ -0,0 +1,324 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package outlier reports the outlier ejections and the circuit breaker overflows of Envoy to Pilot.
+package outlier
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"istio.io/pkg/log"
+
+	"istio.io/istio/pilot/cmd/pilot-agent/status/util"
+	"istio.io/istio/pilot/pkg/model"
+)
+
+const (
+	// ReportPath is the path of the outlier reports on the secure port of Pilot.
+	ReportPath = "/outlier/report"
+
+	// DefaultInterval is the interval between the reports when none is configured.
+	DefaultInterval = 30 * time.Second
+
+	requestTimeout = 5 * time.Second
+
+	actionEject   = "EJECT"
+	actionUneject = "UNEJECT"
+)
+
+// Config for the outlier reporter.
+type Config struct {
+	// LogPath is the outlier detection event log of Envoy. Only the overflows are reported if empty.
+	LogPath string
+
+	// LocalHostAddr and AdminPort are the address of the admin API of Envoy.
+	LocalHostAddr string
+	AdminPort     uint16
+
+	// PilotAddress is the host:port of the secure port of Pilot receiving the reports over mutual TLS.
+	PilotAddress string
+
+	// CertChainFile and KeyFile are the workload certificate authenticating the proxy to Pilot, and
+	// RootCertFile is the root certificate verifying the one of Pilot. The files are read for each report,
+	// so that the rotated certificates are used.
+	CertChainFile string
+	KeyFile       string
+	RootCertFile  string
+
+	// PilotSAN are the identities accepted for Pilot. Any certificate signed by the root certificate is
+	// accepted if empty.
+	PilotSAN []string
+
+	// Proxy is the ID of the proxy.
+	Proxy string
+
+	// Interval is the interval between the reports. Defaults to DefaultInterval.
+	Interval time.Duration
+}
+
+// event is an outlier detection event of the event log of Envoy, an envoy.data.cluster.v2alpha.OutlierDetectionEvent
+// in JSON.
+type event struct {
+	Type        string    `json:"type"`
+	Timestamp   time.Time `json:"timestamp"`
+	ClusterName string    `json:"cluster_name"`
+	UpstreamURL string    `json:"upstream_url"`
+	Action      string    `json:"action"`
+	Enforced    bool      `json:"enforced"`
+}
+
+// Reporter reports the outlier ejections and the circuit breaker overflows of the clusters of Envoy.
+type Reporter struct {
+	cfg    Config
+	client *http.Client
+
+	// offset is the offset of the next event in the event log.
+	offset int64
+	// clusters is the state of the clusters built from the events, by cluster name.
+	clusters map[string]*clusterState
+}
+
+type clusterState struct {
+	ejected      map[string]bool
+	ejections    uint64
+	lastEjection time.Time
+}
+
+// NewReporter creates a reporter.
+func NewReporter(cfg Config) *Reporter {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	r := &Reporter{
+		cfg:      cfg,
+		clusters: map[string]*clusterState{},
+	}
+	r.client = &http.Client{
+		Timeout: requestTimeout,
+		Transport: &http.Transport{
+			// a new connection is made for each report, with the current certificates
+			DisableKeepAlives: true,
+			TLSClientConfig: &tls.Config{
+				GetClientCertificate: r.getClientCertificate,
+				// the certificate of Pilot has a SPIFFE identity rather than the host name of the address, it is
+				// verified by verifyPilotCertificate instead
+				InsecureSkipVerify:    true, // nolint: gosec
+				VerifyPeerCertificate: r.verifyPilotCertificate,
+			},
+		},
+	}
+	return r
+}
+
+func (r *Reporter) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertChainFile, r.cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the client certificate: %v", err)
+	}
+	return &cert, nil
+}
+
+// verifyPilotCertificate verifies the certificate chain of Pilot with the root certificate, and its identity.
+func (r *Reporter) verifyPilotCertificate(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if len(rawCerts) == 0 {
+		return errors.New("no certificate from Pilot")
+	}
+	rootCert, err := ioutil.ReadFile(r.cfg.RootCertFile)
+	if err != nil {
+		return fmt.Errorf("failed to read the root certificate: %v", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(rootCert) {
+		return fmt.Errorf("invalid root certificate %s", r.cfg.RootCertFile)
+	}
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("invalid certificate from Pilot: %v", err)
+		}
+		certs = append(certs, cert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates}); err != nil {
+		return fmt.Errorf("failed to verify the certificate of Pilot: %v", err)
+	}
+
+	if len(r.cfg.PilotSAN) == 0 {
+		return nil
+	}
+	for _, uri := range certs[0].URIs {
+		for _, san := range r.cfg.PilotSAN {
+			if uri.String() == san {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("the certificate of Pilot does not have any of the identities %v", r.cfg.PilotSAN)
+}
+
+// Run reports the state at the interval until the context is done.
+func (r *Reporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.report(); err != nil {
+				log.Debugf("failed to report the outlier detection state: %v", err)
+			}
+		}
+	}
+}
+
+func (r *Reporter) report() error {
+	if err := r.readEvents(); err != nil {
+		log.Debugf("failed to read the outlier detection events: %v", err)
+	}
+	overflows, err := util.GetClusterOverflows(r.cfg.LocalHostAddr, r.cfg.AdminPort)
+	if err != nil {
+		return fmt.Errorf("failed to get the circuit breaker overflows: %v", err)
+	}
+
+	body, err := json.Marshal(r.buildReport(overflows))
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Post(fmt.Sprintf("https://%s%s", r.cfg.PilotAddress, ReportPath), "application/json",
+		bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// buildReport returns the report of the clusters with ejections or overflows.
+func (r *Reporter) buildReport(overflows map[string]*util.ClusterOverflows) *model.OutlierReport {
+	clusters := map[string]*model.ClusterOutlierStatus{}
+	for name, state := range r.clusters {
+		c := &model.ClusterOutlierStatus{
+			Cluster:      name,
+			Ejections:    state.ejections,
+			LastEjection: state.lastEjection,
+		}
+		for ep := range state.ejected {
+			c.EjectedEndpoints = append(c.EjectedEndpoints, ep)
+		}
+		sort.Strings(c.EjectedEndpoints)
+		clusters[name] = c
+	}
+	for name, o := range overflows {
+		if o.Connections == 0 && o.PendingRequests == 0 && o.Retries == 0 {
+			continue
+		}
+		c, ok := clusters[name]
+		if !ok {
+			c = &model.ClusterOutlierStatus{Cluster: name}
+			clusters[name] = c
+		}
+		c.ConnectionOverflows = o.Connections
+		c.PendingRequestOverflows = o.PendingRequests
+		c.RetryOverflows = o.Retries
+	}
+
+	report := &model.OutlierReport{Proxy: r.cfg.Proxy, Clusters: make([]*model.ClusterOutlierStatus, 0, len(clusters))}
+	for _, c := range clusters {
+		report.Clusters = append(report.Clusters, c)
+	}
+	sort.Slice(report.Clusters, func(i, j int) bool { return report.Clusters[i].Cluster < report.Clusters[j].Cluster })
+	return report
+}
+
+// readEvents reads the events appended to the event log since the last read. The log is read from the start
+// when it is truncated.
+func (r *Reporter) readEvents() error {
+	if r.cfg.LogPath == "" {
+		return nil
+	}
+	f, err := os.Open(r.cfg.LogPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < r.offset {
+		r.offset = 0
+	}
+	if _, err := f.Seek(r.offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// the last line is not complete yet
+			return nil
+		}
+		r.offset += int64(len(line))
+		e := &event{}
+		if err := json.Unmarshal(line, e); err != nil {
+			log.Debugf("invalid outlier detection event %q: %v", string(line), err)
+			continue
+		}
+		r.record(e)
+	}
+}
+
+func (r *Reporter) record(e *event) {
+	state, ok := r.clusters[e.ClusterName]
+	if !ok {
+		state = &clusterState{ejected: map[string]bool{}}
+		r.clusters[e.ClusterName] = state
+	}
+	switch e.Action {
+	case actionEject:
+		// the ejections which are not enforced are only logged by Envoy
+		if e.Enforced {
+			state.ejected[e.UpstreamURL] = true
+			state.ejections++
+			state.lastEjection = e.Timestamp
+		}
+	case actionUneject:
+		delete(state.ejected, e.UpstreamURL)
+	}
+}
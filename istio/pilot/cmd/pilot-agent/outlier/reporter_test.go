@@ -0,0 +1,293 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outlier
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+const (
+	reviews = "outbound|9080|v1|reviews.default.svc.cluster.local"
+	ratings = "outbound|9080||ratings.default.svc.cluster.local"
+
+	overflowStats = "cluster." + reviews + ".upstream_cx_overflow: 0\n" +
+		"cluster." + reviews + ".upstream_rq_pending_overflow: 0\n" +
+		"cluster." + ratings + ".upstream_cx_overflow: 1\n" +
+		"cluster." + ratings + ".upstream_rq_pending_overflow: 2\n" +
+		"cluster." + ratings + ".upstream_rq_retry_overflow: 3\n"
+)
+
+func outlierEvent(action, endpoint string, enforced bool) string {
+	return `{"type":"CONSECUTIVE_5XX","timestamp":"2019-11-05T10:00:00Z","cluster_name":"` + reviews +
+		`","upstream_url":"` + endpoint + `","action":"` + action + `","num_ejections":1,"enforced":` +
+		strconv.FormatBool(enforced) + `,"eject_consecutive_event":{}}` + "\n"
+}
+
+func cluster(t *testing.T, report *model.OutlierReport, name string) *model.ClusterOutlierStatus {
+	t.Helper()
+	for _, c := range report.Clusters {
+		if c.Cluster == name {
+			return c
+		}
+	}
+	t.Fatalf("cluster %s not found in %v", name, report.Clusters)
+	return nil
+}
+
+const (
+	pilotSAN    = "spiffe://cluster.local/ns/istio-system/sa/istio-pilot-service-account"
+	workloadSAN = "spiffe://cluster.local/ns/default/sa/productpage"
+)
+
+// genCert writes a certificate and its key, signed by the signer or self-signed if it is nil, to the directory.
+func genCert(t *testing.T, dir, name, host string, signerCert, signerKey []byte) (certFile, keyFile string) {
+	t.Helper()
+	options := util.CertOptions{
+		Host:         host,
+		TTL:          time.Hour,
+		KeyAlgorithm: util.ECDSAP256,
+		IsServer:     true,
+		IsClient:     true,
+	}
+	if signerCert == nil {
+		options.IsCA = true
+		options.IsSelfSigned = true
+	} else {
+		cert, err := util.ParsePemEncodedCertificate(signerCert)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := util.ParsePemEncodedKey(signerKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		options.SignerCert, options.SignerPriv = cert, key
+	}
+	cert, key, err := util.GenCertKeyFromOptions(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, name+"-cert.pem"), filepath.Join(dir, name+"-key.pem")
+	if err := ioutil.WriteFile(certFile, cert, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, key, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+// certs writes a root certificate, and the certificates of Pilot and of a workload signed by it. It returns
+// the root certificate file and the certificate of Pilot.
+func certs(t *testing.T, dir string) (string, tls.Certificate) {
+	t.Helper()
+	rootFile, rootKeyFile := genCert(t, dir, "root", "cluster.local", nil, nil)
+	rootCert, _ := ioutil.ReadFile(rootFile)
+	rootKey, _ := ioutil.ReadFile(rootKeyFile)
+	load := func(certFile, keyFile string) tls.Certificate {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert
+	}
+	pilot := load(genCert(t, dir, "pilot", pilotSAN, rootCert, rootKey))
+	genCert(t, dir, "workload", workloadSAN, rootCert, rootKey)
+	return rootFile, pilot
+}
+
+// newPilot starts a server requiring mutual TLS with the certificates of the root.
+func newPilot(t *testing.T, rootFile string, cert tls.Certificate, handler http.Handler) *httptest.Server {
+	t.Helper()
+	rootCert, err := ioutil.ReadFile(rootFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(rootCert)
+	pilot := httptest.NewUnstartedServer(handler)
+	pilot.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    roots,
+	}
+	pilot.StartTLS()
+	return pilot
+}
+
+func TestReporterTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outlier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	rootFile, pilotCert := certs(t, dir)
+
+	admin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(overflowStats))
+	}))
+	defer admin.Close()
+	pilot := newPilot(t, rootFile, pilotCert, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer pilot.Close()
+
+	host, port, _ := net.SplitHostPort(strings.TrimPrefix(admin.URL, "http://"))
+	adminPort, _ := strconv.Atoi(port)
+	cases := []struct {
+		name     string
+		cert     string
+		pilotSAN []string
+		wantErr  bool
+	}{
+		{name: "workload certificate", cert: "workload", pilotSAN: []string{pilotSAN}},
+		{name: "any identity of pilot", cert: "workload"},
+		{name: "other identity of pilot", cert: "workload", pilotSAN: []string{workloadSAN}, wantErr: true},
+		{name: "no client certificate", cert: "missing", pilotSAN: []string{pilotSAN}, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewReporter(Config{
+				LocalHostAddr: host,
+				AdminPort:     uint16(adminPort),
+				PilotAddress:  strings.TrimPrefix(pilot.URL, "https://"),
+				CertChainFile: filepath.Join(dir, tc.cert+"-cert.pem"),
+				KeyFile:       filepath.Join(dir, tc.cert+"-key.pem"),
+				RootCertFile:  rootFile,
+				PilotSAN:      tc.pilotSAN,
+				Proxy:         "productpage.default",
+			})
+			if err := r.report(); (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestReporter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outlier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	logPath := filepath.Join(dir, "outlier.log")
+
+	admin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(overflowStats))
+	}))
+	defer admin.Close()
+	reports := make(chan *model.OutlierReport, 10)
+	rootFile, pilotCert := certs(t, dir)
+	pilot := newPilot(t, rootFile, pilotCert, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := &model.OutlierReport{}
+		if r.URL.Path != ReportPath || json.NewDecoder(r.Body).Decode(report) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		reports <- report
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer pilot.Close()
+
+	host, port, _ := net.SplitHostPort(strings.TrimPrefix(admin.URL, "http://"))
+	adminPort, _ := strconv.Atoi(port)
+	r := NewReporter(Config{
+		LogPath:       logPath,
+		LocalHostAddr: host,
+		AdminPort:     uint16(adminPort),
+		PilotAddress:  strings.TrimPrefix(pilot.URL, "https://"),
+		CertChainFile: filepath.Join(dir, "workload-cert.pem"),
+		KeyFile:       filepath.Join(dir, "workload-key.pem"),
+		RootCertFile:  rootFile,
+		PilotSAN:      []string{pilotSAN},
+		Proxy:         "productpage.default",
+	})
+	report := func() *model.OutlierReport {
+		t.Helper()
+		if err := r.report(); err != nil {
+			t.Fatal(err)
+		}
+		return <-reports
+	}
+
+	// no event log yet
+	got := report()
+	if len(got.Clusters) != 1 || got.Clusters[0].Cluster != ratings {
+		t.Fatalf("got clusters %v, want the overflows of %s", got.Clusters, ratings)
+	}
+	wantRatings := &model.ClusterOutlierStatus{
+		Cluster:                 ratings,
+		ConnectionOverflows:     1,
+		PendingRequestOverflows: 2,
+		RetryOverflows:          3,
+	}
+	if !reflect.DeepEqual(got.Clusters[0], wantRatings) {
+		t.Errorf("got %+v, want %+v", got.Clusters[0], wantRatings)
+	}
+
+	// the incomplete last line is read by the next report
+	lastEvent := outlierEvent("EJECT", "10.0.0.3:9080", true)
+	events := outlierEvent("EJECT", "10.0.0.1:9080", true) +
+		outlierEvent("EJECT", "10.0.0.2:9080", true) +
+		outlierEvent("EJECT", "10.0.0.4:9080", false) +
+		outlierEvent("UNEJECT", "10.0.0.1:9080", true) +
+		"not an event\n" +
+		lastEvent[:10]
+	if err := ioutil.WriteFile(logPath, []byte(events), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got = report()
+	if len(got.Clusters) != 2 {
+		t.Fatalf("got %d clusters, want 2", len(got.Clusters))
+	}
+	if c := cluster(t, got, reviews); !reflect.DeepEqual(c.EjectedEndpoints, []string{"10.0.0.2:9080"}) || c.Ejections != 2 ||
+		!c.LastEjection.Equal(time.Date(2019, 11, 5, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected reviews status %+v", c)
+	}
+
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(lastEvent[10:])
+	_ = f.Close()
+	if c := cluster(t, report(), reviews); !reflect.DeepEqual(c.EjectedEndpoints, []string{"10.0.0.2:9080", "10.0.0.3:9080"}) || c.Ejections != 3 {
+		t.Errorf("unexpected reviews status %+v", c)
+	}
+
+	// a truncated log is read from the start
+	if err := ioutil.WriteFile(logPath, []byte(outlierEvent("UNEJECT", "10.0.0.2:9080", true)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if c := cluster(t, report(), reviews); !reflect.DeepEqual(c.EjectedEndpoints, []string{"10.0.0.3:9080"}) {
+		t.Errorf("unexpected reviews status %+v", c)
+	}
+}
@@ -17,6 +17,7 @@ package util
 import (
 	"bytes"
 	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -30,6 +31,11 @@ const (
 	statsLdsSuccess  = "listener_manager.lds.update_success"
 	statServerState  = "server.state"
 	updateStatsRegex = "^(cluster_manager.cds|listener_manager.lds).(update_success|update_rejected)$"
+
+	statConnectionOverflow     = "upstream_cx_overflow"
+	statPendingRequestOverflow = "upstream_rq_pending_overflow"
+	statRetryOverflow          = "upstream_rq_retry_overflow"
+	overflowStatsRegex         = "^cluster\\..*\\.(upstream_cx_overflow|upstream_rq_pending_overflow|upstream_rq_retry_overflow)$"
 )
 
 type stat struct {
@@ -110,6 +116,69 @@ func GetUpdateStatusStats(localHostAddr string, adminPort uint16) (*Stats, error
 	return s, nil
 }
 
+// ClusterOverflows are the circuit breaker overflow counters of an Envoy cluster.
+type ClusterOverflows struct {
+	Connections     uint64
+	PendingRequests uint64
+	Retries         uint64
+}
+
+// GetClusterOverflows returns the circuit breaker overflow counters of the clusters, by cluster name.
+func GetClusterOverflows(localHostAddr string, adminPort uint16) (map[string]*ClusterOverflows, error) {
+	// If the localHostAddr was not set, we use 'localhost' to void emppty host in URL.
+	if localHostAddr == "" {
+		localHostAddr = "localhost"
+	}
+
+	stats, err := doHTTPGet(fmt.Sprintf("http://%s:%d/stats?usedonly&filter=%s", localHostAddr, adminPort,
+		url.QueryEscape(overflowStatsRegex)))
+	if err != nil {
+		return nil, err
+	}
+	return parseClusterOverflows(stats)
+}
+
+// parseClusterOverflows parses the lines of the form cluster.<cluster name>.<stat>: <value>. The cluster names
+// contain dots, the name of the stat is the last part.
+func parseClusterOverflows(input *bytes.Buffer) (map[string]*ClusterOverflows, error) {
+	out := map[string]*ClusterOverflows{}
+	for input.Len() > 0 {
+		line, _ := input.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		sep := strings.LastIndex(line, ":")
+		if sep < 0 {
+			return nil, fmt.Errorf("envoy stat missing separator. line:%s", line)
+		}
+		name := strings.TrimPrefix(line[:sep], "cluster.")
+		val, err := strconv.ParseUint(strings.TrimSpace(line[sep+1:]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing Envoy stat %s (error: %s) line: %s", name, err.Error(), line)
+		}
+		dot := strings.LastIndex(name, ".")
+		if dot < 0 {
+			continue
+		}
+		cluster, stat := name[:dot], name[dot+1:]
+		overflows, ok := out[cluster]
+		if !ok {
+			overflows = &ClusterOverflows{}
+			out[cluster] = overflows
+		}
+		switch stat {
+		case statConnectionOverflow:
+			overflows.Connections = val
+		case statPendingRequestOverflow:
+			overflows.PendingRequests = val
+		case statRetryOverflow:
+			overflows.Retries = val
+		}
+	}
+	return out, nil
+}
+
 func parseStats(input *bytes.Buffer, stats []*stat) (err error) {
 	for input.Len() > 0 {
 		line, _ := input.ReadString('\n')
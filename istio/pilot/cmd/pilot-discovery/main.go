@@ -117,6 +117,12 @@ func init() {
 		fmt.Sprintf("File name for Istio mesh configuration. If not specified, a default mesh will be used."))
 	discoveryCmd.PersistentFlags().StringVar(&serverArgs.NetworksConfigFile, "networksConfig", "/etc/istio/config/meshNetworks",
 		fmt.Sprintf("File name for Istio mesh networks configuration. If not specified, a default mesh networks will be used."))
+	discoveryCmd.PersistentFlags().StringVar(&serverArgs.ExternalAuthorizersFile, "externalAuthorizersConfig",
+		"/etc/istio/config/externalAuthorizers",
+		"File name for the external authorizers configuration. If the file does not exist, no external authorizer is configured.")
+	discoveryCmd.PersistentFlags().StringVar(&serverArgs.RateLimitsFile, "rateLimitsConfig", "/etc/istio/config/rateLimits",
+		"File name for the rate limits configuration, reloaded when it changes. If the file does not exist, no rate "+
+			"limit is configured. The local rate limits limit the connections accepted by the proxies, not the requests.")
 	discoveryCmd.PersistentFlags().StringVarP(&serverArgs.Namespace, "namespace", "n", "",
 		"Select a namespace where the controller resides. If not set, uses ${POD_NAMESPACE} environment variable")
 	discoveryCmd.PersistentFlags().StringSliceVar(&serverArgs.Plugins, "plugins", bootstrap.DefaultPlugins,
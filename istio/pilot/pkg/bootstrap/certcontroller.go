@@ -22,7 +22,9 @@ import (
 	"strings"
 	"time"
 
+	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/security/pkg/k8s/chiron"
+	"istio.io/istio/security/pkg/pki/util"
 	"istio.io/pkg/log"
 )
 
@@ -72,11 +74,16 @@ func (s *Server) initCertController(args *PilotArgs) error {
 		}
 	}
 
+	keyAlgorithm, err := util.ParseKeyAlgorithm(features.WorkloadKeyAlgorithm.Get())
+	if err != nil {
+		return err
+	}
+
 	// Provision and manage the certificates for non-Pilot services.
 	// If services are empty, the certificate controller will do nothing.
 	s.certController, err = chiron.NewWebhookController(defaultCertGracePeriodRatio, defaultMinCertGracePeriod,
 		k8sClient.CoreV1(), k8sClient.AdmissionregistrationV1beta1(), k8sClient.CertificatesV1beta1(),
-		defaultCACertPath, secretNames, dnsNames, namespaces)
+		defaultCACertPath, secretNames, dnsNames, namespaces, keyAlgorithm)
 	if err != nil {
 		return fmt.Errorf("failed to create certificate controller: %v", err)
 	}
@@ -131,9 +138,14 @@ func (s *Server) initDNSCerts(hostname string) error {
 
 	log.Infoa("Generating K8S-signed cert for ", names)
 
+	keyAlgorithm, err := util.ParseKeyAlgorithm(features.WorkloadKeyAlgorithm.Get())
+	if err != nil {
+		return err
+	}
+
 	// TODO: fallback to citadel (or custom CA) if K8S signing is broken
 	certChain, keyPEM, _, err := chiron.GenKeyCertK8sCA(s.kubeClient.CertificatesV1beta1().CertificateSigningRequests(),
-		strings.Join(names, ","), parts[0]+".csr.secret", parts[1], defaultCACertPath)
+		strings.Join(names, ","), parts[0]+".csr.secret", parts[1], defaultCACertPath, keyAlgorithm)
 	if err != nil {
 		return err
 	}
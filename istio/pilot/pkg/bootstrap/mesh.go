@@ -16,6 +16,7 @@ package bootstrap
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/davecgh/go-spew/spew"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -94,6 +95,37 @@ func (s *Server) initMeshNetworks(args *PilotArgs, fileWatcher filewatcher.FileW
 	}
 }
 
+// initExternalAuthorizers loads the external authorizers configuration from the file provided
+// in the args and add a watcher for changes in this file.
+func (s *Server) initExternalAuthorizers(args *PilotArgs, fileWatcher filewatcher.FileWatcher) {
+	if args.ExternalAuthorizersFile == "" {
+		s.environment.ExternalAuthorizersWatcher = mesh.NewFixedExternalAuthorizersWatcher(nil)
+		return
+	}
+	if _, err := os.Stat(args.ExternalAuthorizersFile); os.IsNotExist(err) {
+		log.Info("external authorizers configuration not provided")
+		s.environment.ExternalAuthorizersWatcher = mesh.NewFixedExternalAuthorizersWatcher(nil)
+		return
+	}
+	s.environment.ExternalAuthorizersWatcher = mesh.NewExternalAuthorizersWatcher(fileWatcher,
+		args.ExternalAuthorizersFile)
+}
+
+// initRateLimits loads the rate limits configuration from the file provided
+// in the args and add a watcher for changes in this file.
+func (s *Server) initRateLimits(args *PilotArgs, fileWatcher filewatcher.FileWatcher) {
+	if args.RateLimitsFile == "" {
+		s.environment.RateLimitsWatcher = mesh.NewFixedRateLimitsWatcher(nil)
+		return
+	}
+	if _, err := os.Stat(args.RateLimitsFile); os.IsNotExist(err) {
+		log.Info("rate limits configuration not provided")
+		s.environment.RateLimitsWatcher = mesh.NewFixedRateLimitsWatcher(nil)
+		return
+	}
+	s.environment.RateLimitsWatcher = mesh.NewRateLimitsWatcher(fileWatcher, args.RateLimitsFile)
+}
+
 // getMeshConfig fetches the ProxyMesh configuration from Kubernetes ConfigMap.
 // Deprecated - does not watch !
 func getMeshConfig(kube kubernetes.Interface, namespace, name string) (*meshconfig.MeshConfig, error) {
@@ -74,6 +74,8 @@ type PilotArgs struct {
 	Service                  ServiceArgs
 	MeshConfig               *meshconfig.MeshConfig
 	NetworksConfigFile       string
+	ExternalAuthorizersFile  string
+	RateLimitsFile           string
 	CtrlZOptions             *ctrlz.Options
 	Plugins                  []string
 	MCPMaxMessageSize        int
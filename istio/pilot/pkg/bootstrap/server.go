@@ -45,6 +45,7 @@ import (
 	"istio.io/pkg/log"
 	"istio.io/pkg/version"
 
+	"istio.io/istio/pilot/pkg/config/kube/secret"
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/networking/plugin"
@@ -74,6 +75,7 @@ var (
 	DefaultPlugins = []string{
 		plugin.Authn,
 		plugin.Authz,
+		plugin.RateLimit,
 		plugin.Health,
 		plugin.Mixer,
 	}
@@ -120,6 +122,7 @@ type Server struct {
 	kubeRegistry        *kubecontroller.Controller
 	sseDiscovery        *serviceentry.Discovery
 	certController      *chiron.WebhookController
+	gatewayCertificates *secret.Controller
 
 	ConfigStores []model.ConfigStoreCache
 
@@ -167,6 +170,9 @@ func NewServer(args *PilotArgs) (*Server, error) {
 		return nil, fmt.Errorf("mesh: %v", err)
 	}
 	s.initMeshNetworks(args, fileWatcher)
+	s.initExternalAuthorizers(args, fileWatcher)
+	s.initRateLimits(args, fileWatcher)
+	s.initGatewayCertificates()
 	// Certificate controller is created before MCP
 	// controller in case MCP server pod waits to mount a certificate
 	// to be provisioned by the certificate controller.
@@ -272,6 +278,20 @@ func (s *Server) initKubeClient(args *PilotArgs) error {
 	return nil
 }
 
+// initGatewayCertificates discovers the certificates of the gateways from the Kubernetes secrets of the
+// namespace set by PILOT_GATEWAY_CERTIFICATE_NAMESPACE.
+func (s *Server) initGatewayCertificates() {
+	if features.GatewayCertificateNamespace == "" || s.kubeClient == nil {
+		return
+	}
+	s.gatewayCertificates = secret.NewController(s.kubeClient.CoreV1(), features.GatewayCertificateNamespace)
+	s.environment.GatewayCertificates = s.gatewayCertificates
+	s.addStartFunc(func(stop <-chan struct{}) error {
+		go s.gatewayCertificates.Run(stop)
+		return nil
+	})
+}
+
 func (s *Server) initDiscoveryService(args *PilotArgs) error {
 	s.mux = http.NewServeMux()
 	s.EnvoyXdsServer.InitDebug(s.mux, s.ServiceController(), args.DiscoveryOptions.EnableProfiling, s.webhook)
@@ -283,6 +303,12 @@ func (s *Server) initDiscoveryService(args *PilotArgs) error {
 	s.environment.AddNetworksHandler(func() {
 		s.EnvoyXdsServer.ConfigUpdate(&model.PushRequest{Full: true})
 	})
+	s.environment.AddExternalAuthorizersHandler(func() {
+		s.EnvoyXdsServer.ConfigUpdate(&model.PushRequest{Full: true})
+	})
+	s.environment.AddRateLimitsHandler(func() {
+		s.EnvoyXdsServer.ConfigUpdate(&model.PushRequest{Full: true})
+	})
 
 	if err := s.initEventHandlers(); err != nil {
 		return err
@@ -624,6 +650,16 @@ func (s *Server) initEventHandlers() error {
 		return fmt.Errorf("append instance handler failed: %v", err)
 	}
 
+	// The gateway listeners have a filter chain for each gateway certificate.
+	if s.gatewayCertificates != nil {
+		s.gatewayCertificates.AppendHandler(func() {
+			s.EnvoyXdsServer.ConfigUpdate(&model.PushRequest{
+				Full:               true,
+				ConfigTypesUpdated: map[string]struct{}{schemas.Gateway.Type: {}},
+			})
+		})
+	}
+
 	// TODO(Nino-k): remove this case once incrementalUpdate is default
 	if s.configController != nil {
 		// TODO: changes should not trigger a full recompute of LDS/RDS/CDS/EDS
@@ -0,0 +1,265 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secret discovers the certificates of the ingress gateways from the Kubernetes secrets
+// of the gateway namespace.
+package secret
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"istio.io/pkg/log"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/labels"
+)
+
+// The keys of the server certificates in the secrets served by the SDS of the gateways, see the SecretFetcher of
+// the node agent: the generic secrets hold the certificate in "cert" and the key in "key", the TLS secrets hold
+// them in "tls.crt" and "tls.key".
+const (
+	genericCertKey = "cert"
+	genericKeyKey  = "key"
+	tlsCertKey     = "tls.crt"
+	tlsKeyKey      = "tls.key"
+
+	// caOnlySecretSuffix is the suffix of the secrets only holding a client CA certificate.
+	caOnlySecretSuffix = "-cacert"
+	// tokenKey is the key of the tokens of the secrets generated by Istio, which are not gateway secrets.
+	tokenKey = "token"
+)
+
+// Controller discovers the gateway certificates from the same Kubernetes secrets the SDS of the gateways of the
+// namespace serves the certificates from. The secrets are stripped of everything but their labels and server
+// certificate before they are cached, Pilot never keeps their private keys. The DNS names of the certificates
+// are parsed once, when their secrets are added or updated.
+type Controller struct {
+	namespace string
+	store     cache.Store
+	informer  cache.Controller
+
+	mu sync.RWMutex
+	// hosts maps the names of the secrets to the DNS names of their certificates
+	hosts    map[string][]string
+	handlers []func()
+}
+
+var _ model.GatewayCertificateDiscovery = &Controller{}
+
+// NewController creates a controller discovering the certificates of the secrets of the namespace.
+func NewController(core corev1.CoreV1Interface, namespace string) *Controller { // nolint:interfacer
+	c := &Controller{
+		namespace: namespace,
+		hosts:     make(map[string][]string),
+	}
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			list, err := core.Secrets(namespace).List(options)
+			if err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				list.Items[i] = *serverCertificateSecret(&list.Items[i])
+			}
+			return list, nil
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			w, err := core.Secrets(namespace).Watch(options)
+			if err != nil {
+				return nil, err
+			}
+			return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+				if secret, ok := event.Object.(*v1.Secret); ok {
+					event.Object = serverCertificateSecret(secret)
+				}
+				return event, true
+			}), nil
+		},
+	}
+	c.store, c.informer = cache.NewInformer(lw, &v1.Secret{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc: c.secretAdded,
+		UpdateFunc: func(_, obj interface{}) {
+			c.secretAdded(obj)
+		},
+		DeleteFunc: c.secretDeleted,
+	})
+	return c
+}
+
+// serverCertificateSecret returns a copy of the secret only keeping its name, labels and server certificate,
+// the latter only if the SDS of the gateways serves it, i.e. the secret is a gateway secret with a certificate
+// and a key.
+func serverCertificateSecret(secret *v1.Secret) *v1.Secret {
+	out := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            secret.Name,
+			Namespace:       secret.Namespace,
+			UID:             secret.UID,
+			ResourceVersion: secret.ResourceVersion,
+			Labels:          secret.Labels,
+		},
+	}
+	name := secret.Name
+	if strings.HasPrefix(name, "istio") || strings.HasPrefix(name, "prometheus") ||
+		strings.HasSuffix(name, caOnlySecretSuffix) || len(secret.Data[tokenKey]) > 0 {
+		return out
+	}
+	cert, key := secret.Data[tlsCertKey], secret.Data[tlsKeyKey]
+	if len(secret.Data[genericCertKey]) > 0 {
+		cert, key = secret.Data[genericCertKey], secret.Data[genericKeyKey]
+	}
+	if len(cert) > 0 && len(key) > 0 {
+		out.Data = map[string][]byte{tlsCertKey: cert}
+	}
+	return out
+}
+
+// AppendHandler adds a handler called when the certificates change: a certificate is added or removed,
+// or its DNS names change.
+func (c *Controller) AppendHandler(f func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers = append(c.handlers, f)
+}
+
+// Run watches the secrets until stop is closed. It returns once the secrets are synced.
+func (c *Controller) Run(stop <-chan struct{}) {
+	go c.informer.Run(stop)
+	cache.WaitForCacheSync(stop, c.informer.HasSynced)
+	log.Infof("gateway certificates of namespace %s synced", c.namespace)
+}
+
+// GatewayCertificates implements model.GatewayCertificateDiscovery.
+func (c *Controller) GatewayCertificates(namespace string, selector labels.Instance) []*model.GatewayCertificate {
+	// the SDS of the gateways of other namespaces cannot serve the secrets
+	if namespace != c.namespace {
+		return nil
+	}
+	secretSelector := k8slabels.SelectorFromSet(k8slabels.Set(selector))
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]*model.GatewayCertificate, 0)
+	for _, obj := range c.store.List() {
+		secret, ok := obj.(*v1.Secret)
+		if !ok || !secretSelector.Matches(k8slabels.Set(secret.Labels)) {
+			continue
+		}
+		if hosts := c.hosts[secret.Name]; len(hosts) > 0 {
+			out = append(out, &model.GatewayCertificate{Name: secret.Name, Hosts: hosts})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+func (c *Controller) secretAdded(obj interface{}) {
+	secret, ok := obj.(*v1.Secret)
+	if !ok {
+		return
+	}
+	var hosts []string
+	// the secrets without server certificate, e.g. the client CA certificates, have no hosts
+	if cert := secret.Data[tlsCertKey]; len(cert) > 0 {
+		var err error
+		if hosts, err = certificateHosts(cert); err != nil {
+			log.Warnf("failed to get the hosts of gateway certificate %s: %v", secret.Name, err)
+		}
+	}
+	if len(hosts) == 0 {
+		c.removeHosts(secret.Name)
+		return
+	}
+
+	c.mu.Lock()
+	changed := !reflect.DeepEqual(c.hosts[secret.Name], hosts)
+	c.hosts[secret.Name] = hosts
+	c.mu.Unlock()
+	if changed {
+		c.notify()
+	}
+}
+
+func (c *Controller) secretDeleted(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if secret, ok := obj.(*v1.Secret); ok {
+		c.removeHosts(secret.Name)
+	}
+}
+
+func (c *Controller) removeHosts(secretName string) {
+	c.mu.Lock()
+	_, exists := c.hosts[secretName]
+	delete(c.hosts, secretName)
+	c.mu.Unlock()
+	if exists {
+		c.notify()
+	}
+}
+
+func (c *Controller) notify() {
+	c.mu.RLock()
+	handlers := c.handlers
+	c.mu.RUnlock()
+	for _, f := range handlers {
+		f()
+	}
+}
+
+// certificateHosts returns the sorted DNS names of the leaf certificate of the PEM encoded chain, or its
+// common name if the certificate does not have any DNS name.
+func certificateHosts(certChain []byte) ([]string, error) {
+	block, _ := pem.Decode(certChain)
+	if block == nil {
+		return nil, errors.New("invalid PEM encoded certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	names := cert.DNSNames
+	if len(names) == 0 && cert.Subject.CommonName != "" {
+		names = []string{cert.Subject.CommonName}
+	}
+
+	seen := make(map[string]bool, len(names))
+	hosts := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if !seen[name] {
+			seen[name] = true
+			hosts = append(hosts, name)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts, nil
+}
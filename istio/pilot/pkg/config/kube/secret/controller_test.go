@@ -0,0 +1,196 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/labels"
+)
+
+func makeCertificate(t *testing.T, commonName string, dnsNames ...string) (cert, key []byte) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+func makeSecret(t *testing.T, name string, secretLabels map[string]string, commonName string, dnsNames ...string) *v1.Secret {
+	cert, key := makeCertificate(t, commonName, dnsNames...)
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "istio-system", Labels: secretLabels},
+		Data:       map[string][]byte{"tls.crt": cert, "tls.key": key},
+	}
+}
+
+func TestController(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	c := NewController(client.CoreV1(), "istio-system")
+	var notified int32
+	c.AppendHandler(func() { atomic.AddInt32(&notified, 1) })
+
+	stop := make(chan struct{})
+	defer close(stop)
+	c.Run(stop)
+
+	tenant := map[string]string{"gateway-certificate": "tenant"}
+	for _, s := range []*v1.Secret{
+		makeSecret(t, "tenant-b", tenant, "", "b.example.com", "B.example.com", "*.b.example.com"),
+		makeSecret(t, "tenant-a", tenant, "a.example.com"),
+		makeSecret(t, "other", map[string]string{"gateway-certificate": "other"}, "", "other.example.com"),
+	} {
+		if _, err := client.CoreV1().Secrets("istio-system").Create(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	selector := labels.Instance{"gateway-certificate": "tenant"}
+	want := []*model.GatewayCertificate{
+		{Name: "tenant-a", Hosts: []string{"a.example.com"}},
+		{Name: "tenant-b", Hosts: []string{"*.b.example.com", "b.example.com"}},
+	}
+	waitForCertificates(t, c, "istio-system", selector, want)
+	waitForCertificates(t, c, "istio-system", labels.Instance{"gateway-certificate": "other"},
+		[]*model.GatewayCertificate{{Name: "other", Hosts: []string{"other.example.com"}}})
+	if got := c.GatewayCertificates("default", selector); len(got) != 0 {
+		t.Errorf("got certificates %v for another namespace, want none", got)
+	}
+	waitForNotifications(t, &notified, 3)
+
+	if err := client.CoreV1().Secrets("istio-system").Delete("tenant-a", &metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitForCertificates(t, c, "istio-system", selector, want[1:])
+	waitForNotifications(t, &notified, 4)
+}
+
+func waitForNotifications(t *testing.T, notified *int32, want int32) {
+	t.Helper()
+	for i := 0; i < 50 && atomic.LoadInt32(notified) < want; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if got := atomic.LoadInt32(notified); got != want {
+		t.Fatalf("got %d notifications, want %d", got, want)
+	}
+}
+
+func waitForCertificates(t *testing.T, c *Controller, namespace string, selector labels.Instance, want []*model.GatewayCertificate) {
+	t.Helper()
+	var got []*model.GatewayCertificate
+	for i := 0; i < 50; i++ {
+		if got = c.GatewayCertificates(namespace, selector); reflect.DeepEqual(got, want) {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("got certificates %v, want %v", got, want)
+}
+
+func TestServerCertificateSecret(t *testing.T) {
+	cert, key := makeCertificate(t, "a.example.com")
+	meta := metav1.ObjectMeta{
+		Name:        "tenant-a",
+		Labels:      map[string]string{"gateway-certificate": "tenant"},
+		Annotations: map[string]string{"kubectl.kubernetes.io/last-applied-configuration": string(key)},
+	}
+	cases := []struct {
+		name     string
+		secretFn func(*v1.Secret)
+		want     map[string][]byte
+	}{
+		{
+			name:     "tls secret",
+			secretFn: func(s *v1.Secret) { s.Data = map[string][]byte{"tls.crt": cert, "tls.key": key} },
+			want:     map[string][]byte{"tls.crt": cert},
+		},
+		{
+			name: "generic secret",
+			secretFn: func(s *v1.Secret) {
+				s.Data = map[string][]byte{"cert": cert, "key": key, "cacert": cert}
+			},
+			want: map[string][]byte{"tls.crt": cert},
+		},
+		{
+			name:     "certificate without key",
+			secretFn: func(s *v1.Secret) { s.Data = map[string][]byte{"tls.crt": cert} },
+		},
+		{
+			name: "client CA secret",
+			secretFn: func(s *v1.Secret) {
+				s.Name += "-cacert"
+				s.Data = map[string][]byte{"tls.crt": cert, "tls.key": key}
+			},
+		},
+		{
+			name: "istio secret",
+			secretFn: func(s *v1.Secret) {
+				s.Name = "istio.default"
+				s.Data = map[string][]byte{"cert": cert, "key": key}
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			secret := &v1.Secret{ObjectMeta: *meta.DeepCopy()}
+			tc.secretFn(secret)
+			got := serverCertificateSecret(secret)
+			if !reflect.DeepEqual(got.Data, tc.want) {
+				t.Errorf("got data %v, want %v", got.Data, tc.want)
+			}
+			if !reflect.DeepEqual(got.Labels, meta.Labels) || len(got.Annotations) != 0 {
+				t.Errorf("got labels %v and annotations %v, want only the labels", got.Labels, got.Annotations)
+			}
+		})
+	}
+}
+
+func TestCertificateHosts(t *testing.T) {
+	if _, err := certificateHosts([]byte("not a certificate")); err == nil {
+		t.Errorf("expected an error")
+	}
+}
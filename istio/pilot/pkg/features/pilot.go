@@ -277,6 +277,38 @@ var (
 	// The 15010 port is used with plain text, 15011 with Spiffee certs - we need a different port for DNS cert.
 	IstiodService = env.RegisterStringVar("ISTIOD_ADDR", "",
 		"Service name of istiod. If empty the istiod listener, certs will be disabled.")
+
+	// WorkloadKeyAlgorithm is the algorithm of the private keys of the certificates generated by the
+	// certificate controller, for example RSA_2048 or ECDSA_P256.
+	WorkloadKeyAlgorithm = env.RegisterStringVar("WORKLOAD_KEY_ALGORITHM", "RSA_2048",
+		"The algorithm of the private keys of the certificates generated by Pilot.")
+
+	// GatewayCertificateNamespace is the namespace of the ingress gateway whose Kubernetes secrets are
+	// discovered as gateway certificates, selected by the Gateways with the credentialSelector annotation.
+	GatewayCertificateNamespace = env.RegisterStringVar(
+		"PILOT_GATEWAY_CERTIFICATE_NAMESPACE",
+		"",
+		"If set, Pilot discovers the certificates of the SDS enabled ingress gateways of this namespace from its "+
+			"Kubernetes secrets, and generates a filter chain for each certificate selected by the "+
+			"networking.istio.io/credentialSelector annotation of a Gateway.",
+	).Get()
+
+	// GatewayHTTPSRedirect is the default of the networking.istio.io/httpsRedirect annotation of the Gateways.
+	GatewayHTTPSRedirect = env.RegisterBoolVar(
+		"PILOT_GATEWAY_HTTPS_REDIRECT",
+		false,
+		"If enabled, the plain text HTTP servers of the gateways redirect to HTTPS the hosts also served over TLS, "+
+			"unless disabled by the networking.istio.io/httpsRedirect annotation of their Gateway.",
+	).Get()
+
+	// GatewaySecurityHeaders is the default of the networking.istio.io/securityHeaders annotation of the Gateways.
+	GatewaySecurityHeaders = env.RegisterStringVar(
+		"PILOT_GATEWAY_SECURITY_HEADERS",
+		"",
+		"The headers, of the form 'Name: value' one per line, set on the responses of the HTTP servers of the "+
+			"gateways, unless overridden by the networking.istio.io/securityHeaders annotation of their Gateway. "+
+			"The Strict-Transport-Security header is only set on the responses sent over TLS.",
+	).Get()
 )
 
 var (
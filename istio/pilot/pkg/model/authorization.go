@@ -19,11 +19,19 @@ import (
 	authpb "istio.io/api/security/v1beta1"
 
 	"istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/mesh"
 	"istio.io/istio/pkg/config/schemas"
 
 	istiolog "istio.io/pkg/log"
 )
 
+const (
+	// ExternalAuthorizerAnnotation is the annotation of the AuthorizationPolicy delegating the authorization of
+	// the requests of its workloads to the external authorizer named by the value. Such a policy is not an RBAC
+	// policy, its rules only select the paths of the requests to authorize.
+	ExternalAuthorizerAnnotation = "security.istio.io/externalAuthorizer"
+)
+
 var (
 	rbacLog = istiolog.RegisterScope("rbac", "rbac debugging", 0)
 )
@@ -37,6 +45,7 @@ type AuthorizationPolicyConfig struct {
 	Name                string                      `json:"name"`
 	Namespace           string                      `json:"namespace"`
 	AuthorizationPolicy *authpb.AuthorizationPolicy `json:"authorization_policy"`
+	ExternalAuthorizer  string                      `json:"external_authorizer,omitempty"`
 }
 
 // RolesAndBindings stores the the ServiceRole and ServiceRoleBinding in the same namespace.
@@ -60,6 +69,12 @@ type AuthorizationPolicies struct {
 	// Maps from namespace to the v1beta1 Authorization policies.
 	NamespaceToV1beta1Policies map[string][]AuthorizationPolicyConfig `json:"namespace_to_v1beta1_policies"`
 
+	// Maps from namespace to the v1beta1 Authorization policies delegating to an external authorizer.
+	NamespaceToExternalPolicies map[string][]AuthorizationPolicyConfig `json:"namespace_to_external_policies"`
+
+	// The external authorizers of the mesh.
+	ExternalAuthorizers *mesh.ExternalAuthorizers `json:"external_authorizers,omitempty"`
+
 	// The name of the root namespace. Policy in the root namespace applies to workloads in all
 	// namespaces. Only used for v1beta1 Authorization policy.
 	RootNamespace string `json:"root_namespace"`
@@ -70,8 +85,12 @@ func GetAuthorizationPolicies(env *Environment) (*AuthorizationPolicies, error)
 	policy := &AuthorizationPolicies{
 		NamespaceToV1alpha1Policies: map[string]*RolesAndBindings{},
 		NamespaceToV1beta1Policies:  map[string][]AuthorizationPolicyConfig{},
+		NamespaceToExternalPolicies: map[string][]AuthorizationPolicyConfig{},
 		RootNamespace:               env.Mesh().GetRootNamespace(),
 	}
+	if env.ExternalAuthorizersWatcher != nil {
+		policy.ExternalAuthorizers = env.ExternalAuthorizers()
+	}
 
 	rbacConfig := env.IstioConfigStore.ClusterRbacConfig()
 	if rbacConfig == nil {
@@ -185,7 +204,21 @@ func (policy *AuthorizationPolicies) ListAuthorizationPolicies(configNamespace s
 	if policy == nil {
 		return nil
 	}
+	return policy.listPolicies(policy.NamespaceToV1beta1Policies, configNamespace, workloadLabels)
+}
 
+// ListExternalAuthorizationPolicies returns the AuthorizationPolicy delegating to an external authorizer for the
+// workload in root namespace and the config namespace.
+func (policy *AuthorizationPolicies) ListExternalAuthorizationPolicies(configNamespace string,
+	workloadLabels labels.Collection) []AuthorizationPolicyConfig {
+	if policy == nil {
+		return nil
+	}
+	return policy.listPolicies(policy.NamespaceToExternalPolicies, configNamespace, workloadLabels)
+}
+
+func (policy *AuthorizationPolicies) listPolicies(namespaceToPolicies map[string][]AuthorizationPolicyConfig,
+	configNamespace string, workloadLabels labels.Collection) []AuthorizationPolicyConfig {
 	var namespaces []string
 	if policy.RootNamespace != "" {
 		namespaces = append(namespaces, policy.RootNamespace)
@@ -197,7 +230,7 @@ func (policy *AuthorizationPolicies) ListAuthorizationPolicies(configNamespace s
 
 	var ret []AuthorizationPolicyConfig
 	for _, ns := range namespaces {
-		for _, config := range policy.NamespaceToV1beta1Policies[ns] {
+		for _, config := range namespaceToPolicies[ns] {
 			spec := config.AuthorizationPolicy
 			selector := labels.Instance(spec.GetSelector().GetMatchLabels())
 			if workloadLabels.IsSupersetOf(selector) {
@@ -263,6 +296,12 @@ func (policy *AuthorizationPolicies) addAuthorizationPolicies(configs []Config)
 			Name:                config.Name,
 			Namespace:           config.Namespace,
 			AuthorizationPolicy: config.Spec.(*authpb.AuthorizationPolicy),
+			ExternalAuthorizer:  config.Annotations[ExternalAuthorizerAnnotation],
+		}
+		if authzConfig.ExternalAuthorizer != "" {
+			policy.NamespaceToExternalPolicies[config.Namespace] =
+				append(policy.NamespaceToExternalPolicies[config.Namespace], authzConfig)
+			continue
 		}
 		policy.NamespaceToV1beta1Policies[config.Namespace] =
 			append(policy.NamespaceToV1beta1Policies[config.Namespace], authzConfig)
@@ -596,6 +596,56 @@ func TestAuthorizationPolicies_ListAuthorizationPolicies(t *testing.T) {
 	}
 }
 
+func TestAuthorizationPolicies_ListExternalAuthorizationPolicies(t *testing.T) {
+	policy := &authpb.AuthorizationPolicy{
+		Rules: []*authpb.Rule{
+			{
+				To: []*authpb.Rule_To{
+					{
+						Operation: &authpb.Operation{
+							Paths: []string{"/admin*"},
+						},
+					},
+				},
+			},
+		},
+	}
+	external := newConfig("authz-external", "bar", policy)
+	external.Annotations = map[string]string{ExternalAuthorizerAnnotation: "sso"}
+
+	authzPolicies := createFakeAuthorizationPolicies([]Config{
+		external,
+		newConfig("authz-rbac", "bar", policy),
+	}, t)
+
+	workloadLabels := []labels.Instance{{}}
+	wantRBAC := []AuthorizationPolicyConfig{
+		{
+			Name:                "authz-rbac",
+			Namespace:           "bar",
+			AuthorizationPolicy: policy,
+		},
+	}
+	if got := authzPolicies.ListAuthorizationPolicies("bar", workloadLabels); !reflect.DeepEqual(wantRBAC, got) {
+		t.Errorf("ListAuthorizationPolicies want:%v\n but got: %v\n", wantRBAC, got)
+	}
+
+	wantExternal := []AuthorizationPolicyConfig{
+		{
+			Name:                "authz-external",
+			Namespace:           "bar",
+			AuthorizationPolicy: policy,
+			ExternalAuthorizer:  "sso",
+		},
+	}
+	if got := authzPolicies.ListExternalAuthorizationPolicies("bar", workloadLabels); !reflect.DeepEqual(wantExternal, got) {
+		t.Errorf("ListExternalAuthorizationPolicies want:%v\n but got: %v\n", wantExternal, got)
+	}
+	if got := authzPolicies.ListExternalAuthorizationPolicies("foo", workloadLabels); got != nil {
+		t.Errorf("ListExternalAuthorizationPolicies want no policies in namespace foo but got: %v", got)
+	}
+}
+
 func TestAuthorizationPolicies_IsRBACEnabled(t *testing.T) {
 	target := &rbacproto.RbacConfig_Target{
 		Services:   []string{"review.default.svc", "product.default.svc"},
@@ -56,6 +56,17 @@ type Environment struct {
 	// service registries.
 	mesh.NetworksWatcher
 
+	// ExternalAuthorizersWatcher (loaded from a config map) provides the external authorization services
+	// which the authorization policies can delegate the decisions to.
+	mesh.ExternalAuthorizersWatcher
+
+	// RateLimitsWatcher (loaded from a config map) provides the rate limits of the gateways and sidecars.
+	mesh.RateLimitsWatcher
+
+	// GatewayCertificates (discovered from the Kubernetes secrets) are the certificates the gateways can select
+	// with the GatewayCredentialSelectorAnnotation. Nil when the certificates are not discovered.
+	GatewayCertificates GatewayCertificateDiscovery
+
 	// PushContext holds informations during push generation. It is reset on config change, at the beginning
 	// of the pushAll. It will hold all errors and stats and possibly caches needed during the entire cache computation.
 	// DO NOT USE EXCEPT FOR TESTS AND HANDLING OF NEW CONNECTIONS.
@@ -152,6 +163,10 @@ type Proxy struct {
 
 	// Istio version associated with the Proxy
 	IstioVersion *IstioVersion
+
+	// EnvoyFilterStatus tracks the EnvoyFilter patches applied to the config generated for the proxy.
+	// Nil when the patches are not tracked.
+	EnvoyFilterStatus *EnvoyFilterStatus
 }
 
 var (
@@ -15,6 +15,8 @@ package model
 
 import (
 	"regexp"
+	"sort"
+	"sync"
 
 	"github.com/gogo/protobuf/proto"
 
@@ -39,10 +41,28 @@ type EnvoyFilterConfigPatchWrapper struct {
 	Operation networking.EnvoyFilter_Patch_Operation
 	// Pre-compile the regex from proxy version match in the match
 	ProxyVersionRegex *regexp.Regexp
+	// Name and Namespace of the EnvoyFilter of the patch, and the index of the patch in its config patches
+	Name      string
+	Namespace string
+	Index     int
+	// applied and failed count the objects the patch was applied to, or failed to be applied to.
+	// They are only updated on the copies of the patches merged for a proxy.
+	applied int
+	failed  int
 }
 
-// convertToEnvoyFilterWrapper converts from EnvoyFilter config to EnvoyFilterWrapper object
-func convertToEnvoyFilterWrapper(local *Config) *EnvoyFilterWrapper {
+// RecordApplied records that the patch was applied to an object of the generated config.
+func (cp *EnvoyFilterConfigPatchWrapper) RecordApplied() {
+	cp.applied++
+}
+
+// RecordFailure records that the patch matched an object of the generated config but could not be applied to it.
+func (cp *EnvoyFilterConfigPatchWrapper) RecordFailure() {
+	cp.failed++
+}
+
+// ConvertToEnvoyFilterWrapper converts from EnvoyFilter config to EnvoyFilterWrapper object
+func ConvertToEnvoyFilterWrapper(local *Config) *EnvoyFilterWrapper {
 	localEnvoyFilter := local.Spec.(*networking.EnvoyFilter)
 
 	out := &EnvoyFilterWrapper{}
@@ -50,11 +70,14 @@ func convertToEnvoyFilterWrapper(local *Config) *EnvoyFilterWrapper {
 		out.workloadSelector = localEnvoyFilter.WorkloadSelector.Labels
 	}
 	out.Patches = make(map[networking.EnvoyFilter_ApplyTo][]*EnvoyFilterConfigPatchWrapper)
-	for _, cp := range localEnvoyFilter.ConfigPatches {
+	for i, cp := range localEnvoyFilter.ConfigPatches {
 		cpw := &EnvoyFilterConfigPatchWrapper{
 			ApplyTo:   cp.ApplyTo,
 			Match:     cp.Match,
 			Operation: cp.Patch.Operation,
+			Name:      local.Name,
+			Namespace: local.Namespace,
+			Index:     i,
 		}
 		// there won't be an error here because validation catches mismatched types
 		cpw.Value, _ = xds.BuildXDSObjectFromStruct(cp.ApplyTo, cp.Patch.Value)
@@ -107,3 +130,118 @@ func proxyMatch(proxy *Proxy, cp *EnvoyFilterConfigPatchWrapper) bool {
 	}
 	return true
 }
+
+// EnvoyFilter patch results reported by EnvoyFilterPatchStatus.
+const (
+	EnvoyFilterPatchApplied = "applied"
+	EnvoyFilterPatchNoMatch = "no_match"
+	EnvoyFilterPatchError   = "error"
+)
+
+// EnvoyFilterPatchStatus is the outcome of a patch of an EnvoyFilter in the config generated for a proxy.
+type EnvoyFilterPatchStatus struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Index     int    `json:"index"`
+	ApplyTo   string `json:"apply_to"`
+	Operation string `json:"operation"`
+	// Applied and Failed are the numbers of objects the patch was applied to, or failed to be applied to.
+	Applied int `json:"applied"`
+	Failed  int `json:"failed"`
+}
+
+// Result returns the result of the patch: applied, no_match or error.
+func (s *EnvoyFilterPatchStatus) Result() string {
+	switch {
+	case s.Failed > 0:
+		return EnvoyFilterPatchError
+	case s.Applied > 0:
+		return EnvoyFilterPatchApplied
+	default:
+		return EnvoyFilterPatchNoMatch
+	}
+}
+
+type envoyFilterPatchKey struct {
+	name      string
+	namespace string
+	index     int
+}
+
+// EnvoyFilterStatus tracks the EnvoyFilter patches applied to the config generated for a proxy. The patches
+// are applied to a few objects at a time while the config is generated; their outcomes are accumulated
+// until the generated config is pushed, then flushed.
+type EnvoyFilterStatus struct {
+	mu      sync.Mutex
+	pending map[envoyFilterPatchKey]*EnvoyFilterPatchStatus
+	last    map[envoyFilterPatchKey]*EnvoyFilterPatchStatus
+}
+
+// NewEnvoyFilterStatus creates an empty EnvoyFilterStatus.
+func NewEnvoyFilterStatus() *EnvoyFilterStatus {
+	return &EnvoyFilterStatus{
+		pending: map[envoyFilterPatchKey]*EnvoyFilterPatchStatus{},
+		last:    map[envoyFilterPatchKey]*EnvoyFilterPatchStatus{},
+	}
+}
+
+// Record accumulates the outcome of a patch merged for the proxy. Patches that are recorded but never applied
+// are reported as not matching anything.
+func (s *EnvoyFilterStatus) Record(cp *EnvoyFilterConfigPatchWrapper) {
+	key := envoyFilterPatchKey{name: cp.Name, namespace: cp.Namespace, index: cp.Index}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status, ok := s.pending[key]
+	if !ok {
+		status = &EnvoyFilterPatchStatus{
+			Name:      cp.Name,
+			Namespace: cp.Namespace,
+			Index:     cp.Index,
+			ApplyTo:   cp.ApplyTo.String(),
+			Operation: cp.Operation.String(),
+		}
+		s.pending[key] = status
+	}
+	status.Applied += cp.applied
+	status.Failed += cp.failed
+}
+
+// Flush returns the outcomes accumulated since the last flush, and keeps them as the latest outcomes
+// of their patches.
+func (s *EnvoyFilterStatus) Flush() []*EnvoyFilterPatchStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*EnvoyFilterPatchStatus, 0, len(s.pending))
+	for key, status := range s.pending {
+		s.last[key] = status
+		out = append(out, status)
+	}
+	s.pending = map[envoyFilterPatchKey]*EnvoyFilterPatchStatus{}
+	sortEnvoyFilterPatchStatus(out)
+	return out
+}
+
+// Patches returns the latest outcomes of the patches applied to the proxy, sorted by EnvoyFilter.
+func (s *EnvoyFilterStatus) Patches() []*EnvoyFilterPatchStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*EnvoyFilterPatchStatus, 0, len(s.last))
+	for _, status := range s.last {
+		copied := *status
+		out = append(out, &copied)
+	}
+	sortEnvoyFilterPatchStatus(out)
+	return out
+}
+
+func sortEnvoyFilterPatchStatus(statuses []*EnvoyFilterPatchStatus) {
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Namespace != statuses[j].Namespace {
+			return statuses[i].Namespace < statuses[j].Namespace
+		}
+		if statuses[i].Name != statuses[j].Name {
+			return statuses[i].Name < statuses[j].Name
+		}
+		return statuses[i].Index < statuses[j].Index
+	})
+}
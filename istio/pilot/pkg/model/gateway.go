@@ -22,6 +22,7 @@ import (
 	networking "istio.io/api/networking/v1alpha3"
 
 	"istio.io/istio/pkg/config/gateway"
+	"istio.io/istio/pkg/config/labels"
 	"istio.io/istio/pkg/config/protocol"
 	"istio.io/pkg/monitoring"
 )
@@ -46,6 +47,13 @@ type MergedGateway struct {
 	// Inverse of ServersByRouteName. Returning this as part of merge result allows to keep route name generation logic
 	// encapsulated within the model and, as a side effect, to avoid generating route names twice.
 	RouteNamesByServer map[*networking.Server]string
+
+	// maps from HTTPS server to the selector of the secrets of its discovered certificates, set by the
+	// GatewayCredentialSelectorAnnotation of the owning gateway
+	CredentialSelectorForServer map[*networking.Server]labels.Instance
+
+	// maps from HTTP server to the security policy of the owning gateway, if it has one
+	SecurityPolicyForServer map[*networking.Server]*GatewaySecurityPolicy
 }
 
 var (
@@ -79,6 +87,8 @@ func MergeGateways(gateways ...Config) *MergedGateway {
 	serversByRouteName := make(map[string][]*networking.Server)
 	routeNamesByServer := make(map[*networking.Server]string)
 	gatewayNameForServer := make(map[*networking.Server]string)
+	credentialSelectorForServer := make(map[*networking.Server]labels.Instance)
+	securityPolicyForServer := make(map[*networking.Server]*GatewaySecurityPolicy)
 	tlsHostsByPort := map[uint32]map[string]struct{}{} // port -> host -> exists
 
 	log.Debugf("MergeGateways: merging %d gateways", len(gateways))
@@ -87,10 +97,18 @@ func MergeGateways(gateways ...Config) *MergedGateway {
 		names[gatewayName] = true
 
 		gatewayCfg := gatewayConfig.Spec.(*networking.Gateway)
+		credentialSelector := gatewayCredentialSelector(gatewayConfig)
+		securityPolicy := gatewaySecurityPolicy(gatewayConfig)
 		log.Debugf("MergeGateways: merging gateway %q into %v:\n%v", gatewayName, names, gatewayCfg)
 		for _, s := range gatewayCfg.Servers {
 			sanitizeServerHostNamespace(s, gatewayConfig.Namespace)
 			gatewayNameForServer[s] = gatewayName
+			if credentialSelector != nil && isTerminatedHTTPSServer(s) {
+				credentialSelectorForServer[s] = credentialSelector
+			}
+			if securityPolicy != nil && gateway.IsHTTPServer(s) {
+				securityPolicyForServer[s] = securityPolicy
+			}
 			log.Debugf("MergeGateways: gateway %q processing server %v", gatewayName, s.Hosts)
 			p := protocol.Parse(s.Port.Protocol)
 
@@ -195,11 +213,32 @@ func MergeGateways(gateways ...Config) *MergedGateway {
 	}
 
 	return &MergedGateway{
-		Servers:              servers,
-		GatewayNameForServer: gatewayNameForServer,
-		ServersByRouteName:   serversByRouteName,
-		RouteNamesByServer:   routeNamesByServer,
+		Servers:                     servers,
+		GatewayNameForServer:        gatewayNameForServer,
+		ServersByRouteName:          serversByRouteName,
+		RouteNamesByServer:          routeNamesByServer,
+		CredentialSelectorForServer: credentialSelectorForServer,
+		SecurityPolicyForServer:     securityPolicyForServer,
+	}
+}
+
+// gatewayCredentialSelector returns the selector of the GatewayCredentialSelectorAnnotation of the gateway,
+// or nil if the gateway does not have one.
+func gatewayCredentialSelector(gatewayConfig Config) labels.Instance {
+	selector := strings.TrimSpace(gatewayConfig.Annotations[GatewayCredentialSelectorAnnotation])
+	if selector == "" {
+		return nil
+	}
+	return labels.Parse(selector)
+}
+
+// isTerminatedHTTPSServer returns true if the server terminates HTTPS with a server certificate, which
+// can be discovered.
+func isTerminatedHTTPSServer(s *networking.Server) bool {
+	if s.Tls == nil || protocol.Parse(s.Port.Protocol) != protocol.HTTPS {
+		return false
 	}
+	return s.Tls.Mode == networking.Server_TLSOptions_SIMPLE || s.Tls.Mode == networking.Server_TLSOptions_MUTUAL
 }
 
 // checkDuplicates returns all of the hosts provided that are already known
@@ -0,0 +1,42 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"istio.io/istio/pkg/config/labels"
+)
+
+// GatewayCredentialSelectorAnnotation is the annotation of a Gateway selecting, by the labels of their
+// Kubernetes secrets of the form k1=v1,k2=v2, additional certificates for its HTTPS servers using SIMPLE or
+// MUTUAL TLS. A filter chain is generated for each certificate, matching the SNI of its DNS names that are
+// hosts of the server; the credential of the server remains the certificate of the other SNIs.
+const GatewayCredentialSelectorAnnotation = "networking.istio.io/credentialSelector"
+
+// GatewayCertificate is a certificate discovered for the gateways, served by the SDS of the gateway
+// under the name of its secret.
+type GatewayCertificate struct {
+	// Name of the secret of the certificate.
+	Name string
+
+	// Hosts are the DNS names of the certificate.
+	Hosts []string
+}
+
+// GatewayCertificateDiscovery discovers the certificates of the gateways.
+type GatewayCertificateDiscovery interface {
+	// GatewayCertificates returns the certificates, sorted by name, of the secrets matching the selector
+	// which are available to the gateways of the namespace.
+	GatewayCertificates(namespace string, selector labels.Instance) []*GatewayCertificate
+}
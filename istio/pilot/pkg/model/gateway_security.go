@@ -0,0 +1,113 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"istio.io/istio/pilot/pkg/features"
+)
+
+const (
+	// GatewayHTTPSRedirectAnnotation is the annotation of a Gateway which, when "true", redirects the requests
+	// of its plain text HTTP servers to HTTPS for every host also served by a TLS server of the gateway
+	// workload. It overrides the PILOT_GATEWAY_HTTPS_REDIRECT default.
+	GatewayHTTPSRedirectAnnotation = "networking.istio.io/httpsRedirect"
+
+	// GatewaySecurityHeadersAnnotation is the annotation of a Gateway listing, one per line, the headers
+	// of the form "Name: value" set on the responses of its HTTP servers, e.g.
+	//   Strict-Transport-Security: max-age=31536000; includeSubDomains
+	//   X-Frame-Options: DENY
+	// The Strict-Transport-Security header is only set on the responses sent over TLS. The annotation,
+	// even empty, overrides the PILOT_GATEWAY_SECURITY_HEADERS default.
+	GatewaySecurityHeadersAnnotation = "networking.istio.io/securityHeaders"
+
+	// StrictTransportSecurityHeader is the HSTS header, only sent over TLS.
+	StrictTransportSecurityHeader = "Strict-Transport-Security"
+)
+
+// GatewaySecurityPolicy is the security policy of the HTTP servers of a gateway.
+type GatewaySecurityPolicy struct {
+	// HTTPSRedirect redirects the plain text requests to HTTPS for the hosts served over TLS.
+	HTTPSRedirect bool
+
+	// Headers are the headers set on the responses, overriding the ones of the upstream.
+	Headers []GatewaySecurityHeader
+}
+
+// GatewaySecurityHeader is a header set on the responses of a gateway.
+type GatewaySecurityHeader struct {
+	Name  string
+	Value string
+}
+
+// gatewaySecurityPolicy returns the security policy of the gateway, from its annotations or the defaults of
+// the mesh, or nil if the gateway does not have any.
+func gatewaySecurityPolicy(gatewayConfig Config) *GatewaySecurityPolicy {
+	policy := &GatewaySecurityPolicy{HTTPSRedirect: features.GatewayHTTPSRedirect}
+	if value, ok := gatewayConfig.Annotations[GatewayHTTPSRedirectAnnotation]; ok {
+		redirect, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			log.Warnf("invalid %s annotation of gateway %s/%s: %v", GatewayHTTPSRedirectAnnotation,
+				gatewayConfig.Namespace, gatewayConfig.Name, err)
+		} else {
+			policy.HTTPSRedirect = redirect
+		}
+	}
+
+	headers, ok := gatewayConfig.Annotations[GatewaySecurityHeadersAnnotation]
+	if !ok {
+		headers = features.GatewaySecurityHeaders
+	}
+	var err error
+	if policy.Headers, err = ParseGatewaySecurityHeaders(headers); err != nil {
+		log.Warnf("invalid security headers of gateway %s/%s: %v", gatewayConfig.Namespace, gatewayConfig.Name, err)
+	}
+
+	if !policy.HTTPSRedirect && len(policy.Headers) == 0 {
+		return nil
+	}
+	return policy
+}
+
+// ParseGatewaySecurityHeaders parses the "Name: value" headers, one per line. Blank lines are ignored, and
+// the headers in error are skipped.
+func ParseGatewaySecurityHeaders(text string) ([]GatewaySecurityHeader, error) {
+	var headers []GatewaySecurityHeader
+	var errs []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || name == "" || strings.ContainsAny(name, " \t") {
+			errs = append(errs, fmt.Sprintf("invalid header %q", line))
+			continue
+		}
+		headers = append(headers, GatewaySecurityHeader{
+			Name:  http.CanonicalHeaderKey(name),
+			Value: strings.TrimSpace(parts[1]),
+		})
+	}
+	if len(errs) > 0 {
+		return headers, fmt.Errorf("%s", strings.Join(errs, ", "))
+	}
+	return headers, nil
+}
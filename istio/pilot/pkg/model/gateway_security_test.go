@@ -0,0 +1,61 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGatewaySecurityHeaders(t *testing.T) {
+	cases := []struct {
+		name    string
+		text    string
+		want    []GatewaySecurityHeader
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			text: "",
+		},
+		{
+			name: "headers",
+			text: "strict-transport-security: max-age=31536000; includeSubDomains\n\n  X-Content-Type-Options:nosniff  \n",
+			want: []GatewaySecurityHeader{
+				{Name: "Strict-Transport-Security", Value: "max-age=31536000; includeSubDomains"},
+				{Name: "X-Content-Type-Options", Value: "nosniff"},
+			},
+		},
+		{
+			name: "invalid headers are skipped",
+			text: "X-Frame-Options: DENY\nno value\nbad name: x\n: empty",
+			want: []GatewaySecurityHeader{
+				{Name: "X-Frame-Options", Value: "DENY"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGatewaySecurityHeaders(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got headers %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ package model
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	networking "istio.io/api/networking/v1alpha3"
@@ -94,6 +95,55 @@ func TestMergeGateways(t *testing.T) {
 	}
 }
 
+func TestMergeGatewaysCredentialSelector(t *testing.T) {
+	https := makeConfig("https", "istio-system", "*", "https", "HTTPS", 443, "ingressgateway")
+	https.Annotations = map[string]string{GatewayCredentialSelectorAnnotation: "gateway-certificate=tenant"}
+	httpsServer := https.Spec.(*networking.Gateway).Servers[0]
+	httpsServer.Tls = &networking.Server_TLSOptions{Mode: networking.Server_TLSOptions_SIMPLE, CredentialName: "default"}
+	// the certificates of the passthrough and plaintext servers are not discovered
+	passthrough := makeConfig("passthrough", "istio-system", "*.example.com", "tls", "TLS", 8443, "ingressgateway")
+	passthrough.Annotations = https.Annotations
+	passthrough.Spec.(*networking.Gateway).Servers[0].Tls = &networking.Server_TLSOptions{Mode: networking.Server_TLSOptions_PASSTHROUGH}
+	http := makeConfig("http", "istio-system", "*", "http", "HTTP", 80, "ingressgateway")
+	http.Annotations = https.Annotations
+
+	mgw := MergeGateways(https, passthrough, http)
+	if len(mgw.CredentialSelectorForServer) != 1 {
+		t.Fatalf("got credential selectors for %d servers, want 1", len(mgw.CredentialSelectorForServer))
+	}
+	if got := mgw.CredentialSelectorForServer[httpsServer]; got.String() != "gateway-certificate=tenant" {
+		t.Errorf("got credential selector %v", got)
+	}
+}
+
+func TestMergeGatewaysSecurityPolicy(t *testing.T) {
+	http := makeConfig("http", "istio-system", "*", "http", "HTTP", 80, "ingressgateway")
+	http.Annotations = map[string]string{
+		GatewayHTTPSRedirectAnnotation:   "true",
+		GatewaySecurityHeadersAnnotation: "x-frame-options: DENY",
+	}
+	httpServer := http.Spec.(*networking.Gateway).Servers[0]
+	// the passthrough servers do not serve the responses
+	passthrough := makeConfig("passthrough", "istio-system", "*.example.com", "tls", "TLS", 8443, "ingressgateway")
+	passthrough.Annotations = http.Annotations
+	passthrough.Spec.(*networking.Gateway).Servers[0].Tls = &networking.Server_TLSOptions{Mode: networking.Server_TLSOptions_PASSTHROUGH}
+	// a gateway disabling the redirect and the headers does not have a policy
+	disabled := makeConfig("disabled", "istio-system", "*", "http", "HTTP", 8080, "ingressgateway")
+	disabled.Annotations = map[string]string{GatewayHTTPSRedirectAnnotation: "false", GatewaySecurityHeadersAnnotation: ""}
+
+	mgw := MergeGateways(http, passthrough, disabled)
+	if len(mgw.SecurityPolicyForServer) != 1 {
+		t.Fatalf("got security policies for %d servers, want 1", len(mgw.SecurityPolicyForServer))
+	}
+	want := &GatewaySecurityPolicy{
+		HTTPSRedirect: true,
+		Headers:       []GatewaySecurityHeader{{Name: "X-Frame-Options", Value: "DENY"}},
+	}
+	if got := mgw.SecurityPolicyForServer[httpServer]; !reflect.DeepEqual(got, want) {
+		t.Errorf("got security policy %+v, want %+v", got, want)
+	}
+}
+
 func makeConfig(name, namespace, host, portName, portProtocol string, portNumber uint32, gw string) Config {
 	c := Config{
 		ConfigMeta: ConfigMeta{
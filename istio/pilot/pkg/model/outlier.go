@@ -0,0 +1,177 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"istio.io/istio/pkg/config/host"
+)
+
+// OutlierReportExpiry is the duration after which the last report of a proxy is discarded, e.g. when the
+// proxy is gone or reports to another Pilot instance.
+const OutlierReportExpiry = 5 * time.Minute
+
+// OutlierReport is the outlier detection and circuit breaker state of the clusters of a proxy, reported by
+// its agent. Each report replaces the previous one of the proxy.
+type OutlierReport struct {
+	// Proxy is the ID of the proxy.
+	Proxy string `json:"proxy"`
+
+	// Clusters are the clusters with ejections or circuit breaker overflows.
+	Clusters []*ClusterOutlierStatus `json:"clusters"`
+}
+
+// ClusterOutlierStatus is the outlier detection and circuit breaker state of a cluster of a proxy.
+type ClusterOutlierStatus struct {
+	// Cluster is the name of the Envoy cluster, e.g. outbound|9080|v1|reviews.default.svc.cluster.local.
+	Cluster string `json:"cluster"`
+
+	// EjectedEndpoints are the addresses of the endpoints currently ejected by the proxy.
+	EjectedEndpoints []string `json:"ejectedEndpoints,omitempty"`
+
+	// Ejections is the number of enforced ejections since the proxy started.
+	Ejections uint64 `json:"ejections"`
+
+	// LastEjection is the time of the last enforced ejection.
+	LastEjection time.Time `json:"lastEjection"`
+
+	// ConnectionOverflows is the number of connections refused by the circuit breaker.
+	ConnectionOverflows uint64 `json:"connectionOverflows"`
+
+	// PendingRequestOverflows is the number of requests refused by the circuit breaker of the pending requests.
+	PendingRequestOverflows uint64 `json:"pendingRequestOverflows"`
+
+	// RetryOverflows is the number of retries not attempted because of the circuit breaker.
+	RetryOverflows uint64 `json:"retryOverflows"`
+}
+
+// ProxyOutlierStatus is the state of a destination reported by a proxy.
+type ProxyOutlierStatus struct {
+	ClusterOutlierStatus
+
+	// Proxy is the ID of the proxy.
+	Proxy string `json:"proxy"`
+
+	// Reported is the time of the report.
+	Reported time.Time `json:"reported"`
+}
+
+// DestinationOutlierStatus is the outlier detection and circuit breaker state of a destination of the
+// mesh, reported by the proxies sending requests to it.
+type DestinationOutlierStatus struct {
+	Hostname host.Name `json:"hostname"`
+	Subset   string    `json:"subset,omitempty"`
+	Port     int       `json:"port"`
+
+	// Proxies are the reports of the proxies, sorted by proxy ID.
+	Proxies []*ProxyOutlierStatus `json:"proxies"`
+}
+
+// EjectedEndpoints returns the endpoints ejected by the proxies, with the IDs of the proxies ejecting them.
+func (d *DestinationOutlierStatus) EjectedEndpoints() map[string][]string {
+	out := map[string][]string{}
+	for _, p := range d.Proxies {
+		for _, ep := range p.EjectedEndpoints {
+			out[ep] = append(out[ep], p.Proxy)
+		}
+	}
+	return out
+}
+
+type proxyOutlierReport struct {
+	reported time.Time
+	clusters []*ClusterOutlierStatus
+}
+
+// outlierReportPruneInterval is the minimum interval between the prunings of the expired reports on write.
+const outlierReportPruneInterval = time.Minute
+
+// OutlierStatus aggregates the outlier reports of the proxies.
+type OutlierStatus struct {
+	mu      sync.Mutex
+	reports map[string]*proxyOutlierReport
+	// pruned is the time the expired reports were last discarded.
+	pruned time.Time
+}
+
+// NewOutlierStatus creates an empty OutlierStatus.
+func NewOutlierStatus() *OutlierStatus {
+	return &OutlierStatus{reports: map[string]*proxyOutlierReport{}}
+}
+
+// Report records the report of a proxy, replacing its previous one. The expired reports are discarded at
+// most every outlierReportPruneInterval, so that the reports of the proxies which are gone do not accumulate.
+func (s *OutlierStatus) Report(report *OutlierReport, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.pruned) >= outlierReportPruneInterval {
+		s.prune(now)
+	}
+	s.reports[report.Proxy] = &proxyOutlierReport{reported: now, clusters: report.Clusters}
+}
+
+// prune discards the reports older than OutlierReportExpiry.
+func (s *OutlierStatus) prune(now time.Time) {
+	for proxy, report := range s.reports {
+		if now.Sub(report.reported) > OutlierReportExpiry {
+			delete(s.reports, proxy)
+		}
+	}
+	s.pruned = now
+}
+
+// Destinations returns the state of the outbound destinations reported by the proxies in the last
+// OutlierReportExpiry, sorted by hostname, subset and port. The expired reports are discarded.
+func (s *OutlierStatus) Destinations(now time.Time) []*DestinationOutlierStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(now)
+	byKey := map[string]*DestinationOutlierStatus{}
+	for proxy, report := range s.reports {
+		for _, c := range report.clusters {
+			direction, subset, hostname, port := ParseSubsetKey(c.Cluster)
+			if direction != TrafficDirectionOutbound || hostname == "" {
+				continue
+			}
+			key := BuildSubsetKey(direction, subset, hostname, port)
+			d, ok := byKey[key]
+			if !ok {
+				d = &DestinationOutlierStatus{Hostname: hostname, Subset: subset, Port: port}
+				byKey[key] = d
+			}
+			d.Proxies = append(d.Proxies, &ProxyOutlierStatus{ClusterOutlierStatus: *c, Proxy: proxy, Reported: report.reported})
+		}
+	}
+
+	out := make([]*DestinationOutlierStatus, 0, len(byKey))
+	for _, d := range byKey {
+		sort.Slice(d.Proxies, func(i, j int) bool { return d.Proxies[i].Proxy < d.Proxies[j].Proxy })
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Hostname != out[j].Hostname {
+			return out[i].Hostname < out[j].Hostname
+		}
+		if out[i].Subset != out[j].Subset {
+			return out[i].Subset < out[j].Subset
+		}
+		return out[i].Port < out[j].Port
+	})
+	return out
+}
@@ -0,0 +1,102 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOutlierStatus(t *testing.T) {
+	now := time.Now()
+	s := NewOutlierStatus()
+	s.Report(&OutlierReport{
+		Proxy: "expired.default",
+		Clusters: []*ClusterOutlierStatus{
+			{Cluster: "outbound|9080|v1|reviews.default.svc.cluster.local", EjectedEndpoints: []string{"10.0.0.9:9080"}},
+		},
+	}, now.Add(-OutlierReportExpiry-time.Second))
+	s.Report(&OutlierReport{
+		Proxy: "productpage.default",
+		Clusters: []*ClusterOutlierStatus{
+			{Cluster: "outbound|9080|v1|reviews.default.svc.cluster.local", EjectedEndpoints: []string{"10.0.0.1:9080"}, Ejections: 3},
+			{Cluster: "outbound|9080||ratings.default.svc.cluster.local", PendingRequestOverflows: 7},
+			// the inbound clusters are not destinations of the mesh
+			{Cluster: "inbound|9080|http|productpage.default.svc.cluster.local", ConnectionOverflows: 1},
+		},
+	}, now)
+	s.Report(&OutlierReport{
+		Proxy: "gateway.istio-system",
+		Clusters: []*ClusterOutlierStatus{
+			{Cluster: "outbound|9080|v1|reviews.default.svc.cluster.local", EjectedEndpoints: []string{"10.0.0.1:9080", "10.0.0.2:9080"}},
+		},
+	}, now)
+
+	got := s.Destinations(now)
+	if len(got) != 2 {
+		t.Fatalf("got %d destinations, want 2", len(got))
+	}
+	if got[0].Hostname != "ratings.default.svc.cluster.local" || got[0].Subset != "" || got[0].Port != 9080 ||
+		len(got[0].Proxies) != 1 || got[0].Proxies[0].PendingRequestOverflows != 7 {
+		t.Errorf("unexpected ratings destination %+v", got[0])
+	}
+	reviews := got[1]
+	if reviews.Hostname != "reviews.default.svc.cluster.local" || reviews.Subset != "v1" || len(reviews.Proxies) != 2 {
+		t.Fatalf("unexpected reviews destination %+v", reviews)
+	}
+	if reviews.Proxies[0].Proxy != "gateway.istio-system" || reviews.Proxies[1].Proxy != "productpage.default" ||
+		reviews.Proxies[1].Ejections != 3 || !reviews.Proxies[1].Reported.Equal(now) {
+		t.Errorf("unexpected reviews proxies %+v %+v", reviews.Proxies[0], reviews.Proxies[1])
+	}
+	wantEjected := map[string][]string{
+		"10.0.0.1:9080": {"gateway.istio-system", "productpage.default"},
+		"10.0.0.2:9080": {"gateway.istio-system"},
+	}
+	if ejected := reviews.EjectedEndpoints(); !reflect.DeepEqual(ejected, wantEjected) {
+		t.Errorf("got ejected endpoints %v, want %v", ejected, wantEjected)
+	}
+
+	// a new report replaces the previous one of the proxy
+	s.Report(&OutlierReport{Proxy: "gateway.istio-system"}, now)
+	s.Report(&OutlierReport{Proxy: "productpage.default"}, now)
+	if got := s.Destinations(now); len(got) != 0 {
+		t.Errorf("got destinations %v, want none", got)
+	}
+}
+
+func TestOutlierStatusPrunesOnReport(t *testing.T) {
+	now := time.Now()
+	s := NewOutlierStatus()
+	s.Report(&OutlierReport{Proxy: "gone.default"}, now)
+	s.Report(&OutlierReport{Proxy: "productpage.default"}, now.Add(30*time.Second))
+
+	// the expired reports are discarded by the next report, without listing the destinations
+	pruned := now.Add(OutlierReportExpiry + time.Second)
+	s.Report(&OutlierReport{Proxy: "reviews.default"}, pruned)
+	if _, ok := s.reports["gone.default"]; ok || len(s.reports) != 2 {
+		t.Errorf("got reports of %d proxies, want the expired one to be discarded", len(s.reports))
+	}
+
+	// the reports are not scanned again before the pruning interval
+	s.Report(&OutlierReport{Proxy: "ratings.default"}, pruned.Add(outlierReportPruneInterval/2))
+	if len(s.reports) != 3 {
+		t.Errorf("got reports of %d proxies, want 3", len(s.reports))
+	}
+	s.Report(&OutlierReport{Proxy: "ratings.default"}, pruned.Add(outlierReportPruneInterval))
+	if _, ok := s.reports["productpage.default"]; ok || len(s.reports) != 2 {
+		t.Errorf("got reports of %d proxies, want the expired one to be discarded", len(s.reports))
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/mesh"
 	"istio.io/istio/pkg/config/protocol"
 	"istio.io/istio/pkg/config/schemas"
 	"istio.io/istio/pkg/config/visibility"
@@ -112,6 +113,12 @@ type PushContext struct {
 	// Networks configuration.
 	Networks *meshconfig.MeshNetworks `json:"-"`
 
+	// RateLimits configuration.
+	RateLimits *mesh.RateLimits `json:"-"`
+
+	// GatewayCertificates discovers the certificates of the gateways.
+	GatewayCertificates GatewayCertificateDiscovery `json:"-"`
+
 	// Discovery interface for listing services and instances.
 	ServiceDiscovery `json:"-"`
 
@@ -746,6 +753,10 @@ func (ps *PushContext) InitContext(env *Environment, oldPushContext *PushContext
 
 	ps.Mesh = env.Mesh()
 	ps.Networks = env.Networks()
+	if env.RateLimitsWatcher != nil {
+		ps.RateLimits = env.RateLimits()
+	}
+	ps.GatewayCertificates = env.GatewayCertificates
 	ps.ServiceDiscovery = env
 	ps.IstioConfigStore = env
 	ps.Version = env.Version()
@@ -1427,7 +1438,7 @@ func (ps *PushContext) initEnvoyFilters(env *Environment) error {
 
 	ps.envoyFiltersByNamespace = make(map[string][]*EnvoyFilterWrapper)
 	for _, envoyFilterConfig := range envoyFilterConfigs {
-		efw := convertToEnvoyFilterWrapper(&envoyFilterConfig)
+		efw := ConvertToEnvoyFilterWrapper(&envoyFilterConfig)
 		if _, exists := ps.envoyFiltersByNamespace[envoyFilterConfig.Namespace]; !exists {
 			ps.envoyFiltersByNamespace[envoyFilterConfig.Namespace] = make([]*EnvoyFilterWrapper, 0)
 		}
@@ -1480,7 +1491,9 @@ func (ps *PushContext) EnvoyFilters(proxy *Proxy) *EnvoyFilterWrapper {
 			}
 			for _, cp := range cps {
 				if proxyMatch(proxy, cp) {
-					out.Patches[applyTo] = append(out.Patches[applyTo], cp)
+					// copy the patch, so that the outcome of the patches is tracked for each proxy
+					copied := *cp
+					out.Patches[applyTo] = append(out.Patches[applyTo], &copied)
 				}
 			}
 		}
@@ -343,6 +343,10 @@ type IstioEndpoint struct {
 
 	// TLSMode endpoint is injected with istio sidecar and ready to configure Istio mTLS
 	TLSMode string
+
+	// Unhealthy is true if the endpoint is not ready to receive traffic. Unhealthy endpoints are not
+	// sent to the proxies, they are only counted to compute the health of their locality.
+	Unhealthy bool
 }
 
 // ServiceAttributes represents a group of custom attributes of the service.
@@ -368,6 +372,11 @@ type ServiceAttributes struct {
 	// Used by the aggregator to aggregate the Attributes.ClusterExternalAddresses
 	// for clusters where the service resides
 	ClusterExternalAddresses map[string][]string
+
+	// LocalityOverprovisioningFactor is the overprovisioning factor, in percent, used to spread the load
+	// of the service across the locality priorities according to the health of their endpoints.
+	// 0 means the locality priorities do not depend on the health of the endpoints.
+	LocalityOverprovisioningFactor uint32
 }
 
 // ServiceDiscovery enumerates Istio service instances.
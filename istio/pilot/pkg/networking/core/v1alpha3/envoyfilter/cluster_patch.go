@@ -32,17 +32,28 @@ func ApplyClusterPatches(
 	proxy *model.Proxy,
 	push *model.PushContext,
 	clusters []*xdsapi.Cluster) (out []*xdsapi.Cluster) {
+	var efw *model.EnvoyFilterWrapper
 	defer runtime.HandleCrash(func() {
 		log.Errorf("clusters patch caused panic, so the patches did not take effect")
+		recordPatches(proxy, patchContext, efw, true, clusterPatchTypes)
 	})
 	// In case the patches cause panic, use the clusters generated before to reduce the influence.
 	out = clusters
 
-	efw := push.EnvoyFilters(proxy)
+	efw = push.EnvoyFilters(proxy)
 	if efw == nil {
 		return out
 	}
 
+	out = doClusterListOperation(patchContext, efw, clusters)
+	recordPatches(proxy, patchContext, efw, false, clusterPatchTypes)
+	return out
+}
+
+func doClusterListOperation(
+	patchContext networking.EnvoyFilter_PatchContext,
+	efw *model.EnvoyFilterWrapper,
+	clusters []*xdsapi.Cluster) []*xdsapi.Cluster {
 	clustersRemoved := false
 	for _, cp := range efw.Patches[networking.EnvoyFilter_CLUSTER] {
 		if cp.Operation != networking.EnvoyFilter_Patch_REMOVE &&
@@ -62,6 +73,7 @@ func ApplyClusterPatches(
 				} else {
 					proto.Merge(clusters[i], cp.Value)
 				}
+				cp.RecordApplied()
 			}
 		}
 	}
@@ -71,6 +83,7 @@ func ApplyClusterPatches(
 		if cp.Operation == networking.EnvoyFilter_Patch_ADD {
 			if commonConditionMatch(patchContext, cp) {
 				clusters = append(clusters, proto.Clone(cp.Value).(*xdsapi.Cluster))
+				cp.RecordApplied()
 			}
 		}
 	}
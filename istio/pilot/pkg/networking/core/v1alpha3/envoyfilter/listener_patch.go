@@ -40,18 +40,22 @@ func ApplyListenerPatches(
 	push *model.PushContext,
 	listeners []*xdsapi.Listener,
 	skipAdds bool) (out []*xdsapi.Listener) {
+	var envoyFilterWrapper *model.EnvoyFilterWrapper
 	defer runtime.HandleCrash(func() {
 		log.Errorf("listeners patch caused panic, so the patches did not take effect")
+		recordPatches(proxy, patchContext, envoyFilterWrapper, true, listenerPatchTypes)
 	})
 	// In case the patches cause panic, use the listeners generated before to reduce the influence.
 	out = listeners
 
-	envoyFilterWrapper := push.EnvoyFilters(proxy)
+	envoyFilterWrapper = push.EnvoyFilters(proxy)
 	if envoyFilterWrapper == nil {
 		return
 	}
 
-	return doListenerListOperation(patchContext, envoyFilterWrapper, listeners, skipAdds)
+	out = doListenerListOperation(patchContext, envoyFilterWrapper, listeners, skipAdds)
+	recordPatches(proxy, patchContext, envoyFilterWrapper, false, listenerPatchTypes)
+	return out
 }
 
 func doListenerListOperation(
@@ -83,6 +87,7 @@ func doListenerListOperation(
 				// clone before append. Otherwise, subsequent operations on this listener will corrupt
 				// the master value stored in CP..
 				listeners = append(listeners, proto.Clone(cp.Value).(*xdsapi.Listener))
+				cp.RecordApplied()
 			}
 		}
 	}
@@ -111,10 +116,12 @@ func doListenerOperation(patchContext networking.EnvoyFilter_PatchContext,
 		if cp.Operation == networking.EnvoyFilter_Patch_REMOVE {
 			listener.Name = ""
 			*listenersRemoved = true
+			cp.RecordApplied()
 			// terminate the function here as we have nothing more do to for this listener
 			return
 		} else if cp.Operation == networking.EnvoyFilter_Patch_MERGE {
 			proto.Merge(listener, cp.Value)
+			cp.RecordApplied()
 		}
 	}
 
@@ -138,6 +145,7 @@ func doFilterChainListOperation(patchContext networking.EnvoyFilter_PatchContext
 				continue
 			}
 			listener.FilterChains = append(listener.FilterChains, proto.Clone(cp.Value).(*xdslistener.FilterChain))
+			cp.RecordApplied()
 		}
 	}
 	if filterChainsRemoved {
@@ -164,10 +172,12 @@ func doFilterChainOperation(patchContext networking.EnvoyFilter_PatchContext,
 		if cp.Operation == networking.EnvoyFilter_Patch_REMOVE {
 			fc.Filters = nil
 			*filterChainRemoved = true
+			cp.RecordApplied()
 			// nothing more to do in other patches as we removed this filter chain
 			return
 		} else if cp.Operation == networking.EnvoyFilter_Patch_MERGE {
 			proto.Merge(fc, cp.Value)
+			cp.RecordApplied()
 		}
 	}
 	doNetworkFilterListOperation(patchContext, patches, listener, fc)
@@ -192,10 +202,12 @@ func doNetworkFilterListOperation(patchContext networking.EnvoyFilter_PatchConte
 
 		if cp.Operation == networking.EnvoyFilter_Patch_ADD {
 			fc.Filters = append(fc.Filters, proto.Clone(cp.Value).(*xdslistener.Filter))
+			cp.RecordApplied()
 		} else if cp.Operation == networking.EnvoyFilter_Patch_INSERT_AFTER {
 			// Insert after without a filter match is same as ADD in the end
 			if !hasNetworkFilterMatch(cp) {
 				fc.Filters = append(fc.Filters, proto.Clone(cp.Value).(*xdslistener.Filter))
+				cp.RecordApplied()
 				continue
 			}
 			// find the matching filter first
@@ -217,10 +229,12 @@ func doNetworkFilterListOperation(patchContext networking.EnvoyFilter_PatchConte
 				copy(fc.Filters[insertPosition+1:], fc.Filters[insertPosition:])
 				fc.Filters[insertPosition] = clonedVal
 			}
+			cp.RecordApplied()
 		} else if cp.Operation == networking.EnvoyFilter_Patch_INSERT_BEFORE {
 			// insert before without a filter match is same as insert in the beginning
 			if !hasNetworkFilterMatch(cp) {
 				fc.Filters = append([]*xdslistener.Filter{proto.Clone(cp.Value).(*xdslistener.Filter)}, fc.Filters...)
+				cp.RecordApplied()
 				continue
 			}
 			// find the matching filter first
@@ -239,6 +253,7 @@ func doNetworkFilterListOperation(patchContext networking.EnvoyFilter_PatchConte
 			fc.Filters = append(fc.Filters, clonedVal)
 			copy(fc.Filters[insertPosition+1:], fc.Filters[insertPosition:])
 			fc.Filters[insertPosition] = clonedVal
+			cp.RecordApplied()
 		}
 	}
 	if networkFiltersRemoved {
@@ -266,6 +281,7 @@ func doNetworkFilterOperation(patchContext networking.EnvoyFilter_PatchContext,
 		if cp.Operation == networking.EnvoyFilter_Patch_REMOVE {
 			filter.Name = ""
 			*networkFilterRemoved = true
+			cp.RecordApplied()
 			// nothing more to do in other patches as we removed this filter
 			return
 		} else if cp.Operation == networking.EnvoyFilter_Patch_MERGE {
@@ -277,6 +293,7 @@ func doNetworkFilterOperation(patchContext networking.EnvoyFilter_PatchContext,
 				// TODO(rshriram): fixme
 				// skip this op as we would possibly have to do a merge of Any with struct
 				// which doesn't seem to work well.
+				cp.RecordFailure()
 				continue
 			}
 			userFilter := cp.Value.(*xdslistener.Filter)
@@ -292,16 +309,21 @@ func doNetworkFilterOperation(patchContext networking.EnvoyFilter_PatchContext,
 				// user has any typed struct
 				if retVal, err = util.MergeAnyWithAny(filter.GetTypedConfig(), userFilter.GetTypedConfig()); err != nil {
 					retVal = filter.GetTypedConfig()
+					cp.RecordFailure()
 				}
 			} else if userFilter.GetConfig() != nil { //nolint:staticcheck
 				if retVal, err = util.MergeAnyWithStruct(filter.GetTypedConfig(), userFilter.GetConfig()); err != nil { //nolint:staticcheck
 					retVal = filter.GetTypedConfig()
+					cp.RecordFailure()
 				}
 			}
 			filter.Name = filterName
 			if retVal != nil {
 				filter.ConfigType = &xdslistener.Filter_TypedConfig{TypedConfig: retVal}
 			}
+			if err == nil {
+				cp.RecordApplied()
+			}
 		}
 	}
 	if filter.Name == xdsutil.HTTPConnectionManager {
@@ -342,10 +364,12 @@ func doHTTPFilterListOperation(patchContext networking.EnvoyFilter_PatchContext,
 
 		if cp.Operation == networking.EnvoyFilter_Patch_ADD {
 			hcm.HttpFilters = append(hcm.HttpFilters, proto.Clone(cp.Value).(*http_conn.HttpFilter))
+			cp.RecordApplied()
 		} else if cp.Operation == networking.EnvoyFilter_Patch_INSERT_AFTER {
 			// Insert after without a filter match is same as ADD in the end
 			if !hasHTTPFilterMatch(cp) {
 				hcm.HttpFilters = append(hcm.HttpFilters, proto.Clone(cp.Value).(*http_conn.HttpFilter))
+				cp.RecordApplied()
 				continue
 			}
 
@@ -368,10 +392,12 @@ func doHTTPFilterListOperation(patchContext networking.EnvoyFilter_PatchContext,
 				copy(hcm.HttpFilters[insertPosition+1:], hcm.HttpFilters[insertPosition:])
 				hcm.HttpFilters[insertPosition] = clonedVal
 			}
+			cp.RecordApplied()
 		} else if cp.Operation == networking.EnvoyFilter_Patch_INSERT_BEFORE {
 			// insert before without a filter match is same as insert in the beginning
 			if !hasHTTPFilterMatch(cp) {
 				hcm.HttpFilters = append([]*http_conn.HttpFilter{proto.Clone(cp.Value).(*http_conn.HttpFilter)}, hcm.HttpFilters...)
+				cp.RecordApplied()
 				continue
 			}
 
@@ -392,6 +418,7 @@ func doHTTPFilterListOperation(patchContext networking.EnvoyFilter_PatchContext,
 			hcm.HttpFilters = append(hcm.HttpFilters, clonedVal)
 			copy(hcm.HttpFilters[insertPosition+1:], hcm.HttpFilters[insertPosition:])
 			hcm.HttpFilters[insertPosition] = clonedVal
+			cp.RecordApplied()
 		}
 	}
 	if httpFiltersRemoved {
@@ -426,6 +453,7 @@ func doHTTPFilterOperation(patchContext networking.EnvoyFilter_PatchContext,
 		if cp.Operation == networking.EnvoyFilter_Patch_REMOVE {
 			httpFilter.Name = ""
 			*httpFilterRemoved = true
+			cp.RecordApplied()
 			// nothing more to do in other patches as we removed this filter
 			return
 		} else if cp.Operation == networking.EnvoyFilter_Patch_MERGE {
@@ -437,6 +465,7 @@ func doHTTPFilterOperation(patchContext networking.EnvoyFilter_PatchContext,
 				// TODO(rshriram): fixme
 				// skip this op as we would possibly have to do a merge of Any with struct
 				// which doesn't seem to work well.
+				cp.RecordFailure()
 				continue
 			}
 			userHTTPFilter := cp.Value.(*http_conn.HttpFilter)
@@ -452,16 +481,21 @@ func doHTTPFilterOperation(patchContext networking.EnvoyFilter_PatchContext,
 				// user has any typed struct
 				if retVal, err = util.MergeAnyWithAny(httpFilter.GetTypedConfig(), userHTTPFilter.GetTypedConfig()); err != nil {
 					retVal = httpFilter.GetTypedConfig()
+					cp.RecordFailure()
 				}
 			} else if userHTTPFilter.GetConfig() != nil { //nolint:staticcheck
 				if retVal, err = util.MergeAnyWithStruct(httpFilter.GetTypedConfig(), userHTTPFilter.GetConfig()); err != nil { //nolint:staticcheck
 					retVal = httpFilter.GetTypedConfig()
+					cp.RecordFailure()
 				}
 			}
 			httpFilter.Name = httpFilterName
 			if retVal != nil {
 				httpFilter.ConfigType = &http_conn.HttpFilter_TypedConfig{TypedConfig: retVal}
 			}
+			if err == nil {
+				cp.RecordApplied()
+			}
 		}
 	}
 }
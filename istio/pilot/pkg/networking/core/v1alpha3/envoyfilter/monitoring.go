@@ -0,0 +1,85 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoyfilter
+
+import (
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/pkg/monitoring"
+
+	"istio.io/istio/pilot/pkg/model"
+)
+
+var (
+	nameTag      = monitoring.MustCreateLabel("name")
+	namespaceTag = monitoring.MustCreateLabel("namespace")
+	resultTag    = monitoring.MustCreateLabel("result")
+
+	envoyFilterPatches = monitoring.NewSum(
+		"pilot_envoy_filter_patches",
+		"Outcome of the EnvoyFilter patches in the configs pushed to the proxies: applied, no_match or error.",
+		monitoring.WithLabels(nameTag, namespaceTag, resultTag),
+	)
+
+	listenerPatchTypes = []networking.EnvoyFilter_ApplyTo{
+		networking.EnvoyFilter_LISTENER,
+		networking.EnvoyFilter_FILTER_CHAIN,
+		networking.EnvoyFilter_NETWORK_FILTER,
+		networking.EnvoyFilter_HTTP_FILTER,
+	}
+	clusterPatchTypes = []networking.EnvoyFilter_ApplyTo{
+		networking.EnvoyFilter_CLUSTER,
+	}
+	routeConfigurationPatchTypes = []networking.EnvoyFilter_ApplyTo{
+		networking.EnvoyFilter_ROUTE_CONFIGURATION,
+		networking.EnvoyFilter_VIRTUAL_HOST,
+		networking.EnvoyFilter_HTTP_ROUTE,
+	}
+)
+
+func init() {
+	monitoring.MustRegister(envoyFilterPatches)
+}
+
+// recordPatches records the outcome of the patches of the given types in the EnvoyFilter status of the proxy.
+// All the patches of the patch context are recorded, the ones that were not applied do not match anything.
+func recordPatches(proxy *model.Proxy, patchContext networking.EnvoyFilter_PatchContext, efw *model.EnvoyFilterWrapper,
+	panicked bool, applyTo []networking.EnvoyFilter_ApplyTo) {
+	if proxy == nil || proxy.EnvoyFilterStatus == nil || efw == nil {
+		return
+	}
+	for _, typ := range applyTo {
+		for _, cp := range efw.Patches[typ] {
+			if !commonConditionMatch(patchContext, cp) {
+				continue
+			}
+			if panicked {
+				cp.RecordFailure()
+			}
+			proxy.EnvoyFilterStatus.Record(cp)
+		}
+	}
+}
+
+// RecordPatchMetrics records the outcome of the EnvoyFilter patches applied to the config generated for the
+// proxy since the last call in the metrics. It is called once the generated config is pushed to the proxy.
+func RecordPatchMetrics(proxy *model.Proxy) {
+	if proxy == nil || proxy.EnvoyFilterStatus == nil {
+		return
+	}
+	for _, status := range proxy.EnvoyFilterStatus.Flush() {
+		envoyFilterPatches.With(nameTag.Value(status.Name), namespaceTag.Value(status.Namespace),
+			resultTag.Value(status.Result())).Increment()
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoyfilter
+
+import (
+	"testing"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	"github.com/google/go-cmp/cmp"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/core/v1alpha3/fakes"
+)
+
+func TestRecordPatches(t *testing.T) {
+	configPatches := []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+		{
+			ApplyTo: networking.EnvoyFilter_CLUSTER,
+			Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+				Context: networking.EnvoyFilter_SIDECAR_OUTBOUND,
+			},
+			Patch: &networking.EnvoyFilter_Patch{
+				Operation: networking.EnvoyFilter_Patch_MERGE,
+				Value:     buildPatchStruct(`{"lb_policy":"RING_HASH"}`),
+			},
+		},
+		{
+			ApplyTo: networking.EnvoyFilter_CLUSTER,
+			Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+				Context: networking.EnvoyFilter_SIDECAR_OUTBOUND,
+				ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_Cluster{
+					Cluster: &networking.EnvoyFilter_ClusterMatch{Service: "missing.com"},
+				},
+			},
+			Patch: &networking.EnvoyFilter_Patch{Operation: networking.EnvoyFilter_Patch_REMOVE},
+		},
+		{
+			ApplyTo: networking.EnvoyFilter_CLUSTER,
+			Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+				Context: networking.EnvoyFilter_GATEWAY,
+			},
+			Patch: &networking.EnvoyFilter_Patch{Operation: networking.EnvoyFilter_Patch_REMOVE},
+		},
+	}
+
+	serviceDiscovery := &fakes.ServiceDiscovery{}
+	env := newTestEnvironment(serviceDiscovery, testMesh, buildEnvoyFilterConfigStore(configPatches))
+	push := model.NewPushContext()
+	push.InitContext(env, nil, nil)
+
+	proxy := &model.Proxy{
+		Type:              model.SidecarProxy,
+		ConfigNamespace:   "not-default",
+		EnvoyFilterStatus: model.NewEnvoyFilterStatus(),
+	}
+	clusters := []*xdsapi.Cluster{{Name: "outbound|80||a.com"}, {Name: "outbound|80||b.com"}}
+	// the outcomes are accumulated until the flush, and are not shared with the proxies without status
+	ApplyClusterPatches(networking.EnvoyFilter_SIDECAR_OUTBOUND, proxy, push, clusters)
+	ApplyClusterPatches(networking.EnvoyFilter_SIDECAR_OUTBOUND, &model.Proxy{
+		Type:            model.SidecarProxy,
+		ConfigNamespace: "not-default",
+	}, push, clusters)
+	ApplyClusterPatches(networking.EnvoyFilter_SIDECAR_OUTBOUND, proxy, push, clusters[:1])
+
+	want := []*model.EnvoyFilterPatchStatus{
+		{Name: "test-envoyfilter-0", Namespace: "not-default", ApplyTo: "CLUSTER", Operation: "MERGE", Applied: 3},
+		{Name: "test-envoyfilter-1", Namespace: "not-default", ApplyTo: "CLUSTER", Operation: "REMOVE"},
+	}
+	got := proxy.EnvoyFilterStatus.Flush()
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected patch status (-want +got):\n%s", diff)
+	}
+	if got[0].Result() != model.EnvoyFilterPatchApplied || got[1].Result() != model.EnvoyFilterPatchNoMatch {
+		t.Errorf("got results %s and %s", got[0].Result(), got[1].Result())
+	}
+	if pending := proxy.EnvoyFilterStatus.Flush(); len(pending) != 0 {
+		t.Errorf("got %d patch status after the flush, want none", len(pending))
+	}
+	if diff := cmp.Diff(want, proxy.EnvoyFilterStatus.Patches()); diff != "" {
+		t.Errorf("unexpected last patch status (-want +got):\n%s", diff)
+	}
+
+	RecordPatchMetrics(proxy)
+	RecordPatchMetrics(&model.Proxy{})
+}
@@ -0,0 +1,354 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoyfilter
+
+import (
+	"fmt"
+	"strings"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	xdslistener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
+	http_conn "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	"github.com/envoyproxy/go-control-plane/pkg/conversion"
+	xdsutil "github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/util"
+)
+
+// ProxyConfig is the generated config of a proxy, as returned by LDS, CDS and RDS.
+type ProxyConfig struct {
+	Listeners []*xdsapi.Listener
+	Clusters  []*xdsapi.Cluster
+	Routes    []*xdsapi.RouteConfiguration
+}
+
+// PatchReport lists the resources of a proxy config matched by a config patch of an EnvoyFilter.
+type PatchReport struct {
+	// Index is the index of the patch in the config patches of the EnvoyFilter.
+	Index     int
+	ApplyTo   networking.EnvoyFilter_ApplyTo
+	Operation networking.EnvoyFilter_Patch_Operation
+	// Matched holds the names of the matched resources. For additions, these are the resources the
+	// new objects are added to. Empty when the patch does not apply to the proxy config.
+	Matched []string
+}
+
+// Preview applies the patches of an EnvoyFilter to the config of a proxy, the same way Pilot does
+// when it generates the config. The input is left untouched: the patched config is returned along
+// with a report of the resources matched by each patch. The selection of the proxy by the workload
+// selector and the proxy match of the patches are not checked, the caller has already picked the proxy.
+func Preview(envoyFilter *model.Config, proxyType model.NodeType, in *ProxyConfig) (out *ProxyConfig, reports []*PatchReport,
+	err error) {
+	spec, ok := envoyFilter.Spec.(*networking.EnvoyFilter)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s/%s is not an EnvoyFilter", envoyFilter.Namespace, envoyFilter.Name)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			out, reports, err = nil, nil, fmt.Errorf("patches of %s/%s caused panic: %v", envoyFilter.Namespace, envoyFilter.Name, r)
+		}
+	}()
+
+	efw := model.ConvertToEnvoyFilterWrapper(envoyFilter)
+	for i, cp := range spec.ConfigPatches {
+		report := &PatchReport{Index: i, ApplyTo: cp.ApplyTo}
+		if cp.Patch != nil {
+			report.Operation = cp.Patch.Operation
+		}
+		reports = append(reports, report)
+	}
+	reporter := patchReporter{}
+	for _, cps := range efw.Patches {
+		for _, cp := range cps {
+			reporter[cp] = reports[cp.Index]
+		}
+	}
+
+	out = &ProxyConfig{}
+	if proxyType == model.Router {
+		listeners := cloneListeners(in.Listeners)
+		reporter.reportListeners(networking.EnvoyFilter_GATEWAY, efw.Patches, listeners, false)
+		out.Listeners = doListenerListOperation(networking.EnvoyFilter_GATEWAY, efw, listeners, false)
+
+		clusters := cloneClusters(in.Clusters)
+		reporter.reportClusters(networking.EnvoyFilter_GATEWAY, efw.Patches, clusters)
+		out.Clusters = doClusterListOperation(networking.EnvoyFilter_GATEWAY, efw, clusters)
+
+		for _, rc := range in.Routes {
+			rc = proto.Clone(rc).(*xdsapi.RouteConfiguration)
+			reporter.reportRouteConfiguration(networking.EnvoyFilter_GATEWAY, efw.Patches, rc)
+			out.Routes = append(out.Routes, doRouteConfigurationOperation(networking.EnvoyFilter_GATEWAY, efw, rc))
+		}
+		return out, reports, nil
+	}
+
+	// sidecars: the virtual listeners are patched one by one without additions, like in the listener builder
+	var inboundListeners, outboundListeners []*xdsapi.Listener
+	for _, l := range cloneListeners(in.Listeners) {
+		patchContext := listenerPatchContext(l)
+		if l.GetUseOriginalDst().GetValue() {
+			reporter.reportListeners(patchContext, efw.Patches, []*xdsapi.Listener{l}, true)
+			out.Listeners = append(out.Listeners, doListenerListOperation(patchContext, efw, []*xdsapi.Listener{l}, true)...)
+		} else if patchContext == networking.EnvoyFilter_SIDECAR_INBOUND {
+			inboundListeners = append(inboundListeners, l)
+		} else {
+			outboundListeners = append(outboundListeners, l)
+		}
+	}
+	reporter.reportListeners(networking.EnvoyFilter_SIDECAR_INBOUND, efw.Patches, inboundListeners, false)
+	out.Listeners = append(out.Listeners,
+		doListenerListOperation(networking.EnvoyFilter_SIDECAR_INBOUND, efw, inboundListeners, false)...)
+	reporter.reportListeners(networking.EnvoyFilter_SIDECAR_OUTBOUND, efw.Patches, outboundListeners, false)
+	out.Listeners = append(out.Listeners,
+		doListenerListOperation(networking.EnvoyFilter_SIDECAR_OUTBOUND, efw, outboundListeners, false)...)
+
+	var inboundClusters, outboundClusters []*xdsapi.Cluster
+	for _, c := range cloneClusters(in.Clusters) {
+		if strings.HasPrefix(c.Name, string(model.TrafficDirectionInbound)+"|") ||
+			strings.HasPrefix(c.Name, util.InboundPassthroughClusterIpv4) ||
+			strings.HasPrefix(c.Name, util.InboundPassthroughClusterIpv6) {
+			inboundClusters = append(inboundClusters, c)
+		} else {
+			outboundClusters = append(outboundClusters, c)
+		}
+	}
+	reporter.reportClusters(networking.EnvoyFilter_SIDECAR_OUTBOUND, efw.Patches, outboundClusters)
+	out.Clusters = doClusterListOperation(networking.EnvoyFilter_SIDECAR_OUTBOUND, efw, outboundClusters)
+	reporter.reportClusters(networking.EnvoyFilter_SIDECAR_INBOUND, efw.Patches, inboundClusters)
+	out.Clusters = append(out.Clusters, doClusterListOperation(networking.EnvoyFilter_SIDECAR_INBOUND, efw, inboundClusters)...)
+
+	// the inbound routes of sidecars are part of the inbound listeners, RDS only serves outbound routes
+	for _, rc := range in.Routes {
+		rc = proto.Clone(rc).(*xdsapi.RouteConfiguration)
+		reporter.reportRouteConfiguration(networking.EnvoyFilter_SIDECAR_OUTBOUND, efw.Patches, rc)
+		out.Routes = append(out.Routes, doRouteConfigurationOperation(networking.EnvoyFilter_SIDECAR_OUTBOUND, efw, rc))
+	}
+	return out, reports, nil
+}
+
+func listenerPatchContext(l *xdsapi.Listener) networking.EnvoyFilter_PatchContext {
+	if l.TrafficDirection == core.TrafficDirection_INBOUND {
+		return networking.EnvoyFilter_SIDECAR_INBOUND
+	}
+	return networking.EnvoyFilter_SIDECAR_OUTBOUND
+}
+
+func cloneListeners(in []*xdsapi.Listener) []*xdsapi.Listener {
+	out := make([]*xdsapi.Listener, 0, len(in))
+	for _, l := range in {
+		out = append(out, proto.Clone(l).(*xdsapi.Listener))
+	}
+	return out
+}
+
+func cloneClusters(in []*xdsapi.Cluster) []*xdsapi.Cluster {
+	out := make([]*xdsapi.Cluster, 0, len(in))
+	for _, c := range in {
+		out = append(out, proto.Clone(c).(*xdsapi.Cluster))
+	}
+	return out
+}
+
+// patchReporter records the resources matched by the patches. It walks the config before it is
+// patched, with the same match conditions as the patch operations.
+type patchReporter map[*model.EnvoyFilterConfigPatchWrapper]*PatchReport
+
+func (r patchReporter) match(cp *model.EnvoyFilterConfigPatchWrapper, name string) {
+	if report := r[cp]; report != nil {
+		report.Matched = append(report.Matched, name)
+	}
+}
+
+// isInsert returns true if the patch inserts a new object next to the objects it matches.
+func isInsert(cp *model.EnvoyFilterConfigPatchWrapper) bool {
+	return cp.Operation == networking.EnvoyFilter_Patch_INSERT_BEFORE || cp.Operation == networking.EnvoyFilter_Patch_INSERT_AFTER
+}
+
+func (r patchReporter) reportListeners(patchContext networking.EnvoyFilter_PatchContext,
+	patches map[networking.EnvoyFilter_ApplyTo][]*model.EnvoyFilterConfigPatchWrapper,
+	listeners []*xdsapi.Listener, skipAdds bool) {
+	for _, cp := range patches[networking.EnvoyFilter_LISTENER] {
+		if !commonConditionMatch(patchContext, cp) {
+			continue
+		}
+		if cp.Operation == networking.EnvoyFilter_Patch_ADD {
+			if !skipAdds {
+				r.match(cp, fmt.Sprintf("%s listeners", strings.ToLower(patchContext.String())))
+			}
+			continue
+		}
+		for _, listener := range listeners {
+			if listenerMatch(listener, cp) {
+				r.match(cp, "listener "+listener.Name)
+			}
+		}
+	}
+
+	for _, listener := range listeners {
+		name := "listener " + listener.Name
+		for _, cp := range patches[networking.EnvoyFilter_FILTER_CHAIN] {
+			if cp.Operation == networking.EnvoyFilter_Patch_ADD && commonConditionMatch(patchContext, cp) && listenerMatch(listener, cp) {
+				r.match(cp, name)
+			}
+		}
+		for i, fc := range listener.FilterChains {
+			r.reportFilterChain(patchContext, patches, listener, fc, fmt.Sprintf("%s/filter chain %d", name, i))
+		}
+	}
+}
+
+func (r patchReporter) reportFilterChain(patchContext networking.EnvoyFilter_PatchContext,
+	patches map[networking.EnvoyFilter_ApplyTo][]*model.EnvoyFilterConfigPatchWrapper,
+	listener *xdsapi.Listener, fc *xdslistener.FilterChain, name string) {
+	for _, cp := range patches[networking.EnvoyFilter_FILTER_CHAIN] {
+		if cp.Operation != networking.EnvoyFilter_Patch_ADD && commonConditionMatch(patchContext, cp) &&
+			listenerMatch(listener, cp) && filterChainMatch(fc, cp) {
+			r.match(cp, name)
+		}
+	}
+
+	for _, cp := range patches[networking.EnvoyFilter_NETWORK_FILTER] {
+		if !commonConditionMatch(patchContext, cp) || !listenerMatch(listener, cp) || !filterChainMatch(fc, cp) {
+			continue
+		}
+		if cp.Operation == networking.EnvoyFilter_Patch_ADD || (isInsert(cp) && !hasNetworkFilterMatch(cp)) {
+			r.match(cp, name)
+			continue
+		}
+		for _, filter := range fc.Filters {
+			if networkFilterMatch(filter, cp) {
+				r.match(cp, name+"/"+filter.Name)
+				if isInsert(cp) {
+					// new filters are only inserted next to the first match
+					break
+				}
+			}
+		}
+	}
+
+	for _, filter := range fc.Filters {
+		if filter.Name == xdsutil.HTTPConnectionManager {
+			r.reportHTTPFilters(patchContext, patches, listener, fc, filter, name+"/"+filter.Name)
+		}
+	}
+}
+
+func (r patchReporter) reportHTTPFilters(patchContext networking.EnvoyFilter_PatchContext,
+	patches map[networking.EnvoyFilter_ApplyTo][]*model.EnvoyFilterConfigPatchWrapper,
+	listener *xdsapi.Listener, fc *xdslistener.FilterChain, filter *xdslistener.Filter, name string) {
+	hcm := &http_conn.HttpConnectionManager{}
+	if filter.GetTypedConfig() != nil {
+		if err := ptypes.UnmarshalAny(filter.GetTypedConfig(), hcm); err != nil {
+			return
+		}
+	} else {
+		// nolint: staticcheck
+		if err := conversion.StructToMessage(filter.GetConfig(), hcm); err != nil {
+			return
+		}
+	}
+
+	for _, cp := range patches[networking.EnvoyFilter_HTTP_FILTER] {
+		if !commonConditionMatch(patchContext, cp) || !listenerMatch(listener, cp) || !filterChainMatch(fc, cp) ||
+			!networkFilterMatch(filter, cp) {
+			continue
+		}
+		if cp.Operation == networking.EnvoyFilter_Patch_ADD || (isInsert(cp) && !hasHTTPFilterMatch(cp)) {
+			r.match(cp, name)
+			continue
+		}
+		for _, httpFilter := range hcm.HttpFilters {
+			if httpFilterMatch(httpFilter, cp) {
+				r.match(cp, name+"/"+httpFilter.Name)
+				if isInsert(cp) {
+					break
+				}
+			}
+		}
+	}
+}
+
+func (r patchReporter) reportClusters(patchContext networking.EnvoyFilter_PatchContext,
+	patches map[networking.EnvoyFilter_ApplyTo][]*model.EnvoyFilterConfigPatchWrapper, clusters []*xdsapi.Cluster) {
+	for _, cp := range patches[networking.EnvoyFilter_CLUSTER] {
+		if !commonConditionMatch(patchContext, cp) {
+			continue
+		}
+		if cp.Operation == networking.EnvoyFilter_Patch_ADD {
+			r.match(cp, fmt.Sprintf("%s clusters", strings.ToLower(patchContext.String())))
+			continue
+		}
+		for _, cluster := range clusters {
+			if clusterMatch(cluster, cp) {
+				r.match(cp, "cluster "+cluster.Name)
+			}
+		}
+	}
+}
+
+func (r patchReporter) reportRouteConfiguration(patchContext networking.EnvoyFilter_PatchContext,
+	patches map[networking.EnvoyFilter_ApplyTo][]*model.EnvoyFilterConfigPatchWrapper, rc *xdsapi.RouteConfiguration) {
+	name := "route " + rc.Name
+	for _, cp := range patches[networking.EnvoyFilter_ROUTE_CONFIGURATION] {
+		if cp.Operation == networking.EnvoyFilter_Patch_MERGE && commonConditionMatch(patchContext, cp) &&
+			routeConfigurationMatch(patchContext, rc, cp) {
+			r.match(cp, name)
+		}
+	}
+
+	for _, cp := range patches[networking.EnvoyFilter_VIRTUAL_HOST] {
+		if !commonConditionMatch(patchContext, cp) || !routeConfigurationMatch(patchContext, rc, cp) {
+			continue
+		}
+		if cp.Operation == networking.EnvoyFilter_Patch_ADD {
+			r.match(cp, name)
+			continue
+		}
+		for _, vh := range rc.VirtualHosts {
+			if virtualHostMatch(vh, cp) {
+				r.match(cp, name+"/"+vh.Name)
+			}
+		}
+	}
+
+	for _, vh := range rc.VirtualHosts {
+		vhName := name + "/" + vh.Name
+		for _, cp := range patches[networking.EnvoyFilter_HTTP_ROUTE] {
+			if !commonConditionMatch(patchContext, cp) || !routeConfigurationMatch(patchContext, rc, cp) ||
+				!virtualHostMatch(vh, cp) {
+				continue
+			}
+			if cp.Operation == networking.EnvoyFilter_Patch_ADD {
+				r.match(cp, vhName)
+				continue
+			}
+			for i, httpRoute := range vh.Routes {
+				if routeMatch(httpRoute, cp) {
+					routeName := httpRoute.Name
+					if routeName == "" {
+						routeName = fmt.Sprintf("route %d", i)
+					}
+					r.match(cp, vhName+"/"+routeName)
+				}
+			}
+		}
+	}
+}
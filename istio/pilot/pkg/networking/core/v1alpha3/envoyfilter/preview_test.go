@@ -0,0 +1,202 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoyfilter
+
+import (
+	"reflect"
+	"testing"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
+	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	http_conn "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	xdsutil "github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/protobuf/ptypes"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/util"
+)
+
+func TestPreview(t *testing.T) {
+	envoyFilter := &model.Config{
+		ConfigMeta: model.ConfigMeta{Name: "preview", Namespace: "default"},
+		Spec: &networking.EnvoyFilter{
+			ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+				{
+					ApplyTo: networking.EnvoyFilter_HTTP_FILTER,
+					Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+						Context: networking.EnvoyFilter_SIDECAR_INBOUND,
+						ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_Listener{
+							Listener: &networking.EnvoyFilter_ListenerMatch{
+								PortNumber: 8080,
+								FilterChain: &networking.EnvoyFilter_ListenerMatch_FilterChainMatch{
+									Filter: &networking.EnvoyFilter_ListenerMatch_FilterMatch{
+										Name:      xdsutil.HTTPConnectionManager,
+										SubFilter: &networking.EnvoyFilter_ListenerMatch_SubFilterMatch{Name: xdsutil.Router},
+									},
+								},
+							},
+						},
+					},
+					Patch: &networking.EnvoyFilter_Patch{
+						Operation: networking.EnvoyFilter_Patch_INSERT_BEFORE,
+						Value:     buildPatchStruct(`{"name":"envoy.lua"}`),
+					},
+				},
+				{
+					ApplyTo: networking.EnvoyFilter_CLUSTER,
+					Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+						Context: networking.EnvoyFilter_SIDECAR_OUTBOUND,
+						ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_Cluster{
+							Cluster: &networking.EnvoyFilter_ClusterMatch{Service: "reviews.default.svc.cluster.local"},
+						},
+					},
+					Patch: &networking.EnvoyFilter_Patch{
+						Operation: networking.EnvoyFilter_Patch_MERGE,
+						Value:     buildPatchStruct(`{"lb_policy":"RING_HASH"}`),
+					},
+				},
+				{
+					ApplyTo: networking.EnvoyFilter_CLUSTER,
+					Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+						Context: networking.EnvoyFilter_SIDECAR_OUTBOUND,
+						ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_Cluster{
+							Cluster: &networking.EnvoyFilter_ClusterMatch{Service: "ratings.default.svc.cluster.local"},
+						},
+					},
+					Patch: &networking.EnvoyFilter_Patch{Operation: networking.EnvoyFilter_Patch_REMOVE},
+				},
+				{
+					ApplyTo: networking.EnvoyFilter_HTTP_ROUTE,
+					Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+						Context: networking.EnvoyFilter_SIDECAR_OUTBOUND,
+						ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_RouteConfiguration{
+							RouteConfiguration: &networking.EnvoyFilter_RouteConfigurationMatch{
+								PortNumber: 9080,
+								Vhost: &networking.EnvoyFilter_RouteConfigurationMatch_VirtualHostMatch{
+									Route: &networking.EnvoyFilter_RouteConfigurationMatch_RouteMatch{Name: "default"},
+								},
+							},
+						},
+					},
+					Patch: &networking.EnvoyFilter_Patch{
+						Operation: networking.EnvoyFilter_Patch_MERGE,
+						Value:     buildPatchStruct(`{"decorator":{"operation":"preview"}}`),
+					},
+				},
+				{
+					ApplyTo: networking.EnvoyFilter_LISTENER,
+					Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+						Context: networking.EnvoyFilter_GATEWAY,
+					},
+					Patch: &networking.EnvoyFilter_Patch{Operation: networking.EnvoyFilter_Patch_REMOVE},
+				},
+			},
+		},
+	}
+
+	hcm := &http_conn.HttpConnectionManager{
+		HttpFilters: []*http_conn.HttpFilter{{Name: xdsutil.Fault}, {Name: xdsutil.Router}},
+	}
+	in := &ProxyConfig{
+		Listeners: []*xdsapi.Listener{
+			{
+				Name:             "10.0.0.1_8080",
+				TrafficDirection: core.TrafficDirection_INBOUND,
+				Address: &core.Address{Address: &core.Address_SocketAddress{
+					SocketAddress: &core.SocketAddress{PortSpecifier: &core.SocketAddress_PortValue{PortValue: 8080}},
+				}},
+				FilterChains: []*listener.FilterChain{{
+					Filters: []*listener.Filter{{
+						Name:       xdsutil.HTTPConnectionManager,
+						ConfigType: &listener.Filter_TypedConfig{TypedConfig: util.MessageToAny(hcm)},
+					}},
+				}},
+			},
+		},
+		Clusters: []*xdsapi.Cluster{
+			{Name: "outbound|9080||reviews.default.svc.cluster.local"},
+			{Name: "outbound|9080||ratings.default.svc.cluster.local"},
+			{Name: "inbound|8080|http|reviews.default.svc.cluster.local"},
+		},
+		Routes: []*xdsapi.RouteConfiguration{
+			{
+				Name: "9080",
+				VirtualHosts: []*route.VirtualHost{{
+					Name:   "reviews.default.svc.cluster.local:9080",
+					Routes: []*route.Route{{Name: "default"}},
+				}},
+			},
+		},
+	}
+
+	out, reports, err := Preview(envoyFilter, model.SidecarProxy, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantMatched := [][]string{
+		{"listener 10.0.0.1_8080/filter chain 0/envoy.http_connection_manager/envoy.router"},
+		{"cluster outbound|9080||reviews.default.svc.cluster.local"},
+		{"cluster outbound|9080||ratings.default.svc.cluster.local"},
+		{"route 9080/reviews.default.svc.cluster.local:9080/default"},
+		nil,
+	}
+	if len(reports) != len(wantMatched) {
+		t.Fatalf("got %d reports, want %d", len(reports), len(wantMatched))
+	}
+	for i, report := range reports {
+		if report.Index != i {
+			t.Errorf("report %d: got index %d", i, report.Index)
+		}
+		if !reflect.DeepEqual(report.Matched, wantMatched[i]) {
+			t.Errorf("report %d: got matched %v, want %v", i, report.Matched, wantMatched[i])
+		}
+	}
+	if reports[0].Operation != networking.EnvoyFilter_Patch_INSERT_BEFORE {
+		t.Errorf("got operation %v, want the operation of the patch", reports[0].Operation)
+	}
+
+	if len(out.Clusters) != 2 || out.Clusters[0].LbPolicy != xdsapi.Cluster_RING_HASH {
+		t.Errorf("unexpected clusters %v", out.Clusters)
+	}
+	if in.Clusters[0].LbPolicy == xdsapi.Cluster_RING_HASH || len(in.Clusters) != 3 {
+		t.Errorf("input clusters were modified")
+	}
+	if got := out.Routes[0].VirtualHosts[0].Routes[0].GetDecorator().GetOperation(); got != "preview" {
+		t.Errorf("got decorator %q, want preview", got)
+	}
+
+	gotHCM := &http_conn.HttpConnectionManager{}
+	if err := ptypes.UnmarshalAny(out.Listeners[0].FilterChains[0].Filters[0].GetTypedConfig(), gotHCM); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range gotHCM.HttpFilters {
+		names = append(names, f.Name)
+	}
+	if want := []string{xdsutil.Fault, "envoy.lua", xdsutil.Router}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got http filters %v, want %v", names, want)
+	}
+}
+
+func TestPreviewNotEnvoyFilter(t *testing.T) {
+	if _, _, err := Preview(&model.Config{Spec: &networking.Gateway{}}, model.Router, &ProxyConfig{}); err == nil {
+		t.Errorf("expected an error")
+	}
+}
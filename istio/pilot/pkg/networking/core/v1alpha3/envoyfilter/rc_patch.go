@@ -33,17 +33,28 @@ func ApplyRouteConfigurationPatches(
 	proxy *model.Proxy,
 	push *model.PushContext,
 	routeConfiguration *xdsapi.RouteConfiguration) (out *xdsapi.RouteConfiguration) {
+	var efw *model.EnvoyFilterWrapper
 	defer runtime.HandleCrash(func() {
 		log.Errorf("listeners patch caused panic, so the patches did not take effect")
+		recordPatches(proxy, patchContext, efw, true, routeConfigurationPatchTypes)
 	})
 	// In case the patches cause panic, use the route generated before to reduce the influence.
 	out = routeConfiguration
 
-	efw := push.EnvoyFilters(proxy)
+	efw = push.EnvoyFilters(proxy)
 	if efw == nil {
 		return out
 	}
 
+	out = doRouteConfigurationOperation(patchContext, efw, routeConfiguration)
+	recordPatches(proxy, patchContext, efw, false, routeConfigurationPatchTypes)
+	return out
+}
+
+func doRouteConfigurationOperation(
+	patchContext networking.EnvoyFilter_PatchContext,
+	efw *model.EnvoyFilterWrapper,
+	routeConfiguration *xdsapi.RouteConfiguration) *xdsapi.RouteConfiguration {
 	// only merge is applicable for route configuration.
 	for _, cp := range efw.Patches[networking.EnvoyFilter_ROUTE_CONFIGURATION] {
 		if cp.Operation != networking.EnvoyFilter_Patch_MERGE {
@@ -53,6 +64,7 @@ func ApplyRouteConfigurationPatches(
 		if commonConditionMatch(patchContext, cp) &&
 			routeConfigurationMatch(patchContext, routeConfiguration, cp) {
 			proto.Merge(routeConfiguration, cp.Value)
+			cp.RecordApplied()
 		}
 	}
 
@@ -79,6 +91,7 @@ func doVirtualHostListOperation(patchContext networking.EnvoyFilter_PatchContext
 		if commonConditionMatch(patchContext, cp) &&
 			routeConfigurationMatch(patchContext, routeConfiguration, cp) {
 			routeConfiguration.VirtualHosts = append(routeConfiguration.VirtualHosts, proto.Clone(cp.Value).(*route.VirtualHost))
+			cp.RecordApplied()
 		}
 	}
 
@@ -106,10 +119,12 @@ func doVirtualHostOperation(patchContext networking.EnvoyFilter_PatchContext,
 			if cp.Operation == networking.EnvoyFilter_Patch_REMOVE {
 				virtualHost.Name = ""
 				*virtualHostRemoved = true
+				cp.RecordApplied()
 				// nothing more to do.
 				return
 			} else if cp.Operation == networking.EnvoyFilter_Patch_MERGE {
 				proto.Merge(virtualHost, cp.Value)
+				cp.RecordApplied()
 			}
 		}
 	}
@@ -135,6 +150,7 @@ func doHTTPRouteListOperation(patchContext networking.EnvoyFilter_PatchContext,
 			routeConfigurationMatch(patchContext, routeConfiguration, cp) &&
 			virtualHostMatch(virtualHost, cp) {
 			virtualHost.Routes = append(virtualHost.Routes, proto.Clone(cp.Value).(*route.Route))
+			cp.RecordApplied()
 		}
 	}
 
@@ -164,9 +180,11 @@ func doHTTPRouteOperation(patchContext networking.EnvoyFilter_PatchContext,
 			if cp.Operation == networking.EnvoyFilter_Patch_REMOVE {
 				virtualHost.Routes[routeIndex] = nil
 				*routesRemoved = true
+				cp.RecordApplied()
 				return
 			} else if cp.Operation == networking.EnvoyFilter_Patch_MERGE {
 				proto.Merge(virtualHost.Routes[routeIndex], cp.Value)
+				cp.RecordApplied()
 			}
 		}
 	}
@@ -98,6 +98,9 @@ func (configgen *ConfigGeneratorImpl) buildGatewayListeners(
 						server, map[string]bool{mergedGateway.GatewayNameForServer[server]: true})...)
 				}
 			}
+			// add the filter chains of the certificates discovered for the HTTPS servers
+			filterChainOpts = append(filterChainOpts,
+				configgen.createGatewayCertificateFilterChainOpts(node, push, mergedGateway, servers)...)
 			opts.filterChainOpts = filterChainOpts
 		}
 
@@ -265,6 +268,9 @@ func (configgen *ConfigGeneratorImpl) buildGatewayHTTPRouteConfig(node *model.Pr
 					if server.Tls != nil && server.Tls.HttpsRedirect {
 						newVHost.RequireTls = route.VirtualHost_ALL
 					}
+					if policy := merged.SecurityPolicyForServer[server]; policy != nil {
+						applyGatewaySecurityPolicy(newVHost, hostname, server, policy, merged)
+					}
 					vHostDedupMap[hostname] = newVHost
 				}
 			}
@@ -325,6 +331,43 @@ func (configgen *ConfigGeneratorImpl) buildGatewayHTTPRouteConfig(node *model.Pr
 	return routeCfg
 }
 
+// applyGatewaySecurityPolicy sets the security headers of the policy on the responses of the virtual host, and
+// redirects its plain text requests to HTTPS if the host is also served by a TLS server of the gateway.
+func applyGatewaySecurityPolicy(vHost *route.VirtualHost, hostname host.Name, server *networking.Server,
+	policy *model.GatewaySecurityPolicy, merged *model.MergedGateway) {
+	overTLS := gateway.IsTLSServer(server)
+	if policy.HTTPSRedirect && !overTLS && isServedOverTLS(hostname, merged) {
+		vHost.RequireTls = route.VirtualHost_ALL
+	}
+	for _, h := range policy.Headers {
+		// the browsers ignore HSTS over plain text, RFC 6797 forbids sending it
+		if h.Name == model.StrictTransportSecurityHeader && !overTLS {
+			continue
+		}
+		vHost.ResponseHeadersToAdd = append(vHost.ResponseHeadersToAdd, &core.HeaderValueOption{
+			Header: &core.HeaderValue{Key: h.Name, Value: h.Value},
+			Append: proto.BoolFalse,
+		})
+	}
+}
+
+// isServedOverTLS returns true if all the requests of the host are served by a TLS server of the gateway.
+func isServedOverTLS(hostname host.Name, merged *model.MergedGateway) bool {
+	for _, servers := range merged.Servers {
+		for _, server := range servers {
+			if !gateway.IsTLSServer(server) {
+				continue
+			}
+			for _, h := range getSNIHostsForServer(server) {
+				if hostname.SubsetOf(host.Name(h)) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 // builds a HTTP connection manager for servers of type HTTP or HTTPS (mode: simple/mutual)
 func (configgen *ConfigGeneratorImpl) createGatewayHTTPFilterChainOpts(
 	node *model.Proxy, server *networking.Server, routeName string, sdsPath string) *filterChainOpts {
@@ -399,6 +442,85 @@ func (configgen *ConfigGeneratorImpl) createGatewayHTTPFilterChainOpts(
 	}
 }
 
+// builds a HTTP connection manager for each certificate discovered for the HTTPS servers of a port (mode: simple/mutual),
+// matching the SNI of the DNS names of the certificate the server is the most specific server of. The SDS of the gateway
+// serves the certificate, so the certificates are only discovered for SDS enabled ingress gateways.
+func (configgen *ConfigGeneratorImpl) createGatewayCertificateFilterChainOpts(node *model.Proxy, push *model.PushContext,
+	mergedGateway *model.MergedGateway, servers []*networking.Server) []*filterChainOpts {
+	if push.GatewayCertificates == nil || len(mergedGateway.CredentialSelectorForServer) == 0 {
+		return nil
+	}
+	if enableIngressSdsAgent, _ := strconv.ParseBool(node.Metadata.UserSds); !enableIngressSdsAgent {
+		return nil
+	}
+
+	// Envoy rejects filter chains with the same SNI, so a host is only matched by a single filter chain
+	usedHosts := make(map[string]bool)
+	for _, server := range servers {
+		for _, h := range getSNIHostsForServer(server) {
+			usedHosts[h] = true
+		}
+	}
+	out := make([]*filterChainOpts, 0)
+	for _, server := range servers {
+		selector, ok := mergedGateway.CredentialSelectorForServer[server]
+		if !ok {
+			continue
+		}
+		routeName := mergedGateway.RouteNamesByServer[server]
+		for _, cert := range push.GatewayCertificates.GatewayCertificates(node.ConfigNamespace, selector) {
+			sniHosts := make([]string, 0, len(cert.Hosts))
+			for _, h := range cert.Hosts {
+				if !usedHosts[h] && isMostSpecificServerForHost(host.Name(h), server, servers) {
+					usedHosts[h] = true
+					sniHosts = append(sniHosts, h)
+				}
+			}
+			if len(sniHosts) == 0 {
+				log.Debugf("skipping gateway certificate %s for server %v: no host to match", cert.Name, server.Hosts)
+				continue
+			}
+
+			// the certificate is the credential of a copy of the server
+			certServer := *server
+			tls := *server.Tls
+			tls.CredentialName = cert.Name
+			certServer.Tls = &tls
+			opts := configgen.createGatewayHTTPFilterChainOpts(node, &certServer, routeName, push.Mesh.SdsUdsPath)
+			opts.sniHosts = sniHosts
+			out = append(out, opts)
+		}
+	}
+	return out
+}
+
+// isMostSpecificServerForHost returns true if the server has a host matching h, and no other server
+// has a host matching h that is more specific.
+func isMostSpecificServerForHost(h host.Name, server *networking.Server, servers []*networking.Server) bool {
+	var serverHost host.Name
+	found := false
+	for _, sh := range getSNIHostsForServer(server) {
+		if h.SubsetOf(host.Name(sh)) && (!found || host.Name(sh).SubsetOf(serverHost)) {
+			serverHost = host.Name(sh)
+			found = true
+		}
+	}
+	if !found {
+		return false
+	}
+	for _, other := range servers {
+		if other == server {
+			continue
+		}
+		for _, oh := range getSNIHostsForServer(other) {
+			if h.SubsetOf(host.Name(oh)) && host.Name(oh).SubsetOf(serverHost) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // enableIngressSds: signifies whether this is an SDS enabled ingress controller, with an embedded node agent running
 // alongside the gateway pod (https://istio.io/docs/tasks/traffic-management/ingress/secure-ingress-sds/)
 // sdsPath: is the path to the mesh-wide workload sds uds path, and it is assumed that if this path is unset, that sds is
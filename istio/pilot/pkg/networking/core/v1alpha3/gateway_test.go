@@ -20,8 +20,10 @@ import (
 
 	auth "github.com/envoyproxy/go-control-plane/envoy/api/v2/auth"
 	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
 	http_conn "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
 
+	meshconfig "istio.io/api/mesh/v1alpha1"
 	networking "istio.io/api/networking/v1alpha3"
 
 	"istio.io/istio/pilot/pkg/features"
@@ -31,6 +33,7 @@ import (
 	"istio.io/istio/pilot/pkg/networking/util"
 	"istio.io/istio/pilot/pkg/security/model"
 	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/labels"
 	"istio.io/istio/pkg/config/mesh"
 	"istio.io/istio/pkg/config/schemas"
 	"istio.io/istio/pkg/proto"
@@ -812,6 +815,96 @@ func TestCreateGatewayHTTPFilterChainOpts(t *testing.T) {
 	}
 }
 
+type fakeGatewayCertificates struct {
+	certs map[string][]*pilot_model.GatewayCertificate
+}
+
+func (f *fakeGatewayCertificates) GatewayCertificates(namespace string, selector labels.Instance) []*pilot_model.GatewayCertificate {
+	return f.certs[namespace+"/"+selector.String()]
+}
+
+func TestCreateGatewayCertificateFilterChainOpts(t *testing.T) {
+	tenantGateway := pilot_model.Config{
+		ConfigMeta: pilot_model.ConfigMeta{
+			Name:        "tenants",
+			Namespace:   "istio-system",
+			Annotations: map[string]string{pilot_model.GatewayCredentialSelectorAnnotation: "gateway-certificate=tenant"},
+		},
+		Spec: &networking.Gateway{
+			Servers: []*networking.Server{
+				{
+					Hosts: []string{"*"},
+					Port:  &networking.Port{Name: "https-tenants", Number: 443, Protocol: "HTTPS"},
+					Tls:   &networking.Server_TLSOptions{Mode: networking.Server_TLSOptions_SIMPLE, CredentialName: "default"},
+				},
+			},
+		},
+	}
+	otherGateway := pilot_model.Config{
+		ConfigMeta: pilot_model.ConfigMeta{Name: "other", Namespace: "istio-system"},
+		Spec: &networking.Gateway{
+			Servers: []*networking.Server{
+				{
+					Hosts: []string{"a.example.com"},
+					Port:  &networking.Port{Name: "https-other", Number: 443, Protocol: "HTTPS"},
+					Tls:   &networking.Server_TLSOptions{Mode: networking.Server_TLSOptions_SIMPLE, CredentialName: "other"},
+				},
+			},
+		},
+	}
+	merged := pilot_model.MergeGateways(tenantGateway, otherGateway)
+
+	push := pilot_model.NewPushContext()
+	push.Mesh = &meshconfig.MeshConfig{}
+	push.GatewayCertificates = &fakeGatewayCertificates{certs: map[string][]*pilot_model.GatewayCertificate{
+		"istio-system/gateway-certificate=tenant": {
+			{Name: "tenant-a", Hosts: []string{"a.example.com", "b.example.com"}},
+			{Name: "tenant-b", Hosts: []string{"*.c.example.com", "b.example.com"}},
+			{Name: "tenant-d", Hosts: []string{"a.example.com"}},
+		},
+	}}
+
+	cgi := NewConfigGenerator([]plugin.Plugin{})
+	node := &pilot_model.Proxy{
+		ConfigNamespace: "istio-system",
+		Metadata:        &pilot_model.NodeMetadata{UserSds: "true"},
+	}
+	opts := cgi.createGatewayCertificateFilterChainOpts(node, push, merged, merged.Servers[443])
+	want := []struct {
+		credential string
+		sniHosts   []string
+	}{
+		{"tenant-a", []string{"b.example.com"}},
+		{"tenant-b", []string{"*.c.example.com"}},
+	}
+	if len(opts) != len(want) {
+		t.Fatalf("got %d filter chains, want %d", len(opts), len(want))
+	}
+	for i, w := range want {
+		if !reflect.DeepEqual(opts[i].sniHosts, w.sniHosts) {
+			t.Errorf("filter chain %d: got SNI hosts %v, want %v", i, opts[i].sniHosts, w.sniHosts)
+		}
+		sds := opts[i].tlsContext.GetCommonTlsContext().GetTlsCertificateSdsSecretConfigs()
+		if len(sds) != 1 || sds[0].Name != w.credential {
+			t.Errorf("filter chain %d: got SDS secret configs %v, want %s", i, sds, w.credential)
+		}
+		if opts[i].httpOpts.rds != merged.RouteNamesByServer[tenantGateway.Spec.(*networking.Gateway).Servers[0]] {
+			t.Errorf("filter chain %d: got route %s, want the route of the tenants server", i, opts[i].httpOpts.rds)
+		}
+	}
+	if credential := tenantGateway.Spec.(*networking.Gateway).Servers[0].Tls.CredentialName; credential != "default" {
+		t.Errorf("the credential of the server was changed to %s", credential)
+	}
+
+	// the certificates are served by the SDS of the gateway
+	if opts := cgi.createGatewayCertificateFilterChainOpts(&pilot_model.Proxy{
+		ConfigNamespace: "istio-system",
+		Metadata:        &pilot_model.NodeMetadata{},
+	}, push, merged, merged.Servers[443]); len(opts) != 0 {
+		t.Errorf("got %d filter chains for a gateway without SDS, want none", len(opts))
+	}
+}
+
 func TestGatewayHTTPRouteConfig(t *testing.T) {
 	httpGateway := pilot_model.Config{
 		ConfigMeta: pilot_model.ConfigMeta{
@@ -945,6 +1038,121 @@ func TestGatewayHTTPRouteConfig(t *testing.T) {
 
 }
 
+func TestGatewayHTTPRouteConfigSecurityPolicy(t *testing.T) {
+	gw := pilot_model.Config{
+		ConfigMeta: pilot_model.ConfigMeta{
+			Name:      "gateway",
+			Namespace: "default",
+			Annotations: map[string]string{
+				pilot_model.GatewayHTTPSRedirectAnnotation: "true",
+				pilot_model.GatewaySecurityHeadersAnnotation: "Strict-Transport-Security: max-age=31536000; includeSubDomains\n" +
+					"X-Frame-Options: DENY",
+			},
+		},
+		Spec: &networking.Gateway{
+			Selector: map[string]string{"istio": "ingressgateway"},
+			Servers: []*networking.Server{
+				{
+					Hosts: []string{"example.org", "plaintext.org"},
+					Port:  &networking.Port{Name: "http", Number: 80, Protocol: "HTTP"},
+				},
+				{
+					Hosts: []string{"*.example.org", "example.org"},
+					Port:  &networking.Port{Name: "https", Number: 443, Protocol: "HTTPS"},
+					Tls:   &networking.Server_TLSOptions{Mode: networking.Server_TLSOptions_SIMPLE, CredentialName: "example"},
+				},
+			},
+		},
+	}
+	virtualService := pilot_model.Config{
+		ConfigMeta: pilot_model.ConfigMeta{
+			Type:      schemas.VirtualService.Type,
+			Name:      "virtual-service",
+			Namespace: "default",
+		},
+		Spec: &networking.VirtualService{
+			Hosts:    []string{"example.org", "plaintext.org"},
+			Gateways: []string{"gateway"},
+			Http: []*networking.HTTPRoute{
+				{
+					Route: []*networking.HTTPRouteDestination{
+						{
+							Destination: &networking.Destination{
+								Host: "example.org",
+								Port: &networking.PortSelector{Number: 80},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	hsts := &core.HeaderValueOption{
+		Header: &core.HeaderValue{Key: "Strict-Transport-Security", Value: "max-age=31536000; includeSubDomains"},
+		Append: proto.BoolFalse,
+	}
+	frameOptions := &core.HeaderValueOption{
+		Header: &core.HeaderValue{Key: "X-Frame-Options", Value: "DENY"},
+		Append: proto.BoolFalse,
+	}
+
+	cases := []struct {
+		routeName string
+		vHost     string
+		want      *route.VirtualHost
+	}{
+		{
+			// the host served over TLS is redirected, without HSTS over plain text
+			routeName: "http.80",
+			vHost:     "example.org:80",
+			want: &route.VirtualHost{
+				RequireTls:           route.VirtualHost_ALL,
+				ResponseHeadersToAdd: []*core.HeaderValueOption{frameOptions},
+			},
+		},
+		{
+			routeName: "http.80",
+			vHost:     "plaintext.org:80",
+			want: &route.VirtualHost{
+				ResponseHeadersToAdd: []*core.HeaderValueOption{frameOptions},
+			},
+		},
+		{
+			routeName: "https.443.https.gateway.default",
+			vHost:     "example.org:443",
+			want: &route.VirtualHost{
+				ResponseHeadersToAdd: []*core.HeaderValueOption{hsts, frameOptions},
+			},
+		},
+	}
+	configgen := NewConfigGenerator([]plugin.Plugin{&fakePlugin{}})
+	env := buildEnv(t, []pilot_model.Config{gw}, []pilot_model.Config{virtualService})
+	proxy14Gateway.SetGatewaysForProxy(env.PushContext)
+	for _, tt := range cases {
+		t.Run(tt.vHost, func(t *testing.T) {
+			rc := configgen.buildGatewayHTTPRouteConfig(&proxy14Gateway, env.PushContext, tt.routeName)
+			if rc == nil {
+				t.Fatal("got an empty route configuration")
+			}
+			var got *route.VirtualHost
+			for _, vh := range rc.VirtualHosts {
+				if vh.Name == tt.vHost {
+					got = vh
+				}
+			}
+			if got == nil {
+				t.Fatalf("virtual host %s not found", tt.vHost)
+			}
+			if got.RequireTls != tt.want.RequireTls {
+				t.Errorf("got require TLS %v, want %v", got.RequireTls, tt.want.RequireTls)
+			}
+			if !reflect.DeepEqual(got.ResponseHeadersToAdd, tt.want.ResponseHeadersToAdd) {
+				t.Errorf("got response headers %v, want %v", got.ResponseHeadersToAdd, tt.want.ResponseHeadersToAdd)
+			}
+		})
+	}
+}
+
 func buildEnv(t *testing.T, gateways []pilot_model.Config, virtualServices []pilot_model.Config) pilot_model.Environment {
 	serviceDiscovery := new(fakes.ServiceDiscovery)
 
@@ -27,6 +27,17 @@ import (
 	"istio.io/istio/pilot/pkg/networking/util"
 )
 
+// LocalityHealth counts the endpoints of a locality.
+type LocalityHealth struct {
+	// Healthy is the number of endpoints ready to receive traffic.
+	Healthy uint32
+	// Total is the number of endpoints, including the unhealthy ones.
+	Total uint32
+}
+
+// localityHealthWeightScale is the sum of the locality weights set by ApplyLocalityHealth.
+const localityHealthWeightScale = 1000
+
 func ApplyLocalityLBSetting(
 	locality *core.Locality,
 	loadAssignment *apiv2.ClusterLoadAssignment,
@@ -154,3 +165,79 @@ func applyLocalityFailover(
 	}
 
 }
+
+// ApplyLocalityHealth spreads the load across the priorities set by ApplyLocalityLBSetting according to the
+// health of their localities, keyed by locality string, like Envoy does with the health of the endpoints:
+// a priority receives its ratio of healthy endpoints multiplied by the overprovisioning factor, in percent,
+// and the remaining load spills to the next priority.
+// Since the unhealthy endpoints are not sent to Envoy, the priorities are replaced by locality weights:
+// the localities receiving load get priority 0 and a weight proportional to their share of the load,
+// and the others get priority 1, to be used only if all the former fail.
+func ApplyLocalityHealth(
+	loadAssignment *apiv2.ClusterLoadAssignment,
+	health map[string]LocalityHealth,
+	overprovisioningFactor uint32,
+) {
+	if loadAssignment == nil || overprovisioningFactor == 0 {
+		return
+	}
+
+	// 1. sum the health of the localities of each priority.
+	// key is priority, value is the health of its localities
+	priorityHealth := map[uint32]LocalityHealth{}
+	localityHealth := make([]LocalityHealth, len(loadAssignment.Endpoints))
+	for i, localityEndpoint := range loadAssignment.Endpoints {
+		h, found := health[util.LocalityToString(localityEndpoint.Locality)]
+		if !found {
+			// the endpoints of unknown health are deemed healthy
+			h.Healthy = uint32(len(localityEndpoint.LbEndpoints))
+			h.Total = h.Healthy
+		}
+		localityHealth[i] = h
+		p := priorityHealth[localityEndpoint.Priority]
+		p.Healthy += h.Healthy
+		p.Total += h.Total
+		priorityHealth[localityEndpoint.Priority] = p
+	}
+
+	// 2. compute the load of each priority, in increasing order.
+	priorities := make([]int, 0, len(priorityHealth))
+	for priority := range priorityHealth {
+		priorities = append(priorities, int(priority))
+	}
+	sort.Ints(priorities)
+	priorityLoad := map[uint32]float64{}
+	remaining := 1.0
+	for _, priority := range priorities {
+		h := priorityHealth[uint32(priority)]
+		if h.Total == 0 || remaining <= 0 {
+			continue
+		}
+		load := math.Min(1, float64(overprovisioningFactor)/100*float64(h.Healthy)/float64(h.Total))
+		load = math.Min(load, remaining)
+		priorityLoad[uint32(priority)] = load
+		remaining -= load
+	}
+	// the load is normalized if the priorities are not healthy enough to receive all of it.
+	totalLoad := 1 - remaining
+	if totalLoad <= 0 {
+		// no healthy endpoint, let Envoy handle the priorities.
+		return
+	}
+
+	// 3. set the weights of the localities.
+	for i, localityEndpoint := range loadAssignment.Endpoints {
+		priority := localityEndpoint.Priority
+		var weight uint32
+		if h := priorityHealth[priority]; h.Healthy > 0 {
+			share := priorityLoad[priority] / totalLoad * float64(localityHealth[i].Healthy) / float64(h.Healthy)
+			weight = uint32(math.Round(share * localityHealthWeightScale))
+		}
+		if weight == 0 {
+			localityEndpoint.Priority = 1
+			continue
+		}
+		localityEndpoint.Priority = 0
+		localityEndpoint.LoadBalancingWeight = &wrappers.UInt32Value{Value: weight}
+	}
+}
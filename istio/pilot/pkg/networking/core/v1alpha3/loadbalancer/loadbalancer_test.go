@@ -29,6 +29,7 @@ import (
 
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/networking/core/v1alpha3/fakes"
+	"istio.io/istio/pilot/pkg/networking/util"
 	"istio.io/istio/pkg/config/mesh"
 	"istio.io/istio/pkg/config/protocol"
 	"istio.io/istio/pkg/config/schemas"
@@ -277,6 +278,120 @@ func buildEnvForClustersWithFailover() *model.Environment {
 	return env
 }
 
+func TestApplyLocalityHealth(t *testing.T) {
+	subzone1 := "region1/zone1/subzone1"
+	subzone2 := "region1/zone1/subzone2"
+	region2 := "region2/zone1"
+
+	tests := []struct {
+		name       string
+		priorities []uint32
+		factor     uint32
+		health     map[string]LocalityHealth
+		// expected priorities and weights of the localities subzone1, subzone2 and region2.
+		expectedPriorities []uint32
+		expectedWeights    []uint32
+	}{
+		{
+			name:               "all healthy",
+			priorities:         []uint32{0, 1, 2},
+			factor:             140,
+			health:             map[string]LocalityHealth{subzone1: {4, 4}, subzone2: {4, 4}, region2: {2, 2}},
+			expectedPriorities: []uint32{0, 1, 1},
+			expectedWeights:    []uint32{1000, 0, 0},
+		},
+		{
+			name:               "half of the first priority unhealthy",
+			priorities:         []uint32{0, 1, 2},
+			factor:             140,
+			health:             map[string]LocalityHealth{subzone1: {2, 4}, subzone2: {4, 4}, region2: {2, 2}},
+			expectedPriorities: []uint32{0, 0, 1},
+			expectedWeights:    []uint32{700, 300, 0},
+		},
+		{
+			name:               "first priority down and second degraded",
+			priorities:         []uint32{0, 1, 2},
+			factor:             140,
+			health:             map[string]LocalityHealth{subzone1: {0, 4}, subzone2: {1, 4}, region2: {2, 2}},
+			expectedPriorities: []uint32{1, 0, 0},
+			expectedWeights:    []uint32{0, 350, 650},
+		},
+		{
+			name:               "higher overprovisioning factor",
+			priorities:         []uint32{0, 1, 2},
+			factor:             200,
+			health:             map[string]LocalityHealth{subzone1: {2, 4}, subzone2: {4, 4}, region2: {2, 2}},
+			expectedPriorities: []uint32{0, 1, 1},
+			expectedWeights:    []uint32{1000, 0, 0},
+		},
+		{
+			name:               "load normalized",
+			priorities:         []uint32{0, 1, 2},
+			factor:             100,
+			health:             map[string]LocalityHealth{subzone1: {1, 10}, subzone2: {1, 10}, region2: {2, 10}},
+			expectedPriorities: []uint32{0, 0, 0},
+			expectedWeights:    []uint32{250, 250, 500},
+		},
+		{
+			name:               "localities of the same priority",
+			priorities:         []uint32{0, 1, 1},
+			factor:             140,
+			health:             map[string]LocalityHealth{subzone1: {2, 4}, subzone2: {3, 3}, region2: {1, 1}},
+			expectedPriorities: []uint32{0, 0, 0},
+			expectedWeights:    []uint32{700, 225, 75},
+		},
+		{
+			name:               "unknown health",
+			priorities:         []uint32{0, 1, 2},
+			factor:             140,
+			health:             map[string]LocalityHealth{subzone2: {2, 2}},
+			expectedPriorities: []uint32{0, 1, 1},
+			expectedWeights:    []uint32{1000, 0, 0},
+		},
+		{
+			name:               "no healthy endpoint",
+			priorities:         []uint32{0, 1, 2},
+			factor:             140,
+			health:             map[string]LocalityHealth{subzone1: {0, 4}, subzone2: {0, 4}, region2: {0, 2}},
+			expectedPriorities: []uint32{0, 1, 2},
+			expectedWeights:    []uint32{0, 0, 0},
+		},
+		{
+			name:               "disabled",
+			priorities:         []uint32{0, 1, 2},
+			health:             map[string]LocalityHealth{subzone1: {2, 4}, subzone2: {4, 4}, region2: {2, 2}},
+			expectedPriorities: []uint32{0, 1, 2},
+			expectedWeights:    []uint32{0, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loadAssignment := &apiv2.ClusterLoadAssignment{ClusterName: "outbound|8080||test.example.org"}
+			for i, locality := range []string{subzone1, subzone2, region2} {
+				region, zone, subzone := util.SplitLocality(locality)
+				loadAssignment.Endpoints = append(loadAssignment.Endpoints, &endpoint.LocalityLbEndpoints{
+					Locality:    &envoycore.Locality{Region: region, Zone: zone, SubZone: subzone},
+					LbEndpoints: []*endpoint.LbEndpoint{{}},
+					Priority:    tt.priorities[i],
+				})
+			}
+			ApplyLocalityHealth(loadAssignment, tt.health, tt.factor)
+			priorities := make([]uint32, 0)
+			weights := make([]uint32, 0)
+			for _, localityEndpoint := range loadAssignment.Endpoints {
+				priorities = append(priorities, localityEndpoint.Priority)
+				weights = append(weights, localityEndpoint.LoadBalancingWeight.GetValue())
+			}
+			if !reflect.DeepEqual(priorities, tt.expectedPriorities) {
+				t.Errorf("Got priorities %v expected %v", priorities, tt.expectedPriorities)
+			}
+			if !reflect.DeepEqual(weights, tt.expectedWeights) {
+				t.Errorf("Got weights %v expected %v", weights, tt.expectedWeights)
+			}
+		})
+	}
+}
+
 func buildFakeCluster() *apiv2.Cluster {
 	return &apiv2.Cluster{
 		Name: "outbound|8080||test.example.org",
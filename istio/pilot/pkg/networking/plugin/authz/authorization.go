@@ -23,6 +23,8 @@ package authz
 
 import (
 	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
+	http_conn "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
 
 	istiolog "istio.io/pkg/log"
 
@@ -77,6 +79,12 @@ func (Plugin) OnInboundListener(in *plugin.InputParams, mutable *plugin.MutableO
 }
 
 func buildFilter(in *plugin.InputParams, mutable *plugin.MutableObjects) {
+	// The external authorizer is called before RBAC, so that RBAC can rely on the headers added by it.
+	if extAuthzBuilder := newExtAuthzBuilder(in); extAuthzBuilder != nil {
+		rbacLog.Debugf("building ext_authz filter")
+		addFilters(in, mutable, extAuthzBuilder.BuildHTTPFilter, extAuthzBuilder.BuildTCPFilter)
+	}
+
 	// TODO: Get trust domain from MeshConfig instead.
 	// https://github.com/istio/istio/issues/17873
 	trustDomainBundle := trustdomain.NewTrustDomainBundle(spiffe.GetTrustDomain(), in.Push.Mesh.TrustDomainAliases)
@@ -85,16 +93,26 @@ func buildFilter(in *plugin.InputParams, mutable *plugin.MutableObjects) {
 	if builder == nil {
 		return
 	}
+	addFilters(in, mutable, builder.BuildHTTPFilter, builder.BuildTCPFilter)
+}
 
+func newExtAuthzBuilder(in *plugin.InputParams) *authz_builder.ExtAuthzBuilder {
+	return authz_builder.NewExtAuthzBuilder(in.Node.WorkloadLabels, in.Node.ConfigNamespace, in.Push.AuthzPolicies,
+		util.IsXDSMarshalingToAnyEnabled(in.Node))
+}
+
+// addFilters appends the filters built by the functions to the filter chains matching their protocol.
+func addFilters(in *plugin.InputParams, mutable *plugin.MutableObjects,
+	buildHTTPFilter func() *http_conn.HttpFilter, buildTCPFilter func() *listener.Filter) {
 	switch in.ListenerProtocol {
 	case plugin.ListenerProtocolTCP:
 		rbacLog.Debugf("building filter for TCP listener protocol")
-		tcpFilter := builder.BuildTCPFilter()
+		tcpFilter := buildTCPFilter()
 		if in.Node.Type == model.Router {
 			// For gateways, due to TLS termination, a listener marked as TCP could very well
 			// be using a HTTP connection manager. So check the filterChain.listenerProtocol
 			// to decide the type of filter to attach
-			httpFilter := builder.BuildHTTPFilter()
+			httpFilter := buildHTTPFilter()
 			for cnum := range mutable.FilterChains {
 				if mutable.FilterChains[cnum].ListenerProtocol == plugin.ListenerProtocolHTTP {
 					if httpFilter != nil {
@@ -108,7 +126,7 @@ func buildFilter(in *plugin.InputParams, mutable *plugin.MutableObjects) {
 					}
 				}
 			}
-		} else {
+		} else if tcpFilter != nil {
 			for cnum := range mutable.FilterChains {
 				rbacLog.Debugf("added TCP filter to filter chain %d", cnum)
 				mutable.FilterChains[cnum].TCP = append(mutable.FilterChains[cnum].TCP, tcpFilter)
@@ -116,7 +134,7 @@ func buildFilter(in *plugin.InputParams, mutable *plugin.MutableObjects) {
 		}
 	case plugin.ListenerProtocolHTTP:
 		rbacLog.Debugf("building filter for HTTP listener protocol")
-		filter := builder.BuildHTTPFilter()
+		filter := buildHTTPFilter()
 		if filter != nil {
 			for cnum := range mutable.FilterChains {
 				rbacLog.Debugf("added HTTP filter to filter chain %d", cnum)
@@ -125,8 +143,8 @@ func buildFilter(in *plugin.InputParams, mutable *plugin.MutableObjects) {
 		}
 	case plugin.ListenerProtocolAuto:
 		rbacLog.Debugf("building filter for AUTO listener protocol")
-		httpFilter := builder.BuildHTTPFilter()
-		tcpFilter := builder.BuildTCPFilter()
+		httpFilter := buildHTTPFilter()
+		tcpFilter := buildTCPFilter()
 
 		for cnum := range mutable.FilterChains {
 			switch mutable.FilterChains[cnum].ListenerProtocol {
@@ -155,11 +173,23 @@ func (Plugin) OnInboundCluster(in *plugin.InputParams, cluster *xdsapi.Cluster)
 }
 
 // OnOutboundRouteConfiguration implements the Plugin interface method.
+// It restricts the ext_authz filter of the gateways to the paths of the external authorization policies.
 func (Plugin) OnOutboundRouteConfiguration(in *plugin.InputParams, route *xdsapi.RouteConfiguration) {
+	if in.Node.Type != model.Router {
+		// Only care about router.
+		return
+	}
+	newExtAuthzBuilder(in).ApplyToRoutes(route)
 }
 
 // OnInboundRouteConfiguration implements the Plugin interface method.
+// It restricts the ext_authz filter of the sidecars to the paths of the external authorization policies.
 func (Plugin) OnInboundRouteConfiguration(in *plugin.InputParams, route *xdsapi.RouteConfiguration) {
+	if in.Node.Type != model.SidecarProxy {
+		// Only care about sidecar.
+		return
+	}
+	newExtAuthzBuilder(in).ApplyToRoutes(route)
 }
 
 // OnOutboundCluster implements the Plugin interface method.
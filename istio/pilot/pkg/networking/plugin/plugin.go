@@ -49,6 +49,8 @@ const (
 	Health = "health"
 	// Mixer is the name of the mixer plugin passed through the command line
 	Mixer = "mixer"
+	// RateLimit is the name of the rate limit plugin passed through the command line
+	RateLimit = "ratelimit"
 )
 
 // ModelProtocolToListenerProtocol converts from a config.Protocol to its corresponding plugin.ListenerProtocol
@@ -0,0 +1,370 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit implements the rate limits declared in the rate limits config of Pilot. The global rate
+// limits add the envoy.rate_limit filter, calling the rate limit service, and the actions building its descriptors
+// to the routes. The local rate limits add a token bucket to the listeners, limiting their connections.
+package ratelimit
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
+	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	rl_filter "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/rate_limit/v2"
+	http_conn "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	rls "github.com/envoyproxy/go-control-plane/envoy/config/ratelimit/v2"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher"
+	xdsutil "github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/protobuf/ptypes"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+
+	istiolog "istio.io/pkg/log"
+
+	"istio.io/istio/pilot/pkg/features"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/plugin"
+	"istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/proto"
+)
+
+const (
+	// LocalRateLimitFilterName is the name of the local rate limit network filter in envoy.
+	LocalRateLimitFilterName = "envoy.filters.network.local_ratelimit"
+
+	// localRateLimitStatPrefix is the stat prefix of the local rate limit network filter.
+	localRateLimitStatPrefix = "local_rate_limit"
+)
+
+var (
+	log = istiolog.RegisterScope("ratelimit", "rate limit debugging", 0)
+)
+
+// Plugin implements the rate limits of Pilot.
+type Plugin struct{}
+
+// NewPlugin returns an instance of the rate limit plugin.
+func NewPlugin() plugin.Plugin {
+	return Plugin{}
+}
+
+// limitsFor returns the rate limits selecting the proxy.
+func limitsFor(in *plugin.InputParams) []*mesh.RateLimit {
+	if in.Push == nil || in.Push.RateLimits == nil || in.Node == nil {
+		return nil
+	}
+	var out []*mesh.RateLimit
+	for _, l := range in.Push.RateLimits.Limits {
+		if l.Selects(in.Node.ConfigNamespace, in.Node.WorkloadLabels) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// OnOutboundListener is called whenever a new outbound listener is added to the LDS output for a given service
+// Can be used to add additional filters on the outbound path
+func (Plugin) OnOutboundListener(in *plugin.InputParams, mutable *plugin.MutableObjects) error {
+	if in.Node.Type != model.Router {
+		// Only care about router.
+		return nil
+	}
+
+	buildFilters(in, mutable)
+	return nil
+}
+
+// OnInboundListener is called whenever a new listener is added to the LDS output for a given service
+// Can be used to add additional filters (e.g., mixer filter) or add more stuff to the HTTP connection manager
+// on the inbound path
+func (Plugin) OnInboundListener(in *plugin.InputParams, mutable *plugin.MutableObjects) error {
+	if in.Node.Type != model.SidecarProxy {
+		// Only care about sidecar.
+		return nil
+	}
+
+	buildFilters(in, mutable)
+	return nil
+}
+
+func buildFilters(in *plugin.InputParams, mutable *plugin.MutableObjects) {
+	limits := limitsFor(in)
+	if len(limits) == 0 {
+		return
+	}
+
+	httpFilter := buildHTTPFilter(in.Push.RateLimits.Service, limits)
+	var tcpFilter *listener.Filter
+	if util.IsIstioVersionGE15(in.Node) {
+		tcpFilter = buildLocalFilter(limits)
+	} else {
+		log.Debugf("local rate limits not supported by proxy %s", in.Node.ID)
+	}
+	for cnum := range mutable.FilterChains {
+		chain := &mutable.FilterChains[cnum]
+		if tcpFilter != nil {
+			log.Debugf("added local rate limit filter to filter chain %d", cnum)
+			chain.TCP = append(chain.TCP, tcpFilter)
+		}
+		if httpFilter != nil && (in.ListenerProtocol == plugin.ListenerProtocolHTTP || chain.ListenerProtocol == plugin.ListenerProtocolHTTP) {
+			log.Debugf("added rate limit filter to filter chain %d", cnum)
+			chain.HTTP = append(chain.HTTP, httpFilter)
+		}
+	}
+}
+
+// buildHTTPFilter returns the envoy.rate_limit filter calling the rate limit service, or nil if none of the
+// limits is global.
+func buildHTTPFilter(service *mesh.RateLimitService, limits []*mesh.RateLimit) *http_conn.HttpFilter {
+	if service == nil || !hasDescriptors(limits) {
+		return nil
+	}
+
+	cluster := service.Cluster
+	if cluster == "" {
+		cluster = model.BuildSubsetKey(model.TrafficDirectionOutbound, "", host.Name(service.Service), int(service.Port))
+	}
+	config := &rl_filter.RateLimit{
+		Domain:          service.Domain,
+		FailureModeDeny: !service.FailOpen,
+		RateLimitService: &rls.RateLimitServiceConfig{
+			GrpcService: &core.GrpcService{
+				TargetSpecifier: &core.GrpcService_EnvoyGrpc_{
+					EnvoyGrpc: &core.GrpcService_EnvoyGrpc{ClusterName: cluster},
+				},
+			},
+		},
+	}
+	if d, err := time.ParseDuration(service.Timeout); err == nil {
+		config.Timeout = ptypes.DurationProto(d)
+	}
+
+	return &http_conn.HttpFilter{
+		Name:       xdsutil.HTTPRateLimit,
+		ConfigType: &http_conn.HttpFilter_TypedConfig{TypedConfig: util.MessageToAny(config)},
+	}
+}
+
+func hasDescriptors(limits []*mesh.RateLimit) bool {
+	for _, l := range limits {
+		if len(l.Descriptors) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// buildLocalFilter returns the local rate limit filter of the first limit with a token bucket, or nil if none
+// of the limits is local. It is the network filter, which limits the connections accepted by the listener and
+// not the requests: the HTTP local rate limit filter is not available in the supported proxies. The filter has
+// no typed config in the Envoy API vendored by Pilot, so it is built as a struct. It is only known by the
+// proxies of Istio 1.5 or later.
+func buildLocalFilter(limits []*mesh.RateLimit) *listener.Filter {
+	var local *mesh.LocalRateLimit
+	for _, l := range limits {
+		if l.Local == nil {
+			continue
+		}
+		if local != nil {
+			log.Warnf("ignored local rate limit %q: the listener already has a local rate limit", l.Name)
+			continue
+		}
+		local = l.Local
+	}
+	if local == nil {
+		return nil
+	}
+
+	tokensPerFill := local.TokensPerFill
+	if tokensPerFill == 0 {
+		tokensPerFill = 1
+	}
+	config := &structpb.Struct{
+		Fields: map[string]*structpb.Value{
+			"stat_prefix": {Kind: &structpb.Value_StringValue{StringValue: localRateLimitStatPrefix}},
+			"token_bucket": {Kind: &structpb.Value_StructValue{StructValue: &structpb.Struct{
+				Fields: map[string]*structpb.Value{
+					"max_tokens":      {Kind: &structpb.Value_NumberValue{NumberValue: float64(local.MaxTokens)}},
+					"tokens_per_fill": {Kind: &structpb.Value_NumberValue{NumberValue: float64(tokensPerFill)}},
+					"fill_interval":   {Kind: &structpb.Value_StringValue{StringValue: durationJSON(local.FillIntervalDuration())}},
+				},
+			}}},
+		},
+	}
+
+	return &listener.Filter{
+		Name:       LocalRateLimitFilterName,
+		ConfigType: &listener.Filter_Config{Config: config},
+	}
+}
+
+// durationJSON formats the duration as in the JSON mapping of google.protobuf.Duration, e.g. "0.5s".
+func durationJSON(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// OnOutboundRouteConfiguration implements the Plugin interface method.
+// It adds the actions of the global rate limits to the routes of the gateways.
+func (Plugin) OnOutboundRouteConfiguration(in *plugin.InputParams, routeConfiguration *xdsapi.RouteConfiguration) {
+	if in.Node.Type != model.Router {
+		// Only care about router.
+		return
+	}
+	applyToRoutes(in, routeConfiguration)
+}
+
+// OnInboundRouteConfiguration implements the Plugin interface method.
+// It adds the actions of the global rate limits to the routes of the sidecars.
+func (Plugin) OnInboundRouteConfiguration(in *plugin.InputParams, routeConfiguration *xdsapi.RouteConfiguration) {
+	if in.Node.Type != model.SidecarProxy {
+		// Only care about sidecar.
+		return
+	}
+	applyToRoutes(in, routeConfiguration)
+}
+
+func applyToRoutes(in *plugin.InputParams, routeConfiguration *xdsapi.RouteConfiguration) {
+	if in.Push == nil || in.Push.RateLimits == nil || in.Push.RateLimits.Service == nil {
+		return
+	}
+	rateLimits := buildRouteRateLimits(limitsFor(in), in.Node)
+	if len(rateLimits) == 0 {
+		return
+	}
+
+	for _, virtualHost := range routeConfiguration.VirtualHosts {
+		for _, r := range virtualHost.Routes {
+			if action, ok := r.Action.(*route.Route_Route); ok {
+				action.Route.RateLimits = append(action.Route.RateLimits, rateLimits...)
+			}
+		}
+	}
+}
+
+// buildRouteRateLimits returns the route rate limits of the descriptors of the limits. A limit restricted to
+// paths prefixes its descriptors with a ("header_match", <limit name>) entry, once per path, so that the
+// descriptors are only sent for the requests of the paths.
+func buildRouteRateLimits(limits []*mesh.RateLimit, node *model.Proxy) []*route.RateLimit {
+	var out []*route.RateLimit
+	for _, l := range limits {
+		for _, d := range l.Descriptors {
+			actions := buildActions(d)
+			if len(l.Paths) == 0 {
+				out = append(out, &route.RateLimit{Actions: actions})
+				continue
+			}
+			for _, path := range l.Paths {
+				pathAction := &route.RateLimit_Action{
+					ActionSpecifier: &route.RateLimit_Action_HeaderValueMatch_{
+						HeaderValueMatch: &route.RateLimit_Action_HeaderValueMatch{
+							DescriptorValue: l.Name,
+							ExpectMatch:     proto.BoolTrue,
+							Headers:         []*route.HeaderMatcher{pathMatcher(path, node)},
+						},
+					},
+				}
+				out = append(out, &route.RateLimit{Actions: append([]*route.RateLimit_Action{pathAction}, actions...)})
+			}
+		}
+	}
+	return out
+}
+
+// pathMatcher returns the matcher of the :path header of the requests of the path. The header includes the
+// query string, so an exact path is matched by a regex allowing an optional query.
+func pathMatcher(path string, node *model.Proxy) *route.HeaderMatcher {
+	out := &route.HeaderMatcher{
+		Name: ":path",
+	}
+	if strings.HasSuffix(path, "*") {
+		out.HeaderMatchSpecifier = &route.HeaderMatcher_PrefixMatch{PrefixMatch: strings.TrimSuffix(path, "*")}
+		return out
+	}
+
+	regex := regexp.QuoteMeta(path) + `(\?.*)?`
+	if features.EnableUnsafeRegex.Get() || !util.IsIstioVersionGE14(node) {
+		out.HeaderMatchSpecifier = &route.HeaderMatcher_RegexMatch{RegexMatch: regex}
+	} else {
+		out.HeaderMatchSpecifier = &route.HeaderMatcher_SafeRegexMatch{
+			SafeRegexMatch: &matcher.RegexMatcher{
+				EngineType: &matcher.RegexMatcher_GoogleRe2{GoogleRe2: &matcher.RegexMatcher_GoogleRE2{}},
+				Regex:      regex,
+			},
+		}
+	}
+	return out
+}
+
+func buildActions(d *mesh.RateLimitDescriptor) []*route.RateLimit_Action {
+	out := make([]*route.RateLimit_Action, 0, len(d.Actions))
+	for _, a := range d.Actions {
+		action := &route.RateLimit_Action{}
+		switch a.Type {
+		case mesh.RateLimitActionRemoteAddress:
+			action.ActionSpecifier = &route.RateLimit_Action_RemoteAddress_{
+				RemoteAddress: &route.RateLimit_Action_RemoteAddress{},
+			}
+		case mesh.RateLimitActionRequestHeader:
+			action.ActionSpecifier = &route.RateLimit_Action_RequestHeaders_{
+				RequestHeaders: &route.RateLimit_Action_RequestHeaders{HeaderName: a.Header, DescriptorKey: a.DescriptorKey},
+			}
+		case mesh.RateLimitActionGenericKey:
+			action.ActionSpecifier = &route.RateLimit_Action_GenericKey_{
+				GenericKey: &route.RateLimit_Action_GenericKey{DescriptorValue: a.Value},
+			}
+		case mesh.RateLimitActionDestinationCluster:
+			action.ActionSpecifier = &route.RateLimit_Action_DestinationCluster_{
+				DestinationCluster: &route.RateLimit_Action_DestinationCluster{},
+			}
+		case mesh.RateLimitActionSourceCluster:
+			action.ActionSpecifier = &route.RateLimit_Action_SourceCluster_{
+				SourceCluster: &route.RateLimit_Action_SourceCluster{},
+			}
+		default:
+			continue
+		}
+		out = append(out, action)
+	}
+	return out
+}
+
+// OnVirtualListener implements the Plugin interface method.
+func (Plugin) OnVirtualListener(in *plugin.InputParams, mutable *plugin.MutableObjects) error {
+	return nil
+}
+
+// OnInboundCluster implements the Plugin interface method.
+func (Plugin) OnInboundCluster(in *plugin.InputParams, cluster *xdsapi.Cluster) {
+}
+
+// OnOutboundCluster implements the Plugin interface method.
+func (Plugin) OnOutboundCluster(in *plugin.InputParams, cluster *xdsapi.Cluster) {
+}
+
+// OnInboundFilterChains is called whenever a plugin needs to setup the filter chains, including relevant filter chain configuration.
+func (Plugin) OnInboundFilterChains(in *plugin.InputParams) []plugin.FilterChain {
+	return nil
+}
+
+// OnInboundPassthrough is called whenever a new passthrough filter chain is added to the LDS output.
+func (Plugin) OnInboundPassthrough(in *plugin.InputParams, mutable *plugin.MutableObjects) error {
+	return nil
+}
@@ -0,0 +1,189 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"testing"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	rl_filter "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/rate_limit/v2"
+	xdsutil "github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/protobuf/ptypes"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/plugin"
+	"istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/mesh"
+)
+
+func newInputParams(nodeType model.NodeType, rateLimits string, t *testing.T) *plugin.InputParams {
+	t.Helper()
+	limits, err := mesh.ParseRateLimits(rateLimits)
+	if err != nil {
+		t.Fatalf("failed to parse rate limits: %v", err)
+	}
+	return &plugin.InputParams{
+		ListenerProtocol: plugin.ListenerProtocolHTTP,
+		Node: &model.Proxy{
+			Type:            nodeType,
+			ConfigNamespace: "default",
+			WorkloadLabels:  labels.Collection{{"app": "reviews"}},
+		},
+		Push: &model.PushContext{RateLimits: limits},
+	}
+}
+
+const rateLimits = `
+service:
+  service: ratelimit.ratelimit.svc.cluster.local
+  port: 8081
+  timeout: 50ms
+limits:
+- name: api
+  namespace: default
+  selector: {app: reviews}
+  paths: ["/api/*", "/login"]
+  descriptors:
+  - actions:
+    - type: RequestHeader
+      header: x-user
+      descriptorKey: user
+- name: all
+  descriptors:
+  - actions:
+    - type: RemoteAddress
+  local:
+    maxTokens: 100
+    fillInterval: 500ms
+- name: other
+  namespace: other
+  local:
+    maxTokens: 1
+    fillInterval: 1s
+`
+
+func TestOnInboundListener(t *testing.T) {
+	in := newInputParams(model.SidecarProxy, rateLimits, t)
+	mutable := &plugin.MutableObjects{FilterChains: []plugin.FilterChain{{}}}
+	if err := NewPlugin().OnInboundListener(in, mutable); err != nil {
+		t.Fatalf("OnInboundListener() failed: %v", err)
+	}
+
+	chain := mutable.FilterChains[0]
+	if len(chain.HTTP) != 1 || chain.HTTP[0].Name != xdsutil.HTTPRateLimit {
+		t.Fatalf("got http filters %v, want the rate limit filter", chain.HTTP)
+	}
+	config := &rl_filter.RateLimit{}
+	if err := ptypes.UnmarshalAny(chain.HTTP[0].GetTypedConfig(), config); err != nil {
+		t.Fatalf("failed to unmarshal the rate limit filter config: %v", err)
+	}
+	if got := config.GetRateLimitService().GetGrpcService().GetEnvoyGrpc().GetClusterName(); got != "outbound|8081||ratelimit.ratelimit.svc.cluster.local" {
+		t.Errorf("got rate limit service cluster %q", got)
+	}
+	if config.Domain != mesh.DefaultRateLimitDomain || !config.FailureModeDeny {
+		t.Errorf("got domain %q and failure mode deny %v", config.Domain, config.FailureModeDeny)
+	}
+	if d, _ := ptypes.Duration(config.Timeout); d.Milliseconds() != 50 {
+		t.Errorf("got timeout %v, want 50ms", d)
+	}
+
+	if len(chain.TCP) != 1 || chain.TCP[0].Name != LocalRateLimitFilterName {
+		t.Fatalf("got network filters %v, want the local rate limit filter", chain.TCP)
+	}
+	// nolint: staticcheck
+	bucket := chain.TCP[0].GetConfig().Fields["token_bucket"].GetStructValue().Fields
+	if bucket["max_tokens"].GetNumberValue() != 100 || bucket["tokens_per_fill"].GetNumberValue() != 1 ||
+		bucket["fill_interval"].GetStringValue() != "0.5s" {
+		t.Errorf("got token bucket %v", bucket)
+	}
+
+	// Proxies older than 1.5 don't know the local rate limit filter.
+	in.Node.IstioVersion = &model.IstioVersion{Major: 1, Minor: 4}
+	mutable = &plugin.MutableObjects{FilterChains: []plugin.FilterChain{{}}}
+	_ = NewPlugin().OnInboundListener(in, mutable)
+	if len(mutable.FilterChains[0].HTTP) != 1 || len(mutable.FilterChains[0].TCP) != 0 {
+		t.Errorf("want only the rate limit filter on a 1.4 proxy")
+	}
+	in.Node.IstioVersion = nil
+
+	// Gateways only get the filters on their outbound listeners.
+	in.Node.Type = model.Router
+	mutable = &plugin.MutableObjects{FilterChains: []plugin.FilterChain{{}}}
+	_ = NewPlugin().OnInboundListener(in, mutable)
+	if len(mutable.FilterChains[0].HTTP) != 0 || len(mutable.FilterChains[0].TCP) != 0 {
+		t.Errorf("want no filters on the inbound listener of a gateway")
+	}
+}
+
+func TestOnOutboundListener(t *testing.T) {
+	in := newInputParams(model.Router, rateLimits, t)
+	in.ListenerProtocol = plugin.ListenerProtocolTCP
+	mutable := &plugin.MutableObjects{FilterChains: []plugin.FilterChain{
+		{ListenerProtocol: plugin.ListenerProtocolHTTP},
+		{ListenerProtocol: plugin.ListenerProtocolTCP},
+	}}
+	if err := NewPlugin().OnOutboundListener(in, mutable); err != nil {
+		t.Fatalf("OnOutboundListener() failed: %v", err)
+	}
+	if len(mutable.FilterChains[0].HTTP) != 1 || len(mutable.FilterChains[0].TCP) != 1 {
+		t.Errorf("want the rate limit filters on the HTTP filter chain")
+	}
+	if len(mutable.FilterChains[1].HTTP) != 0 || len(mutable.FilterChains[1].TCP) != 1 {
+		t.Errorf("want only the local rate limit filter on the TCP filter chain")
+	}
+}
+
+func TestOnInboundRouteConfiguration(t *testing.T) {
+	in := newInputParams(model.SidecarProxy, rateLimits, t)
+	rc := &xdsapi.RouteConfiguration{
+		VirtualHosts: []*route.VirtualHost{{
+			Routes: []*route.Route{
+				{Action: &route.Route_Route{Route: &route.RouteAction{}}},
+				{Action: &route.Route_Redirect{Redirect: &route.RedirectAction{}}},
+			},
+		}},
+	}
+	NewPlugin().OnInboundRouteConfiguration(in, rc)
+
+	rateLimits := rc.VirtualHosts[0].Routes[0].GetRoute().RateLimits
+	// One per path of the "api" limit, and one for the "all" limit.
+	if len(rateLimits) != 3 {
+		t.Fatalf("got %d rate limits, want 3: %v", len(rateLimits), rateLimits)
+	}
+	match := rateLimits[0].Actions[0].GetHeaderValueMatch()
+	if match.GetDescriptorValue() != "api" || match.Headers[0].GetPrefixMatch() != "/api/" {
+		t.Errorf("got path action %v", match)
+	}
+	if rateLimits[0].Actions[1].GetRequestHeaders().GetDescriptorKey() != "user" {
+		t.Errorf("got action %v, want the request header action", rateLimits[0].Actions[1])
+	}
+	// The exact paths match the requests with a query string too.
+	if got := rateLimits[1].Actions[0].GetHeaderValueMatch().Headers[0].GetSafeRegexMatch().GetRegex(); got != `/login(\?.*)?` {
+		t.Errorf("got path regex %q", got)
+	}
+	if len(rateLimits[2].Actions) != 1 || rateLimits[2].Actions[0].GetRemoteAddress() == nil {
+		t.Errorf("got actions %v, want the remote address action", rateLimits[2].Actions)
+	}
+
+	// Sidecars only get the rate limits on their inbound routes.
+	rc = &xdsapi.RouteConfiguration{
+		VirtualHosts: []*route.VirtualHost{{Routes: []*route.Route{{Action: &route.Route_Route{Route: &route.RouteAction{}}}}}},
+	}
+	NewPlugin().OnOutboundRouteConfiguration(in, rc)
+	if len(rc.VirtualHosts[0].Routes[0].GetRoute().RateLimits) != 0 {
+		t.Errorf("want no rate limits on the outbound routes of a sidecar")
+	}
+}
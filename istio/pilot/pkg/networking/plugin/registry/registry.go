@@ -23,13 +23,15 @@ import (
 	"istio.io/istio/pilot/pkg/networking/plugin/authz"
 	"istio.io/istio/pilot/pkg/networking/plugin/health"
 	"istio.io/istio/pilot/pkg/networking/plugin/mixer"
+	"istio.io/istio/pilot/pkg/networking/plugin/ratelimit"
 )
 
 var availablePlugins = map[string]plugin.Plugin{
-	plugin.Authn:  authn.NewPlugin(),
-	plugin.Authz:  authz.NewPlugin(),
-	plugin.Health: health.NewPlugin(),
-	plugin.Mixer:  mixer.NewPlugin(),
+	plugin.Authn:     authn.NewPlugin(),
+	plugin.Authz:     authz.NewPlugin(),
+	plugin.Health:    health.NewPlugin(),
+	plugin.Mixer:     mixer.NewPlugin(),
+	plugin.RateLimit: ratelimit.NewPlugin(),
 }
 
 // NewPlugins returns a slice of default Plugins.
@@ -443,6 +443,7 @@ func (s *DiscoveryServer) initConnection(node *core.Node, con *XdsConnection) (f
 	// Set the sidecarScope and merged gateways associated with this proxy
 	proxy.SetSidecarScope(s.globalPushContext())
 	proxy.SetGatewaysForProxy(s.globalPushContext())
+	proxy.EnvoyFilterStatus = model.NewEnvoyFilterStatus()
 
 	// First request so initialize connection id and start tracking it.
 	con.mu.Lock()
@@ -117,6 +117,7 @@ func (s *DiscoveryServer) InitDebug(mux *http.ServeMux, sctl *aggregate.Controll
 
 	mux.HandleFunc("/debug", s.Debug)
 	mux.HandleFunc("/ready", s.ready)
+	mux.HandleFunc("/outlier/report", s.OutlierReport)
 
 	s.addDebugHandler(mux, "/debug/edsz", "Status and debug interface for EDS", s.edsz)
 	s.addDebugHandler(mux, "/debug/adsz", "Status and debug interface for ADS", s.adsz)
@@ -135,6 +136,8 @@ func (s *DiscoveryServer) InitDebug(mux *http.ServeMux, sctl *aggregate.Controll
 	s.addDebugHandler(mux, "/debug/authorizationz", "Internal authorization policies", s.Authorizationz)
 	s.addDebugHandler(mux, "/debug/config_dump", "ConfigDump in the form of the Envoy admin config dump API for passed in proxyID", s.ConfigDump)
 	s.addDebugHandler(mux, "/debug/push_status", "Last PushContext Details", s.PushStatusHandler)
+	s.addDebugHandler(mux, "/debug/envoyfilterz", "EnvoyFilter patches applied to the config pushed to the passed in proxyID", s.EnvoyFilterz)
+	s.addDebugHandler(mux, "/debug/outlierz", "Outlier ejections and circuit breaker overflows reported by the proxies", s.Outlierz)
 
 	s.addDebugHandler(mux, "/debug/inject", "Active inject template", s.InjectTemplateHandler(webhook))
 }
@@ -617,6 +620,61 @@ func (s *DiscoveryServer) ConfigDump(w http.ResponseWriter, req *http.Request) {
 	_, _ = w.Write([]byte("You must provide a proxyID in the query string"))
 }
 
+// EnvoyFilterz dumps the outcome of the EnvoyFilter patches in the config last pushed to a proxy.
+func (s *DiscoveryServer) EnvoyFilterz(w http.ResponseWriter, req *http.Request) {
+	proxyID := req.URL.Query().Get("proxyID")
+	if proxyID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("You must provide a proxyID in the query string"))
+		return
+	}
+	adsClientsMutex.RLock()
+	connections, ok := adsSidecarIDConnectionsMap[proxyID]
+	var con *XdsConnection
+	mostRecent := ""
+	for key := range connections {
+		if mostRecent == "" || key > mostRecent {
+			mostRecent = key
+			con = connections[key]
+		}
+	}
+	adsClientsMutex.RUnlock()
+	if !ok || con == nil {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("Proxy not connected to this Pilot instance"))
+		return
+	}
+
+	con.mu.RLock()
+	node := con.node
+	con.mu.RUnlock()
+	patches := make([]*model.EnvoyFilterPatchStatus, 0)
+	if node != nil && node.EnvoyFilterStatus != nil {
+		// Only report the patches of the EnvoyFilters that still select the proxy.
+		current := map[string]bool{}
+		if efw := s.globalPushContext().EnvoyFilters(node); efw != nil {
+			for _, cps := range efw.Patches {
+				for _, cp := range cps {
+					current[fmt.Sprintf("%s/%s/%d", cp.Namespace, cp.Name, cp.Index)] = true
+				}
+			}
+		}
+		for _, p := range node.EnvoyFilterStatus.Patches() {
+			if current[fmt.Sprintf("%s/%s/%d", p.Namespace, p.Name, p.Index)] {
+				patches = append(patches, p)
+			}
+		}
+	}
+	out, err := json.MarshalIndent(patches, "", "    ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, "unable to marshal EnvoyFilter status: %v", err)
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	_, _ = w.Write(out)
+}
+
 // configDump converts the connection internal state into an Envoy Admin API config dump proto
 // It is used in debugging to create a consistent object for comparison between Envoy and Pilot outputs
 func (s *DiscoveryServer) configDump(conn *XdsConnection) (*adminapi.ConfigDump, error) {
@@ -837,3 +837,67 @@ func TestDebugHandlers(t *testing.T) {
 		t.Errorf("Error in generatating debug endpoint list")
 	}
 }
+
+func TestEnvoyFilterZ(t *testing.T) {
+	tests := []struct {
+		name     string
+		proxyID  string
+		wantCode int
+	}{
+		{
+			name:     "returns 400 if proxyID not provided",
+			proxyID:  "",
+			wantCode: 400,
+		},
+		{
+			name:     "returns 404 if proxy not found",
+			proxyID:  "not-found",
+			wantCode: 404,
+		},
+		{
+			name:     "dumps most recent proxy with 200",
+			proxyID:  "dumpApp-644fc65469-96dza.testns",
+			wantCode: 200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, tearDown := initLocalPilotTestEnv(t)
+			defer tearDown()
+
+			envoy, cancel, err := connectADS(util.MockPilotGrpcAddr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cancel()
+			if err := sendCDSReq(sidecarID(app3Ip, "dumpApp"), envoy); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := adsReceive(envoy, 5*time.Second); err != nil {
+				t.Fatal("Recv failed", err)
+			}
+
+			path := "/debug/envoyfilterz"
+			if tt.proxyID != "" {
+				path += fmt.Sprintf("?proxyID=%v", tt.proxyID)
+			}
+			req, err := http.NewRequest("GET", path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(s.EnvoyXdsServer.EnvoyFilterz).ServeHTTP(rr, req)
+			if rr.Code != tt.wantCode {
+				t.Errorf("wanted response code %v, got %v", tt.wantCode, rr.Code)
+			}
+			if tt.wantCode > 399 {
+				return
+			}
+			got := []*model.EnvoyFilterPatchStatus{}
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal %s: %v", rr.Body.String(), err)
+			}
+		})
+	}
+}
@@ -112,6 +112,9 @@ type DiscoveryServer struct {
 
 	// debugHandlers is the list of all the supported debug handlers.
 	debugHandlers map[string]string
+
+	// outlierStatus aggregates the outlier detection and circuit breaker state reported by the proxies.
+	outlierStatus *model.OutlierStatus
 }
 
 // EndpointShards holds the set of endpoint shards of a service. Registries update
@@ -145,6 +148,7 @@ func NewDiscoveryServer(env *model.Environment, plugins []string) *DiscoveryServ
 		pushQueue:               NewPushQueue(),
 		DebugConfigs:            features.DebugConfigs,
 		debugHandlers:           map[string]string{},
+		outlierStatus:           model.NewOutlierStatus(),
 	}
 
 	// Flush cached discovery responses when detecting jwt public key change.
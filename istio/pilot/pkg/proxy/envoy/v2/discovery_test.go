@@ -31,6 +31,8 @@ import (
 
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/core/v1alpha3/loadbalancer"
+	"istio.io/istio/pkg/config/labels"
 )
 
 func createProxies(n int) []*XdsConnection {
@@ -317,3 +319,47 @@ func TestDebounce(t *testing.T) {
 		})
 	}
 }
+
+func TestLocalityHealthFromShards(t *testing.T) {
+	newEndpoint := func(address, portName, locality, version string, unhealthy bool) *model.IstioEndpoint {
+		return &model.IstioEndpoint{
+			Address:         address,
+			ServicePortName: portName,
+			Locality:        locality,
+			Labels:          labels.Instance{"version": version},
+			EndpointPort:    8080,
+			Unhealthy:       unhealthy,
+		}
+	}
+	shards := &EndpointShards{
+		Shards: map[string][]*model.IstioEndpoint{
+			"cluster1": {
+				newEndpoint("10.0.0.1", "http", "region1/zone1", "v1", false),
+				newEndpoint("10.0.0.2", "http", "region1/zone1", "v1", true),
+				newEndpoint("10.0.0.3", "tcp", "region1/zone1", "v1", false),
+				newEndpoint("10.0.0.4", "http", "region1/zone1", "v2", false),
+			},
+			"cluster2": {
+				newEndpoint("10.1.0.1", "http", "region2/zone1", "v1", true),
+			},
+		},
+	}
+	svcPort := &model.Port{Name: "http", Port: 80}
+	subsetLabels := labels.Collection{{"version": "v1"}}
+
+	health := localityHealthFromShards(shards, svcPort, subsetLabels)
+	expected := map[string]loadbalancer.LocalityHealth{
+		"region1/zone1": {Healthy: 1, Total: 2},
+		"region2/zone1": {Healthy: 0, Total: 1},
+	}
+	if !reflect.DeepEqual(health, expected) {
+		t.Errorf("Got locality health %v expected %v", health, expected)
+	}
+
+	// The unhealthy endpoints are not sent to the proxies.
+	locEps := buildLocalityLbEndpointsFromShards(shards, svcPort, subsetLabels, "outbound|80|v1|foo.com", model.NewPushContext())
+	if len(locEps) != 1 || len(locEps[0].LbEndpoints) != 1 ||
+		locEps[0].LbEndpoints[0].GetEndpoint().GetAddress().GetSocketAddress().GetAddress() != "10.0.0.1" {
+		t.Errorf("Got locality endpoints %v expected only 10.0.0.1", locEps)
+	}
+}
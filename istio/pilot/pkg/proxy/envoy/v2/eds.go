@@ -700,10 +700,45 @@ func (s *DiscoveryServer) generateEndpoints(
 			localityLbSettings = loadBalancerSettings.LocalityLbSetting
 		}
 		loadbalancer.ApplyLocalityLBSetting(proxy.Locality, l, localityLbSettings, enableFailover)
+
+		// The health of the localities can only change the priorities set by failover.
+		if enableFailover && localityLbSettings.GetDistribute() == nil {
+			if factor, health := s.localityHealth(proxy, push, clusterName); factor > 0 {
+				loadbalancer.ApplyLocalityHealth(l, health, factor)
+			}
+		}
 	}
 	return l
 }
 
+// localityHealth returns the overprovisioning factor of the health aware locality load balancing of the
+// service of the cluster, 0 if it is not enabled, and the health of the localities of the cluster.
+func (s *DiscoveryServer) localityHealth(proxy *model.Proxy, push *model.PushContext,
+	clusterName string) (uint32, map[string]loadbalancer.LocalityHealth) {
+	_, subsetName, hostname, port := model.ParseSubsetKey(clusterName)
+
+	push.Mutex.Lock()
+	svc := proxy.SidecarScope.ServiceForHostname(hostname, push.ServiceByHostnameAndNamespace)
+	push.Mutex.Unlock()
+	if svc == nil || svc.Attributes.LocalityOverprovisioningFactor == 0 {
+		return 0, nil
+	}
+	svcPort, f := svc.Ports.GetByPort(port)
+	if !f {
+		return 0, nil
+	}
+
+	s.mutex.RLock()
+	se, f := s.EndpointShardsByService[string(hostname)][svc.Attributes.Namespace]
+	s.mutex.RUnlock()
+	if !f {
+		return 0, nil
+	}
+
+	subsetLabels := push.SubsetToLabels(proxy, subsetName, hostname)
+	return svc.Attributes.LocalityOverprovisioningFactor, localityHealthFromShards(se, svcPort, subsetLabels)
+}
+
 // pushEds is pushing EDS updates for a single connection. Called the first time
 // a client connects, for incremental updates and for full periodic updates.
 func (s *DiscoveryServer) pushEds(push *model.PushContext, con *XdsConnection, version string, edsUpdatedServices map[string]struct{}) error {
@@ -907,6 +942,10 @@ func buildLocalityLbEndpointsFromShards(
 			if !epLabels.HasSubsetOf(ep.Labels) {
 				continue
 			}
+			// Unhealthy endpoints are only counted in the health of their locality
+			if ep.Unhealthy {
+				continue
+			}
 
 			locLbEps, found := localityEpMap[ep.Locality]
 			if !found {
@@ -947,6 +986,31 @@ func buildLocalityLbEndpointsFromShards(
 	return locEps
 }
 
+// localityHealthFromShards counts the healthy and unhealthy endpoints of the cluster in each locality.
+func localityHealthFromShards(
+	shards *EndpointShards,
+	svcPort *model.Port,
+	epLabels labels.Collection) map[string]loadbalancer.LocalityHealth {
+	health := map[string]loadbalancer.LocalityHealth{}
+
+	shards.mutex.RLock()
+	defer shards.mutex.RUnlock()
+	for _, endpoints := range shards.Shards {
+		for _, ep := range endpoints {
+			if svcPort.Name != ep.ServicePortName || !epLabels.HasSubsetOf(ep.Labels) {
+				continue
+			}
+			h := health[ep.Locality]
+			h.Total++
+			if !ep.Unhealthy {
+				h.Healthy++
+			}
+			health[ep.Locality] = h
+		}
+	}
+	return health
+}
+
 func updateEdsStats(locEps []*endpoint.LocalityLbEndpoints, cluster string) {
 	edsInstances.With(clusterTag.Value(cluster)).Record(float64(len(locEps)))
 	epc := 0
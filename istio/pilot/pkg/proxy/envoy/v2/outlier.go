@@ -0,0 +1,129 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/host"
+)
+
+// maxOutlierReportSize is the maximum size of the body of an outlier report.
+const maxOutlierReportSize = 1 << 20
+
+// OutlierReport records the outlier detection and circuit breaker state POSTed by the agent of a proxy. The
+// reports are only accepted over mutual TLS, i.e. on the secure port, from the workload identity of the proxy.
+func (s *DiscoveryServer) OutlierReport(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	namespace, serviceAccount, ok := peerIdentity(req)
+	if !ok {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("the outlier reports require mutual TLS with a workload certificate"))
+		return
+	}
+	report := &model.OutlierReport{}
+	if err := json.NewDecoder(io.LimitReader(req.Body, maxOutlierReportSize)).Decode(report); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintf(w, "invalid outlier report: %v", err)
+		return
+	}
+	if report.Proxy == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("the outlier report does not have a proxy"))
+		return
+	}
+	if err := authorizeOutlierReport(report.Proxy, namespace, serviceAccount); err != nil {
+		adsLog.Warnf("rejected outlier report: %v", err)
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(err.Error()))
+		return
+	}
+	s.outlierStatus.Report(report, time.Now())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// peerIdentity returns the namespace and the service account of the SPIFFE identity of the verified client
+// certificate of the request, spiffe://<trust domain>/ns/<namespace>/sa/<service account>.
+func peerIdentity(req *http.Request) (string, string, bool) {
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.PeerCertificates) == 0 {
+		return "", "", false
+	}
+	for _, uri := range req.TLS.PeerCertificates[0].URIs {
+		if uri.Scheme != "spiffe" {
+			continue
+		}
+		parts := strings.Split(strings.TrimPrefix(uri.Path, "/"), "/")
+		if len(parts) == 4 && parts[0] == "ns" && parts[2] == "sa" && parts[1] != "" && parts[3] != "" {
+			return parts[1], parts[3], true
+		}
+	}
+	return "", "", false
+}
+
+// authorizeOutlierReport checks that the workload identity of the caller can report for the proxy: the
+// proxy ID is <pod name>.<namespace>, the namespace must be the one of the identity, and the service account
+// the one of the proxy if it is connected to this Pilot instance.
+func authorizeOutlierReport(proxy, namespace, serviceAccount string) error {
+	if i := strings.LastIndex(proxy, "."); i < 0 || proxy[i+1:] != namespace {
+		return fmt.Errorf("service account %s/%s cannot report for proxy %s", namespace, serviceAccount, proxy)
+	}
+	adsClientsMutex.RLock()
+	defer adsClientsMutex.RUnlock()
+	for _, con := range adsClients {
+		if con.node == nil || con.node.ID != proxy || con.node.Metadata == nil {
+			continue
+		}
+		if sa := con.node.Metadata.ServiceAccount; sa != "" && sa != serviceAccount {
+			return fmt.Errorf("service account %s/%s cannot report for proxy %s of service account %s",
+				namespace, serviceAccount, proxy, sa)
+		}
+	}
+	return nil
+}
+
+// Outlierz dumps the outlier detection and circuit breaker state of the destinations reported by the proxies
+// to this Pilot instance, optionally filtered by hostname and subset.
+func (s *DiscoveryServer) Outlierz(w http.ResponseWriter, req *http.Request) {
+	hostname := req.URL.Query().Get("hostname")
+	subset, filterSubset := req.URL.Query()["subset"]
+
+	destinations := make([]*model.DestinationOutlierStatus, 0)
+	for _, d := range s.outlierStatus.Destinations(time.Now()) {
+		if hostname != "" && d.Hostname != host.Name(hostname) {
+			continue
+		}
+		if filterSubset && d.Subset != subset[0] {
+			continue
+		}
+		destinations = append(destinations, d)
+	}
+	out, err := json.MarshalIndent(destinations, "", "    ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, "unable to marshal outlier status: %v", err)
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	_, _ = w.Write(out)
+}
@@ -0,0 +1,109 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"istio.io/istio/pilot/pkg/model"
+	v2 "istio.io/istio/pilot/pkg/proxy/envoy/v2"
+)
+
+// peerTLS returns the TLS state of a mutual TLS connection from the workload identity.
+func peerTLS(identity string) *tls.ConnectionState {
+	if identity == "" {
+		return nil
+	}
+	uri, _ := url.Parse(identity)
+	cert := &x509.Certificate{URIs: []*url.URL{uri}}
+	return &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}
+}
+
+func TestOutlierReport(t *testing.T) {
+	s := v2.NewDiscoveryServer(&model.Environment{}, nil)
+
+	const (
+		productpage = "spiffe://cluster.local/ns/default/sa/productpage"
+		report      = `{"proxy":"productpage.default","clusters":[` +
+			`{"cluster":"outbound|9080|v1|reviews.default.svc.cluster.local","ejectedEndpoints":["10.0.0.1:9080"],"ejections":1},` +
+			`{"cluster":"outbound|9080||ratings.default.svc.cluster.local","pendingRequestOverflows":2}]}`
+	)
+	reports := []struct {
+		name     string
+		method   string
+		identity string
+		body     string
+		wantCode int
+	}{
+		{"not a POST", http.MethodGet, productpage, "", http.StatusMethodNotAllowed},
+		{"invalid", http.MethodPost, productpage, "{", http.StatusBadRequest},
+		{"no proxy", http.MethodPost, productpage, `{"clusters":[]}`, http.StatusBadRequest},
+		{"plaintext", http.MethodPost, "", report, http.StatusForbidden},
+		{"no workload identity", http.MethodPost, "spiffe://cluster.local/istio-pilot", report, http.StatusForbidden},
+		{"other namespace", http.MethodPost, "spiffe://cluster.local/ns/other/sa/productpage", report, http.StatusForbidden},
+		{"productpage", http.MethodPost, productpage, report, http.StatusNoContent},
+	}
+	for _, tt := range reports {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/outlier/report", strings.NewReader(tt.body))
+			req.TLS = peerTLS(tt.identity)
+			s.OutlierReport(w, req)
+			if w.Code != tt.wantCode {
+				t.Errorf("got status %d, want %d", w.Code, tt.wantCode)
+			}
+		})
+	}
+
+	queries := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"ratings.default.svc.cluster.local", "reviews.default.svc.cluster.local"}},
+		{"?hostname=reviews.default.svc.cluster.local", []string{"reviews.default.svc.cluster.local"}},
+		{"?subset=", []string{"ratings.default.svc.cluster.local"}},
+		{"?hostname=reviews.default.svc.cluster.local&subset=v2", []string{}},
+	}
+	for _, tt := range queries {
+		t.Run("outlierz"+tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			s.Outlierz(w, httptest.NewRequest(http.MethodGet, "/debug/outlierz"+tt.query, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d", w.Code)
+			}
+			var destinations []*model.DestinationOutlierStatus
+			if err := json.Unmarshal(w.Body.Bytes(), &destinations); err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, 0, len(destinations))
+			for _, d := range destinations {
+				got = append(got, string(d.Hostname))
+				if len(d.Proxies) != 1 || d.Proxies[0].Proxy != "productpage.default" {
+					t.Errorf("unexpected proxies of %s: %v", d.Hostname, d.Proxies)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got destinations %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,377 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"strings"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	tcp_filter "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
+	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	http_config "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/ext_authz/v2"
+	rbac_http_config "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/rbac/v2"
+	tcp_config "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/ext_authz/v2"
+	http_filter "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	rbac_tcp_config "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/rbac/v2"
+	envoy_rbac "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v2"
+	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/util"
+	authz_model "istio.io/istio/pilot/pkg/security/authz/model"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/mesh"
+)
+
+// ExtAuthzBuilder wraps all needed information for building the ext_authz filter for a workload.
+type ExtAuthzBuilder struct {
+	isXDSMarshalingToAnyEnabled bool
+	authorizer                  *mesh.ExternalAuthorizer
+	// paths are the path patterns of the requests to authorize, all the requests are authorized when empty.
+	paths []string
+	// denyAll is set when the authorizer of the policies is unknown, e.g. because the external authorizers
+	// configuration failed to load. The builder then builds RBAC filters denying all the requests.
+	denyAll bool
+}
+
+// NewExtAuthzBuilder creates a builder instance that can be used to build the ext_authz filter config, or nil
+// if no external authorizer applies to the workload. If the authorizer of the policies is unknown, the builder
+// builds filters denying all the requests, rather than leaving the workload unprotected.
+func NewExtAuthzBuilder(workloadLabels labels.Collection, configNamespace string,
+	policies *model.AuthorizationPolicies, isXDSMarshalingToAnyEnabled bool) *ExtAuthzBuilder {
+	configs := policies.ListExternalAuthorizationPolicies(configNamespace, workloadLabels)
+	if len(configs) == 0 {
+		return nil
+	}
+
+	// A workload is authorized by a single external authorizer, the one of its first policy.
+	name := configs[0].ExternalAuthorizer
+	authorizer := policies.ExternalAuthorizers.Get(name)
+	if authorizer == nil {
+		rbacLog.Errorf("external authorizer %q of authorization policy %s/%s not found, all the requests of "+
+			"workload %v in %s are denied", name, configs[0].Namespace, configs[0].Name, workloadLabels, configNamespace)
+		return &ExtAuthzBuilder{
+			isXDSMarshalingToAnyEnabled: isXDSMarshalingToAnyEnabled,
+			denyAll:                     true,
+		}
+	}
+
+	var paths []string
+	allPaths := false
+	for _, config := range configs {
+		if config.ExternalAuthorizer != name {
+			rbacLog.Warnf("ignored authorization policy %s/%s: workload %v already uses external authorizer %q",
+				config.Namespace, config.Name, workloadLabels, name)
+			continue
+		}
+		policyPaths, all := policyPaths(config)
+		if all {
+			allPaths = true
+		}
+		paths = append(paths, policyPaths...)
+	}
+	if allPaths {
+		paths = nil
+	}
+
+	rbacLog.Debugf("external authorizer %q enabled for workload %v in %s, paths %v", name, workloadLabels, configNamespace, paths)
+	return &ExtAuthzBuilder{
+		isXDSMarshalingToAnyEnabled: isXDSMarshalingToAnyEnabled,
+		authorizer:                  authorizer,
+		paths:                       paths,
+	}
+}
+
+// policyPaths returns the paths of the operations of the policy, or true if the policy applies to all paths.
+func policyPaths(config model.AuthorizationPolicyConfig) ([]string, bool) {
+	rules := config.AuthorizationPolicy.GetRules()
+	if len(rules) == 0 {
+		return nil, true
+	}
+	var paths []string
+	for _, rule := range rules {
+		if len(rule.To) == 0 {
+			return nil, true
+		}
+		for _, to := range rule.To {
+			if len(to.GetOperation().GetPaths()) == 0 {
+				return nil, true
+			}
+			for _, path := range to.Operation.Paths {
+				if path == "*" {
+					return nil, true
+				}
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths, false
+}
+
+// Paths returns the path patterns of the requests to authorize, or nil if all the requests are authorized.
+func (b *ExtAuthzBuilder) Paths() []string {
+	if b == nil {
+		return nil
+	}
+	return b.paths
+}
+
+func (b *ExtAuthzBuilder) clusterName() string {
+	return model.BuildSubsetKey(model.TrafficDirectionOutbound, "", host.Name(b.authorizer.Service), int(b.authorizer.Port))
+}
+
+func (b *ExtAuthzBuilder) grpcService() *core.GrpcService {
+	return &core.GrpcService{
+		TargetSpecifier: &core.GrpcService_EnvoyGrpc_{
+			EnvoyGrpc: &core.GrpcService_EnvoyGrpc{ClusterName: b.clusterName()},
+		},
+		Timeout: ptypes.DurationProto(b.authorizer.TimeoutDuration()),
+	}
+}
+
+func (b *ExtAuthzBuilder) httpService() *http_config.HttpService {
+	a := b.authorizer
+	service := &http_config.HttpService{
+		ServerUri: &core.HttpUri{
+			Uri:              "http://" + a.Service,
+			HttpUpstreamType: &core.HttpUri_Cluster{Cluster: b.clusterName()},
+			Timeout:          ptypes.DurationProto(a.TimeoutDuration()),
+		},
+		PathPrefix: a.PathPrefix,
+	}
+	if len(a.IncludeRequestHeaders) > 0 {
+		service.AuthorizationRequest = &http_config.AuthorizationRequest{
+			AllowedHeaders: exactMatchers(a.IncludeRequestHeaders),
+		}
+	}
+	if len(a.HeadersToUpstream) > 0 || len(a.HeadersToDownstream) > 0 {
+		service.AuthorizationResponse = &http_config.AuthorizationResponse{}
+		if len(a.HeadersToUpstream) > 0 {
+			service.AuthorizationResponse.AllowedUpstreamHeaders = exactMatchers(a.HeadersToUpstream)
+		}
+		if len(a.HeadersToDownstream) > 0 {
+			service.AuthorizationResponse.AllowedClientHeaders = exactMatchers(a.HeadersToDownstream)
+		}
+	}
+	return service
+}
+
+func exactMatchers(values []string) *matcher.ListStringMatcher {
+	out := &matcher.ListStringMatcher{}
+	for _, v := range values {
+		out.Patterns = append(out.Patterns, &matcher.StringMatcher{
+			MatchPattern: &matcher.StringMatcher_Exact{Exact: v},
+		})
+	}
+	return out
+}
+
+// BuildHTTPFilter builds the ext_authz HTTP filter, or the RBAC HTTP filter denying all the requests.
+func (b *ExtAuthzBuilder) BuildHTTPFilter() *http_filter.HttpFilter {
+	if b == nil {
+		return nil
+	}
+	if b.denyAll {
+		return b.buildDenyAllHTTPFilter()
+	}
+
+	extAuthz := &http_config.ExtAuthz{
+		FailureModeAllow: b.authorizer.FailOpen,
+	}
+	if b.authorizer.StatusOnError != 0 {
+		extAuthz.StatusOnError = &envoy_type.HttpStatus{Code: envoy_type.StatusCode(b.authorizer.StatusOnError)}
+	}
+	if b.authorizer.Protocol == mesh.ExternalAuthorizerProtocolHTTP {
+		extAuthz.Services = &http_config.ExtAuthz_HttpService{HttpService: b.httpService()}
+	} else {
+		extAuthz.Services = &http_config.ExtAuthz_GrpcService{GrpcService: b.grpcService()}
+	}
+
+	httpConfig := http_filter.HttpFilter{
+		Name: authz_model.ExtAuthzHTTPFilterName,
+	}
+	if b.isXDSMarshalingToAnyEnabled {
+		httpConfig.ConfigType = &http_filter.HttpFilter_TypedConfig{TypedConfig: util.MessageToAny(extAuthz)}
+	} else {
+		httpConfig.ConfigType = &http_filter.HttpFilter_Config{Config: util.MessageToStruct(extAuthz)}
+	}
+
+	rbacLog.Debugf("built ext_authz http filter config: %v", httpConfig)
+	return &httpConfig
+}
+
+// BuildTCPFilter builds the ext_authz network filter, or the RBAC network filter denying all the connections.
+// Only gRPC authorizers can authorize the connections, and a policy selecting paths never applies to them.
+func (b *ExtAuthzBuilder) BuildTCPFilter() *tcp_filter.Filter {
+	if b == nil {
+		return nil
+	}
+	if b.denyAll {
+		return b.buildDenyAllTCPFilter()
+	}
+	if b.authorizer.Protocol != mesh.ExternalAuthorizerProtocolGRPC || len(b.paths) > 0 {
+		return nil
+	}
+
+	extAuthz := &tcp_config.ExtAuthz{
+		StatPrefix:       authz_model.ExtAuthzTCPFilterStatPrefix,
+		GrpcService:      b.grpcService(),
+		FailureModeAllow: b.authorizer.FailOpen,
+	}
+
+	tcpConfig := tcp_filter.Filter{
+		Name: authz_model.ExtAuthzTCPFilterName,
+	}
+	if b.isXDSMarshalingToAnyEnabled {
+		tcpConfig.ConfigType = &tcp_filter.Filter_TypedConfig{TypedConfig: util.MessageToAny(extAuthz)}
+	} else {
+		tcpConfig.ConfigType = &tcp_filter.Filter_Config{Config: util.MessageToStruct(extAuthz)}
+	}
+
+	rbacLog.Debugf("built ext_authz tcp filter config: %v", tcpConfig)
+	return &tcpConfig
+}
+
+// denyAllRules returns the RBAC rules denying all the requests: the allowed requests are the ones matching one of
+// the policies, and there is none.
+func denyAllRules() *envoy_rbac.RBAC {
+	return &envoy_rbac.RBAC{Action: envoy_rbac.RBAC_ALLOW, Policies: map[string]*envoy_rbac.Policy{}}
+}
+
+func (b *ExtAuthzBuilder) buildDenyAllHTTPFilter() *http_filter.HttpFilter {
+	rbacConfig := &rbac_http_config.RBAC{Rules: denyAllRules()}
+	httpConfig := http_filter.HttpFilter{
+		Name: authz_model.RBACHTTPFilterName,
+	}
+	if b.isXDSMarshalingToAnyEnabled {
+		httpConfig.ConfigType = &http_filter.HttpFilter_TypedConfig{TypedConfig: util.MessageToAny(rbacConfig)}
+	} else {
+		httpConfig.ConfigType = &http_filter.HttpFilter_Config{Config: util.MessageToStruct(rbacConfig)}
+	}
+	return &httpConfig
+}
+
+func (b *ExtAuthzBuilder) buildDenyAllTCPFilter() *tcp_filter.Filter {
+	rbacConfig := &rbac_tcp_config.RBAC{
+		Rules:      denyAllRules(),
+		StatPrefix: authz_model.RBACTCPFilterStatPrefix,
+	}
+	tcpConfig := tcp_filter.Filter{
+		Name: authz_model.RBACTCPFilterName,
+	}
+	if b.isXDSMarshalingToAnyEnabled {
+		tcpConfig.ConfigType = &tcp_filter.Filter_TypedConfig{TypedConfig: util.MessageToAny(rbacConfig)}
+	} else {
+		tcpConfig.ConfigType = &tcp_filter.Filter_Config{Config: util.MessageToStruct(rbacConfig)}
+	}
+	return &tcpConfig
+}
+
+// ApplyToRoutes restricts the ext_authz HTTP filter to the routes of the paths to authorize. Routes outside of
+// the paths disable the filter, and routes partially matching them are preceded by narrowed copies keeping it
+// enabled. Routes which cannot be compared with the paths, e.g. regex routes, keep the filter enabled.
+func (b *ExtAuthzBuilder) ApplyToRoutes(routeConfiguration *xdsapi.RouteConfiguration) {
+	if b == nil || b.denyAll || len(b.paths) == 0 {
+		return
+	}
+
+	for _, virtualHost := range routeConfiguration.VirtualHosts {
+		routes := make([]*route.Route, 0, len(virtualHost.Routes))
+		for _, r := range virtualHost.Routes {
+			covered, narrowed := b.matchRoute(r.GetMatch())
+			if covered {
+				routes = append(routes, r)
+				continue
+			}
+			for _, match := range narrowed {
+				copied := proto.Clone(r).(*route.Route)
+				copied.Match = match
+				if copied.Name != "" {
+					copied.Name += ".ext-authz"
+				}
+				routes = append(routes, copied)
+			}
+			r.TypedPerFilterConfig = disableExtAuthz(r.TypedPerFilterConfig)
+			routes = append(routes, r)
+		}
+		virtualHost.Routes = routes
+	}
+}
+
+// matchRoute returns true if all the requests of the route match the paths to authorize, otherwise the matches
+// of the requests of the route which do.
+func (b *ExtAuthzBuilder) matchRoute(match *route.RouteMatch) (bool, []*route.RouteMatch) {
+	var routePath string
+	routeIsPrefix := false
+	switch p := match.GetPathSpecifier().(type) {
+	case *route.RouteMatch_Prefix:
+		routePath, routeIsPrefix = p.Prefix, true
+	case *route.RouteMatch_Path:
+		routePath = p.Path
+	default:
+		return true, nil
+	}
+
+	var narrowed []*route.RouteMatch
+	for _, path := range b.paths {
+		switch {
+		case strings.HasPrefix(path, "*"):
+			// Suffix paths can match any route.
+			return true, nil
+		case strings.HasSuffix(path, "*"):
+			prefix := strings.TrimSuffix(path, "*")
+			if strings.HasPrefix(routePath, prefix) {
+				return true, nil
+			}
+			if routeIsPrefix && strings.HasPrefix(prefix, routePath) {
+				narrowed = append(narrowed, narrowMatch(match, prefix, true))
+			}
+		default:
+			if path == routePath && !routeIsPrefix {
+				return true, nil
+			}
+			if routeIsPrefix && strings.HasPrefix(path, routePath) {
+				narrowed = append(narrowed, narrowMatch(match, path, false))
+			}
+		}
+	}
+	return false, narrowed
+}
+
+func narrowMatch(match *route.RouteMatch, path string, isPrefix bool) *route.RouteMatch {
+	out := proto.Clone(match).(*route.RouteMatch)
+	if isPrefix {
+		out.PathSpecifier = &route.RouteMatch_Prefix{Prefix: path}
+	} else {
+		out.PathSpecifier = &route.RouteMatch_Path{Path: path}
+	}
+	return out
+}
+
+func disableExtAuthz(filterConfigs map[string]*any.Any) map[string]*any.Any {
+	if filterConfigs == nil {
+		filterConfigs = make(map[string]*any.Any)
+	}
+	filterConfigs[authz_model.ExtAuthzHTTPFilterName] = util.MessageToAny(&http_config.ExtAuthzPerRoute{
+		Override: &http_config.ExtAuthzPerRoute_Disabled{Disabled: true},
+	})
+	return filterConfigs
+}
@@ -0,0 +1,300 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"reflect"
+	"testing"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	http_config "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/ext_authz/v2"
+	rbac_http_config "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/rbac/v2"
+	tcp_config "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/ext_authz/v2"
+	rbac_tcp_config "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/rbac/v2"
+	envoy_rbac "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v2"
+	"github.com/golang/protobuf/ptypes"
+
+	authpb "istio.io/api/security/v1beta1"
+
+	"istio.io/istio/pilot/pkg/model"
+	authz_model "istio.io/istio/pilot/pkg/security/authz/model"
+	"istio.io/istio/pkg/config/mesh"
+)
+
+func newExternalPolicies(authorizer string, paths ...[]string) *model.AuthorizationPolicies {
+	var configs []model.AuthorizationPolicyConfig
+	for _, p := range paths {
+		policy := &authpb.AuthorizationPolicy{}
+		if p != nil {
+			policy.Rules = []*authpb.Rule{{To: []*authpb.Rule_To{{Operation: &authpb.Operation{Paths: p}}}}}
+		}
+		configs = append(configs, model.AuthorizationPolicyConfig{
+			Name:                "ext",
+			Namespace:           "a",
+			AuthorizationPolicy: policy,
+			ExternalAuthorizer:  authorizer,
+		})
+	}
+	return &model.AuthorizationPolicies{
+		NamespaceToExternalPolicies: map[string][]model.AuthorizationPolicyConfig{"a": configs},
+		ExternalAuthorizers: &mesh.ExternalAuthorizers{
+			Authorizers: []*mesh.ExternalAuthorizer{
+				{Name: "grpc", Service: "authz.sso.svc.cluster.local", Port: 9191, Protocol: "GRPC", StatusOnError: 503},
+				{Name: "http", Service: "opa.opa.svc.cluster.local", Port: 8181, Protocol: "HTTP", FailOpen: true,
+					Timeout: "1s", IncludeRequestHeaders: []string{"authorization"}},
+			},
+		},
+	}
+}
+
+func TestExtAuthzBuilder_DenyAll(t *testing.T) {
+	unloaded := newExternalPolicies("grpc", []string{"/api/*"})
+	unloaded.ExternalAuthorizers = nil
+	cases := []struct {
+		name     string
+		policies *model.AuthorizationPolicies
+	}{
+		{name: "unknown authorizer", policies: newExternalPolicies("unknown", []string{"/api/*"})},
+		{name: "authorizers not loaded", policies: unloaded},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := NewExtAuthzBuilder(nil, "a", tc.policies, true)
+			if b == nil {
+				t.Fatalf("want a builder denying all the requests")
+			}
+			if b.Paths() != nil {
+				t.Errorf("got paths %v but want none", b.Paths())
+			}
+
+			httpFilter := b.BuildHTTPFilter()
+			if httpFilter.Name != authz_model.RBACHTTPFilterName {
+				t.Errorf("got filter name %q but want %q", httpFilter.Name, authz_model.RBACHTTPFilterName)
+			}
+			httpConfig := &rbac_http_config.RBAC{}
+			if err := ptypes.UnmarshalAny(httpFilter.GetTypedConfig(), httpConfig); err != nil {
+				t.Fatalf("failed to unmarshal the http filter config: %v", err)
+			}
+			if httpConfig.GetRules().GetAction() != envoy_rbac.RBAC_ALLOW || len(httpConfig.GetRules().GetPolicies()) != 0 {
+				t.Errorf("got rules %v but want to deny all the requests", httpConfig.GetRules())
+			}
+
+			tcpFilter := b.BuildTCPFilter()
+			if tcpFilter.Name != authz_model.RBACTCPFilterName {
+				t.Errorf("got filter name %q but want %q", tcpFilter.Name, authz_model.RBACTCPFilterName)
+			}
+			tcpConfig := &rbac_tcp_config.RBAC{}
+			if err := ptypes.UnmarshalAny(tcpFilter.GetTypedConfig(), tcpConfig); err != nil {
+				t.Fatalf("failed to unmarshal the tcp filter config: %v", err)
+			}
+			if tcpConfig.GetRules().GetAction() != envoy_rbac.RBAC_ALLOW || len(tcpConfig.GetRules().GetPolicies()) != 0 {
+				t.Errorf("got rules %v but want to deny all the connections", tcpConfig.GetRules())
+			}
+
+			routeConfiguration := &xdsapi.RouteConfiguration{VirtualHosts: []*route.VirtualHost{{
+				Routes: []*route.Route{{Match: &route.RouteMatch{PathSpecifier: &route.RouteMatch_Prefix{Prefix: "/"}}}},
+			}}}
+			b.ApplyToRoutes(routeConfiguration)
+			if got := routeConfiguration.VirtualHosts[0].Routes; len(got) != 1 || got[0].PerFilterConfig != nil ||
+				got[0].TypedPerFilterConfig != nil {
+				t.Errorf("got routes %v but want them unchanged", got)
+			}
+		})
+	}
+}
+
+func TestExtAuthzBuilder_BuildFilters(t *testing.T) {
+	if b := NewExtAuthzBuilder(nil, "a", &model.AuthorizationPolicies{}, true); b != nil {
+		t.Errorf("want no builder without external policies")
+	}
+	b := NewExtAuthzBuilder(nil, "a", newExternalPolicies("grpc", nil), true)
+	httpFilter := b.BuildHTTPFilter()
+	if httpFilter.Name != authz_model.ExtAuthzHTTPFilterName {
+		t.Errorf("got filter name %q but want %q", httpFilter.Name, authz_model.ExtAuthzHTTPFilterName)
+	}
+	httpConfig := &http_config.ExtAuthz{}
+	if err := ptypes.UnmarshalAny(httpFilter.GetTypedConfig(), httpConfig); err != nil {
+		t.Fatalf("failed to unmarshal the http filter config: %v", err)
+	}
+	if got := httpConfig.GetGrpcService().GetEnvoyGrpc().GetClusterName(); got != "outbound|9191||authz.sso.svc.cluster.local" {
+		t.Errorf("got cluster %q", got)
+	}
+	if got, _ := ptypes.Duration(httpConfig.GetGrpcService().GetTimeout()); got != mesh.DefaultExternalAuthorizerTimeout {
+		t.Errorf("got timeout %v but want the default", got)
+	}
+	if httpConfig.FailureModeAllow || httpConfig.GetStatusOnError().GetCode() != 503 {
+		t.Errorf("got failure mode allow %v and status on error %v", httpConfig.FailureModeAllow, httpConfig.StatusOnError)
+	}
+	tcpConfig := &tcp_config.ExtAuthz{}
+	if err := ptypes.UnmarshalAny(b.BuildTCPFilter().GetTypedConfig(), tcpConfig); err != nil {
+		t.Fatalf("failed to unmarshal the tcp filter config: %v", err)
+	}
+	if tcpConfig.GetGrpcService().GetEnvoyGrpc().GetClusterName() != "outbound|9191||authz.sso.svc.cluster.local" {
+		t.Errorf("got tcp filter config %v", tcpConfig)
+	}
+
+	b = NewExtAuthzBuilder(nil, "a", newExternalPolicies("http", nil), true)
+	if err := ptypes.UnmarshalAny(b.BuildHTTPFilter().GetTypedConfig(), httpConfig); err != nil {
+		t.Fatalf("failed to unmarshal the http filter config: %v", err)
+	}
+	service := httpConfig.GetHttpService()
+	if service.GetServerUri().GetCluster() != "outbound|8181||opa.opa.svc.cluster.local" || !httpConfig.FailureModeAllow {
+		t.Errorf("got http filter config %v", httpConfig)
+	}
+	if got, _ := ptypes.Duration(service.GetServerUri().GetTimeout()); got.Seconds() != 1 {
+		t.Errorf("got timeout %v but want 1s", got)
+	}
+	if got := service.GetAuthorizationRequest().GetAllowedHeaders().GetPatterns()[0].GetExact(); got != "authorization" {
+		t.Errorf("got allowed header %q but want authorization", got)
+	}
+	if b.BuildTCPFilter() != nil {
+		t.Errorf("want no tcp filter for an HTTP authorizer")
+	}
+
+	b = NewExtAuthzBuilder(nil, "a", newExternalPolicies("grpc", []string{"/admin*"}), false)
+	if b.BuildHTTPFilter().GetConfig() == nil {
+		t.Errorf("want struct config when isXDSMarshalingToAnyEnabled is false")
+	}
+	if b.BuildTCPFilter() != nil {
+		t.Errorf("want no tcp filter for a policy selecting paths")
+	}
+}
+
+func TestExtAuthzBuilder_Paths(t *testing.T) {
+	cases := []struct {
+		name  string
+		paths [][]string
+		want  []string
+	}{
+		{
+			name:  "no rules",
+			paths: [][]string{nil},
+		},
+		{
+			name:  "wildcard",
+			paths: [][]string{{"/admin", "*"}},
+		},
+		{
+			name:  "one policy",
+			paths: [][]string{{"/admin", "/api/*"}},
+			want:  []string{"/admin", "/api/*"},
+		},
+		{
+			name:  "merged policies",
+			paths: [][]string{{"/admin"}, {"/api/*"}},
+			want:  []string{"/admin", "/api/*"},
+		},
+		{
+			name:  "merged policies with all paths",
+			paths: [][]string{{"/admin"}, nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := NewExtAuthzBuilder(nil, "a", newExternalPolicies("grpc", tc.paths...), true).Paths()
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got paths %v but want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExtAuthzBuilder_ApplyToRoutes(t *testing.T) {
+	prefix := func(p string) *route.Route {
+		return &route.Route{Name: p, Match: &route.RouteMatch{PathSpecifier: &route.RouteMatch_Prefix{Prefix: p}}}
+	}
+	exact := func(p string) *route.Route {
+		return &route.Route{Name: p, Match: &route.RouteMatch{PathSpecifier: &route.RouteMatch_Path{Path: p}}}
+	}
+	regex := &route.Route{Name: "regex", Match: &route.RouteMatch{PathSpecifier: &route.RouteMatch_Regex{Regex: "/.*"}}}
+
+	type wantRoute struct {
+		match    string
+		disabled bool
+	}
+	cases := []struct {
+		name   string
+		paths  []string
+		routes []*route.Route
+		want   []wantRoute
+	}{
+		{
+			name:   "all paths",
+			routes: []*route.Route{prefix("/")},
+			want:   []wantRoute{{match: "prefix:/"}},
+		},
+		{
+			name:   "route inside the prefix",
+			paths:  []string{"/api/*"},
+			routes: []*route.Route{prefix("/api/v1"), exact("/api/v2")},
+			want:   []wantRoute{{match: "prefix:/api/v1"}, {match: "path:/api/v2"}},
+		},
+		{
+			name:   "route outside of the paths",
+			paths:  []string{"/api/*", "/admin"},
+			routes: []*route.Route{prefix("/static"), exact("/admin/x")},
+			want:   []wantRoute{{match: "prefix:/static", disabled: true}, {match: "path:/admin/x", disabled: true}},
+		},
+		{
+			name:   "route overlapping the paths",
+			paths:  []string{"/api/*", "/admin"},
+			routes: []*route.Route{prefix("/")},
+			want: []wantRoute{
+				{match: "prefix:/api/"},
+				{match: "path:/admin"},
+				{match: "prefix:/", disabled: true},
+			},
+		},
+		{
+			name:   "unsupported patterns fail closed",
+			paths:  []string{"*.php"},
+			routes: []*route.Route{prefix("/")},
+			want:   []wantRoute{{match: "prefix:/"}},
+		},
+		{
+			name:   "regex route fails closed",
+			paths:  []string{"/admin"},
+			routes: []*route.Route{regex},
+			want:   []wantRoute{{match: "regex"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := NewExtAuthzBuilder(nil, "a", newExternalPolicies("grpc", tc.paths), true)
+			rc := &xdsapi.RouteConfiguration{VirtualHosts: []*route.VirtualHost{{Routes: tc.routes}}}
+			b.ApplyToRoutes(rc)
+
+			var got []wantRoute
+			for _, r := range rc.VirtualHosts[0].Routes {
+				var match string
+				switch p := r.Match.PathSpecifier.(type) {
+				case *route.RouteMatch_Prefix:
+					match = "prefix:" + p.Prefix
+				case *route.RouteMatch_Path:
+					match = "path:" + p.Path
+				default:
+					match = "regex"
+				}
+				got = append(got, wantRoute{match: match, disabled: r.TypedPerFilterConfig[authz_model.ExtAuthzHTTPFilterName] != nil})
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got routes %v but want %v", got, tc.want)
+			}
+		})
+	}
+}
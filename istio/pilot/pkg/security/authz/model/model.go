@@ -35,6 +35,13 @@ const (
 	RBACTCPFilterName       = "envoy.filters.network.rbac"
 	RBACTCPFilterStatPrefix = "tcp."
 
+	// ExtAuthzHTTPFilterName is the name of the external authorization http filter in envoy.
+	ExtAuthzHTTPFilterName = "envoy.ext_authz"
+
+	// ExtAuthzTCPFilterName is the name of the external authorization network filter in envoy.
+	ExtAuthzTCPFilterName       = "envoy.ext_authz"
+	ExtAuthzTCPFilterStatPrefix = "tcp."
+
 	// attributes that could be used in both ServiceRoleBinding and ServiceRole.
 	attrRequestHeader = "request.headers" // header name is surrounded by brackets, e.g. "request.headers[User-Agent]".
 
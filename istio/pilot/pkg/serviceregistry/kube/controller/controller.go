@@ -326,7 +326,8 @@ func registerHandlers(informer cache.SharedIndexInformer, q queue.Instance, otyp
 }
 
 // compareEndpoints returns true if the two endpoints are the same in aspects Pilot cares about
-// This currently means only looking at "Ready" endpoints
+// This currently means looking at the "Ready" endpoints, and at the "NotReady" ones which are
+// counted in the health of the localities
 func compareEndpoints(a, b *v1.Endpoints) bool {
 	if len(a.Subsets) != len(b.Subsets) {
 		return false
@@ -338,6 +339,9 @@ func compareEndpoints(a, b *v1.Endpoints) bool {
 		if !reflect.DeepEqual(a.Subsets[i].Addresses, b.Subsets[i].Addresses) {
 			return false
 		}
+		if !reflect.DeepEqual(a.Subsets[i].NotReadyAddresses, b.Subsets[i].NotReadyAddresses) {
+			return false
+		}
 	}
 	return true
 }
@@ -802,7 +806,13 @@ func (c *Controller) updateEDS(ep *v1.Endpoints, event model.Event) {
 	endpoints := make([]*model.IstioEndpoint, 0)
 	if event != model.EventDelete {
 		for _, ss := range ep.Subsets {
-			for _, ea := range ss.Addresses {
+			// The addresses which are not ready are tracked as unhealthy endpoints, to compute the
+			// health of the localities.
+			addresses := make([]v1.EndpointAddress, 0, len(ss.Addresses)+len(ss.NotReadyAddresses))
+			addresses = append(addresses, ss.Addresses...)
+			addresses = append(addresses, ss.NotReadyAddresses...)
+			for i, ea := range addresses {
+				unhealthy := i >= len(ss.Addresses)
 				pod := c.pods.getPodByIP(ea.IP)
 				if pod == nil {
 					// This means, the endpoint event has arrived before pod event. This might happen because
@@ -846,6 +856,7 @@ func (c *Controller) updateEDS(ep *v1.Endpoints, event model.Event) {
 						Locality:        locality,
 						Attributes:      model.ServiceAttributes{Name: ep.Name, Namespace: ep.Namespace},
 						TLSMode:         tlsMode,
+						Unhealthy:       unhealthy,
 					})
 				}
 			}
@@ -855,7 +866,9 @@ func (c *Controller) updateEDS(ep *v1.Endpoints, event model.Event) {
 	if log.InfoEnabled() {
 		var addresses []string
 		for _, iep := range endpoints {
-			addresses = append(addresses, iep.Address)
+			if !iep.Unhealthy {
+				addresses = append(addresses, iep.Address)
+			}
 		}
 		log.Infof("Handle EDS endpoint %s in namespace %s -> %v", ep.Name, ep.Namespace, addresses)
 	}
@@ -1186,6 +1186,22 @@ func TestCompareEndpoints(t *testing.T) {
 			&v1.Endpoints{Subsets: []v1.EndpointSubset{
 				{Addresses: []v1.EndpointAddress{addressA}},
 			}},
+			false,
+		},
+		{
+			"same ready and not ready addresses",
+			&v1.Endpoints{Subsets: []v1.EndpointSubset{
+				{
+					NotReadyAddresses: []v1.EndpointAddress{addressB},
+					Addresses:         []v1.EndpointAddress{addressA},
+				},
+			}},
+			&v1.Endpoints{Subsets: []v1.EndpointSubset{
+				{
+					NotReadyAddresses: []v1.EndpointAddress{addressB},
+					Addresses:         []v1.EndpointAddress{addressA},
+				},
+			}},
 			true,
 		},
 		{
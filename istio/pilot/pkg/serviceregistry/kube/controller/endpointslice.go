@@ -70,10 +70,8 @@ func (e *endpointSliceController) updateEDSSlice(c *Controller, slice *discovery
 	endpoints := make([]*model.IstioEndpoint, 0)
 	if event != model.EventDelete {
 		for _, e := range slice.Endpoints {
-			if e.Conditions.Ready != nil && !*e.Conditions.Ready {
-				// Ignore not ready endpoints
-				continue
-			}
+			// Not ready endpoints are tracked as unhealthy endpoints, to compute the health of the localities.
+			unhealthy := e.Conditions.Ready != nil && !*e.Conditions.Ready
 			for _, a := range e.Addresses {
 				pod := c.pods.getPodByIP(a)
 				if pod == nil {
@@ -123,6 +121,7 @@ func (e *endpointSliceController) updateEDSSlice(c *Controller, slice *discovery
 						Locality:        locality,
 						Attributes:      model.ServiceAttributes{Name: svcName, Namespace: slice.Namespace},
 						TLSMode:         tlsMode,
+						Unhealthy:       unhealthy,
 					})
 				}
 			}
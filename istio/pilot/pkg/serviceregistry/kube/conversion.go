@@ -43,6 +43,10 @@ const (
 	// responsible for it
 	IngressClassAnnotation = "kubernetes.io/ingress.class"
 
+	// LocalityOverprovisioningFactorAnnotation is the annotation on services enabling the health aware
+	// locality load balancing, with the overprovisioning factor in percent, e.g. "140".
+	LocalityOverprovisioningFactorAnnotation = "networking.istio.io/localityOverprovisioningFactor"
+
 	managementPortPrefix = "mgmt-"
 )
 
@@ -79,6 +83,7 @@ func ConvertService(svc coreV1.Service, domainSuffix string, clusterID string) *
 	}
 
 	var exportTo map[visibility.Instance]bool
+	var overprovisioningFactor uint32
 	serviceaccounts := make([]string, 0)
 	if svc.Annotations != nil {
 		if svc.Annotations[annotation.AlphaCanonicalServiceAccounts.Name] != "" {
@@ -95,6 +100,9 @@ func ConvertService(svc coreV1.Service, domainSuffix string, clusterID string) *
 				exportTo[visibility.Instance(e)] = true
 			}
 		}
+		if factor, err := strconv.ParseUint(svc.Annotations[LocalityOverprovisioningFactorAnnotation], 10, 32); err == nil {
+			overprovisioningFactor = uint32(factor)
+		}
 	}
 	sort.Strings(serviceaccounts)
 
@@ -107,11 +115,12 @@ func ConvertService(svc coreV1.Service, domainSuffix string, clusterID string) *
 		Resolution:      resolution,
 		CreationTime:    svc.CreationTimestamp.Time,
 		Attributes: model.ServiceAttributes{
-			ServiceRegistry: string(serviceregistry.Kubernetes),
-			Name:            svc.Name,
-			Namespace:       svc.Namespace,
-			UID:             fmt.Sprintf("istio://%s/services/%s", svc.Namespace, svc.Name),
-			ExportTo:        exportTo,
+			ServiceRegistry:                string(serviceregistry.Kubernetes),
+			Name:                           svc.Name,
+			Namespace:                      svc.Namespace,
+			UID:                            fmt.Sprintf("istio://%s/services/%s", svc.Namespace, svc.Name),
+			ExportTo:                       exportTo,
+			LocalityOverprovisioningFactor: overprovisioningFactor,
 		},
 	}
 
@@ -147,7 +147,8 @@ func TestServiceConversion(t *testing.T) {
 			Annotations: map[string]string{
 				annotation.AlphaKubernetesServiceAccounts.Name: saA + "," + saB,
 				annotation.AlphaCanonicalServiceAccounts.Name:  saC + "," + saD,
-				"other/annotation": "test",
+				LocalityOverprovisioningFactorAnnotation:       "200",
+				"other/annotation":                             "test",
 			},
 			CreationTimestamp: metaV1.Time{Time: tnow},
 		},
@@ -207,6 +208,10 @@ func TestServiceConversion(t *testing.T) {
 	if !reflect.DeepEqual(sa, expected) {
 		t.Fatalf("Unexpected service accounts %v (expecting %v)", sa, expected)
 	}
+
+	if service.Attributes.LocalityOverprovisioningFactor != 200 {
+		t.Fatalf("locality overprovisioning factor incorrect => %d, want 200", service.Attributes.LocalityOverprovisioningFactor)
+	}
 }
 
 func TestServiceConversionWithEmptyServiceAccountsAnnotation(t *testing.T) {
@@ -0,0 +1,239 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mesh
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/ghodss/yaml"
+	"github.com/hashicorp/go-multierror"
+
+	"istio.io/pkg/filewatcher"
+	"istio.io/pkg/log"
+)
+
+const (
+	// ExternalAuthorizerProtocolGRPC is the protocol of the authorization services implementing the
+	// envoy.service.auth.v2.Authorization gRPC service.
+	ExternalAuthorizerProtocolGRPC = "GRPC"
+	// ExternalAuthorizerProtocolHTTP is the protocol of the authorization services receiving the headers of the
+	// requests to authorize, and allowing them with a 2xx response.
+	ExternalAuthorizerProtocolHTTP = "HTTP"
+
+	// DefaultExternalAuthorizerTimeout is the timeout of the authorization requests when none is configured.
+	DefaultExternalAuthorizerTimeout = 200 * time.Millisecond
+)
+
+// ExternalAuthorizer declares an external authorization service, which Envoy calls to authorize the requests.
+type ExternalAuthorizer struct {
+	// Name is the name of the authorizer, referenced by the authorization policies.
+	Name string `json:"name"`
+
+	// Service is the hostname of the authorization service, e.g. "authz.sso.svc.cluster.local".
+	Service string `json:"service"`
+
+	// Port is the port of the authorization service.
+	Port uint32 `json:"port"`
+
+	// Protocol is the protocol of the authorization service, GRPC or HTTP.
+	Protocol string `json:"protocol"`
+
+	// Timeout is the timeout of the authorization requests, e.g. "500ms". Defaults to 200ms.
+	Timeout string `json:"timeout,omitempty"`
+
+	// FailOpen allows the requests when the authorization service fails or cannot be reached.
+	FailOpen bool `json:"failOpen,omitempty"`
+
+	// StatusOnError is the HTTP status returned to the client when the authorization service fails and FailOpen
+	// is false. Defaults to 403.
+	StatusOnError uint32 `json:"statusOnError,omitempty"`
+
+	// PathPrefix is prepended to the path of the authorization requests. HTTP only.
+	PathPrefix string `json:"pathPrefix,omitempty"`
+
+	// IncludeRequestHeaders are the headers of the client request sent to the authorization service, in
+	// addition to Host, Method, Path and Content-Length. HTTP only, the gRPC service receives all of them.
+	IncludeRequestHeaders []string `json:"includeRequestHeaders,omitempty"`
+
+	// HeadersToUpstream are the headers of the authorization response added to the allowed requests. HTTP only.
+	HeadersToUpstream []string `json:"headersToUpstream,omitempty"`
+
+	// HeadersToDownstream are the headers of the authorization response added to the denied responses.
+	// HTTP only.
+	HeadersToDownstream []string `json:"headersToDownstream,omitempty"`
+}
+
+// ExternalAuthorizers is the configuration of the external authorizers of the mesh.
+type ExternalAuthorizers struct {
+	Authorizers []*ExternalAuthorizer `json:"authorizers"`
+}
+
+// Get returns the authorizer with the name, or nil if there is none.
+func (a *ExternalAuthorizers) Get(name string) *ExternalAuthorizer {
+	if a == nil {
+		return nil
+	}
+	for _, authorizer := range a.Authorizers {
+		if authorizer.Name == name {
+			return authorizer
+		}
+	}
+	return nil
+}
+
+// TimeoutDuration returns the timeout of the authorization requests.
+func (a *ExternalAuthorizer) TimeoutDuration() time.Duration {
+	if d, err := time.ParseDuration(a.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return DefaultExternalAuthorizerTimeout
+}
+
+// ParseExternalAuthorizers parses and validates the configuration of the external authorizers.
+func ParseExternalAuthorizers(yamlText string) (*ExternalAuthorizers, error) {
+	out := &ExternalAuthorizers{}
+	if err := yaml.Unmarshal([]byte(yamlText), out); err != nil {
+		return nil, multierror.Prefix(err, "failed to parse external authorizers config")
+	}
+
+	var errs error
+	names := map[string]bool{}
+	for _, a := range out.Authorizers {
+		if a.Name == "" {
+			errs = multierror.Append(errs, fmt.Errorf("external authorizer without name"))
+			continue
+		}
+		if names[a.Name] {
+			errs = multierror.Append(errs, fmt.Errorf("duplicate external authorizer %q", a.Name))
+		}
+		names[a.Name] = true
+		if a.Service == "" {
+			errs = multierror.Append(errs, fmt.Errorf("external authorizer %q: service is required", a.Name))
+		}
+		if a.Port == 0 || a.Port > 65535 {
+			errs = multierror.Append(errs, fmt.Errorf("external authorizer %q: invalid port %d", a.Name, a.Port))
+		}
+		if a.Protocol != ExternalAuthorizerProtocolGRPC && a.Protocol != ExternalAuthorizerProtocolHTTP {
+			errs = multierror.Append(errs, fmt.Errorf("external authorizer %q: protocol must be %s or %s",
+				a.Name, ExternalAuthorizerProtocolGRPC, ExternalAuthorizerProtocolHTTP))
+		}
+		if a.Timeout != "" {
+			if d, err := time.ParseDuration(a.Timeout); err != nil || d <= 0 {
+				errs = multierror.Append(errs, fmt.Errorf("external authorizer %q: invalid timeout %q", a.Name, a.Timeout))
+			}
+		}
+		if a.StatusOnError != 0 && (a.StatusOnError < 200 || a.StatusOnError > 599) {
+			errs = multierror.Append(errs, fmt.Errorf("external authorizer %q: invalid status on error %d",
+				a.Name, a.StatusOnError))
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return out, nil
+}
+
+// ReadExternalAuthorizers gets the configuration of the external authorizers from a config file.
+func ReadExternalAuthorizers(filename string) (*ExternalAuthorizers, error) {
+	yamlText, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, multierror.Prefix(err, "cannot read external authorizers config file")
+	}
+	return ParseExternalAuthorizers(string(yamlText))
+}
+
+// ExternalAuthorizersWatcher watches changes to the external authorizers config.
+type ExternalAuthorizersWatcher interface {
+	// ExternalAuthorizers returns the latest external authorizers configuration, or nil if none was loaded.
+	ExternalAuthorizers() *ExternalAuthorizers
+
+	AddExternalAuthorizersHandler(func())
+}
+
+var _ ExternalAuthorizersWatcher = &externalAuthorizersWatcher{}
+
+type externalAuthorizersWatcher struct {
+	mutex       sync.Mutex
+	handlers    []func()
+	authorizers *ExternalAuthorizers
+}
+
+// NewFixedExternalAuthorizersWatcher creates a new ExternalAuthorizersWatcher that always returns the given config.
+// It will never fire any events, since the config never changes.
+func NewFixedExternalAuthorizersWatcher(authorizers *ExternalAuthorizers) ExternalAuthorizersWatcher {
+	return &externalAuthorizersWatcher{
+		authorizers: authorizers,
+	}
+}
+
+// NewExternalAuthorizersWatcher creates a new watcher for changes to the given external authorizers config file.
+// The file is watched even if it fails to load, the authorizers are then unknown until it is fixed. A config
+// failing to reload is ignored, and the previous one is kept.
+func NewExternalAuthorizersWatcher(fileWatcher filewatcher.FileWatcher, filename string) ExternalAuthorizersWatcher {
+	w := &externalAuthorizersWatcher{}
+	authorizers, err := ReadExternalAuthorizers(filename)
+	if err != nil {
+		log.Errorf("failed to read external authorizers configuration from %q, the authorization policies "+
+			"delegating to external authorizers deny all the requests: %v", filename, err)
+	} else {
+		log.Infof("loaded %d external authorizers", len(authorizers.Authorizers))
+		w.authorizers = authorizers
+	}
+
+	// Watch the external authorizers config file for changes and reload if it got modified
+	addFileWatcher(fileWatcher, filename, func() {
+		authorizers, err := ReadExternalAuthorizers(filename)
+		if err != nil {
+			log.Errorf("failed to reload external authorizers configuration from %q, keeping the previous one: %v",
+				filename, err)
+			return
+		}
+
+		var handlers []func()
+
+		w.mutex.Lock()
+		if !reflect.DeepEqual(authorizers, w.authorizers) {
+			log.Infof("external authorizers configuration file updated, loaded %d external authorizers",
+				len(authorizers.Authorizers))
+			atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&w.authorizers)), unsafe.Pointer(authorizers))
+			handlers = append([]func(){}, w.handlers...)
+		}
+		w.mutex.Unlock()
+
+		// Notify the handlers of the change.
+		for _, h := range handlers {
+			h()
+		}
+	})
+	return w
+}
+
+// ExternalAuthorizers returns the latest external authorizers configuration.
+func (w *externalAuthorizersWatcher) ExternalAuthorizers() *ExternalAuthorizers {
+	return (*ExternalAuthorizers)(atomic.LoadPointer((*unsafe.Pointer)(unsafe.Pointer(&w.authorizers))))
+}
+
+// AddExternalAuthorizersHandler registers a callback handler for changes to the external authorizers config.
+func (w *externalAuthorizersWatcher) AddExternalAuthorizersHandler(h func()) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.handlers = append(w.handlers, h)
+}
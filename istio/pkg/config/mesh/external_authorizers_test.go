@@ -0,0 +1,152 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mesh_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"istio.io/pkg/filewatcher"
+
+	"istio.io/istio/pkg/config/mesh"
+)
+
+func TestParseExternalAuthorizers(t *testing.T) {
+	cases := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			yaml: `
+authorizers:
+- name: sso
+  service: authz.sso.svc.cluster.local
+  port: 9191
+  protocol: GRPC
+  timeout: 500ms
+- name: opa
+  service: opa.opa.svc.cluster.local
+  port: 8181
+  protocol: HTTP
+  failOpen: true
+  statusOnError: 503
+  includeRequestHeaders: [authorization]
+`,
+		},
+		{
+			name:    "missing name",
+			yaml:    "authorizers: [{service: a, port: 80, protocol: GRPC}]",
+			wantErr: "without name",
+		},
+		{
+			name:    "duplicate name",
+			yaml:    "authorizers: [{name: a, service: a, port: 80, protocol: GRPC}, {name: a, service: b, port: 80, protocol: GRPC}]",
+			wantErr: "duplicate",
+		},
+		{
+			name:    "missing service",
+			yaml:    "authorizers: [{name: a, port: 80, protocol: GRPC}]",
+			wantErr: "service is required",
+		},
+		{
+			name:    "invalid port",
+			yaml:    "authorizers: [{name: a, service: a, port: 70000, protocol: GRPC}]",
+			wantErr: "invalid port",
+		},
+		{
+			name:    "invalid protocol",
+			yaml:    "authorizers: [{name: a, service: a, port: 80, protocol: TCP}]",
+			wantErr: "protocol must be",
+		},
+		{
+			name:    "invalid timeout",
+			yaml:    "authorizers: [{name: a, service: a, port: 80, protocol: GRPC, timeout: soon}]",
+			wantErr: "invalid timeout",
+		},
+		{
+			name:    "invalid status on error",
+			yaml:    "authorizers: [{name: a, service: a, port: 80, protocol: GRPC, statusOnError: 99}]",
+			wantErr: "invalid status on error",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := mesh.ParseExternalAuthorizers(c.yaml)
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("ParseExternalAuthorizers() got error %v, want %q", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseExternalAuthorizers() failed: %v", err)
+			}
+			if len(got.Authorizers) != 2 {
+				t.Fatalf("got %d authorizers, want 2", len(got.Authorizers))
+			}
+			if d := got.Get("sso").TimeoutDuration(); d != 500*time.Millisecond {
+				t.Errorf("got timeout %v for sso, want 500ms", d)
+			}
+			if d := got.Get("opa").TimeoutDuration(); d != mesh.DefaultExternalAuthorizerTimeout {
+				t.Errorf("got timeout %v for opa, want the default", d)
+			}
+			if got.Get("missing") != nil {
+				t.Errorf("got an authorizer for an unknown name")
+			}
+		})
+	}
+}
+
+func TestExternalAuthorizersWatcher(t *testing.T) {
+	path := newTempFile(t)
+	defer removeSilent(path)
+
+	// The file fails to load first, the authorizers are unknown until it is fixed.
+	writeFile(t, path, "authorizers: [{name: sso}]")
+	w := mesh.NewExternalAuthorizersWatcher(filewatcher.NewWatcher(), path)
+	if got := w.ExternalAuthorizers(); got != nil {
+		t.Fatalf("got authorizers %v from an invalid config, want none", got)
+	}
+
+	doneCh := make(chan struct{}, 1)
+	w.AddExternalAuthorizersHandler(func() {
+		doneCh <- struct{}{}
+	})
+
+	writeFile(t, path, "authorizers: [{name: sso, service: sso.svc, port: 80, protocol: GRPC}]")
+	select {
+	case <-doneCh:
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for update")
+	}
+	if w.ExternalAuthorizers().Get("sso") == nil {
+		t.Fatalf("got authorizers %v, want sso", w.ExternalAuthorizers())
+	}
+
+	// An invalid update is ignored, the previous authorizers are kept.
+	writeFile(t, path, "authorizers: [{name: sso, port: 80}]")
+	select {
+	case <-doneCh:
+		t.Fatal("got an update for an invalid config")
+	case <-time.After(time.Second):
+	}
+	if w.ExternalAuthorizers().Get("sso") == nil {
+		t.Errorf("got authorizers %v, want sso to be kept", w.ExternalAuthorizers())
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mesh
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/ghodss/yaml"
+	"github.com/hashicorp/go-multierror"
+
+	"istio.io/pkg/filewatcher"
+	"istio.io/pkg/log"
+
+	"istio.io/istio/pkg/config/labels"
+)
+
+const (
+	// RateLimitActionRemoteAddress adds the address of the client to the descriptor, with the key "remote_address".
+	RateLimitActionRemoteAddress = "RemoteAddress"
+	// RateLimitActionRequestHeader adds the value of a request header to the descriptor. The descriptor is not
+	// generated when the header is missing.
+	RateLimitActionRequestHeader = "RequestHeader"
+	// RateLimitActionGenericKey adds a constant value to the descriptor, with the key "generic_key".
+	RateLimitActionGenericKey = "GenericKey"
+	// RateLimitActionDestinationCluster adds the cluster of the route to the descriptor, with the key
+	// "destination_cluster".
+	RateLimitActionDestinationCluster = "DestinationCluster"
+	// RateLimitActionSourceCluster adds the cluster of the client to the descriptor, with the key "source_cluster".
+	RateLimitActionSourceCluster = "SourceCluster"
+
+	// DefaultRateLimitDomain is the domain of the rate limit requests when none is configured.
+	DefaultRateLimitDomain = "istio"
+
+	// minFillInterval is the smallest fill interval of the token buckets accepted by Envoy.
+	minFillInterval = 50 * time.Millisecond
+)
+
+// RateLimitService declares the rate limit service (RLS) called by Envoy for the global rate limits.
+type RateLimitService struct {
+	// Service is the hostname of the rate limit service, e.g. "ratelimit.ratelimit.svc.cluster.local".
+	Service string `json:"service,omitempty"`
+
+	// Port is the gRPC port of the rate limit service.
+	Port uint32 `json:"port,omitempty"`
+
+	// Cluster is the Envoy cluster of the rate limit service, used instead of Service and Port, e.g. for a
+	// cluster of the bootstrap config.
+	Cluster string `json:"cluster,omitempty"`
+
+	// Domain is the domain of the rate limit requests. Defaults to "istio".
+	Domain string `json:"domain,omitempty"`
+
+	// Timeout is the timeout of the rate limit requests, e.g. "50ms". Defaults to the Envoy default, 20ms.
+	Timeout string `json:"timeout,omitempty"`
+
+	// FailOpen allows the requests when the rate limit service fails or cannot be reached.
+	FailOpen bool `json:"failOpen,omitempty"`
+}
+
+// RateLimitAction adds an entry to the descriptor of the rate limit requests.
+type RateLimitAction struct {
+	// Type is the type of the action: RemoteAddress, RequestHeader, GenericKey, DestinationCluster or
+	// SourceCluster.
+	Type string `json:"type"`
+
+	// Header is the name of the request header. RequestHeader only.
+	Header string `json:"header,omitempty"`
+
+	// DescriptorKey is the key of the entry. RequestHeader only.
+	DescriptorKey string `json:"descriptorKey,omitempty"`
+
+	// Value is the value of the entry. GenericKey only.
+	Value string `json:"value,omitempty"`
+}
+
+// RateLimitDescriptor is a descriptor sent to the rate limit service, built from the actions.
+type RateLimitDescriptor struct {
+	Actions []*RateLimitAction `json:"actions"`
+}
+
+// LocalRateLimit is a token bucket applied by each Envoy to the connections of its listeners, without calling
+// the rate limit service. It limits the connections, not the requests: each connection accepted by a listener
+// takes a token, and the connections accepted while the bucket is empty are closed right away. The requests
+// sent over the connections already accepted are not limited, and Paths does not apply. It requires proxies of
+// Istio 1.5 or later, older proxies ignore it.
+type LocalRateLimit struct {
+	// MaxTokens is the size of the bucket.
+	MaxTokens uint32 `json:"maxTokens"`
+
+	// TokensPerFill is the number of tokens added to the bucket on each fill. Defaults to 1.
+	TokensPerFill uint32 `json:"tokensPerFill,omitempty"`
+
+	// FillInterval is the interval of the fills, e.g. "1s". Must be at least 50ms.
+	FillInterval string `json:"fillInterval"`
+}
+
+// RateLimit applies rate limits to the workloads it selects, gateways and sidecars alike.
+type RateLimit struct {
+	// Name is the name of the rate limit.
+	Name string `json:"name"`
+
+	// Namespace restricts the rate limit to the workloads of the namespace. All namespaces when empty.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Selector selects the workloads by their labels. All the workloads of the namespace when empty.
+	Selector labels.Instance `json:"selector,omitempty"`
+
+	// Paths restricts the global rate limits to the requests with these paths, exact or prefix with a trailing
+	// "*". The query string of the requests is ignored. All the requests when empty.
+	Paths []string `json:"paths,omitempty"`
+
+	// Descriptors are the descriptors of the global rate limits, checked with the rate limit service.
+	Descriptors []*RateLimitDescriptor `json:"descriptors,omitempty"`
+
+	// Local is the local rate limit of the connections accepted by the listeners, not of the requests.
+	Local *LocalRateLimit `json:"local,omitempty"`
+}
+
+// RateLimits is the configuration of the rate limits of the mesh.
+type RateLimits struct {
+	Service *RateLimitService `json:"service,omitempty"`
+	Limits  []*RateLimit      `json:"limits"`
+}
+
+// Selects returns true if the rate limit applies to the workload.
+func (l *RateLimit) Selects(namespace string, workloadLabels labels.Collection) bool {
+	if l.Namespace != "" && l.Namespace != namespace {
+		return false
+	}
+	return len(l.Selector) == 0 || workloadLabels.IsSupersetOf(l.Selector)
+}
+
+// FillIntervalDuration returns the fill interval of the token bucket.
+func (l *LocalRateLimit) FillIntervalDuration() time.Duration {
+	d, _ := time.ParseDuration(l.FillInterval)
+	return d
+}
+
+// ParseRateLimits parses and validates the configuration of the rate limits.
+func ParseRateLimits(yamlText string) (*RateLimits, error) {
+	out := &RateLimits{}
+	if err := yaml.Unmarshal([]byte(yamlText), out); err != nil {
+		return nil, multierror.Prefix(err, "failed to parse rate limits config")
+	}
+
+	var errs *multierror.Error
+	if s := out.Service; s != nil {
+		if s.Cluster == "" && (s.Service == "" || s.Port == 0 || s.Port > 65535) {
+			errs = multierror.Append(errs, fmt.Errorf("rate limit service: cluster or service and port are required"))
+		}
+		if s.Timeout != "" {
+			if d, err := time.ParseDuration(s.Timeout); err != nil || d <= 0 {
+				errs = multierror.Append(errs, fmt.Errorf("rate limit service: invalid timeout %q", s.Timeout))
+			}
+		}
+		if s.Domain == "" {
+			s.Domain = DefaultRateLimitDomain
+		}
+	}
+
+	names := map[string]bool{}
+	for _, l := range out.Limits {
+		if l.Name == "" {
+			errs = multierror.Append(errs, fmt.Errorf("rate limit without name"))
+			continue
+		}
+		if names[l.Name] {
+			errs = multierror.Append(errs, fmt.Errorf("duplicate rate limit %q", l.Name))
+		}
+		names[l.Name] = true
+		errs = multierror.Append(errs, validateRateLimit(l, out.Service))
+	}
+	if err := errs.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func validateRateLimit(l *RateLimit, service *RateLimitService) error {
+	var errs error
+	if len(l.Descriptors) == 0 && l.Local == nil {
+		errs = multierror.Append(errs, fmt.Errorf("rate limit %q: descriptors or local are required", l.Name))
+	}
+	if len(l.Descriptors) > 0 && service == nil {
+		errs = multierror.Append(errs, fmt.Errorf("rate limit %q: descriptors require a rate limit service", l.Name))
+	}
+	if err := l.Selector.Validate(); err != nil {
+		errs = multierror.Append(errs, fmt.Errorf("rate limit %q: invalid selector: %v", l.Name, err))
+	}
+	for _, path := range l.Paths {
+		if !strings.HasPrefix(path, "/") || strings.Contains(strings.TrimSuffix(path, "*"), "*") {
+			errs = multierror.Append(errs, fmt.Errorf("rate limit %q: invalid path %q", l.Name, path))
+		}
+	}
+	for _, d := range l.Descriptors {
+		if len(d.Actions) == 0 {
+			errs = multierror.Append(errs, fmt.Errorf("rate limit %q: descriptor without actions", l.Name))
+		}
+		for _, a := range d.Actions {
+			switch a.Type {
+			case RateLimitActionRemoteAddress, RateLimitActionDestinationCluster, RateLimitActionSourceCluster:
+			case RateLimitActionRequestHeader:
+				if a.Header == "" || a.DescriptorKey == "" {
+					errs = multierror.Append(errs, fmt.Errorf("rate limit %q: %s action requires header and descriptorKey",
+						l.Name, a.Type))
+				}
+			case RateLimitActionGenericKey:
+				if a.Value == "" {
+					errs = multierror.Append(errs, fmt.Errorf("rate limit %q: %s action requires value", l.Name, a.Type))
+				}
+			default:
+				errs = multierror.Append(errs, fmt.Errorf("rate limit %q: unknown action type %q", l.Name, a.Type))
+			}
+		}
+	}
+	if local := l.Local; local != nil {
+		if local.MaxTokens == 0 {
+			errs = multierror.Append(errs, fmt.Errorf("rate limit %q: local maxTokens is required", l.Name))
+		}
+		if d, err := time.ParseDuration(local.FillInterval); err != nil || d < minFillInterval {
+			errs = multierror.Append(errs, fmt.Errorf("rate limit %q: local fillInterval must be a duration of at least %v",
+				l.Name, minFillInterval))
+		}
+	}
+	return errs
+}
+
+// ReadRateLimits gets the configuration of the rate limits from a config file.
+func ReadRateLimits(filename string) (*RateLimits, error) {
+	yamlText, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, multierror.Prefix(err, "cannot read rate limits config file")
+	}
+	return ParseRateLimits(string(yamlText))
+}
+
+// RateLimitsWatcher watches changes to the rate limits config.
+type RateLimitsWatcher interface {
+	// RateLimits returns the latest rate limits configuration, or nil if none was loaded.
+	RateLimits() *RateLimits
+
+	AddRateLimitsHandler(func())
+}
+
+var _ RateLimitsWatcher = &rateLimitsWatcher{}
+
+type rateLimitsWatcher struct {
+	mutex      sync.Mutex
+	handlers   []func()
+	rateLimits *RateLimits
+}
+
+// NewFixedRateLimitsWatcher creates a new RateLimitsWatcher that always returns the given config.
+// It will never fire any events, since the config never changes.
+func NewFixedRateLimitsWatcher(rateLimits *RateLimits) RateLimitsWatcher {
+	return &rateLimitsWatcher{
+		rateLimits: rateLimits,
+	}
+}
+
+// NewRateLimitsWatcher creates a new watcher for changes to the given rate limits config file. The file is
+// watched even if it fails to load, there are then no rate limits until it is fixed. A config failing to
+// reload is ignored, and the previous one is kept.
+func NewRateLimitsWatcher(fileWatcher filewatcher.FileWatcher, filename string) RateLimitsWatcher {
+	w := &rateLimitsWatcher{}
+	rateLimits, err := ReadRateLimits(filename)
+	if err != nil {
+		log.Errorf("failed to read rate limits configuration from %q, no rate limit is applied: %v", filename, err)
+	} else {
+		log.Infof("loaded %d rate limits", len(rateLimits.Limits))
+		w.rateLimits = rateLimits
+	}
+
+	// Watch the rate limits config file for changes and reload if it got modified
+	addFileWatcher(fileWatcher, filename, func() {
+		rateLimits, err := ReadRateLimits(filename)
+		if err != nil {
+			log.Errorf("failed to reload rate limits configuration from %q, keeping the previous one: %v",
+				filename, err)
+			return
+		}
+
+		var handlers []func()
+
+		w.mutex.Lock()
+		if !reflect.DeepEqual(rateLimits, w.rateLimits) {
+			log.Infof("rate limits configuration file updated, loaded %d rate limits", len(rateLimits.Limits))
+			atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&w.rateLimits)), unsafe.Pointer(rateLimits))
+			handlers = append([]func(){}, w.handlers...)
+		}
+		w.mutex.Unlock()
+
+		// Notify the handlers of the change.
+		for _, h := range handlers {
+			h()
+		}
+	})
+	return w
+}
+
+// RateLimits returns the latest rate limits configuration.
+func (w *rateLimitsWatcher) RateLimits() *RateLimits {
+	return (*RateLimits)(atomic.LoadPointer((*unsafe.Pointer)(unsafe.Pointer(&w.rateLimits))))
+}
+
+// AddRateLimitsHandler registers a callback handler for changes to the rate limits config.
+func (w *rateLimitsWatcher) AddRateLimitsHandler(h func()) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.handlers = append(w.handlers, h)
+}
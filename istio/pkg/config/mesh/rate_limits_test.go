@@ -0,0 +1,188 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mesh_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"istio.io/pkg/filewatcher"
+
+	"istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/mesh"
+)
+
+func TestParseRateLimits(t *testing.T) {
+	cases := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			yaml: `
+service:
+  service: ratelimit.ratelimit.svc.cluster.local
+  port: 8081
+  timeout: 50ms
+limits:
+- name: api
+  namespace: default
+  selector: {app: reviews}
+  paths: ["/api/*", "/login"]
+  descriptors:
+  - actions:
+    - type: RemoteAddress
+    - type: RequestHeader
+      header: x-user
+      descriptorKey: user
+- name: connections
+  local:
+    maxTokens: 100
+    tokensPerFill: 10
+    fillInterval: 1s
+`,
+		},
+		{
+			name:    "empty",
+			yaml:    "",
+			wantErr: "",
+		},
+		{
+			name:    "service without address",
+			yaml:    "service: {domain: edge}",
+			wantErr: "cluster or service and port are required",
+		},
+		{
+			name:    "descriptors without service",
+			yaml:    "limits: [{name: a, descriptors: [{actions: [{type: RemoteAddress}]}]}]",
+			wantErr: "require a rate limit service",
+		},
+		{
+			name:    "no limit",
+			yaml:    "limits: [{name: a}]",
+			wantErr: "descriptors or local are required",
+		},
+		{
+			name:    "duplicate name",
+			yaml:    "limits: [{name: a, local: {maxTokens: 1, fillInterval: 1s}}, {name: a, local: {maxTokens: 1, fillInterval: 1s}}]",
+			wantErr: "duplicate",
+		},
+		{
+			name:    "unknown action",
+			yaml:    "service: {cluster: rls}\nlimits: [{name: a, descriptors: [{actions: [{type: Path}]}]}]",
+			wantErr: "unknown action type",
+		},
+		{
+			name:    "request header without key",
+			yaml:    "service: {cluster: rls}\nlimits: [{name: a, descriptors: [{actions: [{type: RequestHeader, header: x-user}]}]}]",
+			wantErr: "requires header and descriptorKey",
+		},
+		{
+			name:    "invalid path",
+			yaml:    "limits: [{name: a, paths: ['*.php'], local: {maxTokens: 1, fillInterval: 1s}}]",
+			wantErr: "invalid path",
+		},
+		{
+			name:    "short fill interval",
+			yaml:    "limits: [{name: a, local: {maxTokens: 1, fillInterval: 10ms}}]",
+			wantErr: "fillInterval",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := mesh.ParseRateLimits(c.yaml)
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("ParseRateLimits() got error %v, want %q", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRateLimits() failed: %v", err)
+			}
+			if c.name != "valid" {
+				return
+			}
+			if got.Service.Domain != mesh.DefaultRateLimitDomain {
+				t.Errorf("got domain %q, want the default", got.Service.Domain)
+			}
+			if d := got.Limits[1].Local.FillIntervalDuration(); d != time.Second {
+				t.Errorf("got fill interval %v, want 1s", d)
+			}
+		})
+	}
+}
+
+func TestRateLimitSelects(t *testing.T) {
+	limit := &mesh.RateLimit{Namespace: "default", Selector: labels.Instance{"app": "reviews"}}
+	cases := []struct {
+		namespace string
+		labels    labels.Instance
+		want      bool
+	}{
+		{"default", labels.Instance{"app": "reviews", "version": "v1"}, true},
+		{"default", labels.Instance{"app": "ratings"}, false},
+		{"other", labels.Instance{"app": "reviews"}, false},
+	}
+	for _, c := range cases {
+		if got := limit.Selects(c.namespace, labels.Collection{c.labels}); got != c.want {
+			t.Errorf("Selects(%s, %v) got %v, want %v", c.namespace, c.labels, got, c.want)
+		}
+	}
+	if !(&mesh.RateLimit{}).Selects("any", nil) {
+		t.Errorf("want a rate limit without namespace and selector to select all the workloads")
+	}
+}
+
+func TestRateLimitsWatcher(t *testing.T) {
+	path := newTempFile(t)
+	defer removeSilent(path)
+
+	// The file fails to load first, there are no rate limits until it is fixed.
+	writeFile(t, path, "limits: [{name: api}]")
+	w := mesh.NewRateLimitsWatcher(filewatcher.NewWatcher(), path)
+	if got := w.RateLimits(); got != nil {
+		t.Fatalf("got rate limits %v from an invalid config, want none", got)
+	}
+
+	doneCh := make(chan struct{}, 1)
+	w.AddRateLimitsHandler(func() {
+		doneCh <- struct{}{}
+	})
+
+	writeFile(t, path, "limits: [{name: api, local: {maxTokens: 10, fillInterval: 1s}}]")
+	select {
+	case <-doneCh:
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for update")
+	}
+	if got := w.RateLimits(); got == nil || len(got.Limits) != 1 || got.Limits[0].Name != "api" {
+		t.Fatalf("got rate limits %v, want api", got)
+	}
+
+	// An invalid update is ignored, the previous rate limits are kept.
+	writeFile(t, path, "limits: [{name: api, local: {fillInterval: 1s}}]")
+	select {
+	case <-doneCh:
+		t.Fatal("got an update for an invalid config")
+	case <-time.After(time.Second):
+	}
+	if got := w.RateLimits(); got == nil || len(got.Limits) != 1 || got.Limits[0].Local.MaxTokens != 10 {
+		t.Errorf("got rate limits %v, want api to be kept", got)
+	}
+}
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"strings"
 	"time"
@@ -28,6 +29,7 @@ import (
 	"istio.io/istio/pkg/kube"
 	"istio.io/istio/security/pkg/cmd"
 	"istio.io/istio/security/pkg/k8s/chiron"
+	"istio.io/istio/security/pkg/pki/util"
 	"istio.io/pkg/collateral"
 	"istio.io/pkg/ctrlz"
 	"istio.io/pkg/log"
@@ -88,6 +90,9 @@ type cliOptions struct {
 	// after 24*(1-0.2) hours since the cert is issued.
 	certGracePeriodRatio float32
 
+	// The algorithm of the generated private keys.
+	keyAlgorithm string
+
 	// Whether enable the certificate controller
 	enableController bool
 }
@@ -114,6 +119,8 @@ func init() {
 			"certificate TTL.")
 	flags.DurationVar(&opts.certMinGracePeriod, "cert-min-grace-period",
 		DefaultMinCertGracePeriod, "The minimum certificate rotation grace period.")
+	flags.StringVar(&opts.keyAlgorithm, "key-algorithm", string(util.DefaultKeyAlgorithm),
+		fmt.Sprintf("The algorithm of the generated private keys, one of %v.", util.SupportedKeyAlgorithms))
 
 	flags.StringSliceVar(&opts.secretNames, "secret-names", []string{"istio-webhook-galley",
 		"istio-webhook-sidecar-injector"},
@@ -160,13 +167,19 @@ func runWebhookController() {
 		os.Exit(1)
 	}
 
+	keyAlgorithm, err := util.ParseKeyAlgorithm(opts.keyAlgorithm)
+	if err != nil {
+		log.Errorf("invalid key algorithm: %v", err)
+		os.Exit(1)
+	}
+
 	stopCh := make(chan struct{})
 
 	dnsNames := strings.Split(opts.dnsNames, ";")
 
 	wc, err := chiron.NewWebhookController(opts.certGracePeriodRatio, opts.certMinGracePeriod,
 		k8sClient.CoreV1(), k8sClient.AdmissionregistrationV1beta1(), k8sClient.CertificatesV1beta1(),
-		opts.k8sCaCertFile, opts.secretNames, dnsNames, opts.serviceNamespaces)
+		opts.k8sCaCertFile, opts.secretNames, dnsNames, opts.serviceNamespaces, keyAlgorithm)
 
 	if err != nil {
 		log.Errorf("failed to create certificate controller: %v", err)
@@ -16,13 +16,17 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
+	certclient "k8s.io/client-go/kubernetes/typed/certificates/v1beta1"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,11 +37,16 @@ import (
 	"istio.io/istio/security/pkg/caclient"
 	"istio.io/istio/security/pkg/cmd"
 	"istio.io/istio/security/pkg/k8s/controller"
+	"istio.io/istio/security/pkg/k8s/csrca"
 	"istio.io/istio/security/pkg/pki/ca"
+	"istio.io/istio/security/pkg/pki/pkcs11"
+	"istio.io/istio/security/pkg/pki/util"
 	probecontroller "istio.io/istio/security/pkg/probe"
 	"istio.io/istio/security/pkg/registry"
 	"istio.io/istio/security/pkg/registry/kube"
 	caserver "istio.io/istio/security/pkg/server/ca"
+	"istio.io/istio/security/pkg/server/ca/audit"
+	issuancepolicy "istio.io/istio/security/pkg/server/ca/policy"
 	"istio.io/istio/security/pkg/server/monitoring"
 	"istio.io/pkg/collateral"
 	"istio.io/pkg/ctrlz"
@@ -55,6 +64,22 @@ const (
 	enableJitterForRootCertRotator          = "CITADEL_ENABLE_JITTER_FOR_ROOT_CERT_ROTATOR"
 )
 
+// The sources of the CA signing key.
+const (
+	// signingKeyFromFile loads the signing key from the '--signing-key' file.
+	signingKeyFromFile = "file"
+	// signingKeyFromPKCS11 signs with a key held by a PKCS#11 token, e.g. an HSM.
+	signingKeyFromPKCS11 = "pkcs11"
+)
+
+// trustBundleStatusPath is the monitoring endpoint reporting which workloads rolled to the current signing
+// certificate.
+const trustBundleStatusPath = "/debug/trustbundlez"
+
+// certInventoryPath is the monitoring endpoint listing the valid certificates issued by the gRPC server, for the
+// identity of the "identity" query parameter if set.
+const certInventoryPath = "/debug/certz"
+
 type cliOptions struct { // nolint: maligned
 	// Comma separated string containing all listened namespaces
 	listenedNamespaces        string
@@ -68,6 +93,22 @@ type cliOptions struct { // nolint: maligned
 	signingKeyFile  string
 	rootCertFile    string
 
+	// Where the signing key is loaded from, signingKeyFromFile or signingKeyFromPKCS11.
+	signingKeySource string
+	// The PKCS#11 key used when signingKeySource is signingKeyFromPKCS11, and the file of the PIN of its token.
+	pkcs11Config  pkcs11.Config
+	pkcs11PINFile string
+
+	// Root certificates trusted besides the root of the plugged signing certificate.
+	trustedRootCertFiles []string
+	// Whether to keep trusting the root of the self-signed CA when using a plugged signing certificate.
+	trustSelfSignedRoot bool
+
+	// How often the workloads refresh the certificate revocation list, and the file of the CRLs of the CAs
+	// above a plugged signing certificate.
+	crlRefreshInterval time.Duration
+	chainCRLFile       string
+
 	selfSignedCA                            bool
 	selfSignedCACertTTL                     time.Duration
 	selfSignedRootCertCheckInterval         time.Duration
@@ -92,6 +133,12 @@ type cliOptions struct { // nolint: maligned
 	signCACerts bool
 	// Whether to generate PKCS#8 private keys.
 	pkcs8Keys bool
+	// The algorithm of the private keys generated for the workloads, and its parsed value.
+	workloadKeyAlgorithm       string
+	parsedWorkloadKeyAlgorithm util.KeyAlgorithm
+	// The algorithms allowed for the keys of the workload CSRs, and their parsed values.
+	allowedKeyAlgorithms       []string
+	parsedAllowedKeyAlgorithms []util.KeyAlgorithm
 
 	cAClientConfig caclient.Config
 
@@ -123,6 +170,25 @@ type cliOptions struct { // nolint: maligned
 
 	// Whether SDS is enabled on.
 	sdsEnabled bool
+
+	// Comma separated identities allowed to revoke certificates.
+	revocationAdmins string
+
+	// The ConfigMap of the certificate issuance policy, in the Citadel storage namespace.
+	issuancePolicyConfigMap string
+
+	// Where the audit records of the issued certificates are written: "stdout", "log", a file path, or nowhere
+	// if empty.
+	auditLog string
+	// The rotation of the audit log file.
+	auditLogFileOptions audit.FileOptions
+
+	// Whether to sign the certificates through the Kubernetes CSR API rather than with the Citadel key.
+	kubernetesCSRSigner bool
+	// Whether Citadel approves the CSR objects it creates.
+	kubernetesCSRAutoApprove bool
+	// How long to wait for the CSR objects to be approved and signed.
+	kubernetesCSRTimeout time.Duration
 }
 
 var (
@@ -215,9 +281,43 @@ func initCLI() {
 	flags.StringVar(&opts.signingCertFile, "signing-cert", "", "Path to the CA signing certificate file.")
 	flags.StringVar(&opts.signingKeyFile, "signing-key", "", "Path to the CA signing key file.")
 
+	// Configuration if the signing key of the plugged certificate is held by a PKCS#11 token, e.g. an HSM, and
+	// never leaves it.
+	flags.StringVar(&opts.signingKeySource, "signing-key-source", signingKeyFromFile,
+		fmt.Sprintf("Where the CA signing key is loaded from: %q for the '--signing-key' file, or %q for a key "+
+			"held by a PKCS#11 token.", signingKeyFromFile, signingKeyFromPKCS11))
+	flags.StringVar(&opts.pkcs11Config.Module, "pkcs11-module", "",
+		"Path to the PKCS#11 module of the token holding the CA signing key, e.g. /usr/lib/softhsm/libsofthsm2.so.")
+	flags.StringVar(&opts.pkcs11Config.TokenLabel, "pkcs11-token-label", "",
+		"Label of the PKCS#11 token holding the CA signing key.")
+	flags.StringVar(&opts.pkcs11Config.KeyLabel, "pkcs11-key-label", "", "Label of the CA signing key in the PKCS#11 token.")
+	flags.StringVar(&opts.pkcs11PINFile, "pkcs11-pin-file", "", "Path to the file of the user PIN of the PKCS#11 token.")
+
 	// Both self-signed or non-self-signed Citadel may take a root certificate file with a list of root certificates.
 	flags.StringVar(&opts.rootCertFile, "root-cert", "", "Path to the root certificate file.")
 
+	// To migrate the mesh from the self-signed CA to a plugged signing certificate without outage:
+	// 1. run the self-signed CA with the new root appended through --root-cert, until all the workloads trust it;
+	// 2. plug the new signing certificate with --trust-self-signed-root, until all the workloads rolled to the new
+	//    chain, as reported by the /debug/trustbundlez monitoring endpoint;
+	// 3. drop --trust-self-signed-root.
+	flags.StringSliceVar(&opts.trustedRootCertFiles, "trusted-root-certs", nil,
+		"Paths to root certificate files added to the trust bundle distributed to the workloads, besides the root "+
+			"of the plugged signing certificate. Ignored with '--self-signed-ca', use '--root-cert' instead.")
+	flags.BoolVar(&opts.trustSelfSignedRoot, "trust-self-signed-root", false,
+		"Whether to add the root certificate of the self-signed CA, stored in the istio-ca-secret secret, to the "+
+			"trust bundle distributed to the workloads when using a plugged signing certificate, so that the "+
+			"certificates it issued stay trusted while the workloads migrate to the new chain.")
+
+	// Certificate revocation configuration.
+	flags.DurationVar(&opts.crlRefreshInterval, "crl-refresh-interval", ca.DefaultCRLRefreshInterval,
+		"How often the workloads refresh the certificate revocation list, i.e. the SECRET_JOB_RUN_INTERVAL of the "+
+			"node agents. The CRLs are valid for a few intervals, so that failed refreshes are tolerated.")
+	flags.StringVar(&opts.chainCRLFile, "chain-crl", "",
+		"Path to the PEM-encoded CRLs of the CAs above the plugged signing certificate, up to the root, and of the "+
+			"other trusted roots. The proxies check the revocation of the whole chain, so no CRL is distributed "+
+			"without them.")
+
 	// Configuration if Citadel acts as a self signed CA.
 	flags.BoolVar(&opts.selfSignedCA, "self-signed-ca", false,
 		"Indicates whether to use auto-generated self-signed CA certificate. "+
@@ -251,6 +351,12 @@ func initCLI() {
 
 	flags.BoolVar(&opts.signCACerts, "sign-ca-certs", false, "Whether Citadel signs certificates for other CAs.")
 	flags.BoolVar(&opts.pkcs8Keys, "pkcs8-keys", false, "Whether to generate PKCS#8 private keys.")
+	flags.StringVar(&opts.workloadKeyAlgorithm, "workload-key-algorithm", string(util.DefaultKeyAlgorithm),
+		fmt.Sprintf("The algorithm of the private keys generated for the workloads, one of %v.",
+			util.SupportedKeyAlgorithms))
+	flags.StringSliceVar(&opts.allowedKeyAlgorithms, "allowed-key-algorithms", nil,
+		"The algorithms allowed for the keys of the workload CSRs, e.g. ECDSA_P256,RSA_2048. "+
+			"CSRs with other keys are rejected. If unspecified, any key algorithm is allowed.")
 
 	// Monitoring configuration
 	flags.IntVar(&opts.monitoringPort, "monitoring-port", 15014, "The port number for monitoring Citadel. "+
@@ -276,6 +382,33 @@ func initCLI() {
 
 	flags.BoolVar(&opts.sdsEnabled, "sds-enabled", false, "Whether SDS is enabled.")
 
+	flags.StringVar(&opts.revocationAdmins, "revocation-admins", "",
+		"The comma separated list of identities (e.g. spiffe://cluster.local/ns/istio-system/sa/admin) allowed "+
+			"to revoke certificates. Certificate revocation is disabled if empty.")
+	flags.StringVar(&opts.issuancePolicyConfigMap, "issuance-policy-configmap", "",
+		"The ConfigMap in the Citadel storage namespace holding the certificate issuance policy in its "+
+			issuancepolicy.ConfigMapKey+" key. All the certificate requests are allowed if empty. The requests are "+
+			"denied while the ConfigMap has no valid policy, and the last policy is kept if it is deleted.")
+	flags.StringVar(&opts.auditLog, "audit-log", "",
+		"Where the JSON audit records of the issued certificates are written: \"stdout\" for JSON lines on the "+
+			"standard output, \"log\" for the dedicated certaudit log scope, or the path of a file. The certificates "+
+			"are not audited if empty.")
+	flags.IntVar(&opts.auditLogFileOptions.MaxSizeMB, "audit-log-max-size", 100,
+		"The size in megabytes from which the audit log file is rotated.")
+	flags.IntVar(&opts.auditLogFileOptions.MaxBackups, "audit-log-max-backups", 10,
+		"The number of rotated audit log files kept, or 0 to keep them all.")
+	flags.IntVar(&opts.auditLogFileOptions.MaxAgeDays, "audit-log-max-age", 30,
+		"The number of days the rotated audit log files are kept, or 0 to keep them regardless of age.")
+
+	flags.BoolVar(&opts.kubernetesCSRSigner, "kubernetes-csr-signer", false,
+		"Sign the certificates through the Kubernetes certificates.k8s.io CSR API instead of with the Citadel key. "+
+			"The '--root-cert' option must then hold the root certificate of the Kubernetes signer.")
+	flags.BoolVar(&opts.kubernetesCSRAutoApprove, "kubernetes-csr-auto-approve", false,
+		"Whether Citadel approves the CSR objects it creates with '--kubernetes-csr-signer'. If unset, they "+
+			"have to be approved by another approver.")
+	flags.DurationVar(&opts.kubernetesCSRTimeout, "kubernetes-csr-timeout", 30*time.Second,
+		"How long to wait for a CSR object to be approved and signed with '--kubernetes-csr-signer'.")
+
 	rootCmd.AddCommand(version.CobraCommand())
 
 	rootCmd.AddCommand(collateral.CobraCommand(rootCmd, &doc.GenManHeader{
@@ -342,16 +475,23 @@ func runCA() {
 	if err != nil {
 		fatalf("Could not create k8s clientset: %v", err)
 	}
-	ca := createCA(cs.CoreV1())
+	var signer caserver.CertificateAuthority
+	var istioCA *ca.IstioCA
+	if opts.kubernetesCSRSigner {
+		signer = createKubernetesCSRCA(cs.CertificatesV1beta1().CertificateSigningRequests())
+	} else {
+		istioCA = createCA(cs.CoreV1())
+		signer = istioCA
+	}
 
 	stopCh := make(chan struct{})
 	if !opts.serverOnly {
 		log.Infof("Creating Kubernetes controller to write issued keys and certs into secret ...")
 		// For workloads in K8s, we apply the configured workload cert TTL.
-		sc, err := controller.NewSecretController(ca, opts.enableNamespacesByDefault,
+		sc, err := controller.NewSecretController(signer, opts.enableNamespacesByDefault,
 			opts.workloadCertTTL, opts.workloadCertGracePeriodRatio, opts.workloadCertMinGracePeriod,
-			opts.dualUse, cs.CoreV1(), opts.signCACerts, opts.pkcs8Keys, listenedNamespaces, webhooks,
-			opts.istioCaStorageNamespace, opts.rootCertFile, opts.selfSignedCA)
+			opts.dualUse, cs.CoreV1(), opts.signCACerts, opts.pkcs8Keys, opts.parsedWorkloadKeyAlgorithm,
+			listenedNamespaces, webhooks, opts.istioCaStorageNamespace, opts.rootCertFile, opts.selfSignedCA)
 		if err != nil {
 			fatalf("Failed to create secret controller: %v", err)
 		}
@@ -363,6 +503,7 @@ func runCA() {
 		}
 	}
 
+	var auditor *audit.Auditor
 	if opts.grpcPort > 0 {
 		// start registry if gRPC server is to be started
 		reg := registry.GetIdentityRegistry()
@@ -386,12 +527,28 @@ func runCA() {
 
 		// The CA API uses cert with the max workload cert TTL.
 		hostnames := append(strings.Split(opts.grpcHosts, ","), fqdn())
-		caServer, startErr := caserver.New(ca, opts.maxWorkloadCertTTL,
+		caServer, startErr := caserver.New(signer, opts.maxWorkloadCertTTL,
 			opts.signCACerts, hostnames, opts.grpcPort, spiffe.GetTrustDomain(),
 			opts.sdsEnabled)
 		if startErr != nil {
 			fatalf("Failed to create istio ca server: %v", startErr)
 		}
+		if opts.revocationAdmins != "" {
+			caServer.RevocationAdmins = strings.Split(opts.revocationAdmins, ",")
+		}
+		if opts.issuancePolicyConfigMap != "" {
+			caServer.IssuancePolicy = issuancepolicy.NewStore()
+			watcher := issuancepolicy.NewConfigMapWatcher(cs.CoreV1(), opts.istioCaStorageNamespace,
+				opts.issuancePolicyConfigMap, caServer.IssuancePolicy)
+			go watcher.Run(stopCh)
+			// Wait for the policy, so that no certificate is issued before it applies.
+			if !watcher.WaitForSync(stopCh) {
+				fatalf("Failed to load the issuance policy of the ConfigMap %s", opts.issuancePolicyConfigMap)
+			}
+		}
+		auditor = createAuditor()
+		defer func() { _ = auditor.Close() }()
+		caServer.Audit = auditor
 		if serverErr := caServer.Run(); serverErr != nil {
 			// stop the registry-related controllers
 			ch <- struct{}{}
@@ -407,6 +564,12 @@ func runCA() {
 		if mErr != nil {
 			fatalf("Unable to setup monitoring: %v", mErr)
 		}
+		if istioCA != nil {
+			monitor.HandleFunc(trustBundleStatusPath, trustBundleStatusHandler(istioCA))
+		}
+		if auditor != nil {
+			monitor.HandleFunc(certInventoryPath, auditor.Inventory().ServeHTTP)
+		}
 		go monitor.Start(monitorErrCh)
 		log.Info("Citadel monitor has started.")
 		defer monitor.Close()
@@ -428,7 +591,7 @@ func runCA() {
 		config.CSRGracePeriodPercentage = cmd.DefaultCSRGracePeriodPercentage
 		config.CSRMaxRetries = cmd.DefaultCSRMaxRetries
 		config.CSRInitialRetrialInterval = cmd.DefaultCSRInitialRetrialInterval
-		rotator, creationErr := caclient.NewKeyCertBundleRotator(config, ca.GetCAKeyCertBundle())
+		rotator, creationErr := caclient.NewKeyCertBundleRotator(config, istioCA.GetCAKeyCertBundle())
 		if creationErr != nil {
 			fatalf("Failed to create key cert bundle rotator: %v", creationErr)
 		}
@@ -475,19 +638,39 @@ func createCA(client corev1.CoreV1Interface) *ca.IstioCA {
 		}
 	} else {
 		log.Info("Use certificate from argument as the CA certificate")
-		caOpts, err = ca.NewPluggedCertIstioCAOptions(opts.certChainFile, opts.signingCertFile, opts.signingKeyFile,
-			opts.rootCertFile, opts.workloadCertTTL, opts.maxWorkloadCertTTL, opts.istioCaStorageNamespace, client)
+		if opts.signingKeySource == signingKeyFromPKCS11 {
+			log.Infof("Use the key %q of the PKCS#11 token %q as the CA signing key", opts.pkcs11Config.KeyLabel,
+				opts.pkcs11Config.TokenLabel)
+			caOpts, err = ca.NewPluggedCertIstioCAOptionsWithSigner(opts.certChainFile, opts.signingCertFile,
+				loadPKCS11Signer(), opts.rootCertFile, loadTrustedRootCerts(client), opts.workloadCertTTL,
+				opts.maxWorkloadCertTTL, opts.istioCaStorageNamespace, client)
+		} else {
+			caOpts, err = ca.NewPluggedCertIstioCAOptions(opts.certChainFile, opts.signingCertFile, opts.signingKeyFile,
+				opts.rootCertFile, loadTrustedRootCerts(client), opts.workloadCertTTL, opts.maxWorkloadCertTTL,
+				opts.istioCaStorageNamespace, client)
+		}
 		if err != nil {
 			fatalf("Failed to create an Citadel (error: %v)", err)
 		}
+		if opts.trustSelfSignedRoot {
+			// The certificates issued by the self-signed CA are still trusted, and need a CRL.
+			signer, err := ca.LoadSelfSignedCACRLSigner(opts.istioCaStorageNamespace, client)
+			if err != nil {
+				fatalf("Failed to load the self-signed CA (error: %v)", err)
+			}
+			caOpts.PreviousCRLSigners = append(caOpts.PreviousCRLSigners, *signer)
+		}
 	}
 
 	caOpts.LivenessProbeOptions = opts.LivenessProbeOptions
 	caOpts.ProbeCheckInterval = opts.probeCheckInterval
+	caOpts.AllowedKeyAlgorithms = opts.parsedAllowedKeyAlgorithms
+	caOpts.CRLRefreshInterval = opts.crlRefreshInterval
+	caOpts.ChainCRLFile = opts.chainCRLFile
 
 	istioCA, err := ca.NewIstioCA(caOpts)
 	if err != nil {
-		log.Errorf("Failed to create an Citadel (error: %v)", err)
+		fatalf("Failed to create an Citadel (error: %v)", err)
 	}
 
 	if opts.LivenessProbeOptions.IsValid() {
@@ -510,7 +693,125 @@ func createCA(client corev1.CoreV1Interface) *ca.IstioCA {
 	return istioCA
 }
 
+func createKubernetesCSRCA(client certclient.CertificateSigningRequestInterface) *csrca.CA {
+	log.Info("Use the Kubernetes CSR API to sign the certificates")
+	spiffe.SetTrustDomain(spiffe.DetermineTrustDomain(opts.trustDomain, true))
+	signer, err := csrca.New(client, csrca.Options{
+		RootCertFile:    opts.rootCertFile,
+		AutoApprove:     opts.kubernetesCSRAutoApprove,
+		IssuanceTimeout: opts.kubernetesCSRTimeout,
+
+		AllowedKeyAlgorithms: opts.parsedAllowedKeyAlgorithms,
+	})
+	if err != nil {
+		fatalf("Failed to create the Kubernetes CSR API CA (error: %v)", err)
+	}
+	return signer
+}
+
+// loadPKCS11Signer returns the signer of the CA signing key held by the PKCS#11 token. The signer is used for the
+// lifetime of the process.
+func loadPKCS11Signer() pkcs11.Signer {
+	config := opts.pkcs11Config
+	if opts.pkcs11PINFile != "" {
+		pin, err := ioutil.ReadFile(opts.pkcs11PINFile)
+		if err != nil {
+			fatalf("Failed to read the PKCS#11 PIN (error: %v)", err)
+		}
+		config.PIN = strings.TrimSpace(string(pin))
+	}
+	signer, err := pkcs11.NewSigner(config)
+	if err != nil {
+		fatalf("Failed to load the CA signing key from the PKCS#11 token (error: %v)", err)
+	}
+	return signer
+}
+
+// loadTrustedRootCerts returns the additional roots to trust with a plugged signing certificate.
+func loadTrustedRootCerts(client corev1.CoreV1Interface) []byte {
+	var roots []byte
+	for _, file := range opts.trustedRootCertFiles {
+		certBytes, err := ioutil.ReadFile(file)
+		if err != nil {
+			fatalf("Failed to read trusted root certificates (error: %v)", err)
+		}
+		roots = append(roots, certBytes...)
+	}
+	if opts.trustSelfSignedRoot {
+		certBytes, err := ca.LoadSelfSignedCARootCert(opts.istioCaStorageNamespace, client)
+		if err != nil {
+			fatalf("Failed to load the self-signed root certificate (error: %v)", err)
+		}
+		roots = append(roots, certBytes...)
+	}
+	return roots
+}
+
+// trustBundleStatusHandler serves the trust bundle status of the CA as JSON.
+func trustBundleStatusHandler(istioCA *ca.IstioCA) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		status, err := istioCA.TrustBundleStatus()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		b, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(b)
+	}
+}
+
+// createAuditor returns the auditor of the certificates issued by the gRPC server, writing to the audit log if set.
+func createAuditor() *audit.Auditor {
+	switch opts.auditLog {
+	case "":
+		return audit.NewAuditor()
+	case "stdout":
+		return audit.NewAuditor(audit.NewJSONSink(os.Stdout))
+	case "log":
+		return audit.NewAuditor(audit.NewScopeSink())
+	default:
+		fileOptions := opts.auditLogFileOptions
+		fileOptions.Path = opts.auditLog
+		return audit.NewAuditor(audit.NewFileSink(fileOptions))
+	}
+}
+
 func verifyCommandLineOptions() {
+	var err error
+	if opts.parsedWorkloadKeyAlgorithm, err = util.ParseKeyAlgorithm(opts.workloadKeyAlgorithm); err != nil {
+		fatalf("Invalid '-workload-key-algorithm' option: %v", err)
+	}
+	if opts.parsedAllowedKeyAlgorithms, err = util.ParseKeyAlgorithms(opts.allowedKeyAlgorithms); err != nil {
+		fatalf("Invalid '-allowed-key-algorithms' option: %v", err)
+	}
+	if len(opts.parsedAllowedKeyAlgorithms) > 0 && !opts.serverOnly {
+		allowed := false
+		for _, alg := range opts.parsedAllowedKeyAlgorithms {
+			allowed = allowed || alg == opts.parsedWorkloadKeyAlgorithm
+		}
+		if !allowed {
+			fatalf("The workload key algorithm %s is not one of the allowed key algorithms %v",
+				opts.parsedWorkloadKeyAlgorithm, opts.parsedAllowedKeyAlgorithms)
+		}
+	}
+
+	if opts.kubernetesCSRSigner {
+		if opts.rootCertFile == "" {
+			fatalf("No root cert of the Kubernetes signer has been specified. Specify a root cert file via " +
+				"'-root-cert' option")
+		}
+		if opts.selfSignedCA || len(opts.cAClientConfig.CAAddress) != 0 {
+			fatalf("The '-self-signed-ca' and '-upstream-ca-address' options can not be used with " +
+				"'-kubernetes-csr-signer'")
+		}
+		return
+	}
+
 	if opts.selfSignedCA {
 		return
 	}
@@ -521,10 +822,24 @@ func verifyCommandLineOptions() {
 				"or use '-self-signed-ca'")
 	}
 
-	if opts.signingKeyFile == "" {
-		fatalf(
-			"No signing key has been specified. Either specify a key file via '-signing-key' option " +
-				"or use '-self-signed-ca'")
+	switch opts.signingKeySource {
+	case signingKeyFromFile:
+		if opts.signingKeyFile == "" {
+			fatalf(
+				"No signing key has been specified. Either specify a key file via '-signing-key' option " +
+					"or use '-self-signed-ca'")
+		}
+	case signingKeyFromPKCS11:
+		if err := opts.pkcs11Config.Validate(); err != nil {
+			fatalf("Invalid PKCS#11 signing key options: %v", err)
+		}
+		// The CA client rotates the signing key by writing a new key file.
+		if len(opts.cAClientConfig.CAAddress) != 0 {
+			fatalf("The '-upstream-ca-address' option can not be used with a PKCS#11 signing key")
+		}
+	default:
+		fatalf("Invalid '-signing-key-source' option %q, it should be %q or %q", opts.signingKeySource,
+			signingKeyFromFile, signingKeyFromPKCS11)
 	}
 
 	if opts.rootCertFile == "" {
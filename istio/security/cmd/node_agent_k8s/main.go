@@ -16,6 +16,7 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"strings"
 	"time"
@@ -27,9 +28,12 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"istio.io/istio/pkg/cmd"
+	"istio.io/istio/security/pkg/k8s/tokenreview"
 	"istio.io/istio/security/pkg/nodeagent/cache"
+	vault "istio.io/istio/security/pkg/nodeagent/caclient/providers/vault"
 	"istio.io/istio/security/pkg/nodeagent/sds"
 	"istio.io/istio/security/pkg/nodeagent/secretfetcher"
+	"istio.io/istio/security/pkg/pki/util"
 	"istio.io/istio/security/pkg/server/monitoring"
 	"istio.io/pkg/collateral"
 	"istio.io/pkg/env"
@@ -85,6 +89,26 @@ const (
 	vaultTLSRootCert     = "VAULT_TLS_ROOT_CERT"
 	vaultTLSRootCertFlag = "vaultTLSRootCert"
 
+	// The environmental variable name for Vault auth method.
+	vaultAuthMethod     = "VAULT_AUTH_METHOD"
+	vaultAuthMethodFlag = "vaultAuthMethod"
+
+	// The environmental variable names for Vault AppRole credentials.
+	vaultAppRoleID           = "VAULT_APPROLE_ROLE_ID"
+	vaultAppRoleIDFlag       = "vaultAppRoleID"
+	vaultAppRoleSecretID     = "VAULT_APPROLE_SECRET_ID"
+	vaultAppRoleSecretIDFlag = "vaultAppRoleSecretID"
+
+	// The environmental variable names for Vault TLS client certificate and key files.
+	vaultClientCert     = "VAULT_CLIENT_CERT"
+	vaultClientCertFlag = "vaultClientCert"
+	vaultClientKey      = "VAULT_CLIENT_KEY"
+	vaultClientKeyFlag  = "vaultClientKey"
+
+	// The environmental variable name for Vault CA chain path.
+	vaultCAChainPath     = "VAULT_CA_CHAIN_PATH"
+	vaultCAChainPathFlag = "vaultCAChainPath"
+
 	// The environmental variable name for the flag which is used to indicate the token passed
 	// from envoy is always valid(ex, normal 8ks JWT).
 	alwaysValidTokenFlag     = "VALID_TOKEN"
@@ -94,6 +118,15 @@ const (
 	// validate the certificate's format which is returned by CA.
 	skipValidateCertFlag = "SKIP_CERT_VALIDATION"
 
+	// The environmental variable name for the flag which is used to indicate whether to fetch the
+	// certificate revocation list from CA and deliver it to proxies along with the root cert.
+	enableCRLFlag = "ENABLE_CRL"
+
+	// The environmental variable name for the algorithm of the private keys of the workload certificates,
+	// e.g. RSA_2048 or ECDSA_P256.
+	workloadKeyAlgorithm     = "WORKLOAD_KEY_ALGORITHM"
+	workloadKeyAlgorithmFlag = "workloadKeyAlgorithm"
+
 	// The environmental variable name for secret TTL, node agent decides whether a secret
 	// is expired if time.now - secret.createtime >= secretTTL.
 	// example value format like "90m"
@@ -123,15 +156,24 @@ const (
 	MonitoringPort  = "MONITORING_PORT"
 	EnableProfiling = "ENABLE_PROFILING"
 	DebugPort       = "DEBUG_PORT"
+
+	// The TokenReview API verifying the workload service account tokens for the Vault AppRole and cert auth
+	// methods, and the in-cluster credentials of the node agent calling it.
+	k8sTokenReviewURL = "https://kubernetes.default.svc/apis/authentication.k8s.io/v1/tokenreviews"
+	k8sCACertPath     = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+	k8sTokenPath      = "/var/run/secrets/kubernetes.io/serviceaccount/token"
 )
 
 var (
 	workloadSdsCacheOptions cache.Options
 	gatewaySdsCacheOptions  cache.Options
 	serverOptions           sds.Options
-	gatewaySecretChan       chan struct{}
-	loggingOptions          = log.DefaultOptions()
-	ctrlzOptions            = ctrlz.DefaultOptions()
+	keyAlgorithm            string
+	// The resource bindings of the ingress gateway TCP SDS clients, parsed into serverOptions.
+	gatewayTCPResourceBindings []string
+	gatewaySecretChan          chan struct{}
+	loggingOptions             = log.DefaultOptions()
+	ctrlzOptions               = ctrlz.DefaultOptions()
 	// rootCmd defines the command for node agent.
 	rootCmd = &cobra.Command{
 		Use:   "nodeagent",
@@ -195,14 +237,46 @@ func exitOnMonitorServerError(errCh <-chan error) {
 	}
 }
 
+// newVaultTokenReviewer creates the reviewer of the workload service account tokens for the Vault auth methods
+// which log in as the node agent, or returns nil for the Kubernetes auth method, which logs in as the workload.
+func newVaultTokenReviewer(authMethod string) (vault.TokenReviewer, error) {
+	if authMethod != vault.AuthMethodAppRole && authMethod != vault.AuthMethodCert {
+		return nil, nil
+	}
+	caCert, err := ioutil.ReadFile(k8sCACertPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CA certificate of the API server: %v", err)
+	}
+	token, err := ioutil.ReadFile(k8sTokenPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the service account token: %v", err)
+	}
+	return tokenreview.NewK8sSvcAcctAuthn(k8sTokenReviewURL, caCert, string(token)), nil
+}
+
 // newSecretCache creates the cache for workload secrets and/or gateway secrets.
 // Although currently not used, Citadel Agent can serve both workload and gateway secrets at the same time.
 func newSecretCache(serverOptions sds.Options) (workloadSecretCache, gatewaySecretCache *cache.SecretCache) {
 	if serverOptions.EnableWorkloadSDS {
+		tokenReviewer, err := newVaultTokenReviewer(serverOptions.VaultAuthMethod)
+		if err != nil {
+			log.Errorf("failed to create the token reviewer for Vault: %v", err)
+			os.Exit(1)
+		}
 		wSecretFetcher, err := secretfetcher.NewSecretFetcher(false, serverOptions.CAEndpoint,
-			serverOptions.CAProviderName, true, []byte(serverOptions.VaultTLSRootCert),
-			serverOptions.VaultAddress, serverOptions.VaultRole, serverOptions.VaultAuthPath,
-			serverOptions.VaultSignCsrPath)
+			serverOptions.CAProviderName, true, []byte(serverOptions.VaultTLSRootCert), vault.Config{
+				Addr:            serverOptions.VaultAddress,
+				AuthMethod:      serverOptions.VaultAuthMethod,
+				LoginPath:       serverOptions.VaultAuthPath,
+				LoginRole:       serverOptions.VaultRole,
+				AppRoleID:       serverOptions.VaultAppRoleID,
+				AppRoleSecretID: serverOptions.VaultAppRoleSecretID,
+				ClientCertFile:  serverOptions.VaultClientCertFile,
+				ClientKeyFile:   serverOptions.VaultClientKeyFile,
+				SignCsrPath:     serverOptions.VaultSignCsrPath,
+				CAChainPath:     serverOptions.VaultCAChainPath,
+				TokenReviewer:   tokenReviewer,
+			})
 		if err != nil {
 			log.Errorf("failed to create secretFetcher for workload proxy: %v", err)
 			os.Exit(1)
@@ -215,7 +289,7 @@ func newSecretCache(serverOptions sds.Options) (workloadSecretCache, gatewaySecr
 	}
 
 	if serverOptions.EnableIngressGatewaySDS {
-		gSecretFetcher, err := secretfetcher.NewSecretFetcher(true, "", "", false, nil, "", "", "", "")
+		gSecretFetcher, err := secretfetcher.NewSecretFetcher(true, "", "", false, nil, vault.Config{})
 		if err != nil {
 			log.Errorf("failed to create secretFetcher for gateway proxy: %v", err)
 			os.Exit(1)
@@ -235,6 +309,8 @@ var (
 	enableIngressGatewaySDSEnv         = env.RegisterBoolVar(enableIngressGatewaySDS, false, "").Get()
 	alwaysValidTokenFlagEnv            = env.RegisterBoolVar(alwaysValidTokenFlag, false, "").Get()
 	skipValidateCertFlagEnv            = env.RegisterBoolVar(skipValidateCertFlag, false, "").Get()
+	enableCRLFlagEnv                   = env.RegisterBoolVar(enableCRLFlag, false, "").Get()
+	workloadKeyAlgorithmEnv            = env.RegisterStringVar(workloadKeyAlgorithm, "", "").Get()
 	caProviderEnv                      = env.RegisterStringVar(caProvider, "", "").Get()
 	caEndpointEnv                      = env.RegisterStringVar(caEndpoint, "", "").Get()
 	trustDomainEnv                     = env.RegisterStringVar(trustDomain, "", "").Get()
@@ -243,6 +319,12 @@ var (
 	vaultAuthPathEnv                   = env.RegisterStringVar(vaultAuthPath, "", "").Get()
 	vaultSignCsrPathEnv                = env.RegisterStringVar(vaultSignCsrPath, "", "").Get()
 	vaultTLSRootCertEnv                = env.RegisterStringVar(vaultTLSRootCert, "", "").Get()
+	vaultAuthMethodEnv                 = env.RegisterStringVar(vaultAuthMethod, "", "").Get()
+	vaultAppRoleIDEnv                  = env.RegisterStringVar(vaultAppRoleID, "", "").Get()
+	vaultAppRoleSecretIDEnv            = env.RegisterStringVar(vaultAppRoleSecretID, "", "").Get()
+	vaultClientCertEnv                 = env.RegisterStringVar(vaultClientCert, "", "").Get()
+	vaultClientKeyEnv                  = env.RegisterStringVar(vaultClientKey, "", "").Get()
+	vaultCAChainPathEnv                = env.RegisterStringVar(vaultCAChainPath, "", "").Get()
 	secretTTLEnv                       = env.RegisterDurationVar(secretTTL, 24*time.Hour, "").Get()
 	secretRefreshGraceDurationEnv      = env.RegisterDurationVar(SecretRefreshGraceDuration, 1*time.Hour, "").Get()
 	secretRotationIntervalEnv          = env.RegisterDurationVar(SecretRotationInterval, 10*time.Minute, "").Get()
@@ -305,6 +387,30 @@ func applyEnvVars(cmd *cobra.Command) {
 		serverOptions.VaultTLSRootCert = vaultTLSRootCertEnv
 	}
 
+	if !cmd.Flag(vaultAuthMethodFlag).Changed {
+		serverOptions.VaultAuthMethod = vaultAuthMethodEnv
+	}
+
+	if !cmd.Flag(vaultAppRoleIDFlag).Changed {
+		serverOptions.VaultAppRoleID = vaultAppRoleIDEnv
+	}
+
+	if !cmd.Flag(vaultAppRoleSecretIDFlag).Changed {
+		serverOptions.VaultAppRoleSecretID = vaultAppRoleSecretIDEnv
+	}
+
+	if !cmd.Flag(vaultClientCertFlag).Changed {
+		serverOptions.VaultClientCertFile = vaultClientCertEnv
+	}
+
+	if !cmd.Flag(vaultClientKeyFlag).Changed {
+		serverOptions.VaultClientKeyFile = vaultClientKeyEnv
+	}
+
+	if !cmd.Flag(vaultCAChainPathFlag).Changed {
+		serverOptions.VaultCAChainPath = vaultCAChainPathEnv
+	}
+
 	if !cmd.Flag(secretTTLFlag).Changed {
 		workloadSdsCacheOptions.SecretTTL = secretTTLEnv
 	}
@@ -321,6 +427,14 @@ func applyEnvVars(cmd *cobra.Command) {
 		workloadSdsCacheOptions.SkipValidateCert = skipValidateCertFlagEnv
 	}
 
+	if !cmd.Flag(enableCRLFlag).Changed {
+		workloadSdsCacheOptions.EnableCRL = enableCRLFlagEnv
+	}
+
+	if !cmd.Flag(workloadKeyAlgorithmFlag).Changed {
+		keyAlgorithm = workloadKeyAlgorithmEnv
+	}
+
 	serverOptions.RecycleInterval = staledConnectionRecycleIntervalEnv
 
 	if !cmd.Flag(InitialBackoffFlag).Changed {
@@ -338,11 +452,31 @@ func validateOptions() error {
 		return fmt.Errorf("initial backoff should be within range 10 to 120000, found: %d", initBackoff)
 	}
 
+	alg, err := util.ParseKeyAlgorithm(keyAlgorithm)
+	if err != nil {
+		return err
+	}
+	workloadSdsCacheOptions.KeyAlgorithm = alg
+
 	if serverOptions.EnableIngressGatewaySDS && serverOptions.EnableWorkloadSDS &&
 		serverOptions.IngressGatewayUDSPath == serverOptions.WorkloadUDSPath {
 		return fmt.Errorf("UDS paths for ingress gateway and workload cannot be the same: %s", serverOptions.IngressGatewayUDSPath)
 	}
 
+	if serverOptions.WorkloadTCPAddress != "" || serverOptions.IngressGatewayTCPAddress != "" {
+		if serverOptions.TCPCertFile == "" || serverOptions.TCPKeyFile == "" || serverOptions.TCPClientRootCertFile == "" {
+			return fmt.Errorf("the certificate, key and client root certificate of the TCP SDS servers must be set " +
+				"when SDS is served over TCP")
+		}
+		if serverOptions.WorkloadTCPAddress == serverOptions.IngressGatewayTCPAddress {
+			return fmt.Errorf("TCP addresses for ingress gateway and workload cannot be the same: %s",
+				serverOptions.WorkloadTCPAddress)
+		}
+	}
+	if serverOptions.GatewayTCPResourceBindings, err = sds.ParseResourceBindings(gatewayTCPResourceBindings); err != nil {
+		return err
+	}
+
 	if serverOptions.EnableWorkloadSDS {
 		if serverOptions.CAProviderName == "" {
 			return fmt.Errorf("CA provider cannot be empty when workload SDS is enabled")
@@ -378,6 +512,21 @@ func main() {
 	rootCmd.PersistentFlags().StringVar(&serverOptions.CertFile, "sdsCertFile", "", "SDS gRPC TLS server-side certificate")
 	rootCmd.PersistentFlags().StringVar(&serverOptions.KeyFile, "sdsKeyFile", "", "SDS gRPC TLS server-side key")
 
+	rootCmd.PersistentFlags().StringVar(&serverOptions.WorkloadTCPAddress, "workloadSdsTcpAddress", "",
+		"TCP address, e.g. :8234, on which the SDS server for workload proxies also listens with mTLS. Disabled if empty.")
+	rootCmd.PersistentFlags().StringVar(&serverOptions.IngressGatewayTCPAddress, "gatewaySdsTcpAddress", "",
+		"TCP address on which the SDS server for ingress gateway proxies also listens with mTLS. Disabled if empty.")
+	rootCmd.PersistentFlags().StringVar(&serverOptions.TCPCertFile, "sdsTcpCertFile", "",
+		"Bootstrap certificate presented by the TCP SDS servers")
+	rootCmd.PersistentFlags().StringVar(&serverOptions.TCPKeyFile, "sdsTcpKeyFile", "",
+		"Key of the bootstrap certificate of the TCP SDS servers")
+	rootCmd.PersistentFlags().StringVar(&serverOptions.TCPClientRootCertFile, "sdsTcpClientRootCertFile", "",
+		"Root certificate of the client certificates required by the TCP SDS servers")
+	rootCmd.PersistentFlags().StringArrayVar(&gatewayTCPResourceBindings, "gatewaySdsTcpResourceBinding", []string{},
+		"Resources that an ingress gateway TCP SDS client may request, as <identity>=<resource name>[|...]. "+
+			"A resource name ending with * matches any suffix. Workload TCP SDS clients may only request the "+
+			"certificates of the identity of their client certificate.")
+
 	rootCmd.PersistentFlags().DurationVar(&workloadSdsCacheOptions.SecretTTL, secretTTLFlag,
 		24*time.Hour, "Secret's TTL")
 	rootCmd.PersistentFlags().DurationVar(&workloadSdsCacheOptions.SecretRefreshGraceDuration, secretRefreshGraceDurationFlag,
@@ -399,6 +548,14 @@ func main() {
 		false,
 		"If true, node agent skip validating format of certificate returned from CA.")
 
+	rootCmd.PersistentFlags().BoolVar(&workloadSdsCacheOptions.EnableCRL, enableCRLFlag,
+		false,
+		"If true, node agent fetches the certificate revocation list from CA and sends it to proxies with the root cert.")
+
+	rootCmd.PersistentFlags().StringVar(&keyAlgorithm, workloadKeyAlgorithmFlag, "",
+		fmt.Sprintf("Algorithm of the private keys of the workload certificates, one of %v (default %s)",
+			util.SupportedKeyAlgorithms, util.DefaultKeyAlgorithm))
+
 	rootCmd.PersistentFlags().StringVar(&serverOptions.VaultAddress, vaultAddressFlag, "",
 		"Vault address")
 	rootCmd.PersistentFlags().StringVar(&serverOptions.VaultRole, vaultRoleFlag, "",
@@ -406,9 +563,22 @@ func main() {
 	rootCmd.PersistentFlags().StringVar(&serverOptions.VaultAuthPath, vaultAuthPathFlag, "",
 		"Vault auth path")
 	rootCmd.PersistentFlags().StringVar(&serverOptions.VaultSignCsrPath, vaultSignCsrPathFlag, "",
-		"Vault sign CSR path")
+		"Vault sign CSR path, a template receiving the Namespace, ServiceAccount and TrustDomain of the workload, "+
+			"e.g. pki/sign/{{.Namespace}}")
 	rootCmd.PersistentFlags().StringVar(&serverOptions.VaultTLSRootCert, vaultTLSRootCertFlag, "",
 		"Vault TLS root certificate")
+	rootCmd.PersistentFlags().StringVar(&serverOptions.VaultAuthMethod, vaultAuthMethodFlag, "",
+		"Vault auth method: kubernetes (default), approle or cert")
+	rootCmd.PersistentFlags().StringVar(&serverOptions.VaultAppRoleID, vaultAppRoleIDFlag, "",
+		"Vault AppRole role ID, for the approle auth method")
+	rootCmd.PersistentFlags().StringVar(&serverOptions.VaultAppRoleSecretID, vaultAppRoleSecretIDFlag, "",
+		"Vault AppRole secret ID, for the approle auth method")
+	rootCmd.PersistentFlags().StringVar(&serverOptions.VaultClientCertFile, vaultClientCertFlag, "",
+		"Vault TLS client certificate file, for the cert auth method")
+	rootCmd.PersistentFlags().StringVar(&serverOptions.VaultClientKeyFile, vaultClientKeyFlag, "",
+		"Vault TLS client key file, for the cert auth method")
+	rootCmd.PersistentFlags().StringVar(&serverOptions.VaultCAChainPath, vaultCAChainPathFlag, "",
+		"Vault path of the CA certificate chain, used to complete the signed certificate chain with the root")
 
 	// Attach the Istio logging options to the command.
 	loggingOptions.AttachCobraFlags(rootCmd)
@@ -60,9 +60,6 @@ const (
 	recommendedMinGracePeriodRatio = 0.2
 	recommendedMaxGracePeriodRatio = 0.8
 
-	// The size of a private key for a leaf certificate.
-	keySize = 2048
-
 	// The number of retries when requesting to create secret.
 	secretCreationRetry = 3
 
@@ -98,13 +95,15 @@ type WebhookController struct {
 	// Length of the grace period for the certificate rotation.
 	gracePeriodRatio float32
 	certUtil         certutil.CertUtil
+	// The algorithm of the generated private keys.
+	keyAlgorithm util.KeyAlgorithm
 }
 
 // NewWebhookController returns a pointer to a newly constructed WebhookController instance.
 func NewWebhookController(gracePeriodRatio float32, minGracePeriod time.Duration,
 	core corev1.CoreV1Interface, admission admissionv1.AdmissionregistrationV1beta1Interface,
 	certClient certclient.CertificatesV1beta1Interface, k8sCaCertFile string,
-	secretNames, dnsNames, serviceNamespaces []string) (*WebhookController, error) {
+	secretNames, dnsNames, serviceNamespaces []string, keyAlgorithm util.KeyAlgorithm) (*WebhookController, error) {
 	if gracePeriodRatio < 0 || gracePeriodRatio > 1 {
 		return nil, fmt.Errorf("grace period ratio %f should be within [0, 1]", gracePeriodRatio)
 	}
@@ -139,6 +138,7 @@ func NewWebhookController(gracePeriodRatio float32, minGracePeriod time.Duration
 		dnsNames:          dnsNames,
 		serviceNamespaces: serviceNamespaces,
 		certUtil:          certutil.NewCertUtil(int(gracePeriodRatio * 100)),
+		keyAlgorithm:      keyAlgorithm,
 	}
 
 	// read CA cert at the beginning of launching the controller.
@@ -213,7 +213,8 @@ func (wc *WebhookController) upsertSecret(secretName, dnsName, secretNamespace s
 	}
 
 	// Now we know the secret does not exist yet. So we create a new one.
-	chain, key, caCert, err := GenKeyCertK8sCA(wc.certClient.CertificateSigningRequests(), dnsName, secretName, secretNamespace, wc.k8sCaCertFile)
+	chain, key, caCert, err := GenKeyCertK8sCA(wc.certClient.CertificateSigningRequests(), dnsName, secretName, secretNamespace,
+		wc.k8sCaCertFile, wc.keyAlgorithm)
 	if err != nil {
 		log.Errorf("failed to generate key and certificate for secret %v in namespace %v (error %v)",
 			secretName, secretNamespace, err)
@@ -334,7 +335,8 @@ func (wc *WebhookController) refreshSecret(scrt *v1.Secret) error {
 		return fmt.Errorf("failed to find the service name for the secret (%v) to refresh", scrtName)
 	}
 
-	chain, key, caCert, err := GenKeyCertK8sCA(wc.certClient.CertificateSigningRequests(), dnsName, scrtName, namespace, wc.k8sCaCertFile)
+	chain, key, caCert, err := GenKeyCertK8sCA(wc.certClient.CertificateSigningRequests(), dnsName, scrtName, namespace,
+		wc.k8sCaCertFile, wc.keyAlgorithm)
 	if err != nil {
 		return err
 	}
@@ -42,13 +42,16 @@ import (
 // 4. Read the signed certificate
 // 5. Clean up the artifacts (e.g., delete CSR)
 func GenKeyCertK8sCA(certClient certclient.CertificateSigningRequestInterface, dnsName,
-	secretName, secretNamespace, caFilePath string) ([]byte, []byte, []byte, error) {
+	secretName, secretNamespace, caFilePath string, keyAlgorithm util.KeyAlgorithm) ([]byte, []byte, []byte, error) {
 	// 1. Generate a CSR
+	if keyAlgorithm == "" {
+		keyAlgorithm = util.DefaultKeyAlgorithm
+	}
 	options := util.CertOptions{
-		Host:       dnsName,
-		RSAKeySize: keySize,
-		IsDualUse:  false,
-		PKCS8Key:   false,
+		Host:         dnsName,
+		KeyAlgorithm: keyAlgorithm,
+		IsDualUse:    false,
+		PKCS8Key:     false,
 	}
 	csrPEM, keyPEM, err := util.GenCSR(options)
 	if err != nil {
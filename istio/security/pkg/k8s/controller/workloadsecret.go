@@ -68,9 +68,6 @@ const (
 	recommendedMinGracePeriodRatio = 0.2
 	recommendedMaxGracePeriodRatio = 0.8
 
-	// The size of a private key for a leaf certificate.
-	keySize = 2048
-
 	// The number of retries when requesting to create secret.
 	secretCreationRetry = 3
 
@@ -160,6 +157,9 @@ type SecretController struct {
 	// If true, generate a PKCS#8 private key.
 	pkcs8Key bool
 
+	// The algorithm of the generated private keys.
+	keyAlgorithm util.KeyAlgorithm
+
 	// The most recent time when root cert in keycertbundle is synced with root
 	// cert in istio-ca-secret.
 	lastKCBSyncTime time.Time
@@ -172,7 +172,8 @@ type SecretController struct {
 // NewSecretController returns a pointer to a newly constructed SecretController instance.
 func NewSecretController(ca certificateAuthority, enableNamespacesByDefault bool,
 	certTTL time.Duration, gracePeriodRatio float32, minGracePeriod time.Duration,
-	dualUse bool, core corev1.CoreV1Interface, forCA bool, pkcs8Key bool, namespaces []string,
+	dualUse bool, core corev1.CoreV1Interface, forCA bool, pkcs8Key bool, keyAlgorithm util.KeyAlgorithm,
+	namespaces []string,
 	dnsNames map[string]*DNSNameEntry, istioCaStorageNamespace, rootCertFile string,
 	selfSignedCa bool) (*SecretController, error) {
 
@@ -198,6 +199,7 @@ func NewSecretController(ca certificateAuthority, enableNamespacesByDefault bool
 		core:                       core,
 		forCA:                      forCA,
 		pkcs8Key:                   pkcs8Key,
+		keyAlgorithm:               keyAlgorithm,
 		namespaces:                 make(map[string]struct{}),
 		dnsNames:                   dnsNames,
 		monitoring:                 newMonitoringMetrics(),
@@ -473,10 +475,13 @@ func (sc *SecretController) generateKeyAndCert(saName string, saNamespace string
 	}
 
 	options := util.CertOptions{
-		Host:       id,
-		RSAKeySize: keySize,
-		IsDualUse:  sc.dualUse,
-		PKCS8Key:   sc.pkcs8Key,
+		Host:         id,
+		KeyAlgorithm: sc.keyAlgorithm,
+		IsDualUse:    sc.dualUse,
+		PKCS8Key:     sc.pkcs8Key,
+	}
+	if options.KeyAlgorithm == "" {
+		options.KeyAlgorithm = util.DefaultKeyAlgorithm
 	}
 
 	csrPEM, keyPEM, err := util.GenCSR(options)
@@ -213,7 +213,7 @@ func TestSecretController(t *testing.T) {
 		}
 		controller, err := NewSecretController(createFakeCA(), enableNamespacesByDefault,
 			defaultTTL, tc.gracePeriodRatio, defaultMinGracePeriod, false, client.CoreV1(),
-			false, false, []string{metav1.NamespaceAll}, webhooks,
+			false, false, util.DefaultKeyAlgorithm, []string{metav1.NamespaceAll}, webhooks,
 			"test-ns", "", false)
 		if tc.shouldFail {
 			if err == nil {
@@ -252,7 +252,7 @@ func TestSecretContent(t *testing.T) {
 	client := fake.NewSimpleClientset()
 	controller, err := NewSecretController(createFakeCA(), enableNamespacesByDefault,
 		defaultTTL, defaultGracePeriodRatio, defaultMinGracePeriod, false,
-		client.CoreV1(), false, false, []string{metav1.NamespaceAll}, map[string]*DNSNameEntry{},
+		client.CoreV1(), false, false, util.DefaultKeyAlgorithm, []string{metav1.NamespaceAll}, map[string]*DNSNameEntry{},
 		"test-namespace", "", false)
 	if err != nil {
 		t.Errorf("Failed to create secret controller: %v", err)
@@ -277,7 +277,7 @@ func TestDeletedIstioSecret(t *testing.T) {
 	client := fake.NewSimpleClientset()
 	controller, err := NewSecretController(createFakeCA(), enableNamespacesByDefault,
 		defaultTTL, defaultGracePeriodRatio, defaultMinGracePeriod, false,
-		client.CoreV1(), false, false, []string{metav1.NamespaceAll}, nil,
+		client.CoreV1(), false, false, util.DefaultKeyAlgorithm, []string{metav1.NamespaceAll}, nil,
 		"test-ns", "", false)
 	if err != nil {
 		t.Errorf("failed to create secret controller: %v", err)
@@ -463,7 +463,7 @@ func TestUpdateSecret(t *testing.T) {
 		ca := createFakeCA()
 		controller, err := NewSecretController(ca, enableNamespacesByDefault, time.Hour,
 			tc.gracePeriodRatio, tc.minGracePeriod, false, client.CoreV1(), false,
-			false, []string{metav1.NamespaceAll}, nil, "", "",
+			false, util.DefaultKeyAlgorithm, []string{metav1.NamespaceAll}, nil, "", "",
 			true)
 		if err != nil {
 			t.Errorf("failed to create secret controller: %v", err)
@@ -562,7 +562,7 @@ func TestManagedNamespaceRules(t *testing.T) {
 			client := fake.NewSimpleClientset()
 			controller, err := NewSecretController(createFakeCA(), tc.enableNamespacesByDefault,
 				defaultTTL, defaultGracePeriodRatio, defaultMinGracePeriod, false,
-				client.CoreV1(), false, false, []string{metav1.NamespaceAll},
+				client.CoreV1(), false, false, util.DefaultKeyAlgorithm, []string{metav1.NamespaceAll},
 				nil, tc.istioCaStorageNamespace, "", false)
 			if err != nil {
 				t.Errorf("failed to create secret controller: %v", err)
@@ -637,7 +637,7 @@ func TestRetroactiveNamespaceActivation(t *testing.T) {
 			client := fake.NewSimpleClientset()
 			controller, err := NewSecretController(createFakeCA(), tc.enableNamespacesByDefault,
 				defaultTTL, defaultGracePeriodRatio, defaultMinGracePeriod, false,
-				client.CoreV1(), false, false, []string{metav1.NamespaceAll},
+				client.CoreV1(), false, false, util.DefaultKeyAlgorithm, []string{metav1.NamespaceAll},
 				nil, tc.istioCaStorageNamespace, "", false)
 			if err != nil {
 				t.Errorf("failed to create secret controller: %v", err)
@@ -0,0 +1,56 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csrca
+
+import (
+	"crypto"
+	"crypto/x509"
+	"fmt"
+
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+// rootCertBundle is a KeyCertBundle holding root certificates only, since the signing key stays with the
+// Kubernetes signer.
+type rootCertBundle struct {
+	rootCertBytes []byte
+}
+
+var _ util.KeyCertBundle = &rootCertBundle{}
+
+func (b *rootCertBundle) GetAllPem() (certBytes, privKeyBytes, certChainBytes, rootCertBytes []byte) {
+	return nil, nil, nil, b.rootCertBytes
+}
+
+func (b *rootCertBundle) GetAll() (cert *x509.Certificate, privKey *crypto.PrivateKey, certChainBytes,
+	rootCertBytes []byte) {
+	return nil, nil, nil, b.rootCertBytes
+}
+
+func (b *rootCertBundle) GetCertChainPem() []byte {
+	return nil
+}
+
+func (b *rootCertBundle) GetRootCertPem() []byte {
+	return b.rootCertBytes
+}
+
+func (b *rootCertBundle) VerifyAndSetAll(_, _, _, _ []byte) error {
+	return fmt.Errorf("the key and certificates of the Kubernetes signer can not be set")
+}
+
+func (b *rootCertBundle) CertOptions() (*util.CertOptions, error) {
+	return nil, fmt.Errorf("the certificate of the Kubernetes signer is not available")
+}
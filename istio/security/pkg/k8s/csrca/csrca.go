@@ -0,0 +1,313 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package csrca implements a CA that delegates the signing of the certificates to the Kubernetes
+// certificates.k8s.io CSR API, so that the approval and audit tooling of the cluster applies to them.
+package csrca
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	cert "k8s.io/api/certificates/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	certclient "k8s.io/client-go/kubernetes/typed/certificates/v1beta1"
+
+	caerror "istio.io/istio/security/pkg/pki/error"
+	"istio.io/istio/security/pkg/pki/util"
+	"istio.io/pkg/log"
+)
+
+const (
+	// SubjectIDsAnnotation lists the identities the CSR objects are requested for, separated by comma, for the
+	// approvers to check them.
+	SubjectIDsAnnotation = "security.istio.io/subject-ids"
+
+	// csrNamePrefix is the prefix of the names of the CSR objects created by the CA.
+	csrNamePrefix = "istio-csr-"
+
+	defaultIssuanceTimeout = 30 * time.Second
+	defaultPollInterval    = time.Second
+)
+
+var csrCALog = log.RegisterScope("csrCA", "Kubernetes CSR API CA log", 0)
+
+// Options configures a CA backed by the Kubernetes CSR API.
+type Options struct {
+	// RootCertFile is the path to the PEM-encoded root certificates of the Kubernetes signer, distributed to
+	// the workloads.
+	RootCertFile string
+	// AutoApprove makes the CA approve the CSR objects it creates, which requires the approve permission on
+	// certificatesigningrequests. Otherwise they wait for an external approver.
+	AutoApprove bool
+	// IssuanceTimeout bounds the time waiting for a CSR object to be approved and signed.
+	IssuanceTimeout time.Duration
+	// PollInterval is the interval between two checks of the status of a CSR object.
+	PollInterval time.Duration
+	// AllowedKeyAlgorithms restricts the algorithms of the keys of the CSRs. Any algorithm is allowed if it is
+	// empty.
+	AllowedKeyAlgorithms []util.KeyAlgorithm
+}
+
+// CA signs certificates through the Kubernetes CSR API. It implements the CertificateAuthority interface of
+// the Citadel server.
+//
+// Unlike IstioCA, it can not set the identities of the certificates: they have to be in the CSR already, and
+// CSRs requesting other identities than the caller's are rejected. The lifetime of the certificates is
+// decided by the Kubernetes signer, and CA certificates can not be requested.
+type CA struct {
+	client        certclient.CertificateSigningRequestInterface
+	opts          Options
+	keyCertBundle util.KeyCertBundle
+}
+
+// New returns a CA creating CSR objects with the given client.
+func New(client certclient.CertificateSigningRequestInterface, opts Options) (*CA, error) {
+	rootCerts, err := ioutil.ReadFile(opts.RootCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the root certificates of the Kubernetes signer (%v)", err)
+	}
+	roots, err := util.ParseTrustBundle(rootCerts)
+	if err != nil {
+		return nil, err
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no certificate found in %s", opts.RootCertFile)
+	}
+	if opts.IssuanceTimeout <= 0 {
+		opts.IssuanceTimeout = defaultIssuanceTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+	return &CA{
+		client:        client,
+		opts:          opts,
+		keyCertBundle: &rootCertBundle{rootCertBytes: rootCerts},
+	}, nil
+}
+
+// Sign submits the PEM-encoded CSR to the Kubernetes CSR API, waits for it to be approved and signed, and returns
+// the issued certificate followed by the intermediate certificates returned by the signer, if any.
+func (ca *CA) Sign(csrPEM []byte, subjectIDs []string, _ time.Duration, forCA bool) ([]byte, error) {
+	if forCA {
+		return nil, caerror.NewError(caerror.CSRError,
+			fmt.Errorf("CA certificates can not be signed through the Kubernetes CSR API"))
+	}
+	csr, err := util.ParsePemEncodedCSR(csrPEM)
+	if err != nil {
+		return nil, caerror.NewError(caerror.CSRError, err)
+	}
+	if err = checkIdentities(csr, subjectIDs); err != nil {
+		return nil, caerror.NewError(caerror.CSRError, err)
+	}
+	if err = util.CheckKeyAlgorithm(csr.PublicKey, ca.opts.AllowedKeyAlgorithms); err != nil {
+		return nil, caerror.NewError(caerror.CSRError, err)
+	}
+
+	name := csrName(csrPEM)
+	if err = ca.submit(name, csrPEM, subjectIDs); err != nil {
+		return nil, caerror.NewError(caerror.CertGenError, err)
+	}
+	certPEM, err := ca.waitForCertificate(name)
+	if err != nil {
+		return nil, caerror.NewError(caerror.CertGenError, err)
+	}
+	if err = ca.verify(certPEM, csr); err != nil {
+		return nil, caerror.NewError(caerror.CertGenError, err)
+	}
+	// The signed CSR objects are kept for auditing, the Kubernetes CSR cleaner garbage collects them.
+	csrCALog.Debugf("certificate for %v issued through CSR %s", subjectIDs, name)
+	return certPEM, nil
+}
+
+// SignWithCertChain is the same as Sign, since the certificates returned by the Kubernetes signer already include
+// the intermediate certificates.
+func (ca *CA) SignWithCertChain(csrPEM []byte, subjectIDs []string, ttl time.Duration, forCA bool) ([]byte, error) {
+	return ca.Sign(csrPEM, subjectIDs, ttl, forCA)
+}
+
+// GetCAKeyCertBundle returns a KeyCertBundle holding the root certificates of the Kubernetes signer only.
+func (ca *CA) GetCAKeyCertBundle() util.KeyCertBundle {
+	return ca.keyCertBundle
+}
+
+// checkIdentities verifies that the CSR only requests identities of the caller. The Kubernetes signer copies the
+// subject of the CSR into the certificate, so a CSR with a common name, organization or organizational unit is
+// rejected, since they may be taken as identities by the peers.
+func checkIdentities(csr *x509.CertificateRequest, subjectIDs []string) error {
+	for attr, values := range map[string][]string{
+		"common name":         {csr.Subject.CommonName},
+		"organization":        csr.Subject.Organization,
+		"organizational unit": csr.Subject.OrganizationalUnit,
+	} {
+		for _, v := range values {
+			if v != "" {
+				return fmt.Errorf("the CSR requests the subject %s %q, only identities in the SAN are allowed", attr, v)
+			}
+		}
+	}
+
+	ids, err := util.ExtractIDs(csr.Extensions)
+	if err != nil {
+		return err
+	}
+	allowed := map[string]bool{}
+	for _, id := range subjectIDs {
+		allowed[id] = true
+	}
+	for _, id := range ids {
+		if !allowed[id] {
+			return fmt.Errorf("the CSR requests identity %q, the caller is %v", id, subjectIDs)
+		}
+	}
+	return nil
+}
+
+// csrName returns the name of the CSR object for the given CSR. It is derived from its content, so that
+// retrying a request waits for the same object.
+func csrName(csrPEM []byte) string {
+	sum := sha256.Sum256(csrPEM)
+	return csrNamePrefix + hex.EncodeToString(sum[:10])
+}
+
+func (ca *CA) submit(name string, csrPEM []byte, subjectIDs []string) error {
+	k8sCSR := &cert.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{SubjectIDsAnnotation: strings.Join(subjectIDs, ",")},
+		},
+		Spec: cert.CertificateSigningRequestSpec{
+			Request: csrPEM,
+			Usages: []cert.KeyUsage{
+				cert.UsageDigitalSignature,
+				cert.UsageKeyEncipherment,
+				cert.UsageServerAuth,
+				cert.UsageClientAuth,
+			},
+		},
+	}
+	created, err := ca.client.Create(k8sCSR)
+	if kerrors.IsAlreadyExists(err) {
+		csrCALog.Debugf("CSR %s already exists, waiting for it", name)
+		created, err = ca.client.Get(name, metav1.GetOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create CSR %s (%v)", name, err)
+	}
+	if !ca.opts.AutoApprove || isApproved(created) {
+		return nil
+	}
+
+	created.Status.Conditions = append(created.Status.Conditions, cert.CertificateSigningRequestCondition{
+		Type:    cert.CertificateApproved,
+		Reason:  "IstioAutoApproved",
+		Message: fmt.Sprintf("approved by Citadel for %s", strings.Join(subjectIDs, ",")),
+	})
+	if _, err = ca.client.UpdateApproval(created); err != nil {
+		return fmt.Errorf("failed to approve CSR %s (%v)", name, err)
+	}
+	return nil
+}
+
+// waitForCertificate returns the certificate issued for the CSR object, or an error if it was denied or not
+// issued in time, in which case the CSR object is deleted.
+func (ca *CA) waitForCertificate(name string) ([]byte, error) {
+	var certPEM []byte
+	var denied error
+	err := wait.PollImmediate(ca.opts.PollInterval, ca.opts.IssuanceTimeout, func() (bool, error) {
+		r, err := ca.client.Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, c := range r.Status.Conditions {
+			if c.Type == cert.CertificateDenied {
+				denied = fmt.Errorf("CSR %s was denied: %s %s", name, c.Reason, c.Message)
+				return true, nil
+			}
+		}
+		if len(r.Status.Certificate) == 0 {
+			return false, nil
+		}
+		certPEM = r.Status.Certificate
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		// Do not leave pending requests behind for the approvers.
+		if delErr := ca.client.Delete(name, nil); delErr != nil && !kerrors.IsNotFound(delErr) {
+			csrCALog.Warnf("failed to delete CSR %s (%v)", name, delErr)
+		}
+		return nil, fmt.Errorf("CSR %s was not signed within %v", name, ca.opts.IssuanceTimeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSR %s (%v)", name, err)
+	}
+	if denied != nil {
+		return nil, denied
+	}
+	return certPEM, nil
+}
+
+// verify checks that the issued certificate is for the key of the CSR and chains up to the roots.
+func (ca *CA) verify(certPEM []byte, csr *x509.CertificateRequest) error {
+	certs, err := util.ParseTrustBundle(certPEM)
+	if err != nil {
+		return err
+	}
+	if len(certs) == 0 {
+		return fmt.Errorf("no certificate returned by the Kubernetes signer")
+	}
+	leafKey, err := x509.MarshalPKIXPublicKey(certs[0].PublicKey)
+	if err != nil {
+		return err
+	}
+	csrKey, err := x509.MarshalPKIXPublicKey(csr.PublicKey)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(leafKey, csrKey) {
+		return fmt.Errorf("the issued certificate does not match the key of the CSR")
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         x509.NewCertPool(),
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	opts.Roots.AppendCertsFromPEM(ca.keyCertBundle.GetRootCertPem())
+	for _, c := range certs[1:] {
+		opts.Intermediates.AddCert(c)
+	}
+	if _, err = certs[0].Verify(opts); err != nil {
+		return fmt.Errorf("failed to verify the issued certificate (%v)", err)
+	}
+	return nil
+}
+
+func isApproved(csr *cert.CertificateSigningRequest) bool {
+	for _, c := range csr.Status.Conditions {
+		if c.Type == cert.CertificateApproved {
+			return true
+		}
+	}
+	return false
+}
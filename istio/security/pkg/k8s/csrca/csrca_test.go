@@ -0,0 +1,321 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csrca
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	cert "k8s.io/api/certificates/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	certclient "k8s.io/client-go/kubernetes/typed/certificates/v1beta1"
+
+	caerror "istio.io/istio/security/pkg/pki/error"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+const testID = "spiffe://cluster.local/ns/foo/sa/bar"
+
+type signerAction int
+
+const (
+	// signApproved signs the approved CSR objects only.
+	signApproved signerAction = iota
+	// approveAndSign approves the pending CSR objects and signs them.
+	approveAndSign
+	// deny denies the CSR objects.
+	deny
+	// ignore leaves the CSR objects pending.
+	ignore
+)
+
+// fakeSigner plays the role of the approver and of the Kubernetes signer.
+type fakeSigner struct {
+	client   certclient.CertificateSigningRequestInterface
+	rootCert *x509.Certificate
+	rootKey  crypto.PrivateKey
+	action   signerAction
+}
+
+func (s *fakeSigner) run(t *testing.T, stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+		list, err := s.client.List(metav1.ListOptions{})
+		if err != nil {
+			t.Errorf("failed to list CSRs: %v", err)
+			return
+		}
+		for i := range list.Items {
+			s.process(t, &list.Items[i])
+		}
+	}
+}
+
+func (s *fakeSigner) process(t *testing.T, r *cert.CertificateSigningRequest) {
+	if len(r.Status.Certificate) > 0 || s.action == ignore {
+		return
+	}
+	switch s.action {
+	case deny:
+		if len(r.Status.Conditions) == 0 {
+			r.Status.Conditions = append(r.Status.Conditions, cert.CertificateSigningRequestCondition{
+				Type: cert.CertificateDenied, Reason: "PolicyViolation", Message: "not allowed"})
+			if _, err := s.client.UpdateApproval(r); err != nil {
+				t.Errorf("failed to deny CSR: %v", err)
+			}
+		}
+		return
+	case approveAndSign:
+		if !isApproved(r) {
+			r.Status.Conditions = append(r.Status.Conditions, cert.CertificateSigningRequestCondition{
+				Type: cert.CertificateApproved, Reason: "Approved"})
+			if _, err := s.client.UpdateApproval(r); err != nil {
+				t.Errorf("failed to approve CSR: %v", err)
+				return
+			}
+		}
+	}
+	if !isApproved(r) {
+		return
+	}
+	csr, err := util.ParsePemEncodedCSR(r.Spec.Request)
+	if err != nil {
+		t.Errorf("invalid CSR: %v", err)
+		return
+	}
+	der, err := util.GenCertFromCSR(csr, s.rootCert, csr.PublicKey, s.rootKey,
+		strings.Split(r.Annotations[SubjectIDsAnnotation], ","), time.Hour, false)
+	if err != nil {
+		t.Errorf("failed to sign CSR: %v", err)
+		return
+	}
+	r.Status.Certificate = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if _, err := s.client.UpdateStatus(r); err != nil {
+		t.Errorf("failed to update CSR: %v", err)
+	}
+}
+
+func setup(t *testing.T, action signerAction, opts Options) (*CA, certclient.CertificateSigningRequestInterface,
+	func()) {
+	t.Helper()
+	rootPEM, keyPEM, err := util.GenCertKeyFromOptions(util.CertOptions{
+		IsCA:         true,
+		IsSelfSigned: true,
+		TTL:          24 * time.Hour,
+		Org:          "Kubernetes signer",
+		RSAKeySize:   2048,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootCert, err := util.ParsePemEncodedCertificate(rootPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootKey, err := util.ParsePemEncodedKey(keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "csrca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.RootCertFile = filepath.Join(dir, "root-cert.pem")
+	if err = ioutil.WriteFile(opts.RootCertFile, rootPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := fake.NewSimpleClientset().CertificatesV1beta1().CertificateSigningRequests()
+	if opts.PollInterval == 0 {
+		opts.PollInterval = 10 * time.Millisecond
+	}
+	ca, err := New(client, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan struct{})
+	signer := &fakeSigner{client: client, rootCert: rootCert, rootKey: rootKey, action: action}
+	go signer.run(t, stop)
+	return ca, client, func() {
+		close(stop)
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func genCSR(t *testing.T, host string) []byte {
+	t.Helper()
+	csrPEM, _ := genCSRAndKey(t, host)
+	return csrPEM
+}
+
+func genCSRAndKey(t *testing.T, host string) ([]byte, []byte) {
+	t.Helper()
+	csrPEM, keyPEM, err := util.GenCSR(util.CertOptions{Host: host, RSAKeySize: 2048})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return csrPEM, keyPEM
+}
+
+// genCSRWithSubject generates a CSR for testID with the given subject.
+func genCSRWithSubject(t *testing.T, subject pkix.Name) []byte {
+	t.Helper()
+	template, err := util.GenCSRTemplate(util.CertOptions{Host: testID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.Subject = subject
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+}
+
+func checkErrorType(t *testing.T, err error, want string) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("Sign() succeeded, want a %s error", want)
+	}
+	if caErr, ok := err.(*caerror.Error); !ok || caErr.ErrorType() != want {
+		t.Errorf("Sign() returned error %v, want a %s error", err, want)
+	}
+}
+
+func TestSign(t *testing.T) {
+	cases := map[string]struct {
+		action      signerAction
+		autoApprove bool
+	}{
+		"auto approved":         {action: signApproved, autoApprove: true},
+		"approved by the admin": {action: approveAndSign},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ca, client, cleanup := setup(t, c.action, Options{AutoApprove: c.autoApprove, IssuanceTimeout: 5 * time.Second})
+			defer cleanup()
+
+			csrPEM, keyPEM := genCSRAndKey(t, testID)
+			certPEM, err := ca.Sign(csrPEM, []string{testID}, time.Hour, false)
+			if err != nil {
+				t.Fatalf("Sign() failed: %v", err)
+			}
+			fields := &util.VerifyFields{
+				ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+				KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+				Host:        testID,
+			}
+			if err := util.VerifyCertificate(keyPEM, certPEM, ca.GetCAKeyCertBundle().GetRootCertPem(), fields); err != nil {
+				t.Errorf("Failed to verify the issued certificate: %v", err)
+			}
+
+			// The CSR object is kept for auditing.
+			r, err := client.Get(csrName(csrPEM), metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Failed to get the CSR object: %v", err)
+			}
+			if got := r.Annotations[SubjectIDsAnnotation]; got != testID {
+				t.Errorf("Got subject IDs annotation %q, want %q", got, testID)
+			}
+		})
+	}
+}
+
+func TestSignDenied(t *testing.T) {
+	ca, client, cleanup := setup(t, deny, Options{IssuanceTimeout: 5 * time.Second})
+	defer cleanup()
+
+	csrPEM := genCSR(t, testID)
+	_, err := ca.Sign(csrPEM, []string{testID}, time.Hour, false)
+	checkErrorType(t, err, "CERT_GEN_ERROR")
+	if err != nil && !strings.Contains(err.Error(), "denied") {
+		t.Errorf("Sign() returned error %v, want the CSR to be denied", err)
+	}
+	// The denied CSR object is kept for auditing.
+	if _, err := client.Get(csrName(csrPEM), metav1.GetOptions{}); err != nil {
+		t.Errorf("Failed to get the denied CSR object: %v", err)
+	}
+}
+
+func TestSignTimeout(t *testing.T) {
+	ca, client, cleanup := setup(t, ignore, Options{IssuanceTimeout: 100 * time.Millisecond})
+	defer cleanup()
+
+	csrPEM := genCSR(t, testID)
+	_, err := ca.Sign(csrPEM, []string{testID}, time.Hour, false)
+	checkErrorType(t, err, "CERT_GEN_ERROR")
+	if _, err := client.Get(csrName(csrPEM), metav1.GetOptions{}); err == nil {
+		t.Error("The pending CSR object was not deleted after the timeout")
+	}
+}
+
+func TestSignInvalidRequests(t *testing.T) {
+	ca, client, cleanup := setup(t, approveAndSign, Options{AllowedKeyAlgorithms: []util.KeyAlgorithm{util.RSA2048}})
+	defer cleanup()
+
+	ecdsaCSR, _, err := util.GenCSR(util.CertOptions{Host: testID, KeyAlgorithm: util.ECDSAP256})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		csrPEM []byte
+		forCA  bool
+	}{
+		"invalid CSR":       {csrPEM: []byte("invalid")},
+		"other identity":    {csrPEM: genCSR(t, "spiffe://cluster.local/ns/foo/sa/other")},
+		"extra identity":    {csrPEM: genCSR(t, testID+",spiffe://cluster.local/ns/foo/sa/other")},
+		"CA certificate":    {csrPEM: genCSR(t, testID), forCA: true},
+		"no identity in it": {csrPEM: genCSR(t, "")},
+		"disallowed key":    {csrPEM: ecdsaCSR},
+		"common name":       {csrPEM: genCSRWithSubject(t, pkix.Name{CommonName: "admin"})},
+		"organization":      {csrPEM: genCSRWithSubject(t, pkix.Name{Organization: []string{"system:masters"}})},
+		"organizational unit": {
+			csrPEM: genCSRWithSubject(t, pkix.Name{OrganizationalUnit: []string{"ops"}}),
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ca.Sign(c.csrPEM, []string{testID}, time.Hour, c.forCA)
+			checkErrorType(t, err, "CSR_ERROR")
+		})
+	}
+	list, err := client.List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 0 {
+		t.Errorf("Got %d CSR objects for invalid requests, want none", len(list.Items))
+	}
+}
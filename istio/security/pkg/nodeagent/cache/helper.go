@@ -60,6 +60,12 @@ func constructCSRHostName(trustDomain, token string) (string, error) {
 	return fmt.Sprintf(identityTemplate, domain, ns, sa), nil
 }
 
+// IdentityFromToken returns the identity of the certificates requested with a Kubernetes JWT token, i.e. the
+// SPIFFE identity of its service account.
+func IdentityFromToken(trustDomain, token string) (string, error) {
+	return constructCSRHostName(trustDomain, token)
+}
+
 // isRetryableErr checks if a failed request should be retry based on gRPC resp code or http status code.
 func isRetryableErr(c codes.Code, httpRespCode int, isGrpc bool) bool {
 	if isGrpc {
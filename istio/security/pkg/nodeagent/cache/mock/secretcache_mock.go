@@ -22,6 +22,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"math/rand"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -30,6 +31,9 @@ type CAClient struct {
 	signInvokeCount     uint64
 	mockCertChain1st    []string
 	mockCertChainRemain []string
+
+	crlMutex sync.Mutex
+	crl      []byte
 }
 
 func NewMockCAClient(mockCertChain1st, mockCertChainRemain []string) *CAClient {
@@ -62,6 +66,22 @@ func (c *CAClient) CSRSign(ctx context.Context, csrPEM []byte, exchangedToken st
 	return c.mockCertChainRemain, nil
 }
 
+// SetCRL sets the certificate revocation list returned by FetchCRL.
+func (c *CAClient) SetCRL(crl []byte) {
+	c.crlMutex.Lock()
+	defer c.crlMutex.Unlock()
+	c.crl = crl
+}
+
+func (c *CAClient) FetchCRL(ctx context.Context, exchangedToken string) ([]byte, error) {
+	c.crlMutex.Lock()
+	defer c.crlMutex.Unlock()
+	if c.crl == nil {
+		return nil, status.Error(codes.Unimplemented, "CRL is not available")
+	}
+	return c.crl, nil
+}
+
 type TokenExchangeServer struct {
 }
 
@@ -18,6 +18,8 @@ package cache
 import (
 	"bytes"
 	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -28,6 +30,7 @@ import (
 	"time"
 
 	"istio.io/istio/pkg/mcp/status"
+	caClientInterface "istio.io/istio/security/pkg/nodeagent/caclient/interface"
 	"istio.io/istio/security/pkg/nodeagent/model"
 	"istio.io/istio/security/pkg/nodeagent/plugin"
 	"istio.io/istio/security/pkg/nodeagent/secretfetcher"
@@ -41,9 +44,6 @@ var (
 )
 
 const (
-	// The size of a private key for a leaf certificate.
-	keySize = 2048
-
 	// max retry number to wait CSR response come back to parse root cert from it.
 	maxRetryNum = 5
 
@@ -115,6 +115,13 @@ type Options struct {
 
 	// set this flag to true if skip validate format for certificate chain returned from CA.
 	SkipValidateCert bool
+
+	// set this flag to true to fetch the certificate revocation list from the CA, and push it to proxies
+	// along with the root cert. It is refreshed every RotationInterval.
+	EnableCRL bool
+
+	// The algorithm of the private keys of the workload certificates. RSA_2048 if empty.
+	KeyAlgorithm util.KeyAlgorithm
 }
 
 // SecretManager defines secrets management interface which is used by SDS.
@@ -174,6 +181,9 @@ type SecretCache struct {
 	rootCertMutex      *sync.Mutex
 	rootCert           []byte
 	rootCertExpireTime time.Time
+	// certificate revocation list of the CA, and the token used to fetch it.
+	crl      []byte
+	crlToken string
 
 	// Source of random numbers. It is not concurrency safe, requires lock protected.
 	rand      *rand.Rand
@@ -281,6 +291,7 @@ func (sc *SecretCache) GenerateSecret(ctx context.Context, connectionID, resourc
 	ns = &model.SecretItem{
 		ResourceName: resourceName,
 		RootCert:     sc.rootCert,
+		CRL:          sc.crl,
 		ExpireTime:   sc.rootCertExpireTime,
 		Token:        token,
 		CreatedTime:  t,
@@ -391,6 +402,9 @@ func (sc *SecretCache) keyCertRotationJob() {
 		select {
 		case <-sc.rotationTicker.C:
 			sc.rotate(false /*updateRootFlag*/)
+			if sc.configOptions.EnableCRL {
+				sc.rotateCRL()
+			}
 		case <-sc.closing:
 			if sc.rotationTicker != nil {
 				sc.rotationTicker.Stop()
@@ -478,14 +492,37 @@ func (sc *SecretCache) UpdateK8sSecret(secretName string, ns model.SecretItem) {
 	})
 }
 
+// ForceRotate rotates the key/cert secrets of resourceName, or all of them if resourceName is empty, before they
+// expire. It returns the number of secrets rotated.
+func (sc *SecretCache) ForceRotate(resourceName string) (int, error) {
+	if !sc.fetcher.UseCaClient {
+		return 0, fmt.Errorf("the secrets of kubernetes secrets cannot be rotated")
+	}
+	if resourceName == RootCertReqResourceName {
+		return 0, fmt.Errorf("the root certificate cannot be rotated, it changes with the key/cert secrets")
+	}
+	cacheLog.Infof("Forced rotation of the secrets of resource %q", resourceName)
+	rotated := sc.rotateSecrets(false, func(connKey ConnKey) bool {
+		return resourceName == "" || connKey.ResourceName == resourceName
+	})
+	return rotated, nil
+}
+
 func (sc *SecretCache) rotate(updateRootFlag bool) {
+	sc.rotateSecrets(updateRootFlag, nil)
+}
+
+// rotateSecrets refreshes the secrets about to expire, and the ones selected by force if it is not nil. It returns
+// the number of key/cert secrets refreshed.
+func (sc *SecretCache) rotateSecrets(updateRootFlag bool, force func(ConnKey) bool) int {
 	// Skip secret rotation for kubernetes secrets.
 	if !sc.fetcher.UseCaClient {
-		return
+		return 0
 	}
 
 	cacheLog.Debug("Refresh job running")
 
+	var rotated int64
 	var secretMap sync.Map
 	wg := sync.WaitGroup{}
 	sc.secrets.Range(func(k interface{}, v interface{}) bool {
@@ -504,6 +541,7 @@ func (sc *SecretCache) rotate(updateRootFlag bool) {
 			ns := &model.SecretItem{
 				ResourceName: connKey.ResourceName,
 				RootCert:     sc.rootCert,
+				CRL:          sc.crl,
 				ExpireTime:   sc.rootCertExpireTime,
 				Token:        e.Token,
 				CreatedTime:  t,
@@ -529,9 +567,10 @@ func (sc *SecretCache) rotate(updateRootFlag bool) {
 			return true
 		}
 
-		// Re-generate secret if it's expired.
-		if sc.shouldRefresh(&e) {
+		// Re-generate secret if it's expired, or if its rotation is forced.
+		if (force != nil && force(connKey)) || sc.shouldRefresh(&e) {
 			atomic.AddUint64(&sc.secretChangedCount, 1)
+			atomic.AddInt64(&rotated, 1)
 
 			// Send the notification to close the stream if token is expired, so that client could re-connect with a new token.
 			if sc.isTokenExpired() {
@@ -552,6 +591,7 @@ func (sc *SecretCache) rotate(updateRootFlag bool) {
 				ns, err := sc.generateSecret(context.Background(), e.Token, connKey, now)
 				if err != nil {
 					cacheLog.Errorf("%s failed to rotate secret: %v", conIDresourceNamePrefix, err)
+					atomic.AddInt64(&rotated, -1)
 					return
 				}
 
@@ -573,6 +613,7 @@ func (sc *SecretCache) rotate(updateRootFlag bool) {
 		sc.secrets.Store(key, *e)
 		return true
 	})
+	return int(atomic.LoadInt64(&rotated))
 }
 
 // generateGatewaySecret returns secret for ingress gateway proxy.
@@ -716,8 +757,11 @@ func (sc *SecretCache) generateSecret(ctx context.Context, token string, connKey
 		csrHostName = connKey.ResourceName
 	}
 	options := util.CertOptions{
-		Host:       csrHostName,
-		RSAKeySize: keySize,
+		Host:         csrHostName,
+		KeyAlgorithm: sc.configOptions.KeyAlgorithm,
+	}
+	if options.KeyAlgorithm == "" {
+		options.KeyAlgorithm = util.DefaultKeyAlgorithm
 	}
 
 	// Generate the cert/key, send CSR to CA.
@@ -773,8 +817,14 @@ func (sc *SecretCache) generateSecret(ctx context.Context, token string, connKey
 	}
 	sc.rootCertMutex.Unlock()
 
+	crlChanged := sc.configOptions.EnableCRL && sc.refreshCRL(ctx, exchangedToken)
 	if rootCertChanged {
-		cacheLog.Info("Root cert has changed, start rotating root cert for SDS clients")
+		// The root may be a trust bundle of several roots while the mesh migrates from one root to another.
+		roots, _ := util.ParseTrustBundle([]byte(certChainPEM[length-1]))
+		cacheLog.Infof("Root cert has changed (%d trusted roots), start rotating root cert for SDS clients", len(roots))
+		sc.rotate(true /*updateRootFlag*/)
+	} else if crlChanged {
+		cacheLog.Info("Certificate revocation list has changed, start rotating root cert for SDS clients")
 		sc.rotate(true /*updateRootFlag*/)
 	}
 
@@ -789,6 +839,62 @@ func (sc *SecretCache) generateSecret(ctx context.Context, token string, connKey
 	}, nil
 }
 
+// refreshCRL fetches the certificate revocation list from the CA, and returns whether it changed.
+func (sc *SecretCache) refreshCRL(ctx context.Context, token string) bool {
+	client, ok := sc.fetcher.CaClient.(caClientInterface.CRLClient)
+	if !ok {
+		return false
+	}
+	crl, err := client.FetchCRL(ctx, token)
+	if err != nil {
+		cacheLog.Warnf("failed to fetch the certificate revocation list: %v", err)
+		return false
+	}
+
+	sc.rootCertMutex.Lock()
+	defer sc.rootCertMutex.Unlock()
+	sc.crlToken = token
+	if bytes.Equal(sc.crl, crl) {
+		return false
+	}
+	sc.crl = crl
+	return true
+}
+
+// rotateCRL refreshes the certificate revocation list with the last token used to fetch it, and pushes the
+// root cert along with the new list to SDS clients if it changed. A list that expired because it could not be
+// refreshed is dropped, since the proxies would otherwise reject every certificate.
+func (sc *SecretCache) rotateCRL() {
+	sc.rootCertMutex.Lock()
+	token := sc.crlToken
+	sc.rootCertMutex.Unlock()
+	if token == "" {
+		return
+	}
+	if sc.refreshCRL(context.Background(), token) || sc.dropExpiredCRL(time.Now()) {
+		cacheLog.Info("Certificate revocation list has changed, start rotating root cert for SDS clients")
+		sc.rotate(true /*updateRootFlag*/)
+	}
+}
+
+// dropExpiredCRL drops the certificate revocation list if one of its CRLs expired, and returns whether it did.
+func (sc *SecretCache) dropExpiredCRL(now time.Time) bool {
+	sc.rootCertMutex.Lock()
+	defer sc.rootCertMutex.Unlock()
+	for rest := sc.crl; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			return false
+		}
+		crl, err := x509.ParseCRL(block.Bytes)
+		if err != nil || now.After(crl.TBSCertList.NextUpdate) {
+			cacheLog.Errorf("dropping the certificate revocation list, which could not be refreshed before it expired")
+			sc.crl = nil
+			return true
+		}
+	}
+}
+
 func (sc *SecretCache) shouldRefresh(s *model.SecretItem) bool {
 	// secret should be refreshed before it expired, SecretRefreshGraceDuration is the grace period;
 	return time.Now().After(s.ExpireTime.Add(-sc.configOptions.SecretRefreshGraceDuration))
@@ -17,11 +17,14 @@ package cache
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"reflect"
 	"strconv"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -36,6 +39,7 @@ import (
 	"istio.io/istio/security/pkg/nodeagent/model"
 	"istio.io/istio/security/pkg/nodeagent/secretfetcher"
 	nodeagentutil "istio.io/istio/security/pkg/nodeagent/util"
+	"istio.io/istio/security/pkg/pki/util"
 )
 
 var (
@@ -320,6 +324,87 @@ func testWorkloadAgentGenerateSecret(t *testing.T, isUsingPluginProvider bool) {
 	}
 }
 
+func TestWorkloadAgentCRL(t *testing.T) {
+	fakeCACli := mock.NewMockCAClient(mockCertChain1st, mockCertChainRemain)
+	fakeCACli.SetCRL([]byte("crl1"))
+	opt := Options{
+		SecretTTL:        time.Hour,
+		RotationInterval: 100 * time.Millisecond,
+		EvictionDuration: time.Hour,
+		InitialBackoff:   10,
+		SkipValidateCert: true,
+		EnableCRL:        true,
+	}
+	fetcher := &secretfetcher.SecretFetcher{
+		UseCaClient: true,
+		CaClient:    fakeCACli,
+	}
+	sc := NewSecretCache(fetcher, notifyCb, opt)
+	defer sc.Close()
+
+	conID := "proxy1-id"
+	ctx := context.Background()
+	if _, err := sc.GenerateSecret(ctx, conID, testResourceName, "jwtToken1"); err != nil {
+		t.Fatalf("Failed to get secrets: %v", err)
+	}
+	gotSecretRoot, err := sc.GenerateSecret(ctx, conID, RootCertReqResourceName, "jwtToken1")
+	if err != nil {
+		t.Fatalf("Failed to get secrets: %v", err)
+	}
+	if got, want := gotSecretRoot.CRL, []byte("crl1"); !bytes.Equal(got, want) {
+		t.Errorf("CRL: got: %s, want: %s", got, want)
+	}
+
+	// The updated CRL is pushed with the root cert on the next rotation.
+	fakeCACli.SetCRL([]byte("crl2"))
+	key := ConnKey{ConnectionID: conID, ResourceName: RootCertReqResourceName}
+	var got []byte
+	for retries := 0; retries < 20; retries++ {
+		time.Sleep(100 * time.Millisecond)
+		if v, found := sc.secrets.Load(key); found {
+			if got = v.(model.SecretItem).CRL; bytes.Equal(got, []byte("crl2")) {
+				break
+			}
+		}
+	}
+	if !bytes.Equal(got, []byte("crl2")) {
+		t.Errorf("CRL after rotation: got: %s, want: crl2", got)
+	}
+	if atomic.LoadUint64(&sc.rootCertChangedCount) == 0 {
+		t.Error("Expected the root cert to be pushed again after the CRL changed")
+	}
+}
+
+func TestDropExpiredCRL(t *testing.T) {
+	certPEM, keyPEM, err := util.GenCertKeyFromOptions(util.CertOptions{
+		TTL: time.Hour, Org: "Root CA", IsCA: true, IsSelfSigned: true, RSAKeySize: 2048})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := util.ParsePemEncodedCertificate(certPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := util.ParsePemEncodedKey(keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	der, err := cert.CreateCRL(rand.Reader, key, nil, now.Add(-time.Minute), now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	crl := pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der})
+
+	sc := &SecretCache{rootCertMutex: &sync.Mutex{}, crl: crl}
+	if sc.dropExpiredCRL(now) || !bytes.Equal(sc.crl, crl) {
+		t.Error("Unexpected drop of a valid CRL")
+	}
+	if !sc.dropExpiredCRL(now.Add(2*time.Hour)) || sc.crl != nil {
+		t.Error("Expected an expired CRL to be dropped")
+	}
+}
+
 func TestWorkloadAgentRefreshSecret(t *testing.T) {
 	fakeCACli := mock.NewMockCAClient(mockCertChain1st, mockCertChainRemain)
 	opt := Options{
@@ -382,6 +467,76 @@ func TestWorkloadAgentRefreshSecret(t *testing.T) {
 	}
 }
 
+// TestWorkloadAgentForceRotate verifies that the rotation of the workload secrets can be forced before they expire.
+func TestWorkloadAgentForceRotate(t *testing.T) {
+	fakeCACli := mock.NewMockCAClient(mockCertChain1st, mockCertChainRemain)
+	opt := Options{
+		SecretTTL:        time.Hour,
+		RotationInterval: time.Hour,
+		EvictionDuration: time.Hour,
+		InitialBackoff:   10,
+		SkipValidateCert: true,
+	}
+	fetcher := &secretfetcher.SecretFetcher{
+		UseCaClient: true,
+		CaClient:    fakeCACli,
+	}
+	sc := NewSecretCache(fetcher, notifyCb, opt)
+	atomic.StoreUint32(&sc.skipTokenExpireCheck, 0)
+	defer func() {
+		sc.Close()
+		atomic.StoreUint32(&sc.skipTokenExpireCheck, 1)
+	}()
+
+	for i := 0; i < 3; i++ {
+		id := "proxy-id" + strconv.Itoa(i)
+		if _, err := sc.GenerateSecret(context.Background(), id, testResourceName, "jwtToken1"); err != nil {
+			t.Fatalf("Failed to get secrets for %q: %v", id, err)
+		}
+		if _, err := sc.GenerateSecret(context.Background(), id, RootCertReqResourceName, "jwtToken1"); err != nil {
+			t.Fatalf("Failed to get root cert for %q: %v", id, err)
+		}
+	}
+	if _, err := sc.GenerateSecret(context.Background(), "proxy-id0", "other", "jwtToken1"); err != nil {
+		t.Fatalf("Failed to get secrets for resource other: %v", err)
+	}
+	key := ConnKey{ConnectionID: "proxy-id0", ResourceName: testResourceName}
+	val, _ := sc.secrets.Load(key)
+	before := val.(model.SecretItem)
+
+	testCases := []struct {
+		resourceName string
+		rotated      int
+		err          bool
+	}{
+		{resourceName: "other", rotated: 1},
+		{resourceName: testResourceName, rotated: 3},
+		{resourceName: "", rotated: 4},
+		{resourceName: "unknown", rotated: 0},
+		{resourceName: RootCertReqResourceName, err: true},
+	}
+	for _, tc := range testCases {
+		rotated, err := sc.ForceRotate(tc.resourceName)
+		if tc.err != (err != nil) {
+			t.Errorf("ForceRotate(%q) got error %v", tc.resourceName, err)
+		}
+		if rotated != tc.rotated {
+			t.Errorf("ForceRotate(%q) rotated %d secrets, expected %d", tc.resourceName, rotated, tc.rotated)
+		}
+	}
+
+	val, _ = sc.secrets.Load(key)
+	if after := val.(model.SecretItem); !after.CreatedTime.After(before.CreatedTime) {
+		t.Errorf("Secret of %+v is not rotated, created at %v", key, after.CreatedTime)
+	}
+
+	gatewayCache := NewSecretCache(&secretfetcher.SecretFetcher{UseCaClient: false}, notifyCb, opt)
+	defer gatewayCache.Close()
+	if _, err := gatewayCache.ForceRotate(""); err == nil {
+		t.Error("ForceRotate() of kubernetes secrets succeeded, expected an error")
+	}
+}
+
 // TestGatewayAgentGenerateSecret verifies that ingress gateway agent manages secret cache correctly.
 func TestGatewayAgentGenerateSecret(t *testing.T) {
 	sc := createSecretCache()
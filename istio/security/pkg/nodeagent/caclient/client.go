@@ -44,14 +44,14 @@ type configMap interface {
 	GetCATLSRootCert() (string, error)
 }
 
-// NewCAClient create an CA client.
-func NewCAClient(endpoint, caProviderName string, tlsFlag bool, tlsRootCert []byte, vaultAddr, vaultRole,
-	vaultAuthPath, vaultSignCsrPath string) (caClientInterface.Client, error) {
+// NewCAClient create an CA client. vaultConfig is only used by the Vault CA provider.
+func NewCAClient(endpoint, caProviderName string, tlsFlag bool, tlsRootCert []byte,
+	vaultConfig vault.Config) (caClientInterface.Client, error) {
 	switch caProviderName {
 	case googleCAName:
 		return gca.NewGoogleCAClient(endpoint, tlsFlag)
 	case vaultCAName:
-		return vault.NewVaultClient(tlsFlag, tlsRootCert, vaultAddr, vaultRole, vaultAuthPath, vaultSignCsrPath)
+		return vault.NewVaultClientWithConfig(tlsFlag, tlsRootCert, vaultConfig)
 	case citadelName:
 		cs, err := kube.CreateClientset("", "")
 		if err != nil {
@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"testing"
 	"time"
+
+	vault "istio.io/istio/security/pkg/nodeagent/caclient/providers/vault"
 )
 
 type mockConfigMap struct {
@@ -55,7 +57,7 @@ func TestNewCAClient(t *testing.T) {
 	}
 
 	for id, tc := range testCases {
-		_, err := NewCAClient("abc:0", tc.provider, false, nil, "", "", "", "")
+		_, err := NewCAClient("abc:0", tc.provider, false, nil, vault.Config{})
 		if tc.expectedErr == "" {
 			if err != nil {
 				t.Errorf("Test case [%s]: Expect no error, got %q",
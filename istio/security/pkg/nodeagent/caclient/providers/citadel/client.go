@@ -45,6 +45,7 @@ type citadelClient struct {
 	enableTLS     bool
 	caTLSRootCert []byte
 	client        pb.IstioCertificateServiceClient
+	crlClient     pb.IstioCertificateRevocationServiceClient
 }
 
 // NewCitadelClient create a CA client for Citadel.
@@ -75,6 +76,7 @@ func NewCitadelClient(endpoint string, tls bool, rootCert []byte) (caClientInter
 	}
 
 	c.client = pb.NewIstioCertificateServiceClient(conn)
+	c.crlClient = pb.NewIstioCertificateRevocationServiceClient(conn)
 	return c, nil
 }
 
@@ -103,6 +105,17 @@ func (c *citadelClient) CSRSign(ctx context.Context, csrPEM []byte, token string
 	return resp.CertChain, nil
 }
 
+// FetchCRL calls Citadel to get its certificate revocation list.
+func (c *citadelClient) FetchCRL(ctx context.Context, token string) ([]byte, error) {
+	ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs("Authorization", bearerTokenPrefix+token))
+	resp, err := c.crlClient.GetCertificateRevocationList(ctx, &pb.CertificateRevocationListRequest{})
+	if err != nil {
+		citadelClientLog.Errorf("Failed to get the certificate revocation list: %v", err)
+		return nil, err
+	}
+	return []byte(resp.Crl), nil
+}
+
 func (c *citadelClient) getTLSDialOption() (grpc.DialOption, error) {
 	// Load the TLS root certificate from the specified file.
 	// Create a certificate pool
@@ -24,6 +24,7 @@ import (
 
 	"google.golang.org/grpc"
 
+	caClientInterface "istio.io/istio/security/pkg/nodeagent/caclient/interface"
 	pb "istio.io/istio/security/proto"
 )
 
@@ -107,3 +108,68 @@ func TestCitadelClient(t *testing.T) {
 		}
 	}
 }
+
+type mockCRLServer struct {
+	CRL string
+	Err error
+}
+
+func (ca *mockCRLServer) RevokeCertificate(ctx context.Context, in *pb.RevokeCertificateRequest) (*pb.RevokeCertificateResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (ca *mockCRLServer) GetCertificateRevocationList(ctx context.Context, in *pb.CertificateRevocationListRequest) (
+	*pb.CertificateRevocationListResponse, error) {
+	if ca.Err == nil {
+		return &pb.CertificateRevocationListResponse{Crl: ca.CRL}, nil
+	}
+	return nil, ca.Err
+}
+
+func TestCitadelClientFetchCRL(t *testing.T) {
+	testCases := map[string]struct {
+		server      mockCRLServer
+		expectedCRL string
+		expectedErr string
+	}{
+		"Valid CRL": {
+			server:      mockCRLServer{CRL: "crl"},
+			expectedCRL: "crl",
+		},
+		"Error in response": {
+			server:      mockCRLServer{Err: fmt.Errorf("test failure")},
+			expectedErr: "rpc error: code = Unknown desc = test failure",
+		},
+	}
+
+	for id, tc := range testCases {
+		s := grpc.NewServer()
+		defer s.Stop()
+		lis, err := net.Listen("tcp", mockServerAddress)
+		if err != nil {
+			t.Fatalf("Test case [%s]: failed to listen: %v", id, err)
+		}
+		pb.RegisterIstioCertificateRevocationServiceServer(s, &tc.server)
+		go func() {
+			if err := s.Serve(lis); err != nil {
+				t.Logf("Test case [%s]: failed to serve: %v", id, err)
+			}
+		}()
+
+		cli, err := NewCitadelClient(lis.Addr().String(), false, nil)
+		if err != nil {
+			t.Fatalf("Test case [%s]: failed to create ca client: %v", id, err)
+		}
+
+		crl, err := cli.(caClientInterface.CRLClient).FetchCRL(context.Background(), "fakeToken")
+		if err != nil {
+			if err.Error() != tc.expectedErr {
+				t.Errorf("Test case [%s]: error (%s) does not match expected error (%s)", id, err.Error(), tc.expectedErr)
+			}
+		} else if tc.expectedErr != "" {
+			t.Errorf("Test case [%s]: expect error: %s but got no error", id, tc.expectedErr)
+		} else if string(crl) != tc.expectedCRL {
+			t.Errorf("Test case [%s]: got CRL %q, expected %q", id, crl, tc.expectedCRL)
+		}
+	}
+}
@@ -15,23 +15,106 @@
 package caclient
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"github.com/hashicorp/vault/api"
 
+	"istio.io/istio/pkg/spiffe"
 	caClientInterface "istio.io/istio/security/pkg/nodeagent/caclient/interface"
+	"istio.io/istio/security/pkg/pki/util"
 	"istio.io/pkg/log"
 )
 
+const (
+	// AuthMethodKubernetes logs in with the Kubernetes service account token of the workload.
+	AuthMethodKubernetes = "kubernetes"
+	// AuthMethodAppRole logs in with an AppRole role ID and secret ID.
+	AuthMethodAppRole = "approle"
+	// AuthMethodCert logs in with the TLS client certificate of the node agent.
+	AuthMethodCert = "cert"
+)
+
 var (
 	vaultClientLog = log.RegisterScope("vaultClientLog", "Vault client debugging", 0)
 )
 
+// Config configures the Vault CA client.
+type Config struct {
+	// Addr is the address of the Vault server.
+	Addr string
+	// AuthMethod is the auth method used to get the Vault tokens, one of "kubernetes" (the default), "approle"
+	// and "cert".
+	AuthMethod string
+	// LoginPath is the login path of the auth method, e.g. "auth/kubernetes/login".
+	LoginPath string
+	// LoginRole is the role to log in with, for the Kubernetes and cert auth methods.
+	LoginRole string
+	// AppRoleID and AppRoleSecretID are the credentials of the AppRole auth method.
+	AppRoleID       string
+	AppRoleSecretID string
+	// ClientCertFile and ClientKeyFile are the TLS client certificate and key used by the cert auth method.
+	ClientCertFile string
+	ClientKeyFile  string
+	// SignCsrPath is the path signing the CSRs. It is a Go template receiving the Namespace, ServiceAccount
+	// and TrustDomain of the workload, from the SPIFFE ID of the CSR once verified against the service account
+	// token and the trust domain of the mesh, to map them to different PKI roles, e.g. "pki/sign/{{.Namespace}}".
+	SignCsrPath string
+	// CAChainPath is the path to read the CA certificate chain from, e.g. "pki/cert/ca_chain". When set, the
+	// root certificate of the chain, which must be self-signed, completes the signed certificate chain if Vault
+	// did not return it.
+	CAChainPath string
+	// TokenReviewer verifies the service account tokens of the workloads. It is required by the AppRole and cert
+	// auth methods, which log in as the node agent, to verify the identity of a workload before signing its CSR.
+	// With the Kubernetes auth method, Vault verifies the token of the workload when logging in.
+	TokenReviewer TokenReviewer
+}
+
+// TokenReviewer verifies a Kubernetes service account token and returns its namespace and service account name,
+// e.g. through the Kubernetes TokenReview API.
+type TokenReviewer interface {
+	ValidateK8sJwt(token string) ([]string, error)
+}
+
+// signPathParams are the parameters of the SignCsrPath template.
+type signPathParams struct {
+	Namespace      string
+	ServiceAccount string
+	TrustDomain    string
+}
+
+// workloadIdentity is the verified Kubernetes service account of a workload.
+type workloadIdentity struct {
+	namespace      string
+	serviceAccount string
+}
+
+// vaultToken is a Vault client token and its lease.
+type vaultToken struct {
+	token     string
+	renewable bool
+	// renewAt is when the token is renewed, at half of its lease.
+	renewAt  time.Time
+	expireAt time.Time
+	// identity is the service account the token was issued to by the Kubernetes auth method.
+	identity *workloadIdentity
+}
+
+// caChainRefreshInterval is how often the CA chain is read again from Vault.
+const caChainRefreshInterval = 10 * time.Minute
+
 type vaultClient struct {
 	enableTLS   bool
 	tlsRootCert []byte
@@ -41,50 +124,141 @@ type vaultClient struct {
 	vaultLoginPath   string
 	vaultSignCsrPath string
 
+	config       Config
+	signPathTmpl *template.Template
+
+	// tokensMutex guards tokens, which caches the Vault tokens by hash of the service account token for the
+	// Kubernetes auth method, or under the empty key for the other auth methods.
+	tokensMutex sync.Mutex
+	tokens      map[string]*vaultToken
+	now         func() time.Time
+
+	// caChainMutex guards caChain, the CA chain read from the CA chain path, and when it was read.
+	caChainMutex  sync.Mutex
+	caChain       []string
+	caChainReadAt time.Time
+
 	client *api.Client
 }
 
 // NewVaultClient create a CA client for the Vault provider 1.
 func NewVaultClient(tls bool, tlsRootCert []byte,
 	vaultAddr, vaultLoginRole, vaultLoginPath, vaultSignCsrPath string) (caClientInterface.Client, error) {
+	return NewVaultClientWithConfig(tls, tlsRootCert, Config{
+		Addr:        vaultAddr,
+		AuthMethod:  AuthMethodKubernetes,
+		LoginRole:   vaultLoginRole,
+		LoginPath:   vaultLoginPath,
+		SignCsrPath: vaultSignCsrPath,
+	})
+}
+
+// NewVaultClientWithConfig creates a CA client for the Vault provider with the given config.
+func NewVaultClientWithConfig(tls bool, tlsRootCert []byte, config Config) (caClientInterface.Client, error) {
+	if config.AuthMethod == "" {
+		config.AuthMethod = AuthMethodKubernetes
+	}
+	switch config.AuthMethod {
+	case AuthMethodKubernetes:
+	case AuthMethodAppRole:
+		if config.AppRoleID == "" || config.AppRoleSecretID == "" {
+			return nil, fmt.Errorf("the AppRole auth method requires a role ID and a secret ID")
+		}
+		if config.TokenReviewer == nil {
+			return nil, fmt.Errorf("the AppRole auth method requires a token reviewer")
+		}
+	case AuthMethodCert:
+		if !tls || config.ClientCertFile == "" || config.ClientKeyFile == "" {
+			return nil, fmt.Errorf("the cert auth method requires TLS and a client certificate and key")
+		}
+		if config.TokenReviewer == nil {
+			return nil, fmt.Errorf("the cert auth method requires a token reviewer")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported Vault auth method %q, supported methods are %q", config.AuthMethod,
+			strings.Join([]string{AuthMethodKubernetes, AuthMethodAppRole, AuthMethodCert}, ","))
+	}
+
 	c := &vaultClient{
 		enableTLS:        tls,
 		tlsRootCert:      tlsRootCert,
-		vaultAddr:        vaultAddr,
-		vaultLoginRole:   vaultLoginRole,
-		vaultLoginPath:   vaultLoginPath,
-		vaultSignCsrPath: vaultSignCsrPath,
+		vaultAddr:        config.Addr,
+		vaultLoginRole:   config.LoginRole,
+		vaultLoginPath:   config.LoginPath,
+		vaultSignCsrPath: config.SignCsrPath,
+		config:           config,
+		tokens:           map[string]*vaultToken{},
+		now:              time.Now,
+	}
+	if strings.Contains(config.SignCsrPath, "{{") {
+		tmpl, err := template.New("signCsrPath").Option("missingkey=error").Parse(config.SignCsrPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sign CSR path template %q: %v", config.SignCsrPath, err)
+		}
+		c.signPathTmpl = tmpl
 	}
 
 	var client *api.Client
 	var err error
 	if tls {
-		client, err = createVaultTLSClient(vaultAddr, tlsRootCert)
+		client, err = createVaultTLSClient(config.Addr, tlsRootCert, config.ClientCertFile, config.ClientKeyFile)
 	} else {
-		client, err = createVaultClient(vaultAddr)
+		client, err = createVaultClient(config.Addr)
 	}
 	if err != nil {
 		return nil, err
 	}
 	c.client = client
-	vaultClientLog.Infof("created Vault client for Vault address: %s, TLS: %v", vaultAddr, tls)
+	vaultClientLog.Infof("created Vault client for Vault address: %s, TLS: %v, auth method: %s",
+		config.Addr, tls, config.AuthMethod)
 
 	return c, nil
 }
 
-// CSR Sign calls Vault to sign a CSR.
+// CSR Sign calls Vault to sign a CSR, after verifying that it only requests the identity of the service
+// account token.
 func (c *vaultClient) CSRSign(ctx context.Context, csrPEM []byte, saToken string,
 	certValidTTLInSec int64) ([]string /*PEM-encoded certificate chain*/, error) {
-	token, err := loginVaultK8sAuthMethod(c.client, c.vaultLoginPath, c.vaultLoginRole, saToken)
+	token, identity, err := c.getToken(saToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to login Vault at %s: %v", c.vaultAddr, err)
 	}
-	c.client.SetToken(token)
-	certChain, err := signCsrByVault(c.client, c.vaultSignCsrPath, certValidTTLInSec, csrPEM)
+	if identity == nil {
+		if identity, err = c.reviewToken(saToken); err != nil {
+			return nil, fmt.Errorf("failed to verify the service account token: %v", err)
+		}
+	}
+	params, err := checkCSRIdentity(csrPEM, identity, spiffe.GetTrustDomain())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign CSR: %v", err)
+	}
+	signPath, err := c.signPath(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign CSR: %v", err)
+	}
+
+	// The client is shared by the concurrent requests, which use different tokens.
+	client, err := c.client.Clone()
+	if err != nil {
+		return nil, err
+	}
+	client.SetToken(token)
+	certChain, err := signCsrByVault(client, signPath, certValidTTLInSec, csrPEM)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign CSR: %v", err)
 	}
 
+	if c.config.CAChainPath != "" {
+		caChain, err := c.getCAChain(client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the CA chain: %v", err)
+		}
+		root := caChain[len(caChain)-1]
+		if strings.TrimSpace(certChain[len(certChain)-1]) != strings.TrimSpace(root) {
+			certChain = append(certChain, root)
+		}
+	}
+
 	if len(certChain) <= 1 {
 		vaultClientLog.Errorf("certificate chain length is %d, expected more than 1", len(certChain))
 		return nil, fmt.Errorf("invalid certificate chain in the response")
@@ -93,6 +267,218 @@ func (c *vaultClient) CSRSign(ctx context.Context, csrPEM []byte, saToken string
 	return certChain, nil
 }
 
+// reviewToken verifies the service account token with the token reviewer.
+func (c *vaultClient) reviewToken(saToken string) (*workloadIdentity, error) {
+	if c.config.TokenReviewer == nil {
+		return nil, fmt.Errorf("no token reviewer")
+	}
+	id, err := c.config.TokenReviewer.ValidateK8sJwt(saToken)
+	if err != nil {
+		return nil, err
+	}
+	if len(id) != 2 {
+		return nil, fmt.Errorf("unexpected token review result %v", id)
+	}
+	return &workloadIdentity{namespace: id[0], serviceAccount: id[1]}, nil
+}
+
+// checkCSRIdentity verifies that the CSR only requests the SPIFFE ID of the service account in the trust domain,
+// and returns the parameters of the sign CSR path template.
+func checkCSRIdentity(csrPEM []byte, identity *workloadIdentity, trustDomain string) (*signPathParams, error) {
+	csr, err := util.ParsePemEncodedCSR(csrPEM)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := util.ExtractIDs(csr.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no identity in the CSR")
+	}
+	var params *signPathParams
+	for _, id := range ids {
+		p := parseSpiffeID(id)
+		if p == nil || p.Namespace != identity.namespace || p.ServiceAccount != identity.serviceAccount {
+			return nil, fmt.Errorf("the CSR requests identity %q, the caller is service account %s/%s", id,
+				identity.namespace, identity.serviceAccount)
+		}
+		// The trust domain selects the sign CSR path too, so the workload must not pick it.
+		if p.TrustDomain != trustDomain {
+			return nil, fmt.Errorf("the CSR requests identity %q, the trust domain is %q", id, trustDomain)
+		}
+		params = p
+	}
+	return params, nil
+}
+
+// signPath returns the path signing the CSR, rendering the sign CSR path template with the verified identity.
+func (c *vaultClient) signPath(params *signPathParams) (string, error) {
+	if c.signPathTmpl == nil {
+		return c.vaultSignCsrPath, nil
+	}
+	var path strings.Builder
+	if err := c.signPathTmpl.Execute(&path, params); err != nil {
+		return "", fmt.Errorf("failed to render the sign CSR path: %v", err)
+	}
+	return path.String(), nil
+}
+
+// parseSpiffeID returns the parameters of a SPIFFE ID in the spiffe://<trust domain>/ns/<namespace>/sa/<sa>
+// format, or nil for other IDs.
+func parseSpiffeID(id string) *signPathParams {
+	if !strings.HasPrefix(id, spiffe.URIPrefix) {
+		return nil
+	}
+	parts := strings.Split(strings.TrimPrefix(id, spiffe.URIPrefix), "/")
+	if len(parts) != 5 || parts[1] != "ns" || parts[3] != "sa" || parts[2] == "" || parts[4] == "" {
+		return nil
+	}
+	return &signPathParams{TrustDomain: parts[0], Namespace: parts[2], ServiceAccount: parts[4]}
+}
+
+// getCAChain returns the CA chain, read again from Vault every caChainRefreshInterval. The cached chain is
+// used if it can't be read.
+func (c *vaultClient) getCAChain(client *api.Client) ([]string, error) {
+	c.caChainMutex.Lock()
+	defer c.caChainMutex.Unlock()
+
+	now := c.now()
+	if c.caChain != nil && now.Before(c.caChainReadAt.Add(caChainRefreshInterval)) {
+		return c.caChain, nil
+	}
+	caChain, err := readCAChainFromVault(client, c.config.CAChainPath)
+	if err != nil {
+		if c.caChain == nil {
+			return nil, err
+		}
+		vaultClientLog.Warnf("failed to read the CA chain, using the cached one: %v", err)
+		return c.caChain, nil
+	}
+	c.caChain = caChain
+	c.caChainReadAt = now
+	return caChain, nil
+}
+
+// getToken returns a valid Vault token for the service account token, renewing or replacing the cached one
+// when it reaches half of its lease, and the identity of the service account if verified by the Kubernetes
+// auth method.
+func (c *vaultClient) getToken(saToken string) (string, *workloadIdentity, error) {
+	key := ""
+	if c.config.AuthMethod == AuthMethodKubernetes {
+		sum := sha256.Sum256([]byte(saToken))
+		key = hex.EncodeToString(sum[:])
+	}
+	now := c.now()
+
+	c.tokensMutex.Lock()
+	c.pruneTokens(now)
+	t, found := c.tokens[key]
+	c.tokensMutex.Unlock()
+
+	if found {
+		if now.Before(t.renewAt) {
+			return t.token, t.identity, nil
+		}
+		if t.renewable {
+			renewed, err := c.renewToken(t.token)
+			if err == nil {
+				c.cacheToken(key, newVaultToken(renewed, now, t.identity))
+				vaultClientLog.Debugf("renewed Vault token, lease %v", renewed.LeaseDuration)
+				return renewed.ClientToken, t.identity, nil
+			}
+			vaultClientLog.Warnf("failed to renew Vault token, logging in again: %v", err)
+		}
+		c.tokensMutex.Lock()
+		delete(c.tokens, key)
+		c.tokensMutex.Unlock()
+	}
+
+	auth, err := c.login(saToken)
+	if err != nil {
+		return "", nil, err
+	}
+	var identity *workloadIdentity
+	if c.config.AuthMethod == AuthMethodKubernetes {
+		if identity, err = k8sAuthIdentity(auth); err != nil {
+			return "", nil, err
+		}
+	}
+	// Tokens without lease, e.g. root tokens, are not cached.
+	if auth.LeaseDuration > 0 {
+		c.cacheToken(key, newVaultToken(auth, now, identity))
+	}
+	return auth.ClientToken, identity, nil
+}
+
+// cacheToken caches the Vault token under the key.
+func (c *vaultClient) cacheToken(key string, t *vaultToken) {
+	c.tokensMutex.Lock()
+	defer c.tokensMutex.Unlock()
+	c.tokens[key] = t
+}
+
+// k8sAuthIdentity returns the service account verified by the Kubernetes auth method, from the metadata of
+// its login response.
+func k8sAuthIdentity(auth *api.SecretAuth) (*workloadIdentity, error) {
+	identity := &workloadIdentity{
+		namespace:      auth.Metadata["service_account_namespace"],
+		serviceAccount: auth.Metadata["service_account_name"],
+	}
+	if identity.namespace == "" || identity.serviceAccount == "" {
+		return nil, fmt.Errorf("no service account in the metadata of the login response")
+	}
+	return identity, nil
+}
+
+// pruneTokens removes the expired tokens from the cache. The caller must hold the tokens mutex.
+func (c *vaultClient) pruneTokens(now time.Time) {
+	for key, t := range c.tokens {
+		if !now.Before(t.expireAt) {
+			delete(c.tokens, key)
+		}
+	}
+}
+
+func newVaultToken(auth *api.SecretAuth, now time.Time, identity *workloadIdentity) *vaultToken {
+	lease := time.Duration(auth.LeaseDuration) * time.Second
+	return &vaultToken{
+		token:     auth.ClientToken,
+		renewable: auth.Renewable,
+		renewAt:   now.Add(lease / 2),
+		expireAt:  now.Add(lease),
+		identity:  identity,
+	}
+}
+
+// renewToken renews the Vault token with a client of its own.
+func (c *vaultClient) renewToken(token string) (*api.SecretAuth, error) {
+	client, err := c.client.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return renewVaultToken(client, token)
+}
+
+// login logs into Vault with the configured auth method.
+func (c *vaultClient) login(saToken string) (*api.SecretAuth, error) {
+	switch c.config.AuthMethod {
+	case AuthMethodAppRole:
+		return loginVault(c.client, c.vaultLoginPath, map[string]interface{}{
+			"role_id":   c.config.AppRoleID,
+			"secret_id": c.config.AppRoleSecretID,
+		})
+	case AuthMethodCert:
+		body := map[string]interface{}{}
+		if c.vaultLoginRole != "" {
+			body["name"] = c.vaultLoginRole
+		}
+		return loginVault(c.client, c.vaultLoginPath, body)
+	default:
+		return loginVaultK8sAuthMethod(c.client, c.vaultLoginPath, c.vaultLoginRole, saToken)
+	}
+}
+
 // createVaultClient creates a client to a Vault server
 // vaultAddr: the address of the Vault server (e.g., "http://127.0.0.1:8200").
 func createVaultClient(vaultAddr string) (*api.Client, error) {
@@ -110,7 +496,9 @@ func createVaultClient(vaultAddr string) (*api.Client, error) {
 
 // createVaultTLSClient creates a client to a Vault server
 // vaultAddr: the address of the Vault server (e.g., "https://127.0.0.1:8200").
-func createVaultTLSClient(vaultAddr string, tlsRootCert []byte) (*api.Client, error) {
+// clientCertFile, clientKeyFile: the optional TLS client certificate and key, for the cert auth method.
+func createVaultTLSClient(vaultAddr string, tlsRootCert []byte, clientCertFile, clientKeyFile string) (*api.Client,
+	error) {
 	// Load the system default root certificates.
 	pool, err := x509.SystemCertPool()
 	if err != nil {
@@ -130,6 +518,13 @@ func createVaultTLSClient(vaultAddr string, tlsRootCert []byte) (*api.Client, er
 	tlsConfig := &tls.Config{
 		RootCAs: pool,
 	}
+	if clientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(clientCertFile, clientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the Vault client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
 
 	transport := &http.Transport{TLSClientConfig: tlsConfig}
 	httpClient := &http.Client{Transport: transport}
@@ -148,31 +543,48 @@ func createVaultTLSClient(vaultAddr string, tlsRootCert []byte) (*api.Client, er
 }
 
 // loginVaultK8sAuthMethod logs into the Vault k8s auth method with the service account and
-// returns the auth client token.
+// returns the auth client token and its lease.
 // loginPath: the path of the login
 // role: the login role
 // jwt: the service account used for login
-func loginVaultK8sAuthMethod(client *api.Client, loginPath, role, sa string) (string, error) {
-	resp, err := client.Logical().Write(
-		loginPath,
-		map[string]interface{}{
-			"jwt":  sa,
-			"role": role,
-		})
+func loginVaultK8sAuthMethod(client *api.Client, loginPath, role, sa string) (*api.SecretAuth, error) {
+	return loginVault(client, loginPath, map[string]interface{}{
+		"jwt":  sa,
+		"role": role,
+	})
+}
+
+// loginVault posts the login request to the login path of an auth method and returns the auth client token
+// and its lease.
+func loginVault(client *api.Client, loginPath string, body map[string]interface{}) (*api.SecretAuth, error) {
+	resp, err := client.Logical().Write(loginPath, body)
 
 	if err != nil {
 		vaultClientLog.Errorf("failed to login Vault: %v", err)
-		return "", err
+		return nil, err
 	}
 	if resp == nil {
 		vaultClientLog.Errorf("login response is nil")
-		return "", fmt.Errorf("login response is nil")
+		return nil, fmt.Errorf("login response is nil")
 	}
 	if resp.Auth == nil {
 		vaultClientLog.Errorf("login response auth field is nil")
-		return "", fmt.Errorf("login response auth field is nil")
+		return nil, fmt.Errorf("login response auth field is nil")
+	}
+	return resp.Auth, nil
+}
+
+// renewVaultToken renews the token and returns its new lease.
+func renewVaultToken(client *api.Client, token string) (*api.SecretAuth, error) {
+	client.SetToken(token)
+	resp, err := client.Auth().Token().RenewSelf(0)
+	if err != nil {
+		return nil, err
 	}
-	return resp.Auth.ClientToken, nil
+	if resp == nil || resp.Auth == nil {
+		return nil, fmt.Errorf("renew response auth field is nil")
+	}
+	return resp.Auth, nil
 }
 
 // signCsrByVault signs the CSR and return the signed certificate and the CA certificate chain
@@ -234,3 +646,42 @@ func signCsrByVault(client *api.Client, csrSigningPath string, certTTLInSec int6
 
 	return certChain, nil
 }
+
+// readCAChainFromVault reads the PEM-encoded CA certificate chain at the path, and returns its certificates,
+// the root certificate last. The last certificate must be self-signed.
+// caChainPath: the path of the CA chain, e.g. "pki/cert/ca_chain"
+func readCAChainFromVault(client *api.Client, caChainPath string) ([]string, error) {
+	res, err := client.Logical().Read(caChainPath)
+	if err != nil {
+		vaultClientLog.Errorf("failed to read %v: %v", caChainPath, err)
+		return nil, fmt.Errorf("failed to read %v: %v", caChainPath, err)
+	}
+	if res == nil || res.Data == nil {
+		return nil, fmt.Errorf("CA chain response has a nil Data field")
+	}
+	chain, ok := res.Data["certificate"].(string)
+	if !ok {
+		return nil, fmt.Errorf("no certificate in the CA chain response")
+	}
+	var certs []string
+	rest := []byte(chain)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		certs = append(certs, string(pem.EncodeToMemory(block)))
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no PEM certificate in the CA chain response")
+	}
+	root, err := util.ParsePemEncodedCertificate([]byte(certs[len(certs)-1]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid root certificate in the CA chain: %v", err)
+	}
+	if !bytes.Equal(root.RawIssuer, root.RawSubject) || root.CheckSignatureFrom(root) != nil {
+		return nil, fmt.Errorf("the last certificate of the CA chain is not a self-signed root certificate")
+	}
+	return certs, nil
+}
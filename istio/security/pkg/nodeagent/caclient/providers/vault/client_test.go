@@ -16,24 +16,37 @@ package caclient
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"encoding/pem"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"istio.io/istio/security/pkg/pki/util"
 )
 
 // vaultAuthHeaderName is the name of the header containing the token.
 const vaultAuthHeaderName = "X-Vault-Token"
 
+// testSpiffeID is the identity of the fake-client-token service account token.
+const testSpiffeID = "spiffe://cluster.local/ns/foo/sa/bar"
+
 var (
 	vaultLoginResp = `
 	{
 	  "auth": {
-		  "client_token": "fake-vault-token" 
+		  "client_token": "fake-vault-token",
+		  "metadata": {"service_account_namespace": "foo", "service_account_name": "bar"}
 	  }
 	}
   `
@@ -44,6 +57,26 @@ var (
 		  "ca_chain": ["fake-ca1", "fake-ca2"] 
 	  }
 	}
+  `
+	vaultRenewableLoginResp = `
+	{
+	  "auth": {
+		  "client_token": "fake-vault-token",
+		  "lease_duration": 3600,
+		  "renewable": true,
+		  "metadata": {"service_account_namespace": "foo", "service_account_name": "bar"}
+	  }
+	}
+  `
+	vaultNonRenewableLoginResp = `
+	{
+	  "auth": {
+		  "client_token": "fake-vault-token",
+		  "lease_duration": 3600,
+		  "renewable": false,
+		  "metadata": {"service_account_namespace": "foo", "service_account_name": "bar"}
+	  }
+	}
   `
 	fakeCert = []string{"fake-certificate\n", "fake-ca1\n", "fake-ca2\n"}
 )
@@ -54,6 +87,25 @@ type mockVaultServer struct {
 	token          string
 	vaultLoginResp string
 	vaultSignResp  string
+
+	// appRoleID and appRoleSecretID are the credentials accepted on /v1/auth/approle/login.
+	appRoleID       string
+	appRoleSecretID string
+	// caChainResp is the response to /v1/ca_chain.
+	caChainResp string
+	// signPaths are the additional sign paths, e.g. "/v1/sign/foo-bar".
+	signPaths map[string]bool
+
+	mutex        sync.Mutex
+	loginCount   int
+	renewCount   int
+	caChainCount int
+}
+
+func (s *mockVaultServer) counts() (login, renew int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.loginCount, s.renewCount
 }
 
 type clientConfig struct {
@@ -72,11 +124,41 @@ type loginRequest struct {
 	Role string `json:"role"`
 }
 
+type appRoleLoginRequest struct {
+	RoleID   string `json:"role_id"`
+	SecretID string `json:"secret_id"`
+}
+
 type signRequest struct {
 	Format string `json:"format"`
 	Csr    string `json:"csr"`
 }
 
+// fakeTokenReviewer verifies the service account tokens in ids, which are mapped to their namespace and service
+// account name.
+type fakeTokenReviewer struct {
+	ids map[string][]string
+}
+
+func (r *fakeTokenReviewer) ValidateK8sJwt(token string) ([]string, error) {
+	if id, ok := r.ids[token]; ok {
+		return id, nil
+	}
+	return nil, fmt.Errorf("invalid token")
+}
+
+var testTokenReviewer = &fakeTokenReviewer{ids: map[string][]string{"fake-client-token": {"foo", "bar"}}}
+
+// genTestCSR generates a CSR for the given hosts, without SAN if hosts is empty.
+func genTestCSR(t *testing.T, hosts string) []byte {
+	t.Helper()
+	csrPEM, _, err := util.GenCSR(util.CertOptions{Host: hosts, RSAKeySize: 2048})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return csrPEM
+}
+
 func TestClientOnMockVaultCA(t *testing.T) {
 	testCases := map[string]struct {
 		cliConfig    clientConfig
@@ -85,38 +167,38 @@ func TestClientOnMockVaultCA(t *testing.T) {
 	}{
 		"Valid certs 1": {
 			cliConfig: clientConfig{tls: false, tlsCert: []byte{}, vaultLoginPath: "login",
-				vaultSignCsrPath: "sign", clientToken: "fake-client-token", csr: []byte{01}},
+				vaultSignCsrPath: "sign", clientToken: "fake-client-token"},
 			expectedCert: fakeCert,
 			expectedErr:  "",
 		},
 		"Valid certs 1 (TLS)": {
 			cliConfig: clientConfig{tls: true, vaultLoginPath: "login", vaultSignCsrPath: "sign",
-				clientToken: "fake-client-token", csr: []byte{01}},
+				clientToken: "fake-client-token"},
 			expectedCert: fakeCert,
 			expectedErr:  "",
 		},
 		"Wrong Vault addr": {
 			cliConfig: clientConfig{tls: false, tlsCert: []byte{}, vaultAddr: "wrong-vault-addr",
 				vaultLoginPath: "login", vaultSignCsrPath: "wrong-sign-path",
-				clientToken: "fake-client-token", csr: []byte{01}},
+				clientToken: "fake-client-token"},
 			expectedCert: nil,
 			expectedErr:  "failed to login Vault",
 		},
 		"Wrong login path": {
 			cliConfig: clientConfig{tls: false, tlsCert: []byte{}, vaultLoginPath: "wrong-login-path",
-				vaultSignCsrPath: "sign", clientToken: "fake-client-token", csr: []byte{01}},
+				vaultSignCsrPath: "sign", clientToken: "fake-client-token"},
 			expectedCert: nil,
 			expectedErr:  "failed to login Vault",
 		},
 		"Wrong client token": {
 			cliConfig: clientConfig{tls: false, tlsCert: []byte{}, vaultLoginPath: "login",
-				vaultSignCsrPath: "sign", clientToken: "wrong-client-token", csr: []byte{01}},
+				vaultSignCsrPath: "sign", clientToken: "wrong-client-token"},
 			expectedCert: nil,
 			expectedErr:  "failed to login Vault",
 		},
 		"Wrong sign path": {
 			cliConfig: clientConfig{tls: false, tlsCert: []byte{}, vaultLoginPath: "login",
-				vaultSignCsrPath: "wrong-sign-path", clientToken: "fake-client-token", csr: []byte{01}},
+				vaultSignCsrPath: "wrong-sign-path", clientToken: "fake-client-token"},
 			expectedCert: nil,
 			expectedErr:  "failed to sign CSR",
 		},
@@ -139,7 +221,11 @@ func TestClientOnMockVaultCA(t *testing.T) {
 	s2 := <-ch
 	defer s2.httpServer.Close()
 
+	csrPEM := genTestCSR(t, testSpiffeID)
 	for id, tc := range testCases {
+		if tc.cliConfig.csr == nil {
+			tc.cliConfig.csr = csrPEM
+		}
 		if len(tc.cliConfig.vaultAddr) == 0 {
 			// If the address of Vault is not set by the test case, use that of the test server.
 			if tc.cliConfig.tls {
@@ -176,6 +262,442 @@ func TestClientOnMockVaultCA(t *testing.T) {
 	}
 }
 
+func TestClientTokenCache(t *testing.T) {
+	testCases := map[string]struct {
+		loginResp string
+		// wantLogins and wantRenewals are the expected counts after signing, past half of the lease.
+		wantLogins   int
+		wantRenewals int
+	}{
+		"Renewable token": {
+			loginResp:    vaultRenewableLoginResp,
+			wantLogins:   1,
+			wantRenewals: 1,
+		},
+		"Non-renewable token": {
+			loginResp:  vaultNonRenewableLoginResp,
+			wantLogins: 2,
+		},
+		"Token without lease": {
+			loginResp:  vaultLoginResp,
+			wantLogins: 3,
+		},
+	}
+
+	csrPEM := genTestCSR(t, testSpiffeID)
+	for id, tc := range testCases {
+		t.Run(id, func(t *testing.T) {
+			server := newMockVaultServer(t, false, "", "fake-client-token", tc.loginResp, vaultSignResp)
+			defer server.httpServer.Close()
+
+			cli, err := NewVaultClient(false, nil, server.httpServer.URL, "", "login", "sign")
+			if err != nil {
+				t.Fatalf("failed to create ca client: %v", err)
+			}
+			now := time.Now()
+			cli.(*vaultClient).now = func() time.Time { return now }
+
+			for i := 0; i < 2; i++ {
+				if _, err := cli.CSRSign(context.Background(), csrPEM, "fake-client-token", 1); err != nil {
+					t.Fatalf("CSRSign() failed: %v", err)
+				}
+			}
+			now = now.Add(45 * time.Minute)
+			if _, err := cli.CSRSign(context.Background(), csrPEM, "fake-client-token", 1); err != nil {
+				t.Fatalf("CSRSign() failed: %v", err)
+			}
+			if login, renew := server.counts(); login != tc.wantLogins || renew != tc.wantRenewals {
+				t.Errorf("got %d logins and %d renewals, expected %d and %d", login, renew, tc.wantLogins,
+					tc.wantRenewals)
+			}
+		})
+	}
+}
+
+func TestClientAppRoleAuth(t *testing.T) {
+	server := &mockVaultServer{
+		vaultLoginResp:  vaultRenewableLoginResp,
+		vaultSignResp:   vaultSignResp,
+		appRoleID:       "fake-role-id",
+		appRoleSecretID: "fake-secret-id",
+	}
+	server.start(t, false)
+	defer server.httpServer.Close()
+
+	testCases := map[string]struct {
+		secretID      string
+		saToken       string
+		tokenReviewer TokenReviewer
+		expectedErr   string
+	}{
+		"Valid secret ID": {secretID: "fake-secret-id", saToken: "fake-client-token", tokenReviewer: testTokenReviewer},
+		"Wrong secret ID": {secretID: "wrong-secret-id", saToken: "fake-client-token", tokenReviewer: testTokenReviewer,
+			expectedErr: "failed to login Vault"},
+		"No secret ID": {tokenReviewer: testTokenReviewer, expectedErr: "requires a role ID and a secret ID"},
+		"Unverified service account token": {secretID: "fake-secret-id", saToken: "wrong-client-token",
+			tokenReviewer: testTokenReviewer, expectedErr: "failed to verify the service account token"},
+		"No token reviewer": {secretID: "fake-secret-id", expectedErr: "requires a token reviewer"},
+	}
+	csrPEM := genTestCSR(t, testSpiffeID)
+	for id, tc := range testCases {
+		t.Run(id, func(t *testing.T) {
+			cli, err := NewVaultClientWithConfig(false, nil, Config{
+				Addr:            server.httpServer.URL,
+				AuthMethod:      AuthMethodAppRole,
+				LoginPath:       "auth/approle/login",
+				AppRoleID:       "fake-role-id",
+				AppRoleSecretID: tc.secretID,
+				SignCsrPath:     "sign",
+				TokenReviewer:   tc.tokenReviewer,
+			})
+			if err == nil {
+				// The service account token is only used to verify the identity of the workload.
+				_, err = cli.CSRSign(context.Background(), csrPEM, tc.saToken, 1)
+			}
+			checkError(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestClientCertAuth(t *testing.T) {
+	server := newMockVaultCertAuthServer(t, vaultRenewableLoginResp, vaultSignResp)
+	defer server.httpServer.Close()
+	rootCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.httpServer.Certificate().Raw})
+
+	dir, err := ioutil.TempDir("", "vault-cert-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certPEM, keyPEM, err := util.GenCertKeyFromOptions(util.CertOptions{
+		Host:         "node-agent",
+		TTL:          time.Hour,
+		IsSelfSigned: true,
+		IsClient:     true,
+		RSAKeySize:   2048,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := ioutil.WriteFile(certFile, certPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		tls           bool
+		certFile      string
+		keyFile       string
+		tokenReviewer TokenReviewer
+		expectedErr   string
+	}{
+		"Valid client certificate": {tls: true, certFile: certFile, keyFile: keyFile, tokenReviewer: testTokenReviewer},
+		"No client certificate": {tls: true, tokenReviewer: testTokenReviewer,
+			expectedErr: "requires TLS and a client certificate"},
+		"No TLS": {certFile: certFile, keyFile: keyFile, tokenReviewer: testTokenReviewer,
+			expectedErr: "requires TLS"},
+		"Invalid client key": {tls: true, certFile: certFile, keyFile: certFile, tokenReviewer: testTokenReviewer,
+			expectedErr: "failed to load"},
+		"No token reviewer": {tls: true, certFile: certFile, keyFile: keyFile,
+			expectedErr: "requires a token reviewer"},
+	}
+	csrPEM := genTestCSR(t, testSpiffeID)
+	for id, tc := range testCases {
+		t.Run(id, func(t *testing.T) {
+			cli, err := NewVaultClientWithConfig(tc.tls, rootCert, Config{
+				Addr:           server.httpServer.URL,
+				AuthMethod:     AuthMethodCert,
+				LoginPath:      "auth/cert/login",
+				ClientCertFile: tc.certFile,
+				ClientKeyFile:  tc.keyFile,
+				SignCsrPath:    "sign",
+				TokenReviewer:  tc.tokenReviewer,
+			})
+			if err == nil {
+				_, err = cli.CSRSign(context.Background(), csrPEM, "fake-client-token", 1)
+			}
+			checkError(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestClientSignPathTemplate(t *testing.T) {
+	server := &mockVaultServer{
+		token:          "fake-client-token",
+		vaultLoginResp: vaultLoginResp,
+		vaultSignResp:  vaultSignResp,
+		signPaths:      map[string]bool{"/v1/pki/sign/foo-bar": true},
+	}
+	server.start(t, false)
+	defer server.httpServer.Close()
+
+	testCases := map[string]struct {
+		host        string
+		signPath    string
+		expectedErr string
+	}{
+		"Role of the service account": {
+			host:     "spiffe://cluster.local/ns/foo/sa/bar",
+			signPath: "pki/sign/{{.Namespace}}-{{.ServiceAccount}}",
+		},
+		"Role of another service account": {
+			host:        "spiffe://cluster.local/ns/foo/sa/other",
+			signPath:    "pki/sign/{{.Namespace}}-{{.ServiceAccount}}",
+			expectedErr: "the CSR requests identity",
+		},
+		"Another service account in addition": {
+			host:        "spiffe://cluster.local/ns/foo/sa/bar,spiffe://cluster.local/ns/foo/sa/other",
+			signPath:    "pki/sign/{{.Namespace}}-{{.ServiceAccount}}",
+			expectedErr: "the CSR requests identity",
+		},
+		"Another trust domain": {
+			host:        "spiffe://other.domain/ns/foo/sa/bar",
+			signPath:    "pki/sign/{{.TrustDomain}}",
+			expectedErr: "the trust domain is",
+		},
+		"No SPIFFE ID": {
+			host:        "foo.example.com",
+			signPath:    "pki/sign/{{.Namespace}}",
+			expectedErr: "the CSR requests identity",
+		},
+		"No identity": {
+			signPath:    "pki/sign/{{.Namespace}}",
+			expectedErr: "the SAN extension does not exist",
+		},
+		"Unknown parameter": {
+			host:        "spiffe://cluster.local/ns/foo/sa/bar",
+			signPath:    "pki/sign/{{.Cluster}}",
+			expectedErr: "failed to render",
+		},
+		"Invalid template": {
+			signPath:    "pki/sign/{{.Namespace",
+			expectedErr: "invalid sign CSR path template",
+		},
+	}
+	for id, tc := range testCases {
+		t.Run(id, func(t *testing.T) {
+			csrPEM := genTestCSR(t, tc.host)
+			cli, err := NewVaultClient(false, nil, server.httpServer.URL, "", "login", tc.signPath)
+			if err == nil {
+				_, err = cli.CSRSign(context.Background(), csrPEM, "fake-client-token", 1)
+			}
+			checkError(t, err, tc.expectedErr)
+		})
+	}
+}
+
+// genTestCAChain returns a PEM-encoded self-signed root certificate, and an intermediate certificate it signed.
+func genTestCAChain(t *testing.T) (string, string) {
+	t.Helper()
+	rootPEM, rootKeyPEM, err := util.GenCertKeyFromOptions(util.CertOptions{
+		IsCA: true, IsSelfSigned: true, TTL: time.Hour, Org: "Root CA", RSAKeySize: 2048})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootCert, err := util.ParsePemEncodedCertificate(rootPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootKey, err := util.ParsePemEncodedKey(rootKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intermediatePEM, _, err := util.GenCertKeyFromOptions(util.CertOptions{
+		IsCA: true, TTL: time.Hour, Org: "Intermediate CA", RSAKeySize: 2048, SignerCert: rootCert, SignerPriv: rootKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(rootPEM), string(intermediatePEM)
+}
+
+func TestClientCAChain(t *testing.T) {
+	root, intermediate := genTestCAChain(t)
+	caChainResp, err := json.Marshal(map[string]interface{}{
+		"data": map[string]interface{}{"certificate": intermediate + root},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signResp, err := json.Marshal(map[string]interface{}{
+		"data": map[string]interface{}{"certificate": "fake-certificate", "ca_chain": []string{intermediate}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signWithRootResp, err := json.Marshal(map[string]interface{}{
+		"data": map[string]interface{}{"certificate": "fake-certificate", "ca_chain": []string{intermediate, root}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	noRootResp, err := json.Marshal(map[string]interface{}{"data": map[string]interface{}{"certificate": intermediate}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		signResp     string
+		caChainResp  string
+		expectedCert []string
+		expectedErr  string
+	}{
+		"Root added to the chain": {
+			signResp:     string(signResp),
+			caChainResp:  string(caChainResp),
+			expectedCert: []string{"fake-certificate\n", intermediate + "\n", root},
+		},
+		"Root already in the chain": {
+			signResp:     string(signWithRootResp),
+			caChainResp:  string(caChainResp),
+			expectedCert: []string{"fake-certificate\n", intermediate + "\n", root + "\n"},
+		},
+		"Empty CA chain": {
+			signResp:    string(signResp),
+			caChainResp: `{"data": {"certificate": ""}}`,
+			expectedErr: "failed to read the CA chain",
+		},
+		"CA chain not ending with a root": {
+			signResp:    string(signResp),
+			caChainResp: string(noRootResp),
+			expectedErr: "not a self-signed root certificate",
+		},
+	}
+	csrPEM := genTestCSR(t, testSpiffeID)
+	for id, tc := range testCases {
+		t.Run(id, func(t *testing.T) {
+			server := &mockVaultServer{
+				token:          "fake-client-token",
+				vaultLoginResp: vaultLoginResp,
+				vaultSignResp:  tc.signResp,
+				caChainResp:    tc.caChainResp,
+			}
+			server.start(t, false)
+			defer server.httpServer.Close()
+
+			cli, err := NewVaultClientWithConfig(false, nil, Config{
+				Addr:        server.httpServer.URL,
+				LoginPath:   "login",
+				SignCsrPath: "sign",
+				CAChainPath: "ca_chain",
+			})
+			if err != nil {
+				t.Fatalf("failed to create ca client: %v", err)
+			}
+			resp, err := cli.CSRSign(context.Background(), csrPEM, "fake-client-token", 1)
+			checkError(t, err, tc.expectedErr)
+			if err == nil && !reflect.DeepEqual(resp, tc.expectedCert) {
+				t.Errorf("resp: got %q, expected %q", resp, tc.expectedCert)
+			}
+		})
+	}
+}
+
+func TestClientCAChainCache(t *testing.T) {
+	root, _ := genTestCAChain(t)
+	caChainResp, err := json.Marshal(map[string]interface{}{"data": map[string]interface{}{"certificate": root}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &mockVaultServer{
+		token:          "fake-client-token",
+		vaultLoginResp: vaultLoginResp,
+		vaultSignResp:  vaultSignResp,
+		caChainResp:    string(caChainResp),
+	}
+	server.start(t, false)
+	defer server.httpServer.Close()
+
+	cli, err := NewVaultClientWithConfig(false, nil, Config{
+		Addr:        server.httpServer.URL,
+		LoginPath:   "login",
+		SignCsrPath: "sign",
+		CAChainPath: "ca_chain",
+	})
+	if err != nil {
+		t.Fatalf("failed to create ca client: %v", err)
+	}
+	now := time.Now()
+	cli.(*vaultClient).now = func() time.Time { return now }
+	csrPEM := genTestCSR(t, testSpiffeID)
+	sign := func() {
+		t.Helper()
+		if _, err := cli.CSRSign(context.Background(), csrPEM, "fake-client-token", 1); err != nil {
+			t.Fatalf("CSRSign() failed: %v", err)
+		}
+	}
+	caChainCount := func() int {
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		return server.caChainCount
+	}
+
+	sign()
+	sign()
+	if got := caChainCount(); got != 1 {
+		t.Errorf("got %d CA chain reads, expected 1", got)
+	}
+
+	// The cached chain is used while the CA chain can't be read.
+	now = now.Add(caChainRefreshInterval)
+	server.mutex.Lock()
+	server.caChainResp = `{"data": {"certificate": ""}}`
+	server.mutex.Unlock()
+	sign()
+	if got := caChainCount(); got != 2 {
+		t.Errorf("got %d CA chain reads, expected 2", got)
+	}
+}
+
+func TestClientKubernetesAuthIdentity(t *testing.T) {
+	testCases := map[string]struct {
+		loginResp   string
+		expectedErr string
+	}{
+		"Identity of another service account": {
+			loginResp: `{"auth": {"client_token": "fake-vault-token",
+				"metadata": {"service_account_namespace": "foo", "service_account_name": "other"}}}`,
+			expectedErr: "the CSR requests identity",
+		},
+		"No identity": {
+			loginResp:   `{"auth": {"client_token": "fake-vault-token"}}`,
+			expectedErr: "no service account in the metadata",
+		},
+	}
+	csrPEM := genTestCSR(t, testSpiffeID)
+	for id, tc := range testCases {
+		t.Run(id, func(t *testing.T) {
+			server := newMockVaultServer(t, false, "", "fake-client-token", tc.loginResp, vaultSignResp)
+			defer server.httpServer.Close()
+
+			cli, err := NewVaultClient(false, nil, server.httpServer.URL, "", "login", "sign")
+			if err != nil {
+				t.Fatalf("failed to create ca client: %v", err)
+			}
+			_, err = cli.CSRSign(context.Background(), csrPEM, "fake-client-token", 1)
+			checkError(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func checkError(t *testing.T, err error, expectedErr string) {
+	t.Helper()
+	if expectedErr == "" {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		return
+	}
+	if err == nil {
+		t.Errorf("expect error: %s but got no error", expectedErr)
+	} else if !strings.Contains(err.Error(), expectedErr) {
+		t.Errorf("error (%s) does not match expected error (%s)", err.Error(), expectedErr)
+	}
+}
+
 // newMockVaultServer creates a mock Vault server for testing purpose.
 // token: required access token
 func newMockVaultServer(t *testing.T, tls bool, loginRole, token, loginResp, signResp string) *mockVaultServer {
@@ -185,10 +707,57 @@ func newMockVaultServer(t *testing.T, tls bool, loginRole, token, loginResp, sig
 		vaultLoginResp: loginResp,
 		vaultSignResp:  signResp,
 	}
+	vaultServer.start(t, tls)
+	return vaultServer
+}
+
+// start starts serving Vault, over TLS if tls is true.
+func (vaultServer *mockVaultServer) start(t *testing.T, tls bool) {
+	handler := vaultServer.handler(t)
+	if tls {
+		vaultServer.httpServer = httptest.NewTLSServer(handler)
+	} else {
+		vaultServer.httpServer = httptest.NewServer(handler)
+	}
+
+	t.Logf("Serving Vault at: %v", vaultServer.httpServer.URL)
+}
+
+// newMockVaultCertAuthServer creates a mock TLS Vault server requesting the TLS client certificates, which are
+// required on /v1/auth/cert/login.
+func newMockVaultCertAuthServer(t *testing.T, loginResp, signResp string) *mockVaultServer {
+	vaultServer := &mockVaultServer{
+		vaultLoginResp: loginResp,
+		vaultSignResp:  signResp,
+	}
+	vaultServer.httpServer = httptest.NewUnstartedServer(vaultServer.handler(t))
+	vaultServer.httpServer.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	vaultServer.httpServer.StartTLS()
+	return vaultServer
+}
 
-	handler := http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+func (vaultServer *mockVaultServer) handler(t *testing.T) http.Handler {
+	checkToken := func(resp http.ResponseWriter, req *http.Request) bool {
+		if req.Header.Get(vaultAuthHeaderName) != "fake-vault-token" {
+			t.Logf("the vault token is invalid: %v", req.Header.Get(vaultAuthHeaderName))
+			resp.WriteHeader(http.StatusBadRequest)
+			return false
+		}
+		return true
+	}
+	countLogin := func() {
+		vaultServer.mutex.Lock()
+		vaultServer.loginCount++
+		vaultServer.mutex.Unlock()
+	}
+
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
 		t.Logf("request: %+v", *req)
-		switch req.URL.Path {
+		path := req.URL.Path
+		if vaultServer.signPaths[path] {
+			path = "/v1/sign"
+		}
+		switch path {
 		case "/v1/login":
 			t.Logf("%v", req.URL)
 			body, err := ioutil.ReadAll(req.Body)
@@ -214,14 +783,60 @@ func newMockVaultServer(t *testing.T, tls bool, loginRole, token, loginResp, sig
 				resp.WriteHeader(http.StatusBadRequest)
 				return
 			}
+			countLogin()
+			resp.Header().Set("Content-Type", "application/json")
+			resp.Write([]byte(vaultServer.vaultLoginResp))
+
+		case "/v1/auth/approle/login":
+			loginReq := appRoleLoginRequest{}
+			if err := json.NewDecoder(req.Body).Decode(&loginReq); err != nil {
+				t.Logf("failed to parse the request body: %v", err)
+				resp.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if loginReq.RoleID != vaultServer.appRoleID || loginReq.SecretID != vaultServer.appRoleSecretID {
+				t.Logf("invalid AppRole credentials: %+v", loginReq)
+				resp.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			countLogin()
 			resp.Header().Set("Content-Type", "application/json")
 			resp.Write([]byte(vaultServer.vaultLoginResp))
 
+		case "/v1/auth/cert/login":
+			if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+				t.Logf("no TLS client certificate")
+				resp.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			countLogin()
+			resp.Header().Set("Content-Type", "application/json")
+			resp.Write([]byte(vaultServer.vaultLoginResp))
+
+		case "/v1/auth/token/renew-self":
+			if !checkToken(resp, req) {
+				return
+			}
+			vaultServer.mutex.Lock()
+			vaultServer.renewCount++
+			vaultServer.mutex.Unlock()
+			resp.Header().Set("Content-Type", "application/json")
+			resp.Write([]byte(vaultServer.vaultLoginResp))
+
+		case "/v1/ca_chain":
+			if !checkToken(resp, req) {
+				return
+			}
+			vaultServer.mutex.Lock()
+			vaultServer.caChainCount++
+			caChainResp := vaultServer.caChainResp
+			vaultServer.mutex.Unlock()
+			resp.Header().Set("Content-Type", "application/json")
+			resp.Write([]byte(caChainResp))
+
 		case "/v1/sign":
 			t.Logf("%v", req.URL)
-			if req.Header.Get(vaultAuthHeaderName) != "fake-vault-token" {
-				t.Logf("the vault token is invalid: %v", req.Header.Get(vaultAuthHeaderName))
-				resp.WriteHeader(http.StatusBadRequest)
+			if !checkToken(resp, req) {
 				return
 			}
 			body, err := ioutil.ReadAll(req.Body)
@@ -255,14 +870,4 @@ func newMockVaultServer(t *testing.T, tls bool, loginRole, token, loginResp, sig
 			resp.WriteHeader(http.StatusNotFound)
 		}
 	})
-
-	if tls {
-		vaultServer.httpServer = httptest.NewTLSServer(handler)
-	} else {
-		vaultServer.httpServer = httptest.NewServer(handler)
-	}
-
-	t.Logf("Serving Vault at: %v", vaultServer.httpServer.URL)
-
-	return vaultServer
 }
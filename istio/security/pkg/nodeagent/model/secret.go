@@ -24,6 +24,9 @@ type SecretItem struct {
 
 	RootCert []byte
 
+	// CRL is the PEM-encoded certificate revocation list of the CA, delivered along with RootCert.
+	CRL []byte
+
 	// RootCertOwnedByCompoundSecret is true if this SecretItem was created by a
 	// K8S secret having both server cert/key and client ca and should be deleted
 	// with the secret.
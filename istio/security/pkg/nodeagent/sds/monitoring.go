@@ -52,6 +52,20 @@ var (
 		"total_secret_update_failures",
 		"The total number of dynamic secret update failures reported by proxy.",
 	)
+
+	// keyCertExpiryTimestamp records the soonest expiration of the key/cert pairs pushed to the connected proxies.
+	keyCertExpiryTimestamp = monitoring.NewGauge(
+		"pushed_cert_soonest_expiry_timestamp",
+		"The unix timestamp, in seconds, when the first key/cert pair pushed to the connected proxies expires, "+
+			"or 0 if none is pushed.",
+	)
+
+	// rootCertExpiryTimestamp records the soonest expiration of the root certs pushed to the connected proxies.
+	rootCertExpiryTimestamp = monitoring.NewGauge(
+		"pushed_root_cert_soonest_expiry_timestamp",
+		"The unix timestamp, in seconds, when the first root cert pushed to the connected proxies expires, "+
+			"or 0 if none is pushed.",
+	)
 )
 
 func init() {
@@ -61,5 +75,7 @@ func init() {
 		totalActiveConnCounts,
 		totalStaleConnCounts,
 		totalSecretUpdateFailureCounts,
+		keyCertExpiryTimestamp,
+		rootCertExpiryTimestamp,
 	)
 }
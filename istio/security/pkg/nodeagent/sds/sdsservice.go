@@ -17,7 +17,9 @@ package sds
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -39,6 +41,7 @@ import (
 	"istio.io/istio/security/pkg/nodeagent/cache"
 	"istio.io/istio/security/pkg/nodeagent/model"
 	"istio.io/pkg/log"
+	"istio.io/pkg/monitoring"
 )
 
 const (
@@ -109,6 +112,9 @@ type sdsConnection struct {
 	// Time of the recent SDS push. Will be reset to zero when a new SDS request is received. A
 	// non-zero time indicates that the connection is waiting for SDS request.
 	sdsPushTime time.Time
+
+	// Time of the last successful SDS push, for debugging.
+	lastPushTime time.Time
 }
 
 type sdsservice struct {
@@ -124,6 +130,9 @@ type sdsservice struct {
 	skipToken bool
 
 	localJWT bool
+
+	// authorizeResource checks the resources requested by the peers, if set.
+	authorizeResource resourceAuthorizer
 }
 
 // ClientDebug represents a single SDS connection to the ndoe agent
@@ -137,6 +146,12 @@ type ClientDebug struct {
 	RootCert         string `json:"root_cert"`
 	CreatedTime      string `json:"created_time"`
 	ExpireTime       string `json:"expire_time"`
+
+	// fields from the pushed certificate, the leaf of the chain or the root cert
+	SerialNumber string `json:"serial_number,omitempty"`
+	NotBefore    string `json:"not_before,omitempty"`
+	NotAfter     string `json:"not_after,omitempty"`
+	LastPushTime string `json:"last_push_time,omitempty"`
 }
 
 // Debug represents all clients connected to this node agent endpoint and their supplied secrets
@@ -189,6 +204,14 @@ func (s *sdsservice) DebugInfo() (string, error) {
 			CreatedTime:      conn.secret.CreatedTime.Format(time.RFC3339),
 			ExpireTime:       conn.secret.ExpireTime.Format(time.RFC3339),
 		}
+		if cert := secretCertificate(conn.secret); cert != nil {
+			c.SerialNumber = cert.SerialNumber.Text(16)
+			c.NotBefore = cert.NotBefore.Format(time.RFC3339)
+			c.NotAfter = cert.NotAfter.Format(time.RFC3339)
+		}
+		if !conn.lastPushTime.IsZero() {
+			c.LastPushTime = conn.lastPushTime.Format(time.RFC3339)
+		}
 		clientDebug = append(clientDebug, c)
 		conn.mutex.RUnlock()
 	}
@@ -289,6 +312,14 @@ func (s *sdsservice) StreamSecrets(stream sds.SecretDiscoveryService_StreamSecre
 				token = t
 			}
 
+			if s.authorizeResource != nil {
+				if err := s.authorizeResource(stream.Context(), resourceName, token); err != nil {
+					sdsServiceLog.Errorf("%s Close connection. Request from proxy %q is not authorized: %v",
+						conIDresourceNamePrefix, discReq.Node.Id, err)
+					return err
+				}
+			}
+
 			// Update metrics.
 			totalActiveConnCounts.Increment()
 			if discReq.ErrorDetail != nil {
@@ -342,6 +373,7 @@ func (s *sdsservice) StreamSecrets(stream sds.SecretDiscoveryService_StreamSecre
 					conIDresourceNamePrefix, discReq.Node.Id, err)
 				return err
 			}
+			updateExpiryMetrics()
 			sdsServiceLog.Infof("%s pushed secret", conIDresourceNamePrefix)
 		case <-con.pushChannel:
 			con.mutex.RLock()
@@ -372,6 +404,7 @@ func (s *sdsservice) StreamSecrets(stream sds.SecretDiscoveryService_StreamSecre
 					conIDresourceNamePrefix, proxyID, err)
 				return err
 			}
+			updateExpiryMetrics()
 			sdsServiceLog.Infoa("Dynamic push for secret ", resourceName)
 		}
 	}
@@ -403,6 +436,13 @@ func (s *sdsservice) FetchSecrets(ctx context.Context, discReq *xdsapi.Discovery
 		return nil, err
 	}
 
+	if s.authorizeResource != nil {
+		if err := s.authorizeResource(ctx, resourceName, token); err != nil {
+			sdsServiceLog.Errorf("Request from proxy %q is not authorized: %v", discReq.Node.Id, err)
+			return nil, err
+		}
+	}
+
 	connID := constructConnectionID(discReq.Node.Id)
 	secret, err := s.st.GenerateSecret(ctx, connID, resourceName, token)
 	if err != nil {
@@ -422,6 +462,7 @@ func (s *sdsservice) clearStaledClientsJob() {
 		select {
 		case <-s.ticker.C:
 			clearStaledClients()
+			updateExpiryMetrics()
 		case <-s.closing:
 			if s.ticker != nil {
 				s.ticker.Stop()
@@ -444,6 +485,63 @@ func clearStaledClients() {
 	}
 }
 
+// updateExpiryMetrics records the soonest expiration of the certificates pushed on the active connections.
+func updateExpiryMetrics() {
+	var keyCertExpiry, rootCertExpiry time.Time
+	sdsClientsMutex.RLock()
+	for connKey, conn := range sdsClients {
+		if staledClientKeys[connKey] {
+			continue
+		}
+		conn.mutex.RLock()
+		cert := secretCertificate(conn.secret)
+		isRoot := conn.secret != nil && conn.secret.RootCert != nil
+		conn.mutex.RUnlock()
+		if cert == nil {
+			continue
+		}
+		soonest := &keyCertExpiry
+		if isRoot {
+			soonest = &rootCertExpiry
+		}
+		if soonest.IsZero() || cert.NotAfter.Before(*soonest) {
+			*soonest = cert.NotAfter
+		}
+	}
+	sdsClientsMutex.RUnlock()
+
+	recordExpiry(keyCertExpiryTimestamp, keyCertExpiry)
+	recordExpiry(rootCertExpiryTimestamp, rootCertExpiry)
+}
+
+func recordExpiry(gauge monitoring.Metric, expiry time.Time) {
+	if expiry.IsZero() {
+		gauge.Record(0)
+		return
+	}
+	gauge.Record(float64(expiry.Unix()))
+}
+
+// secretCertificate returns the leaf certificate of the secret, or its root cert, or nil if it cannot be parsed.
+func secretCertificate(secret *model.SecretItem) *x509.Certificate {
+	if secret == nil {
+		return nil
+	}
+	certPEM := secret.CertificateChain
+	if secret.RootCert != nil {
+		certPEM = secret.RootCert
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil
+	}
+	return cert
+}
+
 // NotifyProxy sends notification to proxy about secret update,
 // SDS will close streaming connection if secret is nil.
 func NotifyProxy(connKey cache.ConnKey, secret *model.SecretItem) error {
@@ -575,6 +673,7 @@ func pushSDS(con *sdsConnection) error {
 	}
 
 	con.sdsPushTime = time.Now()
+	con.lastPushTime = con.sdsPushTime
 
 	// Update metrics after push to avoid adding latency to SDS push.
 	if secret.RootCert != nil {
@@ -615,6 +714,13 @@ func sdsDiscoveryResponse(s *model.SecretItem, conID, resourceName string) (*xds
 				},
 			},
 		}
+		if s.CRL != nil {
+			secret.GetValidationContext().Crl = &core.DataSource{
+				Specifier: &core.DataSource_InlineBytes{
+					InlineBytes: s.CRL,
+				},
+			}
+		}
 	} else {
 		secret.Type = &authapi.Secret_TlsCertificate{
 			TlsCertificate: &authapi.TlsCertificate{
@@ -42,6 +42,7 @@ import (
 	"istio.io/istio/security/pkg/nodeagent/cache"
 	"istio.io/istio/security/pkg/nodeagent/model"
 	"istio.io/istio/security/pkg/nodeagent/util"
+	pkiutil "istio.io/istio/security/pkg/pki/util"
 )
 
 var (
@@ -812,6 +813,44 @@ func verifySDSSResponseForRootCert(t *testing.T, resp *api.DiscoveryResponse, ex
 	}
 }
 
+func TestSDSDiscoveryResponseWithCRL(t *testing.T) {
+	secret := &model.SecretItem{
+		RootCert:     fakeRootCert,
+		CRL:          []byte("crl"),
+		ResourceName: cache.RootCertReqResourceName,
+		Version:      time.Now().String(),
+	}
+	resp, err := sdsDiscoveryResponse(secret, "conID", cache.RootCertReqResourceName)
+	if err != nil {
+		t.Fatalf("failed to build SDS response: %v", err)
+	}
+	var pb authapi.Secret
+	if err := ptypes.UnmarshalAny(resp.Resources[0], &pb); err != nil {
+		t.Fatalf("UnmarshalAny SDS response failed: %v", err)
+	}
+
+	expectedResponseSecret := authapi.Secret{
+		Name: "ROOTCA",
+		Type: &authapi.Secret_ValidationContext{
+			ValidationContext: &authapi.CertificateValidationContext{
+				TrustedCa: &core.DataSource{
+					Specifier: &core.DataSource_InlineBytes{
+						InlineBytes: fakeRootCert,
+					},
+				},
+				Crl: &core.DataSource{
+					Specifier: &core.DataSource_InlineBytes{
+						InlineBytes: []byte("crl"),
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(pb, expectedResponseSecret) {
+		t.Errorf("secret key: got %+v, want %+v", pb, expectedResponseSecret)
+	}
+}
+
 func sdsRequestStream(socket string, req *api.DiscoveryRequest) (*api.DiscoveryResponse, error) {
 	conn, err := setupConnection(socket)
 	if err != nil {
@@ -1029,6 +1068,109 @@ func TestDebugEndpoints(t *testing.T) {
 	}
 }
 
+type fakeRotator struct {
+	resourceName string
+	err          error
+}
+
+func (r *fakeRotator) ForceRotate(resourceName string) (int, error) {
+	r.resourceName = resourceName
+	if r.err != nil {
+		return 0, r.err
+	}
+	return 3, nil
+}
+
+func TestRotateEndpoint(t *testing.T) {
+	tests := map[string]struct {
+		method       string
+		url          string
+		err          error
+		code         int
+		resourceName string
+		body         string
+	}{
+		"all resources": {
+			method: http.MethodPost,
+			url:    "/debug/sds/workload/rotate",
+			code:   http.StatusOK,
+			body:   "{\"rotated\": 3}\n",
+		},
+		"one resource": {
+			method:       http.MethodPost,
+			url:          "/debug/sds/workload/rotate?resource=default",
+			code:         http.StatusOK,
+			resourceName: "default",
+			body:         "{\"rotated\": 3}\n",
+		},
+		"rotation failure": {
+			method:       http.MethodPost,
+			url:          "/debug/sds/workload/rotate?resource=ROOTCA",
+			err:          fmt.Errorf("cannot rotate"),
+			code:         http.StatusBadRequest,
+			resourceName: "ROOTCA",
+		},
+		"GET": {
+			method: http.MethodGet,
+			url:    "/debug/sds/workload/rotate",
+			code:   http.StatusMethodNotAllowed,
+		},
+	}
+
+	for id, tc := range tests {
+		rotator := &fakeRotator{err: tc.err}
+		response := httptest.NewRecorder()
+		rotateHTTPHandler(rotator)(response, httptest.NewRequest(tc.method, tc.url, nil))
+		if response.Code != tc.code {
+			t.Errorf("%s: got status %d, expected %d", id, response.Code, tc.code)
+		}
+		if rotator.resourceName != tc.resourceName {
+			t.Errorf("%s: rotated resource %q, expected %q", id, rotator.resourceName, tc.resourceName)
+		}
+		if tc.body != "" && response.Body.String() != tc.body {
+			t.Errorf("%s: got response %q, expected %q", id, response.Body.String(), tc.body)
+		}
+	}
+}
+
+func TestSecretCertificate(t *testing.T) {
+	certPEM, _, err := pkiutil.GenCertKeyFromOptions(pkiutil.CertOptions{
+		Host:         "spiffe://cluster.local/ns/foo/sa/bar",
+		TTL:          time.Hour,
+		IsSelfSigned: true,
+		RSAKeySize:   2048,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := pkiutil.ParsePemEncodedCertificate(certPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		secret *model.SecretItem
+		valid  bool
+	}{
+		"nil":        {},
+		"cert chain": {secret: &model.SecretItem{CertificateChain: certPEM}, valid: true},
+		"root cert":  {secret: &model.SecretItem{RootCert: certPEM, CertificateChain: fakeCertificateChain}, valid: true},
+		"invalid":    {secret: &model.SecretItem{CertificateChain: fakeCertificateChain}},
+	}
+	for id, tc := range tests {
+		cert := secretCertificate(tc.secret)
+		if !tc.valid {
+			if cert != nil {
+				t.Errorf("%s: got certificate %v, expected none", id, cert.Subject)
+			}
+			continue
+		}
+		if cert == nil || cert.SerialNumber.Cmp(expected.SerialNumber) != 0 || !cert.NotAfter.Equal(expected.NotAfter) {
+			t.Errorf("%s: got certificate %v, expected %v", id, cert, expected.SerialNumber)
+		}
+	}
+}
+
 func checkStaledConnCount(t *testing.T) {
 	// Manually clear staled clients instead of waiting for ticker.
 	clearStaledClients()
@@ -50,6 +50,26 @@ type Options struct {
 	// ingress gateway proxies.
 	IngressGatewayUDSPath string
 
+	// WorkloadTCPAddress is the address, e.g. ":8234", on which the SDS server for workload proxies also listens
+	// over TCP with mTLS, for proxies which cannot share its unix domain socket. It is disabled if empty.
+	WorkloadTCPAddress string
+
+	// IngressGatewayTCPAddress is the TCP address of the SDS server for ingress gateway proxies. It is disabled
+	// if empty.
+	IngressGatewayTCPAddress string
+
+	// TCPCertFile and TCPKeyFile are the bootstrap certificate and key presented by the TCP SDS servers.
+	TCPCertFile string
+	TCPKeyFile  string
+
+	// TCPClientRootCertFile is the root certificate of the client certificates required by the TCP SDS servers.
+	TCPClientRootCertFile string
+
+	// GatewayTCPResourceBindings are the resource names that the ingress gateway TCP clients may request, by
+	// identity of their client certificate. The workload TCP clients may only request the certificates of the
+	// identity of their client certificate.
+	GatewayTCPResourceBindings map[string][]string
+
 	// CertFile is the path of Cert File for gRPC server TLS settings.
 	CertFile string
 
@@ -81,6 +101,20 @@ type Options struct {
 	// The Vault TLS root certificate.
 	VaultTLSRootCert string
 
+	// The Vault auth method: kubernetes, approle or cert.
+	VaultAuthMethod string
+
+	// The Vault AppRole role ID and secret ID, for the approle auth method.
+	VaultAppRoleID       string
+	VaultAppRoleSecretID string
+
+	// The Vault TLS client certificate and key files, for the cert auth method.
+	VaultClientCertFile string
+	VaultClientKeyFile  string
+
+	// The Vault path to read the CA certificate chain from.
+	VaultCAChainPath string
+
 	// GrpcServer is an already configured (shared) grpc server. If set, the agent will just register on the server.
 	GrpcServer *grpc.Server
 
@@ -104,7 +138,7 @@ type Options struct {
 	UseLocalJWT bool
 }
 
-// Server is the gPRC server that exposes SDS through UDS.
+// Server is the gPRC server that exposes SDS through UDS, and optionally over TCP with mTLS.
 type Server struct {
 	workloadSds *sdsservice
 	gatewaySds  *sdsservice
@@ -115,6 +149,10 @@ type Server struct {
 	grpcWorkloadServer *grpc.Server
 	grpcGatewayServer  *grpc.Server
 	debugServer        *http.Server
+
+	// The mTLS servers over TCP, and their SDS services.
+	tcpServers  []*grpc.Server
+	tcpServices []*sdsservice
 }
 
 // NewServer creates and starts the Grpc server for SDS.
@@ -139,10 +177,15 @@ func NewServer(options Options, workloadSecretCache, gatewaySecretCache cache.Se
 		sdsServiceLog.Infof("SDS gRPC server for ingress gateway controller starts, listening on %q \n",
 			options.IngressGatewayUDSPath)
 	}
+	if err := s.initTCPSdsServices(&options, workloadSecretCache, gatewaySecretCache); err != nil {
+		sdsServiceLog.Errorf("Failed to initialize secret discovery service over TCP: %v", err)
+		s.Stop()
+		return nil, err
+	}
 	version.Info.RecordComponentBuildTag("citadel_agent")
 
 	if options.DebugPort > 0 {
-		s.initDebugServer(options.DebugPort)
+		s.initDebugServer(options.DebugPort, workloadSecretCache)
 	}
 	return s, nil
 }
@@ -168,6 +211,10 @@ func (s *Server) Stop() {
 		s.gatewaySds.Stop()
 		s.grpcGatewayServer.Stop()
 	}
+	for i, server := range s.tcpServers {
+		s.tcpServices[i].Stop()
+		server.Stop()
+	}
 
 	if s.debugServer != nil {
 		if err := s.debugServer.Shutdown(context.TODO()); err != nil {
@@ -190,10 +237,20 @@ func NewPlugins(in []string) []plugin.Plugin {
 	return plugins
 }
 
-func (s *Server) initDebugServer(port int) {
+// secretRotator is implemented by the secret managers that can rotate their secrets on demand.
+type secretRotator interface {
+	// ForceRotate rotates the secrets of resourceName, or all of them if resourceName is empty, and returns the
+	// number of secrets rotated.
+	ForceRotate(resourceName string) (int, error)
+}
+
+func (s *Server) initDebugServer(port int, workloadSecretCache cache.SecretManager) {
 	mux := http.NewServeMux()
 	mux.HandleFunc(fmt.Sprintf("%s/sds/workload", debugBase), s.workloadSds.debugHTTPHandler)
 	mux.HandleFunc(fmt.Sprintf("%s/sds/gateway", debugBase), s.gatewaySds.debugHTTPHandler)
+	if rotator, ok := workloadSecretCache.(secretRotator); ok {
+		mux.HandleFunc(fmt.Sprintf("%s/sds/workload/rotate", debugBase), rotateHTTPHandler(rotator))
+	}
 	s.debugServer = &http.Server{
 		Addr:    fmt.Sprintf("127.0.0.1:%d", port),
 		Handler: mux,
@@ -222,6 +279,27 @@ func (s *sdsservice) debugHTTPHandler(w http.ResponseWriter, req *http.Request)
 	}
 }
 
+// rotateHTTPHandler forces the rotation of the workload secrets of the "resource" query parameter, or of all of
+// them if it is not set, on POST requests.
+func rotateHTTPHandler(rotator secretRotator) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		rotated, err := rotator.ForceRotate(req.URL.Query().Get("resource"))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("rotation failure: %s", err), http.StatusBadRequest)
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		if _, err := fmt.Fprintf(w, "{\"rotated\": %d}\n", rotated); err != nil {
+			sdsServiceLog.Errorf("rotation endpoint failed to write response: %s", err)
+		}
+	}
+}
+
 func (s *Server) initWorkloadSdsService(options *Options) error { //nolint: unparam
 	if options.GrpcServer != nil {
 		s.grpcWorkloadServer = options.GrpcServer
@@ -308,6 +386,36 @@ func (s *Server) initGatewaySdsService(options *Options) error {
 	return nil
 }
 
+// initTCPSdsServices starts the TCP SDS servers, which serve the same secrets as the unix domain socket servers.
+func (s *Server) initTCPSdsServices(options *Options, workloadSecretCache, gatewaySecretCache cache.SecretManager) error {
+	workload := options.EnableWorkloadSDS && options.WorkloadTCPAddress != ""
+	gateway := options.EnableIngressGatewaySDS && options.IngressGatewayTCPAddress != ""
+	if !workload && !gateway {
+		return nil
+	}
+	creds, err := tcpServerCredentials(options)
+	if err != nil {
+		return err
+	}
+	if workload {
+		sdsService := newSDSService(workloadSecretCache, false, options.UseLocalJWT, options.RecycleInterval)
+		sdsService.authorizeResource = workloadResourceAuthorizer(options.TrustDomain)
+		if err := s.serveTCP(sdsService, options.WorkloadTCPAddress, creds); err != nil {
+			sdsService.Stop()
+			return fmt.Errorf("SDS grpc server for workload proxies failed to start over TCP: %v", err)
+		}
+	}
+	if gateway {
+		sdsService := newSDSService(gatewaySecretCache, true, options.UseLocalJWT, options.RecycleInterval)
+		sdsService.authorizeResource = gatewayResourceAuthorizer(options.GatewayTCPResourceBindings)
+		if err := s.serveTCP(sdsService, options.IngressGatewayTCPAddress, creds); err != nil {
+			sdsService.Stop()
+			return fmt.Errorf("SDS grpc server for ingress gateway proxy failed to start over TCP: %v", err)
+		}
+	}
+	return nil
+}
+
 func setUpUds(udsPath string) (net.Listener, error) {
 	// Remove unix socket before use.
 	if err := os.Remove(udsPath); err != nil && !os.IsNotExist(err) {
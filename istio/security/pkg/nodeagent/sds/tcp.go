@@ -0,0 +1,227 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sds
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"istio.io/istio/security/pkg/nodeagent/cache"
+)
+
+// resourceAuthorizer returns an error if the peer of the connection is not allowed to request the resource with
+// the credential token.
+type resourceAuthorizer func(ctx context.Context, resourceName, token string) error
+
+// keyPairReloader loads a certificate and key, and loads them again when their files change, so that a rotated
+// certificate is presented without restarting the server.
+type keyPairReloader struct {
+	certFile string
+	keyFile  string
+
+	mutex        sync.Mutex
+	cert         *tls.Certificate
+	certModTime  time.Time
+	keyModTime   time.Time
+	reloadFailed bool
+}
+
+// newKeyPairReloader loads the certificate and key of the files.
+func newKeyPairReloader(certFile, keyFile string) (*keyPairReloader, error) {
+	r := &keyPairReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload loads the certificate and key if their files changed since they were last loaded.
+func (r *keyPairReloader) reload() error {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return err
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.cert != nil && certInfo.ModTime().Equal(r.certModTime) && keyInfo.ModTime().Equal(r.keyModTime) {
+		return nil
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		// The files may be written one after the other, they are loaded again on the next handshake.
+		return err
+	}
+	r.cert = &cert
+	r.certModTime = certInfo.ModTime()
+	r.keyModTime = keyInfo.ModTime()
+	return nil
+}
+
+// GetCertificate returns the current certificate, loaded again if its files changed. The previous certificate
+// is kept if they can't be loaded.
+func (r *keyPairReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	err := r.reload()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if err != nil && !r.reloadFailed {
+		sdsServiceLog.Warnf("failed to load the certificate %s, the previous one is kept: %v", r.certFile, err)
+	}
+	r.reloadFailed = err != nil
+	return r.cert, nil
+}
+
+// tcpServerCredentials returns the mTLS credentials of the TCP SDS servers: they present the bootstrap certificate,
+// loaded again when it is rotated, and require client certificates signed by the client root certificate.
+func tcpServerCredentials(options *Options) (credentials.TransportCredentials, error) {
+	if options.TCPCertFile == "" || options.TCPKeyFile == "" || options.TCPClientRootCertFile == "" {
+		return nil, fmt.Errorf("the certificate, key and client root certificate of the TCP SDS servers must be set")
+	}
+	keyPair, err := newKeyPairReloader(options.TCPCertFile, options.TCPKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the TCP SDS server certificate: %v", err)
+	}
+	rootCert, err := ioutil.ReadFile(options.TCPClientRootCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the TCP SDS client root certificate: %v", err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(rootCert) {
+		return nil, fmt.Errorf("no certificate in the TCP SDS client root certificate file %s",
+			options.TCPClientRootCertFile)
+	}
+	return credentials.NewTLS(&tls.Config{
+		GetCertificate: keyPair.GetCertificate,
+		ClientAuth:     tls.RequireAndVerifyClientCert,
+		ClientCAs:      clientCAs,
+		MinVersion:     tls.VersionTLS12,
+	}), nil
+}
+
+// serveTCP registers the SDS service on a new mTLS gRPC server listening on address.
+func (s *Server) serveTCP(sdsService *sdsservice, address string, creds credentials.TransportCredentials) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", address, err)
+	}
+	server := grpc.NewServer(grpc.MaxConcurrentStreams(uint32(maxStreams)), grpc.Creds(creds))
+	sdsService.register(server)
+	s.tcpServers = append(s.tcpServers, server)
+	s.tcpServices = append(s.tcpServices, sdsService)
+
+	go func() {
+		sdsServiceLog.Infof("Start SDS grpc server over TCP on %s", listener.Addr())
+		if err := server.Serve(listener); err != nil {
+			sdsServiceLog.Errorf("SDS grpc server over TCP on %s failed: %v", listener.Addr(), err)
+		}
+	}()
+	return nil
+}
+
+// peerIdentities returns the URI SANs of the verified client certificate of the connection.
+func peerIdentities(ctx context.Context) ([]string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("no peer in the request context")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil, fmt.Errorf("no verified client certificate")
+	}
+	var ids []string
+	for _, uri := range tlsInfo.State.VerifiedChains[0][0].URIs {
+		ids = append(ids, uri.String())
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no identity in the client certificate")
+	}
+	return ids, nil
+}
+
+// workloadResourceAuthorizer allows the workload TCP clients to request the certificates of their own identity,
+// i.e. their credential token must be the one of the service account of their client certificate.
+func workloadResourceAuthorizer(trustDomain string) resourceAuthorizer {
+	return func(ctx context.Context, resourceName, token string) error {
+		ids, err := peerIdentities(ctx)
+		if err != nil {
+			return status.Errorf(codes.Unauthenticated, "SDS client authentication failure: %v", err)
+		}
+		if resourceName == cache.RootCertReqResourceName {
+			return nil
+		}
+		tokenID, err := cache.IdentityFromToken(trustDomain, token)
+		if err != nil {
+			return status.Errorf(codes.PermissionDenied, "SDS client credential token error: %v", err)
+		}
+		for _, id := range ids {
+			if id == tokenID {
+				return nil
+			}
+		}
+		return status.Errorf(codes.PermissionDenied, "SDS client %v is not allowed to request the certificate of %s",
+			ids, tokenID)
+	}
+}
+
+// gatewayResourceAuthorizer allows the gateway TCP clients to request the resources bound to their identity.
+// The resource names of the bindings may end with "*" to match any suffix.
+func gatewayResourceAuthorizer(bindings map[string][]string) resourceAuthorizer {
+	return func(ctx context.Context, resourceName, token string) error {
+		ids, err := peerIdentities(ctx)
+		if err != nil {
+			return status.Errorf(codes.Unauthenticated, "SDS client authentication failure: %v", err)
+		}
+		for _, id := range ids {
+			for _, pattern := range bindings[id] {
+				if pattern == resourceName ||
+					(strings.HasSuffix(pattern, "*") && strings.HasPrefix(resourceName, strings.TrimSuffix(pattern, "*"))) {
+					return nil
+				}
+			}
+		}
+		return status.Errorf(codes.PermissionDenied, "SDS client %v is not allowed to request %q", ids, resourceName)
+	}
+}
+
+// ParseResourceBindings parses the bindings of the gateway TCP clients, in the format
+// <identity>=<resource name>[|<resource name>...].
+func ParseResourceBindings(bindings []string) (map[string][]string, error) {
+	parsed := map[string][]string{}
+	for _, b := range bindings {
+		parts := strings.SplitN(b, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid resource binding %q, it should be <identity>=<resource name>[|...]", b)
+		}
+		parsed[parts[0]] = append(parsed[parts[0]], strings.Split(parts[1], "|")...)
+	}
+	return parsed, nil
+}
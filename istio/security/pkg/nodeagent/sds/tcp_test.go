@@ -0,0 +1,362 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sds
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	api "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	sds "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v2"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pkiutil "istio.io/istio/security/pkg/pki/util"
+)
+
+const (
+	tcpWorkloadID = "spiffe://cluster.local/ns/foo/sa/bar"
+	tcpGatewayID  = "spiffe://cluster.local/ns/istio-system/sa/istio-ingressgateway-service-account"
+)
+
+// tcpTestCerts holds the bootstrap certificates of a TCP SDS test, written to dir.
+type tcpTestCerts struct {
+	dir        string
+	rootPool   *x509.CertPool
+	clientCert func(id string) tls.Certificate
+}
+
+func newTCPTestCerts(t *testing.T) *tcpTestCerts {
+	dir, err := ioutil.TempDir("", "sds_tcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootPEM, rootKeyPEM, err := pkiutil.GenCertKeyFromOptions(pkiutil.CertOptions{
+		Host:         "cluster.local",
+		TTL:          time.Hour,
+		IsCA:         true,
+		IsSelfSigned: true,
+		RSAKeySize:   2048,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootCert, err := pkiutil.ParsePemEncodedCertificate(rootPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootKey, err := pkiutil.ParsePemEncodedKey(rootKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issue := func(host string, server bool) ([]byte, []byte) {
+		certPEM, keyPEM, err := pkiutil.GenCertKeyFromOptions(pkiutil.CertOptions{
+			Host:       host,
+			TTL:        time.Hour,
+			SignerCert: rootCert,
+			SignerPriv: rootKey,
+			IsServer:   server,
+			IsClient:   !server,
+			RSAKeySize: 2048,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return certPEM, keyPEM
+	}
+
+	serverCert, serverKey := issue("localhost", true)
+	for name, content := range map[string][]byte{"cert.pem": serverCert, "key.pem": serverKey, "root.pem": rootPEM} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), content, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rootPool := x509.NewCertPool()
+	rootPool.AddCert(rootCert)
+	return &tcpTestCerts{
+		dir:      dir,
+		rootPool: rootPool,
+		clientCert: func(id string) tls.Certificate {
+			certPEM, keyPEM := issue(id, false)
+			cert, err := tls.X509KeyPair(certPEM, keyPEM)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return cert
+		},
+	}
+}
+
+func freeTCPAddress(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+// fakeServiceAccountToken returns an unsigned Kubernetes JWT token of the service account ns/sa.
+func fakeServiceAccountToken(ns, sa string) string {
+	enc := base64.RawURLEncoding.EncodeToString
+	payload := fmt.Sprintf(`{"iss":"kubernetes/serviceaccount","sub":"system:serviceaccount:%s:%s"}`, ns, sa)
+	return enc([]byte(`{"alg":"RS256"}`)) + "." + enc([]byte(payload)) + ".c2lnbmF0dXJl"
+}
+
+func sdsTCPRequest(address string, clientCerts []tls.Certificate, rootPool *x509.CertPool, token string,
+	req *api.DiscoveryRequest, stream bool) (*api.DiscoveryResponse, error) {
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: clientCerts,
+		RootCAs:      rootPool,
+		ServerName:   "localhost",
+	})
+	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	sdsClient := sds.NewSecretDiscoveryServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs(credentialTokenHeaderKey, token))
+	if !stream {
+		return sdsClient.FetchSecrets(ctx, req)
+	}
+	s, err := sdsClient.StreamSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Send(req); err != nil {
+		return nil, err
+	}
+	return s.Recv()
+}
+
+func TestSecretsOverTCP(t *testing.T) {
+	certs := newTCPTestCerts(t)
+	defer os.RemoveAll(certs.dir)
+
+	arg := Options{
+		EnableWorkloadSDS:        true,
+		EnableIngressGatewaySDS:  true,
+		RecycleInterval:          30 * time.Second,
+		WorkloadUDSPath:          fmt.Sprintf("/tmp/workload_gotest%d.sock", time.Now().UnixNano()),
+		IngressGatewayUDSPath:    fmt.Sprintf("/tmp/gateway_gotest%d.sock", time.Now().UnixNano()),
+		WorkloadTCPAddress:       freeTCPAddress(t),
+		IngressGatewayTCPAddress: freeTCPAddress(t),
+		TCPCertFile:              filepath.Join(certs.dir, "cert.pem"),
+		TCPKeyFile:               filepath.Join(certs.dir, "key.pem"),
+		TCPClientRootCertFile:    filepath.Join(certs.dir, "root.pem"),
+		GatewayTCPResourceBindings: map[string][]string{
+			tcpGatewayID: {"default"},
+		},
+	}
+	server, err := NewServer(arg, &mockSecretStore{}, &mockSecretStore{})
+	if err != nil {
+		t.Fatalf("failed to start grpc server for sds: %v", err)
+	}
+	defer server.Stop()
+
+	workloadCert := []tls.Certificate{certs.clientCert(tcpWorkloadID)}
+	gatewayCert := []tls.Certificate{certs.clientCert(tcpGatewayID)}
+	testCases := map[string]struct {
+		address      string
+		clientCerts  []tls.Certificate
+		token        string
+		resourceName string
+		code         codes.Code
+	}{
+		"workload": {
+			address:      arg.WorkloadTCPAddress,
+			clientCerts:  workloadCert,
+			token:        fakeServiceAccountToken("foo", "bar"),
+			resourceName: testResourceName,
+			code:         codes.OK,
+		},
+		"workload token of another service account": {
+			address:      arg.WorkloadTCPAddress,
+			clientCerts:  workloadCert,
+			token:        fakeServiceAccountToken("foo", "admin"),
+			resourceName: testResourceName,
+			code:         codes.PermissionDenied,
+		},
+		"workload without client certificate": {
+			address:      arg.WorkloadTCPAddress,
+			token:        fakeServiceAccountToken("foo", "bar"),
+			resourceName: testResourceName,
+			code:         codes.Unavailable,
+		},
+		"gateway": {
+			address:      arg.IngressGatewayTCPAddress,
+			clientCerts:  gatewayCert,
+			resourceName: testResourceName,
+			code:         codes.OK,
+		},
+		"gateway unbound identity": {
+			address:      arg.IngressGatewayTCPAddress,
+			clientCerts:  workloadCert,
+			resourceName: testResourceName,
+			code:         codes.PermissionDenied,
+		},
+	}
+	for id, tc := range testCases {
+		for _, stream := range []bool{false, true} {
+			req := &api.DiscoveryRequest{
+				ResourceNames: []string{tc.resourceName},
+				Node:          &core.Node{Id: "sidecar~127.0.0.1~id1~local"},
+			}
+			resp, err := sdsTCPRequest(tc.address, tc.clientCerts, certs.rootPool, tc.token, req, stream)
+			if code := status.Code(err); code != tc.code {
+				t.Errorf("%s (stream: %v): got code %v (%v), expected %v", id, stream, code, err, tc.code)
+				continue
+			}
+			if tc.code == codes.OK {
+				if err := verifySDSSResponse(resp, fakePrivateKey, fakeCertificateChain); err != nil {
+					t.Errorf("%s (stream: %v): failed to verify SDS response %v", id, stream, err)
+				}
+			}
+		}
+	}
+}
+
+func TestNewServerOverTCPWithoutCerts(t *testing.T) {
+	arg := Options{
+		EnableWorkloadSDS:  true,
+		RecycleInterval:    30 * time.Second,
+		WorkloadUDSPath:    fmt.Sprintf("/tmp/workload_gotest%d.sock", time.Now().UnixNano()),
+		WorkloadTCPAddress: "127.0.0.1:0",
+	}
+	if _, err := NewServer(arg, &mockSecretStore{}, nil); err == nil {
+		t.Error("NewServer() succeeded without the certificates of the TCP server")
+	}
+}
+
+func TestParseResourceBindings(t *testing.T) {
+	testCases := map[string]struct {
+		bindings []string
+		expected map[string][]string
+		err      bool
+	}{
+		"empty": {
+			expected: map[string][]string{},
+		},
+		"bindings": {
+			bindings: []string{tcpGatewayID + "=default|gateway-*", tcpWorkloadID + "=foo", tcpGatewayID + "=bar"},
+			expected: map[string][]string{
+				tcpGatewayID:  {"default", "gateway-*", "bar"},
+				tcpWorkloadID: {"foo"},
+			},
+		},
+		"no resource": {
+			bindings: []string{tcpGatewayID + "="},
+			err:      true,
+		},
+		"no identity": {
+			bindings: []string{"default"},
+			err:      true,
+		},
+	}
+	for id, tc := range testCases {
+		got, err := ParseResourceBindings(tc.bindings)
+		if tc.err != (err != nil) {
+			t.Errorf("%s: got error %v", id, err)
+		} else if !tc.err && !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%s: got %v, expected %v", id, got, tc.expected)
+		}
+	}
+}
+
+func TestKeyPairReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sds_tcp_reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+
+	// writeKeyPair writes a new certificate and key, modified at the given time, and returns the certificate.
+	writeKeyPair := func(modTime time.Time) []byte {
+		certPEM, keyPEM, err := pkiutil.GenCertKeyFromOptions(pkiutil.CertOptions{
+			Host:         "localhost",
+			TTL:          time.Hour,
+			IsSelfSigned: true,
+			IsServer:     true,
+			RSAKeySize:   2048,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for file, content := range map[string][]byte{certFile: certPEM, keyFile: keyPEM} {
+			if err := ioutil.WriteFile(file, content, 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(file, modTime, modTime); err != nil {
+				t.Fatal(err)
+			}
+		}
+		cert, err := pkiutil.ParsePemEncodedCertificate(certPEM)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert.Raw
+	}
+	checkCert := func(r *keyPairReloader, want []byte) {
+		t.Helper()
+		cert, err := r.GetCertificate(nil)
+		if err != nil {
+			t.Fatalf("GetCertificate() failed: %v", err)
+		}
+		if !reflect.DeepEqual(cert.Certificate[0], want) {
+			t.Error("GetCertificate() returned an unexpected certificate")
+		}
+	}
+
+	now := time.Now()
+	first := writeKeyPair(now.Add(-time.Hour))
+	r, err := newKeyPairReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("newKeyPairReloader() failed: %v", err)
+	}
+	checkCert(r, first)
+
+	// The rotated certificate is loaded.
+	second := writeKeyPair(now)
+	checkCert(r, second)
+
+	// The previous certificate is kept while the files are invalid.
+	if err := ioutil.WriteFile(keyFile, []byte("invalid key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(keyFile, now.Add(time.Hour), now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	checkCert(r, second)
+}
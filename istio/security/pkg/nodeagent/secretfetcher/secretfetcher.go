@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -25,6 +26,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -33,6 +35,7 @@ import (
 	"istio.io/istio/pkg/kube"
 	ca "istio.io/istio/security/pkg/nodeagent/caclient"
 	caClientInterface "istio.io/istio/security/pkg/nodeagent/caclient/interface"
+	vault "istio.io/istio/security/pkg/nodeagent/caclient/providers/vault"
 	"istio.io/istio/security/pkg/nodeagent/model"
 	nodeagentutil "istio.io/istio/security/pkg/nodeagent/util"
 	"istio.io/pkg/env"
@@ -119,7 +122,7 @@ func fatalf(template string, args ...interface{}) {
 
 // NewSecretFetcher returns a pointer to a newly constructed SecretFetcher instance.
 func NewSecretFetcher(ingressGatewayAgent bool, endpoint, caProviderName string, tlsFlag bool,
-	tlsRootCert []byte, vaultAddr, vaultRole, vaultAuthPath, vaultSignCsrPath string) (*SecretFetcher, error) {
+	tlsRootCert []byte, vaultConfig vault.Config) (*SecretFetcher, error) {
 	ret := &SecretFetcher{}
 
 	if ingressGatewayAgent {
@@ -132,8 +135,7 @@ func NewSecretFetcher(ingressGatewayAgent bool, endpoint, caProviderName string,
 		secretFetcherLog.Debugf("SecretFetcher set fallback secret name %s", ret.FallbackSecretName)
 		ret.InitWithKubeClient(cs.CoreV1())
 	} else {
-		caClient, err := ca.NewCAClient(endpoint, caProviderName, tlsFlag, tlsRootCert,
-			vaultAddr, vaultRole, vaultAuthPath, vaultSignCsrPath)
+		caClient, err := ca.NewCAClient(endpoint, caProviderName, tlsFlag, tlsRootCert, vaultConfig)
 		if err != nil {
 			secretFetcherLog.Errorf("failed to create caClient: %v", err)
 			return ret, fmt.Errorf("failed to create caClient")
@@ -532,6 +534,33 @@ func (sf *SecretFetcher) FindIngressGatewaySecret(key string) (secret model.Secr
 	return e, true
 }
 
+// FindIngressGatewaySecrets returns the server certificates of the kubernetes secrets whose labels match the
+// selector, sorted by name. Unlike FindIngressGatewaySecret, it never returns the fallback secret for a missing
+// secret, and it skips the CA only secrets.
+func (sf *SecretFetcher) FindIngressGatewaySecrets(selector labels.Selector) []model.SecretItem {
+	if sf.scrtStore == nil {
+		return nil
+	}
+	out := make([]model.SecretItem, 0)
+	for _, obj := range sf.scrtStore.List() {
+		scrt, ok := obj.(*v1.Secret)
+		if !ok || !selector.Matches(labels.Set(scrt.GetLabels())) {
+			continue
+		}
+		val, exist := sf.secrets.Load(scrt.GetName())
+		if !exist {
+			continue
+		}
+		if secret := val.(model.SecretItem); len(secret.CertificateChain) > 0 {
+			out = append(out, secret)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].ResourceName < out[j].ResourceName
+	})
+	return out
+}
+
 // AddSecret adds obj into local store. Only used for testing.
 func (sf *SecretFetcher) AddSecret(obj interface{}) {
 	sf.scrtAdded(obj)
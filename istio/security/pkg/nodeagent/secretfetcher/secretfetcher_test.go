@@ -20,6 +20,7 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes/fake"
 
 	"istio.io/istio/security/pkg/nodeagent/model"
@@ -771,6 +772,50 @@ func TestSecretFetcherUsingFallbackIngressSecret(t *testing.T) {
 	}
 }
 
+// TestFindIngressGatewaySecrets verifies that secret fetcher finds the server certificates of the secrets
+// matching a label selector, and skips the CA only secrets.
+func TestFindIngressGatewaySecrets(t *testing.T) {
+	gSecretFetcher := &SecretFetcher{
+		UseCaClient:        false,
+		FallbackSecretName: k8sSecretFallbackScrt,
+	}
+	gSecretFetcher.InitWithKubeClient(fake.NewSimpleClientset().CoreV1())
+
+	tenantLabels := map[string]string{"gateway-certificate": "tenant"}
+	secretA := k8sTestGenericSecretA.DeepCopy()
+	secretA.Labels = tenantLabels
+	secretC := k8sTestTLSSecretC.DeepCopy()
+	secretC.Labels = tenantLabels
+	secretE := k8sTestGenericCASecretE.DeepCopy()
+	secretE.Labels = tenantLabels
+	for _, scrt := range []*v1.Secret{secretC, secretA, secretE, k8sTestTLSFallbackSecret} {
+		if err := gSecretFetcher.scrtStore.Add(scrt); err != nil {
+			t.Fatal(err)
+		}
+		gSecretFetcher.AddSecret(scrt)
+	}
+
+	selector := labels.SelectorFromSet(tenantLabels)
+	got := gSecretFetcher.FindIngressGatewaySecrets(selector)
+	if len(got) != 2 || got[0].ResourceName != k8sSecretNameA || got[1].ResourceName != k8sSecretNameC {
+		t.Fatalf("got secrets %v, want %s and %s", got, k8sSecretNameA, k8sSecretNameC)
+	}
+	if !bytes.Equal(got[1].CertificateChain, k8sCertChainC) {
+		t.Errorf("got certificate chain %s, want %s", got[1].CertificateChain, k8sCertChainC)
+	}
+
+	if err := gSecretFetcher.scrtStore.Delete(secretA); err != nil {
+		t.Fatal(err)
+	}
+	gSecretFetcher.DeleteSecret(secretA)
+	if got := gSecretFetcher.FindIngressGatewaySecrets(selector); len(got) != 1 || got[0].ResourceName != k8sSecretNameC {
+		t.Errorf("got secrets %v after deletion, want %s", got, k8sSecretNameC)
+	}
+	if got := gSecretFetcher.FindIngressGatewaySecrets(labels.SelectorFromSet(map[string]string{"other": "label"})); len(got) != 0 {
+		t.Errorf("got secrets %v, want none", got)
+	}
+}
+
 func compareSecret(t *testing.T, secret, expectedSecret *model.SecretItem) {
 	if expectedSecret.ResourceName != secret.ResourceName {
 		t.Errorf("resource name verification error: expected %s but got %s", expectedSecret.ResourceName, secret.ResourceName)
@@ -15,8 +15,11 @@
 package ca
 
 import (
+	"bytes"
 	"context"
+	"crypto"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
 	"encoding/pem"
 	"fmt"
@@ -58,6 +61,15 @@ const (
 
 	// The size of a private key for a self-signed Istio CA.
 	caKeySize = 2048
+
+	// DefaultCRLRefreshInterval is how often the workloads refresh the certificate revocation list by default,
+	// the default secret rotation interval of the node agent.
+	DefaultCRLRefreshInterval = 10 * time.Minute
+	// crlRefreshMargin is the number of refresh intervals the certificate revocation lists are valid for.
+	crlRefreshMargin = 6
+
+	// statePersistInterval is how often the state of the CA is persisted if it changed, besides on revocation.
+	statePersistInterval = 30 * time.Second
 )
 
 var pkiCaLog = log.RegisterScope("pkiCaLog", "Citadel CA log", 0)
@@ -87,6 +99,25 @@ type IstioCAOptions struct {
 
 	// Config for creating self-signed root cert rotator.
 	RotatorConfig *SelfSignedCARootCertRotatorConfig
+
+	// AllowedKeyAlgorithms restricts the algorithms of the keys of the CSRs of workload certificates. Any
+	// algorithm is allowed if it is empty.
+	AllowedKeyAlgorithms []util.KeyAlgorithm
+
+	// CRLRefreshInterval is how often the workloads refresh the certificate revocation list. Defaults to
+	// DefaultCRLRefreshInterval.
+	CRLRefreshInterval time.Duration
+	// ChainCRLFile is the file of the PEM-encoded CRLs of the CAs above the signing certificate, up to the root,
+	// and of the other trusted roots. The proxies check the revocation of every certificate of the chain, so the
+	// CA only serves a CRL when the CRLs of all the CAs of the chain are available.
+	ChainCRLFile string
+	// PreviousCRLSigners are the signing certificates the CA used before, e.g. the self-signed CA being migrated
+	// from, and their keys. The certificates they issued are still trusted, so the CA serves CRLs signed by them.
+	PreviousCRLSigners []CRLSigner
+
+	// stateStore persists the state of the CA, e.g. the revoked certificates. It is kept in memory only if the
+	// store is nil.
+	stateStore stateStore
 }
 
 // NewSelfSignedIstioCAOptions returns a new IstioCAOptions instance using self-signed certificate.
@@ -119,6 +150,7 @@ func NewSelfSignedIstioCAOptions(ctx context.Context,
 		CAType:     selfSignedCA,
 		CertTTL:    certTTL,
 		MaxCertTTL: maxCertTTL,
+		stateStore: newSecretStateStore(namespace, client),
 		RotatorConfig: &SelfSignedCARootCertRotatorConfig{
 			CheckInterval:      rootCertCheckInverval,
 			caCertTTL:          caCertTTL,
@@ -186,17 +218,67 @@ func NewSelfSignedIstioCAOptions(ctx context.Context,
 }
 
 // NewPluggedCertIstioCAOptions returns a new IstioCAOptions instance using given certificate.
+// trustedRootCerts are PEM-encoded roots added to the trust bundle distributed to the workloads besides the
+// root of the signing certificate, e.g. the root of the self-signed CA being migrated from, so that the
+// workloads keep trusting the certificates it issued until they are all renewed.
 func NewPluggedCertIstioCAOptions(certChainFile, signingCertFile, signingKeyFile, rootCertFile string,
-	certTTL, maxCertTTL time.Duration, namespace string, client corev1.CoreV1Interface) (caOpts *IstioCAOptions, err error) {
-	caOpts = &IstioCAOptions{
-		CAType:     pluggedCertCA,
-		CertTTL:    certTTL,
-		MaxCertTTL: maxCertTTL,
+	trustedRootCerts []byte, certTTL, maxCertTTL time.Duration, namespace string,
+	client corev1.CoreV1Interface) (caOpts *IstioCAOptions, err error) {
+	bundle, err := util.NewVerifiedKeyCertBundleFromFile(signingCertFile, signingKeyFile, certChainFile, rootCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CA KeyCertBundle (%v)", err)
 	}
-	if caOpts.KeyCertBundle, err = util.NewVerifiedKeyCertBundleFromFile(
-		signingCertFile, signingKeyFile, certChainFile, rootCertFile); err != nil {
+	return newPluggedCertIstioCAOptions(bundle, nil, signingCertFile, trustedRootCerts, certTTL, maxCertTTL,
+		namespace, client)
+}
+
+// NewPluggedCertIstioCAOptionsWithSigner is NewPluggedCertIstioCAOptions for a signing key held by the signer,
+// e.g. a key in an HSM that can't be exported. The key is only used through the signer.
+func NewPluggedCertIstioCAOptionsWithSigner(certChainFile, signingCertFile string, signer crypto.Signer,
+	rootCertFile string, trustedRootCerts []byte, certTTL, maxCertTTL time.Duration, namespace string,
+	client corev1.CoreV1Interface) (caOpts *IstioCAOptions, err error) {
+	bundle, err := util.NewVerifiedKeyCertBundleFromSignerAndFile(signingCertFile, signer, certChainFile, rootCertFile)
+	if err != nil {
 		return nil, fmt.Errorf("failed to create CA KeyCertBundle (%v)", err)
 	}
+	return newPluggedCertIstioCAOptions(bundle, signer, signingCertFile, trustedRootCerts, certTTL, maxCertTTL,
+		namespace, client)
+}
+
+// newPluggedCertIstioCAOptions returns the IstioCAOptions of a plugged certificate, whose private key is held by the
+// signer if it is not nil.
+func newPluggedCertIstioCAOptions(bundle *util.KeyCertBundleImpl, signer crypto.Signer, signingCertFile string,
+	trustedRootCerts []byte, certTTL, maxCertTTL time.Duration, namespace string,
+	client corev1.CoreV1Interface) (caOpts *IstioCAOptions, err error) {
+	caOpts = &IstioCAOptions{
+		CAType:        pluggedCertCA,
+		CertTTL:       certTTL,
+		MaxCertTTL:    maxCertTTL,
+		KeyCertBundle: bundle,
+		stateStore:    newSecretStateStore(namespace, client),
+	}
+	if len(trustedRootCerts) > 0 {
+		certBytes, privKeyBytes, certChainBytes, rootCertBytes := bundle.GetAllPem()
+		trustBundle, err := util.MergeTrustBundles(time.Now(), rootCertBytes, trustedRootCerts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge the trusted root certificates (%v)", err)
+		}
+		if signer != nil {
+			err = bundle.VerifyAndSetAllWithSigner(certBytes, signer, certChainBytes, trustBundle)
+		} else {
+			err = bundle.VerifyAndSetAll(certBytes, privKeyBytes, certChainBytes, trustBundle)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create CA KeyCertBundle (%v)", err)
+		}
+		roots, err := util.ParseTrustBundle(trustBundle)
+		if err != nil {
+			return nil, err
+		}
+		for _, root := range roots {
+			pkiCaLog.Infof("Trusting root %q (fingerprint %s)", root.Subject, util.CertFingerprint(root))
+		}
+	}
 
 	// Validate that the passed in signing cert can be used as CA.
 	// The check can't be done inside `KeyCertBundle`, since bundle could also be used to
@@ -220,6 +302,8 @@ func NewPluggedCertIstioCAOptions(certChainFile, signingCertFile, signingKeyFile
 	crt := caOpts.KeyCertBundle.GetCertChainPem()
 	if len(crt) == 0 {
 		crt = caOpts.KeyCertBundle.GetRootCertPem()
+	} else if len(trustedRootCerts) > 0 {
+		crt = append(append([]byte{}, crt...), trustedRootCerts...)
 	}
 	if err = updateCertInConfigmap(namespace, client, crt); err != nil {
 		pkiCaLog.Errorf("Failed to write Citadel cert to configmap (%v). Node agents will not be able to connect.", err)
@@ -227,6 +311,45 @@ func NewPluggedCertIstioCAOptions(certChainFile, signingCertFile, signingKeyFile
 	return caOpts, nil
 }
 
+// LoadSelfSignedCARootCert returns the root certificate of the self-signed CA persisted in the istio-ca-secret
+// secret. It is meant to be passed as a trusted root to NewPluggedCertIstioCAOptions when migrating from the
+// self-signed CA to a plugged certificate.
+func LoadSelfSignedCARootCert(namespace string, client corev1.CoreV1Interface) ([]byte, error) {
+	caSecret, err := client.Secrets(namespace).Get(CASecret, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secret %s:%s (%v)", namespace, CASecret, err)
+	}
+	if len(caSecret.Data[caCertID]) == 0 {
+		return nil, fmt.Errorf("secret %s:%s has no %s", namespace, CASecret, caCertID)
+	}
+	return caSecret.Data[caCertID], nil
+}
+
+// CRLSigner is a CA certificate and its private key, used to sign certificate revocation lists.
+type CRLSigner struct {
+	Cert *x509.Certificate
+	Key  crypto.PrivateKey
+}
+
+// LoadSelfSignedCACRLSigner returns the certificate and key of the self-signed CA persisted in the
+// istio-ca-secret secret, to sign the CRLs of the certificates it issued when migrating from the self-signed CA
+// to a plugged certificate.
+func LoadSelfSignedCACRLSigner(namespace string, client corev1.CoreV1Interface) (*CRLSigner, error) {
+	caSecret, err := client.Secrets(namespace).Get(CASecret, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secret %s:%s (%v)", namespace, CASecret, err)
+	}
+	cert, err := util.ParsePemEncodedCertificate(caSecret.Data[caCertID])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s of secret %s:%s (%v)", caCertID, namespace, CASecret, err)
+	}
+	key, err := util.ParsePemEncodedKey(caSecret.Data[caPrivateKeyID])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s of secret %s:%s (%v)", caPrivateKeyID, namespace, CASecret, err)
+	}
+	return &CRLSigner{Cert: cert, Key: key}, nil
+}
+
 // IstioCA generates keys and certificates for Istio identities.
 type IstioCA struct {
 	certTTL    time.Duration
@@ -239,15 +362,39 @@ type IstioCA struct {
 	// rootCertRotator periodically rotates self-signed root cert for CA. It is nil
 	// if CA is not self-signed CA.
 	rootCertRotator *SelfSignedCARootCertRotator
+
+	// revocations tracks the issued and revoked workload certificates.
+	revocations *revocationList
+	// chainCRLFile is the file of the CRLs of the CAs above the signing certificate.
+	chainCRLFile string
+	// previousCRLSigners sign CRLs for the certificates issued by the previous signing certificates.
+	previousCRLSigners []CRLSigner
+
+	allowedKeyAlgorithms []util.KeyAlgorithm
 }
 
 // NewIstioCA returns a new IstioCA instance.
 func NewIstioCA(opts *IstioCAOptions) (*IstioCA, error) {
+	crlRefreshInterval := opts.CRLRefreshInterval
+	if crlRefreshInterval <= 0 {
+		crlRefreshInterval = DefaultCRLRefreshInterval
+	}
 	ca := &IstioCA{
 		certTTL:       opts.CertTTL,
 		maxCertTTL:    opts.MaxCertTTL,
 		keyCertBundle: opts.KeyCertBundle,
 		livenessProbe: probe.NewProbe(),
+		revocations:   newRevocationList(opts.MaxCertTTL, crlRefreshInterval),
+		chainCRLFile:  opts.ChainCRLFile,
+
+		previousCRLSigners: opts.PreviousCRLSigners,
+
+		allowedKeyAlgorithms: opts.AllowedKeyAlgorithms,
+	}
+	if opts.stateStore != nil {
+		if err := ca.revocations.load(opts.stateStore); err != nil {
+			return nil, fmt.Errorf("failed to load the revoked certificates (%v)", err)
+		}
 	}
 
 	if opts.CAType == selfSignedCA && opts.RotatorConfig.CheckInterval > time.Duration(0) {
@@ -261,6 +408,7 @@ func (ca *IstioCA) Run(stopChan chan struct{}) {
 		// Start root cert rotator in a separate goroutine.
 		go ca.rootCertRotator.Run(stopChan)
 	}
+	go ca.revocations.persistLoop(statePersistInterval, stopChan)
 }
 
 // Sign takes a PEM-encoded CSR, subject IDs and lifetime, and returns a signed certificate. If forCA is true,
@@ -276,6 +424,11 @@ func (ca *IstioCA) Sign(csrPEM []byte, subjectIDs []string, requestedLifetime ti
 	if err != nil {
 		return nil, caerror.NewError(caerror.CSRError, err)
 	}
+	if !forCA {
+		if err = util.CheckKeyAlgorithm(csr.PublicKey, ca.allowedKeyAlgorithms); err != nil {
+			return nil, caerror.NewError(caerror.CSRError, err)
+		}
+	}
 
 	lifetime := requestedLifetime
 	// If the requested requestedLifetime is non-positive, apply the default TTL.
@@ -293,6 +446,12 @@ func (ca *IstioCA) Sign(csrPEM []byte, subjectIDs []string, requestedLifetime ti
 		return nil, caerror.NewError(caerror.CertGenError, err)
 	}
 
+	if !forCA {
+		if parsed, err := x509.ParseCertificate(certBytes); err == nil {
+			ca.revocations.record(parsed, subjectIDs, util.CertFingerprint(signingCert))
+		}
+	}
+
 	block := &pem.Block{
 		Type:  "CERTIFICATE",
 		Bytes: certBytes,
@@ -320,6 +479,120 @@ func (ca *IstioCA) GetCAKeyCertBundle() util.KeyCertBundle {
 	return ca.keyCertBundle
 }
 
+// Revoke revokes the workload certificates with the given hexadecimal serial numbers, and if subjectID is not
+// empty, all the unexpired certificates the CA issued to that identity. It returns the serial numbers of the
+// certificates that were not revoked yet. An identity the CA knows no unexpired certificate of is an error, rather
+// than nothing to revoke.
+func (ca *IstioCA) Revoke(serialNumbers []string, subjectID string) ([]string, error) {
+	if len(serialNumbers) == 0 && subjectID == "" {
+		return nil, caerror.NewError(caerror.RevocationError, fmt.Errorf("no serial number or identity to revoke"))
+	}
+	revoked, err := ca.revocations.revoke(serialNumbers, subjectID)
+	if len(revoked) > 0 {
+		pkiCaLog.Infof("revoked certificates %v (identity %q)", revoked, subjectID)
+	}
+	if err != nil {
+		return nil, caerror.NewError(caerror.RevocationError, err)
+	}
+	return revoked, nil
+}
+
+// CertificateRevocationList returns the PEM-encoded CRL of the revoked workload certificates signed by the CA,
+// followed by the ones signed by the previous CRL signers and the CRLs of the chain CRL file. The proxies reject
+// every certificate whose chain is not entirely covered by CRLs, so an error is returned rather than an
+// incomplete list if a CA of the signing chain or a trusted root has no valid CRL.
+func (ca *IstioCA) CertificateRevocationList() ([]byte, error) {
+	signingCert, signingKey, certChainBytes, rootCertBytes := ca.keyCertBundle.GetAll()
+	if signingCert == nil {
+		return nil, caerror.NewError(caerror.CANotReady, fmt.Errorf("Istio CA is not ready")) // nolint
+	}
+	crl, err := ca.revocations.crlPEM(signingCert, *signingKey)
+	if err != nil {
+		return nil, caerror.NewError(caerror.CRLGenError, err)
+	}
+	crls := append([]byte{}, crl...)
+	for _, signer := range ca.previousCRLSigners {
+		if crl, err = ca.revocations.crlPEM(signer.Cert, signer.Key); err != nil {
+			return nil, caerror.NewError(caerror.CRLGenError, err)
+		}
+		crls = append(crls, crl...)
+	}
+	if ca.chainCRLFile != "" {
+		chainCRLs, err := ioutil.ReadFile(ca.chainCRLFile)
+		if err != nil {
+			return nil, caerror.NewError(caerror.CRLGenError, fmt.Errorf("failed to read the chain CRLs (%v)", err))
+		}
+		crls = append(crls, chainCRLs...)
+	}
+	if err = checkCRLCoverage(crls, signingCert, certChainBytes, rootCertBytes); err != nil {
+		return nil, caerror.NewError(caerror.CRLGenError, err)
+	}
+	return crls, nil
+}
+
+// checkCRLCoverage checks that the PEM-encoded CRLs include an unexpired CRL of every CA above the signing
+// certificate, of the signing certificate itself, and of every trusted root.
+func checkCRLCoverage(pemCRLs []byte, signingCert *x509.Certificate, certChainBytes, rootCertBytes []byte) error {
+	var crls []*pkix.CertificateList
+	for rest := pemCRLs; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		if block.Type != crlPemType {
+			continue
+		}
+		crl, err := x509.ParseCRL(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("failed to parse CRL (%v)", err)
+		}
+		crls = append(crls, crl)
+	}
+	now := time.Now()
+	covered := func(ca *x509.Certificate) error {
+		for _, crl := range crls {
+			if ca.CheckCRLSignature(crl) == nil && now.Before(crl.TBSCertList.NextUpdate) {
+				return nil
+			}
+		}
+		return fmt.Errorf("no valid CRL of the CA %q, configure it with --chain-crl", ca.Subject)
+	}
+
+	chain, err := util.ParseTrustBundle(certChainBytes)
+	if err != nil {
+		return err
+	}
+	roots, err := util.ParseTrustBundle(rootCertBytes)
+	if err != nil {
+		return err
+	}
+	candidates := append(chain, roots...)
+
+	// Walk the chain up to its root, and check the other trusted roots, whose certificates are still accepted.
+	for cert := signingCert; !bytes.Equal(cert.RawIssuer, cert.RawSubject); {
+		var issuer *x509.Certificate
+		for _, c := range candidates {
+			if bytes.Equal(c.RawSubject, cert.RawIssuer) && cert.CheckSignatureFrom(c) == nil {
+				issuer = c
+				break
+			}
+		}
+		if issuer == nil {
+			return fmt.Errorf("the issuer %q of %q is not in the certificate chain", cert.Issuer, cert.Subject)
+		}
+		if err := covered(issuer); err != nil {
+			return err
+		}
+		cert = issuer
+	}
+	for _, root := range append(roots, signingCert) {
+		if err := covered(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func updateCertInConfigmap(namespace string, client corev1.CoreV1Interface, cert []byte) error {
 	certEncoded := base64.StdEncoding.EncodeToString(cert)
 	cmc := configmap.NewController(namespace, client)
@@ -17,9 +17,11 @@ package ca
 import (
 	"bytes"
 	"context"
+	"crypto"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
+	"io"
 	"io/ioutil"
 	"reflect"
 	"testing"
@@ -327,7 +329,7 @@ func TestCreatePluggedCertCA(t *testing.T) {
 	client := fake.NewSimpleClientset()
 
 	caopts, err := NewPluggedCertIstioCAOptions(certChainFile, signingCertFile, signingKeyFile, rootCertFile,
-		defaultWorkloadCertTTL, maxWorkloadCertTTL, caNamespace, client.CoreV1())
+		nil, defaultWorkloadCertTTL, maxWorkloadCertTTL, caNamespace, client.CoreV1())
 	if err != nil {
 		t.Fatalf("Failed to create a plugged-cert CA Options: %v", err)
 	}
@@ -451,7 +453,7 @@ func TestSignCSRForCA(t *testing.T) {
 	}
 
 	fields := &util.VerifyFields{
-		KeyUsage: x509.KeyUsageCertSign,
+		KeyUsage: x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
 		IsCA:     true,
 		Host:     subjectID,
 	}
@@ -510,6 +512,128 @@ func TestSignCSRTTLError(t *testing.T) {
 	}
 }
 
+func TestSignCSRKeyAlgorithmPolicy(t *testing.T) {
+	subjectID := "spiffe://example.com/ns/foo/sa/bar"
+	ca, err := createCA(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca.allowedKeyAlgorithms = []util.KeyAlgorithm{util.ECDSAP256}
+
+	testCases := map[string]struct {
+		keyAlgorithm util.KeyAlgorithm
+		forCA        bool
+		expectedErr  string
+	}{
+		"allowed key": {keyAlgorithm: util.ECDSAP256},
+		"disallowed key": {
+			keyAlgorithm: util.RSA2048,
+			expectedErr:  "key algorithm RSA_2048 is not allowed, the allowed algorithms are [ECDSA_P256]",
+		},
+		"CA certificate": {keyAlgorithm: util.RSA2048, forCA: true},
+	}
+	for id, tc := range testCases {
+		csrPEM, keyPEM, err := util.GenCSR(util.CertOptions{Host: subjectID, KeyAlgorithm: tc.keyAlgorithm})
+		if err != nil {
+			t.Fatal(err)
+		}
+		certPEM, signErr := ca.Sign(csrPEM, []string{subjectID}, time.Hour, tc.forCA)
+		if tc.expectedErr != "" {
+			if signErr == nil {
+				t.Errorf("%s: Sign() succeeded, expected error: %s", id, tc.expectedErr)
+			} else if signErr.(*caerror.Error).ErrorType() != "CSR_ERROR" || signErr.Error() != tc.expectedErr {
+				t.Errorf("%s: got error %v, expected CSR error: %s", id, signErr, tc.expectedErr)
+			}
+			continue
+		}
+		if signErr != nil {
+			t.Errorf("%s: Sign() failed: %v", id, signErr)
+			continue
+		}
+		if !tc.forCA {
+			fields := &util.VerifyFields{
+				ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+				KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+				Host:        subjectID,
+			}
+			if err := util.VerifyCertificate(keyPEM, append(certPEM, ca.GetCAKeyCertBundle().GetCertChainPem()...),
+				ca.GetCAKeyCertBundle().GetRootCertPem(), fields); err != nil {
+				t.Errorf("%s: failed to verify the certificate: %v", id, err)
+			}
+		}
+	}
+}
+
+// hsmSigner hides the type of the private key, like the signers of keys held by an HSM.
+type hsmSigner struct {
+	crypto.Signer
+	signed int
+}
+
+func (s *hsmSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s.signed++
+	return s.Signer.Sign(rand, digest, opts)
+}
+
+func TestCreatePluggedCertCAWithSigner(t *testing.T) {
+	rootCertFile := "../testdata/multilevelpki/root-cert.pem"
+	certChainFile := "../testdata/multilevelpki/int2-cert-chain.pem"
+	signingCertFile := "../testdata/multilevelpki/int2-cert.pem"
+	signingKeyFile := "../testdata/multilevelpki/int2-key.pem"
+	subjectID := "spiffe://example.com/ns/foo/sa/bar"
+
+	keyBytes, err := ioutil.ReadFile(signingKeyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := util.ParsePemEncodedKey(keyBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := &hsmSigner{Signer: key.(crypto.Signer)}
+
+	client := fake.NewSimpleClientset()
+	caopts, err := NewPluggedCertIstioCAOptionsWithSigner(certChainFile, signingCertFile, signer, rootCertFile,
+		nil, 30*time.Minute, time.Hour, "default", client.CoreV1())
+	if err != nil {
+		t.Fatalf("Failed to create a plugged-cert CA Options: %v", err)
+	}
+	ca, err := NewIstioCA(caopts)
+	if err != nil {
+		t.Fatalf("Got error while creating plugged-cert CA: %v", err)
+	}
+	if _, signingKeyBytes, _, _ := ca.GetCAKeyCertBundle().GetAllPem(); len(signingKeyBytes) != 0 {
+		t.Errorf("The CA exposes a signing key PEM for a key held by a signer")
+	}
+
+	csrPEM, keyPEM, err := util.GenCSR(util.CertOptions{Host: subjectID, RSAKeySize: 2048})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := signer.signed
+	certPEM, err := ca.SignWithCertChain(csrPEM, []string{subjectID}, time.Hour, false)
+	if err != nil {
+		t.Fatalf("Sign() failed: %v", err)
+	}
+	if signer.signed != signed+1 {
+		t.Errorf("The certificate was not signed by the signer")
+	}
+	fields := &util.VerifyFields{
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		Host:        subjectID,
+	}
+	if err = util.VerifyCertificate(keyPEM, certPEM, ca.GetCAKeyCertBundle().GetRootCertPem(), fields); err != nil {
+		t.Errorf("Failed to verify the certificate: %v", err)
+	}
+
+	// The key of another certificate is rejected.
+	if _, err = NewPluggedCertIstioCAOptionsWithSigner(certChainFile, "../testdata/multilevelpki/int-cert.pem", signer,
+		rootCertFile, nil, 30*time.Minute, time.Hour, "default", client.CoreV1()); err == nil {
+		t.Errorf("Created a plugged-cert CA with the signer of another key")
+	}
+}
+
 func TestAppendRootCerts(t *testing.T) {
 	root1 := "root-cert-1"
 	expRootCerts := `root-cert-1
@@ -551,7 +675,7 @@ func TestSignWithCertChain(t *testing.T) {
 	client := fake.NewSimpleClientset()
 
 	caopts, err := NewPluggedCertIstioCAOptions(certChainFile, signingCertFile, signingKeyFile, rootCertFile,
-		defaultWorkloadCertTTL, maxWorkloadCertTTL, caNamespace, client.CoreV1())
+		nil, defaultWorkloadCertTTL, maxWorkloadCertTTL, caNamespace, client.CoreV1())
 	if err != nil {
 		t.Fatalf("Failed to create a plugged-cert CA Options: %v", err)
 	}
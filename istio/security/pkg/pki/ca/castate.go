@@ -0,0 +1,128 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ca
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+const (
+	// CAStateSecret stores the state of the CA that is not part of its key/cert, e.g. the revoked certificates,
+	// next to the CASecret.
+	CAStateSecret = "istio-ca-state"
+	// caStateID is the key of the state in the CAStateSecret.
+	caStateID = "ca-state.json"
+)
+
+// caState is the persisted state of the CA.
+type caState struct {
+	Revoked []persistedRevokedCert `json:"revoked,omitempty"`
+
+	// Signer is the fingerprint of the certificate signing the issued certificates, SignerSince when it
+	// started signing them, and Issued the unexpired certificates issued to each identity. They make up the
+	// status of a migration from one root to another, and Issued lets an identity be revoked after a restart.
+	Signer      string                `json:"signer,omitempty"`
+	SignerSince time.Time             `json:"signerSince,omitempty"`
+	Issued      []persistedIssuedCert `json:"issued,omitempty"`
+}
+
+// persistedIssuedCert is the serialized form of an issuedCert.
+type persistedIssuedCert struct {
+	SerialNumber string    `json:"serialNumber"`
+	Identities   []string  `json:"identities"`
+	IssuedAt     time.Time `json:"issuedAt"`
+	NotAfter     time.Time `json:"notAfter"`
+	Signer       string    `json:"signer"`
+}
+
+// persistedRevokedCert is the serialized form of a revokedCert.
+type persistedRevokedCert struct {
+	SerialNumber string    `json:"serialNumber"`
+	RevokedAt    time.Time `json:"revokedAt"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+// stateStore persists the state of the CA.
+type stateStore interface {
+	// load returns the persisted state, or nil if there is none.
+	load() (*caState, error)
+	save(state *caState) error
+}
+
+// secretStateStore persists the state of the CA in the CAStateSecret of the CA namespace.
+type secretStateStore struct {
+	namespace string
+	client    corev1.CoreV1Interface
+}
+
+func newSecretStateStore(namespace string, client corev1.CoreV1Interface) stateStore {
+	if client == nil {
+		return nil
+	}
+	return &secretStateStore{namespace: namespace, client: client}
+}
+
+func (s *secretStateStore) load() (*caState, error) {
+	secret, err := s.client.Secrets(s.namespace).Get(CAStateSecret, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secret %s:%s (%v)", s.namespace, CAStateSecret, err)
+	}
+	data := secret.Data[caStateID]
+	if len(data) == 0 {
+		return nil, nil
+	}
+	state := &caState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse the state of secret %s:%s (%v)", s.namespace, CAStateSecret, err)
+	}
+	return state, nil
+}
+
+func (s *secretStateStore) save(state *caState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	secrets := s.client.Secrets(s.namespace)
+	secret, err := secrets.Get(CAStateSecret, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = secrets.Create(&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: CAStateSecret, Namespace: s.namespace},
+			Data:       map[string][]byte{caStateID: data},
+			Type:       istioCASecretType,
+		})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[caStateID] = data
+	_, err = secrets.Update(secret)
+	return err
+}
@@ -28,6 +28,13 @@ type FakeCA struct {
 	SignErr       *caerror.Error
 	KeyCertBundle util.KeyCertBundle
 	ReceivedIDs   []string
+
+	RevokedSerials    []string
+	RevokeErr         *caerror.Error
+	ReceivedSerials   []string
+	ReceivedSubjectID string
+	CRL               []byte
+	CRLErr            *caerror.Error
 }
 
 // Sign returns the SignErr if SignErr is not nil, otherwise, it returns SignedCert.
@@ -59,3 +66,21 @@ func (ca *FakeCA) GetCAKeyCertBundle() util.KeyCertBundle {
 	}
 	return ca.KeyCertBundle
 }
+
+// Revoke returns the RevokeErr if RevokeErr is not nil, otherwise, it returns RevokedSerials.
+func (ca *FakeCA) Revoke(serialNumbers []string, subjectID string) ([]string, error) {
+	ca.ReceivedSerials = serialNumbers
+	ca.ReceivedSubjectID = subjectID
+	if ca.RevokeErr != nil {
+		return nil, ca.RevokeErr
+	}
+	return ca.RevokedSerials, nil
+}
+
+// CertificateRevocationList returns the CRLErr if CRLErr is not nil, otherwise, it returns CRL.
+func (ca *FakeCA) CertificateRevocationList() ([]byte, error) {
+	if ca.CRLErr != nil {
+		return nil, ca.CRLErr
+	}
+	return ca.CRL, nil
+}
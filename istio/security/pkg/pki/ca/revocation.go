@@ -0,0 +1,429 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ca
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// crlPemType is the PEM block type of a certificate revocation list.
+const crlPemType = "X509 CRL"
+
+// issuedCert is a certificate issued by the CA that has not expired yet.
+type issuedCert struct {
+	serial     *big.Int
+	identities []string
+	issuedAt   time.Time
+	notAfter   time.Time
+	// signer is the fingerprint of the certificate that signed it.
+	signer string
+}
+
+// revokedCert is a certificate that was revoked before its expiration.
+type revokedCert struct {
+	serial    *big.Int
+	revokedAt time.Time
+	// expiresAt is when the certificate expires, after which it is no longer listed in the CRL.
+	expiresAt time.Time
+}
+
+// revocationList tracks the certificates issued by the CA per identity, and the ones revoked, so that a
+// signed certificate revocation list (CRL) can be published. The revoked and the unexpired issued certificates
+// are persisted in the store, if any, so that they are not forgotten when Citadel restarts.
+type revocationList struct {
+	mutex sync.Mutex
+
+	// store persists the certificates. storeMutex serializes the writes to the store, and generation and
+	// savedGeneration count the changes of the certificates and the last one persisted.
+	store           stateStore
+	storeMutex      sync.Mutex
+	generation      uint64
+	savedGeneration uint64
+
+	// issued certificates, by serial number.
+	issued map[string]*issuedCert
+	// serial numbers of the issued certificates, by identity.
+	byIdentity map[string]map[string]bool
+	// revoked certificates, by serial number.
+	revoked map[string]*revokedCert
+
+	// signer is the fingerprint of the certificate signing the issued certificates, and signerSince when it
+	// started signing them, or when the CA started for the first signer.
+	signer      string
+	signerSince time.Time
+
+	// maxCertTTL bounds the lifetime of revoked certificates the CA does not know about.
+	maxCertTTL time.Duration
+	// crlRefreshInterval is how often the workloads refresh the CRL. The generated CRLs are valid for
+	// crlRefreshMargin intervals, so that a few failed refreshes do not make the peers reject every certificate.
+	crlRefreshInterval time.Duration
+
+	// The last CRL generated for each signing certificate, which is reused for a refresh interval unless the
+	// list changes.
+	crls map[*x509.Certificate]*generatedCRL
+}
+
+// generatedCRL is a PEM-encoded CRL and when it was generated.
+type generatedCRL struct {
+	pem        []byte
+	thisUpdate time.Time
+}
+
+func newRevocationList(maxCertTTL, crlRefreshInterval time.Duration) *revocationList {
+	return &revocationList{
+		issued:             map[string]*issuedCert{},
+		byIdentity:         map[string]map[string]bool{},
+		revoked:            map[string]*revokedCert{},
+		crls:               map[*x509.Certificate]*generatedCRL{},
+		signerSince:        time.Now(),
+		maxCertTTL:         maxCertTTL,
+		crlRefreshInterval: crlRefreshInterval,
+	}
+}
+
+// load restores the revoked certificates and the issued certificates status persisted in the store.
+func (l *revocationList) load(store stateStore) error {
+	state, err := store.load()
+	if err != nil {
+		return err
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.store = store
+	if state == nil {
+		return nil
+	}
+	for _, p := range state.Revoked {
+		serial, err := ParseSerialNumber(p.SerialNumber)
+		if err != nil {
+			return fmt.Errorf("invalid persisted revoked certificate (%v)", err)
+		}
+		l.revoked[FormatSerialNumber(serial)] = &revokedCert{serial: serial, revokedAt: p.RevokedAt, expiresAt: p.ExpiresAt}
+	}
+	for _, p := range state.Issued {
+		serial, err := ParseSerialNumber(p.SerialNumber)
+		if err != nil {
+			return fmt.Errorf("invalid persisted issued certificate (%v)", err)
+		}
+		l.add(&issuedCert{
+			serial:     serial,
+			identities: p.Identities,
+			issuedAt:   p.IssuedAt,
+			notAfter:   p.NotAfter,
+			signer:     p.Signer,
+		})
+	}
+	if state.Signer != "" {
+		l.signer = state.Signer
+		l.signerSince = state.SignerSince
+	}
+	l.prune(time.Now())
+	l.crls = map[*x509.Certificate]*generatedCRL{}
+	return nil
+}
+
+// persist writes the state to the store if it changed since it was last written.
+func (l *revocationList) persist() error {
+	l.storeMutex.Lock()
+	defer l.storeMutex.Unlock()
+
+	l.mutex.Lock()
+	if l.store == nil || l.generation == l.savedGeneration {
+		l.mutex.Unlock()
+		return nil
+	}
+	generation := l.generation
+	state := l.state()
+	l.mutex.Unlock()
+
+	if err := l.store.save(state); err != nil {
+		return err
+	}
+
+	l.mutex.Lock()
+	l.savedGeneration = generation
+	l.mutex.Unlock()
+	return nil
+}
+
+// persistLoop persists the state at the given interval, since it is not written whenever a certificate is
+// issued, until stopCh is closed.
+func (l *revocationList) persistLoop(interval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-stopCh:
+			if err := l.persist(); err != nil {
+				pkiCaLog.Errorf("failed to persist the state of the CA: %v", err)
+			}
+			return
+		}
+		if err := l.persist(); err != nil {
+			pkiCaLog.Warnf("failed to persist the state of the CA: %v", err)
+		}
+	}
+}
+
+// state returns the state to persist. The caller must hold the mutex.
+func (l *revocationList) state() *caState {
+	revoked := make([]*revokedCert, 0, len(l.revoked))
+	for _, cert := range l.revoked {
+		revoked = append(revoked, cert)
+	}
+	sort.Slice(revoked, func(i, j int) bool { return revoked[i].serial.Cmp(revoked[j].serial) < 0 })
+
+	state := &caState{
+		Revoked:     make([]persistedRevokedCert, 0, len(revoked)),
+		Signer:      l.signer,
+		SignerSince: l.signerSince,
+	}
+	for _, cert := range revoked {
+		state.Revoked = append(state.Revoked, persistedRevokedCert{
+			SerialNumber: FormatSerialNumber(cert.serial),
+			RevokedAt:    cert.revokedAt,
+			ExpiresAt:    cert.expiresAt,
+		})
+	}
+
+	// Every unexpired certificate is kept, so that all the certificates of an identity can still be revoked
+	// after a restart: the previous certificate of a workload stays valid for a while after a rotation.
+	now := time.Now()
+	serials := make([]string, 0, len(l.issued))
+	for serial, cert := range l.issued {
+		if now.Before(cert.notAfter) {
+			serials = append(serials, serial)
+		}
+	}
+	sort.Strings(serials)
+	for _, serial := range serials {
+		cert := l.issued[serial]
+		state.Issued = append(state.Issued, persistedIssuedCert{
+			SerialNumber: serial,
+			Identities:   cert.identities,
+			IssuedAt:     cert.issuedAt,
+			NotAfter:     cert.notAfter,
+			Signer:       cert.signer,
+		})
+	}
+	return state
+}
+
+// FormatSerialNumber returns the representation of a certificate serial number used by the revocation API.
+func FormatSerialNumber(serial *big.Int) string {
+	return serial.Text(16)
+}
+
+// ParseSerialNumber parses a hexadecimal certificate serial number. Bytes may be separated by colons, as
+// printed by openssl.
+func ParseSerialNumber(s string) (*big.Int, error) {
+	serial, ok := new(big.Int).SetString(strings.Replace(strings.TrimSpace(s), ":", "", -1), 16)
+	if !ok || serial.Sign() < 0 {
+		return nil, fmt.Errorf("invalid serial number %q", s)
+	}
+	return serial, nil
+}
+
+// record adds a newly issued certificate, signed by the certificate with the given fingerprint.
+func (l *revocationList) record(cert *x509.Certificate, identities []string, signer string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	l.updateSigner(signer, now)
+	l.generation++
+	l.add(&issuedCert{
+		serial:     cert.SerialNumber,
+		identities: identities,
+		issuedAt:   now,
+		notAfter:   cert.NotAfter,
+		signer:     signer,
+	})
+}
+
+// add adds an issued certificate. The caller must hold the mutex.
+func (l *revocationList) add(cert *issuedCert) {
+	serial := FormatSerialNumber(cert.serial)
+	l.issued[serial] = cert
+	for _, id := range cert.identities {
+		serials, found := l.byIdentity[id]
+		if !found {
+			serials = map[string]bool{}
+			l.byIdentity[id] = serials
+		}
+		serials[serial] = true
+	}
+}
+
+// issuedTo returns the serial numbers of the unexpired certificates issued to the identity.
+func (l *revocationList) issuedTo(identity string) []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.prune(time.Now())
+	serials := make([]string, 0, len(l.byIdentity[identity]))
+	for serial := range l.byIdentity[identity] {
+		serials = append(serials, serial)
+	}
+	sort.Strings(serials)
+	return serials
+}
+
+// updateSigner records that the certificate with the given fingerprint is now signing the issued certificates.
+// The caller must hold the mutex.
+func (l *revocationList) updateSigner(signer string, now time.Time) {
+	if signer == l.signer {
+		return
+	}
+	if l.signer != "" {
+		l.signerSince = now
+	}
+	l.signer = signer
+	l.generation++
+}
+
+// revoke revokes the certificates with the given serial numbers, as well as all the unexpired certificates
+// issued to identity if it is not empty, and persists them. It returns the serial numbers of the certificates
+// newly revoked, or an error if no unexpired certificate issued to identity is known. The certificates stay
+// revoked if they can't be persisted, and a later call retries to.
+func (l *revocationList) revoke(serials []string, identity string) ([]string, error) {
+	parsed := make([]*big.Int, 0, len(serials))
+	for _, s := range serials {
+		serial, err := ParseSerialNumber(s)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, serial)
+	}
+
+	revoked, err := l.revokeSerials(parsed, identity)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.persist(); err != nil {
+		return revoked, fmt.Errorf("failed to persist the revoked certificates: %v", err)
+	}
+	return revoked, nil
+}
+
+// revokeSerials revokes the certificates with the given serial numbers and the ones issued to identity.
+func (l *revocationList) revokeSerials(parsed []*big.Int, identity string) ([]string, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	l.prune(now)
+
+	if identity != "" {
+		if len(l.byIdentity[identity]) == 0 {
+			return nil, fmt.Errorf("no unexpired certificate issued to %s is known", identity)
+		}
+		for serial := range l.byIdentity[identity] {
+			parsed = append(parsed, l.issued[serial].serial)
+		}
+	}
+
+	revoked := make([]string, 0, len(parsed))
+	for _, serial := range parsed {
+		key := FormatSerialNumber(serial)
+		if _, found := l.revoked[key]; found {
+			continue
+		}
+		// Certificates issued before the CA started are not known; they are listed for as long as a
+		// certificate issued now could be valid.
+		expiresAt := now.Add(l.maxCertTTL)
+		if cert, found := l.issued[key]; found {
+			expiresAt = cert.notAfter
+		}
+		l.revoked[key] = &revokedCert{serial: serial, revokedAt: now, expiresAt: expiresAt}
+		revoked = append(revoked, key)
+	}
+
+	if len(revoked) > 0 {
+		l.crls = map[*x509.Certificate]*generatedCRL{}
+		l.generation++
+	}
+	sort.Strings(revoked)
+	return revoked, nil
+}
+
+// prune drops the certificates that expired, since they no longer need to be listed. The caller must hold
+// the mutex.
+func (l *revocationList) prune(now time.Time) {
+	for serial, cert := range l.issued {
+		if now.Before(cert.notAfter) {
+			continue
+		}
+		delete(l.issued, serial)
+		for _, id := range cert.identities {
+			delete(l.byIdentity[id], serial)
+			if len(l.byIdentity[id]) == 0 {
+				delete(l.byIdentity, id)
+			}
+		}
+	}
+	for serial, cert := range l.revoked {
+		if now.After(cert.expiresAt) {
+			delete(l.revoked, serial)
+			l.crls = map[*x509.Certificate]*generatedCRL{}
+			l.generation++
+		}
+	}
+}
+
+// crlPEM returns the PEM-encoded CRL of the revoked certificates, signed with the given key.
+func (l *revocationList) crlPEM(signingCert *x509.Certificate, signingKey crypto.PrivateKey) ([]byte, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	l.prune(now)
+	if crl, found := l.crls[signingCert]; found && now.Before(crl.thisUpdate.Add(l.crlRefreshInterval)) {
+		return crl.pem, nil
+	}
+
+	revoked := make([]pkix.RevokedCertificate, 0, len(l.revoked))
+	for _, cert := range l.revoked {
+		revoked = append(revoked, pkix.RevokedCertificate{
+			SerialNumber:   cert.serial,
+			RevocationTime: cert.revokedAt,
+		})
+	}
+	sort.Slice(revoked, func(i, j int) bool { return revoked[i].SerialNumber.Cmp(revoked[j].SerialNumber) < 0 })
+
+	// Backdate the CRL to tolerate clock skew, the same way certificates are.
+	thisUpdate := now.Add(-time.Minute)
+	nextUpdate := now.Add(crlRefreshMargin * l.crlRefreshInterval)
+	der, err := signingCert.CreateCRL(rand.Reader, signingKey, revoked, thisUpdate, nextUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	crl := &generatedCRL{pem: pem.EncodeToMemory(&pem.Block{Type: crlPemType, Bytes: der}), thisUpdate: now}
+	l.crls[signingCert] = crl
+	return crl.pem, nil
+}
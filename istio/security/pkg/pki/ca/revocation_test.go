@@ -0,0 +1,421 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ca
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+
+	caerror "istio.io/istio/security/pkg/pki/error"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+// createRevocationCA returns a CA signing with an intermediate certificate, the file of the CRL of its root, and
+// a function removing the file.
+func createRevocationCA(t *testing.T, opts *IstioCAOptions) (*IstioCA, string, func()) {
+	t.Helper()
+	rootCertPEM, rootKeyPEM, err := util.GenCertKeyFromOptions(util.CertOptions{
+		IsCA: true, IsSelfSigned: true, TTL: time.Hour, Org: "Root CA", RSAKeySize: 2048})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootCert, err := util.ParsePemEncodedCertificate(rootCertPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootKey, err := util.ParsePemEncodedKey(rootKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, keyPEM, err := util.GenCertKeyFromOptions(util.CertOptions{
+		IsCA: true, TTL: time.Hour, Org: "Intermediate CA", RSAKeySize: 2048, SignerCert: rootCert, SignerPriv: rootKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.KeyCertBundle, err = util.NewVerifiedKeyCertBundleFromPem(certPEM, keyPEM, certPEM, rootCertPEM); err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := rootCert.CreateCRL(rand.Reader, rootKey, nil, time.Now().Add(-time.Minute), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+	crlFile := filepath.Join(dir, "chain-crl.pem")
+	if err = ioutil.WriteFile(crlFile, pem.EncodeToMemory(&pem.Block{Type: crlPemType, Bytes: der}), 0644); err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	opts.CAType = pluggedCertCA
+	if opts.MaxCertTTL == 0 {
+		opts.CertTTL, opts.MaxCertTTL = time.Hour, time.Hour
+	}
+	if opts.ChainCRLFile == "" {
+		opts.ChainCRLFile = crlFile
+	}
+	ca, err := NewIstioCA(opts)
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	return ca, crlFile, cleanup
+}
+
+func signWorkloadCert(t *testing.T, ca *IstioCA, subjectID string) *x509.Certificate {
+	t.Helper()
+	csrPEM, _, err := util.GenCSR(util.CertOptions{Host: subjectID, RSAKeySize: 2048})
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, err := ca.Sign(csrPEM, []string{subjectID}, 30*time.Minute, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := util.ParsePemEncodedCertificate(certPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func parseCRL(t *testing.T, ca *IstioCA) []string {
+	t.Helper()
+	crlPEM, err := ca.CertificateRevocationList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(crlPEM)
+	if block == nil || block.Type != crlPemType {
+		t.Fatalf("invalid CRL PEM %q", string(crlPEM))
+	}
+	crl, err := x509.ParseCRL(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingCert, _, _, _ := ca.GetCAKeyCertBundle().GetAll()
+	if err := signingCert.CheckCRLSignature(crl); err != nil {
+		t.Errorf("CRL signature verification failed: %v", err)
+	}
+	if rest, _ := pem.Decode(crlPEM[len(pem.EncodeToMemory(block)):]); rest == nil {
+		t.Error("Expected the CRL of the root to follow the CRL of the CA")
+	}
+	if !crl.TBSCertList.NextUpdate.After(time.Now()) {
+		t.Errorf("CRL already expired at %v", crl.TBSCertList.NextUpdate)
+	}
+
+	serials := []string{}
+	for _, c := range crl.TBSCertList.RevokedCertificates {
+		serials = append(serials, FormatSerialNumber(c.SerialNumber))
+	}
+	sort.Strings(serials)
+	return serials
+}
+
+func TestRevokeBySerialNumber(t *testing.T) {
+	ca, _, cleanup := createRevocationCA(t, &IstioCAOptions{})
+	defer cleanup()
+	cert1 := signWorkloadCert(t, ca, "spiffe://cluster.local/ns/foo/sa/a")
+	signWorkloadCert(t, ca, "spiffe://cluster.local/ns/foo/sa/b")
+
+	if got := parseCRL(t, ca); len(got) != 0 {
+		t.Errorf("Got revoked serials %v in the initial CRL, want none", got)
+	}
+
+	serial := FormatSerialNumber(cert1.SerialNumber)
+	revoked, err := ca.Revoke([]string{serial}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(revoked, []string{serial}) {
+		t.Errorf("Revoke() returned %v, want %v", revoked, []string{serial})
+	}
+	if got := parseCRL(t, ca); !reflect.DeepEqual(got, []string{serial}) {
+		t.Errorf("Got revoked serials %v, want %v", got, []string{serial})
+	}
+
+	// Revoking the same certificate again is a no-op.
+	if revoked, err = ca.Revoke([]string{serial}, ""); err != nil || len(revoked) != 0 {
+		t.Errorf("Revoke() returned (%v, %v), want nothing newly revoked", revoked, err)
+	}
+}
+
+func TestRevokeByIdentity(t *testing.T) {
+	ca, _, cleanup := createRevocationCA(t, &IstioCAOptions{})
+	defer cleanup()
+	id := "spiffe://cluster.local/ns/foo/sa/a"
+	cert1 := signWorkloadCert(t, ca, id)
+	cert2 := signWorkloadCert(t, ca, id)
+	signWorkloadCert(t, ca, "spiffe://cluster.local/ns/foo/sa/b")
+
+	want := []string{FormatSerialNumber(cert1.SerialNumber), FormatSerialNumber(cert2.SerialNumber)}
+	sort.Strings(want)
+	if got := ca.revocations.issuedTo(id); !reflect.DeepEqual(got, want) {
+		t.Errorf("Got issued serials %v, want %v", got, want)
+	}
+
+	revoked, err := ca.Revoke(nil, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(revoked, want) {
+		t.Errorf("Revoke() returned %v, want %v", revoked, want)
+	}
+	if got := parseCRL(t, ca); !reflect.DeepEqual(got, want) {
+		t.Errorf("Got revoked serials %v, want %v", got, want)
+	}
+}
+
+func TestRevokeByIdentityAfterRestart(t *testing.T) {
+	store := newSecretStateStore("istio-system", fake.NewSimpleClientset().CoreV1())
+	ca, crlFile, cleanup := createRevocationCA(t, &IstioCAOptions{stateStore: store})
+	defer cleanup()
+	id := "spiffe://cluster.local/ns/foo/sa/a"
+	// the previous certificate of the identity is still valid after its rotation.
+	cert1 := signWorkloadCert(t, ca, id)
+	cert2 := signWorkloadCert(t, ca, id)
+	if err := ca.revocations.persist(); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted, err := NewIstioCA(&IstioCAOptions{
+		CAType:        pluggedCertCA,
+		CertTTL:       time.Hour,
+		MaxCertTTL:    time.Hour,
+		KeyCertBundle: ca.GetCAKeyCertBundle(),
+		ChainCRLFile:  crlFile,
+		stateStore:    store,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{FormatSerialNumber(cert1.SerialNumber), FormatSerialNumber(cert2.SerialNumber)}
+	sort.Strings(want)
+	revoked, err := restarted.Revoke(nil, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(revoked, want) {
+		t.Errorf("Revoke() returned %v after a restart, want %v", revoked, want)
+	}
+}
+
+func TestRevokeErrors(t *testing.T) {
+	ca, _, cleanup := createRevocationCA(t, &IstioCAOptions{})
+	defer cleanup()
+	cases := map[string]struct {
+		serials []string
+		id      string
+	}{
+		"nothing to revoke":     {},
+		"invalid serial number": {serials: []string{"not-hex"}},
+		"negative serial":       {serials: []string{"-1f"}},
+		"unknown identity":      {id: "spiffe://cluster.local/ns/foo/sa/unknown"},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ca.Revoke(c.serials, c.id)
+			if err == nil {
+				t.Fatal("Revoke() succeeded, want error")
+			}
+			if caErr, ok := err.(*caerror.Error); !ok || caErr.ErrorType() != "REVOCATION_ERROR" {
+				t.Errorf("Got error %v, want a REVOCATION_ERROR error", err)
+			}
+		})
+	}
+}
+
+func TestParseSerialNumber(t *testing.T) {
+	cases := map[string]int64{
+		"1f":          0x1f,
+		"1F":          0x1f,
+		"01:02:0a":    0x01020a,
+		" abcdef12 ":  0xabcdef12,
+		"0000000001f": 0x1f,
+	}
+	for in, want := range cases {
+		got, err := ParseSerialNumber(in)
+		if err != nil {
+			t.Errorf("ParseSerialNumber(%q) failed: %v", in, err)
+			continue
+		}
+		if got.Cmp(big.NewInt(want)) != 0 {
+			t.Errorf("ParseSerialNumber(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestRevocationListPrune(t *testing.T) {
+	l := newRevocationList(time.Hour, time.Minute)
+	id := "spiffe://cluster.local/ns/foo/sa/a"
+	now := time.Now()
+	l.record(&x509.Certificate{SerialNumber: big.NewInt(1), NotAfter: now.Add(-time.Minute)}, []string{id}, "signer")
+	l.record(&x509.Certificate{SerialNumber: big.NewInt(2), NotAfter: now.Add(time.Hour)}, []string{id}, "signer")
+
+	if got := l.issuedTo(id); !reflect.DeepEqual(got, []string{"2"}) {
+		t.Errorf("Got issued serials %v, want [2]", got)
+	}
+	if _, err := l.revoke([]string{"2", "3"}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	l.mutex.Lock()
+	l.prune(now.Add(2 * time.Hour))
+	remaining := len(l.revoked) + len(l.issued) + len(l.byIdentity)
+	l.mutex.Unlock()
+	if remaining != 0 {
+		t.Errorf("Got %d entries after all certificates expired, want none", remaining)
+	}
+}
+
+func TestCRLCaching(t *testing.T) {
+	ca, _, cleanup := createRevocationCA(t, &IstioCAOptions{})
+	defer cleanup()
+	crl1, err := ca.CertificateRevocationList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	crl2, err := ca.CertificateRevocationList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(crl1) != string(crl2) {
+		t.Error("Expected the CRL to be reused while nothing changed")
+	}
+	if _, err := ca.Revoke([]string{"1234"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	crl3, err := ca.CertificateRevocationList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(crl1) == string(crl3) {
+		t.Error("Expected a new CRL after a revocation")
+	}
+}
+
+func TestCRLNextUpdate(t *testing.T) {
+	ca, _, cleanup := createRevocationCA(t, &IstioCAOptions{CRLRefreshInterval: time.Minute})
+	defer cleanup()
+	crlPEM, err := ca.CertificateRevocationList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(crlPEM)
+	crl, err := x509.ParseCRL(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validity := crl.TBSCertList.NextUpdate.Sub(time.Now())
+	if validity > crlRefreshMargin*time.Minute || validity < (crlRefreshMargin-1)*time.Minute {
+		t.Errorf("Got a CRL valid for %v, want %d refresh intervals", validity, crlRefreshMargin)
+	}
+}
+
+func TestCRLChainCoverage(t *testing.T) {
+	ca, crlFile, cleanup := createRevocationCA(t, &IstioCAOptions{})
+	defer cleanup()
+
+	ca.chainCRLFile = ""
+	if _, err := ca.CertificateRevocationList(); err == nil {
+		t.Error("Expected an error without the CRL of the root")
+	}
+
+	// A CRL signed by another CA does not cover the root.
+	otherPEM, otherKeyPEM, err := util.GenCertKeyFromOptions(util.CertOptions{
+		IsCA: true, IsSelfSigned: true, TTL: time.Hour, Org: "Other CA", RSAKeySize: 2048})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := util.ParsePemEncodedCertificate(otherPEM)
+	otherKey, _ := util.ParsePemEncodedKey(otherKeyPEM)
+	der, err := other.CreateCRL(rand.Reader, otherKey, nil, time.Now().Add(-time.Minute), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherFile := filepath.Join(filepath.Dir(crlFile), "other-crl.pem")
+	if err = ioutil.WriteFile(otherFile, pem.EncodeToMemory(&pem.Block{Type: crlPemType, Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ca.chainCRLFile = otherFile
+	if _, err := ca.CertificateRevocationList(); err == nil {
+		t.Error("Expected an error with a CRL of another CA")
+	}
+
+	ca.chainCRLFile = crlFile
+	if _, err := ca.CertificateRevocationList(); err != nil {
+		t.Errorf("CertificateRevocationList() failed with the CRL of the root: %v", err)
+	}
+}
+
+func TestRevocationPersistence(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	store := newSecretStateStore("istio-system", client.CoreV1())
+	ca, crlFile, cleanup := createRevocationCA(t, &IstioCAOptions{stateStore: store})
+	defer cleanup()
+	cert := signWorkloadCert(t, ca, "spiffe://cluster.local/ns/foo/sa/a")
+	serial := FormatSerialNumber(cert.SerialNumber)
+	if _, err := ca.Revoke([]string{serial}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// A restarted CA still lists the revoked certificate.
+	restarted, err := NewIstioCA(&IstioCAOptions{
+		CAType:        pluggedCertCA,
+		CertTTL:       time.Hour,
+		MaxCertTTL:    time.Hour,
+		KeyCertBundle: ca.GetCAKeyCertBundle(),
+		ChainCRLFile:  crlFile,
+		stateStore:    store,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parseCRL(t, restarted); !reflect.DeepEqual(got, []string{serial}) {
+		t.Errorf("Got revoked serials %v after a restart, want %v", got, []string{serial})
+	}
+
+	// Failures to persist are reported, and retried by the next revocation.
+	client.PrependReactor("update", "secrets", func(action ktesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("injected error")
+	})
+	if _, err := restarted.Revoke([]string{"1234"}, ""); err == nil {
+		t.Error("Expected an error when the revocation can't be persisted")
+	}
+	if got := parseCRL(t, restarted); !reflect.DeepEqual(got, []string{"1234", serial}) {
+		t.Errorf("Got revoked serials %v, want the unpersisted revocation to be listed", got)
+	}
+	if _, err := restarted.Revoke([]string{"1234"}, ""); err == nil {
+		t.Error("Expected the unpersisted revocation to be retried")
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ca
+
+import (
+	"crypto/x509"
+	"fmt"
+	"sort"
+	"time"
+
+	caerror "istio.io/istio/security/pkg/pki/error"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+// CertSummary identifies a certificate of the CA.
+type CertSummary struct {
+	Subject     string    `json:"subject"`
+	Fingerprint string    `json:"fingerprint"`
+	NotAfter    time.Time `json:"notAfter"`
+}
+
+// IdentityCertStatus describes the latest unexpired certificate the CA issued to an identity.
+type IdentityCertStatus struct {
+	Identity     string    `json:"identity"`
+	SerialNumber string    `json:"serialNumber"`
+	NotAfter     time.Time `json:"notAfter"`
+	// Signer is the fingerprint of the certificate that signed it.
+	Signer string `json:"signer"`
+	// Migrated is true if it was signed by the current signing certificate.
+	Migrated bool `json:"migrated"`
+}
+
+// TrustBundleStatus reports the roots trusted by the workloads and which identities received a certificate
+// from the current signing certificate, to follow a migration from one root to another. The status is persisted
+// with the state of the CA; without a state store, only the certificates issued since the CA started are known.
+type TrustBundleStatus struct {
+	SigningCert  CertSummary          `json:"signingCert"`
+	TrustedRoots []CertSummary        `json:"trustedRoots"`
+	Identities   []IdentityCertStatus `json:"identities"`
+	Migrated     int                  `json:"migrated"`
+	Pending      int                  `json:"pending"`
+	// PreviousSignersExpireBy is when all the certificates signed before the current signing certificate was
+	// in use have expired. The roots that are not part of its chain can be removed from the trust bundle after.
+	PreviousSignersExpireBy time.Time `json:"previousSignersExpireBy"`
+}
+
+func summarizeCert(cert *x509.Certificate) CertSummary {
+	return CertSummary{
+		Subject:     cert.Subject.String(),
+		Fingerprint: util.CertFingerprint(cert),
+		NotAfter:    cert.NotAfter,
+	}
+}
+
+// TrustBundleStatus returns the status of the trust bundle distributed by the CA.
+func (ca *IstioCA) TrustBundleStatus() (*TrustBundleStatus, error) {
+	signingCert, _, _, rootCertBytes := ca.keyCertBundle.GetAll()
+	if signingCert == nil {
+		return nil, caerror.NewError(caerror.CANotReady, fmt.Errorf("Istio CA is not ready")) // nolint
+	}
+	roots, err := util.ParseTrustBundle(rootCertBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &TrustBundleStatus{
+		SigningCert:  summarizeCert(signingCert),
+		TrustedRoots: make([]CertSummary, 0, len(roots)),
+	}
+	for _, root := range roots {
+		status.TrustedRoots = append(status.TrustedRoots, summarizeCert(root))
+	}
+	var since time.Time
+	status.Identities, since = ca.revocations.identityStatus(status.SigningCert.Fingerprint)
+	for _, s := range status.Identities {
+		if s.Migrated {
+			status.Migrated++
+		} else {
+			status.Pending++
+		}
+	}
+	status.PreviousSignersExpireBy = since.Add(ca.maxCertTTL)
+	return status, nil
+}
+
+// identityStatus returns the latest unexpired certificate issued to each identity, sorted by identity, and
+// since when the certificate with the given fingerprint has been signing.
+func (l *revocationList) identityStatus(signer string) ([]IdentityCertStatus, time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	l.prune(now)
+	l.updateSigner(signer, now)
+
+	latest := map[string]*issuedCert{}
+	for _, cert := range l.issued {
+		for _, id := range cert.identities {
+			if cur, found := latest[id]; !found || cert.issuedAt.After(cur.issuedAt) {
+				latest[id] = cert
+			}
+		}
+	}
+	statuses := make([]IdentityCertStatus, 0, len(latest))
+	for id, cert := range latest {
+		statuses = append(statuses, IdentityCertStatus{
+			Identity:     id,
+			SerialNumber: FormatSerialNumber(cert.serial),
+			NotAfter:     cert.notAfter,
+			Signer:       cert.signer,
+			Migrated:     cert.signer == signer,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Identity < statuses[j].Identity })
+	return statuses, l.signerSince
+}
@@ -0,0 +1,253 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ca
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+
+	"istio.io/istio/security/pkg/k8s/configmap"
+	k8ssecret "istio.io/istio/security/pkg/k8s/secret"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+func genSelfSignedRoot(t *testing.T, org string) (cert, key []byte) {
+	t.Helper()
+	cert, key, err := util.GenCertKeyFromOptions(util.CertOptions{
+		IsCA:         true,
+		IsSelfSigned: true,
+		TTL:          time.Hour,
+		Org:          org,
+		RSAKeySize:   2048,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestPluggedCertCAWithSelfSignedRoot(t *testing.T) {
+	rootCertFile := "../testdata/multilevelpki/root-cert.pem"
+	certChainFile := "../testdata/multilevelpki/int2-cert-chain.pem"
+	signingCertFile := "../testdata/multilevelpki/int2-cert.pem"
+	signingKeyFile := "../testdata/multilevelpki/int2-key.pem"
+	caNamespace := "default"
+
+	selfSignedRoot, selfSignedKey := genSelfSignedRoot(t, "cluster.local")
+	client := fake.NewSimpleClientset()
+	if _, err := LoadSelfSignedCARootCert(caNamespace, client.CoreV1()); err == nil {
+		t.Error("LoadSelfSignedCARootCert() succeeded without istio-ca-secret, want error")
+	}
+	secret := k8ssecret.BuildSecret("", CASecret, caNamespace, nil, nil, nil, selfSignedRoot, selfSignedKey, istioCASecretType)
+	if _, err := client.CoreV1().Secrets(caNamespace).Create(secret); err != nil {
+		t.Fatal(err)
+	}
+	trustedRoots, err := LoadSelfSignedCARootCert(caNamespace, client.CoreV1())
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := LoadSelfSignedCACRLSigner(caNamespace, client.CoreV1())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := util.ParsePemEncodedCertificate(selfSignedRoot); !signer.Cert.Equal(got) || signer.Key == nil {
+		t.Error("LoadSelfSignedCACRLSigner() did not return the self-signed CA")
+	}
+
+	caopts, err := NewPluggedCertIstioCAOptions(certChainFile, signingCertFile, signingKeyFile, rootCertFile,
+		trustedRoots, 30*time.Minute, time.Hour, caNamespace, client.CoreV1())
+	if err != nil {
+		t.Fatalf("Failed to create a plugged-cert CA Options: %v", err)
+	}
+	ca, err := NewIstioCA(caopts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roots, err := util.ParseTrustBundle(ca.GetCAKeyCertBundle().GetRootCertPem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	selfSignedCert, err := util.ParsePemEncodedCertificate(selfSignedRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots) != 2 || !roots[1].Equal(selfSignedCert) {
+		t.Fatalf("Got %d roots, want the plugged root followed by the self-signed root", len(roots))
+	}
+
+	// The node agents must trust both roots too.
+	cmc := configmap.NewController(caNamespace, client.CoreV1())
+	encoded, err := cmc.GetCATLSRootCert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromConfigMap, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certs, err := util.ParseTrustBundle(fromConfigMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !certs[len(certs)-1].Equal(selfSignedCert) {
+		t.Error("The self-signed root is missing from the configmap")
+	}
+}
+
+func TestTrustBundleStatus(t *testing.T) {
+	ca, err := createCA(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idA := "spiffe://cluster.local/ns/foo/sa/a"
+	idB := "spiffe://cluster.local/ns/foo/sa/b"
+	signWorkloadCert(t, ca, idA)
+	signWorkloadCert(t, ca, idB)
+
+	status, err := ca.TrustBundleStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Migrated != 2 || status.Pending != 0 {
+		t.Errorf("Got %d migrated and %d pending identities, want 2 and 0", status.Migrated, status.Pending)
+	}
+	if len(status.TrustedRoots) != 1 {
+		t.Errorf("Got %d trusted roots, want 1", len(status.TrustedRoots))
+	}
+
+	// Switch to a new root, keeping the previous one in the trust bundle.
+	oldRoot := ca.GetCAKeyCertBundle().GetRootCertPem()
+	newRoot, newKey := genSelfSignedRoot(t, "new root")
+	trustBundle, err := util.MergeTrustBundles(time.Now(), newRoot, oldRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ca.GetCAKeyCertBundle().VerifyAndSetAll(newRoot, newKey, nil, trustBundle); err != nil {
+		t.Fatal(err)
+	}
+	rotation := time.Now()
+	signWorkloadCert(t, ca, idA)
+
+	status, err = ca.TrustBundleStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.TrustedRoots) != 2 {
+		t.Errorf("Got %d trusted roots, want 2", len(status.TrustedRoots))
+	}
+	if status.Migrated != 1 || status.Pending != 1 {
+		t.Errorf("Got %d migrated and %d pending identities, want 1 and 1", status.Migrated, status.Pending)
+	}
+	if len(status.Identities) != 2 || status.Identities[0].Identity != idA || !status.Identities[0].Migrated ||
+		status.Identities[1].Identity != idB || status.Identities[1].Migrated {
+		t.Fatalf("Got identities %+v, want %s migrated and %s pending", status.Identities, idA, idB)
+	}
+	if status.Identities[0].Signer != status.SigningCert.Fingerprint {
+		t.Errorf("Got signer %s, want %s", status.Identities[0].Signer, status.SigningCert.Fingerprint)
+	}
+	if status.PreviousSignersExpireBy.Before(rotation.Add(time.Hour)) {
+		t.Errorf("Got previous signers expiration %v, want at least an hour after the rotation at %v",
+			status.PreviousSignersExpireBy, rotation)
+	}
+}
+
+func TestTrustBundleStatusPersistence(t *testing.T) {
+	store := newSecretStateStore("istio-system", fake.NewSimpleClientset().CoreV1())
+	ca, crlFile, cleanup := createRevocationCA(t, &IstioCAOptions{stateStore: store})
+	defer cleanup()
+	idA := "spiffe://cluster.local/ns/foo/sa/a"
+	idB := "spiffe://cluster.local/ns/foo/sa/b"
+	signWorkloadCert(t, ca, idA)
+	signWorkloadCert(t, ca, idB)
+	want, err := ca.TrustBundleStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ca.revocations.persist(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A restarted CA reports the same migration status.
+	restarted, err := NewIstioCA(&IstioCAOptions{
+		CAType:        pluggedCertCA,
+		CertTTL:       time.Hour,
+		MaxCertTTL:    time.Hour,
+		KeyCertBundle: ca.GetCAKeyCertBundle(),
+		ChainCRLFile:  crlFile,
+		stateStore:    store,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := restarted.TrustBundleStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Migrated != 2 || len(got.Identities) != 2 || !reflect.DeepEqual(got.Identities, want.Identities) {
+		t.Errorf("Got identities %+v after a restart, want %+v", got.Identities, want.Identities)
+	}
+	if !got.PreviousSignersExpireBy.Equal(want.PreviousSignersExpireBy) {
+		t.Errorf("Got previous signers expiration %v after a restart, want %v",
+			got.PreviousSignersExpireBy, want.PreviousSignersExpireBy)
+	}
+}
+
+func TestCRLOfPreviousSigner(t *testing.T) {
+	ca, _, cleanup := createRevocationCA(t, &IstioCAOptions{})
+	defer cleanup()
+
+	// Keep trusting an old root, e.g. the self-signed CA the mesh migrates from.
+	oldRootPEM, oldKeyPEM := genSelfSignedRoot(t, "old root")
+	bundle := ca.GetCAKeyCertBundle()
+	certPEM, keyPEM, chainPEM, rootPEM := bundle.GetAllPem()
+	trustBundle, err := util.MergeTrustBundles(time.Now(), rootPEM, oldRootPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = bundle.VerifyAndSetAll(certPEM, keyPEM, chainPEM, trustBundle); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ca.CertificateRevocationList(); err == nil {
+		t.Error("Expected an error without a CRL of the old root")
+	}
+
+	oldRoot, _ := util.ParsePemEncodedCertificate(oldRootPEM)
+	oldKey, _ := util.ParsePemEncodedKey(oldKeyPEM)
+	ca.previousCRLSigners = []CRLSigner{{Cert: oldRoot, Key: oldKey}}
+	crls, err := ca.CertificateRevocationList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedByOldRoot := false
+	for rest := crls; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		if crl, err := x509.ParseCRL(block.Bytes); err == nil && oldRoot.CheckCRLSignature(crl) == nil {
+			signedByOldRoot = true
+		}
+	}
+	if !signedByOldRoot {
+		t.Error("Expected a CRL signed by the old root")
+	}
+}
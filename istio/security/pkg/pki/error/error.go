@@ -28,6 +28,10 @@ const (
 	TTLError
 	// CertGenError means an error happened during the certificate generation.
 	CertGenError
+	// RevocationError means the CA cannot revoke a certificate due to an invalid request.
+	RevocationError
+	// CRLGenError means an error happened during the certificate revocation list generation.
+	CRLGenError
 )
 
 // Error encapsulates the short and long errors.
@@ -52,6 +56,10 @@ func (e Error) ErrorType() string {
 		return "TTL_ERROR"
 	case CertGenError:
 		return "CERT_GEN_ERROR"
+	case RevocationError:
+		return "REVOCATION_ERROR"
+	case CRLGenError:
+		return "CRL_GEN_ERROR"
 	}
 	return "UNKNOWN"
 }
@@ -67,6 +75,10 @@ func (e Error) HTTPErrorCode() codes.Code {
 		return codes.InvalidArgument
 	case TTLError:
 		return codes.InvalidArgument
+	case RevocationError:
+		return codes.InvalidArgument
+	case CRLGenError:
+		return codes.Internal
 	}
 	return codes.Internal
 }
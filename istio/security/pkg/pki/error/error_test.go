@@ -52,6 +52,18 @@ func TestError(t *testing.T) {
 			message: "CERT_GEN_ERROR",
 			code:    codes.Internal,
 		},
+		"REVOCATION_ERROR": {
+			eType:   RevocationError,
+			err:     fmt.Errorf("test error6"),
+			message: "REVOCATION_ERROR",
+			code:    codes.InvalidArgument,
+		},
+		"CRL_GEN_ERROR": {
+			eType:   CRLGenError,
+			err:     fmt.Errorf("test error7"),
+			message: "CRL_GEN_ERROR",
+			code:    codes.Internal,
+		},
 		"UNKNOWN": {
 			eType:   -1,
 			err:     fmt.Errorf("test error5"),
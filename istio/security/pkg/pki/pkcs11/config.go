@@ -0,0 +1,54 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pkcs11 provides signers for private keys held by a PKCS#11 token, e.g. an HSM, so that Citadel can sign
+// certificates without the CA key leaving the token.
+package pkcs11
+
+import (
+	"crypto"
+	"fmt"
+	"io"
+)
+
+// Config identifies a private key in a PKCS#11 token.
+type Config struct {
+	// Module is the path of the PKCS#11 module of the token, e.g. /usr/lib/softhsm/libsofthsm2.so.
+	Module string
+	// TokenLabel is the label of the token holding the key.
+	TokenLabel string
+	// PIN is the user PIN of the token.
+	PIN string
+	// KeyLabel is the label of the private key, and of its public key.
+	KeyLabel string
+}
+
+// Validate returns an error if a field of the config is missing.
+func (c Config) Validate() error {
+	switch {
+	case c.Module == "":
+		return fmt.Errorf("the PKCS#11 module is not set")
+	case c.TokenLabel == "":
+		return fmt.Errorf("the PKCS#11 token label is not set")
+	case c.KeyLabel == "":
+		return fmt.Errorf("the PKCS#11 key label is not set")
+	}
+	return nil
+}
+
+// Signer is a crypto.Signer for a key in a PKCS#11 token. Close releases the sessions with the token.
+type Signer interface {
+	crypto.Signer
+	io.Closer
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build cgo
+
+package pkcs11
+
+import (
+	"fmt"
+
+	"github.com/ThalesIgnite/crypto11"
+)
+
+type signer struct {
+	crypto11.Signer
+	ctx *crypto11.Context
+}
+
+func (s *signer) Close() error {
+	return s.ctx.Close()
+}
+
+// NewSigner loads the PKCS#11 module and returns a signer for the key of the config. The module is loaded by
+// the process, so it must be trusted as much as the binary itself.
+func NewSigner(config Config) (Signer, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	ctx, err := crypto11.Configure(&crypto11.Config{
+		Path:       config.Module,
+		TokenLabel: config.TokenLabel,
+		Pin:        config.PIN,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the PKCS#11 token %q with module %s: %v", config.TokenLabel,
+			config.Module, err)
+	}
+	key, err := ctx.FindKeyPair(nil, []byte(config.KeyLabel))
+	if err == nil && key == nil {
+		err = fmt.Errorf("no key pair is labeled %q", config.KeyLabel)
+	}
+	if err != nil {
+		_ = ctx.Close()
+		return nil, fmt.Errorf("failed to find the key in the PKCS#11 token %q: %v", config.TokenLabel, err)
+	}
+	return &signer{Signer: key, ctx: ctx}, nil
+}
@@ -0,0 +1,29 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !cgo
+
+package pkcs11
+
+import (
+	"fmt"
+)
+
+// NewSigner returns an error, since PKCS#11 modules are loaded with cgo.
+func NewSigner(config Config) (Signer, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("PKCS#11 is not supported, the binary is built without cgo")
+}
@@ -0,0 +1,145 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build cgo
+
+package pkcs11
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ThalesIgnite/crypto11"
+
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+// softHSMConfig returns the config of the token in the SOFTHSM2_MODULE, PKCS11_TOKEN_LABEL and PKCS11_PIN
+// environment variables, e.g. a SoftHSM token initialized by "softhsm2-util --init-token --free --label istio-test
+// --pin 1234 --so-pin 1234". The test is skipped if they are not set.
+func softHSMConfig(t *testing.T) Config {
+	config := Config{
+		Module:     os.Getenv("SOFTHSM2_MODULE"),
+		TokenLabel: os.Getenv("PKCS11_TOKEN_LABEL"),
+		PIN:        os.Getenv("PKCS11_PIN"),
+		KeyLabel:   fmt.Sprintf("istio-test-%d", time.Now().UnixNano()),
+	}
+	if config.Module == "" || config.TokenLabel == "" {
+		t.Skip("SOFTHSM2_MODULE and PKCS11_TOKEN_LABEL are not set")
+	}
+	return config
+}
+
+func TestNewSignerSoftHSM(t *testing.T) {
+	config := softHSMConfig(t)
+
+	// Generate the CA key in the token, as an operator would.
+	ctx, err := crypto11.Configure(&crypto11.Config{Path: config.Module, TokenLabel: config.TokenLabel, Pin: config.PIN})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ctx.Close() }()
+	key, err := ctx.GenerateECDSAKeyPairWithLabel([]byte(config.KeyLabel), []byte(config.KeyLabel), elliptic.P256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = key.Delete() }()
+
+	signer, err := NewSigner(config)
+	if err != nil {
+		t.Fatalf("NewSigner() failed: %v", err)
+	}
+	defer func() { _ = signer.Close() }()
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{"cluster.local"}},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, signer.Public(), signer)
+	if err != nil {
+		t.Fatalf("failed to self-sign the CA certificate: %v", err)
+	}
+	caCertPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	bundle, err := util.NewVerifiedKeyCertBundleFromSigner(caCertPEM, signer, nil, caCertPEM)
+	if err != nil {
+		t.Fatalf("NewVerifiedKeyCertBundleFromSigner() failed: %v", err)
+	}
+
+	csrPEM, _, err := util.GenCSR(util.CertOptions{Host: "spiffe://cluster.local/ns/foo/sa/bar", RSAKeySize: 2048})
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr, err := util.ParsePemEncodedCSR(csrPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, caKey, _, _ := bundle.GetAll()
+	certDER, err := util.GenCertFromCSR(csr, caCert, csr.PublicKey, *caKey,
+		[]string{"spiffe://cluster.local/ns/foo/sa/bar"}, time.Hour, false)
+	if err != nil {
+		t.Fatalf("GenCertFromCSR() failed: %v", err)
+	}
+	cert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cert.CheckSignatureFrom(caCert); err != nil {
+		t.Errorf("failed to verify the certificate signed in the token: %v", err)
+	}
+}
+
+func TestNewSignerErrors(t *testing.T) {
+	testCases := map[string]struct {
+		config      Config
+		expectedErr string
+	}{
+		"no module": {
+			config:      Config{TokenLabel: "istio", KeyLabel: "ca"},
+			expectedErr: "the PKCS#11 module is not set",
+		},
+		"no token": {
+			config:      Config{Module: "/usr/lib/softhsm/libsofthsm2.so", KeyLabel: "ca"},
+			expectedErr: "the PKCS#11 token label is not set",
+		},
+		"no key": {
+			config:      Config{Module: "/usr/lib/softhsm/libsofthsm2.so", TokenLabel: "istio"},
+			expectedErr: "the PKCS#11 key label is not set",
+		},
+		"missing module": {
+			config: Config{Module: "/nonexistent/libpkcs11.so", TokenLabel: "istio", KeyLabel: "ca"},
+			expectedErr: "failed to open the PKCS#11 token \"istio\" with module /nonexistent/libpkcs11.so: " +
+				"could not open PKCS#11",
+		},
+	}
+	for id, tc := range testCases {
+		_, err := NewSigner(tc.config)
+		if err == nil || !strings.HasPrefix(err.Error(), tc.expectedErr) {
+			t.Errorf("%s: got error %v, expected %q", id, err, tc.expectedErr)
+		}
+	}
+}
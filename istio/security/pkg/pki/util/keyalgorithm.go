@@ -0,0 +1,161 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"strings"
+)
+
+// KeyAlgorithm is the algorithm, and the size or the curve, of a private key.
+type KeyAlgorithm string
+
+const (
+	// RSA2048 is a 2048-bit RSA key.
+	RSA2048 KeyAlgorithm = "RSA_2048"
+	// RSA3072 is a 3072-bit RSA key.
+	RSA3072 KeyAlgorithm = "RSA_3072"
+	// RSA4096 is a 4096-bit RSA key.
+	RSA4096 KeyAlgorithm = "RSA_4096"
+	// ECDSAP256 is an ECDSA key on the NIST P-256 curve.
+	ECDSAP256 KeyAlgorithm = "ECDSA_P256"
+	// ECDSAP384 is an ECDSA key on the NIST P-384 curve.
+	ECDSAP384 KeyAlgorithm = "ECDSA_P384"
+
+	// DefaultKeyAlgorithm is the algorithm of the workload keys when none is configured.
+	DefaultKeyAlgorithm = RSA2048
+)
+
+// SupportedKeyAlgorithms are the algorithms the keys can be generated with.
+var SupportedKeyAlgorithms = []KeyAlgorithm{RSA2048, RSA3072, RSA4096, ECDSAP256, ECDSAP384}
+
+var rsaKeySizes = map[KeyAlgorithm]int{
+	RSA2048: 2048,
+	RSA3072: 3072,
+	RSA4096: 4096,
+}
+
+var ecdsaCurves = map[KeyAlgorithm]elliptic.Curve{
+	ECDSAP256: elliptic.P256(),
+	ECDSAP384: elliptic.P384(),
+}
+
+// ParseKeyAlgorithm returns the supported key algorithm with the given name, case insensitive. The empty name is
+// the default key algorithm.
+func ParseKeyAlgorithm(name string) (KeyAlgorithm, error) {
+	if name == "" {
+		return DefaultKeyAlgorithm, nil
+	}
+	for _, alg := range SupportedKeyAlgorithms {
+		if strings.EqualFold(name, string(alg)) {
+			return alg, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported key algorithm %q, supported algorithms are %v", name, SupportedKeyAlgorithms)
+}
+
+// ParseKeyAlgorithms parses a list of key algorithm names.
+func ParseKeyAlgorithms(names []string) ([]KeyAlgorithm, error) {
+	algs := make([]KeyAlgorithm, 0, len(names))
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		alg, err := ParseKeyAlgorithm(name)
+		if err != nil {
+			return nil, err
+		}
+		algs = append(algs, alg)
+	}
+	return algs, nil
+}
+
+// GenPrivateKey generates a private key with the key algorithm of the options, or a RSA key of RSAKeySize
+// bits if it is not set.
+func GenPrivateKey(options CertOptions) (crypto.Signer, error) {
+	if options.KeyAlgorithm == "" {
+		priv, err := rsa.GenerateKey(rand.Reader, options.RSAKeySize)
+		if err != nil {
+			return nil, fmt.Errorf("RSA key generation failed (%v)", err)
+		}
+		return priv, nil
+	}
+	if size, ok := rsaKeySizes[options.KeyAlgorithm]; ok {
+		priv, err := rsa.GenerateKey(rand.Reader, size)
+		if err != nil {
+			return nil, fmt.Errorf("RSA key generation failed (%v)", err)
+		}
+		return priv, nil
+	}
+	if curve, ok := ecdsaCurves[options.KeyAlgorithm]; ok {
+		priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("ECDSA key generation failed (%v)", err)
+		}
+		return priv, nil
+	}
+	return nil, fmt.Errorf("unsupported key algorithm %q", options.KeyAlgorithm)
+}
+
+// KeyAlgorithmOf returns the key algorithm of a public key. RSA keys of any size are reported as RSA_<size>.
+func KeyAlgorithmOf(pub crypto.PublicKey) (KeyAlgorithm, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return KeyAlgorithm(fmt.Sprintf("RSA_%d", k.N.BitLen())), nil
+	case *ecdsa.PublicKey:
+		return KeyAlgorithm("ECDSA_" + strings.Replace(k.Curve.Params().Name, "-", "", 1)), nil
+	default:
+		return "", fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+// samePublicKey reports whether the two public keys are equal, comparing their PKIX encodings. Unlike
+// reflect.DeepEqual, it does not depend on the internal state of the key types, e.g. the curve implementations.
+func samePublicKey(a, b crypto.PublicKey) bool {
+	aBytes, err := x509.MarshalPKIXPublicKey(a)
+	if err != nil {
+		return false
+	}
+	bBytes, err := x509.MarshalPKIXPublicKey(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aBytes, bBytes)
+}
+
+// CheckKeyAlgorithm returns an error if the algorithm of the public key is not one of the allowed algorithms.
+// Any algorithm is allowed if the list is empty.
+func CheckKeyAlgorithm(pub crypto.PublicKey, allowed []KeyAlgorithm) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+	alg, err := KeyAlgorithmOf(pub)
+	if err != nil {
+		return err
+	}
+	for _, a := range allowed {
+		if a == alg {
+			return nil
+		}
+	}
+	return fmt.Errorf("key algorithm %s is not allowed, the allowed algorithms are %v", alg, allowed)
+}
@@ -0,0 +1,175 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+func TestParseKeyAlgorithm(t *testing.T) {
+	cases := map[string]struct {
+		name    string
+		want    KeyAlgorithm
+		wantErr bool
+	}{
+		"default":        {name: "", want: RSA2048},
+		"RSA":            {name: "RSA_4096", want: RSA4096},
+		"ECDSA":          {name: "ECDSA_P256", want: ECDSAP256},
+		"case":           {name: "ecdsa_p384", want: ECDSAP384},
+		"unsupported":    {name: "ECDSA_P521", wantErr: true},
+		"not a key type": {name: "2048", wantErr: true},
+	}
+	for id, c := range cases {
+		t.Run(id, func(t *testing.T) {
+			got, err := ParseKeyAlgorithm(c.name)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("ParseKeyAlgorithm(%q) succeeded, want error", c.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("ParseKeyAlgorithm(%q) = %s, want %s", c.name, got, c.want)
+			}
+		})
+	}
+}
+
+func TestGenCSRWithKeyAlgorithm(t *testing.T) {
+	caCertPEM, caKeyPEM, err := GenCertKeyFromOptions(CertOptions{
+		Org:          "ECDSA CA",
+		TTL:          time.Hour,
+		IsCA:         true,
+		IsSelfSigned: true,
+		KeyAlgorithm: ECDSAP256,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := ParsePemEncodedCertificate(caCertPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caKey, err := ParsePemEncodedKey(caKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alg, _ := KeyAlgorithmOf(caCert.PublicKey); alg != ECDSAP256 {
+		t.Fatalf("Got CA key algorithm %s, want %s", alg, ECDSAP256)
+	}
+
+	for _, alg := range []KeyAlgorithm{RSA2048, ECDSAP256, ECDSAP384} {
+		for _, pkcs8 := range []bool{false, true} {
+			csrPEM, keyPEM, err := GenCSR(CertOptions{Host: "spiffe://cluster.local/ns/foo/sa/bar", KeyAlgorithm: alg,
+				PKCS8Key: pkcs8})
+			if err != nil {
+				t.Fatalf("%s: GenCSR() failed: %v", alg, err)
+			}
+			if _, err := ParsePemEncodedKey(keyPEM); err != nil {
+				t.Errorf("%s: failed to parse the private key: %v", alg, err)
+			}
+			csr, err := ParsePemEncodedCSR(csrPEM)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := KeyAlgorithmOf(csr.PublicKey); got != alg {
+				t.Errorf("Got CSR key algorithm %s, want %s", got, alg)
+			}
+
+			// Keys of any algorithm are signed by the ECDSA CA.
+			der, err := GenCertFromCSR(csr, caCert, csr.PublicKey, caKey, []string{"spiffe://cluster.local/ns/foo/sa/bar"},
+				time.Hour, false)
+			if err != nil {
+				t.Fatalf("%s: GenCertFromCSR() failed: %v", alg, err)
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cert.SignatureAlgorithm != x509.ECDSAWithSHA256 {
+				t.Errorf("%s: got signature algorithm %v, want %v", alg, cert.SignatureAlgorithm, x509.ECDSAWithSHA256)
+			}
+			if err := cert.CheckSignatureFrom(caCert); err != nil {
+				t.Errorf("%s: failed to verify the certificate: %v", alg, err)
+			}
+		}
+	}
+}
+
+func TestCheckKeyAlgorithm(t *testing.T) {
+	_, rsaKeyPEM, err := GenCSR(CertOptions{Host: "test.com", RSAKeySize: 2048})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ecKeyPEM, err := GenCSR(CertOptions{Host: "test.com", KeyAlgorithm: ECDSAP384})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, _ := ParsePemEncodedKey(rsaKeyPEM)
+	ecKey, _ := ParsePemEncodedKey(ecKeyPEM)
+
+	cases := map[string]struct {
+		key     interface{}
+		allowed []KeyAlgorithm
+		wantErr bool
+	}{
+		"no policy":       {key: rsaKey},
+		"allowed RSA":     {key: rsaKey, allowed: []KeyAlgorithm{ECDSAP256, RSA2048}},
+		"allowed ECDSA":   {key: ecKey, allowed: []KeyAlgorithm{ECDSAP384}},
+		"disallowed RSA":  {key: rsaKey, allowed: []KeyAlgorithm{ECDSAP256, ECDSAP384}, wantErr: true},
+		"disallowed size": {key: rsaKey, allowed: []KeyAlgorithm{RSA4096}, wantErr: true},
+		"other curve":     {key: ecKey, allowed: []KeyAlgorithm{ECDSAP256}, wantErr: true},
+	}
+	for id, c := range cases {
+		t.Run(id, func(t *testing.T) {
+			err := CheckKeyAlgorithm(publicKey(c.key), c.allowed)
+			if c.wantErr && err == nil {
+				t.Error("CheckKeyAlgorithm() succeeded, want error")
+			} else if !c.wantErr && err != nil {
+				t.Errorf("CheckKeyAlgorithm() failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestSamePublicKey(t *testing.T) {
+	_, ecKeyPEM, err := GenCSR(CertOptions{Host: "test.com", KeyAlgorithm: ECDSAP256})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherKeyPEM, err := GenCSR(CertOptions{Host: "test.com", KeyAlgorithm: ECDSAP256})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, _ := ParsePemEncodedKey(ecKeyPEM)
+	otherKey, _ := ParsePemEncodedKey(otherKeyPEM)
+	// The key parsed again is equal, although its internal state may differ.
+	reparsedKey, _ := ParsePemEncodedKey(ecKeyPEM)
+
+	if !samePublicKey(publicKey(ecKey), publicKey(reparsedKey)) {
+		t.Error("samePublicKey() = false for the same key")
+	}
+	if samePublicKey(publicKey(ecKey), publicKey(otherKey)) {
+		t.Error("samePublicKey() = true for different keys")
+	}
+	if samePublicKey(publicKey(ecKey), nil) {
+		t.Error("samePublicKey() = true for a nil key")
+	}
+}
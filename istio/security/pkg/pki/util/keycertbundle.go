@@ -186,23 +186,43 @@ func (b *KeyCertBundleImpl) CertOptions() (*CertOptions, error) {
 	if len(ids) != 1 {
 		return nil, fmt.Errorf("expect single id from the cert, found %v", ids)
 	}
-	size, err := GetRSAKeySize(*b.privKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get RSA key size: %v", err)
-	}
-	return &CertOptions{
-		Host:       ids[0],
-		Org:        b.cert.Issuer.Organization[0],
-		IsCA:       b.cert.IsCA,
-		TTL:        b.cert.NotAfter.Sub(b.cert.NotBefore),
-		RSAKeySize: size,
-		IsDualUse:  ids[0] == b.cert.Subject.CommonName,
-	}, nil
+	opts := &CertOptions{
+		Host:      ids[0],
+		Org:       b.cert.Issuer.Organization[0],
+		IsCA:      b.cert.IsCA,
+		TTL:       b.cert.NotAfter.Sub(b.cert.NotBefore),
+		IsDualUse: ids[0] == b.cert.Subject.CommonName,
+	}
+	if size, err := GetRSAKeySize(*b.privKey); err == nil {
+		opts.RSAKeySize = size
+	} else if opts.KeyAlgorithm, err = KeyAlgorithmOf(b.cert.PublicKey); err != nil {
+		return nil, fmt.Errorf("failed to get the key algorithm: %v", err)
+	}
+	return opts, nil
 }
 
 // Verify that the cert chain, root cert and key/cert match.
 func Verify(certBytes, privKeyBytes, certChainBytes, rootCertBytes []byte) error {
 	// Verify the cert can be verified from the root cert through the cert chain.
+	if _, err := verifyCertChain(certBytes, certChainBytes, rootCertBytes); err != nil {
+		return err
+	}
+
+	// Verify that the key can be correctly parsed.
+	if _, err := ParsePemEncodedKey(privKeyBytes); err != nil {
+		return fmt.Errorf("failed to parse private key PEM: %v", err)
+	}
+
+	// Verify the cert and key match.
+	if _, err := tls.X509KeyPair(certBytes, privKeyBytes); err != nil {
+		return fmt.Errorf("the cert does not match the key")
+	}
+
+	return nil
+}
+
+// verifyCertChain parses the cert and verifies it from the root cert through the cert chain.
+func verifyCertChain(certBytes, certChainBytes, rootCertBytes []byte) (*x509.Certificate, error) {
 	rcp := x509.NewCertPool()
 	rcp.AppendCertsFromPEM(rootCertBytes)
 
@@ -215,27 +235,16 @@ func Verify(certBytes, privKeyBytes, certChainBytes, rootCertBytes []byte) error
 	}
 	cert, err := ParsePemEncodedCertificate(certBytes)
 	if err != nil {
-		return fmt.Errorf("failed to parse cert PEM: %v", err)
+		return nil, fmt.Errorf("failed to parse cert PEM: %v", err)
 	}
 	chains, err := cert.Verify(opts)
 
 	if len(chains) == 0 || err != nil {
-		return fmt.Errorf(
+		return nil, fmt.Errorf(
 			"cannot verify the cert with the provided root chain and cert "+
 				"pool with error: %v", err)
 	}
-
-	// Verify that the key can be correctly parsed.
-	if _, err = ParsePemEncodedKey(privKeyBytes); err != nil {
-		return fmt.Errorf("failed to parse private key PEM: %v", err)
-	}
-
-	// Verify the cert and key match.
-	if _, err := tls.X509KeyPair(certBytes, privKeyBytes); err != nil {
-		return fmt.Errorf("the cert does not match the key")
-	}
-
-	return nil
+	return cert, nil
 }
 
 func copyBytes(src []byte) []byte {
@@ -0,0 +1,114 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+)
+
+// NewVerifiedKeyCertBundleFromSigner returns a new KeyCertBundle whose private key is held by the signer, e.g. a
+// key in an HSM that can't be exported, or error if the provided certs failed the verification. The bundle has no
+// PEM-encoded private key, GetAllPem returns an empty one.
+func NewVerifiedKeyCertBundleFromSigner(certBytes []byte, signer crypto.Signer, certChainBytes,
+	rootCertBytes []byte) (*KeyCertBundleImpl, error) {
+	bundle := &KeyCertBundleImpl{}
+	if err := bundle.VerifyAndSetAllWithSigner(certBytes, signer, certChainBytes, rootCertBytes); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// NewVerifiedKeyCertBundleFromSignerAndFile is NewVerifiedKeyCertBundleFromSigner with the certs read from files.
+func NewVerifiedKeyCertBundleFromSignerAndFile(certFile string, signer crypto.Signer, certChainFile,
+	rootCertFile string) (*KeyCertBundleImpl, error) {
+	certBytes, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+	certChainBytes := []byte{}
+	if len(certChainFile) != 0 {
+		if certChainBytes, err = ioutil.ReadFile(certChainFile); err != nil {
+			return nil, err
+		}
+	}
+	rootCertBytes, err := ioutil.ReadFile(rootCertFile)
+	if err != nil {
+		return nil, err
+	}
+	return NewVerifiedKeyCertBundleFromSigner(certBytes, signer, certChainBytes, rootCertBytes)
+}
+
+// VerifyAndSetAllWithSigner is VerifyAndSetAll for a private key held by a signer.
+func (b *KeyCertBundleImpl) VerifyAndSetAllWithSigner(certBytes []byte, signer crypto.Signer, certChainBytes,
+	rootCertBytes []byte) error {
+	if err := VerifyWithSigner(certBytes, signer, certChainBytes, rootCertBytes); err != nil {
+		return err
+	}
+	b.mutex.Lock()
+	b.certBytes = copyBytes(certBytes)
+	b.privKeyBytes = []byte{}
+	b.certChainBytes = copyBytes(certChainBytes)
+	b.rootCertBytes = copyBytes(rootCertBytes)
+	b.cert, _ = ParsePemEncodedCertificate(certBytes)
+	privKey := crypto.PrivateKey(signer)
+	b.privKey = &privKey
+	b.mutex.Unlock()
+	return nil
+}
+
+// VerifyWithSigner verifies that the cert chain, root cert and cert match, and that the signer holds the private key
+// of the cert. The signer signs a test digest, so that a misconfigured external key is reported here rather than
+// when the first certificate is signed.
+func VerifyWithSigner(certBytes []byte, signer crypto.Signer, certChainBytes, rootCertBytes []byte) error {
+	cert, err := verifyCertChain(certBytes, certChainBytes, rootCertBytes)
+	if err != nil {
+		return err
+	}
+	if signer == nil {
+		return fmt.Errorf("no signer for the private key")
+	}
+	if !samePublicKey(signer.Public(), cert.PublicKey) {
+		return fmt.Errorf("the cert does not match the key")
+	}
+
+	digest := sha256.Sum256([]byte("istio signer verification"))
+	sig, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("failed to sign with the private key: %v", err)
+	}
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		err = rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig)
+	case *ecdsa.PublicKey:
+		var esig struct{ R, S *big.Int }
+		if _, err = asn1.Unmarshal(sig, &esig); err == nil && !ecdsa.Verify(pub, digest[:], esig.R, esig.S) {
+			err = fmt.Errorf("invalid ECDSA signature")
+		}
+	default:
+		err = fmt.Errorf("unsupported public key type %T", pub)
+	}
+	if err != nil {
+		return fmt.Errorf("the signature of the private key does not match the cert: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+// opaqueSigner hides the type of the private key, like the signers of keys held by an HSM.
+type opaqueSigner struct {
+	signer crypto.Signer
+	err    error
+}
+
+func (s *opaqueSigner) Public() crypto.PublicKey {
+	return s.signer.Public()
+}
+
+func (s *opaqueSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.signer.Sign(rand, digest, opts)
+}
+
+func loadSigner(t *testing.T, keyFile string) crypto.Signer {
+	t.Helper()
+	keyBytes, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParsePemEncodedKey(keyBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &opaqueSigner{signer: key.(crypto.Signer)}
+}
+
+func TestNewVerifiedKeyCertBundleFromSigner(t *testing.T) {
+	testCases := map[string]struct {
+		signer      crypto.Signer
+		certFile    string
+		expectedErr string
+	}{
+		"Matching signer": {
+			signer:   loadSigner(t, intKeyFile),
+			certFile: intCertFile,
+		},
+		"Signer of another key": {
+			signer:      loadSigner(t, anotherKeyFile),
+			certFile:    intCertFile,
+			expectedErr: "the cert does not match the key",
+		},
+		"Failing signer": {
+			signer:      &opaqueSigner{signer: loadSigner(t, intKeyFile), err: fmt.Errorf("token not present")},
+			certFile:    intCertFile,
+			expectedErr: "failed to sign with the private key: token not present",
+		},
+		"No signer": {
+			certFile:    intCertFile,
+			expectedErr: "no signer for the private key",
+		},
+		"Untrusted cert": {
+			signer:      loadSigner(t, anotherKeyFile),
+			certFile:    anotherRootCertFile,
+			expectedErr: "cannot verify the cert with the provided root chain and cert pool",
+		},
+	}
+	for id, tc := range testCases {
+		_, err := NewVerifiedKeyCertBundleFromSignerAndFile(tc.certFile, tc.signer, intCertChainFile, rootCertFile)
+		if tc.expectedErr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", id, err)
+			}
+		} else if err == nil || !strings.HasPrefix(err.Error(), tc.expectedErr) {
+			t.Errorf("%s: got error %v, expected %q", id, err, tc.expectedErr)
+		}
+	}
+}
+
+func TestSignWithSignerBundle(t *testing.T) {
+	for _, alg := range []KeyAlgorithm{RSA2048, ECDSAP256} {
+		caCertPEM, caKeyPEM, err := GenCertKeyFromOptions(CertOptions{
+			Host:         "spiffe://cluster.local/ns/istio-system/sa/citadel",
+			Org:          "HSM CA",
+			TTL:          time.Hour,
+			IsCA:         true,
+			IsSelfSigned: true,
+			KeyAlgorithm: alg,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		caKey, err := ParsePemEncodedKey(caKeyPEM)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bundle, err := NewVerifiedKeyCertBundleFromSigner(caCertPEM, &opaqueSigner{signer: caKey.(crypto.Signer)}, nil,
+			caCertPEM)
+		if err != nil {
+			t.Fatalf("%s: NewVerifiedKeyCertBundleFromSigner() failed: %v", alg, err)
+		}
+		if _, keyPEM, _, _ := bundle.GetAllPem(); len(keyPEM) != 0 {
+			t.Errorf("%s: got a private key PEM for a signer bundle", alg)
+		}
+		opts, err := bundle.CertOptions()
+		if err != nil {
+			t.Fatalf("%s: CertOptions() failed: %v", alg, err)
+		}
+		if opts.RSAKeySize == 0 && opts.KeyAlgorithm != alg {
+			t.Errorf("%s: got key algorithm %s in the cert options", alg, opts.KeyAlgorithm)
+		}
+
+		csrPEM, _, err := GenCSR(CertOptions{Host: "spiffe://cluster.local/ns/foo/sa/bar", KeyAlgorithm: ECDSAP256})
+		if err != nil {
+			t.Fatal(err)
+		}
+		csr, err := ParsePemEncodedCSR(csrPEM)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signingCert, signingKey, _, _ := bundle.GetAll()
+		der, err := GenCertFromCSR(csr, signingCert, csr.PublicKey, *signingKey,
+			[]string{"spiffe://cluster.local/ns/foo/sa/bar"}, time.Hour, false)
+		if err != nil {
+			t.Fatalf("%s: GenCertFromCSR() failed: %v", alg, err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cert.CheckSignatureFrom(signingCert); err != nil {
+			t.Errorf("%s: failed to verify the certificate: %v", alg, err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"time"
+)
+
+// ParseTrustBundle parses all the PEM-encoded certificates of a trust bundle, in order.
+func ParseTrustBundle(bundle []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for rest := bundle; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse X.509 certificate in trust bundle (%v)", err)
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// MergeTrustBundles returns a PEM-encoded trust bundle with the certificates of all the given bundles, in
+// order. Duplicated certificates are only kept once, and the certificates already expired at now are dropped,
+// so that a root being retired disappears from the bundle by itself.
+func MergeTrustBundles(now time.Time, bundles ...[]byte) ([]byte, error) {
+	var merged []byte
+	seen := map[string]bool{}
+	for _, bundle := range bundles {
+		certs, err := ParseTrustBundle(bundle)
+		if err != nil {
+			return nil, err
+		}
+		for _, cert := range certs {
+			fingerprint := CertFingerprint(cert)
+			if seen[fingerprint] || now.After(cert.NotAfter) {
+				continue
+			}
+			seen[fingerprint] = true
+			merged = append(merged, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+		}
+	}
+	if len(merged) == 0 {
+		return nil, fmt.Errorf("no valid certificate in the trust bundle")
+	}
+	return merged, nil
+}
+
+// CertFingerprint returns the hex-encoded SHA-256 fingerprint of the certificate.
+func CertFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
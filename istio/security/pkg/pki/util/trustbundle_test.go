@@ -0,0 +1,115 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func genRoot(t *testing.T, org string, notBefore time.Time) []byte {
+	t.Helper()
+	cert, _, err := GenCertKeyFromOptions(CertOptions{
+		Org:          org,
+		NotBefore:    notBefore,
+		TTL:          time.Hour,
+		IsCA:         true,
+		IsSelfSigned: true,
+		RSAKeySize:   1024,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func bundleOrgs(t *testing.T, bundle []byte) []string {
+	t.Helper()
+	certs, err := ParseTrustBundle(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orgs := []string{}
+	for _, cert := range certs {
+		orgs = append(orgs, cert.Subject.Organization[0])
+	}
+	return orgs
+}
+
+func TestMergeTrustBundles(t *testing.T) {
+	now := time.Now()
+	oldRoot := genRoot(t, "old", now)
+	newRoot := genRoot(t, "new", now)
+	expiredRoot := genRoot(t, "expired", now.Add(-2*time.Hour))
+
+	cases := map[string]struct {
+		bundles [][]byte
+		want    []string
+		wantErr bool
+	}{
+		"single root": {
+			bundles: [][]byte{oldRoot},
+			want:    []string{"old"},
+		},
+		"order is preserved": {
+			bundles: [][]byte{newRoot, oldRoot},
+			want:    []string{"new", "old"},
+		},
+		"concatenated bundle": {
+			bundles: [][]byte{append(append([]byte{}, oldRoot...), newRoot...)},
+			want:    []string{"old", "new"},
+		},
+		"duplicates are dropped": {
+			bundles: [][]byte{oldRoot, append(append([]byte{}, newRoot...), oldRoot...)},
+			want:    []string{"old", "new"},
+		},
+		"expired roots are dropped": {
+			bundles: [][]byte{expiredRoot, newRoot},
+			want:    []string{"new"},
+		},
+		"no valid root": {
+			bundles: [][]byte{expiredRoot, nil},
+			wantErr: true,
+		},
+		"invalid certificate": {
+			bundles: [][]byte{oldRoot, []byte("-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n")},
+			wantErr: true,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			merged, err := MergeTrustBundles(now, c.bundles...)
+			if c.wantErr {
+				if err == nil {
+					t.Fatal("MergeTrustBundles() succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := bundleOrgs(t, merged)
+			if len(got) != len(c.want) {
+				t.Fatalf("Got roots %v, want %v", got, c.want)
+			}
+			for i := range got {
+				if got[i] != c.want[i] {
+					t.Errorf("Got roots %v, want %v", got, c.want)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package audit records the certificates issued by the Citadel server, to audit sinks and to an in-memory
+// inventory of the currently valid certificates.
+package audit
+
+import (
+	"time"
+
+	"istio.io/pkg/log"
+	"istio.io/pkg/monitoring"
+)
+
+var (
+	auditLog = log.RegisterScope("audit", "Citadel certificate audit log", 0)
+	// recordLog only carries the audit records, so that they can be told apart from the other Citadel logs.
+	recordLog = log.RegisterScope("certaudit", "Citadel certificate audit records", 0)
+
+	sinkErrorCounts = monitoring.NewSum(
+		"citadel_server_audit_sink_err_count",
+		"The number of audit records that could not be written to a sink.",
+	)
+)
+
+func init() {
+	monitoring.MustRegister(sinkErrorCounts)
+}
+
+// Record is the audit record of an issued certificate.
+type Record struct {
+	// Time is when the certificate was issued.
+	Time time.Time `json:"time"`
+	// Method is the Citadel API that issued the certificate, e.g. CreateCertificate.
+	Method string `json:"method"`
+	// Callers are the authenticated identities of the caller.
+	Callers []string `json:"callers"`
+	// Node is the address of the caller.
+	Node string `json:"node,omitempty"`
+	// RequestedSANs are the identities requested in the CSR.
+	RequestedSANs []string `json:"requestedSans,omitempty"`
+	// SANs are the identities of the issued certificate.
+	SANs []string `json:"sans"`
+	// SerialNumber is the hexadecimal serial number of the certificate.
+	SerialNumber string    `json:"serialNumber"`
+	NotBefore    time.Time `json:"notBefore"`
+	NotAfter     time.Time `json:"notAfter"`
+}
+
+// Sink is a destination of the audit records.
+type Sink interface {
+	// Write writes the record to the sink.
+	Write(r *Record) error
+	// Close flushes the sink and releases its resources.
+	Close() error
+}
+
+// Auditor writes the records of the issued certificates to its sinks, and keeps the inventory of the valid ones.
+type Auditor struct {
+	sinks     []Sink
+	inventory *Inventory
+}
+
+// NewAuditor returns an auditor writing the records to the sinks.
+func NewAuditor(sinks ...Sink) *Auditor {
+	return &Auditor{sinks: sinks, inventory: NewInventory()}
+}
+
+// Record records an issued certificate. The errors of the sinks are logged, they do not fail the issuance.
+func (a *Auditor) Record(r *Record) {
+	a.inventory.Add(r)
+	for _, sink := range a.sinks {
+		if err := sink.Write(r); err != nil {
+			auditLog.Errorf("failed to write the audit record of the certificate %s: %v", r.SerialNumber, err)
+			sinkErrorCounts.Increment()
+		}
+	}
+}
+
+// Revoked removes the revoked certificates from the inventory.
+func (a *Auditor) Revoked(serialNumbers []string) {
+	a.inventory.Remove(serialNumbers...)
+}
+
+// Inventory returns the inventory of the valid certificates.
+func (a *Auditor) Inventory() *Inventory {
+	return a.inventory
+}
+
+// Close closes the sinks.
+func (a *Auditor) Close() error {
+	var firstErr error
+	for _, sink := range a.sinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
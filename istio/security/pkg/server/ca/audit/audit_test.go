@@ -0,0 +1,154 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type failingSink struct {
+	closed bool
+}
+
+func (s *failingSink) Write(r *Record) error {
+	return fmt.Errorf("sink is full")
+}
+
+func (s *failingSink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func newRecord(serial, san string, notAfter time.Time) *Record {
+	return &Record{
+		Time:         notAfter.Add(-time.Hour),
+		Method:       "CreateCertificate",
+		Callers:      []string{san},
+		SANs:         []string{san},
+		SerialNumber: serial,
+		NotBefore:    notAfter.Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+}
+
+func TestAuditorRecord(t *testing.T) {
+	var buf bytes.Buffer
+	failing := &failingSink{}
+	auditor := NewAuditor(failing, NewJSONSink(&buf))
+
+	expiry := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	r1 := newRecord("1", "spiffe://cluster.local/ns/foo/sa/bar", expiry)
+	r2 := newRecord("2", "spiffe://cluster.local/ns/foo/sa/baz", expiry)
+	auditor.Record(r1)
+	auditor.Record(r2)
+
+	// The failing sink does not prevent the records from being written to the other sinks.
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d audit records, expected 2: %s", len(lines), buf.String())
+	}
+	var got Record
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatalf("invalid audit record %q: %v", lines[0], err)
+	}
+	if !reflect.DeepEqual(&got, r1) {
+		t.Errorf("got audit record %+v, expected %+v", got, r1)
+	}
+
+	auditor.Revoked([]string{"2"})
+	if records := auditor.Inventory().List(""); !reflect.DeepEqual(records, []*Record{r1}) {
+		t.Errorf("got inventory %v, expected only the unrevoked certificate", records)
+	}
+
+	if err := auditor.Close(); err != nil {
+		t.Errorf("Close() failed: %v", err)
+	}
+	if !failing.closed {
+		t.Error("the sinks are not closed")
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+	sink := NewFileSink(FileOptions{Path: path, MaxSizeMB: 1})
+	if err := sink.Write(newRecord("1", "spiffe://cluster.local/ns/foo/sa/bar", time.Now())); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"serialNumber":"1"`) || !strings.HasSuffix(string(b), "\n") {
+		t.Errorf("unexpected audit log %q", b)
+	}
+}
+
+func TestInventory(t *testing.T) {
+	now := time.Now()
+	inventory := NewInventory()
+	inventory.now = func() time.Time { return now }
+
+	bar := "spiffe://cluster.local/ns/foo/sa/bar"
+	expired := newRecord("1", bar, now.Add(-time.Minute))
+	late := newRecord("2", bar, now.Add(2*time.Hour))
+	early := newRecord("3", bar, now.Add(time.Hour))
+	other := newRecord("4", "spiffe://cluster.local/ns/foo/sa/baz", now.Add(time.Hour))
+	for _, r := range []*Record{expired, late, early, other} {
+		inventory.Add(r)
+	}
+
+	testCases := map[string]struct {
+		identity string
+		expected []*Record
+	}{
+		"all":          {expected: []*Record{early, other, late}},
+		"identity":     {identity: bar, expected: []*Record{early, late}},
+		"no such cert": {identity: "spiffe://cluster.local/ns/foo/sa/qux", expected: []*Record{}},
+	}
+	for id, tc := range testCases {
+		if got := inventory.List(tc.identity); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%s: got %v, expected %v", id, got, tc.expected)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	inventory.ServeHTTP(w, httptest.NewRequest("GET", "/debug/certz?identity="+bar, nil))
+	var got []Record
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid response %q: %v", w.Body.String(), err)
+	}
+	if len(got) != 2 || got[0].SerialNumber != "3" || got[1].SerialNumber != "2" {
+		t.Errorf("unexpected response %s", w.Body.String())
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Inventory holds the records of the certificates which are neither expired nor revoked. It only knows the
+// certificates issued since Citadel started.
+type Inventory struct {
+	mutex     sync.Mutex
+	certs     map[string]*Record
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+// pruneInterval is the minimal interval between the removals of the expired certificates when certificates are added.
+const pruneInterval = time.Minute
+
+// NewInventory returns an empty inventory.
+func NewInventory() *Inventory {
+	return &Inventory{certs: map[string]*Record{}, now: time.Now}
+}
+
+// Add adds the record of an issued certificate.
+func (i *Inventory) Add(r *Record) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.now().Sub(i.lastPrune) >= pruneInterval {
+		i.pruneLocked()
+	}
+	i.certs[r.SerialNumber] = r
+}
+
+// Remove removes the records of the certificates with the serial numbers.
+func (i *Inventory) Remove(serialNumbers ...string) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	for _, serial := range serialNumbers {
+		delete(i.certs, serial)
+	}
+}
+
+// List returns the records of the valid certificates issued for identity, or all of them if identity is empty,
+// sorted by expiration.
+func (i *Inventory) List(identity string) []*Record {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.pruneLocked()
+	records := make([]*Record, 0, len(i.certs))
+	for _, r := range i.certs {
+		if identity == "" || contains(r.SANs, identity) {
+			records = append(records, r)
+		}
+	}
+	sort.Slice(records, func(a, b int) bool {
+		if records[a].NotAfter.Equal(records[b].NotAfter) {
+			return records[a].SerialNumber < records[b].SerialNumber
+		}
+		return records[a].NotAfter.Before(records[b].NotAfter)
+	})
+	return records
+}
+
+// ServeHTTP returns the records of List as JSON, for the identity of the "identity" query parameter.
+func (i *Inventory) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	b, err := json.MarshalIndent(i.List(req.URL.Query().Get("identity")), "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+// pruneLocked removes the expired certificates. The mutex must be held.
+func (i *Inventory) pruneLocked() {
+	now := i.now()
+	i.lastPrune = now
+	for serial, r := range i.certs {
+		if now.After(r.NotAfter) {
+			delete(i.certs, serial)
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/natefinch/lumberjack"
+)
+
+// jsonSink writes the records as JSON lines.
+type jsonSink struct {
+	mutex  sync.Mutex
+	w      io.Writer
+	closer func() error
+}
+
+// NewJSONSink returns a sink writing the records as JSON lines to w, e.g. os.Stdout. Closing the sink does not
+// close w.
+func NewJSONSink(w io.Writer) Sink {
+	return &jsonSink{w: w, closer: func() error { return nil }}
+}
+
+// scopeSink writes the records as JSON messages of the audit records log scope.
+type scopeSink struct{}
+
+// NewScopeSink returns a sink writing the records as JSON messages of the dedicated "certaudit" log scope, at
+// info level. The scope tags the records in the Citadel logs, and its output level is set independently, e.g.
+// --log_output_level=certaudit:info.
+func NewScopeSink() Sink {
+	return scopeSink{}
+}
+
+func (scopeSink) Write(r *Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	recordLog.Info(string(b))
+	return nil
+}
+
+func (scopeSink) Close() error {
+	return nil
+}
+
+// FileOptions configures the rotation of an audit log file.
+type FileOptions struct {
+	// Path is the path of the file.
+	Path string
+	// MaxSizeMB is the size in megabytes from which the file is rotated.
+	MaxSizeMB int
+	// MaxBackups is the number of rotated files kept, or 0 to keep them all.
+	MaxBackups int
+	// MaxAgeDays is the number of days the rotated files are kept, or 0 to keep them regardless of age.
+	MaxAgeDays int
+}
+
+// NewFileSink returns a sink writing the records as JSON lines to a file, rotated according to the options.
+func NewFileSink(options FileOptions) Sink {
+	lj := &lumberjack.Logger{
+		Filename:   options.Path,
+		MaxSize:    options.MaxSizeMB,
+		MaxBackups: options.MaxBackups,
+		MaxAge:     options.MaxAgeDays,
+	}
+	return &jsonSink{w: lj, closer: lj.Close}
+}
+
+func (s *jsonSink) Write(r *Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err = s.w.Write(append(b, '\n'))
+	return err
+}
+
+func (s *jsonSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.closer()
+}
@@ -56,6 +56,11 @@ var (
 		"The number of certificates issuances that have succeeded.",
 	)
 
+	revokedCertCounts = monitoring.NewSum(
+		"citadel_server_revoked_cert_count",
+		"The number of certificates revoked.",
+	)
+
 	rootCertExpiryTimestamp = monitoring.NewGauge(
 		"citadel_server_root_cert_expiry_timestamp",
 		"The unix timestamp, in seconds, when Citadel root cert will expire. "+
@@ -71,6 +76,7 @@ func init() {
 		idExtractionErrorCounts,
 		certSignErrorCounts,
 		successCounts,
+		revokedCertCounts,
 		rootCertExpiryTimestamp,
 	)
 }
@@ -82,6 +88,7 @@ type monitoringMetrics struct {
 	Success           monitoring.Metric
 	CSRError          monitoring.Metric
 	IDExtractionError monitoring.Metric
+	Revoked           monitoring.Metric
 	certSignErrors    monitoring.Metric
 }
 
@@ -93,6 +100,7 @@ func newMonitoringMetrics() monitoringMetrics {
 		Success:           successCounts,
 		CSRError:          csrParsingErrorCounts,
 		IDExtractionError: idExtractionErrorCounts,
+		Revoked:           revokedCertCounts,
 		certSignErrors:    certSignErrorCounts,
 	}
 }
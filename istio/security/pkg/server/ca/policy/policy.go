@@ -0,0 +1,292 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package policy implements the certificate issuance policy of the Citadel server, which constrains the
+// certificates the callers can get depending on their identity.
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+// The actions applied to the callers that no rule selects.
+const (
+	// ActionAllow issues the certificates to the callers that no rule selects.
+	ActionAllow = "ALLOW"
+	// ActionDeny denies the certificates to the callers that no rule selects.
+	ActionDeny = "DENY"
+)
+
+// Config is the YAML configuration of an issuance policy, e.g.
+//
+//  dryRun: false
+//  defaultAction: DENY
+//  rules:
+//  - name: workloads
+//    namespace: "*"
+//    allowedSpiffeIds: ["spiffe://{trustDomain}/ns/{namespace}/sa/{serviceAccount}"]
+//    allowedDnsNames: ["*.{namespace}.svc"]
+//    maxTtl: 24h
+//    allowedKeyAlgorithms: [ECDSA_P256]
+type Config struct {
+	// DryRun logs the requests the policy would deny, and issues their certificates.
+	DryRun bool `json:"dryRun,omitempty"`
+	// DefaultAction is ActionAllow or ActionDeny, for the callers that no rule selects. It is ActionAllow if empty.
+	DefaultAction string `json:"defaultAction,omitempty"`
+	// Rules are evaluated in order, the first rule selecting the caller applies.
+	Rules []RuleConfig `json:"rules,omitempty"`
+}
+
+// RuleConfig is the configuration of a rule. The patterns may contain "*" wildcards, and the {trustDomain},
+// {namespace} and {serviceAccount} variables, replaced by those of the SPIFFE identity of the caller.
+type RuleConfig struct {
+	// Name identifies the rule in the logs and the metrics.
+	Name string `json:"name"`
+	// Namespace and ServiceAccount select the callers the rule applies to. Empty patterns select any caller.
+	Namespace      string `json:"namespace,omitempty"`
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+	// AllowedSpiffeIDs and AllowedDNSNames are the patterns of the URI and DNS SANs the callers can request.
+	// They are not constrained if the lists are empty.
+	AllowedSpiffeIDs []string `json:"allowedSpiffeIds,omitempty"`
+	AllowedDNSNames  []string `json:"allowedDnsNames,omitempty"`
+	// MinTTL and MaxTTL are the range of the TTLs the callers can request, e.g. 1h. They are not checked for
+	// requests of the default TTL of the CA.
+	MinTTL string `json:"minTtl,omitempty"`
+	MaxTTL string `json:"maxTtl,omitempty"`
+	// AllowedKeyAlgorithms are the algorithms allowed for the keys of the CSRs, e.g. ECDSA_P256.
+	AllowedKeyAlgorithms []string `json:"allowedKeyAlgorithms,omitempty"`
+}
+
+// Policy is a parsed issuance policy.
+type Policy struct {
+	dryRun       bool
+	defaultAllow bool
+	rules        []*rule
+}
+
+type rule struct {
+	name                 string
+	namespace            string
+	serviceAccount       string
+	allowedSpiffeIDs     []string
+	allowedDNSNames      []string
+	minTTL               time.Duration
+	maxTTL               time.Duration
+	allowedKeyAlgorithms []util.KeyAlgorithm
+}
+
+// Request is a certificate request evaluated by the policy.
+type Request struct {
+	// CallerIdentities are the authenticated identities of the caller.
+	CallerIdentities []string
+	// SANs are the identities requested in the CSR and the ones the certificate is issued for.
+	SANs []string
+	// TTL is the requested TTL, or 0 for the default TTL of the CA.
+	TTL time.Duration
+	// KeyAlgorithm is the algorithm of the key of the CSR.
+	KeyAlgorithm util.KeyAlgorithm
+}
+
+// Decision is the result of the evaluation of a request.
+type Decision struct {
+	// Rule is the name of the rule that selected the caller, or empty if no rule did.
+	Rule string
+	// Allowed is whether the policy allows the request, regardless of the dry-run mode.
+	Allowed bool
+	// Reason is why the request is denied.
+	Reason string
+}
+
+// DenyAll returns a policy denying all the requests.
+func DenyAll() *Policy {
+	return &Policy{}
+}
+
+// Parse parses the YAML configuration of an issuance policy.
+func Parse(data []byte) (*Policy, error) {
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse the issuance policy: %v", err)
+	}
+	return New(config)
+}
+
+// New validates the configuration of an issuance policy and returns the policy.
+func New(config Config) (*Policy, error) {
+	p := &Policy{dryRun: config.DryRun}
+	switch strings.ToUpper(config.DefaultAction) {
+	case "", ActionAllow:
+		p.defaultAllow = true
+	case ActionDeny:
+	default:
+		return nil, fmt.Errorf("invalid default action %q, it should be %s or %s", config.DefaultAction,
+			ActionAllow, ActionDeny)
+	}
+	names := map[string]bool{}
+	for i, rc := range config.Rules {
+		if rc.Name == "" {
+			return nil, fmt.Errorf("rule %d has no name", i)
+		}
+		if names[rc.Name] {
+			return nil, fmt.Errorf("duplicate rule %q", rc.Name)
+		}
+		names[rc.Name] = true
+		r := &rule{
+			name:             rc.Name,
+			namespace:        rc.Namespace,
+			serviceAccount:   rc.ServiceAccount,
+			allowedSpiffeIDs: rc.AllowedSpiffeIDs,
+			allowedDNSNames:  rc.AllowedDNSNames,
+		}
+		var err error
+		if rc.MinTTL != "" {
+			if r.minTTL, err = time.ParseDuration(rc.MinTTL); err != nil {
+				return nil, fmt.Errorf("rule %q has an invalid minTtl: %v", rc.Name, err)
+			}
+		}
+		if rc.MaxTTL != "" {
+			if r.maxTTL, err = time.ParseDuration(rc.MaxTTL); err != nil {
+				return nil, fmt.Errorf("rule %q has an invalid maxTtl: %v", rc.Name, err)
+			}
+			if r.maxTTL < r.minTTL {
+				return nil, fmt.Errorf("rule %q has a maxTtl shorter than its minTtl", rc.Name)
+			}
+		}
+		if r.allowedKeyAlgorithms, err = util.ParseKeyAlgorithms(rc.AllowedKeyAlgorithms); err != nil {
+			return nil, fmt.Errorf("rule %q has invalid key algorithms: %v", rc.Name, err)
+		}
+		p.rules = append(p.rules, r)
+	}
+	return p, nil
+}
+
+// DryRun returns whether the policy only logs the requests it would deny.
+func (p *Policy) DryRun() bool {
+	return p.dryRun
+}
+
+// Evaluate returns the decision of the policy for the request.
+func (p *Policy) Evaluate(req Request) Decision {
+	for _, caller := range req.CallerIdentities {
+		vars := identityVars(caller)
+		for _, r := range p.rules {
+			if !r.selects(vars) {
+				continue
+			}
+			if reason := r.check(req, vars); reason != "" {
+				return Decision{Rule: r.name, Reason: reason}
+			}
+			return Decision{Rule: r.name, Allowed: true}
+		}
+	}
+	if !p.defaultAllow {
+		return Decision{Reason: fmt.Sprintf("no rule allows the callers %v", req.CallerIdentities)}
+	}
+	return Decision{Allowed: true}
+}
+
+// selects returns whether the rule applies to the caller. The callers without SPIFFE identity are only selected
+// by the rules without namespace and service account.
+func (r *rule) selects(vars map[string]string) bool {
+	if vars["namespace"] == "" {
+		return r.namespace == "" && r.serviceAccount == ""
+	}
+	return matchPattern(r.namespace, vars["namespace"], nil) && matchPattern(r.serviceAccount, vars["serviceAccount"], nil)
+}
+
+// check returns why the rule denies the request, or an empty string if it allows it.
+func (r *rule) check(req Request, vars map[string]string) string {
+	for _, san := range req.SANs {
+		patterns := r.allowedDNSNames
+		if strings.Contains(san, "://") {
+			patterns = r.allowedSpiffeIDs
+		}
+		if len(patterns) > 0 && !matchAny(patterns, san, vars) {
+			return fmt.Sprintf("the identity %q is not allowed", san)
+		}
+	}
+	if req.TTL > 0 {
+		if req.TTL < r.minTTL {
+			return fmt.Sprintf("the TTL %v is shorter than %v", req.TTL, r.minTTL)
+		}
+		if r.maxTTL > 0 && req.TTL > r.maxTTL {
+			return fmt.Sprintf("the TTL %v is longer than %v", req.TTL, r.maxTTL)
+		}
+	}
+	if len(r.allowedKeyAlgorithms) > 0 {
+		allowed := false
+		for _, alg := range r.allowedKeyAlgorithms {
+			allowed = allowed || alg == req.KeyAlgorithm
+		}
+		if !allowed {
+			return fmt.Sprintf("the key algorithm %s is not allowed", req.KeyAlgorithm)
+		}
+	}
+	return ""
+}
+
+// identityVars returns the variables of a SPIFFE identity, spiffe://<trustDomain>/ns/<namespace>/sa/<serviceAccount>.
+// They are empty for other identities.
+func identityVars(id string) map[string]string {
+	vars := map[string]string{"trustDomain": "", "namespace": "", "serviceAccount": ""}
+	if !strings.HasPrefix(id, "spiffe://") {
+		return vars
+	}
+	parts := strings.Split(strings.TrimPrefix(id, "spiffe://"), "/")
+	if len(parts) == 5 && parts[1] == "ns" && parts[3] == "sa" {
+		vars["trustDomain"], vars["namespace"], vars["serviceAccount"] = parts[0], parts[2], parts[4]
+	}
+	return vars
+}
+
+func matchAny(patterns []string, value string, vars map[string]string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, value, vars) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern returns whether the value matches the pattern, where "*" matches any sequence of characters.
+// The variables of the pattern are replaced first, and never match if they are empty. An empty pattern
+// matches any value.
+func matchPattern(pattern, value string, vars map[string]string) bool {
+	if pattern == "" {
+		return true
+	}
+	for name, v := range vars {
+		placeholder := "{" + name + "}"
+		if !strings.Contains(pattern, placeholder) {
+			continue
+		}
+		if v == "" {
+			return false
+		}
+		pattern = strings.Replace(pattern, placeholder, v, -1)
+	}
+	parts := strings.Split(pattern, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	matched, _ := regexp.MatchString("^"+strings.Join(parts, ".*")+"$", value)
+	return matched
+}
@@ -0,0 +1,201 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+const testPolicy = `
+defaultAction: DENY
+rules:
+- name: ingress
+  namespace: istio-system
+  serviceAccount: istio-ingressgateway-*
+  allowedSpiffeIds: ["spiffe://{trustDomain}/ns/{namespace}/sa/{serviceAccount}"]
+  allowedDnsNames: ["*.example.com"]
+- name: workloads
+  namespace: "*"
+  allowedSpiffeIds: ["spiffe://{trustDomain}/ns/{namespace}/sa/{serviceAccount}"]
+  allowedDnsNames: ["*.{namespace}.svc"]
+  minTtl: 1h
+  maxTtl: 24h
+  allowedKeyAlgorithms: [RSA_2048, ECDSA_P256]
+`
+
+func TestParse(t *testing.T) {
+	testCases := map[string]struct {
+		config      string
+		expectedErr string
+	}{
+		"valid": {
+			config: testPolicy,
+		},
+		"empty": {
+			config: "",
+		},
+		"invalid YAML": {
+			config:      "rules: {",
+			expectedErr: "failed to parse the issuance policy",
+		},
+		"invalid default action": {
+			config:      "defaultAction: REJECT",
+			expectedErr: `invalid default action "REJECT"`,
+		},
+		"no rule name": {
+			config:      "rules:\n- namespace: foo",
+			expectedErr: "rule 0 has no name",
+		},
+		"duplicate rule": {
+			config:      "rules:\n- name: foo\n- name: foo",
+			expectedErr: `duplicate rule "foo"`,
+		},
+		"invalid TTL": {
+			config:      "rules:\n- name: foo\n  maxTtl: day",
+			expectedErr: `rule "foo" has an invalid maxTtl`,
+		},
+		"inverted TTL range": {
+			config:      "rules:\n- name: foo\n  minTtl: 2h\n  maxTtl: 1h",
+			expectedErr: `rule "foo" has a maxTtl shorter than its minTtl`,
+		},
+		"invalid key algorithm": {
+			config:      "rules:\n- name: foo\n  allowedKeyAlgorithms: [DSA]",
+			expectedErr: `rule "foo" has invalid key algorithms`,
+		},
+	}
+	for id, tc := range testCases {
+		_, err := Parse([]byte(tc.config))
+		if tc.expectedErr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", id, err)
+			}
+		} else if err == nil || !strings.HasPrefix(err.Error(), tc.expectedErr) {
+			t.Errorf("%s: got error %v, expected %q", id, err, tc.expectedErr)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	p, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	workload := "spiffe://cluster.local/ns/foo/sa/bar"
+	ingress := "spiffe://cluster.local/ns/istio-system/sa/istio-ingressgateway-service-account"
+	testCases := map[string]struct {
+		req            Request
+		expectedRule   string
+		expectedReason string
+	}{
+		"workload": {
+			req: Request{
+				CallerIdentities: []string{workload},
+				SANs:             []string{workload, "bar.foo.svc"},
+				TTL:              12 * time.Hour,
+				KeyAlgorithm:     util.ECDSAP256,
+			},
+			expectedRule: "workloads",
+		},
+		"default TTL": {
+			req:          Request{CallerIdentities: []string{workload}, SANs: []string{workload}, KeyAlgorithm: util.RSA2048},
+			expectedRule: "workloads",
+		},
+		"other SPIFFE ID": {
+			req: Request{
+				CallerIdentities: []string{workload},
+				SANs:             []string{"spiffe://cluster.local/ns/foo/sa/admin"},
+				KeyAlgorithm:     util.RSA2048,
+			},
+			expectedRule:   "workloads",
+			expectedReason: `the identity "spiffe://cluster.local/ns/foo/sa/admin" is not allowed`,
+		},
+		"DNS name of another namespace": {
+			req: Request{
+				CallerIdentities: []string{workload},
+				SANs:             []string{"bar.baz.svc"},
+				KeyAlgorithm:     util.RSA2048,
+			},
+			expectedRule:   "workloads",
+			expectedReason: `the identity "bar.baz.svc" is not allowed`,
+		},
+		"TTL too short": {
+			req:            Request{CallerIdentities: []string{workload}, TTL: time.Minute, KeyAlgorithm: util.RSA2048},
+			expectedRule:   "workloads",
+			expectedReason: "the TTL 1m0s is shorter than 1h0m0s",
+		},
+		"TTL too long": {
+			req:            Request{CallerIdentities: []string{workload}, TTL: 48 * time.Hour, KeyAlgorithm: util.RSA2048},
+			expectedRule:   "workloads",
+			expectedReason: "the TTL 48h0m0s is longer than 24h0m0s",
+		},
+		"key algorithm": {
+			req:            Request{CallerIdentities: []string{workload}, KeyAlgorithm: util.RSA4096},
+			expectedRule:   "workloads",
+			expectedReason: "the key algorithm RSA_4096 is not allowed",
+		},
+		"first matching rule": {
+			req: Request{
+				CallerIdentities: []string{ingress},
+				SANs:             []string{ingress, "www.example.com"},
+				TTL:              time.Minute,
+				KeyAlgorithm:     util.RSA4096,
+			},
+			expectedRule: "ingress",
+		},
+		"no matching rule": {
+			req:            Request{CallerIdentities: []string{"spiffe://cluster.local/custom"}},
+			expectedReason: "no rule allows the callers [spiffe://cluster.local/custom]",
+		},
+	}
+	for id, tc := range testCases {
+		d := p.Evaluate(tc.req)
+		if d.Rule != tc.expectedRule {
+			t.Errorf("%s: got rule %q, expected %q", id, d.Rule, tc.expectedRule)
+		}
+		if d.Allowed != (tc.expectedReason == "") || d.Reason != tc.expectedReason {
+			t.Errorf("%s: got decision %+v, expected reason %q", id, d, tc.expectedReason)
+		}
+	}
+}
+
+func TestStoreAuthorize(t *testing.T) {
+	deny, err := New(Config{DefaultAction: ActionDeny})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dryRun, err := New(Config{DefaultAction: ActionDeny, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := map[string]struct {
+		policy   *Policy
+		expected bool
+	}{
+		"no policy": {expected: true},
+		"deny":      {policy: deny, expected: false},
+		"dry run":   {policy: dryRun, expected: true},
+	}
+	for id, tc := range testCases {
+		store := NewStore()
+		store.Set(tc.policy)
+		if allowed, _ := store.Authorize(Request{CallerIdentities: []string{"spiffe://cluster.local/ns/foo/sa/bar"}}); allowed != tc.expected {
+			t.Errorf("%s: got allowed %v, expected %v", id, allowed, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"sync"
+
+	"istio.io/pkg/log"
+	"istio.io/pkg/monitoring"
+)
+
+// The decisions recorded by the metrics.
+const (
+	decisionAllow      = "allow"
+	decisionDeny       = "deny"
+	decisionDryRunDeny = "dry_run_deny"
+)
+
+var (
+	policyLog = log.RegisterScope("issuancePolicy", "Citadel certificate issuance policy log", 0)
+
+	ruleTag     = monitoring.MustCreateLabel("rule")
+	decisionTag = monitoring.MustCreateLabel("decision")
+
+	decisionCounts = monitoring.NewSum(
+		"citadel_server_issuance_policy_decision_count",
+		"The number of certificate requests evaluated by the issuance policy, by rule and decision.",
+		monitoring.WithLabels(ruleTag, decisionTag),
+	)
+)
+
+func init() {
+	monitoring.MustRegister(decisionCounts)
+}
+
+// Store holds the current issuance policy, which can be replaced while requests are evaluated.
+type Store struct {
+	mutex  sync.RWMutex
+	policy *Policy
+}
+
+// NewStore returns a store without policy, which allows all the requests.
+func NewStore() *Store {
+	return &Store{}
+}
+
+// Set replaces the policy of the store. A nil policy allows all the requests.
+func (s *Store) Set(p *Policy) {
+	s.mutex.Lock()
+	s.policy = p
+	s.mutex.Unlock()
+}
+
+// Get returns the policy of the store, or nil if there is none.
+func (s *Store) Get() *Policy {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.policy
+}
+
+// Authorize evaluates the request with the current policy, and returns whether the certificate can be issued.
+// In dry-run mode, the requests the policy denies are logged and allowed.
+func (s *Store) Authorize(req Request) (bool, Decision) {
+	p := s.Get()
+	if p == nil {
+		return true, Decision{Allowed: true}
+	}
+	d := p.Evaluate(req)
+	switch {
+	case d.Allowed:
+		decisionCounts.With(ruleTag.Value(d.Rule), decisionTag.Value(decisionAllow)).Increment()
+		return true, d
+	case p.DryRun():
+		policyLog.Warnf("dry run: the issuance policy would deny the certificate of %v (rule %q): %s",
+			req.CallerIdentities, d.Rule, d.Reason)
+		decisionCounts.With(ruleTag.Value(d.Rule), decisionTag.Value(decisionDryRunDeny)).Increment()
+		return true, d
+	default:
+		policyLog.Warnf("the issuance policy denies the certificate of %v (rule %q): %s",
+			req.CallerIdentities, d.Rule, d.Reason)
+		decisionCounts.With(ruleTag.Value(d.Rule), decisionTag.Value(decisionDeny)).Increment()
+		return false, d
+	}
+}
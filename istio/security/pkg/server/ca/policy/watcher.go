@@ -0,0 +1,128 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// ConfigMapKey is the key of the issuance policy in the ConfigMap.
+	ConfigMapKey = "policy.yaml"
+
+	configMapResyncPeriod = time.Minute
+)
+
+// ConfigMapWatcher loads the issuance policy of a ConfigMap into a store, whenever the ConfigMap changes. The
+// store denies all the requests until a valid policy is loaded.
+type ConfigMapWatcher struct {
+	store     *Store
+	name      string
+	namespace string
+	informer  cache.Controller
+
+	// loaded is whether a valid policy has been loaded.
+	mutex  sync.Mutex
+	loaded bool
+}
+
+// NewConfigMapWatcher returns a watcher of the issuance policy in the ConfigMap name in namespace.
+func NewConfigMapWatcher(core corev1.ConfigMapsGetter, namespace, name string, store *Store) *ConfigMapWatcher {
+	w := &ConfigMapWatcher{store: store, name: name, namespace: namespace}
+	store.Set(DenyAll())
+	selector := fields.OneTermEqualSelector("metadata.name", name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = selector
+			return core.ConfigMaps(namespace).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = selector
+			return core.ConfigMaps(namespace).Watch(options)
+		},
+	}
+	_, w.informer = cache.NewInformer(lw, &v1.ConfigMap{}, configMapResyncPeriod, cache.ResourceEventHandlerFuncs{
+		AddFunc:    w.configMapUpdated,
+		UpdateFunc: func(_, obj interface{}) { w.configMapUpdated(obj) },
+		DeleteFunc: w.configMapDeleted,
+	})
+	return w
+}
+
+// Run watches the ConfigMap until stopCh is closed.
+func (w *ConfigMapWatcher) Run(stopCh <-chan struct{}) {
+	w.informer.Run(stopCh)
+}
+
+// HasSynced returns whether the ConfigMap has been loaded.
+func (w *ConfigMapWatcher) HasSynced() bool {
+	return w.informer.HasSynced()
+}
+
+// WaitForSync waits until the ConfigMap has been loaded, and returns false if stopCh is closed first. The requests
+// are denied if there is no valid policy in the ConfigMap at this point.
+func (w *ConfigMapWatcher) WaitForSync(stopCh <-chan struct{}) bool {
+	if !cache.WaitForCacheSync(stopCh, w.HasSynced) {
+		return false
+	}
+	w.mutex.Lock()
+	loaded := w.loaded
+	w.mutex.Unlock()
+	if !loaded {
+		policyLog.Errorf("no valid issuance policy in the ConfigMap %s/%s, all the certificate requests are denied",
+			w.namespace, w.name)
+	}
+	return true
+}
+
+// configMapUpdated loads the policy of the ConfigMap. An invalid policy is ignored, and the previous one is kept.
+func (w *ConfigMapWatcher) configMapUpdated(obj interface{}) {
+	cm, ok := obj.(*v1.ConfigMap)
+	if !ok {
+		return
+	}
+	data, found := cm.Data[ConfigMapKey]
+	if !found {
+		policyLog.Errorf("the ConfigMap %s/%s has no %s key, the issuance policy is not updated",
+			w.namespace, w.name, ConfigMapKey)
+		return
+	}
+	p, err := Parse([]byte(data))
+	if err != nil {
+		policyLog.Errorf("invalid issuance policy in the ConfigMap %s/%s, the policy is not updated: %v",
+			w.namespace, w.name, err)
+		return
+	}
+	w.store.Set(p)
+	w.mutex.Lock()
+	w.loaded = true
+	w.mutex.Unlock()
+	policyLog.Infof("loaded the issuance policy of the ConfigMap %s/%s (%d rules, dry run: %v)",
+		w.namespace, w.name, len(p.rules), p.DryRun())
+}
+
+// configMapDeleted keeps the current policy, so that deleting the ConfigMap does not lift the restrictions.
+func (w *ConfigMapWatcher) configMapDeleted(_ interface{}) {
+	policyLog.Warnf("the ConfigMap %s/%s is deleted, the current issuance policy is kept", w.namespace, w.name)
+}
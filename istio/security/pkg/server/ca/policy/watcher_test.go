@@ -0,0 +1,93 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestConfigMapWatcher(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	store := NewStore()
+	watcher := NewConfigMapWatcher(client.CoreV1(), "istio-system", "citadel-issuance-policy", store)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go watcher.Run(stopCh)
+
+	waitFor := func(desc string, cond func(*Policy) bool) {
+		t.Helper()
+		for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+			if cond(store.Get()) {
+				return
+			}
+		}
+		t.Fatalf("timed out waiting for %s", desc)
+	}
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "citadel-issuance-policy", Namespace: "istio-system"},
+		Data:       map[string]string{ConfigMapKey: testPolicy},
+	}
+	if _, err := client.CoreV1().ConfigMaps("istio-system").Create(cm); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("the policy to be loaded", func(p *Policy) bool { return p != nil && len(p.rules) == 2 })
+
+	// An invalid policy keeps the previous one.
+	cm.Data[ConfigMapKey] = "defaultAction: REJECT"
+	if _, err := client.CoreV1().ConfigMaps("istio-system").Update(cm); err != nil {
+		t.Fatal(err)
+	}
+	cm.Data[ConfigMapKey] = "dryRun: true"
+	if _, err := client.CoreV1().ConfigMaps("istio-system").Update(cm); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("the policy to be updated", func(p *Policy) bool { return p != nil && p.DryRun() })
+
+	// Deleting the ConfigMap keeps the last policy.
+	if err := client.CoreV1().ConfigMaps("istio-system").Delete(cm.Name, &metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("the ConfigMap to be deleted", func(*Policy) bool {
+		_, err := client.CoreV1().ConfigMaps("istio-system").Get(cm.Name, metav1.GetOptions{})
+		return err != nil
+	})
+	time.Sleep(100 * time.Millisecond)
+	if p := store.Get(); p == nil || !p.DryRun() {
+		t.Errorf("the policy is %+v after the ConfigMap is deleted, expected the last policy", p)
+	}
+}
+
+func TestConfigMapWatcherWithoutConfigMap(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	store := NewStore()
+	watcher := NewConfigMapWatcher(client.CoreV1(), "istio-system", "citadel-issuance-policy", store)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go watcher.Run(stopCh)
+
+	if !watcher.WaitForSync(stopCh) {
+		t.Fatal("WaitForSync() = false")
+	}
+	req := Request{CallerIdentities: []string{"spiffe://cluster.local/ns/foo/sa/bar"}}
+	if allowed, _ := store.Authorize(req); allowed {
+		t.Error("the request is allowed without policy, expected it to be denied")
+	}
+}
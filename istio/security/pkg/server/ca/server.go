@@ -19,6 +19,7 @@ import (
 	"crypto/x509"
 	"fmt"
 	"net"
+	"strings"
 	"time"
 
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
@@ -26,12 +27,16 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 
+	"istio.io/istio/security/pkg/pki/ca"
 	caerror "istio.io/istio/security/pkg/pki/error"
 	"istio.io/istio/security/pkg/pki/util"
 	"istio.io/istio/security/pkg/registry"
+	"istio.io/istio/security/pkg/server/ca/audit"
 	"istio.io/istio/security/pkg/server/ca/authenticate"
+	"istio.io/istio/security/pkg/server/ca/policy"
 	pb "istio.io/istio/security/proto"
 	"istio.io/pkg/log"
 	"istio.io/pkg/version"
@@ -63,6 +68,15 @@ type CertificateAuthority interface {
 	GetCAKeyCertBundle() util.KeyCertBundle
 }
 
+// CertificateRevoker is implemented by the CAs that support certificate revocation.
+type CertificateRevoker interface {
+	// Revoke revokes the certificates with the given serial numbers, and all the certificates issued to
+	// subjectID if it is not empty. It returns the serial numbers of the newly revoked certificates.
+	Revoke(serialNumbers []string, subjectID string) ([]string, error)
+	// CertificateRevocationList returns the PEM-encoded CRL of the revoked certificates.
+	CertificateRevocationList() ([]byte, error)
+}
+
 // Server implements IstioCAService and IstioCertificateService and provides the services on the
 // specified port.
 type Server struct {
@@ -76,6 +90,13 @@ type Server struct {
 	port           int
 	forCA          bool
 	grpcServer     *grpc.Server
+
+	// RevocationAdmins are the identities allowed to revoke certificates. Revocation is disabled if empty.
+	RevocationAdmins []string
+	// IssuancePolicy constrains the certificates issued to the callers. All the requests are allowed if nil.
+	IssuancePolicy *policy.Store
+	// Audit records the issued certificates. They are not recorded if nil.
+	Audit *audit.Auditor
 }
 
 // CreateCertificate handles an incoming certificate signing request (CSR). It does
@@ -95,6 +116,10 @@ func (s *Server) CreateCertificate(ctx context.Context, request *pb.IstioCertifi
 
 	// TODO: Call authorizer.
 
+	if err := s.authorizeIssuance(caller, []byte(request.Csr), time.Duration(request.ValidityDuration)*time.Second); err != nil {
+		return nil, err
+	}
+
 	_, _, certChainBytes, rootCertBytes := s.ca.GetCAKeyCertBundle().GetAll()
 	cert, signErr := s.ca.Sign(
 		[]byte(request.Csr), caller.Identities, time.Duration(request.ValidityDuration)*time.Second, false)
@@ -103,6 +128,7 @@ func (s *Server) CreateCertificate(ctx context.Context, request *pb.IstioCertifi
 		s.monitoring.GetCertSignError(signErr.(*caerror.Error).ErrorType()).Increment()
 		return nil, status.Errorf(signErr.(*caerror.Error).HTTPErrorCode(), "CSR signing error (%v)", signErr.(*caerror.Error))
 	}
+	s.recordIssuance(ctx, "CreateCertificate", caller, []byte(request.Csr), cert)
 	respCertChain := []string{string(cert)}
 	if len(certChainBytes) != 0 {
 		respCertChain = append(respCertChain, string(certChainBytes))
@@ -116,6 +142,139 @@ func (s *Server) CreateCertificate(ctx context.Context, request *pb.IstioCertifi
 	return response, nil
 }
 
+// RevokeCertificate revokes the requested certificates, if the caller is one of the revocation admins.
+func (s *Server) RevokeCertificate(ctx context.Context, request *pb.RevokeCertificateRequest) (
+	*pb.RevokeCertificateResponse, error) {
+	revoker, ok := s.ca.(CertificateRevoker)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "the CA does not support certificate revocation")
+	}
+	caller := s.authenticate(ctx)
+	if caller == nil {
+		serverCaLog.Warn("request authentication failure")
+		s.monitoring.AuthnError.Increment()
+		return nil, status.Error(codes.Unauthenticated, "request authenticate failure")
+	}
+	if !s.isRevocationAdmin(caller) {
+		serverCaLog.Warnf("certificate revocation denied to %v", caller.Identities)
+		return nil, status.Errorf(codes.PermissionDenied, "caller %v is not allowed to revoke certificates", caller.Identities)
+	}
+
+	revoked, err := revoker.Revoke(request.SerialNumbers, request.SubjectId)
+	if err != nil {
+		serverCaLog.Errorf("certificate revocation error (%v)", err)
+		if caErr, ok := err.(*caerror.Error); ok {
+			return nil, status.Errorf(caErr.HTTPErrorCode(), "certificate revocation error (%v)", caErr)
+		}
+		return nil, status.Errorf(codes.Internal, "certificate revocation error (%v)", err)
+	}
+	serverCaLog.Infof("%v revoked certificates %v", caller.Identities, revoked)
+	if s.Audit != nil {
+		s.Audit.Revoked(revoked)
+	}
+	s.monitoring.Revoked.Record(float64(len(revoked)))
+
+	return &pb.RevokeCertificateResponse{RevokedSerialNumbers: revoked}, nil
+}
+
+// GetCertificateRevocationList returns the current CRL of the CA to authenticated callers.
+func (s *Server) GetCertificateRevocationList(ctx context.Context, request *pb.CertificateRevocationListRequest) (
+	*pb.CertificateRevocationListResponse, error) {
+	revoker, ok := s.ca.(CertificateRevoker)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "the CA does not support certificate revocation")
+	}
+	if caller := s.authenticate(ctx); caller == nil {
+		serverCaLog.Warn("request authentication failure")
+		s.monitoring.AuthnError.Increment()
+		return nil, status.Error(codes.Unauthenticated, "request authenticate failure")
+	}
+
+	crl, err := revoker.CertificateRevocationList()
+	if err != nil {
+		serverCaLog.Errorf("CRL generation error (%v)", err)
+		if caErr, ok := err.(*caerror.Error); ok {
+			return nil, status.Errorf(caErr.HTTPErrorCode(), "CRL generation error (%v)", caErr)
+		}
+		return nil, status.Errorf(codes.Internal, "CRL generation error (%v)", err)
+	}
+	return &pb.CertificateRevocationListResponse{Crl: string(crl)}, nil
+}
+
+// isRevocationAdmin returns whether one of the caller identities is allowed to revoke certificates.
+func (s *Server) isRevocationAdmin(caller *authenticate.Caller) bool {
+	for _, id := range caller.Identities {
+		for _, admin := range s.RevocationAdmins {
+			if id == admin {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// authorizeIssuance evaluates the certificate request with the issuance policy, and returns a PermissionDenied
+// error if the policy denies it. The evaluated identities are the ones of the CSR and the ones of the caller,
+// which the certificate is issued for.
+func (s *Server) authorizeIssuance(caller *authenticate.Caller, csrPEM []byte, ttl time.Duration) error {
+	if s.IssuancePolicy == nil {
+		return nil
+	}
+	csr, err := util.ParsePemEncodedCSR(csrPEM)
+	if err != nil {
+		serverCaLog.Warnf("CSR Pem parsing error (error %v)", err)
+		s.monitoring.CSRError.Increment()
+		return status.Errorf(codes.InvalidArgument, "CSR parsing error (%v)", err)
+	}
+	req := policy.Request{CallerIdentities: caller.Identities, TTL: ttl}
+	if req.KeyAlgorithm, err = util.KeyAlgorithmOf(csr.PublicKey); err != nil {
+		return status.Errorf(codes.InvalidArgument, "CSR key error (%v)", err)
+	}
+	seen := map[string]bool{}
+	var requested []string
+	if ids, err := util.ExtractIDs(csr.Extensions); err == nil {
+		requested = ids
+	}
+	for _, id := range append(requested, caller.Identities...) {
+		if !seen[id] {
+			seen[id] = true
+			req.SANs = append(req.SANs, id)
+		}
+	}
+	if allowed, decision := s.IssuancePolicy.Authorize(req); !allowed {
+		return status.Errorf(codes.PermissionDenied, "certificate issuance denied by the policy (%s)", decision.Reason)
+	}
+	return nil
+}
+
+// recordIssuance records the certificate issued to the caller in the audit log.
+func (s *Server) recordIssuance(ctx context.Context, method string, caller *authenticate.Caller, csrPEM, certPEM []byte) {
+	if s.Audit == nil {
+		return
+	}
+	cert, err := util.ParsePemEncodedCertificate(certPEM)
+	if err != nil {
+		serverCaLog.Errorf("failed to parse the issued certificate for the audit log: %v", err)
+		return
+	}
+	r := &audit.Record{
+		Time:         time.Now(),
+		Method:       method,
+		Callers:      caller.Identities,
+		SerialNumber: ca.FormatSerialNumber(cert.SerialNumber),
+		NotBefore:    cert.NotBefore,
+		NotAfter:     cert.NotAfter,
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		r.Node = p.Addr.String()
+	}
+	if csr, err := util.ParsePemEncodedCSR(csrPEM); err == nil {
+		r.RequestedSANs, _ = util.ExtractIDs(csr.Extensions)
+	}
+	r.SANs, _ = util.ExtractIDs(cert.Extensions)
+	s.Audit.Record(r)
+}
+
 // extractRootCertExpiryTimestamp returns the unix timestamp when the root becomes expires.
 func extractRootCertExpiryTimestamp(ca CertificateAuthority) float64 {
 	rb := ca.GetCAKeyCertBundle().GetRootCertPem()
@@ -161,6 +320,10 @@ func (s *Server) HandleCSR(ctx context.Context, request *pb.CsrRequest) (*pb.Csr
 
 	// TODO: Call authorizer.
 
+	if err := s.authorizeIssuance(caller, request.CsrPem, time.Duration(request.RequestedTtlMinutes)*time.Minute); err != nil {
+		return nil, err
+	}
+
 	_, _, certChainBytes, _ := s.ca.GetCAKeyCertBundle().GetAll()
 	cert, signErr := s.ca.Sign(
 		request.CsrPem, caller.Identities, time.Duration(request.RequestedTtlMinutes)*time.Minute, s.forCA)
@@ -170,6 +333,7 @@ func (s *Server) HandleCSR(ctx context.Context, request *pb.CsrRequest) (*pb.Csr
 		return nil, status.Errorf(codes.Internal, "CSR signing error (%v)", signErr.(*caerror.Error))
 	}
 
+	s.recordIssuance(ctx, "HandleCSR", caller, request.CsrPem, cert)
 	response := &pb.CsrResponse{
 		IsApproved: true,
 		SignedCert: cert,
@@ -200,6 +364,9 @@ func (s *Server) Run() error {
 	}
 	pb.RegisterIstioCAServiceServer(grpcServer, s)
 	pb.RegisterIstioCertificateServiceServer(grpcServer, s)
+	if _, ok := s.ca.(CertificateRevoker); ok {
+		pb.RegisterIstioCertificateRevocationServiceServer(grpcServer, s)
+	}
 
 	grpc_prometheus.EnableHandlingTimeHistogram()
 	grpc_prometheus.Register(grpcServer)
@@ -306,7 +473,9 @@ func (s *Server) createTLSServerOption() grpc.ServerOption {
 // getServerCertificate returns a valid server TLS certificate and the intermediate CA certificates,
 // signed by the current CA root.
 func (s *Server) getServerCertificate() (*tls.Certificate, error) {
+	// The hostnames are requested in the CSR too, for the CAs that can not set them on their own.
 	opts := util.CertOptions{
+		Host:       strings.Join(s.hostnames, ","),
 		RSAKeySize: 2048,
 	}
 
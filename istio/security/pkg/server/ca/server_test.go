@@ -34,7 +34,9 @@ import (
 	caerror "istio.io/istio/security/pkg/pki/error"
 	pkiutil "istio.io/istio/security/pkg/pki/util"
 	mockutil "istio.io/istio/security/pkg/pki/util/mock"
+	"istio.io/istio/security/pkg/server/ca/audit"
 	"istio.io/istio/security/pkg/server/ca/authenticate"
+	"istio.io/istio/security/pkg/server/ca/policy"
 	pb "istio.io/istio/security/proto"
 )
 
@@ -243,6 +245,131 @@ func TestCreateCertificate(t *testing.T) {
 	}
 }
 
+func TestCreateCertificateIssuancePolicy(t *testing.T) {
+	caller := "spiffe://test.com/ns/foo/sa/bar"
+	testCases := map[string]struct {
+		policy *policy.Policy
+		ttl    int64
+		code   codes.Code
+	}{
+		"No policy": {
+			code: codes.OK,
+		},
+		"Allowed": {
+			policy: mustNewPolicy(t, policy.Config{Rules: []policy.RuleConfig{{
+				Name:      "foo",
+				Namespace: "foo",
+				AllowedSpiffeIDs: []string{
+					"spiffe://{trustDomain}/ns/{namespace}/sa/{serviceAccount}",
+					"spiffe://test.com/namespace/ns/serviceaccount/sa",
+				},
+				MaxTTL: "1h",
+			}}}),
+			ttl:  1800,
+			code: codes.OK,
+		},
+		"Denied requested identity": {
+			policy: mustNewPolicy(t, policy.Config{Rules: []policy.RuleConfig{{
+				Name:             "foo",
+				AllowedSpiffeIDs: []string{"spiffe://{trustDomain}/ns/{namespace}/sa/{serviceAccount}"},
+			}}}),
+			code: codes.PermissionDenied,
+		},
+		"Denied TTL": {
+			policy: mustNewPolicy(t, policy.Config{Rules: []policy.RuleConfig{{Name: "foo", MaxTTL: "1h"}}}),
+			ttl:    7200,
+			code:   codes.PermissionDenied,
+		},
+		"Denied key algorithm": {
+			policy: mustNewPolicy(t, policy.Config{Rules: []policy.RuleConfig{{
+				Name:                 "foo",
+				AllowedKeyAlgorithms: []string{"ECDSA_P256"},
+			}}}),
+			code: codes.PermissionDenied,
+		},
+		"Denied in dry run": {
+			policy: mustNewPolicy(t, policy.Config{DryRun: true, DefaultAction: policy.ActionDeny}),
+			code:   codes.OK,
+		},
+	}
+
+	for id, c := range testCases {
+		store := policy.NewStore()
+		store.Set(c.policy)
+		server := &Server{
+			ca: &mockca.FakeCA{
+				SignedCert:    []byte("cert"),
+				KeyCertBundle: &mockutil.FakeKeyCertBundle{RootCertBytes: []byte("root_cert")},
+			},
+			Authenticators: []authenticator{&mockAuthenticator{identities: []string{caller}}},
+			monitoring:     newMonitoringMetrics(),
+			IssuancePolicy: store,
+		}
+		request := &pb.IstioCertificateRequest{Csr: csr, ValidityDuration: c.ttl}
+
+		_, err := server.CreateCertificate(context.Background(), request)
+		s, _ := status.FromError(err)
+		if code := s.Code(); c.code != code {
+			t.Errorf("Case %s: expecting code to be (%d) but got (%d): %s", id, c.code, code, s.Message())
+		}
+	}
+}
+
+func TestCreateCertificateAudit(t *testing.T) {
+	caller := "spiffe://test.com/ns/foo/sa/bar"
+	notBefore := time.Now().Truncate(time.Second)
+	certPEM, _, err := pkiutil.GenCertKeyFromOptions(pkiutil.CertOptions{
+		Host:         caller,
+		NotBefore:    notBefore,
+		TTL:          time.Hour,
+		IsSelfSigned: true,
+		RSAKeySize:   2048,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := pkiutil.ParsePemEncodedCertificate(certPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &Server{
+		ca: &mockca.FakeCA{
+			SignedCert:    certPEM,
+			KeyCertBundle: &mockutil.FakeKeyCertBundle{RootCertBytes: []byte("root_cert")},
+		},
+		Authenticators: []authenticator{&mockAuthenticator{identities: []string{caller}}},
+		monitoring:     newMonitoringMetrics(),
+		Audit:          audit.NewAuditor(),
+	}
+
+	if _, err := server.CreateCertificate(context.Background(), &pb.IstioCertificateRequest{Csr: csr}); err != nil {
+		t.Fatalf("CreateCertificate() failed: %v", err)
+	}
+	records := server.Audit.Inventory().List(caller)
+	if len(records) != 1 {
+		t.Fatalf("got %d certificates in the inventory, expected 1", len(records))
+	}
+	r := records[0]
+	if r.Method != "CreateCertificate" || r.SerialNumber != ca.FormatSerialNumber(cert.SerialNumber) ||
+		!r.NotBefore.Equal(notBefore) || !r.NotAfter.Equal(cert.NotAfter) {
+		t.Errorf("unexpected audit record %+v", r)
+	}
+	if len(r.RequestedSANs) != 1 || r.RequestedSANs[0] != "spiffe://test.com/namespace/ns/serviceaccount/sa" {
+		t.Errorf("got requested SANs %v, expected the identity of the CSR", r.RequestedSANs)
+	}
+	if len(r.Callers) != 1 || r.Callers[0] != caller || len(r.SANs) != 1 || r.SANs[0] != caller {
+		t.Errorf("got callers %v and SANs %v, expected %s", r.Callers, r.SANs, caller)
+	}
+}
+
+func mustNewPolicy(t *testing.T, config policy.Config) *policy.Policy {
+	p, err := policy.New(config)
+	if err != nil {
+		t.Fatalf("invalid issuance policy: %v", err)
+	}
+	return p
+}
+
 func TestHandleCSR(t *testing.T) {
 	testCases := map[string]struct {
 		authenticators []authenticator
@@ -360,6 +487,105 @@ func TestHandleCSR(t *testing.T) {
 	}
 }
 
+func TestRevokeCertificate(t *testing.T) {
+	admin := "spiffe://cluster.local/ns/istio-system/sa/admin"
+	testCases := map[string]struct {
+		authenticators []authenticator
+		ca             *mockca.FakeCA
+		revoked        []string
+		code           codes.Code
+	}{
+		"Unauthenticated request": {
+			authenticators: []authenticator{&mockAuthenticator{errMsg: "Not authorized"}},
+			ca:             &mockca.FakeCA{},
+			code:           codes.Unauthenticated,
+		},
+		"Caller is not an admin": {
+			authenticators: []authenticator{&mockAuthenticator{identities: []string{"spiffe://cluster.local/ns/foo/sa/bar"}}},
+			ca:             &mockca.FakeCA{},
+			code:           codes.PermissionDenied,
+		},
+		"Invalid request": {
+			authenticators: []authenticator{&mockAuthenticator{identities: []string{admin}}},
+			ca:             &mockca.FakeCA{RevokeErr: caerror.NewError(caerror.RevocationError, fmt.Errorf("bad serial"))},
+			code:           codes.InvalidArgument,
+		},
+		"Successful revocation": {
+			authenticators: []authenticator{&mockAuthenticator{identities: []string{admin}}},
+			ca:             &mockca.FakeCA{RevokedSerials: []string{"1f"}},
+			revoked:        []string{"1f"},
+			code:           codes.OK,
+		},
+	}
+
+	for id, c := range testCases {
+		server := &Server{
+			ca:               c.ca,
+			Authenticators:   c.authenticators,
+			RevocationAdmins: []string{admin},
+			monitoring:       newMonitoringMetrics(),
+		}
+		request := &pb.RevokeCertificateRequest{SerialNumbers: []string{"1f"}, SubjectId: "spiffe://cluster.local/ns/foo/sa/bar"}
+
+		response, err := server.RevokeCertificate(context.Background(), request)
+		s, _ := status.FromError(err)
+		if c.code != s.Code() {
+			t.Errorf("Case %s: expecting code to be (%d) but got (%d): %s", id, c.code, s.Code(), s.Message())
+			continue
+		}
+		if c.code != codes.OK {
+			continue
+		}
+		if len(response.RevokedSerialNumbers) != len(c.revoked) || response.RevokedSerialNumbers[0] != c.revoked[0] {
+			t.Errorf("Case %s: expecting revoked serials %v but got %v", id, c.revoked, response.RevokedSerialNumbers)
+		}
+		if c.ca.ReceivedSubjectID != request.SubjectId || len(c.ca.ReceivedSerials) != 1 {
+			t.Errorf("Case %s: unexpected revocation request (%v, %q)", id, c.ca.ReceivedSerials, c.ca.ReceivedSubjectID)
+		}
+	}
+}
+
+func TestGetCertificateRevocationList(t *testing.T) {
+	testCases := map[string]struct {
+		authenticators []authenticator
+		ca             *mockca.FakeCA
+		crl            string
+		code           codes.Code
+	}{
+		"Unauthenticated request": {
+			authenticators: []authenticator{&mockAuthenticator{errMsg: "Not authorized"}},
+			ca:             &mockca.FakeCA{CRL: []byte("crl")},
+			code:           codes.Unauthenticated,
+		},
+		"CA not ready": {
+			authenticators: []authenticator{&mockAuthenticator{}},
+			ca:             &mockca.FakeCA{CRLErr: caerror.NewError(caerror.CANotReady, fmt.Errorf("not ready"))},
+			code:           codes.Internal,
+		},
+		"Successful request": {
+			authenticators: []authenticator{&mockAuthenticator{}},
+			ca:             &mockca.FakeCA{CRL: []byte("crl")},
+			crl:            "crl",
+			code:           codes.OK,
+		},
+	}
+
+	for id, c := range testCases {
+		server := &Server{
+			ca:             c.ca,
+			Authenticators: c.authenticators,
+			monitoring:     newMonitoringMetrics(),
+		}
+		response, err := server.GetCertificateRevocationList(context.Background(), &pb.CertificateRevocationListRequest{})
+		s, _ := status.FromError(err)
+		if c.code != s.Code() {
+			t.Errorf("Case %s: expecting code to be (%d) but got (%d): %s", id, c.code, s.Code(), s.Message())
+		} else if c.code == codes.OK && response.Crl != c.crl {
+			t.Errorf("Case %s: expecting CRL %q but got %q", id, c.crl, response.Crl)
+		}
+	}
+}
+
 func TestShouldRefresh(t *testing.T) {
 	now := time.Now()
 	testCases := map[string]struct {
@@ -514,7 +740,7 @@ func TestGetServerCertificate(t *testing.T) {
 	client := fake.NewSimpleClientset()
 
 	caopts, err := ca.NewPluggedCertIstioCAOptions(certChainFile, signingCertFile, signingKeyFile, rootCertFile,
-		defaultWorkloadCertTTL, maxWorkloadCertTTL, caNamespace, client.CoreV1())
+		nil, defaultWorkloadCertTTL, maxWorkloadCertTTL, caNamespace, client.CoreV1())
 	if err != nil {
 		t.Fatalf("Failed to create a plugged-cert CA Options: %v", err)
 	}
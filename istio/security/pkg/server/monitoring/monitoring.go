@@ -36,6 +36,7 @@ var (
 // Monitor is the server that exposes Prometheus metrics about Citadel.
 type Monitor struct {
 	monitoringServer *http.Server
+	mux              *http.ServeMux
 	port             int
 	closed           chan bool
 }
@@ -75,6 +76,7 @@ func NewMonitor(port int, enableProfiling bool) (*Monitor, error) {
 		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
 	}
 
+	m.mux = mux
 	m.monitoringServer = &http.Server{
 		Handler: mux,
 	}
@@ -82,6 +84,11 @@ func NewMonitor(port int, enableProfiling bool) (*Monitor, error) {
 	return m, nil
 }
 
+// HandleFunc registers an additional handler, e.g. for a debug endpoint, on the monitor server.
+func (m *Monitor) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.mux.HandleFunc(pattern, handler)
+}
+
 // Start starts the monitor server.
 func (m *Monitor) Start(errCh chan<- error) {
 	// get the network stuff setup
@@ -137,34 +137,227 @@ func (m *IstioCertificateResponse) GetCertChain() []string {
 	return nil
 }
 
+// Certificate revocation request message.
+type RevokeCertificateRequest struct {
+	// Hexadecimal serial numbers of the certificates to revoke.
+	SerialNumbers []string `protobuf:"bytes,1,rep,name=serial_numbers,json=serialNumbers,proto3" json:"serial_numbers,omitempty"`
+	// Optional: identity (e.g. SPIFFE ID) whose unexpired certificates are all revoked.
+	SubjectId string `protobuf:"bytes,2,opt,name=subject_id,json=subjectId,proto3" json:"subject_id,omitempty"`
+}
+
+func (m *RevokeCertificateRequest) Reset()      { *m = RevokeCertificateRequest{} }
+func (*RevokeCertificateRequest) ProtoMessage() {}
+func (*RevokeCertificateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9eff2d2b4471d6ff, []int{2}
+}
+func (m *RevokeCertificateRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RevokeCertificateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RevokeCertificateRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RevokeCertificateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevokeCertificateRequest.Merge(m, src)
+}
+func (m *RevokeCertificateRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *RevokeCertificateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevokeCertificateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RevokeCertificateRequest proto.InternalMessageInfo
+
+func (m *RevokeCertificateRequest) GetSerialNumbers() []string {
+	if m != nil {
+		return m.SerialNumbers
+	}
+	return nil
+}
+
+func (m *RevokeCertificateRequest) GetSubjectId() string {
+	if m != nil {
+		return m.SubjectId
+	}
+	return ""
+}
+
+// Certificate revocation response message.
+type RevokeCertificateResponse struct {
+	// Hexadecimal serial numbers of the certificates that were newly revoked.
+	RevokedSerialNumbers []string `protobuf:"bytes,1,rep,name=revoked_serial_numbers,json=revokedSerialNumbers,proto3" json:"revoked_serial_numbers,omitempty"`
+}
+
+func (m *RevokeCertificateResponse) Reset()      { *m = RevokeCertificateResponse{} }
+func (*RevokeCertificateResponse) ProtoMessage() {}
+func (*RevokeCertificateResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9eff2d2b4471d6ff, []int{3}
+}
+func (m *RevokeCertificateResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RevokeCertificateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RevokeCertificateResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RevokeCertificateResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevokeCertificateResponse.Merge(m, src)
+}
+func (m *RevokeCertificateResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *RevokeCertificateResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevokeCertificateResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RevokeCertificateResponse proto.InternalMessageInfo
+
+func (m *RevokeCertificateResponse) GetRevokedSerialNumbers() []string {
+	if m != nil {
+		return m.RevokedSerialNumbers
+	}
+	return nil
+}
+
+// Certificate revocation list request message.
+type CertificateRevocationListRequest struct {
+}
+
+func (m *CertificateRevocationListRequest) Reset()      { *m = CertificateRevocationListRequest{} }
+func (*CertificateRevocationListRequest) ProtoMessage() {}
+func (*CertificateRevocationListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9eff2d2b4471d6ff, []int{4}
+}
+func (m *CertificateRevocationListRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *CertificateRevocationListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_CertificateRevocationListRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *CertificateRevocationListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CertificateRevocationListRequest.Merge(m, src)
+}
+func (m *CertificateRevocationListRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *CertificateRevocationListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CertificateRevocationListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CertificateRevocationListRequest proto.InternalMessageInfo
+
+// Certificate revocation list response message.
+type CertificateRevocationListResponse struct {
+	// PEM-encoded certificate revocation list, signed by the CA.
+	Crl string `protobuf:"bytes,1,opt,name=crl,proto3" json:"crl,omitempty"`
+}
+
+func (m *CertificateRevocationListResponse) Reset()      { *m = CertificateRevocationListResponse{} }
+func (*CertificateRevocationListResponse) ProtoMessage() {}
+func (*CertificateRevocationListResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9eff2d2b4471d6ff, []int{5}
+}
+func (m *CertificateRevocationListResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *CertificateRevocationListResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_CertificateRevocationListResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *CertificateRevocationListResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CertificateRevocationListResponse.Merge(m, src)
+}
+func (m *CertificateRevocationListResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *CertificateRevocationListResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CertificateRevocationListResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CertificateRevocationListResponse proto.InternalMessageInfo
+
+func (m *CertificateRevocationListResponse) GetCrl() string {
+	if m != nil {
+		return m.Crl
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*IstioCertificateRequest)(nil), "istio.v1.auth.IstioCertificateRequest")
 	proto.RegisterType((*IstioCertificateResponse)(nil), "istio.v1.auth.IstioCertificateResponse")
+	proto.RegisterType((*RevokeCertificateRequest)(nil), "istio.v1.auth.RevokeCertificateRequest")
+	proto.RegisterType((*RevokeCertificateResponse)(nil), "istio.v1.auth.RevokeCertificateResponse")
+	proto.RegisterType((*CertificateRevocationListRequest)(nil), "istio.v1.auth.CertificateRevocationListRequest")
+	proto.RegisterType((*CertificateRevocationListResponse)(nil), "istio.v1.auth.CertificateRevocationListResponse")
 }
 
 func init() { proto.RegisterFile("security/proto/istioca.proto", fileDescriptor_9eff2d2b4471d6ff) }
 
 var fileDescriptor_9eff2d2b4471d6ff = []byte{
-	// 294 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x91, 0xbd, 0x4a, 0x03, 0x41,
-	0x14, 0x85, 0xe7, 0xba, 0x20, 0x64, 0x40, 0x48, 0xb6, 0x71, 0x11, 0xbd, 0x84, 0x14, 0x1a, 0x10,
-	0x36, 0xf8, 0xd3, 0xd8, 0x1a, 0x9b, 0xb4, 0xf1, 0x01, 0xc2, 0x64, 0xf6, 0x86, 0x8c, 0x48, 0x36,
-	0xce, 0xdc, 0x8d, 0xa4, 0xd3, 0x37, 0xf0, 0x31, 0x7c, 0x14, 0xcb, 0x94, 0x29, 0xcd, 0x6c, 0x63,
-	0x99, 0x47, 0x90, 0x59, 0x23, 0x28, 0x41, 0xec, 0xe6, 0x7c, 0x67, 0xe0, 0x9c, 0xc3, 0x95, 0x87,
-	0x8e, 0x74, 0x61, 0x0d, 0xcf, 0x3b, 0x53, 0x9b, 0x73, 0xde, 0x31, 0x8e, 0x4d, 0xae, 0x55, 0x5a,
-	0xa9, 0x78, 0xaf, 0x92, 0xe9, 0xec, 0x2c, 0x55, 0x05, 0x8f, 0x5b, 0x8f, 0x72, 0xbf, 0x17, 0x40,
-	0x97, 0x2c, 0x9b, 0x91, 0xd1, 0x8a, 0xa9, 0x4f, 0x0f, 0x05, 0x39, 0x8e, 0xeb, 0x32, 0xd2, 0xce,
-	0x26, 0xd0, 0x84, 0x76, 0xad, 0x1f, 0x9e, 0xf1, 0x91, 0x94, 0xae, 0x18, 0xde, 0x91, 0xe6, 0x81,
-	0xc9, 0x92, 0x9d, 0xca, 0xa8, 0x6d, 0x48, 0x2f, 0x8b, 0x4f, 0x65, 0x63, 0xa6, 0xee, 0x4d, 0x66,
-	0x78, 0x3e, 0xc8, 0x0a, 0xab, 0xd8, 0xe4, 0x93, 0x24, 0x6a, 0x42, 0x3b, 0xea, 0xd7, 0xbf, 0x8d,
-	0x9b, 0x0d, 0x6f, 0x5d, 0xc9, 0x64, 0x3b, 0xd8, 0x4d, 0xf3, 0x89, 0xa3, 0x90, 0xa3, 0xc9, 0xf2,
-	0x40, 0x8f, 0x95, 0x99, 0x24, 0xd0, 0x8c, 0x42, 0x4e, 0x20, 0xdd, 0x00, 0xce, 0x9f, 0x61, 0xbb,
-	0xf4, 0x2d, 0xd9, 0x99, 0xd1, 0x14, 0x8f, 0x64, 0xa3, 0x6b, 0x49, 0x31, 0xfd, 0xf0, 0xe2, 0xe3,
-	0xf4, 0xd7, 0xe8, 0xf4, 0x8f, 0xc5, 0x07, 0x27, 0xff, 0xfe, 0xfb, 0x2a, 0xd8, 0x12, 0xd7, 0x97,
-	0x8b, 0x15, 0x8a, 0xe5, 0x0a, 0xc5, 0x7a, 0x85, 0xf0, 0xe4, 0x11, 0x5e, 0x3d, 0xc2, 0x9b, 0x47,
-	0x58, 0x78, 0x84, 0x77, 0x8f, 0xf0, 0xe1, 0x51, 0xac, 0x3d, 0xc2, 0x4b, 0x89, 0x62, 0x51, 0xa2,
-	0x58, 0x96, 0x28, 0x86, 0xbb, 0xd5, 0x0d, 0x2e, 0x3e, 0x03, 0x00, 0x00, 0xff, 0xff, 0x23, 0xaa,
-	0x00, 0x39, 0xa3, 0x01, 0x00, 0x00,
+	// 437 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x53, 0xd1, 0x6a, 0xd4, 0x40,
+	0x14, 0xcd, 0x74, 0x41, 0xe8, 0x85, 0x4a, 0x77, 0x10, 0x8d, 0x4b, 0x1d, 0xd2, 0x01, 0x75, 0x41,
+	0xc8, 0x6a, 0xad, 0x0f, 0xbe, 0xba, 0x82, 0x14, 0x44, 0x30, 0xfd, 0x80, 0x38, 0x3b, 0xb9, 0x65,
+	0x47, 0x63, 0x52, 0x67, 0x26, 0x91, 0xbe, 0x29, 0xfe, 0x80, 0xe0, 0x4f, 0xf8, 0x29, 0x3e, 0xee,
+	0x63, 0x1f, 0xdd, 0xec, 0x8b, 0x8f, 0xfd, 0x04, 0x49, 0x36, 0x91, 0x76, 0xb3, 0x6b, 0xfa, 0x96,
+	0x39, 0xe7, 0xce, 0x9c, 0x73, 0xef, 0xb9, 0x81, 0x3d, 0x83, 0x32, 0xd3, 0xca, 0x9e, 0x8d, 0x4e,
+	0x75, 0x6a, 0xd3, 0x91, 0x32, 0x56, 0xa5, 0x52, 0xf8, 0xd5, 0x89, 0xee, 0x54, 0x47, 0x3f, 0x7f,
+	0xe2, 0x8b, 0xcc, 0x4e, 0xf9, 0x67, 0xb8, 0x73, 0x54, 0x02, 0x63, 0xd4, 0x56, 0x9d, 0x28, 0x29,
+	0x2c, 0x06, 0xf8, 0x29, 0x43, 0x63, 0xe9, 0x2e, 0xf4, 0xa4, 0xd1, 0x2e, 0xf1, 0xc8, 0x70, 0x3b,
+	0x28, 0x3f, 0xe9, 0x3d, 0x00, 0x93, 0x4d, 0xde, 0xa3, 0xb4, 0xa1, 0x8a, 0xdc, 0xad, 0x8a, 0xd8,
+	0xae, 0x91, 0xa3, 0x88, 0x3e, 0x82, 0x7e, 0x2e, 0x62, 0x15, 0x29, 0x7b, 0x16, 0x46, 0x99, 0x16,
+	0x56, 0xa5, 0x89, 0xdb, 0xf3, 0xc8, 0xb0, 0x17, 0xec, 0x36, 0xc4, 0xcb, 0x1a, 0xe7, 0xcf, 0xc1,
+	0x6d, 0x0b, 0x9b, 0xd3, 0x34, 0x31, 0x58, 0xea, 0x48, 0xd4, 0x36, 0x94, 0x53, 0xa1, 0x12, 0x97,
+	0x78, 0xbd, 0x52, 0xa7, 0x44, 0xc6, 0x25, 0xc0, 0xdf, 0x81, 0x1b, 0x60, 0x9e, 0x7e, 0xc0, 0x35,
+	0xa6, 0xef, 0xc3, 0x4d, 0x83, 0x5a, 0x89, 0x38, 0x4c, 0xb2, 0x8f, 0x13, 0xd4, 0xa6, 0xbe, 0xbe,
+	0xb3, 0x44, 0xdf, 0x2c, 0xc1, 0x8e, 0x4e, 0xf8, 0x5b, 0xb8, 0xbb, 0x46, 0xa1, 0x76, 0x77, 0x08,
+	0xb7, 0x75, 0x45, 0x46, 0xe1, 0x5a, 0xa9, 0x5b, 0x35, 0x7b, 0x7c, 0x59, 0x91, 0x73, 0xf0, 0xae,
+	0x3c, 0x96, 0xa7, 0xb2, 0x1a, 0xc4, 0x6b, 0x65, 0x6c, 0x6d, 0x9e, 0x3f, 0x83, 0xfd, 0xff, 0xd4,
+	0xd4, 0xf2, 0x65, 0x2c, 0x3a, 0xfe, 0x17, 0x8b, 0x8e, 0x0f, 0xbe, 0x92, 0x76, 0x88, 0xc7, 0xa8,
+	0x73, 0x25, 0x91, 0x9e, 0x40, 0x7f, 0xac, 0x51, 0xd8, 0xcb, 0x9d, 0xd0, 0x07, 0xfe, 0x95, 0x25,
+	0xf0, 0x37, 0x6c, 0xc0, 0xe0, 0x61, 0x67, 0xdd, 0xd2, 0x13, 0x77, 0x0e, 0x7e, 0x6c, 0xc1, 0x7e,
+	0x9b, 0x6e, 0x1a, 0x68, 0xdc, 0x4c, 0xa1, 0xdf, 0x9a, 0x2b, 0x5d, 0x55, 0xd9, 0x94, 0xed, 0x60,
+	0xd8, 0x5d, 0xd8, 0xf8, 0xa1, 0xdf, 0x08, 0xec, 0xbd, 0x42, 0xbb, 0x71, 0x9c, 0x74, 0xb4, 0xf2,
+	0x58, 0x57, 0x38, 0x83, 0xc7, 0xd7, 0xbf, 0xd0, 0xb8, 0x78, 0x71, 0x38, 0x9b, 0x33, 0xe7, 0x7c,
+	0xce, 0x9c, 0x8b, 0x39, 0x23, 0x5f, 0x0a, 0x46, 0x7e, 0x16, 0x8c, 0xfc, 0x2a, 0x18, 0x99, 0x15,
+	0x8c, 0xfc, 0x2e, 0x18, 0xf9, 0x53, 0x30, 0xe7, 0xa2, 0x60, 0xe4, 0xfb, 0x82, 0x39, 0xb3, 0x05,
+	0x73, 0xce, 0x17, 0xcc, 0x99, 0xdc, 0xa8, 0xfe, 0xd4, 0xa7, 0x7f, 0x07, 0x00, 0x32, 0xbe, 0x7f,
+	0xa5, 0xc9, 0x03, 0x00, 0x00,
 }
 
 func (this *IstioCertificateRequest) Equal(that interface{}) bool {
@@ -226,6 +419,112 @@ func (this *IstioCertificateResponse) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *RevokeCertificateRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*RevokeCertificateRequest)
+	if !ok {
+		that2, ok := that.(RevokeCertificateRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.SerialNumbers) != len(that1.SerialNumbers) {
+		return false
+	}
+	for i := range this.SerialNumbers {
+		if this.SerialNumbers[i] != that1.SerialNumbers[i] {
+			return false
+		}
+	}
+	if this.SubjectId != that1.SubjectId {
+		return false
+	}
+	return true
+}
+func (this *RevokeCertificateResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*RevokeCertificateResponse)
+	if !ok {
+		that2, ok := that.(RevokeCertificateResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.RevokedSerialNumbers) != len(that1.RevokedSerialNumbers) {
+		return false
+	}
+	for i := range this.RevokedSerialNumbers {
+		if this.RevokedSerialNumbers[i] != that1.RevokedSerialNumbers[i] {
+			return false
+		}
+	}
+	return true
+}
+func (this *CertificateRevocationListRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*CertificateRevocationListRequest)
+	if !ok {
+		that2, ok := that.(CertificateRevocationListRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	return true
+}
+func (this *CertificateRevocationListResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*CertificateRevocationListResponse)
+	if !ok {
+		that2, ok := that.(CertificateRevocationListResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Crl != that1.Crl {
+		return false
+	}
+	return true
+}
 func (this *IstioCertificateRequest) GoString() string {
 	if this == nil {
 		return "nil"
@@ -248,6 +547,46 @@ func (this *IstioCertificateResponse) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *RevokeCertificateRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&istio_v1_auth.RevokeCertificateRequest{")
+	s = append(s, "SerialNumbers: "+fmt.Sprintf("%#v", this.SerialNumbers)+",\n")
+	s = append(s, "SubjectId: "+fmt.Sprintf("%#v", this.SubjectId)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *RevokeCertificateResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&istio_v1_auth.RevokeCertificateResponse{")
+	s = append(s, "RevokedSerialNumbers: "+fmt.Sprintf("%#v", this.RevokedSerialNumbers)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *CertificateRevocationListRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 4)
+	s = append(s, "&istio_v1_auth.CertificateRevocationListRequest{")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *CertificateRevocationListResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&istio_v1_auth.CertificateRevocationListResponse{")
+	s = append(s, "Crl: "+fmt.Sprintf("%#v", this.Crl)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func valueToGoStringIstioca(v interface{}, typ string) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -339,35 +678,147 @@ var _IstioCertificateService_serviceDesc = grpc.ServiceDesc{
 	Metadata: "security/proto/istioca.proto",
 }
 
-func (m *IstioCertificateRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+// IstioCertificateRevocationServiceClient is the client API for IstioCertificateRevocationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type IstioCertificateRevocationServiceClient interface {
+	// Revokes certificates issued by the CA, which are then listed in its certificate revocation list.
+	RevokeCertificate(ctx context.Context, in *RevokeCertificateRequest, opts ...grpc.CallOption) (*RevokeCertificateResponse, error)
+	// Returns the current certificate revocation list of the CA.
+	GetCertificateRevocationList(ctx context.Context, in *CertificateRevocationListRequest, opts ...grpc.CallOption) (*CertificateRevocationListResponse, error)
+}
+
+type istioCertificateRevocationServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewIstioCertificateRevocationServiceClient(cc *grpc.ClientConn) IstioCertificateRevocationServiceClient {
+	return &istioCertificateRevocationServiceClient{cc}
+}
+
+func (c *istioCertificateRevocationServiceClient) RevokeCertificate(ctx context.Context, in *RevokeCertificateRequest, opts ...grpc.CallOption) (*RevokeCertificateResponse, error) {
+	out := new(RevokeCertificateResponse)
+	err := c.cc.Invoke(ctx, "/istio.v1.auth.IstioCertificateRevocationService/RevokeCertificate", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return dAtA[:n], nil
+	return out, nil
 }
 
-func (m *IstioCertificateRequest) MarshalTo(dAtA []byte) (int, error) {
-	size := m.Size()
-	return m.MarshalToSizedBuffer(dAtA[:size])
+func (c *istioCertificateRevocationServiceClient) GetCertificateRevocationList(ctx context.Context, in *CertificateRevocationListRequest, opts ...grpc.CallOption) (*CertificateRevocationListResponse, error) {
+	out := new(CertificateRevocationListResponse)
+	err := c.cc.Invoke(ctx, "/istio.v1.auth.IstioCertificateRevocationService/GetCertificateRevocationList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (m *IstioCertificateRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
-	i := len(dAtA)
-	_ = i
-	var l int
-	_ = l
-	if m.ValidityDuration != 0 {
-		i = encodeVarintIstioca(dAtA, i, uint64(m.ValidityDuration))
-		i--
-		dAtA[i] = 0x18
-	}
-	if len(m.SubjectId) > 0 {
-		i -= len(m.SubjectId)
-		copy(dAtA[i:], m.SubjectId)
-		i = encodeVarintIstioca(dAtA, i, uint64(len(m.SubjectId)))
+// IstioCertificateRevocationServiceServer is the server API for IstioCertificateRevocationService service.
+type IstioCertificateRevocationServiceServer interface {
+	// Revokes certificates issued by the CA, which are then listed in its certificate revocation list.
+	RevokeCertificate(context.Context, *RevokeCertificateRequest) (*RevokeCertificateResponse, error)
+	// Returns the current certificate revocation list of the CA.
+	GetCertificateRevocationList(context.Context, *CertificateRevocationListRequest) (*CertificateRevocationListResponse, error)
+}
+
+// UnimplementedIstioCertificateRevocationServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedIstioCertificateRevocationServiceServer struct {
+}
+
+func (*UnimplementedIstioCertificateRevocationServiceServer) RevokeCertificate(ctx context.Context, req *RevokeCertificateRequest) (*RevokeCertificateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeCertificate not implemented")
+}
+func (*UnimplementedIstioCertificateRevocationServiceServer) GetCertificateRevocationList(ctx context.Context, req *CertificateRevocationListRequest) (*CertificateRevocationListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCertificateRevocationList not implemented")
+}
+
+func RegisterIstioCertificateRevocationServiceServer(s *grpc.Server, srv IstioCertificateRevocationServiceServer) {
+	s.RegisterService(&_IstioCertificateRevocationService_serviceDesc, srv)
+}
+
+func _IstioCertificateRevocationService_RevokeCertificate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeCertificateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IstioCertificateRevocationServiceServer).RevokeCertificate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/istio.v1.auth.IstioCertificateRevocationService/RevokeCertificate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IstioCertificateRevocationServiceServer).RevokeCertificate(ctx, req.(*RevokeCertificateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IstioCertificateRevocationService_GetCertificateRevocationList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CertificateRevocationListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IstioCertificateRevocationServiceServer).GetCertificateRevocationList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/istio.v1.auth.IstioCertificateRevocationService/GetCertificateRevocationList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IstioCertificateRevocationServiceServer).GetCertificateRevocationList(ctx, req.(*CertificateRevocationListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _IstioCertificateRevocationService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "istio.v1.auth.IstioCertificateRevocationService",
+	HandlerType: (*IstioCertificateRevocationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RevokeCertificate",
+			Handler:    _IstioCertificateRevocationService_RevokeCertificate_Handler,
+		},
+		{
+			MethodName: "GetCertificateRevocationList",
+			Handler:    _IstioCertificateRevocationService_GetCertificateRevocationList_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "security/proto/istioca.proto",
+}
+
+func (m *IstioCertificateRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *IstioCertificateRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *IstioCertificateRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.ValidityDuration != 0 {
+		i = encodeVarintIstioca(dAtA, i, uint64(m.ValidityDuration))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.SubjectId) > 0 {
+		i -= len(m.SubjectId)
+		copy(dAtA[i:], m.SubjectId)
+		i = encodeVarintIstioca(dAtA, i, uint64(len(m.SubjectId)))
 		i--
 		dAtA[i] = 0x12
 	}
@@ -413,6 +864,130 @@ func (m *IstioCertificateResponse) MarshalToSizedBuffer(dAtA []byte) (int, error
 	return len(dAtA) - i, nil
 }
 
+func (m *RevokeCertificateRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RevokeCertificateRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RevokeCertificateRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.SubjectId) > 0 {
+		i -= len(m.SubjectId)
+		copy(dAtA[i:], m.SubjectId)
+		i = encodeVarintIstioca(dAtA, i, uint64(len(m.SubjectId)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.SerialNumbers) > 0 {
+		for iNdEx := len(m.SerialNumbers) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.SerialNumbers[iNdEx])
+			copy(dAtA[i:], m.SerialNumbers[iNdEx])
+			i = encodeVarintIstioca(dAtA, i, uint64(len(m.SerialNumbers[iNdEx])))
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *RevokeCertificateResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RevokeCertificateResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RevokeCertificateResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.RevokedSerialNumbers) > 0 {
+		for iNdEx := len(m.RevokedSerialNumbers) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.RevokedSerialNumbers[iNdEx])
+			copy(dAtA[i:], m.RevokedSerialNumbers[iNdEx])
+			i = encodeVarintIstioca(dAtA, i, uint64(len(m.RevokedSerialNumbers[iNdEx])))
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *CertificateRevocationListRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CertificateRevocationListRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *CertificateRevocationListRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	return len(dAtA) - i, nil
+}
+
+func (m *CertificateRevocationListResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CertificateRevocationListResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *CertificateRevocationListResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Crl) > 0 {
+		i -= len(m.Crl)
+		copy(dAtA[i:], m.Crl)
+		i = encodeVarintIstioca(dAtA, i, uint64(len(m.Crl)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintIstioca(dAtA []byte, offset int, v uint64) int {
 	offset -= sovIstioca(v)
 	base := offset
@@ -456,46 +1031,363 @@ func (m *IstioCertificateResponse) Size() (n int) {
 			n += 1 + l + sovIstioca(uint64(l))
 		}
 	}
-	return n
-}
+	return n
+}
+
+func (m *RevokeCertificateRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.SerialNumbers) > 0 {
+		for _, s := range m.SerialNumbers {
+			l = len(s)
+			n += 1 + l + sovIstioca(uint64(l))
+		}
+	}
+	l = len(m.SubjectId)
+	if l > 0 {
+		n += 1 + l + sovIstioca(uint64(l))
+	}
+	return n
+}
+
+func (m *RevokeCertificateResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.RevokedSerialNumbers) > 0 {
+		for _, s := range m.RevokedSerialNumbers {
+			l = len(s)
+			n += 1 + l + sovIstioca(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *CertificateRevocationListRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	return n
+}
+
+func (m *CertificateRevocationListResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Crl)
+	if l > 0 {
+		n += 1 + l + sovIstioca(uint64(l))
+	}
+	return n
+}
+
+func sovIstioca(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozIstioca(x uint64) (n int) {
+	return sovIstioca(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *IstioCertificateRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&IstioCertificateRequest{`,
+		`Csr:` + fmt.Sprintf("%v", this.Csr) + `,`,
+		`SubjectId:` + fmt.Sprintf("%v", this.SubjectId) + `,`,
+		`ValidityDuration:` + fmt.Sprintf("%v", this.ValidityDuration) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *IstioCertificateResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&IstioCertificateResponse{`,
+		`CertChain:` + fmt.Sprintf("%v", this.CertChain) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *RevokeCertificateRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&RevokeCertificateRequest{`,
+		`SerialNumbers:` + fmt.Sprintf("%v", this.SerialNumbers) + `,`,
+		`SubjectId:` + fmt.Sprintf("%v", this.SubjectId) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *RevokeCertificateResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&RevokeCertificateResponse{`,
+		`RevokedSerialNumbers:` + fmt.Sprintf("%v", this.RevokedSerialNumbers) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *CertificateRevocationListRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&CertificateRevocationListRequest{`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *CertificateRevocationListResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&CertificateRevocationListResponse{`,
+		`Crl:` + fmt.Sprintf("%v", this.Crl) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func valueToStringIstioca(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("*%v", pv)
+}
+func (m *IstioCertificateRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIstioca
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: IstioCertificateRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: IstioCertificateRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Csr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIstioca
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIstioca
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIstioca
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Csr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SubjectId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIstioca
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIstioca
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIstioca
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SubjectId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ValidityDuration", wireType)
+			}
+			m.ValidityDuration = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIstioca
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ValidityDuration |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIstioca(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIstioca
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIstioca
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
 
-func sovIstioca(x uint64) (n int) {
-	return (math_bits.Len64(x|1) + 6) / 7
-}
-func sozIstioca(x uint64) (n int) {
-	return sovIstioca(uint64((x << 1) ^ uint64((int64(x) >> 63))))
-}
-func (this *IstioCertificateRequest) String() string {
-	if this == nil {
-		return "nil"
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	s := strings.Join([]string{`&IstioCertificateRequest{`,
-		`Csr:` + fmt.Sprintf("%v", this.Csr) + `,`,
-		`SubjectId:` + fmt.Sprintf("%v", this.SubjectId) + `,`,
-		`ValidityDuration:` + fmt.Sprintf("%v", this.ValidityDuration) + `,`,
-		`}`,
-	}, "")
-	return s
+	return nil
 }
-func (this *IstioCertificateResponse) String() string {
-	if this == nil {
-		return "nil"
+func (m *IstioCertificateResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIstioca
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: IstioCertificateResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: IstioCertificateResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CertChain", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIstioca
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIstioca
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIstioca
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.CertChain = append(m.CertChain, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIstioca(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIstioca
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIstioca
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
 	}
-	s := strings.Join([]string{`&IstioCertificateResponse{`,
-		`CertChain:` + fmt.Sprintf("%v", this.CertChain) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func valueToStringIstioca(v interface{}) string {
-	rv := reflect.ValueOf(v)
-	if rv.IsNil() {
-		return "nil"
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	pv := reflect.Indirect(rv).Interface()
-	return fmt.Sprintf("*%v", pv)
+	return nil
 }
-func (m *IstioCertificateRequest) Unmarshal(dAtA []byte) error {
+func (m *RevokeCertificateRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -518,15 +1410,15 @@ func (m *IstioCertificateRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: IstioCertificateRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: RevokeCertificateRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: IstioCertificateRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: RevokeCertificateRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Csr", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field SerialNumbers", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -554,7 +1446,7 @@ func (m *IstioCertificateRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Csr = string(dAtA[iNdEx:postIndex])
+			m.SerialNumbers = append(m.SerialNumbers, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
@@ -588,11 +1480,64 @@ func (m *IstioCertificateRequest) Unmarshal(dAtA []byte) error {
 			}
 			m.SubjectId = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 3:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ValidityDuration", wireType)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIstioca(dAtA[iNdEx:])
+			if err != nil {
+				return err
 			}
-			m.ValidityDuration = 0
+			if skippy < 0 {
+				return ErrInvalidLengthIstioca
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIstioca
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RevokeCertificateResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIstioca
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RevokeCertificateResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RevokeCertificateResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RevokedSerialNumbers", wireType)
+			}
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowIstioca
@@ -602,11 +1547,24 @@ func (m *IstioCertificateRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ValidityDuration |= int64(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIstioca
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIstioca
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RevokedSerialNumbers = append(m.RevokedSerialNumbers, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipIstioca(dAtA[iNdEx:])
@@ -631,7 +1589,7 @@ func (m *IstioCertificateRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *IstioCertificateResponse) Unmarshal(dAtA []byte) error {
+func (m *CertificateRevocationListRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -654,15 +1612,68 @@ func (m *IstioCertificateResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: IstioCertificateResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: CertificateRevocationListRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: IstioCertificateResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: CertificateRevocationListRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIstioca(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIstioca
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIstioca
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *CertificateRevocationListResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIstioca
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CertificateRevocationListResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CertificateRevocationListResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field CertChain", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Crl", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -690,7 +1701,7 @@ func (m *IstioCertificateResponse) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.CertChain = append(m.CertChain, string(dAtA[iNdEx:postIndex]))
+			m.Crl = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex